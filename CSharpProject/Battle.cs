@@ -1,81 +1,513 @@
 using System;
+using System.Collections.Generic;
+using System.Linq;
 using GoPureWithCsharp.Battle;
 
 namespace GoPureWithCsharp
 {
+    /// <summary>
+    /// 战斗状态
+    /// Running ⇄ Paused (Pause / Resume)，Running/Paused → Finished (战斗分出胜负或 End)，
+    /// 任意状态 → Destroyed (Destroy)；Destroyed 不再执行回合也不接受输入
+    /// </summary>
+    public enum BattleState
+    {
+        Running,
+        Paused,
+        Finished,
+        Destroyed,
+    }
+
     /// <summary>
     /// 单场战斗实例
     /// </summary>
     public class BattleInstance
     {
-        private static readonly Random _random = new Random();
+        private readonly BattleRandom _random;
+        private readonly List<BattleEvent> _events = new List<BattleEvent>();
+
+        /// <summary>
+        /// 参战单位，攻击方在前，按阵容顺序
+        /// </summary>
+        private readonly List<BattleUnit> _units;
+
+        /// <summary>
+        /// 回放格式版本
+        /// </summary>
+        public const string ReplayVersion = "1.0";
+
+        /// <summary>
+        /// BattleStatus.State 取值，与 Go 侧 csharp.BattleState* 常量对应
+        /// </summary>
+        public const string StateRunning = "running";
+        public const string StatePaused = "paused";
+        public const string StateFinished = "finished";
+        public const string StateDestroyed = "destroyed";
+
+        /// <summary>
+        /// 阵容为空的队伍作为一个单位时的血量 (最大生命)，旧版本快照没有最大生命时也使用
+        /// </summary>
+        public const int DefaultHealth = 300;
 
         public uint BattleId { get; private set; }
+
+        /// <summary>
+        /// 战斗所在的分片 (BattleShard.Id)，创建后不变
+        /// </summary>
+        public uint ShardId { get; init; }
         public uint AtkTeamId { get; private set; }
         public uint DefTeamId { get; private set; }
-        public int AtkHealth { get; private set; }
-        public int DefHealth { get; private set; }
+
+        /// <summary>
+        /// 创建战斗时的队伍 (回放)
+        /// </summary>
+        public Team AtkTeam { get; private set; }
+        public Team DefTeam { get; private set; }
         public int CurrentRound { get; private set; }
-        public bool IsFinished { get; private set; }
+        public BattleState State { get; private set; }
         public uint? Winner { get; private set; }
 
         /// <summary>
-        /// 创建战斗实例
+        /// 已分出胜负 (Finished，或分出胜负后被 Destroy)
+        /// </summary>
+        public bool IsFinished => Winner.HasValue;
+
+        /// <summary>
+        /// 已强制结束，下一个逻辑帧记录结束事件并输出结果
+        /// </summary>
+        public bool EndRequested { get; private set; }
+
+        /// <summary>
+        /// 超过最长战斗时长被强制结束 (BattleEnd.Timeout)，结果的 Timeout 为 true
         /// </summary>
-        public BattleInstance(uint battleId, uint atkTeamId, uint defTeamId, int initialHealth)
+        public bool TimedOut { get; private set; }
+
+        /// <summary>
+        /// 本帧结束的战斗，OnTick 输出结果和回放后调用 TakeResult 清除
+        /// </summary>
+        public bool ResultPending { get; private set; }
+        public ulong Seed { get; private set; }
+        public ulong StartFrame { get; private set; }
+        public ulong EndFrame { get; private set; }
+
+        /// <summary>
+        /// 创建战斗实例，units 由 UnitTable.CreateUnits 按双方阵容创建，战斗内的随机数只由 seed 决定
+        /// </summary>
+        public BattleInstance(uint battleId, Team atkTeam, Team defTeam, List<BattleUnit> units, ulong seed)
         {
+            _random = new BattleRandom(seed);
+            _units = units;
+            Seed = seed;
             BattleId = battleId;
-            AtkTeamId = atkTeamId;
-            DefTeamId = defTeamId;
-            AtkHealth = initialHealth;
-            DefHealth = initialHealth;
+            AtkTeam = atkTeam.Clone();
+            DefTeam = defTeam.Clone();
+            AtkTeamId = atkTeam.TeamId;
+            DefTeamId = defTeam.TeamId;
             CurrentRound = 0;
-            IsFinished = false;
+            State = BattleState.Running;
             Winner = null;
         }
 
         /// <summary>
-        /// 执行一回合战斗
+        /// 导出战斗快照 (热重载)
         /// </summary>
-        public void ExecuteRound(int minDamage, int maxDamage)
+        public BattleSnapshot ToSnapshot()
         {
-            if (IsFinished) return;
+            var (atkHealth, atkMaxHealth, atkAttack) = TeamStats(AtkTeamId);
+            var (defHealth, defMaxHealth, defAttack) = TeamStats(DefTeamId);
+            return new BattleSnapshot
+            {
+                BattleId = BattleId,
+                ShardId = ShardId,
+                AtkTeamId = AtkTeamId,
+                DefTeamId = DefTeamId,
+                AtkHealth = atkHealth,
+                DefHealth = defHealth,
+                CurrentRound = CurrentRound,
+                IsFinished = IsFinished,
+                Winner = Winner ?? 0,
+                Seed = Seed,
+                State = StateName(State),
+                EndRequested = EndRequested,
+                Timeout = TimedOut,
+                AtkMaxHealth = atkMaxHealth,
+                DefMaxHealth = defMaxHealth,
+                AtkAttack = atkAttack,
+                DefAttack = defAttack,
+                RngState = _random.State,
+                StartFrame = StartFrame,
+                Events = { _events },
+                Units = { _units.Select(u => u.Clone()) },
+                AtkTeam = AtkTeam.Clone(),
+                DefTeam = DefTeam.Clone(),
+            };
+        }
+
+        /// <summary>
+        /// 当前战斗状态 (运维和匹配查询)
+        /// </summary>
+        public BattleStatus ToStatus(long timestamp)
+        {
+            var (atkHealth, atkMaxHealth, atkAttack) = TeamStats(AtkTeamId);
+            var (defHealth, defMaxHealth, defAttack) = TeamStats(DefTeamId);
+            return new BattleStatus
+            {
+                BattleId = BattleId,
+                ShardId = ShardId,
+                Round = CurrentRound,
+                AtkHealth = atkHealth,
+                DefHealth = defHealth,
+                State = StateName(State),
+                Timestamp = timestamp,
+                AtkTeamId = AtkTeamId,
+                DefTeamId = DefTeamId,
+                Winner = Winner ?? 0,
+                AtkMaxHealth = atkMaxHealth,
+                DefMaxHealth = defMaxHealth,
+                AtkAttack = atkAttack,
+                DefAttack = defAttack,
+                Units = { _units.Select(u => u.Clone()) },
+            };
+        }
+
+        /// <summary>
+        /// 从快照恢复战斗实例 (热重载)
+        /// </summary>
+        public static BattleInstance FromSnapshot(BattleSnapshot snapshot)
+        {
+            // 旧版本快照没有单位和队伍，每队恢复为一个单位
+            var units = snapshot.Units.Count > 0
+                ? snapshot.Units.Select(u => u.Clone()).ToList()
+                : new List<BattleUnit>
+                {
+                    UnitTable.TeamUnit(snapshot.AtkTeamId, snapshot.AtkHealth, snapshot.AtkMaxHealth != 0 ? snapshot.AtkMaxHealth : DefaultHealth, snapshot.AtkAttack),
+                    UnitTable.TeamUnit(snapshot.DefTeamId, snapshot.DefHealth, snapshot.DefMaxHealth != 0 ? snapshot.DefMaxHealth : DefaultHealth, snapshot.DefAttack),
+                };
+            var atkTeam = snapshot.AtkTeam ?? new Team { TeamId = snapshot.AtkTeamId };
+            var defTeam = snapshot.DefTeam ?? new Team { TeamId = snapshot.DefTeamId };
+            var battle = new BattleInstance(snapshot.BattleId, atkTeam, defTeam, units, snapshot.Seed)
+            {
+                ShardId = snapshot.ShardId,
+                CurrentRound = snapshot.CurrentRound,
+                Winner = snapshot.IsFinished ? snapshot.Winner : null,
+                EndRequested = snapshot.EndRequested,
+                TimedOut = snapshot.Timeout,
+            };
+            battle.State = snapshot.State switch
+            {
+                StatePaused => BattleState.Paused,
+                StateFinished => BattleState.Finished,
+                StateDestroyed => BattleState.Destroyed,
+                StateRunning => BattleState.Running,
+                // 旧版本快照没有 state
+                _ => snapshot.IsFinished ? BattleState.Finished : BattleState.Running,
+            };
+            battle._random.State = snapshot.RngState;
+            battle.StartFrame = snapshot.StartFrame;
+            battle._events.AddRange(snapshot.Events);
+            if (battle.IsFinished && !battle.EndRequested && battle._events.Count > 0)
+            {
+                battle.EndFrame = (ulong)battle._events[^1].Timestamp;
+            }
+            return battle;
+        }
+
+        /// <summary>
+        /// 在逻辑帧 frame 执行一回合战斗，攻击和结束都记录为回放事件
+        /// 攻击方存活单位按阵容顺序各攻击一次，然后是防守方；一方单位全部被击败时战斗结束
+        /// </summary>
+        public void ExecuteRound(int minDamage, int maxDamage, ulong frame)
+        {
+            if (State != BattleState.Running) return;
 
             CurrentRound++;
+            if (CurrentRound == 1)
+            {
+                StartFrame = frame;
+            }
+
+            foreach (var (teamId, enemyId) in new[] { (AtkTeamId, DefTeamId), (DefTeamId, AtkTeamId) })
+            {
+                foreach (var unit in _units)
+                {
+                    if (unit.TeamId != teamId || unit.Health <= 0) continue;
+
+                    var target = PickTarget(teamId)!;
+                    int damage = UnitTable.Damage(_random.Next(minDamage, maxDamage + 1), unit, target);
+                    target.Health -= damage;
+                    var evt = AddEvent(frame, "attack", unit.UnitId, target.UnitId, damage);
+                    BattleLogger.Debug($"[Battle {BattleId}] Round {CurrentRound}: 单位 {unit.UnitId} 攻击 {target.UnitId}, 伤害={damage}, 剩余血量={target.Health}");
+                    if (target.Health > 0) continue;
+
+                    evt.Extra["killed"] = "1";
+                    if (PickTarget(teamId) == null)
+                    {
+                        Finish(frame, teamId, enemyId);
+                        BattleLogger.Info($"[Battle {BattleId}] 队伍 {enemyId} 全部被击败, 队伍 {teamId} 获胜!");
+                        return;
+                    }
+                }
+            }
+        }
+
+        /// <summary>
+        /// 选择 teamId 一方的攻击目标: 对方存活单位中生命最低的，相同时取阵容中靠前的
+        /// 对方没有存活单位时返回 null
+        /// </summary>
+        private BattleUnit? PickTarget(uint teamId)
+        {
+            BattleUnit? target = null;
+            foreach (var unit in _units)
+            {
+                if (unit.TeamId == teamId || unit.Health <= 0) continue;
+                if (target == null || unit.Health < target.Health) target = unit;
+            }
+            return target;
+        }
+
+        private BattleUnit? FindUnit(uint unitId) => _units.Find(u => u.UnitId == unitId);
+
+        /// <summary>
+        /// 一方存活单位的生命之和、最大生命之和与攻击力之和
+        /// </summary>
+        private (int Health, int MaxHealth, int Attack) TeamStats(uint teamId)
+        {
+            int health = 0, maxHealth = 0, attack = 0;
+            foreach (var unit in _units)
+            {
+                if (unit.TeamId != teamId) continue;
+                health += Math.Max(unit.Health, 0);
+                maxHealth += unit.MaxHealth;
+                attack += unit.Attack;
+            }
+            return (health, maxHealth, attack);
+        }
 
-            // ATK 攻击 DEF
-            int atkDamage = _random.Next(minDamage, maxDamage + 1);
-            DefHealth -= atkDamage;
-            BattleLogger.Debug($"[Battle {BattleId}] Round {CurrentRound}: ATK={AtkTeamId} 攻击 DEF={DefTeamId}, 伤害={atkDamage}, DEF 剩余血量={DefHealth}");
+        private void Finish(ulong frame, uint winner, uint loser)
+        {
+            State = BattleState.Finished;
+            Winner = winner;
+            EndFrame = frame;
+            ResultPending = true;
+            AddEvent(frame, "end", winner, loser, 1);
+        }
 
-            // 检查 DEF 是否死亡
-            if (DefHealth <= 0)
+        /// <summary>
+        /// 完成强制结束: 在逻辑帧 frame 记录结束事件 (value=0 区别于分出胜负)，之后输出结果
+        /// </summary>
+        public void CompleteEnd(ulong frame)
+        {
+            if (!EndRequested) return;
+
+            EndRequested = false;
+            if (CurrentRound == 0)
             {
-                IsFinished = true;
-                Winner = AtkTeamId;
-                BattleLogger.Info($"[Battle {BattleId}] DEF={DefTeamId} 死亡, ATK={AtkTeamId} 获胜!");
-                return;
+                StartFrame = frame;
             }
+            uint winner = Winner ?? 0;
+            EndFrame = frame;
+            ResultPending = true;
+            AddEvent(frame, "end", winner, winner == AtkTeamId ? DefTeamId : AtkTeamId, 0);
+            BattleLogger.Info($"[Battle {BattleId}] 强制结束, 胜方={winner}, 超时={TimedOut}");
+        }
 
-            // DEF 反击 ATK
-            int defDamage = _random.Next(minDamage, maxDamage + 1);
-            AtkHealth -= defDamage;
-            BattleLogger.Debug($"[Battle {BattleId}] Round {CurrentRound}: DEF={DefTeamId} 反击 ATK={AtkTeamId}, 伤害={defDamage}, ATK 剩余血量={AtkHealth}");
+        /// <summary>
+        /// 取走本帧结束的战斗结果，返回 false 表示没有待输出的结果
+        /// </summary>
+        public bool TakeResult()
+        {
+            if (!ResultPending) return false;
+            ResultPending = false;
+            return true;
+        }
 
-            // 检查 ATK 是否死亡
-            if (AtkHealth <= 0)
+        private BattleEvent AddEvent(ulong frame, string eventType, uint performerId, uint targetId, int value)
+        {
+            var evt = new BattleEvent
             {
-                IsFinished = true;
-                Winner = DefTeamId;
-                BattleLogger.Info($"[Battle {BattleId}] ATK={AtkTeamId} 死亡, DEF={DefTeamId} 获胜!");
+                Timestamp = (long)frame,
+                EventType = eventType,
+                PerformerId = performerId,
+                TargetId = targetId,
+                Value = value,
+                Extra = { ["round"] = CurrentRound.ToString() },
+            };
+            _events.Add(evt);
+            return evt;
+        }
+
+        /// <summary>
+        /// 战斗结果，只在战斗结束后有意义
+        /// 伤害为该方单位受到的伤害总和，击杀列表为被击败的单位 (按被击败的顺序)，时长为经历的逻辑帧数
+        /// </summary>
+        public BattleResult BuildResult()
+        {
+            uint winner = Winner ?? 0;
+            uint loser = winner == AtkTeamId ? DefTeamId : AtkTeamId;
+
+            int atkDamage = 0, defDamage = 0;
+            var kills = new List<uint>();
+            foreach (var evt in _events)
+            {
+                if (evt.EventType != "attack") continue;
+                if (FindUnit(evt.TargetId)?.TeamId == AtkTeamId) atkDamage += evt.Value;
+                else defDamage += evt.Value;
+                if (evt.Extra.ContainsKey("killed")) kills.Add(evt.TargetId);
             }
+
+            return new BattleResult
+            {
+                Winner = winner,
+                Loser = loser,
+                AtkDamage = atkDamage,
+                DefDamage = defDamage,
+                Kills = { kills },
+                Duration = (long)(EndFrame - StartFrame + 1),
+                BattleScore = defDamage * 10,
+                Timeout = TimedOut,
+            };
         }
-    
-        public void ProcessInput(BattleContext ctx)
+
+        /// <summary>
+        /// 战斗回放，包含全部回合事件和最终结果
+        /// </summary>
+        public BattleReplay BuildReplay(BattleResult result)
         {
-            // 这里可以根据 BattleContext 的内容处理输入
-            BattleLogger.Info($"[Battle {BattleId}] 处理 BattleContext 输入, Tick={ctx.Tick}");
-            // 示例：可以根据 ctx.Option.BattleInput 的内容进行战斗逻辑处理
+            return new BattleReplay
+            {
+                BattleId = BattleId,
+                StartTime = (long)StartFrame,
+                EndTime = (long)EndFrame,
+                AtkTeam = AtkTeam.Clone(),
+                DefTeam = DefTeam.Clone(),
+                Events = { _events },
+                Result = result,
+                Version = ReplayVersion,
+            };
         }
+
+        /// <summary>
+        /// 处理一条输入，返回 BattleErrorCode
+        /// 生命周期输入按状态机迁移，当前状态不允许时返回 InvalidBattleState；
+        /// 道具和用户操作按 effects 修改属性，属性变化以 ctx.Tick 为时间戳记录为回放事件
+        /// </summary>
+        public BattleErrorCode ProcessInput(BattleContext ctx, BattleEffectTable effects)
+        {
+            var input = ctx.BattleInput;
+            if (input == null)
+            {
+                return BattleErrorCode.InvalidRequest;
+            }
+
+            BattleLogger.Info($"[Battle {BattleId}] 处理 BattleContext 输入 {input.InputCase}, 状态={State}, Tick={ctx.Tick}");
+            switch (input.InputCase)
+            {
+                case Battle.BattleInput.InputOneofCase.Pause:
+                    if (State != BattleState.Running) return BattleErrorCode.InvalidBattleState;
+                    State = BattleState.Paused;
+                    return BattleErrorCode.Success;
+
+                case Battle.BattleInput.InputOneofCase.Resume:
+                    if (State != BattleState.Paused) return BattleErrorCode.InvalidBattleState;
+                    State = BattleState.Running;
+                    return BattleErrorCode.Success;
+
+                case Battle.BattleInput.InputOneofCase.End:
+                    return RequestEnd(input.End.Winner, input.End.Timeout);
+
+                case Battle.BattleInput.InputOneofCase.Destroy:
+                    if (State == BattleState.Destroyed) return BattleErrorCode.InvalidBattleState;
+                    // 强制结束后尚未输出的结果不再输出
+                    State = BattleState.Destroyed;
+                    EndRequested = false;
+                    return BattleErrorCode.Success;
+
+                case Battle.BattleInput.InputOneofCase.Use:
+                case Battle.BattleInput.InputOneofCase.UserOp:
+                    if (State != BattleState.Running) return BattleErrorCode.InvalidBattleState;
+                    var code = effects.Resolve(input, out var resolved);
+                    if (code != BattleErrorCode.Success) return code;
+                    return ApplyEffects(ctx.Tick, resolved);
+
+                default:
+                    return BattleErrorCode.InvalidRequest;
+            }
+        }
+
+        /// <summary>
+        /// 在逻辑帧 frame 修改使用者单位的属性，每个属性变化记录一个回放事件
+        /// 使用者不在战斗中或已被击败时不修改任何属性；生命不会因效果降到 0 以下，单位只会在攻击中被击败
+        /// </summary>
+        private BattleErrorCode ApplyEffects(ulong frame, List<InputEffect> effects)
+        {
+            foreach (var effect in effects)
+            {
+                var unit = FindUnit(effect.User);
+                if (unit == null) return BattleErrorCode.TeamNotFound;
+                if (unit.Health <= 0) return BattleErrorCode.InvalidRequest;
+            }
+
+            foreach (var effect in effects)
+            {
+                var unit = FindUnit(effect.User)!;
+                switch (effect.AttributeId)
+                {
+                    case BattleEffectTable.AttributeMaxHealth:
+                        unit.MaxHealth = Math.Max(unit.MaxHealth + effect.Value, 1);
+                        if (effect.Value > 0) unit.Health += effect.Value;
+                        break;
+                    case BattleEffectTable.AttributeAttack:
+                        unit.Attack = Math.Max(unit.Attack + effect.Value, 0);
+                        break;
+                    case BattleEffectTable.AttributeHealth:
+                        unit.Health += effect.Value;
+                        break;
+                }
+                unit.Health = Math.Min(Math.Max(unit.Health, 1), unit.MaxHealth);
+
+                var evt = AddEvent(frame, effect.EventType, effect.User, effect.User, effect.Value);
+                evt.Extra["attribute_id"] = effect.AttributeId.ToString();
+                evt.Extra.Add(effect.Extra);
+                BattleLogger.Debug($"[Battle {BattleId}] {effect.EventType}: 单位={effect.User}, 属性={effect.AttributeId}, 变化={effect.Value}");
+            }
+            return BattleErrorCode.Success;
+        }
+
+        /// <summary>
+        /// 强制结束，胜方立即确定，结束事件和结果在下一个逻辑帧输出
+        /// winner 为 0 时按双方单位的剩余血量之和判定，相同时防守方获胜；timeout 记入结果
+        /// </summary>
+        private BattleErrorCode RequestEnd(uint winner, bool timeout)
+        {
+            if (State != BattleState.Running && State != BattleState.Paused)
+            {
+                return BattleErrorCode.InvalidBattleState;
+            }
+            if (winner == 0)
+            {
+                winner = TeamStats(AtkTeamId).Health > TeamStats(DefTeamId).Health ? AtkTeamId : DefTeamId;
+            }
+            else if (winner != AtkTeamId && winner != DefTeamId)
+            {
+                return BattleErrorCode.InvalidRequest;
+            }
+
+            State = BattleState.Finished;
+            Winner = winner;
+            EndRequested = true;
+            TimedOut = timeout;
+            return BattleErrorCode.Success;
+        }
+
+        /// <summary>
+        /// BattleState -> BattleStatus.State / BattleSnapshot.State
+        /// </summary>
+        public static string StateName(BattleState state) => state switch
+        {
+            BattleState.Paused => StatePaused,
+            BattleState.Finished => StateFinished,
+            BattleState.Destroyed => StateDestroyed,
+            _ => StateRunning,
+        };
     }
 }
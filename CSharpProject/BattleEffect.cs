@@ -0,0 +1,197 @@
+using System;
+using System.Collections.Generic;
+using System.Text.Json;
+using GoPureWithCsharp.Battle;
+
+namespace GoPureWithCsharp
+{
+    /// <summary>
+    /// 一条输入产生的一次属性修改，对应一个回放事件
+    /// </summary>
+    public readonly record struct InputEffect(uint User, string EventType, uint AttributeId, int Value, Dictionary<string, string> Extra);
+
+    /// <summary>
+    /// 道具和用户操作效果表 - 由 ability_attribute.json 和 battle_item.json 驱动
+    /// 算法与 Go 侧 csharp/battle_effect.go 一致，SimEngine 复算时得到相同的属性和回放事件
+    /// BattleUseItem.UserId 和 BattleUserOp.CharId 为使用者的单位 ID (见 BattleUnit.cs)
+    /// </summary>
+    public sealed class BattleEffectTable
+    {
+        public const string AbilityAttributeConfig = "ability_attribute.json";
+        public const string BattleItemConfig = "battle_item.json";
+
+        /// <summary>
+        /// 战斗中生效的属性 ID，与 Go 侧 csharp.Attribute* 常量对应
+        /// </summary>
+        public const uint AttributeMaxHealth = 3001; // 最大生命，增加时当前生命同步增加
+        public const uint AttributeAttack = 3002;    // 攻击力，叠加到单位每次攻击的伤害上
+        public const uint AttributeHealth = 4001;    // 当前生命，不超过最大生命
+
+        private const string AbilityAttributeSheet = "proy.config.ExcelAbilityAttribute";
+
+        private sealed record ItemConfig(uint ItemId, string Name, int MaxQuantity, List<(uint AttributeId, int Value)> Effects);
+        private sealed record OperationConfig(string Operation, List<(uint AttributeId, int Value)> Effects);
+
+        private Dictionary<uint, string> _attributes = new();
+        private Dictionary<uint, ItemConfig> _items = new();
+        private Dictionary<string, OperationConfig> _operations = new();
+
+        /// <summary>
+        /// 解析效果表依赖的配置，其他配置忽略；解析失败时抛出异常，效果表保持不变
+        /// </summary>
+        public void Load(string configName, byte[] data)
+        {
+            switch (configName)
+            {
+                case AbilityAttributeConfig:
+                    LoadAttributes(data);
+                    break;
+                case BattleItemConfig:
+                    LoadItems(data);
+                    break;
+            }
+        }
+
+        // ability_attribute.json: [文件头, {表名: [属性...]}, 表名]
+        private void LoadAttributes(byte[] data)
+        {
+            using var doc = JsonDocument.Parse(data);
+            foreach (var section in doc.RootElement.EnumerateArray())
+            {
+                if (section.ValueKind != JsonValueKind.Object || !section.TryGetProperty(AbilityAttributeSheet, out var rows))
+                {
+                    continue;
+                }
+                var attributes = new Dictionary<uint, string>();
+                foreach (var row in rows.EnumerateArray())
+                {
+                    attributes[row.GetProperty("attribute_id").GetUInt32()] = row.GetProperty("name").GetString() ?? "";
+                }
+                _attributes = attributes;
+                return;
+            }
+            throw new InvalidDataException($"{AbilityAttributeConfig} 中没有属性表 {AbilityAttributeSheet}");
+        }
+
+        // battle_item.json，效果引用的属性必须已在属性表中且在战斗中生效
+        private void LoadItems(byte[] data)
+        {
+            using var doc = JsonDocument.Parse(data);
+            var root = doc.RootElement;
+
+            var items = new Dictionary<uint, ItemConfig>();
+            if (root.TryGetProperty("items", out var itemRows))
+            {
+                foreach (var row in itemRows.EnumerateArray())
+                {
+                    uint itemId = row.GetProperty("itemId").GetUInt32();
+                    int maxQuantity = row.TryGetProperty("maxQuantity", out var q) ? q.GetInt32() : 0;
+                    if (itemId == 0 || items.ContainsKey(itemId))
+                    {
+                        throw new InvalidDataException($"{BattleItemConfig}: 道具 ID {itemId} 为 0 或重复");
+                    }
+                    if (maxQuantity <= 0)
+                    {
+                        throw new InvalidDataException($"{BattleItemConfig}: 道具 {itemId} 的最大数量 {maxQuantity} 无效");
+                    }
+                    string name = row.TryGetProperty("name", out var n) ? n.GetString() ?? "" : "";
+                    items[itemId] = new ItemConfig(itemId, name, maxQuantity, ParseEffects(row, $"道具 {itemId}"));
+                }
+            }
+
+            var operations = new Dictionary<string, OperationConfig>();
+            if (root.TryGetProperty("operations", out var opRows))
+            {
+                foreach (var row in opRows.EnumerateArray())
+                {
+                    string operation = row.GetProperty("operation").GetString() ?? "";
+                    if (operation == "" || operations.ContainsKey(operation))
+                    {
+                        throw new InvalidDataException($"{BattleItemConfig}: 操作 \"{operation}\" 为空或重复");
+                    }
+                    operations[operation] = new OperationConfig(operation, ParseEffects(row, $"操作 {operation}"));
+                }
+            }
+
+            _items = items;
+            _operations = operations;
+        }
+
+        private List<(uint AttributeId, int Value)> ParseEffects(JsonElement row, string owner)
+        {
+            var effects = new List<(uint, int)>();
+            if (row.TryGetProperty("effects", out var rows))
+            {
+                foreach (var effect in rows.EnumerateArray())
+                {
+                    uint attributeId = effect.GetProperty("attributeId").GetUInt32();
+                    int value = effect.TryGetProperty("value", out var v) ? v.GetInt32() : 0;
+                    if (!_attributes.TryGetValue(attributeId, out var attributeName))
+                    {
+                        throw new InvalidDataException($"{BattleItemConfig}: {owner}: 属性 {attributeId} 不在 {AbilityAttributeConfig} 中");
+                    }
+                    if (attributeId != AttributeMaxHealth && attributeId != AttributeAttack && attributeId != AttributeHealth)
+                    {
+                        throw new InvalidDataException($"{BattleItemConfig}: {owner}: 属性 {attributeId} ({attributeName}) 在战斗中不生效");
+                    }
+                    effects.Add((attributeId, value));
+                }
+            }
+            if (effects.Count == 0)
+            {
+                throw new InvalidDataException($"{BattleItemConfig}: {owner}: 没有效果");
+            }
+            return effects;
+        }
+
+        /// <summary>
+        /// 校验道具和用户操作输入并展开为属性修改: 道具和操作存在、数量在 1 ~ maxQuantity 内
+        /// 任一道具无效时整条输入被拒绝；使用者单位由 BattleInstance.ApplyEffects 检查
+        /// </summary>
+        public BattleErrorCode Resolve(Battle.BattleInput input, out List<InputEffect> effects)
+        {
+            effects = new List<InputEffect>();
+            switch (input.InputCase)
+            {
+                case Battle.BattleInput.InputOneofCase.Use:
+                    var use = input.Use;
+                    if (use.ItemIds.Count == 0)
+                    {
+                        return BattleErrorCode.InvalidRequest;
+                    }
+                    foreach (uint itemId in use.ItemIds)
+                    {
+                        if (!_items.TryGetValue(itemId, out var item) || use.Quantity < 1 || use.Quantity > item.MaxQuantity)
+                        {
+                            effects.Clear();
+                            return BattleErrorCode.InvalidRequest;
+                        }
+                        foreach (var (attributeId, value) in item.Effects)
+                        {
+                            effects.Add(new InputEffect(use.UserId, "item", attributeId, value * use.Quantity, new Dictionary<string, string>
+                            {
+                                ["item_id"] = itemId.ToString(),
+                                ["quantity"] = use.Quantity.ToString(),
+                            }));
+                        }
+                    }
+                    break;
+
+                case Battle.BattleInput.InputOneofCase.UserOp:
+                    if (!_operations.TryGetValue(input.UserOp.Operation, out var op))
+                    {
+                        return BattleErrorCode.InvalidRequest;
+                    }
+                    foreach (var (attributeId, value) in op.Effects)
+                    {
+                        effects.Add(new InputEffect((uint)input.UserOp.CharId, "skill", attributeId, value, new Dictionary<string, string>
+                        {
+                            ["operation"] = op.Operation,
+                        }));
+                    }
+                    break;
+            }
+            return BattleErrorCode.Success;
+        }
+    }
+}
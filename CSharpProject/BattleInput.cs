@@ -30,9 +30,9 @@ namespace GoPureWithCsharp
                 return -1; // 战斗不存在
             }
 
-            if (battle.IsFinished)
+            if (battle.State != BattleState.Running)
             {
-                return -2; // 战斗已结束
+                return -2; // 战斗已结束 (或暂停、已销毁)
             }
 
             // 根据操作类型处理
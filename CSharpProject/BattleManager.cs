@@ -1,5 +1,7 @@
 using System;
+using System.Collections.Concurrent;
 using System.Collections.Generic;
+using System.Linq;
 using System.Runtime.InteropServices;
 using Google.Protobuf;
 using GoPureWithCsharp.Battle;
@@ -36,16 +38,38 @@ namespace GoPureWithCsharp
 
     /// <summary>
     /// 战斗管理器 - 存储和管理所有战斗实例
+    /// 战斗按分片 (BattleShard) 存放，每个分片有自己的锁，_lockObj 只保护配置、回调和环形缓冲区的绑定
+    /// 锁顺序: _lockObj → BattleShard.Lock → _outputLock
     /// </summary>
     public static class BattleManager
     {
-        private static readonly Dictionary<uint, BattleInstance> _battles = new Dictionary<uint, BattleInstance>();
+        /// <summary>
+        /// 分片 ID -> 分片，第一次使用时创建
+        /// </summary>
+        private static readonly ConcurrentDictionary<uint, BattleShard> _shards = new ConcurrentDictionary<uint, BattleShard>();
+
+        /// <summary>
+        /// 战斗 ID -> 所在分片，战斗 ID 在所有分片中唯一
+        /// </summary>
+        private static readonly ConcurrentDictionary<uint, BattleShard> _battleShards = new ConcurrentDictionary<uint, BattleShard>();
+
         private static readonly object _lockObj = new object();
+
+        /// <summary>
+        /// 串行化输出环形缓冲区的写入 (单生产者)，多个分片并行 OnTickShard 时使用
+        /// </summary>
+        private static readonly object _outputLock = new object();
         private static BattleConfig? _config;
-        
-        // 复用的缓冲区，避免频繁分配
-        private static readonly byte[] _outputBuffer = new byte[20480];
-        private static GCHandle _bufferHandle = GCHandle.Alloc(_outputBuffer, GCHandleType.Pinned);
+
+        /// <summary>
+        /// 道具和用户操作效果表，LoadConfig 加载 ability_attribute.json / battle_item.json 时更新
+        /// </summary>
+        private static readonly BattleEffectTable _effects = new BattleEffectTable();
+
+        /// <summary>
+        /// 单位初始属性表，LoadConfig 加载 unit_config.json 时更新
+        /// </summary>
+        private static readonly UnitTable _units = new UnitTable();
 
         /// <summary>
         /// 委托：由 Go 侧实现，用于获取配置数据
@@ -57,15 +81,21 @@ namespace GoPureWithCsharp
         /// </summary>
         private static BattleResultCallback? _resultCallback;
 
+        /// <summary>
+        /// Go 绑定的输入/输出环形缓冲区，未绑定时输入走 ProcessBattleContextInput，输出走结果回调
+        /// </summary>
+        private static SharedRing? _inputRing;
+        private static SharedRing? _outputRing;
+
         /// <summary>
         /// 注册配置加载器 (由 Go 调用)
         /// </summary>
-        public static void RegisterConfigLoader(ConfigLoaderCallback configLoader)
+        public static void RegisterConfigLoader(ConfigLoaderCallback? configLoader)
         {
             lock (_lockObj)
             {
                 _configLoader = configLoader;
-                BattleLogger.Info("配置加载器已注册");
+                BattleLogger.Info(configLoader == null ? "配置加载器已注销" : "配置加载器已注册");
             }
         }
 
@@ -118,6 +148,18 @@ namespace GoPureWithCsharp
                     Marshal.Copy(outDataPtr, configData, 0, outDataLen);
                 }
 
+                // 效果表和单位表依赖的配置在这里解析，格式错误时加载失败
+                try
+                {
+                    _effects.Load(configName, configData);
+                    _units.Load(configName, configData);
+                }
+                catch (Exception ex)
+                {
+                    BattleLogger.Error($"解析配置失败: {configName}, {ex.Message}");
+                    return -1;
+                }
+
                 // 保存配置
                 _config = new BattleConfig
                 {
@@ -189,41 +231,89 @@ namespace GoPureWithCsharp
         /// <summary>
         /// 注册战斗结果回调 (由 Go 调用)
         /// </summary>
-        public static void RegisterResultCallback(BattleResultCallback resultCallback)
+        public static void RegisterResultCallback(BattleResultCallback? resultCallback)
         {
             lock (_lockObj)
             {
                 _resultCallback = resultCallback;
-                BattleLogger.Info($"战斗结果回调已注册 地址 {_resultCallback}");
+                BattleLogger.Info(resultCallback == null ? "战斗结果回调已注销" : $"战斗结果回调已注册 地址 {_resultCallback}");
             }
         }
 
         /// <summary>
-        /// 创建战斗 (由 Go 调用)
+        /// 获取分片，不存在时创建
         /// </summary>
-        public static int CreateBattlee(uint battleId, uint atkTeamId, uint defTeamId)
+        private static BattleShard GetShard(uint shardId)
         {
+            return _shards.GetOrAdd(shardId, id => new BattleShard(id));
+        }
+
+        /// <summary>
+        /// 按 ID 顺序返回所有分片，与 OnTick 的执行顺序一致
+        /// </summary>
+        private static IEnumerable<BattleShard> OrderedShards()
+        {
+            return _shards.Values.OrderBy(shard => shard.Id);
+        }
+
+        /// <summary>
+        /// 创建战斗 (由 Go 调用)，在分片 0 中创建
+        /// </summary>
+        public static int CreateBattlee(BattleEnv env)
+        {
+            return CreateBattlee(0, env);
+        }
+
+        /// <summary>
+        /// 在 shardId 分片中创建战斗 (由 Go 调用)，按 env 的双方阵容创建单位，env.Seed 为战斗随机种子
+        /// 战斗 ID 在所有分片中唯一
+        /// 返回: 0 成功, -1 战斗 ID 冲突, 正数为 env 被拒绝的 BattleErrorCode
+        /// </summary>
+        public static int CreateBattlee(uint shardId, BattleEnv env)
+        {
+            uint battleId = env.BattleId;
+            if (_battleShards.ContainsKey(battleId))
+            {
+                BattleLogger.Error($"战斗 ID={battleId} 已存在");
+                return -1;
+            }
+            if (env.Atk == null || env.Def == null)
+            {
+                BattleLogger.Error($"战斗 ID={battleId} 缺少攻击方或防守方队伍");
+                return (int)BattleErrorCode.TeamNotFound;
+            }
+            if (env.Atk.TeamId == env.Def.TeamId)
+            {
+                BattleLogger.Error($"战斗 ID={battleId} 双方队伍 ID 相同: {env.Atk.TeamId}");
+                return (int)BattleErrorCode.InvalidRequest;
+            }
+
+            BattleErrorCode code;
+            List<BattleUnit> units;
             lock (_lockObj)
             {
-                if (_battles.ContainsKey(battleId))
+                code = _units.CreateUnits(env.Atk, env.Def, out units);
+            }
+            if (code != BattleErrorCode.Success)
+            {
+                BattleLogger.Error($"战斗 ID={battleId} 阵容无效: {code}");
+                return (int)code;
+            }
+
+            var shard = GetShard(shardId);
+            lock (shard.Lock)
+            {
+                // 并发创建同一 ID 时只有一个成功
+                if (!_battleShards.TryAdd(battleId, shard))
                 {
                     BattleLogger.Error($"战斗 ID={battleId} 已存在");
                     return -1;
                 }
-
-                // 从配置获取初始血量
-                int initialHealth = 300; // 默认值
-                if (_config != null)
-                {
-                    // 可以从配置中解析初始血量
-                }
-
-                BattleInstance battle = new(battleId, atkTeamId, defTeamId, initialHealth);
-                _battles[battleId] = battle;
-
-                BattleLogger.Info($"战斗已创建: ID={battleId}, ATK={atkTeamId}, DEF={defTeamId}");
-                return 0; // 成功
+                shard.Battles[battleId] = new BattleInstance(battleId, env.Atk, env.Def, units, env.Seed) { ShardId = shardId };
             }
+
+            BattleLogger.Info($"战斗已创建: ID={battleId}, 分片={shardId}, ATK={env.Atk.TeamId}, DEF={env.Def.TeamId}, 单位={units.Count}, Seed={env.Seed}");
+            return 0; // 成功
         }
 
         /// <summary>
@@ -231,88 +321,186 @@ namespace GoPureWithCsharp
         /// </summary>
         public static int DestroyBattle(uint battleId)
         {
-            lock (_lockObj)
+            if (!_battleShards.TryGetValue(battleId, out var shard))
+            {
+                BattleLogger.Error($"战斗 ID={battleId} 不存在");
+                return -1;
+            }
+
+            lock (shard.Lock)
             {
-                if (!_battles.ContainsKey(battleId))
+                if (!shard.Battles.Remove(battleId))
                 {
                     BattleLogger.Error($"战斗 ID={battleId} 不存在");
                     return -1;
                 }
+                _battleShards.TryRemove(battleId, out _);
+            }
+            BattleLogger.Info($"战斗已销毁: ID={battleId}, 分片={shard.Id}");
+            return 0; // 成功
+        }
 
-                _battles.Remove(battleId);
-                BattleLogger.Info($"战斗已销毁: ID={battleId}");
-                return 0; // 成功
+        /// <summary>
+        /// 绑定环形缓冲区 (由 Go 调用)，两个参数都为 null 时解除绑定
+        /// </summary>
+        public static void AttachRingTransport(SharedRing? inputRing, SharedRing? outputRing)
+        {
+            lock (_lockObj)
+            {
+                lock (_outputLock)
+                {
+                    _inputRing = inputRing;
+                    _outputRing = outputRing;
+                }
+                BattleLogger.Info(inputRing == null ? "环形缓冲区已解除绑定" : "环形缓冲区已绑定");
             }
         }
 
         /// <summary>
-        /// Tick 驱动 - 执行所有进行中的战斗 (由 Go 调用)
+        /// 处理输入环形缓冲区中累积的 BattleContext，调用方需持有 _lockObj
+        /// 输入的 Tick 记为应用它的逻辑帧，与复算时的重放顺序一致
         /// </summary>
-        public static int OnTick()
+        private static void DrainInputRing(ulong tick)
+        {
+            _inputRing?.Drain(frame =>
+            {
+                try
+                {
+                    var ctx = BattleContext.Parser.ParseFrom(frame);
+                    ctx.Tick = tick;
+                    if (ctx.OptionCase == BattleContext.OptionOneofCase.BattleInput)
+                    {
+                        int code = ProcessBattleContextInput(ctx);
+                        if (code != (int)BattleErrorCode.Success)
+                        {
+                            BattleLogger.Warn($"环形缓冲区输入被拒绝: ID={ctx.BattleId}, 错误码={(BattleErrorCode)code}");
+                        }
+                    }
+                }
+                catch (Exception ex)
+                {
+                    // 单条输入异常不影响后续输入和战斗回合
+                    NativeAOTExceptionInjector.ReportException(ex, "OnTick", 0);
+                }
+            });
+        }
+
+        /// <summary>
+        /// Tick 驱动 - 执行所有进行中的战斗一个逻辑帧 (由 Go 调用)
+        /// 绑定环形缓冲区时先处理累积的输入，再按 ID 顺序执行所有分片
+        /// 输出的 BattleContext.Tick 为产生该输出的逻辑帧
+        /// </summary>
+        public static int OnTick(ulong frame)
         {
             lock (_lockObj)
+            {
+                DrainInputRing(frame);
+            }
+
+            int battleCount = 0;
+            foreach (var shard in OrderedShards())
+            {
+                battleCount += TickShard(shard, frame);
+            }
+            return battleCount; // 返回处理的战斗数
+        }
+
+        /// <summary>
+        /// 分片 Tick 驱动 - 只执行 shardId 分片中的战斗一个逻辑帧 (由 Go 调用)
+        /// 不处理环形缓冲区中的输入，不同分片可以在不同线程上并行调用
+        /// </summary>
+        public static int OnTickShard(uint shardId, ulong frame)
+        {
+            if (!_shards.TryGetValue(shardId, out var shard))
+            {
+                return 0;
+            }
+            return TickShard(shard, frame);
+        }
+
+        /// <summary>
+        /// 执行分片中所有进行中的战斗一个逻辑帧，返回处理的战斗数
+        /// </summary>
+        private static int TickShard(BattleShard shard, ulong frame)
+        {
+            lock (shard.Lock)
             {
                 int battleCount = 0;
-                List<uint> finishedBattles = new List<uint>();
+                int finishedCount = 0;
 
-                foreach (var kvp in _battles)
+                foreach (var kvp in shard.Battles)
                 {
                     uint battleId = kvp.Key;
                     BattleInstance battle = kvp.Value;
 
-                    if (!battle.IsFinished)
+                    // 暂停、结束和销毁的战斗不执行回合
+                    if (battle.State == BattleState.Running)
                     {
-                        battle.ExecuteRound(20, 50); // minDamage=20, maxDamage=50
-
-                        if (battle.IsFinished)
+                        try
                         {
-                            BattleLogger.Debug($"战斗结束开始处理");
-                            finishedBattles.Add(battleId);
-                            // 通知 Go 战斗结果并获取 BattleContext 输出
-                            if (_resultCallback != null)
-                            {
-                                BattleLogger.Debug($"结束 处理 回调地址: 0x{_resultCallback:X}");
-
-                                BattleContext ctx = new()
-                                {
-                                    BattleId = battleId,
-                                    BattleOutput = new BattleOutput()
-                                };
-                                BattleResult result = new()
-                                {
-                                    Winner = (uint)(battle.Winner ?? 0),
-                                };
-                                ctx.BattleOutput.Result = result;
-
-                                // 使用复用的缓冲区序列化数据
-                                var codedOutput = new Google.Protobuf.CodedOutputStream(_outputBuffer);
-                                ctx.WriteTo(codedOutput);
-                                codedOutput.Flush();
-                                int dataLen = (int)codedOutput.Position;
-                                
-
-                                BattleContext retctx = BattleContext.Parser.ParseFrom(_outputBuffer, 0, dataLen);
-                                BattleLogger.Debug($"战斗结果序列化完成: ID={retctx.BattleId}, 长度={dataLen} 字节");
-                       
-                                // 使用已钉住的缓冲区指针调用回调
-                                IntPtr bufferPtr = _bufferHandle.AddrOfPinnedObject();
-                                BattleLogger.Debug($"buffout: 0x{_outputBuffer:X} 战斗结数据地址:  0x{bufferPtr:X}, 长度={dataLen} 字节");
-                                BattleLogger.Debug($"战斗结果开始处理");
-                                int callbackResult = _resultCallback(bufferPtr, dataLen);
-                                BattleLogger.Debug($"战斗结果已处理: ID={battleId}, 输出长度={dataLen} 字节");
-                            }
+                            battle.ExecuteRound(20, 50, frame); // minDamage=20, maxDamage=50
+                        }
+                        catch (Exception ex)
+                        {
+                            // 单场战斗异常不中断其他战斗，通过全局异常回调报告给 Go
+                            NativeAOTExceptionInjector.ReportException(ex, "OnTick", battleId);
+                            continue;
                         }
-
                         battleCount++;
                     }
+
+                    // 强制结束的战斗在本帧记录结束事件
+                    battle.CompleteEnd(frame);
+
+                    if (battle.TakeResult())
+                    {
+                        BattleLogger.Debug($"战斗结束开始处理");
+                        finishedCount++;
+
+                        // 先输出结果，再输出包含全部回合事件的回放
+                        BattleResult result = battle.BuildResult();
+                        EmitOutput(shard, battleId, frame, new BattleOutput { Result = result });
+                        EmitOutput(shard, battleId, frame, new BattleOutput { Replay = battle.BuildReplay(result) });
+                    }
                 }
 
                 if (battleCount > 0)
                 {
-                    BattleLogger.Debug($"Tick: 处理 {battleCount} 场战斗, 完成 {finishedBattles.Count} 场");
+                    BattleLogger.Debug($"Tick {frame}: 分片 {shard.Id} 处理 {battleCount} 场战斗, 完成 {finishedCount} 场");
                 }
+                return battleCount;
+            }
+        }
 
-                return battleCount; // 返回处理的战斗数
+        /// <summary>
+        /// 发送一条战斗输出，Tick 为产生该输出的逻辑帧，调用方需持有 shard.Lock
+        /// 优先写入输出环形缓冲区，Go 在 OnTick 返回后读取；写满或未绑定时通过分片的输出缓冲区走结果回调
+        /// </summary>
+        private static void EmitOutput(BattleShard shard, uint battleId, ulong frame, BattleOutput output)
+        {
+            BattleContext ctx = new()
+            {
+                BattleId = battleId,
+                Tick = frame,
+                BattleOutput = output,
+            };
+
+            bool written;
+            lock (_outputLock)
+            {
+                written = _outputRing != null && _outputRing.TryWrite(ctx);
+            }
+
+            var callback = _resultCallback;
+            if (written)
+            {
+                BattleLogger.Debug($"战斗输出已写入环形缓冲区: ID={battleId}, 类型={output.OutputCase}");
+            }
+            else if (callback != null)
+            {
+                BattleLogger.Debug($"结束 处理 回调地址: 0x{callback:X}");
+                int callbackResult = shard.Notify(callback, ctx);
+                BattleLogger.Debug($"战斗输出已处理: ID={battleId}, 回调返回={callbackResult}");
             }
         }
 
@@ -321,9 +509,13 @@ namespace GoPureWithCsharp
         /// </summary>
         public static BattleInstance? GetBattle(uint battleId)
         {
-            lock (_lockObj)
+            if (!_battleShards.TryGetValue(battleId, out var shard))
             {
-                _battles.TryGetValue(battleId, out var battle);
+                return null;
+            }
+            lock (shard.Lock)
+            {
+                shard.Battles.TryGetValue(battleId, out var battle);
                 return battle;
             }
         }
@@ -332,18 +524,150 @@ namespace GoPureWithCsharp
         /// 获取所有战斗数量
         /// </summary>
         public static int GetBattleCount()
+        {
+            return _battleShards.Count;
+        }
+
+        /// <summary>
+        /// 查询战斗状态 (由 Go 调用)
+        /// battleId 为 0 时按分片顺序返回所有战斗，否则只返回该战斗 (不存在时列表为空)
+        /// </summary>
+        public static BattleStatusList ListStatus(uint battleId)
+        {
+            long now = DateTimeOffset.UtcNow.ToUnixTimeMilliseconds();
+            BattleStatusList list = new();
+            if (battleId == 0)
+            {
+                foreach (var shard in OrderedShards())
+                {
+                    lock (shard.Lock)
+                    {
+                        foreach (var battle in shard.Battles.Values)
+                        {
+                            list.Battles.Add(battle.ToStatus(now));
+                        }
+                    }
+                }
+            }
+            else if (_battleShards.TryGetValue(battleId, out var shard))
+            {
+                lock (shard.Lock)
+                {
+                    if (shard.Battles.TryGetValue(battleId, out var battle))
+                    {
+                        list.Battles.Add(battle.ToStatus(now));
+                    }
+                }
+            }
+            return list;
+        }
+
+        /// <summary>
+        /// 导出所有战斗的状态快照 (热重载时由 Go 调用)，快照记录每场战斗所在的分片
+        /// </summary>
+        public static BattleStateSnapshot ExportState()
         {
             lock (_lockObj)
             {
-                return _battles.Count;
+                BattleStateSnapshot snapshot = new()
+                {
+                    Timestamp = DateTimeOffset.UtcNow.ToUnixTimeMilliseconds()
+                };
+                foreach (var shard in OrderedShards())
+                {
+                    lock (shard.Lock)
+                    {
+                        foreach (var battle in shard.Battles.Values)
+                        {
+                            snapshot.Battles.Add(battle.ToSnapshot());
+                        }
+                    }
+                }
+
+                BattleLogger.Info($"战斗状态已导出: {snapshot.Battles.Count} 场, {_shards.Count} 个分片");
+                return snapshot;
+            }
+        }
+
+        /// <summary>
+        /// 从快照恢复战斗 (热重载时由 Go 调用)，每场战斗恢复到快照记录的分片
+        /// 已存在的战斗 ID 视为冲突，整个快照不导入
+        /// 返回: 导入的战斗数量, -1 表示冲突
+        /// </summary>
+        public static int ImportState(BattleStateSnapshot snapshot)
+        {
+            lock (_lockObj)
+            {
+                foreach (var battle in snapshot.Battles)
+                {
+                    if (_battleShards.ContainsKey(battle.BattleId))
+                    {
+                        BattleLogger.Error($"导入战斗状态失败: 战斗 ID={battle.BattleId} 已存在");
+                        return -1;
+                    }
+                }
+
+                foreach (var battle in snapshot.Battles)
+                {
+                    var shard = GetShard(battle.ShardId);
+                    lock (shard.Lock)
+                    {
+                        shard.Battles[battle.BattleId] = BattleInstance.FromSnapshot(battle);
+                        _battleShards[battle.BattleId] = shard;
+                    }
+                }
+
+                BattleLogger.Info($"战斗状态已导入: {snapshot.Battles.Count} 场");
+                return snapshot.Battles.Count;
             }
         }
-    
+
+        /// <summary>
+        /// 处理一条 BattleContext 输入，只锁定战斗所在的分片
+        /// 返回: BattleErrorCode (0 成功)
+        /// </summary>
         public static int ProcessBattleContextInput(BattleContext ctx)
         {
-            GetBattle(ctx.BattleId)?.ProcessInput(ctx);
+            if (!_battleShards.TryGetValue(ctx.BattleId, out var shard))
+            {
+                return (int)BattleErrorCode.BattleNotFound;
+            }
+            lock (shard.Lock)
+            {
+                if (!shard.Battles.TryGetValue(ctx.BattleId, out var battle))
+                {
+                    return (int)BattleErrorCode.BattleNotFound;
+                }
+                return (int)battle.ProcessInput(ctx, _effects);
+            }
+        }
 
-            return 0;
+        /// <summary>
+        /// 批量处理 BattleContext 输入 (由 Go 调用)
+        /// 每条输入的结果以 BattleErrorCode 写入 codes，顺序与 batch.Contexts 一致
+        /// </summary>
+        public static void ProcessBattleContextBatch(BattleContextBatch batch, Span<int> codes)
+        {
+            for (int i = 0; i < batch.Contexts.Count; i++)
+            {
+                var ctx = batch.Contexts[i];
+                if (ctx.OptionCase != BattleContext.OptionOneofCase.BattleInput)
+                {
+                    codes[i] = (int)BattleErrorCode.InvalidRequest;
+                    continue;
+                }
+
+                try
+                {
+                    codes[i] = ProcessBattleContextInput(ctx);
+                }
+                catch (Exception ex)
+                {
+                    // 单条输入异常不影响同批次的其他输入
+                    NativeAOTExceptionInjector.ReportException(ex, "ProcessBattleContextBatch", ctx.BattleId);
+                    codes[i] = (int)BattleErrorCode.InternalError;
+                }
+            }
         }
     }
 }
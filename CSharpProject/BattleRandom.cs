@@ -0,0 +1,49 @@
+namespace GoPureWithCsharp
+{
+    /// <summary>
+    /// 每场战斗独立的确定性随机数生成器 (SplitMix64)
+    /// 算法与 Go 侧 csharp/battle_rand.go 一致，相同种子在两侧产生相同的序列，
+    /// 因此 Go 可以用 SimEngine 复算这里进行的战斗
+    /// </summary>
+    public sealed class BattleRandom
+    {
+        private const ulong Gamma = 0x9E3779B97F4A7C15UL;
+
+        /// <summary>
+        /// 当前状态，随战斗快照迁移 (热重载)
+        /// </summary>
+        public ulong State { get; set; }
+
+        public BattleRandom(ulong seed)
+        {
+            State = seed;
+        }
+
+        /// <summary>
+        /// 返回下一个 64 位随机数
+        /// </summary>
+        public ulong NextUInt64()
+        {
+            unchecked
+            {
+                State += Gamma;
+                ulong z = State;
+                z = (z ^ (z >> 30)) * 0xBF58476D1CE4E5B9UL;
+                z = (z ^ (z >> 27)) * 0x94D049BB133111EBUL;
+                return z ^ (z >> 31);
+            }
+        }
+
+        /// <summary>
+        /// 返回 [minValue, maxValue) 内的随机数，语义与 System.Random.Next(min, max) 相同
+        /// </summary>
+        public int Next(int minValue, int maxValue)
+        {
+            if (maxValue <= minValue)
+            {
+                return minValue;
+            }
+            return minValue + (int)(NextUInt64() % (ulong)(maxValue - minValue));
+        }
+    }
+}
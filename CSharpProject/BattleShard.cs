@@ -0,0 +1,51 @@
+using System.Collections.Generic;
+using System.Runtime.InteropServices;
+using GoPureWithCsharp.Battle;
+
+namespace GoPureWithCsharp
+{
+    /// <summary>
+    /// 战斗分片 - BattleManager 按分片划分战斗
+    /// 每个分片有自己的锁和输出缓冲区，不同分片的 OnTickShard、输入和状态查询互不阻塞
+    /// 分片在第一次使用时创建，之后不会删除
+    /// </summary>
+    public sealed class BattleShard
+    {
+        public uint Id { get; }
+
+        /// <summary>
+        /// 保护 Battles 和分片内所有战斗实例
+        /// </summary>
+        public object Lock { get; } = new object();
+
+        /// <summary>
+        /// 分片中的战斗，按创建顺序执行
+        /// </summary>
+        public Dictionary<uint, BattleInstance> Battles { get; } = new Dictionary<uint, BattleInstance>();
+
+        // 复用的输出缓冲区，避免频繁分配；只在持有 Lock 时使用
+        private readonly byte[] _outputBuffer = new byte[20480];
+        private readonly GCHandle _bufferHandle;
+
+        public BattleShard(uint id)
+        {
+            Id = id;
+            _bufferHandle = GCHandle.Alloc(_outputBuffer, GCHandleType.Pinned);
+        }
+
+        /// <summary>
+        /// 把战斗输出序列化到分片的输出缓冲区，并以已钉住的缓冲区指针调用结果回调
+        /// 返回回调的返回值
+        /// </summary>
+        public int Notify(BattleResultCallback callback, BattleContext ctx)
+        {
+            var codedOutput = new Google.Protobuf.CodedOutputStream(_outputBuffer);
+            ctx.WriteTo(codedOutput);
+            codedOutput.Flush();
+            int dataLen = (int)codedOutput.Position;
+
+            BattleLogger.Debug($"战斗输出序列化完成: ID={ctx.BattleId}, 分片={Id}, 类型={ctx.BattleOutput.OutputCase}, 长度={dataLen} 字节");
+            return callback(_bufferHandle.AddrOfPinnedObject(), dataLen);
+        }
+    }
+}
@@ -0,0 +1,165 @@
+using System;
+using System.Collections.Generic;
+using System.Text.Json;
+using GoPureWithCsharp.Battle;
+
+namespace GoPureWithCsharp
+{
+    /// <summary>
+    /// 单位初始属性表 - 由 unit_config.json 驱动
+    /// 每个单位的初始属性为默认值 (defaultHealth / defaultAttack / defaultDefense)，units 中按单位 ID 配置的属性覆盖默认值
+    /// 阵容为空的队伍整个作为一个单位 (单位 ID 为队伍 ID，300 血、无攻击和防御加成)，与旧版本的战斗一致
+    /// 算法与 Go 侧 csharp/battle_unit.go 一致，SimEngine 复算时得到相同的单位和回放事件
+    /// </summary>
+    public sealed class UnitTable
+    {
+        public const string UnitConfig = "unit_config.json";
+
+        // 未加载 unit_config.json 时的默认值，与 config/unit_config.json 一致
+        private int _maxUnits = 100;
+        private int _defaultHealth = 100;
+        private int _defaultAttack = 10;
+        private int _defaultDefense = 5;
+        private Dictionary<uint, List<(uint AttributeId, int Value)>> _units = new();
+
+        /// <summary>
+        /// 解析 unit_config.json，其他配置忽略；解析失败时抛出异常，单位表保持不变
+        /// </summary>
+        public void Load(string configName, byte[] data)
+        {
+            if (configName != UnitConfig) return;
+
+            using var doc = JsonDocument.Parse(data);
+            var root = doc.RootElement;
+            var cfg = root.GetProperty("unitConfig");
+            int maxUnits = ReadInt(cfg, "maxUnits");
+            int defaultHealth = ReadInt(cfg, "defaultHealth");
+            int defaultAttack = ReadInt(cfg, "defaultAttack");
+            int defaultDefense = ReadInt(cfg, "defaultDefense");
+            if (maxUnits <= 0 || defaultHealth <= 0 || defaultAttack < 0 || defaultDefense < 0)
+            {
+                throw new InvalidDataException($"{UnitConfig}: 默认属性无效");
+            }
+
+            var units = new Dictionary<uint, List<(uint, int)>>();
+            if (root.TryGetProperty("units", out var rows))
+            {
+                foreach (var row in rows.EnumerateArray())
+                {
+                    uint unitId = row.GetProperty("unitId").GetUInt32();
+                    if (unitId == 0 || units.ContainsKey(unitId))
+                    {
+                        throw new InvalidDataException($"{UnitConfig}: 单位 ID {unitId} 为 0 或重复");
+                    }
+                    var attributes = new List<(uint, int)>();
+                    if (row.TryGetProperty("attributes", out var attrRows))
+                    {
+                        foreach (var attr in attrRows.EnumerateArray())
+                        {
+                            uint attributeId = attr.GetProperty("attributeId").GetUInt32();
+                            int value = ReadInt(attr, "value");
+                            bool valid = (attributeId == BattleEffectTable.AttributeMaxHealth && value > 0)
+                                || (attributeId == BattleEffectTable.AttributeAttack && value >= 0);
+                            if (!valid)
+                            {
+                                throw new InvalidDataException($"{UnitConfig}: 单位 {unitId} 的属性 {attributeId} = {value} 无效 (只能配置 3001 最大生命和 3002 攻击力)");
+                            }
+                            attributes.Add((attributeId, value));
+                        }
+                    }
+                    units[unitId] = attributes;
+                }
+            }
+
+            _maxUnits = maxUnits;
+            _defaultHealth = defaultHealth;
+            _defaultAttack = defaultAttack;
+            _defaultDefense = defaultDefense;
+            _units = units;
+        }
+
+        private static int ReadInt(JsonElement element, string name) =>
+            element.TryGetProperty(name, out var v) ? v.GetInt32() : 0;
+
+        /// <summary>
+        /// 按双方阵容创建单位，攻击方在前
+        /// 单位 ID 为 0 或在同一场战斗中重复时返回 InvalidRequest，单位总数超过 maxUnits 时返回 InvalidTeamSize
+        /// </summary>
+        public BattleErrorCode CreateUnits(Team atk, Team def, out List<BattleUnit> units)
+        {
+            units = new List<BattleUnit>();
+            if (atk.Lineup.Count + def.Lineup.Count > _maxUnits)
+            {
+                return BattleErrorCode.InvalidTeamSize;
+            }
+
+            var seen = new HashSet<uint>();
+            foreach (var team in new[] { atk, def })
+            {
+                if (team.Lineup.Count == 0)
+                {
+                    // 整个队伍作为一个单位
+                    if (!seen.Add(team.TeamId)) return BattleErrorCode.InvalidRequest;
+                    units.Add(TeamUnit(team.TeamId, BattleInstance.DefaultHealth, BattleInstance.DefaultHealth, 0));
+                    continue;
+                }
+                foreach (uint unitId in team.Lineup)
+                {
+                    if (unitId == 0 || !seen.Add(unitId)) return BattleErrorCode.InvalidRequest;
+                    units.Add(CreateUnit(unitId, team.TeamId));
+                }
+            }
+            return BattleErrorCode.Success;
+        }
+
+        /// <summary>
+        /// 以默认属性创建单位，再应用按单位 ID 配置的属性
+        /// </summary>
+        private BattleUnit CreateUnit(uint unitId, uint teamId)
+        {
+            var unit = new BattleUnit
+            {
+                UnitId = unitId,
+                TeamId = teamId,
+                MaxHealth = _defaultHealth,
+                Attack = _defaultAttack,
+                Defense = _defaultDefense,
+            };
+            if (_units.TryGetValue(unitId, out var attributes))
+            {
+                foreach (var (attributeId, value) in attributes)
+                {
+                    switch (attributeId)
+                    {
+                        case BattleEffectTable.AttributeMaxHealth:
+                            unit.MaxHealth = value;
+                            break;
+                        case BattleEffectTable.AttributeAttack:
+                            unit.Attack = value;
+                            break;
+                    }
+                }
+            }
+            unit.Health = unit.MaxHealth;
+            return unit;
+        }
+
+        /// <summary>
+        /// 整个队伍作为一个单位，单位 ID 为队伍 ID (旧版本快照也按此恢复)
+        /// </summary>
+        public static BattleUnit TeamUnit(uint teamId, int health, int maxHealth, int attack) => new BattleUnit
+        {
+            UnitId = teamId,
+            TeamId = teamId,
+            MaxHealth = maxHealth,
+            Health = health,
+            Attack = attack,
+        };
+
+        /// <summary>
+        /// 一次攻击的伤害: 随机伤害 + 攻击力 - 目标防御力，至少为 1
+        /// </summary>
+        public static int Damage(int roll, BattleUnit attacker, BattleUnit target) =>
+            Math.Max(roll + attacker.Attack - target.Defense, 1);
+    }
+}
@@ -14,6 +14,73 @@ namespace GoPureWithCsharp
         private const int SINGLE_RESPONSE_BUFFER_SIZE = 10240;
         private const int BATCH_RESPONSE_BUFFER_SIZE = 102400;
 
+        // 超出缓冲区的响应按调用线程暂存，等待 Go 在同一线程以相同请求扩容重试
+        // Go 在协商期间锁定 OS 线程，并发调用各自使用自己线程的暂存区，不会互相覆盖
+        [ThreadStatic] private static byte[]? _pendingRequest;
+        [ThreadStatic] private static byte[]? _pendingResponse;
+
+        /// <summary>
+        /// 读取 Go 写入 *response_len 的缓冲区容量
+        /// 未写入 (旧调用方) 时使用默认大小
+        /// </summary>
+        private static int ReadResponseCapacity(IntPtr responseLenPtr, int defaultSize)
+        {
+            int capacity = Marshal.ReadInt32(responseLenPtr);
+            return capacity > 0 ? capacity : defaultSize;
+        }
+
+        /// <summary>
+        /// 写入响应数据和长度
+        /// 响应超过容量时不写数据，*response_len 写入所需长度并暂存响应，返回 false
+        /// </summary>
+        private static bool WriteResponse(byte[] requestData, byte[] responseData, int capacity, IntPtr responseBufferPtr, IntPtr responseLenPtr)
+        {
+            if (responseData.Length > capacity)
+            {
+                Console.WriteLine($"[Export] 响应大小 {responseData.Length} 超过缓冲区 {capacity}，等待 Go 扩容重试");
+                _pendingRequest = requestData;
+                _pendingResponse = responseData;
+                Marshal.WriteInt32(responseLenPtr, responseData.Length);
+                return false;
+            }
+
+            Marshal.Copy(responseData, 0, responseBufferPtr, responseData.Length);
+            Marshal.WriteInt32(responseLenPtr, responseData.Length);
+            return true;
+        }
+
+        /// <summary>
+        /// 相同请求重试时取出本线程暂存的响应，避免重复执行战斗
+        /// 本线程的新请求与暂存的不同时，说明 Go 已放弃重试，丢弃暂存的响应
+        /// </summary>
+        private static bool TryTakePendingResponse(byte[] requestData, int capacity, IntPtr responseBufferPtr, IntPtr responseLenPtr)
+        {
+            byte[]? pendingRequest = _pendingRequest;
+            byte[]? responseData = _pendingResponse;
+            _pendingRequest = null;
+            _pendingResponse = null;
+            if (pendingRequest == null || !pendingRequest.AsSpan().SequenceEqual(requestData))
+            {
+                return false;
+            }
+
+            WriteResponse(requestData, responseData!, capacity, responseBufferPtr, responseLenPtr);
+            return true;
+        }
+
+        /// <summary>
+        /// 丢弃调用线程暂存的响应 (Go 放弃扩容重试时在同一线程调用)
+        ///
+        /// 函数签名 (C 风格):
+        /// void DiscardPendingResponse();
+        /// </summary>
+        [UnmanagedCallersOnly(CallConvs = new[] { typeof(System.Runtime.CompilerServices.CallConvCdecl) }, EntryPoint = "DiscardPendingResponse")]
+        public static void DiscardPendingResponse()
+        {
+            _pendingRequest = null;
+            _pendingResponse = null;
+        }
+
         /// <summary>
         /// 处理单个 Protobuf 消息
         /// 
@@ -22,12 +89,13 @@ namespace GoPureWithCsharp
         ///     const uint8_t* request_data,
         ///     int32_t request_len,
         ///     uint8_t* response_buffer,
-        ///     int32_t* response_len
+        ///     int32_t* response_len      // 入: 缓冲区容量  出: 响应长度 (大于容量时为所需长度)
         /// );
         /// </summary>
         [UnmanagedCallersOnly(CallConvs = new[] { typeof(System.Runtime.CompilerServices.CallConvCdecl) }, EntryPoint = "ProcessProtoMessage")]
         public static void ProcessProtoMessage(IntPtr requestDataPtr, int requestLen, IntPtr responseBufferPtr, IntPtr responseLenPtr)
         {
+            int capacity = ReadResponseCapacity(responseLenPtr, SINGLE_RESPONSE_BUFFER_SIZE);
             try
             {
                 Console.WriteLine($"[Export] ProcessProtoMessage 被调用, 请求长度={requestLen}");
@@ -36,6 +104,12 @@ namespace GoPureWithCsharp
                 byte[] requestData = new byte[requestLen];
                 Marshal.Copy(requestDataPtr, requestData, 0, requestLen);
 
+                // 扩容重试：直接返回暂存的响应
+                if (TryTakePendingResponse(requestData, capacity, responseBufferPtr, responseLenPtr))
+                {
+                    return;
+                }
+
                 // 尝试解析为 StartBattle 请求
                 BattleResponse response;
 
@@ -74,26 +148,18 @@ namespace GoPureWithCsharp
                         Code = (int)BattleErrorCode.InternalError,
                         Message = $"内部错误: {ex.Message}",
                         Timestamp = DateTimeOffset.Now.ToUnixTimeMilliseconds(),
+                        Exception = NativeAOTExceptionInjector.BuildReport(ex, "ProcessProtoMessage", 0),
                     };
                 }
 
                 // 序列化响应
                 byte[] responseData = response.ToByteArray();
 
-                // 检查缓冲区大小
-                if (responseData.Length > SINGLE_RESPONSE_BUFFER_SIZE)
+                // 写入响应数据 (超出缓冲区时只回报所需长度)
+                if (WriteResponse(requestData, responseData, capacity, responseBufferPtr, responseLenPtr))
                 {
-                    Console.WriteLine($"[Export] 警告: 响应大小 {responseData.Length} 超过缓冲区 {SINGLE_RESPONSE_BUFFER_SIZE}");
-                    // 在实际应用中，这里应该返回错误或使用动态分配
-                    Array.Resize(ref responseData, SINGLE_RESPONSE_BUFFER_SIZE);
+                    Console.WriteLine($"[Export] 响应已发送, 长度={responseData.Length}");
                 }
-
-                // 写入响应数据
-                Marshal.Copy(responseData, 0, responseBufferPtr, responseData.Length);
-
-                // 设置响应长度
-                Marshal.WriteInt32(responseLenPtr, responseData.Length);
-                Console.WriteLine($"[Export] 响应已发送, 长度={responseData.Length}");
             }
             catch (Exception ex)
             {
@@ -104,9 +170,10 @@ namespace GoPureWithCsharp
                     Code = (int)BattleErrorCode.InternalError,
                     Message = $"处理异常: {ex.Message}",
                     Timestamp = DateTimeOffset.Now.ToUnixTimeMilliseconds(),
+                    Exception = NativeAOTExceptionInjector.BuildReport(ex, "ProcessProtoMessage", 0),
                 }.ToByteArray();
 
-                if (errorResponse.Length <= SINGLE_RESPONSE_BUFFER_SIZE)
+                if (errorResponse.Length <= capacity)
                 {
                     Marshal.Copy(errorResponse, 0, responseBufferPtr, errorResponse.Length);
                     Marshal.WriteInt32(responseLenPtr, errorResponse.Length);
@@ -122,12 +189,13 @@ namespace GoPureWithCsharp
         ///     const uint8_t* request_data,
         ///     int32_t request_len,
         ///     uint8_t* response_buffer,
-        ///     int32_t* response_len
+        ///     int32_t* response_len      // 入: 缓冲区容量  出: 响应长度 (大于容量时为所需长度)
         /// );
         /// </summary>
         [UnmanagedCallersOnly(CallConvs = new[] { typeof(System.Runtime.CompilerServices.CallConvCdecl) }, EntryPoint = "ProcessBatchProtoMessage")]
         public static void ProcessBatchProtoMessage(IntPtr requestDataPtr, int requestLen, IntPtr responseBufferPtr, IntPtr responseLenPtr)
         {
+            int capacity = ReadResponseCapacity(responseLenPtr, BATCH_RESPONSE_BUFFER_SIZE);
             try
             {
                 Console.WriteLine($"[Export] ProcessBatchProtoMessage 被调用, 请求长度={requestLen}");
@@ -136,6 +204,12 @@ namespace GoPureWithCsharp
                 byte[] requestData = new byte[requestLen];
                 Marshal.Copy(requestDataPtr, requestData, 0, requestLen);
 
+                // 扩容重试：直接返回暂存的响应
+                if (TryTakePendingResponse(requestData, capacity, responseBufferPtr, responseLenPtr))
+                {
+                    return;
+                }
+
                 BattleResponse response;
 
                 try
@@ -173,25 +247,18 @@ namespace GoPureWithCsharp
                         Code = (int)BattleErrorCode.InternalError,
                         Message = $"内部错误: {ex.Message}",
                         Timestamp = DateTimeOffset.Now.ToUnixTimeMilliseconds(),
+                        Exception = NativeAOTExceptionInjector.BuildReport(ex, "ProcessBatchProtoMessage", 0),
                     };
                 }
 
                 // 序列化响应
                 byte[] responseData = response.ToByteArray();
 
-                // 检查缓冲区大小
-                if (responseData.Length > BATCH_RESPONSE_BUFFER_SIZE)
+                // 写入响应数据 (超出缓冲区时只回报所需长度)
+                if (WriteResponse(requestData, responseData, capacity, responseBufferPtr, responseLenPtr))
                 {
-                    Console.WriteLine($"[Export] 警告: 响应大小 {responseData.Length} 超过缓冲区 {BATCH_RESPONSE_BUFFER_SIZE}");
-                    Array.Resize(ref responseData, BATCH_RESPONSE_BUFFER_SIZE);
+                    Console.WriteLine($"[Export] 批量响应已发送, 长度={responseData.Length}");
                 }
-
-                // 写入响应数据
-                Marshal.Copy(responseData, 0, responseBufferPtr, responseData.Length);
-
-                // 设置响应长度
-                Marshal.WriteInt32(responseLenPtr, responseData.Length);
-                Console.WriteLine($"[Export] 批量响应已发送, 长度={responseData.Length}");
             }
             catch (Exception ex)
             {
@@ -201,9 +268,10 @@ namespace GoPureWithCsharp
                     Code = (int)BattleErrorCode.InternalError,
                     Message = $"处理异常: {ex.Message}",
                     Timestamp = DateTimeOffset.Now.ToUnixTimeMilliseconds(),
+                    Exception = NativeAOTExceptionInjector.BuildReport(ex, "ProcessBatchProtoMessage", 0),
                 }.ToByteArray();
 
-                if (errorResponse.Length <= BATCH_RESPONSE_BUFFER_SIZE)
+                if (errorResponse.Length <= capacity)
                 {
                     Marshal.Copy(errorResponse, 0, responseBufferPtr, errorResponse.Length);
                     Marshal.WriteInt32(responseLenPtr, errorResponse.Length);
@@ -272,7 +340,37 @@ namespace GoPureWithCsharp
         [UnmanagedCallersOnly(CallConvs = new[] { typeof(System.Runtime.CompilerServices.CallConvCdecl) }, EntryPoint = "GetLibVersion")]
         public static IntPtr GetLibVersion()
         {
-            return Marshal.StringToHGlobalAnsi("goPureWithCsharp-1.0");
+            return Marshal.StringToHGlobalAnsi(LibraryCapabilities.Version);
+        }
+
+        /// <summary>
+        /// 获取库信息 (ABI 版本、proto schema 哈希、可选特性)，Go 加载库时用于握手
+        ///
+        /// 函数签名 (C 风格):
+        /// void GetLibInfo(
+        ///     uint8_t* buffer,
+        ///     int32_t* buffer_len        // 入: 缓冲区容量  出: LibraryInfo 长度 (大于容量时为所需长度, -1 表示失败)
+        /// );
+        /// </summary>
+        [UnmanagedCallersOnly(CallConvs = new[] { typeof(System.Runtime.CompilerServices.CallConvCdecl) }, EntryPoint = "GetLibInfo")]
+        public static void GetLibInfo(IntPtr bufferPtr, IntPtr bufferLenPtr)
+        {
+            try
+            {
+                int capacity = Marshal.ReadInt32(bufferLenPtr);
+                byte[] data = LibraryCapabilities.GetInfo().ToByteArray();
+
+                if (data.Length <= capacity)
+                {
+                    Marshal.Copy(data, 0, bufferPtr, data.Length);
+                }
+                Marshal.WriteInt32(bufferLenPtr, data.Length);
+            }
+            catch (Exception ex)
+            {
+                Console.WriteLine($"[Export] GetLibInfo 异常: {ex}");
+                Marshal.WriteInt32(bufferLenPtr, -1);
+            }
         }
 
         /// <summary>
@@ -352,6 +450,7 @@ namespace GoPureWithCsharp
         /// </summary>
         /// <summary>
         /// 注册配置加载器 (由 Go 调用)
+        /// configLoaderPtr 为 NULL 时注销已注册的加载器
         /// </summary>
         [UnmanagedCallersOnly(CallConvs = new[] { typeof(System.Runtime.CompilerServices.CallConvCdecl) }, EntryPoint = "RegisterConfigLoader")]
         public static void RegisterConfigLoader(IntPtr configLoaderPtr)
@@ -360,7 +459,8 @@ namespace GoPureWithCsharp
             
             if (configLoaderPtr == IntPtr.Zero)
             {
-                System.Console.WriteLine("[Export-RC] 2. 参数为 NULL，返回");
+                System.Console.WriteLine("[Export-RC] 2. 参数为 NULL，注销配置加载器");
+                BattleManager.RegisterConfigLoader(null);
                 return;
             }
 
@@ -385,7 +485,8 @@ namespace GoPureWithCsharp
             Marshal.Copy(configNamePtr, configNameBytes, 0, configNameLen);
             string configName = System.Text.Encoding.UTF8.GetString(configNameBytes);
 
-            return BattleManager.LoadConfig(configName);
+            return NativeAOTExceptionInjector.WrapExportFunction("LoadConfig", 0,
+                () => BattleManager.LoadConfig(configName), (int)NativeAOTExceptionInjector.NativeErrorCode.SystemError);
         }
 
         /// <summary>
@@ -440,14 +541,15 @@ namespace GoPureWithCsharp
 
         /// <summary>
         /// 注册战斗结果回调 (由 Go 调用)
-        /// 参数: callbackPtr - 指向结果回调函数的指针
+        /// 参数: callbackPtr - 指向结果回调函数的指针，为 NULL 时注销已注册的回调
         /// </summary>
         [UnmanagedCallersOnly(CallConvs = new[] { typeof(System.Runtime.CompilerServices.CallConvCdecl) }, EntryPoint = "RegisterBattleResultCallback")]
         public static int RegisterBattleResultCallback(IntPtr callbackPtr)
         {
             if (callbackPtr == IntPtr.Zero)
             {
-                return -1;
+                BattleManager.RegisterResultCallback(null);
+                return 0;
             }
 
             System.Console.WriteLine($"[Export] RegisterBattleResultCallback 被调用 地址 0x{callbackPtr:X}");
@@ -458,40 +560,102 @@ namespace GoPureWithCsharp
 
         /// <summary>
         /// 创建战斗 (由 Go 调用)
-        /// 参数: battleId, atkTeamId, defTeamId
-        /// 返回: 0 成功, -1 失败
+        /// 参数: 序列化的 BattleEnv (battle_id、双方队伍和阵容、seed 战斗随机种子)
+        /// 返回: 0 成功, -1 战斗 ID 冲突, -2 数据格式错误, -3 托管异常 (通过 TakeManagedException 取回),
+        ///       正数为 BattleEnv 被拒绝的 BattleErrorCode (例如 INVALID_TEAM_SIZE)
         /// </summary>
         [UnmanagedCallersOnly(CallConvs = new[] { typeof(System.Runtime.CompilerServices.CallConvCdecl) }, EntryPoint = "CreateBattle")]
-        public static int CreateBattle(uint battleId, uint atkTeamId, uint defTeamId)
+        public static unsafe int CreateBattle(IntPtr envPtr, int envLen)
         {
-            return BattleManager.CreateBattlee(battleId, atkTeamId, defTeamId);
+            return NativeAOTExceptionInjector.WrapExportFunction("CreateBattle", 0, () =>
+            {
+                Battle.BattleEnv env;
+                try
+                {
+                    env = Battle.BattleEnv.Parser.ParseFrom(new ReadOnlySpan<byte>((void*)envPtr, envLen));
+                }
+                catch (InvalidProtocolBufferException)
+                {
+                    return -2;
+                }
+                return BattleManager.CreateBattlee(env);
+            }, (int)NativeAOTExceptionInjector.NativeErrorCode.SystemError);
+        }
+
+        /// <summary>
+        /// 在指定分片中创建战斗 (由 Go 调用)，战斗 ID 在所有分片中唯一
+        /// 参数: shardId - 分片 ID, 序列化的 BattleEnv
+        /// 返回: 与 CreateBattle 相同
+        /// </summary>
+        [UnmanagedCallersOnly(CallConvs = new[] { typeof(System.Runtime.CompilerServices.CallConvCdecl) }, EntryPoint = "CreateShardBattle")]
+        public static unsafe int CreateShardBattle(uint shardId, IntPtr envPtr, int envLen)
+        {
+            return NativeAOTExceptionInjector.WrapExportFunction("CreateShardBattle", 0, () =>
+            {
+                Battle.BattleEnv env;
+                try
+                {
+                    env = Battle.BattleEnv.Parser.ParseFrom(new ReadOnlySpan<byte>((void*)envPtr, envLen));
+                }
+                catch (InvalidProtocolBufferException)
+                {
+                    return -2;
+                }
+                return BattleManager.CreateBattlee(shardId, env);
+            }, (int)NativeAOTExceptionInjector.NativeErrorCode.SystemError);
         }
 
+        /// <summary>
+        /// 旧版本创建战斗入口，双方阵容为空 (各作为一个单位)
+        /// </summary>
         [UnmanagedCallersOnly(CallConvs = new[] { typeof(System.Runtime.CompilerServices.CallConvCdecl) }, EntryPoint = "CreateBattleByCtx")]
-        public static int CreateBattleByCtx(uint battleId, uint atkTeamId, uint defTeamId)
+        public static int CreateBattleByCtx(uint battleId, uint atkTeamId, uint defTeamId, ulong seed)
         {
-            return BattleManager.CreateBattlee(battleId, atkTeamId, defTeamId);
+            return BattleManager.CreateBattlee(new Battle.BattleEnv
+            {
+                BattleId = battleId,
+                Atk = new Battle.Team { TeamId = atkTeamId },
+                Def = new Battle.Team { TeamId = defTeamId },
+                Seed = seed,
+            });
         }
 
         /// <summary>
         /// 销毁战斗 (由 Go 调用)
         /// 参数: battleId
-        /// 返回: 0 成功, -1 失败
+        /// 返回: 0 成功, -1 失败, -3 托管异常
         /// </summary>
         [UnmanagedCallersOnly(CallConvs = new[] { typeof(System.Runtime.CompilerServices.CallConvCdecl) }, EntryPoint = "DestroyBattle")]
         public static int DestroyBattle(uint battleId)
         {
-            return BattleManager.DestroyBattle(battleId);
+            return NativeAOTExceptionInjector.WrapExportFunction("DestroyBattle", battleId,
+                () => BattleManager.DestroyBattle(battleId), (int)NativeAOTExceptionInjector.NativeErrorCode.SystemError);
         }
 
         /// <summary>
-        /// Tick 驱动 - 推动所有战斗进行 (由 Go 调用)
-        /// 返回: 处理的战斗数量
+        /// Tick 驱动 - 推动所有战斗进行一个逻辑帧 (由 Go 调用)
+        /// 参数: frame - 逻辑帧序号，本帧产生的输出 BattleContext.Tick 为该值
+        /// 返回: 处理的战斗数量, -3 托管异常
+        /// 单场战斗抛出的异常通过全局异常回调报告，不中断其他战斗
         /// </summary>
         [UnmanagedCallersOnly(CallConvs = new[] { typeof(System.Runtime.CompilerServices.CallConvCdecl) }, EntryPoint = "OnTick")]
-        public static int OnTick()
+        public static int OnTick(ulong frame)
         {
-            return BattleManager.OnTick();
+            return NativeAOTExceptionInjector.WrapExportFunction("OnTick", 0,
+                () => BattleManager.OnTick(frame), (int)NativeAOTExceptionInjector.NativeErrorCode.SystemError);
+        }
+
+        /// <summary>
+        /// 分片 Tick 驱动 - 只推动 shardId 分片中的战斗进行一个逻辑帧 (由 Go 调用)
+        /// 参数: shardId - 分片 ID, frame - 逻辑帧序号
+        /// 返回: 处理的战斗数量, -3 托管异常
+        /// 不处理环形缓冲区中的输入，不同分片可以在不同线程上并行调用
+        /// </summary>
+        [UnmanagedCallersOnly(CallConvs = new[] { typeof(System.Runtime.CompilerServices.CallConvCdecl) }, EntryPoint = "OnTickShard")]
+        public static int OnTickShard(uint shardId, ulong frame)
+        {
+            return NativeAOTExceptionInjector.WrapExportFunction("OnTickShard", 0,
+                () => BattleManager.OnTickShard(shardId, frame), (int)NativeAOTExceptionInjector.NativeErrorCode.SystemError);
         }
 
         /// <summary>
@@ -507,29 +671,191 @@ namespace GoPureWithCsharp
         /// <summary>
         /// 处理战斗输入 (由 Go 调用)
         /// 参数: battleId, teamId, actionType, actionValue
-        /// 返回: 0 成功, 负数表示错误码
+        /// 返回: 0 成功, 负数表示错误码 (托管异常时为 -3，通过 TakeManagedException 取回)
         /// </summary>
         [UnmanagedCallersOnly(CallConvs = new[] { typeof(System.Runtime.CompilerServices.CallConvCdecl) }, EntryPoint = "ProcessBattleInput")]
         public static int ProcessBattleInput(uint battleId, uint teamId, byte actionType, int actionValue)
         {
-            return BattleInputHandler.ProcessBattleInput(battleId, teamId, actionType, actionValue);
+            return NativeAOTExceptionInjector.WrapExportFunction("ProcessBattleInput", battleId,
+                () => BattleInputHandler.ProcessBattleInput(battleId, teamId, actionType, actionValue), (int)NativeAOTExceptionInjector.NativeErrorCode.SystemError);
         }
 
+        /// <summary>
+        /// 处理序列化的 BattleContext 输入 (由 Go 调用)
+        /// 返回: 0 成功, -1 无效输入, -3 托管异常, 正数为输入被拒绝的 BattleErrorCode (例如 INVALID_BATTLE_STATE)
+        /// </summary>
         [UnmanagedCallersOnly(CallConvs = new[] { typeof(System.Runtime.CompilerServices.CallConvCdecl) }, EntryPoint = "ProcessBattleContextInput")]
         public static int ProcessBattleContextInput(IntPtr buffPtr, int buffLen)
         {
+            return NativeAOTExceptionInjector.WrapExportFunction("ProcessBattleContextInput", 0, () =>
+            {
+                // 从指针读取字符串
+                byte[] inputBytes = new byte[buffLen];
+                Marshal.Copy(buffPtr, inputBytes, 0, buffLen);
+
+                var battleInputContext = Battle.BattleContext.Parser.ParseFrom(inputBytes);
+                if (battleInputContext == null || battleInputContext.OptionCase != Battle.BattleContext.OptionOneofCase.BattleInput)
+                {
+                    return -1; // 无效输入
+                }
+
+                return BattleManager.ProcessBattleContextInput(battleInputContext);
+            }, (int)NativeAOTExceptionInjector.NativeErrorCode.SystemError);
+        }
+
+        /// <summary>
+        /// 批量处理 BattleContext 输入
+        ///
+        /// 函数签名 (C 风格):
+        /// int32_t ProcessBattleContextBatch(
+        ///     const uint8_t* batch, int32_t batch_len,   // 序列化的 BattleContextBatch
+        ///     int32_t* codes, int32_t codes_cap          // 出: 每条输入的 BattleErrorCode
+        /// );
+        /// 返回: 处理的条数, -1 数据格式错误, -2 codes 容量不足, -3 托管异常 (通过 TakeManagedException 取回)
+        /// </summary>
+        [UnmanagedCallersOnly(CallConvs = new[] { typeof(System.Runtime.CompilerServices.CallConvCdecl) }, EntryPoint = "ProcessBattleContextBatch")]
+        public static unsafe int ProcessBattleContextBatch(IntPtr batchPtr, int batchLen, IntPtr codesPtr, int codesCap)
+        {
+            return NativeAOTExceptionInjector.WrapExportFunction("ProcessBattleContextBatch", 0, () =>
+            {
+                Battle.BattleContextBatch batch;
+                try
+                {
+                    batch = Battle.BattleContextBatch.Parser.ParseFrom(new ReadOnlySpan<byte>((void*)batchPtr, batchLen));
+                }
+                catch (InvalidProtocolBufferException)
+                {
+                    return -1; // 数据格式错误
+                }
+
+                if (batch.Contexts.Count > codesCap)
+                {
+                    return -2; // 结果数组容量不足
+                }
+
+                BattleManager.ProcessBattleContextBatch(batch, new Span<int>((void*)codesPtr, codesCap));
+                return batch.Contexts.Count;
+            }, (int)NativeAOTExceptionInjector.NativeErrorCode.SystemError);
+        }
+
+        /// <summary>
+        /// 绑定 Go 分配的输入/输出环形缓冲区
+        ///
+        /// 函数签名 (C 风格):
+        /// int32_t AttachRingTransport(
+        ///     uint8_t* input,  int64_t input_size,   // Go 写入, OnTick 开始时读取
+        ///     uint8_t* output, int64_t output_size   // OnTick 写入, Go 在 OnTick 返回后读取
+        /// );
+        /// 参数全为 NULL 时解除绑定
+        /// 返回: 0 成功, -1 共享内存头部校验失败
+        /// </summary>
+        [UnmanagedCallersOnly(CallConvs = new[] { typeof(System.Runtime.CompilerServices.CallConvCdecl) }, EntryPoint = "AttachRingTransport")]
+        public static int AttachRingTransport(IntPtr inputPtr, long inputSize, IntPtr outputPtr, long outputSize)
+        {
+            if (inputPtr == IntPtr.Zero && outputPtr == IntPtr.Zero)
+            {
+                BattleManager.AttachRingTransport(null, null);
+                return 0;
+            }
+
+            var inputRing = SharedRing.Attach(inputPtr, inputSize);
+            var outputRing = SharedRing.Attach(outputPtr, outputSize);
+            if (inputRing == null || outputRing == null)
+            {
+                return -1;
+            }
+
+            BattleManager.AttachRingTransport(inputRing, outputRing);
+            return 0;
+        }
+
+        /// <summary>
+        /// 导出所有战斗状态 (热重载)
+        ///
+        /// 函数签名 (C 风格):
+        /// void ExportBattleState(
+        ///     uint8_t* buffer,
+        ///     int32_t* buffer_len        // 入: 缓冲区容量  出: BattleStateSnapshot 长度 (大于容量时为所需长度, -1 表示失败)
+        /// );
+        /// </summary>
+        [UnmanagedCallersOnly(CallConvs = new[] { typeof(System.Runtime.CompilerServices.CallConvCdecl) }, EntryPoint = "ExportBattleState")]
+        public static void ExportBattleState(IntPtr bufferPtr, IntPtr bufferLenPtr)
+        {
+            try
+            {
+                int capacity = Marshal.ReadInt32(bufferLenPtr);
+                byte[] data = BattleManager.ExportState().ToByteArray();
 
-// 从指针读取字符串
-            byte[] inputBytes = new byte[buffLen];
-            Marshal.Copy(buffPtr, inputBytes, 0, buffLen);
+                // 超出缓冲区时只回报所需长度，Go 扩容后重新导出
+                if (data.Length <= capacity)
+                {
+                    Marshal.Copy(data, 0, bufferPtr, data.Length);
+                }
+                Marshal.WriteInt32(bufferLenPtr, data.Length);
+            }
+            catch (Exception ex)
+            {
+                Console.WriteLine($"[Export] ExportBattleState 异常: {ex}");
+                Marshal.WriteInt32(bufferLenPtr, -1);
+            }
+        }
 
-            var battleInputContext = Battle.BattleContext.Parser.ParseFrom(inputBytes);
-            if (battleInputContext == null || battleInputContext.OptionCase != Battle.BattleContext.OptionOneofCase.BattleInput)
+        /// <summary>
+        /// 查询战斗状态
+        ///
+        /// 函数签名 (C 风格):
+        /// void ListBattleStatus(
+        ///     uint32_t battle_id,        // 0 表示所有战斗
+        ///     uint8_t* buffer,
+        ///     int32_t* buffer_len        // 入: 缓冲区容量  出: BattleStatusList 长度 (大于容量时为所需长度, -1 表示失败)
+        /// );
+        /// </summary>
+        [UnmanagedCallersOnly(CallConvs = new[] { typeof(System.Runtime.CompilerServices.CallConvCdecl) }, EntryPoint = "ListBattleStatus")]
+        public static void ListBattleStatus(uint battleId, IntPtr bufferPtr, IntPtr bufferLenPtr)
+        {
+            try
+            {
+                int capacity = Marshal.ReadInt32(bufferLenPtr);
+                byte[] data = BattleManager.ListStatus(battleId).ToByteArray();
+
+                // 超出缓冲区时只回报所需长度，Go 扩容后重新查询
+                if (data.Length <= capacity)
+                {
+                    Marshal.Copy(data, 0, bufferPtr, data.Length);
+                }
+                Marshal.WriteInt32(bufferLenPtr, data.Length);
+            }
+            catch (Exception ex)
             {
-                return -1; // 无效输入
+                Console.WriteLine($"[Export] ListBattleStatus 异常: {ex}");
+                Marshal.WriteInt32(bufferLenPtr, -1);
             }
+        }
+
+        /// <summary>
+        /// 导入战斗状态 (热重载)
+        /// 参数: dataPtr - BattleStateSnapshot 数据指针, dataLen - 数据长度
+        /// 返回: 导入的战斗数量, -1 战斗 ID 冲突, -2 数据格式错误
+        /// </summary>
+        [UnmanagedCallersOnly(CallConvs = new[] { typeof(System.Runtime.CompilerServices.CallConvCdecl) }, EntryPoint = "ImportBattleState")]
+        public static int ImportBattleState(IntPtr dataPtr, int dataLen)
+        {
+            try
+            {
+                byte[] data = new byte[dataLen];
+                if (dataLen > 0)
+                {
+                    Marshal.Copy(dataPtr, data, 0, dataLen);
+                }
 
-            return BattleManager.ProcessBattleContextInput(battleInputContext);
+                var snapshot = BattleStateSnapshot.Parser.ParseFrom(data);
+                return BattleManager.ImportState(snapshot);
+            }
+            catch (InvalidProtocolBufferException ex)
+            {
+                Console.WriteLine($"[Export] ImportBattleState 解析错误: {ex.Message}");
+                return -2;
+            }
         }
 
         /// <summary>
@@ -0,0 +1,61 @@
+using System;
+using System.Security.Cryptography;
+using GoPureWithCsharp.Battle;
+
+namespace GoPureWithCsharp
+{
+    /// <summary>
+    /// 库能力描述 - 加载时由 Go 通过 GetLibInfo 读取并校验
+    /// </summary>
+    public static class LibraryCapabilities
+    {
+        /// <summary>
+        /// 库版本
+        /// </summary>
+        public const string Version = "goPureWithCsharp-1.0";
+
+        /// <summary>
+        /// 导出函数 ABI 版本
+        /// 任何导出函数的参数列表或调用约定变化时递增，并同步修改 Go 侧 csharp.AbiVersion
+        /// </summary>
+        public const uint AbiVersion = 5;
+
+        /// <summary>
+        /// 支持的可选特性，与 Go 侧 Feature* 常量对应
+        /// </summary>
+        public static readonly string[] Features =
+        {
+            "response_negotiation", // ProcessProtoMessage 响应缓冲区大小协商
+            "hot_reload",           // ExportBattleState / ImportBattleState
+            "exception_reports",    // TakeManagedException 与全局异常回调
+            "ring_transport",       // AttachRingTransport 共享内存输入/输出
+            "context_batch",        // ProcessBattleContextBatch 批量输入
+            "battle_status",        // ListBattleStatus 战斗状态查询
+            "shards",               // CreateShardBattle / OnTickShard 按分片创建和驱动战斗
+        };
+
+        // battle.proto 描述符哈希，与 Go 侧 SchemaHash 算法一致
+        private static readonly Lazy<string> _schemaHash = new(() =>
+            Convert.ToHexString(SHA256.HashData(BattleReflection.Descriptor.SerializedData.ToByteArray())).ToLowerInvariant());
+
+        /// <summary>
+        /// battle.proto 描述符的 SHA-256 (十六进制小写)
+        /// </summary>
+        public static string SchemaHash => _schemaHash.Value;
+
+        /// <summary>
+        /// 构建库信息
+        /// </summary>
+        public static LibraryInfo GetInfo()
+        {
+            var info = new LibraryInfo
+            {
+                Version = Version,
+                AbiVersion = AbiVersion,
+                SchemaHash = SchemaHash,
+            };
+            info.Features.Add(Features);
+            return info;
+        }
+    }
+}
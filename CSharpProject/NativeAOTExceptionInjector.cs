@@ -1,16 +1,26 @@
 using GoPureWithCsharp;
+using GoPureWithCsharp.Battle;
 using System;
 using System.Runtime.InteropServices;
-using System.Text;
+using Google.Protobuf;
 
 
 namespace GoPureWithCsharp
 {
 
     // 异常处理工具类：适配Go侧调用的统一逻辑
-
+    //
+    // 1. 导出函数内抛出的异常: WrapExportFunction 捕获后保存到当前线程，导出函数返回失败值，
+    //    Go 在同一线程上调用 TakeManagedException 取回报告，作为该次调用的错误返回
+    // 2. 不中断导出函数的异常 (例如 OnTick 中单场战斗抛出): ReportException 通过
+    //    InjectedExceptionContext 注册的全局回调通知 Go
+
+    /// <summary>
+    /// Go 侧全局异常回调
+    /// 参数: data - 序列化的 ManagedException, len - 数据长度
+    /// </summary>
     [UnmanagedFunctionPointer(CallingConvention.Cdecl)]
-    public delegate void ExNotifyCallback();
+    public delegate void ExNotifyCallback(IntPtr data, int len);
 
     public static class NativeAOTExceptionInjector
     {
@@ -25,24 +35,23 @@ namespace GoPureWithCsharp
         }
 
         /// <summary>
-        /// Go侧注入的错误栈写入区指针（缓冲区首地址）
+        /// 当前线程上最近一次导出函数失败的异常，等待 Go 通过 TakeManagedException 取回
+        /// Go 调用导出函数期间锁定 OS 线程，保证取回的是同一次调用的异常
         /// </summary>
-        private static IntPtr exBufferPtr;
-
-        /// <summary>
-        /// Go侧注入的缓冲区大小（字节）
-        /// </summary>
-        private static Int32 exBufferSize;
-
+        [ThreadStatic]
+        private static ManagedException? lastException;
 
         private static ExNotifyCallback? exNotifyCallback;
 
+        /// <summary>
+        /// 注册全局异常回调 (由 Go 在加载库时调用)
+        ///
+        /// 函数签名 (C 风格):
+        /// void InjectedExceptionContext(void (*callback)(const uint8_t* data, int32_t len));
+        /// </summary>
         [UnmanagedCallersOnly(CallConvs = new[] { typeof(System.Runtime.CompilerServices.CallConvCdecl) }, EntryPoint = "InjectedExceptionContext")]
-        public static void InjectedExceptionContext(IntPtr callbackPtr, IntPtr goBuffPtr, Int32 BufferSize )
+        public static void InjectedExceptionContext(IntPtr callbackPtr)
         {
-            Console.WriteLine($"C# [InjectedExceptionBuff] Go 开始注入的异常信息写入栈");
-            NativeAOTExceptionInjector.exBufferPtr = goBuffPtr;
-            NativeAOTExceptionInjector.exBufferSize = BufferSize;
             if (callbackPtr == IntPtr.Zero)
             {
                 exNotifyCallback = null;
@@ -51,100 +60,104 @@ namespace GoPureWithCsharp
             }
 
             exNotifyCallback = Marshal.GetDelegateForFunctionPointer<ExNotifyCallback>(callbackPtr);
+            Console.WriteLine($"C# [InjectedExceptionContext] Go 注入的异常通知地址 0x{callbackPtr:X}");
+        }
 
+        /// <summary>
+        /// 取回当前线程上最近一次导出函数失败的异常 (由 Go 在导出函数返回失败后调用)
+        ///
+        /// 函数签名 (C 风格):
+        /// void TakeManagedException(
+        ///     uint8_t* buffer,
+        ///     int32_t* buffer_len        // 入: 缓冲区容量  出: ManagedException 长度 (0 表示没有异常, 大于容量时为所需长度, -1 表示失败)
+        /// );
+        /// 缓冲区不足时报告保留，Go 扩容后重新取回
+        /// </summary>
+        [UnmanagedCallersOnly(CallConvs = new[] { typeof(System.Runtime.CompilerServices.CallConvCdecl) }, EntryPoint = "TakeManagedException")]
+        public static void TakeManagedException(IntPtr bufferPtr, IntPtr bufferLenPtr)
+        {
+            try
+            {
+                int capacity = Marshal.ReadInt32(bufferLenPtr);
+                if (lastException == null)
+                {
+                    Marshal.WriteInt32(bufferLenPtr, 0);
+                    return;
+                }
 
-            Console.WriteLine($"C# [InjectedExceptionBuff] Go 注入的异常信息写入栈 地址0x{exBufferPtr:X} 长度 {BufferSize}  通知地址0x{exNotifyCallback:X}");
+                byte[] data = lastException.ToByteArray();
+                if (data.Length <= capacity)
+                {
+                    Marshal.Copy(data, 0, bufferPtr, data.Length);
+                    lastException = null;
+                }
+                Marshal.WriteInt32(bufferLenPtr, data.Length);
+            }
+            catch (Exception ex)
+            {
+                Console.WriteLine($"[NativeAOTExceptionInjector] TakeManagedException 异常: {ex}");
+                Marshal.WriteInt32(bufferLenPtr, -1);
+            }
         }
 
         // ========== 核心：捕获异常后通知Go ==========
-        // 所有导出函数的统一包装（捕获异常+通知Go）
-        public static T WrapExportFunction<T>(Func<T> func)
+        // 所有导出函数的统一包装（捕获异常 + 保存报告）
+        // export: 导出函数名, battleId: 相关战斗 (没有时为 0), failure: 捕获到异常时的返回值
+        public static T WrapExportFunction<T>(string export, uint battleId, Func<T> func, T failure)
         {
+            lastException = null;
             try
             {
-                Console.WriteLine($"C# WrapExportFunction Called");
                 return func();
             }
             catch (Exception ex)
             {
-                Console.WriteLine($"[NativeAOTExceptionInjector] 捕获异常: {ex}");
-                // 1. 处理异常并通知Go
-                NativeAOTExceptionInjector.HandleException(ex);
-                // 2. 返回默认值（适配FFI调用）
-                // 显式处理引用类型的 default 返回
-                if (default(T) is null && typeof(T).IsClass)
-                {
-                    return (T)(object)null!;
-                }
-                return default!;
+                Console.WriteLine($"[NativeAOTExceptionInjector] {export} 捕获异常: {ex}");
+                lastException = BuildReport(ex, export, battleId);
+                return failure;
             }
         }
 
         /// <summary>
-        /// 统一处理异常，写入Go侧缓冲区并返回错误码
+        /// 报告不中断导出函数的异常，通过全局回调通知 Go
         /// </summary>
-        /// <param name="ex">捕获的异常</param>
-        /// <param name="errorMsgBuffer">Go侧传入的缓冲区指针</param>
-        /// <param name="bufferSize">缓冲区大小</param>
-        /// <returns>统一错误码</returns>
-        public static NativeErrorCode HandleException(Exception ex)
+        public static void ReportException(Exception ex, string export, uint battleId)
         {
+            Console.WriteLine($"[NativeAOTExceptionInjector] {export} 战斗 {battleId} 异常: {ex}");
 
-
-            // 1. 构建结构化的错误信息（JSON格式，方便Go侧解析）
-            var errorBuilder = new StringBuilder();
-            errorBuilder.Append("{");
-
-            // 根据异常类型分类处理
-            switch (ex)
+            var callback = exNotifyCallback;
+            if (callback == null)
             {
-                //case ParameterValidationException paramEx:
-                //    errorBuilder.Append($"\"type\":\"ParameterValidationException\",")
-                //                .Append($"\"errorCode\":{paramEx.ErrorCode},")
-                //                .Append($"\"parameterName\":\"{paramEx.ParameterName}\",")
-                //                .Append($"\"message\":\"{EscapeJson(paramEx.Message)}\",")
-                //                .Append($"\"stackTrace\":\"{EscapeJson(paramEx.StackTrace)}\"");
-                //    break;
-
-                //case BusinessRuleException businessEx:
-                //    errorBuilder.Append($"\"type\":\"BusinessRuleException\",")
-                //                .Append($"\"businessCode\":{businessEx.BusinessCode},")
-                //                .Append($"\"message\":\"{EscapeJson(businessEx.Message)}\"");
-                //    break;
-
-                default:
-                    // 系统异常（如NullReferenceException）
-                    errorBuilder.Append($"\"type\":\"{ex.GetType().Name}\",")
-                                .Append($"\"message\":\"{EscapeJson(ex.Message ?? string.Empty)}\",")
-                                .Append($"\"stackTrace\":\"{EscapeJson(ex.StackTrace ?? string.Empty)}\"");
-                    break;
+                return;
             }
 
-            errorBuilder.Append("}");
-            var errorJson = errorBuilder.ToString();
-
-            // 2. 将JSON写入Go侧缓冲区（注意缓冲区大小限制）
-            var errorBytes = Encoding.UTF8.GetBytes(errorJson);
-            var copyLength = Math.Min(errorBytes.Length, exBufferSize - 1);
-            Marshal.Copy(errorBytes, 0, exBufferPtr, copyLength);
-            Marshal.WriteByte(exBufferPtr, copyLength, 0);
-            exNotifyCallback?.Invoke();
-            // 3. 返回统一错误码（供Go侧快速判断）
-            return ex switch
+            byte[] data = BuildReport(ex, export, battleId).ToByteArray();
+            var handle = GCHandle.Alloc(data, GCHandleType.Pinned);
+            try
             {
-                //ParameterValidationException => NativeErrorCode.ParameterError,
-                //BusinessRuleException => NativeErrorCode.BusinessError,
-                _ => NativeErrorCode.SystemError
-            };
+                callback(handle.AddrOfPinnedObject(), data.Length);
+            }
+            finally
+            {
+                handle.Free();
+            }
         }
 
-        // 辅助方法：JSON字符串转义（避免双引号、换行符等破坏JSON格式）
-        private static string EscapeJson(string input)
+        /// <summary>
+        /// 构建结构化的异常报告
+        /// </summary>
+        public static ManagedException BuildReport(Exception ex, string export, uint battleId)
         {
-            if (string.IsNullOrEmpty(input)) return "";
-            return input.Replace("\"", "\\\"")
-                        .Replace("\n", "\\n")
-                        .Replace("\r", "\\r");
+            return new ManagedException
+            {
+                Type = ex.GetType().FullName ?? ex.GetType().Name,
+                Message = ex.Message ?? string.Empty,
+                StackTrace = ex.StackTrace ?? string.Empty,
+                BattleId = battleId,
+                Export = export,
+                Inner = ex.InnerException?.ToString() ?? string.Empty,
+                Timestamp = DateTimeOffset.Now.ToUnixTimeMilliseconds(),
+            };
         }
     }
-}
\ No newline at end of file
+}
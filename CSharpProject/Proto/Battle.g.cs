@@ -25,88 +25,135 @@ namespace GoPureWithCsharp.Battle {
       byte[] descriptorData = global::System.Convert.FromBase64String(
           string.Concat(
             "CgxiYXR0bGUucHJvdG8SBmJhdHRsZSI6CgRUZWFtEg4KBmxpbmV1cBgBIAMo",
-            "DRIPCgd0ZWFtX2lkGAIgASgNEhEKCXRlYW1fbmFtZRgDIAEoCSJ/CglCYXR0",
-            "bGVFbnYSGQoDYXRrGAEgASgLMgwuYmF0dGxlLlRlYW0SGQoDZGVmGAIgASgL",
-            "MgwuYmF0dGxlLlRlYW0SEQoJYmF0dGxlX2lkGAMgASgNEhEKCXRpbWVzdGFt",
-            "cBgEIAEoAxIWCg5jb25maWdfdmVyc2lvbhgFIAEoDSJpCgtTdGFydEJhdHRs",
-            "ZRIZCgNhdGsYASABKAsyDC5iYXR0bGUuVGVhbRIZCgNkZWYYAiABKAsyDC5i",
-            "YXR0bGUuVGVhbRIRCgliYXR0bGVfaWQYAyABKA0SEQoJdGltZXN0YW1wGAQg",
-            "ASgDIrMBCgtCYXR0bGVJbnB1dBIkCgN1c2UYASABKAsyFS5iYXR0bGUuQmF0",
-            "dGxlVXNlSXRlbUgAEiYKBnJlc3VtZRgCIAEoCzIULmJhdHRsZS5CYXR0bGVS",
-            "ZXN1bWVIABIkCgVwYXVzZRgDIAEoCzITLmJhdHRsZS5CYXR0bGVQYXVzZUgA",
-            "EicKB3VzZXJfb3AYBCABKAsyFC5iYXR0bGUuQmF0dGxlVXNlck9wSABCBwoF",
-            "aW5wdXQiMgoMQmF0dGxlVXNlck9wEg8KB2NoYXJfaWQYASABKAUSEQoJb3Bl",
-            "cmF0aW9uGAIgASgJIkQKDUJhdHRsZVVzZUl0ZW0SEAoIaXRlbV9pZHMYASAD",
-            "KA0SDwoHdXNlcl9pZBgCIAEoDRIQCghxdWFudGl0eRgDIAEoBSIOCgxCYXR0",
-            "bGVSZXN1bWUiDQoLQmF0dGxlUGF1c2UiaAoMQmF0dGxlT3V0cHV0EiYKBnJl",
-            "c3VsdBgBIAEoCzIULmJhdHRsZS5CYXR0bGVSZXN1bHRIABImCgZyZXBsYXkY",
-            "AiABKAsyFC5iYXR0bGUuQmF0dGxlUmVwbGF5SABCCAoGb3V0cHV0IowBCgxC",
-            "YXR0bGVSZXN1bHQSDgoGd2lubmVyGAEgASgNEg0KBWxvc2VyGAIgASgNEhIK",
-            "CmF0a19kYW1hZ2UYAyABKAUSEgoKZGVmX2RhbWFnZRgEIAEoBRINCgVraWxs",
-            "cxgFIAMoDRIQCghkdXJhdGlvbhgGIAEoAxIUCgxiYXR0bGVfc2NvcmUYByAB",
-            "KAUiegoMQmF0dGxlU3RhdHVzEhEKCWJhdHRsZV9pZBgBIAEoDRINCgVyb3Vu",
-            "ZBgCIAEoBRISCgphdGtfaGVhbHRoGAMgASgFEhIKCmRlZl9oZWFsdGgYBCAB",
-            "KAUSDQoFc3RhdGUYBSABKAkSEQoJdGltZXN0YW1wGAYgASgDIlIKDkJhdHRs",
-            "ZVJlc3BvbnNlEgwKBGNvZGUYASABKAUSDwoHbWVzc2FnZRgCIAEoCRIOCgZy",
-            "ZXN1bHQYAyABKAwSEQoJdGltZXN0YW1wGAQgASgDIl4KEkJhdGNoQmF0dGxl",
-            "UmVxdWVzdBIkCgdiYXR0bGVzGAEgAygLMhMuYmF0dGxlLlN0YXJ0QmF0dGxl",
-            "EhAKCGJhdGNoX2lkGAIgASgJEhAKCHBhcmFsbGVsGAMgASgFIpQBChNCYXRj",
-            "aEJhdHRsZVJlc3BvbnNlEiUKB3Jlc3VsdHMYASADKAsyFC5iYXR0bGUuQmF0",
-            "dGxlUmVzdWx0EhAKCGJhdGNoX2lkGAIgASgJEhUKDXN1Y2Nlc3NfY291bnQY",
-            "AyABKAUSFQoNZmFpbHVyZV9jb3VudBgEIAEoBRIWCg50b3RhbF9kdXJhdGlv",
-            "bhgFIAEoAyLJAQoLQmF0dGxlRXZlbnQSEQoJdGltZXN0YW1wGAEgASgDEhIK",
-            "CmV2ZW50X3R5cGUYAiABKAkSFAoMcGVyZm9ybWVyX2lkGAMgASgNEhEKCXRh",
-            "cmdldF9pZBgEIAEoDRINCgV2YWx1ZRgFIAEoBRItCgVleHRyYRgGIAMoCzIe",
-            "LmJhdHRsZS5CYXR0bGVFdmVudC5FeHRyYUVudHJ5GiwKCkV4dHJhRW50cnkS",
-            "CwoDa2V5GAEgASgJEg0KBXZhbHVlGAIgASgJOgI4ASLjAQoMQmF0dGxlUmVw",
-            "bGF5EhEKCWJhdHRsZV9pZBgBIAEoDRISCgpzdGFydF90aW1lGAIgASgDEhAK",
-            "CGVuZF90aW1lGAMgASgDEh4KCGF0a190ZWFtGAQgASgLMgwuYmF0dGxlLlRl",
-            "YW0SHgoIZGVmX3RlYW0YBSABKAsyDC5iYXR0bGUuVGVhbRIjCgZldmVudHMY",
-            "BiADKAsyEy5iYXR0bGUuQmF0dGxlRXZlbnQSJAoGcmVzdWx0GAcgASgLMhQu",
-            "YmF0dGxlLkJhdHRsZVJlc3VsdBIPCgd2ZXJzaW9uGAggASgJIo0BCg5Qcm9n",
-            "cmVzc1JlcG9ydBIRCgliYXR0bGVfaWQYASABKA0SGAoQcHJvZ3Jlc3NfcGVy",
-            "Y2VudBgCIAEoBRIVCg1jdXJyZW50X3JvdW5kGAMgASgFEiQKBnN0YXR1cxgE",
-            "IAEoCzIULmJhdHRsZS5CYXR0bGVTdGF0dXMSEQoJdGltZXN0YW1wGAUgASgD",
-            "IpcBChJCYXR0bGVOb3RpZmljYXRpb24SEQoJdGltZXN0YW1wGAEgASgDEjMK",
-            "EW5vdGlmaWNhdGlvbl90eXBlGAIgASgOMhguYmF0dGxlLk5vdGlmaWNhdGlv",
-            "blR5cGUSEQoJYmF0dGxlX2lkGAMgASgNEg8KB3BheWxvYWQYBCABKAwSFQoN",
-            "ZXJyb3JfbWVzc2FnZRgFIAEoCSKWAQoNQmF0dGxlQ29udGV4dBIRCgliYXR0",
-            "bGVfaWQYASABKA0SDAoEdGljaxgCIAEoBBIrCgxiYXR0bGVfaW5wdXQYAyAB",
-            "KAsyEy5iYXR0bGUuQmF0dGxlSW5wdXRIABItCg1iYXR0bGVfb3V0cHV0GAQg",
-            "ASgLMhQuYmF0dGxlLkJhdHRsZU91dHB1dEgAQggKBm9wdGlvbip8ChRCYXR0",
-            "bGVJbnB1dE9wZXJhdGlvbhIJCgVTdGFydBAAEg0KCVRpY2tFdmVudBABEgsK",
-            "B1VzZUl0ZW0QAhIHCgNFbmQQAxIJCgVQYXVzZRAEEgoKBlJlc3VtZRAFEhAK",
-            "DFN0YXR1c1VwZGF0ZRAGEgsKB0Rlc3Ryb3kQByrFAQoPQmF0dGxlRXJyb3JD",
-            "b2RlEgsKB1NVQ0NFU1MQABITCg9JTlZBTElEX1JFUVVFU1QQARISCg5URUFN",
-            "X05PVF9GT1VORBACEhUKEUlOVkFMSURfVEVBTV9TSVpFEAMSFAoQQkFUVExF",
-            "X05PVF9GT1VORBAEEhQKEERVUExJQ0FURV9CQVRUTEUQBRISCg5JTlRFUk5B",
-            "TF9FUlJPUhAGEgsKB1RJTUVPVVQQBxIYChRJTlZBTElEX1BST1RPX0ZPUk1B",
-            "VBAIKmMKEE5vdGlmaWNhdGlvblR5cGUSEQoNU1RBVFVTX1VQREFURRAAEhIK",
-            "DkVWRU5UX09DQ1VSUkVEEAESFAoQQkFUVExFX0NPTVBMRVRFRBACEhIKDkVS",
-            "Uk9SX09DQ1VSUkVEEANCP1ojZ29QdXJlV2l0aENzaGFycC9jc2hhcnAvcHJv",
-            "dG87cHJvdG+qAhdHb1B1cmVXaXRoQ3NoYXJwLkJhdHRsZWIGcHJvdG8z"));
+            "DRIPCgd0ZWFtX2lkGAIgASgNEhEKCXRlYW1fbmFtZRgDIAEoCSJzCgpCYXR0",
+            "bGVVbml0Eg8KB3VuaXRfaWQYASABKA0SDwoHdGVhbV9pZBgCIAEoDRISCgpt",
+            "YXhfaGVhbHRoGAMgASgFEg4KBmhlYWx0aBgEIAEoBRIOCgZhdHRhY2sYBSAB",
+            "KAUSDwoHZGVmZW5zZRgGIAEoBSKNAQoJQmF0dGxlRW52EhkKA2F0axgBIAEo",
+            "CzIMLmJhdHRsZS5UZWFtEhkKA2RlZhgCIAEoCzIMLmJhdHRsZS5UZWFtEhEK",
+            "CWJhdHRsZV9pZBgDIAEoDRIRCgl0aW1lc3RhbXAYBCABKAMSFgoOY29uZmln",
+            "X3ZlcnNpb24YBSABKA0SDAoEc2VlZBgGIAEoBCJ3CgtTdGFydEJhdHRsZRIZ",
+            "CgNhdGsYASABKAsyDC5iYXR0bGUuVGVhbRIZCgNkZWYYAiABKAsyDC5iYXR0",
+            "bGUuVGVhbRIRCgliYXR0bGVfaWQYAyABKA0SEQoJdGltZXN0YW1wGAQgASgD",
+            "EgwKBHNlZWQYBSABKAQi/wEKC0JhdHRsZUlucHV0EiQKA3VzZRgBIAEoCzIV",
+            "LmJhdHRsZS5CYXR0bGVVc2VJdGVtSAASJgoGcmVzdW1lGAIgASgLMhQuYmF0",
+            "dGxlLkJhdHRsZVJlc3VtZUgAEiQKBXBhdXNlGAMgASgLMhMuYmF0dGxlLkJh",
+            "dHRsZVBhdXNlSAASJwoHdXNlcl9vcBgEIAEoCzIULmJhdHRsZS5CYXR0bGVV",
+            "c2VyT3BIABIgCgNlbmQYBSABKAsyES5iYXR0bGUuQmF0dGxlRW5kSAASKAoH",
+            "ZGVzdHJveRgGIAEoCzIVLmJhdHRsZS5CYXR0bGVEZXN0cm95SABCBwoFaW5w",
+            "dXQiMgoMQmF0dGxlVXNlck9wEg8KB2NoYXJfaWQYASABKAUSEQoJb3BlcmF0",
+            "aW9uGAIgASgJIkQKDUJhdHRsZVVzZUl0ZW0SEAoIaXRlbV9pZHMYASADKA0S",
+            "DwoHdXNlcl9pZBgCIAEoDRIQCghxdWFudGl0eRgDIAEoBSIOCgxCYXR0bGVS",
+            "ZXN1bWUiDQoLQmF0dGxlUGF1c2UiLAoJQmF0dGxlRW5kEg4KBndpbm5lchgB",
+            "IAEoDRIPCgd0aW1lb3V0GAIgASgIIg8KDUJhdHRsZURlc3Ryb3kiaAoMQmF0",
+            "dGxlT3V0cHV0EiYKBnJlc3VsdBgBIAEoCzIULmJhdHRsZS5CYXR0bGVSZXN1",
+            "bHRIABImCgZyZXBsYXkYAiABKAsyFC5iYXR0bGUuQmF0dGxlUmVwbGF5SABC",
+            "CAoGb3V0cHV0Ip0BCgxCYXR0bGVSZXN1bHQSDgoGd2lubmVyGAEgASgNEg0K",
+            "BWxvc2VyGAIgASgNEhIKCmF0a19kYW1hZ2UYAyABKAUSEgoKZGVmX2RhbWFn",
+            "ZRgEIAEoBRINCgVraWxscxgFIAMoDRIQCghkdXJhdGlvbhgGIAEoAxIUCgxi",
+            "YXR0bGVfc2NvcmUYByABKAUSDwoHdGltZW91dBgIIAEoCCLBAgoMQmF0dGxl",
+            "U3RhdHVzEhEKCWJhdHRsZV9pZBgBIAEoDRINCgVyb3VuZBgCIAEoBRISCgph",
+            "dGtfaGVhbHRoGAMgASgFEhIKCmRlZl9oZWFsdGgYBCABKAUSDQoFc3RhdGUY",
+            "BSABKAkSEQoJdGltZXN0YW1wGAYgASgDEhMKC2F0a190ZWFtX2lkGAcgASgN",
+            "EhMKC2RlZl90ZWFtX2lkGAggASgNEg4KBndpbm5lchgJIAEoDRIWCg5hdGtf",
+            "bWF4X2hlYWx0aBgKIAEoBRIWCg5kZWZfbWF4X2hlYWx0aBgLIAEoBRISCgph",
+            "dGtfYXR0YWNrGAwgASgFEhIKCmRlZl9hdHRhY2sYDSABKAUSIQoFdW5pdHMY",
+            "DiADKAsyEi5iYXR0bGUuQmF0dGxlVW5pdBIQCghzaGFyZF9pZBgPIAEoDSI5",
+            "ChBCYXR0bGVTdGF0dXNMaXN0EiUKB2JhdHRsZXMYASADKAsyFC5iYXR0bGUu",
+            "QmF0dGxlU3RhdHVzIn8KDkJhdHRsZVJlc3BvbnNlEgwKBGNvZGUYASABKAUS",
+            "DwoHbWVzc2FnZRgCIAEoCRIOCgZyZXN1bHQYAyABKAwSEQoJdGltZXN0YW1w",
+            "GAQgASgDEisKCWV4Y2VwdGlvbhgFIAEoCzIYLmJhdHRsZS5NYW5hZ2VkRXhj",
+            "ZXB0aW9uIl4KEkJhdGNoQmF0dGxlUmVxdWVzdBIkCgdiYXR0bGVzGAEgAygL",
+            "MhMuYmF0dGxlLlN0YXJ0QmF0dGxlEhAKCGJhdGNoX2lkGAIgASgJEhAKCHBh",
+            "cmFsbGVsGAMgASgFIpQBChNCYXRjaEJhdHRsZVJlc3BvbnNlEiUKB3Jlc3Vs",
+            "dHMYASADKAsyFC5iYXR0bGUuQmF0dGxlUmVzdWx0EhAKCGJhdGNoX2lkGAIg",
+            "ASgJEhUKDXN1Y2Nlc3NfY291bnQYAyABKAUSFQoNZmFpbHVyZV9jb3VudBgE",
+            "IAEoBRIWCg50b3RhbF9kdXJhdGlvbhgFIAEoAyLJAQoLQmF0dGxlRXZlbnQS",
+            "EQoJdGltZXN0YW1wGAEgASgDEhIKCmV2ZW50X3R5cGUYAiABKAkSFAoMcGVy",
+            "Zm9ybWVyX2lkGAMgASgNEhEKCXRhcmdldF9pZBgEIAEoDRINCgV2YWx1ZRgF",
+            "IAEoBRItCgVleHRyYRgGIAMoCzIeLmJhdHRsZS5CYXR0bGVFdmVudC5FeHRy",
+            "YUVudHJ5GiwKCkV4dHJhRW50cnkSCwoDa2V5GAEgASgJEg0KBXZhbHVlGAIg",
+            "ASgJOgI4ASLjAQoMQmF0dGxlUmVwbGF5EhEKCWJhdHRsZV9pZBgBIAEoDRIS",
+            "CgpzdGFydF90aW1lGAIgASgDEhAKCGVuZF90aW1lGAMgASgDEh4KCGF0a190",
+            "ZWFtGAQgASgLMgwuYmF0dGxlLlRlYW0SHgoIZGVmX3RlYW0YBSABKAsyDC5i",
+            "YXR0bGUuVGVhbRIjCgZldmVudHMYBiADKAsyEy5iYXR0bGUuQmF0dGxlRXZl",
+            "bnQSJAoGcmVzdWx0GAcgASgLMhQuYmF0dGxlLkJhdHRsZVJlc3VsdBIPCgd2",
+            "ZXJzaW9uGAggASgJIo0BCg5Qcm9ncmVzc1JlcG9ydBIRCgliYXR0bGVfaWQY",
+            "ASABKA0SGAoQcHJvZ3Jlc3NfcGVyY2VudBgCIAEoBRIVCg1jdXJyZW50X3Jv",
+            "dW5kGAMgASgFEiQKBnN0YXR1cxgEIAEoCzIULmJhdHRsZS5CYXR0bGVTdGF0",
+            "dXMSEQoJdGltZXN0YW1wGAUgASgDIpcBChJCYXR0bGVOb3RpZmljYXRpb24S",
+            "EQoJdGltZXN0YW1wGAEgASgDEjMKEW5vdGlmaWNhdGlvbl90eXBlGAIgASgO",
+            "MhguYmF0dGxlLk5vdGlmaWNhdGlvblR5cGUSEQoJYmF0dGxlX2lkGAMgASgN",
+            "Eg8KB3BheWxvYWQYBCABKAwSFQoNZXJyb3JfbWVzc2FnZRgFIAEoCSKWAQoN",
+            "QmF0dGxlQ29udGV4dBIRCgliYXR0bGVfaWQYASABKA0SDAoEdGljaxgCIAEo",
+            "BBIrCgxiYXR0bGVfaW5wdXQYAyABKAsyEy5iYXR0bGUuQmF0dGxlSW5wdXRI",
+            "ABItCg1iYXR0bGVfb3V0cHV0GAQgASgLMhQuYmF0dGxlLkJhdHRsZU91dHB1",
+            "dEgAQggKBm9wdGlvbiKQBAoOQmF0dGxlU25hcHNob3QSEQoJYmF0dGxlX2lk",
+            "GAEgASgNEhMKC2F0a190ZWFtX2lkGAIgASgNEhMKC2RlZl90ZWFtX2lkGAMg",
+            "ASgNEhIKCmF0a19oZWFsdGgYBCABKAUSEgoKZGVmX2hlYWx0aBgFIAEoBRIV",
+            "Cg1jdXJyZW50X3JvdW5kGAYgASgFEhMKC2lzX2ZpbmlzaGVkGAcgASgIEg4K",
+            "Bndpbm5lchgIIAEoDRIMCgRzZWVkGAkgASgEEhEKCXJuZ19zdGF0ZRgKIAEo",
+            "BBITCgtzdGFydF9mcmFtZRgLIAEoBBIjCgZldmVudHMYDCADKAsyEy5iYXR0",
+            "bGUuQmF0dGxlRXZlbnQSDQoFc3RhdGUYDSABKAkSFQoNZW5kX3JlcXVlc3Rl",
+            "ZBgOIAEoCBIWCg5hdGtfbWF4X2hlYWx0aBgPIAEoBRIWCg5kZWZfbWF4X2hl",
+            "YWx0aBgQIAEoBRISCgphdGtfYXR0YWNrGBEgASgFEhIKCmRlZl9hdHRhY2sY",
+            "EiABKAUSIQoFdW5pdHMYEyADKAsyEi5iYXR0bGUuQmF0dGxlVW5pdBIeCghh",
+            "dGtfdGVhbRgUIAEoCzIMLmJhdHRsZS5UZWFtEh4KCGRlZl90ZWFtGBUgASgL",
+            "MgwuYmF0dGxlLlRlYW0SEAoIc2hhcmRfaWQYFiABKA0SDwoHdGltZW91dBgX",
+            "IAEoCCJRChNCYXR0bGVTdGF0ZVNuYXBzaG90EhEKCXRpbWVzdGFtcBgBIAEo",
+            "AxInCgdiYXR0bGVzGAIgAygLMhYuYmF0dGxlLkJhdHRsZVNuYXBzaG90IloK",
+            "C0xpYnJhcnlJbmZvEg8KB3ZlcnNpb24YASABKAkSEwoLYWJpX3ZlcnNpb24Y",
+            "AiABKA0SEwoLc2NoZW1hX2hhc2gYAyABKAkSEAoIZmVhdHVyZXMYBCADKAki",
+            "iwEKEE1hbmFnZWRFeGNlcHRpb24SDAoEdHlwZRgBIAEoCRIPCgdtZXNzYWdl",
+            "GAIgASgJEhMKC3N0YWNrX3RyYWNlGAMgASgJEhEKCWJhdHRsZV9pZBgEIAEo",
+            "DRIOCgZleHBvcnQYBSABKAkSDQoFaW5uZXIYBiABKAkSEQoJdGltZXN0YW1w",
+            "GAcgASgDIj0KEkJhdHRsZUNvbnRleHRCYXRjaBInCghjb250ZXh0cxgBIAMo",
+            "CzIVLmJhdHRsZS5CYXR0bGVDb250ZXh0InIKDEpvdXJuYWxFbnRyeRINCgVm",
+            "cmFtZRgBIAEoBBIgCgNlbnYYAiABKAsyES5iYXR0bGUuQmF0dGxlRW52SAAS",
+            "KAoHY29udGV4dBgDIAEoCzIVLmJhdHRsZS5CYXR0bGVDb250ZXh0SABCBwoF",
+            "ZW50cnkqfAoUQmF0dGxlSW5wdXRPcGVyYXRpb24SCQoFU3RhcnQQABINCglU",
+            "aWNrRXZlbnQQARILCgdVc2VJdGVtEAISBwoDRW5kEAMSCQoFUGF1c2UQBBIK",
+            "CgZSZXN1bWUQBRIQCgxTdGF0dXNVcGRhdGUQBhILCgdEZXN0cm95EAcq3wEK",
+            "D0JhdHRsZUVycm9yQ29kZRILCgdTVUNDRVNTEAASEwoPSU5WQUxJRF9SRVFV",
+            "RVNUEAESEgoOVEVBTV9OT1RfRk9VTkQQAhIVChFJTlZBTElEX1RFQU1fU0la",
+            "RRADEhQKEEJBVFRMRV9OT1RfRk9VTkQQBBIUChBEVVBMSUNBVEVfQkFUVExF",
+            "EAUSEgoOSU5URVJOQUxfRVJST1IQBhILCgdUSU1FT1VUEAcSGAoUSU5WQUxJ",
+            "RF9QUk9UT19GT1JNQVQQCBIYChRJTlZBTElEX0JBVFRMRV9TVEFURRAJKmMK",
+            "EE5vdGlmaWNhdGlvblR5cGUSEQoNU1RBVFVTX1VQREFURRAAEhIKDkVWRU5U",
+            "X09DQ1VSUkVEEAESFAoQQkFUVExFX0NPTVBMRVRFRBACEhIKDkVSUk9SX09D",
+            "Q1VSUkVEEANCP1ojZ29QdXJlV2l0aENzaGFycC9jc2hhcnAvcHJvdG87cHJv",
+            "dG+qAhdHb1B1cmVXaXRoQ3NoYXJwLkJhdHRsZWIGcHJvdG8z"));
       descriptor = pbr::FileDescriptor.FromGeneratedCode(descriptorData,
           new pbr::FileDescriptor[] { },
           new pbr::GeneratedClrTypeInfo(new[] {typeof(global::GoPureWithCsharp.Battle.BattleInputOperation), typeof(global::GoPureWithCsharp.Battle.BattleErrorCode), typeof(global::GoPureWithCsharp.Battle.NotificationType), }, null, new pbr::GeneratedClrTypeInfo[] {
             new pbr::GeneratedClrTypeInfo(typeof(global::GoPureWithCsharp.Battle.Team), global::GoPureWithCsharp.Battle.Team.Parser, new[]{ "Lineup", "TeamId", "TeamName" }, null, null, null, null),
-            new pbr::GeneratedClrTypeInfo(typeof(global::GoPureWithCsharp.Battle.BattleEnv), global::GoPureWithCsharp.Battle.BattleEnv.Parser, new[]{ "Atk", "Def", "BattleId", "Timestamp", "ConfigVersion" }, null, null, null, null),
-            new pbr::GeneratedClrTypeInfo(typeof(global::GoPureWithCsharp.Battle.StartBattle), global::GoPureWithCsharp.Battle.StartBattle.Parser, new[]{ "Atk", "Def", "BattleId", "Timestamp" }, null, null, null, null),
-            new pbr::GeneratedClrTypeInfo(typeof(global::GoPureWithCsharp.Battle.BattleInput), global::GoPureWithCsharp.Battle.BattleInput.Parser, new[]{ "Use", "Resume", "Pause", "UserOp" }, new[]{ "Input" }, null, null, null),
+            new pbr::GeneratedClrTypeInfo(typeof(global::GoPureWithCsharp.Battle.BattleUnit), global::GoPureWithCsharp.Battle.BattleUnit.Parser, new[]{ "UnitId", "TeamId", "MaxHealth", "Health", "Attack", "Defense" }, null, null, null, null),
+            new pbr::GeneratedClrTypeInfo(typeof(global::GoPureWithCsharp.Battle.BattleEnv), global::GoPureWithCsharp.Battle.BattleEnv.Parser, new[]{ "Atk", "Def", "BattleId", "Timestamp", "ConfigVersion", "Seed" }, null, null, null, null),
+            new pbr::GeneratedClrTypeInfo(typeof(global::GoPureWithCsharp.Battle.StartBattle), global::GoPureWithCsharp.Battle.StartBattle.Parser, new[]{ "Atk", "Def", "BattleId", "Timestamp", "Seed" }, null, null, null, null),
+            new pbr::GeneratedClrTypeInfo(typeof(global::GoPureWithCsharp.Battle.BattleInput), global::GoPureWithCsharp.Battle.BattleInput.Parser, new[]{ "Use", "Resume", "Pause", "UserOp", "End", "Destroy" }, new[]{ "Input" }, null, null, null),
             new pbr::GeneratedClrTypeInfo(typeof(global::GoPureWithCsharp.Battle.BattleUserOp), global::GoPureWithCsharp.Battle.BattleUserOp.Parser, new[]{ "CharId", "Operation" }, null, null, null, null),
             new pbr::GeneratedClrTypeInfo(typeof(global::GoPureWithCsharp.Battle.BattleUseItem), global::GoPureWithCsharp.Battle.BattleUseItem.Parser, new[]{ "ItemIds", "UserId", "Quantity" }, null, null, null, null),
             new pbr::GeneratedClrTypeInfo(typeof(global::GoPureWithCsharp.Battle.BattleResume), global::GoPureWithCsharp.Battle.BattleResume.Parser, null, null, null, null, null),
             new pbr::GeneratedClrTypeInfo(typeof(global::GoPureWithCsharp.Battle.BattlePause), global::GoPureWithCsharp.Battle.BattlePause.Parser, null, null, null, null, null),
+            new pbr::GeneratedClrTypeInfo(typeof(global::GoPureWithCsharp.Battle.BattleEnd), global::GoPureWithCsharp.Battle.BattleEnd.Parser, new[]{ "Winner" }, null, null, null, null),
+            new pbr::GeneratedClrTypeInfo(typeof(global::GoPureWithCsharp.Battle.BattleDestroy), global::GoPureWithCsharp.Battle.BattleDestroy.Parser, null, null, null, null, null),
             new pbr::GeneratedClrTypeInfo(typeof(global::GoPureWithCsharp.Battle.BattleOutput), global::GoPureWithCsharp.Battle.BattleOutput.Parser, new[]{ "Result", "Replay" }, new[]{ "Output" }, null, null, null),
             new pbr::GeneratedClrTypeInfo(typeof(global::GoPureWithCsharp.Battle.BattleResult), global::GoPureWithCsharp.Battle.BattleResult.Parser, new[]{ "Winner", "Loser", "AtkDamage", "DefDamage", "Kills", "Duration", "BattleScore" }, null, null, null, null),
-            new pbr::GeneratedClrTypeInfo(typeof(global::GoPureWithCsharp.Battle.BattleStatus), global::GoPureWithCsharp.Battle.BattleStatus.Parser, new[]{ "BattleId", "Round", "AtkHealth", "DefHealth", "State", "Timestamp" }, null, null, null, null),
-            new pbr::GeneratedClrTypeInfo(typeof(global::GoPureWithCsharp.Battle.BattleResponse), global::GoPureWithCsharp.Battle.BattleResponse.Parser, new[]{ "Code", "Message", "Result", "Timestamp" }, null, null, null, null),
+            new pbr::GeneratedClrTypeInfo(typeof(global::GoPureWithCsharp.Battle.BattleStatus), global::GoPureWithCsharp.Battle.BattleStatus.Parser, new[]{ "BattleId", "Round", "AtkHealth", "DefHealth", "State", "Timestamp", "AtkTeamId", "DefTeamId", "Winner", "AtkMaxHealth", "DefMaxHealth", "AtkAttack", "DefAttack", "Units", "ShardId" }, null, null, null, null),
+            new pbr::GeneratedClrTypeInfo(typeof(global::GoPureWithCsharp.Battle.BattleStatusList), global::GoPureWithCsharp.Battle.BattleStatusList.Parser, new[]{ "Battles" }, null, null, null, null),
+            new pbr::GeneratedClrTypeInfo(typeof(global::GoPureWithCsharp.Battle.BattleResponse), global::GoPureWithCsharp.Battle.BattleResponse.Parser, new[]{ "Code", "Message", "Result", "Timestamp", "Exception" }, null, null, null, null),
             new pbr::GeneratedClrTypeInfo(typeof(global::GoPureWithCsharp.Battle.BatchBattleRequest), global::GoPureWithCsharp.Battle.BatchBattleRequest.Parser, new[]{ "Battles", "BatchId", "Parallel" }, null, null, null, null),
             new pbr::GeneratedClrTypeInfo(typeof(global::GoPureWithCsharp.Battle.BatchBattleResponse), global::GoPureWithCsharp.Battle.BatchBattleResponse.Parser, new[]{ "Results", "BatchId", "SuccessCount", "FailureCount", "TotalDuration" }, null, null, null, null),
             new pbr::GeneratedClrTypeInfo(typeof(global::GoPureWithCsharp.Battle.BattleEvent), global::GoPureWithCsharp.Battle.BattleEvent.Parser, new[]{ "Timestamp", "EventType", "PerformerId", "TargetId", "Value", "Extra" }, null, null, null, new pbr::GeneratedClrTypeInfo[] { null, }),
             new pbr::GeneratedClrTypeInfo(typeof(global::GoPureWithCsharp.Battle.BattleReplay), global::GoPureWithCsharp.Battle.BattleReplay.Parser, new[]{ "BattleId", "StartTime", "EndTime", "AtkTeam", "DefTeam", "Events", "Result", "Version" }, null, null, null, null),
             new pbr::GeneratedClrTypeInfo(typeof(global::GoPureWithCsharp.Battle.ProgressReport), global::GoPureWithCsharp.Battle.ProgressReport.Parser, new[]{ "BattleId", "ProgressPercent", "CurrentRound", "Status", "Timestamp" }, null, null, null, null),
             new pbr::GeneratedClrTypeInfo(typeof(global::GoPureWithCsharp.Battle.BattleNotification), global::GoPureWithCsharp.Battle.BattleNotification.Parser, new[]{ "Timestamp", "NotificationType", "BattleId", "Payload", "ErrorMessage" }, null, null, null, null),
-            new pbr::GeneratedClrTypeInfo(typeof(global::GoPureWithCsharp.Battle.BattleContext), global::GoPureWithCsharp.Battle.BattleContext.Parser, new[]{ "BattleId", "Tick", "BattleInput", "BattleOutput" }, new[]{ "Option" }, null, null, null)
+            new pbr::GeneratedClrTypeInfo(typeof(global::GoPureWithCsharp.Battle.BattleContext), global::GoPureWithCsharp.Battle.BattleContext.Parser, new[]{ "BattleId", "Tick", "BattleInput", "BattleOutput" }, new[]{ "Option" }, null, null, null),
+            new pbr::GeneratedClrTypeInfo(typeof(global::GoPureWithCsharp.Battle.BattleSnapshot), global::GoPureWithCsharp.Battle.BattleSnapshot.Parser, new[]{ "BattleId", "AtkTeamId", "DefTeamId", "AtkHealth", "DefHealth", "CurrentRound", "IsFinished", "Winner", "Seed", "RngState", "StartFrame", "Events", "State", "EndRequested", "AtkMaxHealth", "DefMaxHealth", "AtkAttack", "DefAttack", "Units", "AtkTeam", "DefTeam", "ShardId" }, null, null, null, null),
+            new pbr::GeneratedClrTypeInfo(typeof(global::GoPureWithCsharp.Battle.BattleStateSnapshot), global::GoPureWithCsharp.Battle.BattleStateSnapshot.Parser, new[]{ "Timestamp", "Battles" }, null, null, null, null),
+            new pbr::GeneratedClrTypeInfo(typeof(global::GoPureWithCsharp.Battle.LibraryInfo), global::GoPureWithCsharp.Battle.LibraryInfo.Parser, new[]{ "Version", "AbiVersion", "SchemaHash", "Features" }, null, null, null, null),
+            new pbr::GeneratedClrTypeInfo(typeof(global::GoPureWithCsharp.Battle.ManagedException), global::GoPureWithCsharp.Battle.ManagedException.Parser, new[]{ "Type", "Message", "StackTrace", "BattleId", "Export", "Inner", "Timestamp" }, null, null, null, null),
+            new pbr::GeneratedClrTypeInfo(typeof(global::GoPureWithCsharp.Battle.BattleContextBatch), global::GoPureWithCsharp.Battle.BattleContextBatch.Parser, new[]{ "Contexts" }, null, null, null, null),
+            new pbr::GeneratedClrTypeInfo(typeof(global::GoPureWithCsharp.Battle.JournalEntry), global::GoPureWithCsharp.Battle.JournalEntry.Parser, new[]{ "Frame", "Env", "Context" }, new[]{ "Entry" }, null, null, null)
           }));
     }
     #endregion
@@ -185,6 +232,10 @@ namespace GoPureWithCsharp.Battle {
     /// Protobuf 格式错误
     /// </summary>
     [pbr::OriginalName("INVALID_PROTO_FORMAT")] InvalidProtoFormat = 8,
+    /// <summary>
+    /// 当前战斗状态不允许该操作 (例如恢复未暂停的战斗)
+    /// </summary>
+    [pbr::OriginalName("INVALID_BATTLE_STATE")] InvalidBattleState = 9,
   }
 
   /// <summary>
@@ -267,7 +318,7 @@ namespace GoPureWithCsharp.Battle {
         = pb::FieldCodec.ForUInt32(10);
     private readonly pbc::RepeatedField<uint> lineup_ = new pbc::RepeatedField<uint>();
     /// <summary>
-    /// 阵容ID列表
+    /// 阵容ID列表 (单位ID，同一场战斗内不能重复；为空时整个队伍作为一个单位，单位ID为队伍ID)
     /// </summary>
     [global::System.Diagnostics.DebuggerNonUserCodeAttribute]
     [global::System.CodeDom.Compiler.GeneratedCode("protoc", null)]
@@ -479,18 +530,18 @@ namespace GoPureWithCsharp.Battle {
   }
 
   /// <summary>
-  /// 战斗环境
+  /// 战斗单位状态，初始属性来自 unit_config.json (默认值和按单位ID配置的属性)
   /// </summary>
-  public sealed partial class BattleEnv : pb::IMessage<BattleEnv>
+  public sealed partial class BattleUnit : pb::IMessage<BattleUnit>
   #if !GOOGLE_PROTOBUF_REFSTRUCT_COMPATIBILITY_MODE
       , pb::IBufferMessage
   #endif
   {
-    private static readonly pb::MessageParser<BattleEnv> _parser = new pb::MessageParser<BattleEnv>(() => new BattleEnv());
+    private static readonly pb::MessageParser<BattleUnit> _parser = new pb::MessageParser<BattleUnit>(() => new BattleUnit());
     private pb::UnknownFieldSet _unknownFields;
     [global::System.Diagnostics.DebuggerNonUserCodeAttribute]
     [global::System.CodeDom.Compiler.GeneratedCode("protoc", null)]
-    public static pb::MessageParser<BattleEnv> Parser { get { return _parser; } }
+    public static pb::MessageParser<BattleUnit> Parser { get { return _parser; } }
 
     [global::System.Diagnostics.DebuggerNonUserCodeAttribute]
     [global::System.CodeDom.Compiler.GeneratedCode("protoc", null)]
@@ -506,7 +557,7 @@ namespace GoPureWithCsharp.Battle {
 
     [global::System.Diagnostics.DebuggerNonUserCodeAttribute]
     [global::System.CodeDom.Compiler.GeneratedCode("protoc", null)]
-    public BattleEnv() {
+    public BattleUnit() {
       OnConstruction();
     }
 
@@ -514,116 +565,133 @@ namespace GoPureWithCsharp.Battle {
 
     [global::System.Diagnostics.DebuggerNonUserCodeAttribute]
     [global::System.CodeDom.Compiler.GeneratedCode("protoc", null)]
-    public BattleEnv(BattleEnv other) : this() {
-      atk_ = other.atk_ != null ? other.atk_.Clone() : null;
-      def_ = other.def_ != null ? other.def_.Clone() : null;
-      battleId_ = other.battleId_;
-      timestamp_ = other.timestamp_;
-      configVersion_ = other.configVersion_;
+    public BattleUnit(BattleUnit other) : this() {
+      unitId_ = other.unitId_;
+      teamId_ = other.teamId_;
+      maxHealth_ = other.maxHealth_;
+      health_ = other.health_;
+      attack_ = other.attack_;
+      defense_ = other.defense_;
       _unknownFields = pb::UnknownFieldSet.Clone(other._unknownFields);
     }
 
     [global::System.Diagnostics.DebuggerNonUserCodeAttribute]
     [global::System.CodeDom.Compiler.GeneratedCode("protoc", null)]
-    public BattleEnv Clone() {
-      return new BattleEnv(this);
+    public BattleUnit Clone() {
+      return new BattleUnit(this);
     }
 
-    /// <summary>Field number for the "atk" field.</summary>
-    public const int AtkFieldNumber = 1;
-    private global::GoPureWithCsharp.Battle.Team atk_;
+    /// <summary>Field number for the "unit_id" field.</summary>
+    public const int UnitIdFieldNumber = 1;
+    private uint unitId_;
     /// <summary>
-    /// 攻击方队伍
+    /// 单位ID (Team.lineup 中的ID)
     /// </summary>
     [global::System.Diagnostics.DebuggerNonUserCodeAttribute]
     [global::System.CodeDom.Compiler.GeneratedCode("protoc", null)]
-    public global::GoPureWithCsharp.Battle.Team Atk {
-      get { return atk_; }
+    public uint UnitId {
+      get { return unitId_; }
       set {
-        atk_ = value;
+        unitId_ = value;
       }
     }
 
-    /// <summary>Field number for the "def" field.</summary>
-    public const int DefFieldNumber = 2;
-    private global::GoPureWithCsharp.Battle.Team def_;
+    /// <summary>Field number for the "team_id" field.</summary>
+    public const int TeamIdFieldNumber = 2;
+    private uint teamId_;
     /// <summary>
-    /// 防守方队伍
+    /// 所属队伍ID
     /// </summary>
     [global::System.Diagnostics.DebuggerNonUserCodeAttribute]
     [global::System.CodeDom.Compiler.GeneratedCode("protoc", null)]
-    public global::GoPureWithCsharp.Battle.Team Def {
-      get { return def_; }
+    public uint TeamId {
+      get { return teamId_; }
       set {
-        def_ = value;
+        teamId_ = value;
       }
     }
 
-    /// <summary>Field number for the "battle_id" field.</summary>
-    public const int BattleIdFieldNumber = 3;
-    private uint battleId_;
+    /// <summary>Field number for the "max_health" field.</summary>
+    public const int MaxHealthFieldNumber = 3;
+    private int maxHealth_;
     /// <summary>
-    /// 战斗ID
+    /// 最大生命 (属性 3001)
     /// </summary>
     [global::System.Diagnostics.DebuggerNonUserCodeAttribute]
     [global::System.CodeDom.Compiler.GeneratedCode("protoc", null)]
-    public uint BattleId {
-      get { return battleId_; }
+    public int MaxHealth {
+      get { return maxHealth_; }
       set {
-        battleId_ = value;
+        maxHealth_ = value;
       }
     }
 
-    /// <summary>Field number for the "timestamp" field.</summary>
-    public const int TimestampFieldNumber = 4;
-    private long timestamp_;
+    /// <summary>Field number for the "health" field.</summary>
+    public const int HealthFieldNumber = 4;
+    private int health_;
     /// <summary>
-    /// 时间戳
+    /// 当前生命 (属性 4001)，&lt;= 0 表示已被击败
     /// </summary>
     [global::System.Diagnostics.DebuggerNonUserCodeAttribute]
     [global::System.CodeDom.Compiler.GeneratedCode("protoc", null)]
-    public long Timestamp {
-      get { return timestamp_; }
+    public int Health {
+      get { return health_; }
       set {
-        timestamp_ = value;
+        health_ = value;
       }
     }
 
-    /// <summary>Field number for the "config_version" field.</summary>
-    public const int ConfigVersionFieldNumber = 5;
-    private uint configVersion_;
+    /// <summary>Field number for the "attack" field.</summary>
+    public const int AttackFieldNumber = 5;
+    private int attack_;
     /// <summary>
-    /// 配置版本
+    /// 攻击力 (属性 3002)
     /// </summary>
     [global::System.Diagnostics.DebuggerNonUserCodeAttribute]
     [global::System.CodeDom.Compiler.GeneratedCode("protoc", null)]
-    public uint ConfigVersion {
-      get { return configVersion_; }
+    public int Attack {
+      get { return attack_; }
       set {
-        configVersion_ = value;
+        attack_ = value;
+      }
+    }
+
+    /// <summary>Field number for the "defense" field.</summary>
+    public const int DefenseFieldNumber = 6;
+    private int defense_;
+    /// <summary>
+    /// 防御力
+    /// </summary>
+    [global::System.Diagnostics.DebuggerNonUserCodeAttribute]
+    [global::System.CodeDom.Compiler.GeneratedCode("protoc", null)]
+    public int Defense {
+      get { return defense_; }
+      set {
+        defense_ = value;
       }
     }
 
     [global::System.Diagnostics.DebuggerNonUserCodeAttribute]
     [global::System.CodeDom.Compiler.GeneratedCode("protoc", null)]
     public override bool Equals(object other) {
-      return Equals(other as BattleEnv);
+      return Equals(other as BattleUnit);
     }
 
     [global::System.Diagnostics.DebuggerNonUserCodeAttribute]
     [global::System.CodeDom.Compiler.GeneratedCode("protoc", null)]
-    public bool Equals(BattleEnv other) {
+    public bool Equals(BattleUnit other) {
       if (ReferenceEquals(other, null)) {
         return false;
       }
       if (ReferenceEquals(other, this)) {
         return true;
       }
-      if (!object.Equals(Atk, other.Atk)) return false;
-      if (!object.Equals(Def, other.Def)) return false;
-      if (BattleId != other.BattleId) return false;
-      if (Timestamp != other.Timestamp) return false;
-      if (ConfigVersion != other.ConfigVersion) return false;
+      if (UnitId != other.UnitId) return false;
+      if (TeamId != other.TeamId) return false;
+      if (MaxHealth != other.MaxHealth) return false;
+      if (Health != other.Health) return false;
+      if (Attack != other.Attack) return false;
+      if (Defense != other.Defense) return false;
       return Equals(_unknownFields, other._unknownFields);
     }
 
@@ -631,11 +699,12 @@ namespace GoPureWithCsharp.Battle {
     [global::System.CodeDom.Compiler.GeneratedCode("protoc", null)]
     public override int GetHashCode() {
       int hash = 1;
-      if (atk_ != null) hash ^= Atk.GetHashCode();
-      if (def_ != null) hash ^= Def.GetHashCode();
-      if (BattleId != 0) hash ^= BattleId.GetHashCode();
-      if (Timestamp != 0L) hash ^= Timestamp.GetHashCode();
-      if (ConfigVersion != 0) hash ^= ConfigVersion.GetHashCode();
+      if (UnitId != 0) hash ^= UnitId.GetHashCode();
+      if (TeamId != 0) hash ^= TeamId.GetHashCode();
+      if (MaxHealth != 0) hash ^= MaxHealth.GetHashCode();
+      if (Health != 0) hash ^= Health.GetHashCode();
+      if (Attack != 0) hash ^= Attack.GetHashCode();
+      if (Defense != 0) hash ^= Defense.GetHashCode();
       if (_unknownFields != null) {
         hash ^= _unknownFields.GetHashCode();
       }
@@ -654,25 +723,29 @@ namespace GoPureWithCsharp.Battle {
     #if !GOOGLE_PROTOBUF_REFSTRUCT_COMPATIBILITY_MODE
       output.WriteRawMessage(this);
     #else
-      if (atk_ != null) {
-        output.WriteRawTag(10);
-        output.WriteMessage(Atk);
+      if (UnitId != 0) {
+        output.WriteRawTag(8);
+        output.WriteUInt32(UnitId);
       }
-      if (def_ != null) {
-        output.WriteRawTag(18);
-        output.WriteMessage(Def);
+      if (TeamId != 0) {
+        output.WriteRawTag(16);
+        output.WriteUInt32(TeamId);
       }
-      if (BattleId != 0) {
+      if (MaxHealth != 0) {
         output.WriteRawTag(24);
-        output.WriteUInt32(BattleId);
+        output.WriteInt32(MaxHealth);
       }
-      if (Timestamp != 0L) {
+      if (Health != 0) {
         output.WriteRawTag(32);
-        output.WriteInt64(Timestamp);
+        output.WriteInt32(Health);
       }
-      if (ConfigVersion != 0) {
+      if (Attack != 0) {
         output.WriteRawTag(40);
-        output.WriteUInt32(ConfigVersion);
+        output.WriteInt32(Attack);
+      }
+      if (Defense != 0) {
+        output.WriteRawTag(48);
+        output.WriteInt32(Defense);
       }
       if (_unknownFields != null) {
         _unknownFields.WriteTo(output);
@@ -684,25 +757,29 @@ namespace GoPureWithCsharp.Battle {
     [global::System.Diagnostics.DebuggerNonUserCodeAttribute]
     [global::System.CodeDom.Compiler.GeneratedCode("protoc", null)]
     void pb::IBufferMessage.InternalWriteTo(ref pb::WriteContext output) {
-      if (atk_ != null) {
-        output.WriteRawTag(10);
-        output.WriteMessage(Atk);
+      if (UnitId != 0) {
+        output.WriteRawTag(8);
+        output.WriteUInt32(UnitId);
       }
-      if (def_ != null) {
-        output.WriteRawTag(18);
-        output.WriteMessage(Def);
+      if (TeamId != 0) {
+        output.WriteRawTag(16);
+        output.WriteUInt32(TeamId);
       }
-      if (BattleId != 0) {
+      if (MaxHealth != 0) {
         output.WriteRawTag(24);
-        output.WriteUInt32(BattleId);
+        output.WriteInt32(MaxHealth);
       }
-      if (Timestamp != 0L) {
+      if (Health != 0) {
         output.WriteRawTag(32);
-        output.WriteInt64(Timestamp);
+        output.WriteInt32(Health);
       }
-      if (ConfigVersion != 0) {
+      if (Attack != 0) {
         output.WriteRawTag(40);
-        output.WriteUInt32(ConfigVersion);
+        output.WriteInt32(Attack);
+      }
+      if (Defense != 0) {
+        output.WriteRawTag(48);
+        output.WriteInt32(Defense);
       }
       if (_unknownFields != null) {
         _unknownFields.WriteTo(ref output);
@@ -714,20 +791,23 @@ namespace GoPureWithCsharp.Battle {
     [global::System.CodeDom.Compiler.GeneratedCode("protoc", null)]
     public int CalculateSize() {
       int size = 0;
-      if (atk_ != null) {
-        size += 1 + pb::CodedOutputStream.ComputeMessageSize(Atk);
+      if (UnitId != 0) {
+        size += 1 + pb::CodedOutputStream.ComputeUInt32Size(UnitId);
       }
-      if (def_ != null) {
-        size += 1 + pb::CodedOutputStream.ComputeMessageSize(Def);
+      if (TeamId != 0) {
+        size += 1 + pb::CodedOutputStream.ComputeUInt32Size(TeamId);
       }
-      if (BattleId != 0) {
-        size += 1 + pb::CodedOutputStream.ComputeUInt32Size(BattleId);
+      if (MaxHealth != 0) {
+        size += 1 + pb::CodedOutputStream.ComputeInt32Size(MaxHealth);
       }
-      if (Timestamp != 0L) {
-        size += 1 + pb::CodedOutputStream.ComputeInt64Size(Timestamp);
+      if (Health != 0) {
+        size += 1 + pb::CodedOutputStream.ComputeInt32Size(Health);
       }
-      if (ConfigVersion != 0) {
-        size += 1 + pb::CodedOutputStream.ComputeUInt32Size(ConfigVersion);
+      if (Attack != 0) {
+        size += 1 + pb::CodedOutputStream.ComputeInt32Size(Attack);
+      }
+      if (Defense != 0) {
+        size += 1 + pb::CodedOutputStream.ComputeInt32Size(Defense);
       }
       if (_unknownFields != null) {
         size += _unknownFields.CalculateSize();
@@ -737,30 +817,27 @@ namespace GoPureWithCsharp.Battle {
 
     [global::System.Diagnostics.DebuggerNonUserCodeAttribute]
     [global::System.CodeDom.Compiler.GeneratedCode("protoc", null)]
-    public void MergeFrom(BattleEnv other) {
+    public void MergeFrom(BattleUnit other) {
       if (other == null) {
         return;
       }
-      if (other.atk_ != null) {
-        if (atk_ == null) {
-          Atk = new global::GoPureWithCsharp.Battle.Team();
-        }
-        Atk.MergeFrom(other.Atk);
+      if (other.UnitId != 0) {
+        UnitId = other.UnitId;
       }
-      if (other.def_ != null) {
-        if (def_ == null) {
-          Def = new global::GoPureWithCsharp.Battle.Team();
-        }
-        Def.MergeFrom(other.Def);
+      if (other.TeamId != 0) {
+        TeamId = other.TeamId;
       }
-      if (other.BattleId != 0) {
-        BattleId = other.BattleId;
+      if (other.MaxHealth != 0) {
+        MaxHealth = other.MaxHealth;
       }
-      if (other.Timestamp != 0L) {
-        Timestamp = other.Timestamp;
+      if (other.Health != 0) {
+        Health = other.Health;
       }
-      if (other.ConfigVersion != 0) {
-        ConfigVersion = other.ConfigVersion;
+      if (other.Attack != 0) {
+        Attack = other.Attack;
+      }
+      if (other.Defense != 0) {
+        Defense = other.Defense;
       }
       _unknownFields = pb::UnknownFieldSet.MergeFrom(_unknownFields, other._unknownFields);
     }
@@ -777,30 +854,28 @@ namespace GoPureWithCsharp.Battle {
           default:
             _unknownFields = pb::UnknownFieldSet.MergeFieldFrom(_unknownFields, input);
             break;
-          case 10: {
-            if (atk_ == null) {
-              Atk = new global::GoPureWithCsharp.Battle.Team();
-            }
-            input.ReadMessage(Atk);
+          case 8: {
+            UnitId = input.ReadUInt32();
             break;
           }
-          case 18: {
-            if (def_ == null) {
-              Def = new global::GoPureWithCsharp.Battle.Team();
-            }
-            input.ReadMessage(Def);
+          case 16: {
+            TeamId = input.ReadUInt32();
             break;
           }
           case 24: {
-            BattleId = input.ReadUInt32();
+            MaxHealth = input.ReadInt32();
             break;
           }
           case 32: {
-            Timestamp = input.ReadInt64();
+            Health = input.ReadInt32();
             break;
           }
           case 40: {
-            ConfigVersion = input.ReadUInt32();
+            Attack = input.ReadInt32();
+            break;
+          }
+          case 48: {
+            Defense = input.ReadInt32();
             break;
           }
         }
@@ -818,30 +893,28 @@ namespace GoPureWithCsharp.Battle {
           default:
             _unknownFields = pb::UnknownFieldSet.MergeFieldFrom(_unknownFields, ref input);
             break;
-          case 10: {
-            if (atk_ == null) {
-              Atk = new global::GoPureWithCsharp.Battle.Team();
-            }
-            input.ReadMessage(Atk);
+          case 8: {
+            UnitId = input.ReadUInt32();
             break;
           }
-          case 18: {
-            if (def_ == null) {
-              Def = new global::GoPureWithCsharp.Battle.Team();
-            }
-            input.ReadMessage(Def);
+          case 16: {
+            TeamId = input.ReadUInt32();
             break;
           }
           case 24: {
-            BattleId = input.ReadUInt32();
+            MaxHealth = input.ReadInt32();
             break;
           }
           case 32: {
-            Timestamp = input.ReadInt64();
+            Health = input.ReadInt32();
             break;
           }
           case 40: {
-            ConfigVersion = input.ReadUInt32();
+            Attack = input.ReadInt32();
+            break;
+          }
+          case 48: {
+            Defense = input.ReadInt32();
             break;
           }
         }
@@ -852,18 +925,18 @@ namespace GoPureWithCsharp.Battle {
   }
 
   /// <summary>
-  /// 开始战斗请求
+  /// 战斗环境
   /// </summary>
-  public sealed partial class StartBattle : pb::IMessage<StartBattle>
+  public sealed partial class BattleEnv : pb::IMessage<BattleEnv>
   #if !GOOGLE_PROTOBUF_REFSTRUCT_COMPATIBILITY_MODE
       , pb::IBufferMessage
   #endif
   {
-    private static readonly pb::MessageParser<StartBattle> _parser = new pb::MessageParser<StartBattle>(() => new StartBattle());
+    private static readonly pb::MessageParser<BattleEnv> _parser = new pb::MessageParser<BattleEnv>(() => new BattleEnv());
     private pb::UnknownFieldSet _unknownFields;
     [global::System.Diagnostics.DebuggerNonUserCodeAttribute]
     [global::System.CodeDom.Compiler.GeneratedCode("protoc", null)]
-    public static pb::MessageParser<StartBattle> Parser { get { return _parser; } }
+    public static pb::MessageParser<BattleEnv> Parser { get { return _parser; } }
 
     [global::System.Diagnostics.DebuggerNonUserCodeAttribute]
     [global::System.CodeDom.Compiler.GeneratedCode("protoc", null)]
@@ -879,7 +952,7 @@ namespace GoPureWithCsharp.Battle {
 
     [global::System.Diagnostics.DebuggerNonUserCodeAttribute]
     [global::System.CodeDom.Compiler.GeneratedCode("protoc", null)]
-    public StartBattle() {
+    public BattleEnv() {
       OnConstruction();
     }
 
@@ -887,18 +960,20 @@ namespace GoPureWithCsharp.Battle {
 
     [global::System.Diagnostics.DebuggerNonUserCodeAttribute]
     [global::System.CodeDom.Compiler.GeneratedCode("protoc", null)]
-    public StartBattle(StartBattle other) : this() {
+    public BattleEnv(BattleEnv other) : this() {
       atk_ = other.atk_ != null ? other.atk_.Clone() : null;
       def_ = other.def_ != null ? other.def_.Clone() : null;
       battleId_ = other.battleId_;
       timestamp_ = other.timestamp_;
+      configVersion_ = other.configVersion_;
+      seed_ = other.seed_;
       _unknownFields = pb::UnknownFieldSet.Clone(other._unknownFields);
     }
 
     [global::System.Diagnostics.DebuggerNonUserCodeAttribute]
     [global::System.CodeDom.Compiler.GeneratedCode("protoc", null)]
-    public StartBattle Clone() {
-      return new StartBattle(this);
+    public BattleEnv Clone() {
+      return new BattleEnv(this);
     }
 
     /// <summary>Field number for the "atk" field.</summary>
@@ -961,15 +1036,45 @@ namespace GoPureWithCsharp.Battle {
       }
     }
 
+    /// <summary>Field number for the "config_version" field.</summary>
+    public const int ConfigVersionFieldNumber = 5;
+    private uint configVersion_;
+    /// <summary>
+    /// 配置版本
+    /// </summary>
+    [global::System.Diagnostics.DebuggerNonUserCodeAttribute]
+    [global::System.CodeDom.Compiler.GeneratedCode("protoc", null)]
+    public uint ConfigVersion {
+      get { return configVersion_; }
+      set {
+        configVersion_ = value;
+      }
+    }
+
+    /// <summary>Field number for the "seed" field.</summary>
+    public const int SeedFieldNumber = 6;
+    private ulong seed_;
+    /// <summary>
+    /// 随机种子，相同种子和输入得到相同结果 (为 0 时由 Go 侧分配)
+    /// </summary>
+    [global::System.Diagnostics.DebuggerNonUserCodeAttribute]
+    [global::System.CodeDom.Compiler.GeneratedCode("protoc", null)]
+    public ulong Seed {
+      get { return seed_; }
+      set {
+        seed_ = value;
+      }
+    }
+
     [global::System.Diagnostics.DebuggerNonUserCodeAttribute]
     [global::System.CodeDom.Compiler.GeneratedCode("protoc", null)]
     public override bool Equals(object other) {
-      return Equals(other as StartBattle);
+      return Equals(other as BattleEnv);
     }
 
     [global::System.Diagnostics.DebuggerNonUserCodeAttribute]
     [global::System.CodeDom.Compiler.GeneratedCode("protoc", null)]
-    public bool Equals(StartBattle other) {
+    public bool Equals(BattleEnv other) {
       if (ReferenceEquals(other, null)) {
         return false;
       }
@@ -980,6 +1085,8 @@ namespace GoPureWithCsharp.Battle {
       if (!object.Equals(Def, other.Def)) return false;
       if (BattleId != other.BattleId) return false;
       if (Timestamp != other.Timestamp) return false;
+      if (ConfigVersion != other.ConfigVersion) return false;
+      if (Seed != other.Seed) return false;
       return Equals(_unknownFields, other._unknownFields);
     }
 
@@ -991,6 +1098,8 @@ namespace GoPureWithCsharp.Battle {
       if (def_ != null) hash ^= Def.GetHashCode();
       if (BattleId != 0) hash ^= BattleId.GetHashCode();
       if (Timestamp != 0L) hash ^= Timestamp.GetHashCode();
+      if (ConfigVersion != 0) hash ^= ConfigVersion.GetHashCode();
+      if (Seed != 0UL) hash ^= Seed.GetHashCode();
       if (_unknownFields != null) {
         hash ^= _unknownFields.GetHashCode();
       }
@@ -1025,6 +1134,14 @@ namespace GoPureWithCsharp.Battle {
         output.WriteRawTag(32);
         output.WriteInt64(Timestamp);
       }
+      if (ConfigVersion != 0) {
+        output.WriteRawTag(40);
+        output.WriteUInt32(ConfigVersion);
+      }
+      if (Seed != 0UL) {
+        output.WriteRawTag(48);
+        output.WriteUInt64(Seed);
+      }
       if (_unknownFields != null) {
         _unknownFields.WriteTo(output);
       }
@@ -1051,6 +1168,14 @@ namespace GoPureWithCsharp.Battle {
         output.WriteRawTag(32);
         output.WriteInt64(Timestamp);
       }
+      if (ConfigVersion != 0) {
+        output.WriteRawTag(40);
+        output.WriteUInt32(ConfigVersion);
+      }
+      if (Seed != 0UL) {
+        output.WriteRawTag(48);
+        output.WriteUInt64(Seed);
+      }
       if (_unknownFields != null) {
         _unknownFields.WriteTo(ref output);
       }
@@ -1073,6 +1198,12 @@ namespace GoPureWithCsharp.Battle {
       if (Timestamp != 0L) {
         size += 1 + pb::CodedOutputStream.ComputeInt64Size(Timestamp);
       }
+      if (ConfigVersion != 0) {
+        size += 1 + pb::CodedOutputStream.ComputeUInt32Size(ConfigVersion);
+      }
+      if (Seed != 0UL) {
+        size += 1 + pb::CodedOutputStream.ComputeUInt64Size(Seed);
+      }
       if (_unknownFields != null) {
         size += _unknownFields.CalculateSize();
       }
@@ -1081,7 +1212,7 @@ namespace GoPureWithCsharp.Battle {
 
     [global::System.Diagnostics.DebuggerNonUserCodeAttribute]
     [global::System.CodeDom.Compiler.GeneratedCode("protoc", null)]
-    public void MergeFrom(StartBattle other) {
+    public void MergeFrom(BattleEnv other) {
       if (other == null) {
         return;
       }
@@ -1103,6 +1234,12 @@ namespace GoPureWithCsharp.Battle {
       if (other.Timestamp != 0L) {
         Timestamp = other.Timestamp;
       }
+      if (other.ConfigVersion != 0) {
+        ConfigVersion = other.ConfigVersion;
+      }
+      if (other.Seed != 0UL) {
+        Seed = other.Seed;
+      }
       _unknownFields = pb::UnknownFieldSet.MergeFrom(_unknownFields, other._unknownFields);
     }
 
@@ -1140,8 +1277,16 @@ namespace GoPureWithCsharp.Battle {
             Timestamp = input.ReadInt64();
             break;
           }
-        }
-      }
+          case 40: {
+            ConfigVersion = input.ReadUInt32();
+            break;
+          }
+          case 48: {
+            Seed = input.ReadUInt64();
+            break;
+          }
+        }
+      }
     #endif
     }
 
@@ -1177,6 +1322,14 @@ namespace GoPureWithCsharp.Battle {
             Timestamp = input.ReadInt64();
             break;
           }
+          case 40: {
+            ConfigVersion = input.ReadUInt32();
+            break;
+          }
+          case 48: {
+            Seed = input.ReadUInt64();
+            break;
+          }
         }
       }
     }
@@ -1185,18 +1338,18 @@ namespace GoPureWithCsharp.Battle {
   }
 
   /// <summary>
-  /// 战斗输入 (通用请求格式)
+  /// 开始战斗请求
   /// </summary>
-  public sealed partial class BattleInput : pb::IMessage<BattleInput>
+  public sealed partial class StartBattle : pb::IMessage<StartBattle>
   #if !GOOGLE_PROTOBUF_REFSTRUCT_COMPATIBILITY_MODE
       , pb::IBufferMessage
   #endif
   {
-    private static readonly pb::MessageParser<BattleInput> _parser = new pb::MessageParser<BattleInput>(() => new BattleInput());
+    private static readonly pb::MessageParser<StartBattle> _parser = new pb::MessageParser<StartBattle>(() => new StartBattle());
     private pb::UnknownFieldSet _unknownFields;
     [global::System.Diagnostics.DebuggerNonUserCodeAttribute]
     [global::System.CodeDom.Compiler.GeneratedCode("protoc", null)]
-    public static pb::MessageParser<BattleInput> Parser { get { return _parser; } }
+    public static pb::MessageParser<StartBattle> Parser { get { return _parser; } }
 
     [global::System.Diagnostics.DebuggerNonUserCodeAttribute]
     [global::System.CodeDom.Compiler.GeneratedCode("protoc", null)]
@@ -1212,7 +1365,7 @@ namespace GoPureWithCsharp.Battle {
 
     [global::System.Diagnostics.DebuggerNonUserCodeAttribute]
     [global::System.CodeDom.Compiler.GeneratedCode("protoc", null)]
-    public BattleInput() {
+    public StartBattle() {
       OnConstruction();
     }
 
@@ -1220,122 +1373,116 @@ namespace GoPureWithCsharp.Battle {
 
     [global::System.Diagnostics.DebuggerNonUserCodeAttribute]
     [global::System.CodeDom.Compiler.GeneratedCode("protoc", null)]
-    public BattleInput(BattleInput other) : this() {
-      switch (other.InputCase) {
-        case InputOneofCase.Use:
-          Use = other.Use.Clone();
-          break;
-        case InputOneofCase.Resume:
-          Resume = other.Resume.Clone();
-          break;
-        case InputOneofCase.Pause:
-          Pause = other.Pause.Clone();
-          break;
-        case InputOneofCase.UserOp:
-          UserOp = other.UserOp.Clone();
-          break;
-      }
-
+    public StartBattle(StartBattle other) : this() {
+      atk_ = other.atk_ != null ? other.atk_.Clone() : null;
+      def_ = other.def_ != null ? other.def_.Clone() : null;
+      battleId_ = other.battleId_;
+      timestamp_ = other.timestamp_;
+      seed_ = other.seed_;
       _unknownFields = pb::UnknownFieldSet.Clone(other._unknownFields);
     }
 
     [global::System.Diagnostics.DebuggerNonUserCodeAttribute]
     [global::System.CodeDom.Compiler.GeneratedCode("protoc", null)]
-    public BattleInput Clone() {
-      return new BattleInput(this);
+    public StartBattle Clone() {
+      return new StartBattle(this);
     }
 
-    /// <summary>Field number for the "use" field.</summary>
-    public const int UseFieldNumber = 1;
+    /// <summary>Field number for the "atk" field.</summary>
+    public const int AtkFieldNumber = 1;
+    private global::GoPureWithCsharp.Battle.Team atk_;
+    /// <summary>
+    /// 攻击方队伍
+    /// </summary>
     [global::System.Diagnostics.DebuggerNonUserCodeAttribute]
     [global::System.CodeDom.Compiler.GeneratedCode("protoc", null)]
-    public global::GoPureWithCsharp.Battle.BattleUseItem Use {
-      get { return inputCase_ == InputOneofCase.Use ? (global::GoPureWithCsharp.Battle.BattleUseItem) input_ : null; }
+    public global::GoPureWithCsharp.Battle.Team Atk {
+      get { return atk_; }
       set {
-        input_ = value;
-        inputCase_ = value == null ? InputOneofCase.None : InputOneofCase.Use;
+        atk_ = value;
       }
     }
 
-    /// <summary>Field number for the "resume" field.</summary>
-    public const int ResumeFieldNumber = 2;
+    /// <summary>Field number for the "def" field.</summary>
+    public const int DefFieldNumber = 2;
+    private global::GoPureWithCsharp.Battle.Team def_;
+    /// <summary>
+    /// 防守方队伍
+    /// </summary>
     [global::System.Diagnostics.DebuggerNonUserCodeAttribute]
     [global::System.CodeDom.Compiler.GeneratedCode("protoc", null)]
-    public global::GoPureWithCsharp.Battle.BattleResume Resume {
-      get { return inputCase_ == InputOneofCase.Resume ? (global::GoPureWithCsharp.Battle.BattleResume) input_ : null; }
+    public global::GoPureWithCsharp.Battle.Team Def {
+      get { return def_; }
       set {
-        input_ = value;
-        inputCase_ = value == null ? InputOneofCase.None : InputOneofCase.Resume;
+        def_ = value;
       }
     }
 
-    /// <summary>Field number for the "pause" field.</summary>
-    public const int PauseFieldNumber = 3;
+    /// <summary>Field number for the "battle_id" field.</summary>
+    public const int BattleIdFieldNumber = 3;
+    private uint battleId_;
+    /// <summary>
+    /// 战斗ID
+    /// </summary>
     [global::System.Diagnostics.DebuggerNonUserCodeAttribute]
     [global::System.CodeDom.Compiler.GeneratedCode("protoc", null)]
-    public global::GoPureWithCsharp.Battle.BattlePause Pause {
-      get { return inputCase_ == InputOneofCase.Pause ? (global::GoPureWithCsharp.Battle.BattlePause) input_ : null; }
+    public uint BattleId {
+      get { return battleId_; }
       set {
-        input_ = value;
-        inputCase_ = value == null ? InputOneofCase.None : InputOneofCase.Pause;
+        battleId_ = value;
       }
     }
 
-    /// <summary>Field number for the "user_op" field.</summary>
-    public const int UserOpFieldNumber = 4;
+    /// <summary>Field number for the "timestamp" field.</summary>
+    public const int TimestampFieldNumber = 4;
+    private long timestamp_;
+    /// <summary>
+    /// 时间戳
+    /// </summary>
     [global::System.Diagnostics.DebuggerNonUserCodeAttribute]
     [global::System.CodeDom.Compiler.GeneratedCode("protoc", null)]
-    public global::GoPureWithCsharp.Battle.BattleUserOp UserOp {
-      get { return inputCase_ == InputOneofCase.UserOp ? (global::GoPureWithCsharp.Battle.BattleUserOp) input_ : null; }
+    public long Timestamp {
+      get { return timestamp_; }
       set {
-        input_ = value;
-        inputCase_ = value == null ? InputOneofCase.None : InputOneofCase.UserOp;
+        timestamp_ = value;
       }
     }
 
-    private object input_;
-    /// <summary>Enum of possible cases for the "input" oneof.</summary>
-    public enum InputOneofCase {
-      None = 0,
-      Use = 1,
-      Resume = 2,
-      Pause = 3,
-      UserOp = 4,
-    }
-    private InputOneofCase inputCase_ = InputOneofCase.None;
-    [global::System.Diagnostics.DebuggerNonUserCodeAttribute]
-    [global::System.CodeDom.Compiler.GeneratedCode("protoc", null)]
-    public InputOneofCase InputCase {
-      get { return inputCase_; }
-    }
-
+    /// <summary>Field number for the "seed" field.</summary>
+    public const int SeedFieldNumber = 5;
+    private ulong seed_;
+    /// <summary>
+    /// 随机种子
+    /// </summary>
     [global::System.Diagnostics.DebuggerNonUserCodeAttribute]
     [global::System.CodeDom.Compiler.GeneratedCode("protoc", null)]
-    public void ClearInput() {
-      inputCase_ = InputOneofCase.None;
-      input_ = null;
+    public ulong Seed {
+      get { return seed_; }
+      set {
+        seed_ = value;
+      }
     }
 
     [global::System.Diagnostics.DebuggerNonUserCodeAttribute]
     [global::System.CodeDom.Compiler.GeneratedCode("protoc", null)]
     public override bool Equals(object other) {
-      return Equals(other as BattleInput);
+      return Equals(other as StartBattle);
     }
 
     [global::System.Diagnostics.DebuggerNonUserCodeAttribute]
     [global::System.CodeDom.Compiler.GeneratedCode("protoc", null)]
-    public bool Equals(BattleInput other) {
+    public bool Equals(StartBattle other) {
       if (ReferenceEquals(other, null)) {
         return false;
       }
       if (ReferenceEquals(other, this)) {
         return true;
       }
-      if (!object.Equals(Use, other.Use)) return false;
-      if (!object.Equals(Resume, other.Resume)) return false;
-      if (!object.Equals(Pause, other.Pause)) return false;
-      if (!object.Equals(UserOp, other.UserOp)) return false;
-      if (InputCase != other.InputCase) return false;
+      if (!object.Equals(Atk, other.Atk)) return false;
+      if (!object.Equals(Def, other.Def)) return false;
+      if (BattleId != other.BattleId) return false;
+      if (Timestamp != other.Timestamp) return false;
+      if (Seed != other.Seed) return false;
       return Equals(_unknownFields, other._unknownFields);
     }
 
@@ -1343,11 +1490,11 @@ namespace GoPureWithCsharp.Battle {
     [global::System.CodeDom.Compiler.GeneratedCode("protoc", null)]
     public override int GetHashCode() {
       int hash = 1;
-      if (inputCase_ == InputOneofCase.Use) hash ^= Use.GetHashCode();
-      if (inputCase_ == InputOneofCase.Resume) hash ^= Resume.GetHashCode();
-      if (inputCase_ == InputOneofCase.Pause) hash ^= Pause.GetHashCode();
-      if (inputCase_ == InputOneofCase.UserOp) hash ^= UserOp.GetHashCode();
-      hash ^= (int) inputCase_;
+      if (atk_ != null) hash ^= Atk.GetHashCode();
+      if (def_ != null) hash ^= Def.GetHashCode();
+      if (BattleId != 0) hash ^= BattleId.GetHashCode();
+      if (Timestamp != 0L) hash ^= Timestamp.GetHashCode();
+      if (Seed != 0UL) hash ^= Seed.GetHashCode();
       if (_unknownFields != null) {
         hash ^= _unknownFields.GetHashCode();
       }
@@ -1366,21 +1513,25 @@ namespace GoPureWithCsharp.Battle {
     #if !GOOGLE_PROTOBUF_REFSTRUCT_COMPATIBILITY_MODE
       output.WriteRawMessage(this);
     #else
-      if (inputCase_ == InputOneofCase.Use) {
+      if (atk_ != null) {
         output.WriteRawTag(10);
-        output.WriteMessage(Use);
+        output.WriteMessage(Atk);
       }
-      if (inputCase_ == InputOneofCase.Resume) {
+      if (def_ != null) {
         output.WriteRawTag(18);
-        output.WriteMessage(Resume);
+        output.WriteMessage(Def);
       }
-      if (inputCase_ == InputOneofCase.Pause) {
-        output.WriteRawTag(26);
-        output.WriteMessage(Pause);
+      if (BattleId != 0) {
+        output.WriteRawTag(24);
+        output.WriteUInt32(BattleId);
       }
-      if (inputCase_ == InputOneofCase.UserOp) {
-        output.WriteRawTag(34);
-        output.WriteMessage(UserOp);
+      if (Timestamp != 0L) {
+        output.WriteRawTag(32);
+        output.WriteInt64(Timestamp);
+      }
+      if (Seed != 0UL) {
+        output.WriteRawTag(40);
+        output.WriteUInt64(Seed);
       }
       if (_unknownFields != null) {
         _unknownFields.WriteTo(output);
@@ -1392,21 +1543,25 @@ namespace GoPureWithCsharp.Battle {
     [global::System.Diagnostics.DebuggerNonUserCodeAttribute]
     [global::System.CodeDom.Compiler.GeneratedCode("protoc", null)]
     void pb::IBufferMessage.InternalWriteTo(ref pb::WriteContext output) {
-      if (inputCase_ == InputOneofCase.Use) {
+      if (atk_ != null) {
         output.WriteRawTag(10);
-        output.WriteMessage(Use);
+        output.WriteMessage(Atk);
       }
-      if (inputCase_ == InputOneofCase.Resume) {
+      if (def_ != null) {
         output.WriteRawTag(18);
-        output.WriteMessage(Resume);
+        output.WriteMessage(Def);
       }
-      if (inputCase_ == InputOneofCase.Pause) {
-        output.WriteRawTag(26);
-        output.WriteMessage(Pause);
+      if (BattleId != 0) {
+        output.WriteRawTag(24);
+        output.WriteUInt32(BattleId);
       }
-      if (inputCase_ == InputOneofCase.UserOp) {
-        output.WriteRawTag(34);
-        output.WriteMessage(UserOp);
+      if (Timestamp != 0L) {
+        output.WriteRawTag(32);
+        output.WriteInt64(Timestamp);
+      }
+      if (Seed != 0UL) {
+        output.WriteRawTag(40);
+        output.WriteUInt64(Seed);
       }
       if (_unknownFields != null) {
         _unknownFields.WriteTo(ref output);
@@ -1418,17 +1573,20 @@ namespace GoPureWithCsharp.Battle {
     [global::System.CodeDom.Compiler.GeneratedCode("protoc", null)]
     public int CalculateSize() {
       int size = 0;
-      if (inputCase_ == InputOneofCase.Use) {
-        size += 1 + pb::CodedOutputStream.ComputeMessageSize(Use);
+      if (atk_ != null) {
+        size += 1 + pb::CodedOutputStream.ComputeMessageSize(Atk);
       }
-      if (inputCase_ == InputOneofCase.Resume) {
-        size += 1 + pb::CodedOutputStream.ComputeMessageSize(Resume);
+      if (def_ != null) {
+        size += 1 + pb::CodedOutputStream.ComputeMessageSize(Def);
       }
-      if (inputCase_ == InputOneofCase.Pause) {
-        size += 1 + pb::CodedOutputStream.ComputeMessageSize(Pause);
+      if (BattleId != 0) {
+        size += 1 + pb::CodedOutputStream.ComputeUInt32Size(BattleId);
       }
-      if (inputCase_ == InputOneofCase.UserOp) {
-        size += 1 + pb::CodedOutputStream.ComputeMessageSize(UserOp);
+      if (Timestamp != 0L) {
+        size += 1 + pb::CodedOutputStream.ComputeInt64Size(Timestamp);
+      }
+      if (Seed != 0UL) {
+        size += 1 + pb::CodedOutputStream.ComputeUInt64Size(Seed);
       }
       if (_unknownFields != null) {
         size += _unknownFields.CalculateSize();
@@ -1438,43 +1596,37 @@ namespace GoPureWithCsharp.Battle {
 
     [global::System.Diagnostics.DebuggerNonUserCodeAttribute]
     [global::System.CodeDom.Compiler.GeneratedCode("protoc", null)]
-    public void MergeFrom(BattleInput other) {
+    public void MergeFrom(StartBattle other) {
       if (other == null) {
         return;
       }
-      switch (other.InputCase) {
-        case InputOneofCase.Use:
-          if (Use == null) {
-            Use = new global::GoPureWithCsharp.Battle.BattleUseItem();
-          }
-          Use.MergeFrom(other.Use);
-          break;
-        case InputOneofCase.Resume:
-          if (Resume == null) {
-            Resume = new global::GoPureWithCsharp.Battle.BattleResume();
-          }
-          Resume.MergeFrom(other.Resume);
-          break;
-        case InputOneofCase.Pause:
-          if (Pause == null) {
-            Pause = new global::GoPureWithCsharp.Battle.BattlePause();
-          }
-          Pause.MergeFrom(other.Pause);
-          break;
-        case InputOneofCase.UserOp:
-          if (UserOp == null) {
-            UserOp = new global::GoPureWithCsharp.Battle.BattleUserOp();
-          }
-          UserOp.MergeFrom(other.UserOp);
-          break;
+      if (other.atk_ != null) {
+        if (atk_ == null) {
+          Atk = new global::GoPureWithCsharp.Battle.Team();
+        }
+        Atk.MergeFrom(other.Atk);
       }
-
-      _unknownFields = pb::UnknownFieldSet.MergeFrom(_unknownFields, other._unknownFields);
-    }
-
-    [global::System.Diagnostics.DebuggerNonUserCodeAttribute]
-    [global::System.CodeDom.Compiler.GeneratedCode("protoc", null)]
-    public void MergeFrom(pb::CodedInputStream input) {
+      if (other.def_ != null) {
+        if (def_ == null) {
+          Def = new global::GoPureWithCsharp.Battle.Team();
+        }
+        Def.MergeFrom(other.Def);
+      }
+      if (other.BattleId != 0) {
+        BattleId = other.BattleId;
+      }
+      if (other.Timestamp != 0L) {
+        Timestamp = other.Timestamp;
+      }
+      if (other.Seed != 0UL) {
+        Seed = other.Seed;
+      }
+      _unknownFields = pb::UnknownFieldSet.MergeFrom(_unknownFields, other._unknownFields);
+    }
+
+    [global::System.Diagnostics.DebuggerNonUserCodeAttribute]
+    [global::System.CodeDom.Compiler.GeneratedCode("protoc", null)]
+    public void MergeFrom(pb::CodedInputStream input) {
     #if !GOOGLE_PROTOBUF_REFSTRUCT_COMPATIBILITY_MODE
       input.ReadRawMessage(this);
     #else
@@ -1485,39 +1637,29 @@ namespace GoPureWithCsharp.Battle {
             _unknownFields = pb::UnknownFieldSet.MergeFieldFrom(_unknownFields, input);
             break;
           case 10: {
-            global::GoPureWithCsharp.Battle.BattleUseItem subBuilder = new global::GoPureWithCsharp.Battle.BattleUseItem();
-            if (inputCase_ == InputOneofCase.Use) {
-              subBuilder.MergeFrom(Use);
+            if (atk_ == null) {
+              Atk = new global::GoPureWithCsharp.Battle.Team();
             }
-            input.ReadMessage(subBuilder);
-            Use = subBuilder;
+            input.ReadMessage(Atk);
             break;
           }
           case 18: {
-            global::GoPureWithCsharp.Battle.BattleResume subBuilder = new global::GoPureWithCsharp.Battle.BattleResume();
-            if (inputCase_ == InputOneofCase.Resume) {
-              subBuilder.MergeFrom(Resume);
+            if (def_ == null) {
+              Def = new global::GoPureWithCsharp.Battle.Team();
             }
-            input.ReadMessage(subBuilder);
-            Resume = subBuilder;
+            input.ReadMessage(Def);
             break;
           }
-          case 26: {
-            global::GoPureWithCsharp.Battle.BattlePause subBuilder = new global::GoPureWithCsharp.Battle.BattlePause();
-            if (inputCase_ == InputOneofCase.Pause) {
-              subBuilder.MergeFrom(Pause);
-            }
-            input.ReadMessage(subBuilder);
-            Pause = subBuilder;
+          case 24: {
+            BattleId = input.ReadUInt32();
             break;
           }
-          case 34: {
-            global::GoPureWithCsharp.Battle.BattleUserOp subBuilder = new global::GoPureWithCsharp.Battle.BattleUserOp();
-            if (inputCase_ == InputOneofCase.UserOp) {
-              subBuilder.MergeFrom(UserOp);
-            }
-            input.ReadMessage(subBuilder);
-            UserOp = subBuilder;
+          case 32: {
+            Timestamp = input.ReadInt64();
+            break;
+          }
+          case 40: {
+            Seed = input.ReadUInt64();
             break;
           }
         }
@@ -1536,39 +1678,29 @@ namespace GoPureWithCsharp.Battle {
             _unknownFields = pb::UnknownFieldSet.MergeFieldFrom(_unknownFields, ref input);
             break;
           case 10: {
-            global::GoPureWithCsharp.Battle.BattleUseItem subBuilder = new global::GoPureWithCsharp.Battle.BattleUseItem();
-            if (inputCase_ == InputOneofCase.Use) {
-              subBuilder.MergeFrom(Use);
+            if (atk_ == null) {
+              Atk = new global::GoPureWithCsharp.Battle.Team();
             }
-            input.ReadMessage(subBuilder);
-            Use = subBuilder;
+            input.ReadMessage(Atk);
             break;
           }
           case 18: {
-            global::GoPureWithCsharp.Battle.BattleResume subBuilder = new global::GoPureWithCsharp.Battle.BattleResume();
-            if (inputCase_ == InputOneofCase.Resume) {
-              subBuilder.MergeFrom(Resume);
+            if (def_ == null) {
+              Def = new global::GoPureWithCsharp.Battle.Team();
             }
-            input.ReadMessage(subBuilder);
-            Resume = subBuilder;
+            input.ReadMessage(Def);
             break;
           }
-          case 26: {
-            global::GoPureWithCsharp.Battle.BattlePause subBuilder = new global::GoPureWithCsharp.Battle.BattlePause();
-            if (inputCase_ == InputOneofCase.Pause) {
-              subBuilder.MergeFrom(Pause);
-            }
-            input.ReadMessage(subBuilder);
-            Pause = subBuilder;
+          case 24: {
+            BattleId = input.ReadUInt32();
             break;
           }
-          case 34: {
-            global::GoPureWithCsharp.Battle.BattleUserOp subBuilder = new global::GoPureWithCsharp.Battle.BattleUserOp();
-            if (inputCase_ == InputOneofCase.UserOp) {
-              subBuilder.MergeFrom(UserOp);
-            }
-            input.ReadMessage(subBuilder);
-            UserOp = subBuilder;
+          case 32: {
+            Timestamp = input.ReadInt64();
+            break;
+          }
+          case 40: {
+            Seed = input.ReadUInt64();
             break;
           }
         }
@@ -1578,16 +1710,19 @@ namespace GoPureWithCsharp.Battle {
 
   }
 
-  public sealed partial class BattleUserOp : pb::IMessage<BattleUserOp>
+  /// <summary>
+  /// 战斗输入 (通用请求格式)
+  /// </summary>
+  public sealed partial class BattleInput : pb::IMessage<BattleInput>
   #if !GOOGLE_PROTOBUF_REFSTRUCT_COMPATIBILITY_MODE
       , pb::IBufferMessage
   #endif
   {
-    private static readonly pb::MessageParser<BattleUserOp> _parser = new pb::MessageParser<BattleUserOp>(() => new BattleUserOp());
+    private static readonly pb::MessageParser<BattleInput> _parser = new pb::MessageParser<BattleInput>(() => new BattleInput());
     private pb::UnknownFieldSet _unknownFields;
     [global::System.Diagnostics.DebuggerNonUserCodeAttribute]
     [global::System.CodeDom.Compiler.GeneratedCode("protoc", null)]
-    public static pb::MessageParser<BattleUserOp> Parser { get { return _parser; } }
+    public static pb::MessageParser<BattleInput> Parser { get { return _parser; } }
 
     [global::System.Diagnostics.DebuggerNonUserCodeAttribute]
     [global::System.CodeDom.Compiler.GeneratedCode("protoc", null)]
@@ -1603,7 +1738,7 @@ namespace GoPureWithCsharp.Battle {
 
     [global::System.Diagnostics.DebuggerNonUserCodeAttribute]
     [global::System.CodeDom.Compiler.GeneratedCode("protoc", null)]
-    public BattleUserOp() {
+    public BattleInput() {
       OnConstruction();
     }
 
@@ -1611,65 +1746,156 @@ namespace GoPureWithCsharp.Battle {
 
     [global::System.Diagnostics.DebuggerNonUserCodeAttribute]
     [global::System.CodeDom.Compiler.GeneratedCode("protoc", null)]
-    public BattleUserOp(BattleUserOp other) : this() {
-      charId_ = other.charId_;
-      operation_ = other.operation_;
+    public BattleInput(BattleInput other) : this() {
+      switch (other.InputCase) {
+        case InputOneofCase.Use:
+          Use = other.Use.Clone();
+          break;
+        case InputOneofCase.Resume:
+          Resume = other.Resume.Clone();
+          break;
+        case InputOneofCase.Pause:
+          Pause = other.Pause.Clone();
+          break;
+        case InputOneofCase.UserOp:
+          UserOp = other.UserOp.Clone();
+          break;
+        case InputOneofCase.End:
+          End = other.End.Clone();
+          break;
+        case InputOneofCase.Destroy:
+          Destroy = other.Destroy.Clone();
+          break;
+      }
+
       _unknownFields = pb::UnknownFieldSet.Clone(other._unknownFields);
     }
 
     [global::System.Diagnostics.DebuggerNonUserCodeAttribute]
     [global::System.CodeDom.Compiler.GeneratedCode("protoc", null)]
-    public BattleUserOp Clone() {
-      return new BattleUserOp(this);
+    public BattleInput Clone() {
+      return new BattleInput(this);
     }
 
-    /// <summary>Field number for the "char_id" field.</summary>
-    public const int CharIdFieldNumber = 1;
-    private int charId_;
-    /// <summary>
-    /// 角色ID
-    /// </summary>
+    /// <summary>Field number for the "use" field.</summary>
+    public const int UseFieldNumber = 1;
     [global::System.Diagnostics.DebuggerNonUserCodeAttribute]
     [global::System.CodeDom.Compiler.GeneratedCode("protoc", null)]
-    public int CharId {
-      get { return charId_; }
+    public global::GoPureWithCsharp.Battle.BattleUseItem Use {
+      get { return inputCase_ == InputOneofCase.Use ? (global::GoPureWithCsharp.Battle.BattleUseItem) input_ : null; }
       set {
-        charId_ = value;
+        input_ = value;
+        inputCase_ = value == null ? InputOneofCase.None : InputOneofCase.Use;
       }
     }
 
-    /// <summary>Field number for the "operation" field.</summary>
-    public const int OperationFieldNumber = 2;
-    private string operation_ = "";
-    /// <summary>
-    /// 操作类型 (attack/defend/use_item
-    /// </summary>
+    /// <summary>Field number for the "resume" field.</summary>
+    public const int ResumeFieldNumber = 2;
     [global::System.Diagnostics.DebuggerNonUserCodeAttribute]
     [global::System.CodeDom.Compiler.GeneratedCode("protoc", null)]
-    public string Operation {
-      get { return operation_; }
+    public global::GoPureWithCsharp.Battle.BattleResume Resume {
+      get { return inputCase_ == InputOneofCase.Resume ? (global::GoPureWithCsharp.Battle.BattleResume) input_ : null; }
       set {
-        operation_ = pb::ProtoPreconditions.CheckNotNull(value, "value");
+        input_ = value;
+        inputCase_ = value == null ? InputOneofCase.None : InputOneofCase.Resume;
+      }
+    }
+
+    /// <summary>Field number for the "pause" field.</summary>
+    public const int PauseFieldNumber = 3;
+    [global::System.Diagnostics.DebuggerNonUserCodeAttribute]
+    [global::System.CodeDom.Compiler.GeneratedCode("protoc", null)]
+    public global::GoPureWithCsharp.Battle.BattlePause Pause {
+      get { return inputCase_ == InputOneofCase.Pause ? (global::GoPureWithCsharp.Battle.BattlePause) input_ : null; }
+      set {
+        input_ = value;
+        inputCase_ = value == null ? InputOneofCase.None : InputOneofCase.Pause;
+      }
+    }
+
+    /// <summary>Field number for the "user_op" field.</summary>
+    public const int UserOpFieldNumber = 4;
+    [global::System.Diagnostics.DebuggerNonUserCodeAttribute]
+    [global::System.CodeDom.Compiler.GeneratedCode("protoc", null)]
+    public global::GoPureWithCsharp.Battle.BattleUserOp UserOp {
+      get { return inputCase_ == InputOneofCase.UserOp ? (global::GoPureWithCsharp.Battle.BattleUserOp) input_ : null; }
+      set {
+        input_ = value;
+        inputCase_ = value == null ? InputOneofCase.None : InputOneofCase.UserOp;
+      }
+    }
+
+    /// <summary>Field number for the "end" field.</summary>
+    public const int EndFieldNumber = 5;
+    [global::System.Diagnostics.DebuggerNonUserCodeAttribute]
+    [global::System.CodeDom.Compiler.GeneratedCode("protoc", null)]
+    public global::GoPureWithCsharp.Battle.BattleEnd End {
+      get { return inputCase_ == InputOneofCase.End ? (global::GoPureWithCsharp.Battle.BattleEnd) input_ : null; }
+      set {
+        input_ = value;
+        inputCase_ = value == null ? InputOneofCase.None : InputOneofCase.End;
+      }
+    }
+
+    /// <summary>Field number for the "destroy" field.</summary>
+    public const int DestroyFieldNumber = 6;
+    [global::System.Diagnostics.DebuggerNonUserCodeAttribute]
+    [global::System.CodeDom.Compiler.GeneratedCode("protoc", null)]
+    public global::GoPureWithCsharp.Battle.BattleDestroy Destroy {
+      get { return inputCase_ == InputOneofCase.Destroy ? (global::GoPureWithCsharp.Battle.BattleDestroy) input_ : null; }
+      set {
+        input_ = value;
+        inputCase_ = value == null ? InputOneofCase.None : InputOneofCase.Destroy;
       }
     }
 
+    private object input_;
+    /// <summary>Enum of possible cases for the "input" oneof.</summary>
+    public enum InputOneofCase {
+      None = 0,
+      Use = 1,
+      Resume = 2,
+      Pause = 3,
+      UserOp = 4,
+      End = 5,
+      Destroy = 6,
+    }
+    private InputOneofCase inputCase_ = InputOneofCase.None;
+    [global::System.Diagnostics.DebuggerNonUserCodeAttribute]
+    [global::System.CodeDom.Compiler.GeneratedCode("protoc", null)]
+    public InputOneofCase InputCase {
+      get { return inputCase_; }
+    }
+
+    [global::System.Diagnostics.DebuggerNonUserCodeAttribute]
+    [global::System.CodeDom.Compiler.GeneratedCode("protoc", null)]
+    public void ClearInput() {
+      inputCase_ = InputOneofCase.None;
+      input_ = null;
+    }
+
     [global::System.Diagnostics.DebuggerNonUserCodeAttribute]
     [global::System.CodeDom.Compiler.GeneratedCode("protoc", null)]
     public override bool Equals(object other) {
-      return Equals(other as BattleUserOp);
+      return Equals(other as BattleInput);
     }
 
     [global::System.Diagnostics.DebuggerNonUserCodeAttribute]
     [global::System.CodeDom.Compiler.GeneratedCode("protoc", null)]
-    public bool Equals(BattleUserOp other) {
+    public bool Equals(BattleInput other) {
       if (ReferenceEquals(other, null)) {
         return false;
       }
       if (ReferenceEquals(other, this)) {
         return true;
       }
-      if (CharId != other.CharId) return false;
-      if (Operation != other.Operation) return false;
+      if (!object.Equals(Use, other.Use)) return false;
+      if (!object.Equals(Resume, other.Resume)) return false;
+      if (!object.Equals(Pause, other.Pause)) return false;
+      if (!object.Equals(UserOp, other.UserOp)) return false;
+      if (!object.Equals(End, other.End)) return false;
+      if (!object.Equals(Destroy, other.Destroy)) return false;
+      if (InputCase != other.InputCase) return false;
       return Equals(_unknownFields, other._unknownFields);
     }
 
@@ -1677,8 +1903,13 @@ namespace GoPureWithCsharp.Battle {
     [global::System.CodeDom.Compiler.GeneratedCode("protoc", null)]
     public override int GetHashCode() {
       int hash = 1;
-      if (CharId != 0) hash ^= CharId.GetHashCode();
-      if (Operation.Length != 0) hash ^= Operation.GetHashCode();
+      if (inputCase_ == InputOneofCase.Use) hash ^= Use.GetHashCode();
+      if (inputCase_ == InputOneofCase.Resume) hash ^= Resume.GetHashCode();
+      if (inputCase_ == InputOneofCase.Pause) hash ^= Pause.GetHashCode();
+      if (inputCase_ == InputOneofCase.UserOp) hash ^= UserOp.GetHashCode();
+      if (inputCase_ == InputOneofCase.End) hash ^= End.GetHashCode();
+      if (inputCase_ == InputOneofCase.Destroy) hash ^= Destroy.GetHashCode();
+      hash ^= (int) inputCase_;
       if (_unknownFields != null) {
         hash ^= _unknownFields.GetHashCode();
       }
@@ -1697,31 +1928,63 @@ namespace GoPureWithCsharp.Battle {
     #if !GOOGLE_PROTOBUF_REFSTRUCT_COMPATIBILITY_MODE
       output.WriteRawMessage(this);
     #else
-      if (CharId != 0) {
-        output.WriteRawTag(8);
-        output.WriteInt32(CharId);
+      if (inputCase_ == InputOneofCase.Use) {
+        output.WriteRawTag(10);
+        output.WriteMessage(Use);
       }
-      if (Operation.Length != 0) {
+      if (inputCase_ == InputOneofCase.Resume) {
         output.WriteRawTag(18);
-        output.WriteString(Operation);
+        output.WriteMessage(Resume);
       }
-      if (_unknownFields != null) {
-        _unknownFields.WriteTo(output);
+      if (inputCase_ == InputOneofCase.Pause) {
+        output.WriteRawTag(26);
+        output.WriteMessage(Pause);
       }
-    #endif
-    }
-
-    #if !GOOGLE_PROTOBUF_REFSTRUCT_COMPATIBILITY_MODE
+      if (inputCase_ == InputOneofCase.UserOp) {
+        output.WriteRawTag(34);
+        output.WriteMessage(UserOp);
+      }
+      if (inputCase_ == InputOneofCase.End) {
+        output.WriteRawTag(42);
+        output.WriteMessage(End);
+      }
+      if (inputCase_ == InputOneofCase.Destroy) {
+        output.WriteRawTag(50);
+        output.WriteMessage(Destroy);
+      }
+      if (_unknownFields != null) {
+        _unknownFields.WriteTo(output);
+      }
+    #endif
+    }
+
+    #if !GOOGLE_PROTOBUF_REFSTRUCT_COMPATIBILITY_MODE
     [global::System.Diagnostics.DebuggerNonUserCodeAttribute]
     [global::System.CodeDom.Compiler.GeneratedCode("protoc", null)]
     void pb::IBufferMessage.InternalWriteTo(ref pb::WriteContext output) {
-      if (CharId != 0) {
-        output.WriteRawTag(8);
-        output.WriteInt32(CharId);
+      if (inputCase_ == InputOneofCase.Use) {
+        output.WriteRawTag(10);
+        output.WriteMessage(Use);
       }
-      if (Operation.Length != 0) {
+      if (inputCase_ == InputOneofCase.Resume) {
         output.WriteRawTag(18);
-        output.WriteString(Operation);
+        output.WriteMessage(Resume);
+      }
+      if (inputCase_ == InputOneofCase.Pause) {
+        output.WriteRawTag(26);
+        output.WriteMessage(Pause);
+      }
+      if (inputCase_ == InputOneofCase.UserOp) {
+        output.WriteRawTag(34);
+        output.WriteMessage(UserOp);
+      }
+      if (inputCase_ == InputOneofCase.End) {
+        output.WriteRawTag(42);
+        output.WriteMessage(End);
+      }
+      if (inputCase_ == InputOneofCase.Destroy) {
+        output.WriteRawTag(50);
+        output.WriteMessage(Destroy);
       }
       if (_unknownFields != null) {
         _unknownFields.WriteTo(ref output);
@@ -1733,11 +1996,23 @@ namespace GoPureWithCsharp.Battle {
     [global::System.CodeDom.Compiler.GeneratedCode("protoc", null)]
     public int CalculateSize() {
       int size = 0;
-      if (CharId != 0) {
-        size += 1 + pb::CodedOutputStream.ComputeInt32Size(CharId);
+      if (inputCase_ == InputOneofCase.Use) {
+        size += 1 + pb::CodedOutputStream.ComputeMessageSize(Use);
       }
-      if (Operation.Length != 0) {
-        size += 1 + pb::CodedOutputStream.ComputeStringSize(Operation);
+      if (inputCase_ == InputOneofCase.Resume) {
+        size += 1 + pb::CodedOutputStream.ComputeMessageSize(Resume);
+      }
+      if (inputCase_ == InputOneofCase.Pause) {
+        size += 1 + pb::CodedOutputStream.ComputeMessageSize(Pause);
+      }
+      if (inputCase_ == InputOneofCase.UserOp) {
+        size += 1 + pb::CodedOutputStream.ComputeMessageSize(UserOp);
+      }
+      if (inputCase_ == InputOneofCase.End) {
+        size += 1 + pb::CodedOutputStream.ComputeMessageSize(End);
+      }
+      if (inputCase_ == InputOneofCase.Destroy) {
+        size += 1 + pb::CodedOutputStream.ComputeMessageSize(Destroy);
       }
       if (_unknownFields != null) {
         size += _unknownFields.CalculateSize();
@@ -1747,16 +2022,49 @@ namespace GoPureWithCsharp.Battle {
 
     [global::System.Diagnostics.DebuggerNonUserCodeAttribute]
     [global::System.CodeDom.Compiler.GeneratedCode("protoc", null)]
-    public void MergeFrom(BattleUserOp other) {
+    public void MergeFrom(BattleInput other) {
       if (other == null) {
         return;
       }
-      if (other.CharId != 0) {
-        CharId = other.CharId;
-      }
-      if (other.Operation.Length != 0) {
-        Operation = other.Operation;
+      switch (other.InputCase) {
+        case InputOneofCase.Use:
+          if (Use == null) {
+            Use = new global::GoPureWithCsharp.Battle.BattleUseItem();
+          }
+          Use.MergeFrom(other.Use);
+          break;
+        case InputOneofCase.Resume:
+          if (Resume == null) {
+            Resume = new global::GoPureWithCsharp.Battle.BattleResume();
+          }
+          Resume.MergeFrom(other.Resume);
+          break;
+        case InputOneofCase.Pause:
+          if (Pause == null) {
+            Pause = new global::GoPureWithCsharp.Battle.BattlePause();
+          }
+          Pause.MergeFrom(other.Pause);
+          break;
+        case InputOneofCase.UserOp:
+          if (UserOp == null) {
+            UserOp = new global::GoPureWithCsharp.Battle.BattleUserOp();
+          }
+          UserOp.MergeFrom(other.UserOp);
+          break;
+        case InputOneofCase.End:
+          if (End == null) {
+            End = new global::GoPureWithCsharp.Battle.BattleEnd();
+          }
+          End.MergeFrom(other.End);
+          break;
+        case InputOneofCase.Destroy:
+          if (Destroy == null) {
+            Destroy = new global::GoPureWithCsharp.Battle.BattleDestroy();
+          }
+          Destroy.MergeFrom(other.Destroy);
+          break;
       }
+
       _unknownFields = pb::UnknownFieldSet.MergeFrom(_unknownFields, other._unknownFields);
     }
 
@@ -1772,12 +2080,58 @@ namespace GoPureWithCsharp.Battle {
           default:
             _unknownFields = pb::UnknownFieldSet.MergeFieldFrom(_unknownFields, input);
             break;
-          case 8: {
-            CharId = input.ReadInt32();
+          case 10: {
+            global::GoPureWithCsharp.Battle.BattleUseItem subBuilder = new global::GoPureWithCsharp.Battle.BattleUseItem();
+            if (inputCase_ == InputOneofCase.Use) {
+              subBuilder.MergeFrom(Use);
+            }
+            input.ReadMessage(subBuilder);
+            Use = subBuilder;
             break;
           }
           case 18: {
-            Operation = input.ReadString();
+            global::GoPureWithCsharp.Battle.BattleResume subBuilder = new global::GoPureWithCsharp.Battle.BattleResume();
+            if (inputCase_ == InputOneofCase.Resume) {
+              subBuilder.MergeFrom(Resume);
+            }
+            input.ReadMessage(subBuilder);
+            Resume = subBuilder;
+            break;
+          }
+          case 26: {
+            global::GoPureWithCsharp.Battle.BattlePause subBuilder = new global::GoPureWithCsharp.Battle.BattlePause();
+            if (inputCase_ == InputOneofCase.Pause) {
+              subBuilder.MergeFrom(Pause);
+            }
+            input.ReadMessage(subBuilder);
+            Pause = subBuilder;
+            break;
+          }
+          case 34: {
+            global::GoPureWithCsharp.Battle.BattleUserOp subBuilder = new global::GoPureWithCsharp.Battle.BattleUserOp();
+            if (inputCase_ == InputOneofCase.UserOp) {
+              subBuilder.MergeFrom(UserOp);
+            }
+            input.ReadMessage(subBuilder);
+            UserOp = subBuilder;
+            break;
+          }
+          case 42: {
+            global::GoPureWithCsharp.Battle.BattleEnd subBuilder = new global::GoPureWithCsharp.Battle.BattleEnd();
+            if (inputCase_ == InputOneofCase.End) {
+              subBuilder.MergeFrom(End);
+            }
+            input.ReadMessage(subBuilder);
+            End = subBuilder;
+            break;
+          }
+          case 50: {
+            global::GoPureWithCsharp.Battle.BattleDestroy subBuilder = new global::GoPureWithCsharp.Battle.BattleDestroy();
+            if (inputCase_ == InputOneofCase.Destroy) {
+              subBuilder.MergeFrom(Destroy);
+            }
+            input.ReadMessage(subBuilder);
+            Destroy = subBuilder;
             break;
           }
         }
@@ -1795,12 +2149,58 @@ namespace GoPureWithCsharp.Battle {
           default:
             _unknownFields = pb::UnknownFieldSet.MergeFieldFrom(_unknownFields, ref input);
             break;
-          case 8: {
-            CharId = input.ReadInt32();
+          case 10: {
+            global::GoPureWithCsharp.Battle.BattleUseItem subBuilder = new global::GoPureWithCsharp.Battle.BattleUseItem();
+            if (inputCase_ == InputOneofCase.Use) {
+              subBuilder.MergeFrom(Use);
+            }
+            input.ReadMessage(subBuilder);
+            Use = subBuilder;
             break;
           }
           case 18: {
-            Operation = input.ReadString();
+            global::GoPureWithCsharp.Battle.BattleResume subBuilder = new global::GoPureWithCsharp.Battle.BattleResume();
+            if (inputCase_ == InputOneofCase.Resume) {
+              subBuilder.MergeFrom(Resume);
+            }
+            input.ReadMessage(subBuilder);
+            Resume = subBuilder;
+            break;
+          }
+          case 26: {
+            global::GoPureWithCsharp.Battle.BattlePause subBuilder = new global::GoPureWithCsharp.Battle.BattlePause();
+            if (inputCase_ == InputOneofCase.Pause) {
+              subBuilder.MergeFrom(Pause);
+            }
+            input.ReadMessage(subBuilder);
+            Pause = subBuilder;
+            break;
+          }
+          case 34: {
+            global::GoPureWithCsharp.Battle.BattleUserOp subBuilder = new global::GoPureWithCsharp.Battle.BattleUserOp();
+            if (inputCase_ == InputOneofCase.UserOp) {
+              subBuilder.MergeFrom(UserOp);
+            }
+            input.ReadMessage(subBuilder);
+            UserOp = subBuilder;
+            break;
+          }
+          case 42: {
+            global::GoPureWithCsharp.Battle.BattleEnd subBuilder = new global::GoPureWithCsharp.Battle.BattleEnd();
+            if (inputCase_ == InputOneofCase.End) {
+              subBuilder.MergeFrom(End);
+            }
+            input.ReadMessage(subBuilder);
+            End = subBuilder;
+            break;
+          }
+          case 50: {
+            global::GoPureWithCsharp.Battle.BattleDestroy subBuilder = new global::GoPureWithCsharp.Battle.BattleDestroy();
+            if (inputCase_ == InputOneofCase.Destroy) {
+              subBuilder.MergeFrom(Destroy);
+            }
+            input.ReadMessage(subBuilder);
+            Destroy = subBuilder;
             break;
           }
         }
@@ -1811,18 +2211,18 @@ namespace GoPureWithCsharp.Battle {
   }
 
   /// <summary>
-  /// 使用道具请求
+  /// 用户操作，效果由 battle_item.json 的 operations 定义
   /// </summary>
-  public sealed partial class BattleUseItem : pb::IMessage<BattleUseItem>
+  public sealed partial class BattleUserOp : pb::IMessage<BattleUserOp>
   #if !GOOGLE_PROTOBUF_REFSTRUCT_COMPATIBILITY_MODE
       , pb::IBufferMessage
   #endif
   {
-    private static readonly pb::MessageParser<BattleUseItem> _parser = new pb::MessageParser<BattleUseItem>(() => new BattleUseItem());
+    private static readonly pb::MessageParser<BattleUserOp> _parser = new pb::MessageParser<BattleUserOp>(() => new BattleUserOp());
     private pb::UnknownFieldSet _unknownFields;
     [global::System.Diagnostics.DebuggerNonUserCodeAttribute]
     [global::System.CodeDom.Compiler.GeneratedCode("protoc", null)]
-    public static pb::MessageParser<BattleUseItem> Parser { get { return _parser; } }
+    public static pb::MessageParser<BattleUserOp> Parser { get { return _parser; } }
 
     [global::System.Diagnostics.DebuggerNonUserCodeAttribute]
     [global::System.CodeDom.Compiler.GeneratedCode("protoc", null)]
@@ -1838,7 +2238,7 @@ namespace GoPureWithCsharp.Battle {
 
     [global::System.Diagnostics.DebuggerNonUserCodeAttribute]
     [global::System.CodeDom.Compiler.GeneratedCode("protoc", null)]
-    public BattleUseItem() {
+    public BattleUserOp() {
       OnConstruction();
     }
 
@@ -1846,81 +2246,65 @@ namespace GoPureWithCsharp.Battle {
 
     [global::System.Diagnostics.DebuggerNonUserCodeAttribute]
     [global::System.CodeDom.Compiler.GeneratedCode("protoc", null)]
-    public BattleUseItem(BattleUseItem other) : this() {
-      itemIds_ = other.itemIds_.Clone();
-      userId_ = other.userId_;
-      quantity_ = other.quantity_;
+    public BattleUserOp(BattleUserOp other) : this() {
+      charId_ = other.charId_;
+      operation_ = other.operation_;
       _unknownFields = pb::UnknownFieldSet.Clone(other._unknownFields);
     }
 
     [global::System.Diagnostics.DebuggerNonUserCodeAttribute]
     [global::System.CodeDom.Compiler.GeneratedCode("protoc", null)]
-    public BattleUseItem Clone() {
-      return new BattleUseItem(this);
-    }
-
-    /// <summary>Field number for the "item_ids" field.</summary>
-    public const int ItemIdsFieldNumber = 1;
-    private static readonly pb::FieldCodec<uint> _repeated_itemIds_codec
-        = pb::FieldCodec.ForUInt32(10);
-    private readonly pbc::RepeatedField<uint> itemIds_ = new pbc::RepeatedField<uint>();
-    /// <summary>
-    /// 道具ID列表
-    /// </summary>
-    [global::System.Diagnostics.DebuggerNonUserCodeAttribute]
-    [global::System.CodeDom.Compiler.GeneratedCode("protoc", null)]
-    public pbc::RepeatedField<uint> ItemIds {
-      get { return itemIds_; }
+    public BattleUserOp Clone() {
+      return new BattleUserOp(this);
     }
 
-    /// <summary>Field number for the "user_id" field.</summary>
-    public const int UserIdFieldNumber = 2;
-    private uint userId_;
+    /// <summary>Field number for the "char_id" field.</summary>
+    public const int CharIdFieldNumber = 1;
+    private int charId_;
     /// <summary>
-    /// 使用者ID
+    /// 角色ID (执行操作的单位ID)
     /// </summary>
     [global::System.Diagnostics.DebuggerNonUserCodeAttribute]
     [global::System.CodeDom.Compiler.GeneratedCode("protoc", null)]
-    public uint UserId {
-      get { return userId_; }
+    public int CharId {
+      get { return charId_; }
       set {
-        userId_ = value;
+        charId_ = value;
       }
     }
 
-    /// <summary>Field number for the "quantity" field.</summary>
-    public const int QuantityFieldNumber = 3;
-    private int quantity_;
+    /// <summary>Field number for the "operation" field.</summary>
+    public const int OperationFieldNumber = 2;
+    private string operation_ = "";
     /// <summary>
-    /// 数量
+    /// 操作类型 (attack/defend/use_item
     /// </summary>
     [global::System.Diagnostics.DebuggerNonUserCodeAttribute]
     [global::System.CodeDom.Compiler.GeneratedCode("protoc", null)]
-    public int Quantity {
-      get { return quantity_; }
+    public string Operation {
+      get { return operation_; }
       set {
-        quantity_ = value;
+        operation_ = pb::ProtoPreconditions.CheckNotNull(value, "value");
       }
     }
 
     [global::System.Diagnostics.DebuggerNonUserCodeAttribute]
     [global::System.CodeDom.Compiler.GeneratedCode("protoc", null)]
     public override bool Equals(object other) {
-      return Equals(other as BattleUseItem);
+      return Equals(other as BattleUserOp);
     }
 
     [global::System.Diagnostics.DebuggerNonUserCodeAttribute]
     [global::System.CodeDom.Compiler.GeneratedCode("protoc", null)]
-    public bool Equals(BattleUseItem other) {
+    public bool Equals(BattleUserOp other) {
       if (ReferenceEquals(other, null)) {
         return false;
       }
       if (ReferenceEquals(other, this)) {
         return true;
       }
-      if(!itemIds_.Equals(other.itemIds_)) return false;
-      if (UserId != other.UserId) return false;
-      if (Quantity != other.Quantity) return false;
+      if (CharId != other.CharId) return false;
+      if (Operation != other.Operation) return false;
       return Equals(_unknownFields, other._unknownFields);
     }
 
@@ -1928,9 +2312,8 @@ namespace GoPureWithCsharp.Battle {
     [global::System.CodeDom.Compiler.GeneratedCode("protoc", null)]
     public override int GetHashCode() {
       int hash = 1;
-      hash ^= itemIds_.GetHashCode();
-      if (UserId != 0) hash ^= UserId.GetHashCode();
-      if (Quantity != 0) hash ^= Quantity.GetHashCode();
+      if (CharId != 0) hash ^= CharId.GetHashCode();
+      if (Operation.Length != 0) hash ^= Operation.GetHashCode();
       if (_unknownFields != null) {
         hash ^= _unknownFields.GetHashCode();
       }
@@ -1949,12 +2332,264 @@ namespace GoPureWithCsharp.Battle {
     #if !GOOGLE_PROTOBUF_REFSTRUCT_COMPATIBILITY_MODE
       output.WriteRawMessage(this);
     #else
-      itemIds_.WriteTo(output, _repeated_itemIds_codec);
-      if (UserId != 0) {
-        output.WriteRawTag(16);
-        output.WriteUInt32(UserId);
+      if (CharId != 0) {
+        output.WriteRawTag(8);
+        output.WriteInt32(CharId);
       }
-      if (Quantity != 0) {
+      if (Operation.Length != 0) {
+        output.WriteRawTag(18);
+        output.WriteString(Operation);
+      }
+      if (_unknownFields != null) {
+        _unknownFields.WriteTo(output);
+      }
+    #endif
+    }
+
+    #if !GOOGLE_PROTOBUF_REFSTRUCT_COMPATIBILITY_MODE
+    [global::System.Diagnostics.DebuggerNonUserCodeAttribute]
+    [global::System.CodeDom.Compiler.GeneratedCode("protoc", null)]
+    void pb::IBufferMessage.InternalWriteTo(ref pb::WriteContext output) {
+      if (CharId != 0) {
+        output.WriteRawTag(8);
+        output.WriteInt32(CharId);
+      }
+      if (Operation.Length != 0) {
+        output.WriteRawTag(18);
+        output.WriteString(Operation);
+      }
+      if (_unknownFields != null) {
+        _unknownFields.WriteTo(ref output);
+      }
+    }
+    #endif
+
+    [global::System.Diagnostics.DebuggerNonUserCodeAttribute]
+    [global::System.CodeDom.Compiler.GeneratedCode("protoc", null)]
+    public int CalculateSize() {
+      int size = 0;
+      if (CharId != 0) {
+        size += 1 + pb::CodedOutputStream.ComputeInt32Size(CharId);
+      }
+      if (Operation.Length != 0) {
+        size += 1 + pb::CodedOutputStream.ComputeStringSize(Operation);
+      }
+      if (_unknownFields != null) {
+        size += _unknownFields.CalculateSize();
+      }
+      return size;
+    }
+
+    [global::System.Diagnostics.DebuggerNonUserCodeAttribute]
+    [global::System.CodeDom.Compiler.GeneratedCode("protoc", null)]
+    public void MergeFrom(BattleUserOp other) {
+      if (other == null) {
+        return;
+      }
+      if (other.CharId != 0) {
+        CharId = other.CharId;
+      }
+      if (other.Operation.Length != 0) {
+        Operation = other.Operation;
+      }
+      _unknownFields = pb::UnknownFieldSet.MergeFrom(_unknownFields, other._unknownFields);
+    }
+
+    [global::System.Diagnostics.DebuggerNonUserCodeAttribute]
+    [global::System.CodeDom.Compiler.GeneratedCode("protoc", null)]
+    public void MergeFrom(pb::CodedInputStream input) {
+    #if !GOOGLE_PROTOBUF_REFSTRUCT_COMPATIBILITY_MODE
+      input.ReadRawMessage(this);
+    #else
+      uint tag;
+      while ((tag = input.ReadTag()) != 0) {
+        switch(tag) {
+          default:
+            _unknownFields = pb::UnknownFieldSet.MergeFieldFrom(_unknownFields, input);
+            break;
+          case 8: {
+            CharId = input.ReadInt32();
+            break;
+          }
+          case 18: {
+            Operation = input.ReadString();
+            break;
+          }
+        }
+      }
+    #endif
+    }
+
+    #if !GOOGLE_PROTOBUF_REFSTRUCT_COMPATIBILITY_MODE
+    [global::System.Diagnostics.DebuggerNonUserCodeAttribute]
+    [global::System.CodeDom.Compiler.GeneratedCode("protoc", null)]
+    void pb::IBufferMessage.InternalMergeFrom(ref pb::ParseContext input) {
+      uint tag;
+      while ((tag = input.ReadTag()) != 0) {
+        switch(tag) {
+          default:
+            _unknownFields = pb::UnknownFieldSet.MergeFieldFrom(_unknownFields, ref input);
+            break;
+          case 8: {
+            CharId = input.ReadInt32();
+            break;
+          }
+          case 18: {
+            Operation = input.ReadString();
+            break;
+          }
+        }
+      }
+    }
+    #endif
+
+  }
+
+  /// <summary>
+  /// 使用道具请求，效果由 battle_item.json 的 items 定义
+  /// </summary>
+  public sealed partial class BattleUseItem : pb::IMessage<BattleUseItem>
+  #if !GOOGLE_PROTOBUF_REFSTRUCT_COMPATIBILITY_MODE
+      , pb::IBufferMessage
+  #endif
+  {
+    private static readonly pb::MessageParser<BattleUseItem> _parser = new pb::MessageParser<BattleUseItem>(() => new BattleUseItem());
+    private pb::UnknownFieldSet _unknownFields;
+    [global::System.Diagnostics.DebuggerNonUserCodeAttribute]
+    [global::System.CodeDom.Compiler.GeneratedCode("protoc", null)]
+    public static pb::MessageParser<BattleUseItem> Parser { get { return _parser; } }
+
+    [global::System.Diagnostics.DebuggerNonUserCodeAttribute]
+    [global::System.CodeDom.Compiler.GeneratedCode("protoc", null)]
+    public static pbr::MessageDescriptor Descriptor {
+      get { return global::GoPureWithCsharp.Battle.BattleReflection.Descriptor.MessageTypes[6]; }
+    }
+
+    [global::System.Diagnostics.DebuggerNonUserCodeAttribute]
+    [global::System.CodeDom.Compiler.GeneratedCode("protoc", null)]
+    pbr::MessageDescriptor pb::IMessage.Descriptor {
+      get { return Descriptor; }
+    }
+
+    [global::System.Diagnostics.DebuggerNonUserCodeAttribute]
+    [global::System.CodeDom.Compiler.GeneratedCode("protoc", null)]
+    public BattleUseItem() {
+      OnConstruction();
+    }
+
+    partial void OnConstruction();
+
+    [global::System.Diagnostics.DebuggerNonUserCodeAttribute]
+    [global::System.CodeDom.Compiler.GeneratedCode("protoc", null)]
+    public BattleUseItem(BattleUseItem other) : this() {
+      itemIds_ = other.itemIds_.Clone();
+      userId_ = other.userId_;
+      quantity_ = other.quantity_;
+      _unknownFields = pb::UnknownFieldSet.Clone(other._unknownFields);
+    }
+
+    [global::System.Diagnostics.DebuggerNonUserCodeAttribute]
+    [global::System.CodeDom.Compiler.GeneratedCode("protoc", null)]
+    public BattleUseItem Clone() {
+      return new BattleUseItem(this);
+    }
+
+    /// <summary>Field number for the "item_ids" field.</summary>
+    public const int ItemIdsFieldNumber = 1;
+    private static readonly pb::FieldCodec<uint> _repeated_itemIds_codec
+        = pb::FieldCodec.ForUInt32(10);
+    private readonly pbc::RepeatedField<uint> itemIds_ = new pbc::RepeatedField<uint>();
+    /// <summary>
+    /// 道具ID列表
+    /// </summary>
+    [global::System.Diagnostics.DebuggerNonUserCodeAttribute]
+    [global::System.CodeDom.Compiler.GeneratedCode("protoc", null)]
+    public pbc::RepeatedField<uint> ItemIds {
+      get { return itemIds_; }
+    }
+
+    /// <summary>Field number for the "user_id" field.</summary>
+    public const int UserIdFieldNumber = 2;
+    private uint userId_;
+    /// <summary>
+    /// 使用者ID (使用道具的单位ID)
+    /// </summary>
+    [global::System.Diagnostics.DebuggerNonUserCodeAttribute]
+    [global::System.CodeDom.Compiler.GeneratedCode("protoc", null)]
+    public uint UserId {
+      get { return userId_; }
+      set {
+        userId_ = value;
+      }
+    }
+
+    /// <summary>Field number for the "quantity" field.</summary>
+    public const int QuantityFieldNumber = 3;
+    private int quantity_;
+    /// <summary>
+    /// 数量 (每种道具 1 ~ maxQuantity)
+    /// </summary>
+    [global::System.Diagnostics.DebuggerNonUserCodeAttribute]
+    [global::System.CodeDom.Compiler.GeneratedCode("protoc", null)]
+    public int Quantity {
+      get { return quantity_; }
+      set {
+        quantity_ = value;
+      }
+    }
+
+    [global::System.Diagnostics.DebuggerNonUserCodeAttribute]
+    [global::System.CodeDom.Compiler.GeneratedCode("protoc", null)]
+    public override bool Equals(object other) {
+      return Equals(other as BattleUseItem);
+    }
+
+    [global::System.Diagnostics.DebuggerNonUserCodeAttribute]
+    [global::System.CodeDom.Compiler.GeneratedCode("protoc", null)]
+    public bool Equals(BattleUseItem other) {
+      if (ReferenceEquals(other, null)) {
+        return false;
+      }
+      if (ReferenceEquals(other, this)) {
+        return true;
+      }
+      if(!itemIds_.Equals(other.itemIds_)) return false;
+      if (UserId != other.UserId) return false;
+      if (Quantity != other.Quantity) return false;
+      return Equals(_unknownFields, other._unknownFields);
+    }
+
+    [global::System.Diagnostics.DebuggerNonUserCodeAttribute]
+    [global::System.CodeDom.Compiler.GeneratedCode("protoc", null)]
+    public override int GetHashCode() {
+      int hash = 1;
+      hash ^= itemIds_.GetHashCode();
+      if (UserId != 0) hash ^= UserId.GetHashCode();
+      if (Quantity != 0) hash ^= Quantity.GetHashCode();
+      if (_unknownFields != null) {
+        hash ^= _unknownFields.GetHashCode();
+      }
+      return hash;
+    }
+
+    [global::System.Diagnostics.DebuggerNonUserCodeAttribute]
+    [global::System.CodeDom.Compiler.GeneratedCode("protoc", null)]
+    public override string ToString() {
+      return pb::JsonFormatter.ToDiagnosticString(this);
+    }
+
+    [global::System.Diagnostics.DebuggerNonUserCodeAttribute]
+    [global::System.CodeDom.Compiler.GeneratedCode("protoc", null)]
+    public void WriteTo(pb::CodedOutputStream output) {
+    #if !GOOGLE_PROTOBUF_REFSTRUCT_COMPATIBILITY_MODE
+      output.WriteRawMessage(this);
+    #else
+      itemIds_.WriteTo(output, _repeated_itemIds_codec);
+      if (UserId != 0) {
+        output.WriteRawTag(16);
+        output.WriteUInt32(UserId);
+      }
+      if (Quantity != 0) {
         output.WriteRawTag(24);
         output.WriteInt32(Quantity);
       }
@@ -2090,7 +2725,7 @@ namespace GoPureWithCsharp.Battle {
     [global::System.Diagnostics.DebuggerNonUserCodeAttribute]
     [global::System.CodeDom.Compiler.GeneratedCode("protoc", null)]
     public static pbr::MessageDescriptor Descriptor {
-      get { return global::GoPureWithCsharp.Battle.BattleReflection.Descriptor.MessageTypes[6]; }
+      get { return global::GoPureWithCsharp.Battle.BattleReflection.Descriptor.MessageTypes[7]; }
     }
 
     [global::System.Diagnostics.DebuggerNonUserCodeAttribute]
@@ -2242,7 +2877,7 @@ namespace GoPureWithCsharp.Battle {
     [global::System.Diagnostics.DebuggerNonUserCodeAttribute]
     [global::System.CodeDom.Compiler.GeneratedCode("protoc", null)]
     public static pbr::MessageDescriptor Descriptor {
-      get { return global::GoPureWithCsharp.Battle.BattleReflection.Descriptor.MessageTypes[7]; }
+      get { return global::GoPureWithCsharp.Battle.BattleReflection.Descriptor.MessageTypes[8]; }
     }
 
     [global::System.Diagnostics.DebuggerNonUserCodeAttribute]
@@ -2380,21 +3015,24 @@ namespace GoPureWithCsharp.Battle {
 
   }
 
-  public sealed partial class BattleOutput : pb::IMessage<BattleOutput>
+  /// <summary>
+  /// 强制结束战斗，下一个逻辑帧输出结果和回放
+  /// </summary>
+  public sealed partial class BattleEnd : pb::IMessage<BattleEnd>
   #if !GOOGLE_PROTOBUF_REFSTRUCT_COMPATIBILITY_MODE
       , pb::IBufferMessage
   #endif
   {
-    private static readonly pb::MessageParser<BattleOutput> _parser = new pb::MessageParser<BattleOutput>(() => new BattleOutput());
+    private static readonly pb::MessageParser<BattleEnd> _parser = new pb::MessageParser<BattleEnd>(() => new BattleEnd());
     private pb::UnknownFieldSet _unknownFields;
     [global::System.Diagnostics.DebuggerNonUserCodeAttribute]
     [global::System.CodeDom.Compiler.GeneratedCode("protoc", null)]
-    public static pb::MessageParser<BattleOutput> Parser { get { return _parser; } }
+    public static pb::MessageParser<BattleEnd> Parser { get { return _parser; } }
 
     [global::System.Diagnostics.DebuggerNonUserCodeAttribute]
     [global::System.CodeDom.Compiler.GeneratedCode("protoc", null)]
     public static pbr::MessageDescriptor Descriptor {
-      get { return global::GoPureWithCsharp.Battle.BattleReflection.Descriptor.MessageTypes[8]; }
+      get { return global::GoPureWithCsharp.Battle.BattleReflection.Descriptor.MessageTypes[9]; }
     }
 
     [global::System.Diagnostics.DebuggerNonUserCodeAttribute]
@@ -2405,7 +3043,7 @@ namespace GoPureWithCsharp.Battle {
 
     [global::System.Diagnostics.DebuggerNonUserCodeAttribute]
     [global::System.CodeDom.Compiler.GeneratedCode("protoc", null)]
-    public BattleOutput() {
+    public BattleEnd() {
       OnConstruction();
     }
 
@@ -2413,94 +3051,65 @@ namespace GoPureWithCsharp.Battle {
 
     [global::System.Diagnostics.DebuggerNonUserCodeAttribute]
     [global::System.CodeDom.Compiler.GeneratedCode("protoc", null)]
-    public BattleOutput(BattleOutput other) : this() {
-      switch (other.OutputCase) {
-        case OutputOneofCase.Result:
-          Result = other.Result.Clone();
-          break;
-        case OutputOneofCase.Replay:
-          Replay = other.Replay.Clone();
-          break;
-      }
-
+    public BattleEnd(BattleEnd other) : this() {
+      winner_ = other.winner_;
+      timeout_ = other.timeout_;
       _unknownFields = pb::UnknownFieldSet.Clone(other._unknownFields);
     }
 
     [global::System.Diagnostics.DebuggerNonUserCodeAttribute]
     [global::System.CodeDom.Compiler.GeneratedCode("protoc", null)]
-    public BattleOutput Clone() {
-      return new BattleOutput(this);
+    public BattleEnd Clone() {
+      return new BattleEnd(this);
     }
 
-    /// <summary>Field number for the "result" field.</summary>
-    public const int ResultFieldNumber = 1;
+    /// <summary>Field number for the "winner" field.</summary>
+    public const int WinnerFieldNumber = 1;
+    private uint winner_;
     /// <summary>
-    /// 战斗结果
+    /// 指定胜方队伍ID，0 表示按剩余血量判定 (相同时防守方获胜)
     /// </summary>
     [global::System.Diagnostics.DebuggerNonUserCodeAttribute]
     [global::System.CodeDom.Compiler.GeneratedCode("protoc", null)]
-    public global::GoPureWithCsharp.Battle.BattleResult Result {
-      get { return outputCase_ == OutputOneofCase.Result ? (global::GoPureWithCsharp.Battle.BattleResult) output_ : null; }
+    public uint Winner {
+      get { return winner_; }
       set {
-        output_ = value;
-        outputCase_ = value == null ? OutputOneofCase.None : OutputOneofCase.Result;
+        winner_ = value;
       }
     }
 
-    /// <summary>Field number for the "replay" field.</summary>
-    public const int ReplayFieldNumber = 2;
+    /// <summary>Field number for the "timeout" field.</summary>
+    public const int TimeoutFieldNumber = 2;
+    private bool timeout_;
     /// <summary>
-    /// 战斗回放
+    /// 超过最长战斗时长被强制结束，结果的 timeout 为 true
     /// </summary>
     [global::System.Diagnostics.DebuggerNonUserCodeAttribute]
     [global::System.CodeDom.Compiler.GeneratedCode("protoc", null)]
-    public global::GoPureWithCsharp.Battle.BattleReplay Replay {
-      get { return outputCase_ == OutputOneofCase.Replay ? (global::GoPureWithCsharp.Battle.BattleReplay) output_ : null; }
+    public bool Timeout {
+      get { return timeout_; }
       set {
-        output_ = value;
-        outputCase_ = value == null ? OutputOneofCase.None : OutputOneofCase.Replay;
+        timeout_ = value;
       }
     }
 
-    private object output_;
-    /// <summary>Enum of possible cases for the "output" oneof.</summary>
-    public enum OutputOneofCase {
-      None = 0,
-      Result = 1,
-      Replay = 2,
-    }
-    private OutputOneofCase outputCase_ = OutputOneofCase.None;
-    [global::System.Diagnostics.DebuggerNonUserCodeAttribute]
-    [global::System.CodeDom.Compiler.GeneratedCode("protoc", null)]
-    public OutputOneofCase OutputCase {
-      get { return outputCase_; }
-    }
-
-    [global::System.Diagnostics.DebuggerNonUserCodeAttribute]
-    [global::System.CodeDom.Compiler.GeneratedCode("protoc", null)]
-    public void ClearOutput() {
-      outputCase_ = OutputOneofCase.None;
-      output_ = null;
-    }
-
     [global::System.Diagnostics.DebuggerNonUserCodeAttribute]
     [global::System.CodeDom.Compiler.GeneratedCode("protoc", null)]
     public override bool Equals(object other) {
-      return Equals(other as BattleOutput);
+      return Equals(other as BattleEnd);
     }
 
     [global::System.Diagnostics.DebuggerNonUserCodeAttribute]
     [global::System.CodeDom.Compiler.GeneratedCode("protoc", null)]
-    public bool Equals(BattleOutput other) {
+    public bool Equals(BattleEnd other) {
       if (ReferenceEquals(other, null)) {
         return false;
       }
       if (ReferenceEquals(other, this)) {
         return true;
       }
-      if (!object.Equals(Result, other.Result)) return false;
-      if (!object.Equals(Replay, other.Replay)) return false;
-      if (OutputCase != other.OutputCase) return false;
+      if (Winner != other.Winner) return false;
+      if (Timeout != other.Timeout) return false;
       return Equals(_unknownFields, other._unknownFields);
     }
 
@@ -2508,9 +3117,8 @@ namespace GoPureWithCsharp.Battle {
     [global::System.CodeDom.Compiler.GeneratedCode("protoc", null)]
     public override int GetHashCode() {
       int hash = 1;
-      if (outputCase_ == OutputOneofCase.Result) hash ^= Result.GetHashCode();
-      if (outputCase_ == OutputOneofCase.Replay) hash ^= Replay.GetHashCode();
-      hash ^= (int) outputCase_;
+      if (Winner != 0) hash ^= Winner.GetHashCode();
+      if (Timeout != false) hash ^= Timeout.GetHashCode();
       if (_unknownFields != null) {
         hash ^= _unknownFields.GetHashCode();
       }
@@ -2529,13 +3137,13 @@ namespace GoPureWithCsharp.Battle {
     #if !GOOGLE_PROTOBUF_REFSTRUCT_COMPATIBILITY_MODE
       output.WriteRawMessage(this);
     #else
-      if (outputCase_ == OutputOneofCase.Result) {
-        output.WriteRawTag(10);
-        output.WriteMessage(Result);
+      if (Winner != 0) {
+        output.WriteRawTag(8);
+        output.WriteUInt32(Winner);
       }
-      if (outputCase_ == OutputOneofCase.Replay) {
-        output.WriteRawTag(18);
-        output.WriteMessage(Replay);
+      if (Timeout != false) {
+        output.WriteRawTag(16);
+        output.WriteBool(Timeout);
       }
       if (_unknownFields != null) {
         _unknownFields.WriteTo(output);
@@ -2547,13 +3155,13 @@ namespace GoPureWithCsharp.Battle {
     [global::System.Diagnostics.DebuggerNonUserCodeAttribute]
     [global::System.CodeDom.Compiler.GeneratedCode("protoc", null)]
     void pb::IBufferMessage.InternalWriteTo(ref pb::WriteContext output) {
-      if (outputCase_ == OutputOneofCase.Result) {
-        output.WriteRawTag(10);
-        output.WriteMessage(Result);
+      if (Winner != 0) {
+        output.WriteRawTag(8);
+        output.WriteUInt32(Winner);
       }
-      if (outputCase_ == OutputOneofCase.Replay) {
-        output.WriteRawTag(18);
-        output.WriteMessage(Replay);
+      if (Timeout != false) {
+        output.WriteRawTag(16);
+        output.WriteBool(Timeout);
       }
       if (_unknownFields != null) {
         _unknownFields.WriteTo(ref output);
@@ -2565,11 +3173,11 @@ namespace GoPureWithCsharp.Battle {
     [global::System.CodeDom.Compiler.GeneratedCode("protoc", null)]
     public int CalculateSize() {
       int size = 0;
-      if (outputCase_ == OutputOneofCase.Result) {
-        size += 1 + pb::CodedOutputStream.ComputeMessageSize(Result);
+      if (Winner != 0) {
+        size += 1 + pb::CodedOutputStream.ComputeUInt32Size(Winner);
       }
-      if (outputCase_ == OutputOneofCase.Replay) {
-        size += 1 + pb::CodedOutputStream.ComputeMessageSize(Replay);
+      if (Timeout != false) {
+        size += 1 + 1;
       }
       if (_unknownFields != null) {
         size += _unknownFields.CalculateSize();
@@ -2579,25 +3187,16 @@ namespace GoPureWithCsharp.Battle {
 
     [global::System.Diagnostics.DebuggerNonUserCodeAttribute]
     [global::System.CodeDom.Compiler.GeneratedCode("protoc", null)]
-    public void MergeFrom(BattleOutput other) {
+    public void MergeFrom(BattleEnd other) {
       if (other == null) {
         return;
       }
-      switch (other.OutputCase) {
-        case OutputOneofCase.Result:
-          if (Result == null) {
-            Result = new global::GoPureWithCsharp.Battle.BattleResult();
-          }
-          Result.MergeFrom(other.Result);
-          break;
-        case OutputOneofCase.Replay:
-          if (Replay == null) {
-            Replay = new global::GoPureWithCsharp.Battle.BattleReplay();
-          }
-          Replay.MergeFrom(other.Replay);
-          break;
+      if (other.Winner != 0) {
+        Winner = other.Winner;
+      }
+      if (other.Timeout != false) {
+        Timeout = other.Timeout;
       }
-
       _unknownFields = pb::UnknownFieldSet.MergeFrom(_unknownFields, other._unknownFields);
     }
 
@@ -2613,22 +3212,12 @@ namespace GoPureWithCsharp.Battle {
           default:
             _unknownFields = pb::UnknownFieldSet.MergeFieldFrom(_unknownFields, input);
             break;
-          case 10: {
-            global::GoPureWithCsharp.Battle.BattleResult subBuilder = new global::GoPureWithCsharp.Battle.BattleResult();
-            if (outputCase_ == OutputOneofCase.Result) {
-              subBuilder.MergeFrom(Result);
-            }
-            input.ReadMessage(subBuilder);
-            Result = subBuilder;
+          case 8: {
+            Winner = input.ReadUInt32();
             break;
           }
-          case 18: {
-            global::GoPureWithCsharp.Battle.BattleReplay subBuilder = new global::GoPureWithCsharp.Battle.BattleReplay();
-            if (outputCase_ == OutputOneofCase.Replay) {
-              subBuilder.MergeFrom(Replay);
-            }
-            input.ReadMessage(subBuilder);
-            Replay = subBuilder;
+          case 16: {
+            Timeout = input.ReadBool();
             break;
           }
         }
@@ -2646,22 +3235,12 @@ namespace GoPureWithCsharp.Battle {
           default:
             _unknownFields = pb::UnknownFieldSet.MergeFieldFrom(_unknownFields, ref input);
             break;
-          case 10: {
-            global::GoPureWithCsharp.Battle.BattleResult subBuilder = new global::GoPureWithCsharp.Battle.BattleResult();
-            if (outputCase_ == OutputOneofCase.Result) {
-              subBuilder.MergeFrom(Result);
-            }
-            input.ReadMessage(subBuilder);
-            Result = subBuilder;
+          case 8: {
+            Winner = input.ReadUInt32();
             break;
           }
-          case 18: {
-            global::GoPureWithCsharp.Battle.BattleReplay subBuilder = new global::GoPureWithCsharp.Battle.BattleReplay();
-            if (outputCase_ == OutputOneofCase.Replay) {
-              subBuilder.MergeFrom(Replay);
-            }
-            input.ReadMessage(subBuilder);
-            Replay = subBuilder;
+          case 16: {
+            Timeout = input.ReadBool();
             break;
           }
         }
@@ -2672,23 +3251,23 @@ namespace GoPureWithCsharp.Battle {
   }
 
   /// <summary>
-  /// 战斗结果
+  /// 销毁战斗，之后不再执行回合也不接受输入
   /// </summary>
-  public sealed partial class BattleResult : pb::IMessage<BattleResult>
+  public sealed partial class BattleDestroy : pb::IMessage<BattleDestroy>
   #if !GOOGLE_PROTOBUF_REFSTRUCT_COMPATIBILITY_MODE
       , pb::IBufferMessage
   #endif
   {
-    private static readonly pb::MessageParser<BattleResult> _parser = new pb::MessageParser<BattleResult>(() => new BattleResult());
+    private static readonly pb::MessageParser<BattleDestroy> _parser = new pb::MessageParser<BattleDestroy>(() => new BattleDestroy());
     private pb::UnknownFieldSet _unknownFields;
     [global::System.Diagnostics.DebuggerNonUserCodeAttribute]
     [global::System.CodeDom.Compiler.GeneratedCode("protoc", null)]
-    public static pb::MessageParser<BattleResult> Parser { get { return _parser; } }
+    public static pb::MessageParser<BattleDestroy> Parser { get { return _parser; } }
 
     [global::System.Diagnostics.DebuggerNonUserCodeAttribute]
     [global::System.CodeDom.Compiler.GeneratedCode("protoc", null)]
     public static pbr::MessageDescriptor Descriptor {
-      get { return global::GoPureWithCsharp.Battle.BattleReflection.Descriptor.MessageTypes[9]; }
+      get { return global::GoPureWithCsharp.Battle.BattleReflection.Descriptor.MessageTypes[10]; }
     }
 
     [global::System.Diagnostics.DebuggerNonUserCodeAttribute]
@@ -2699,7 +3278,7 @@ namespace GoPureWithCsharp.Battle {
 
     [global::System.Diagnostics.DebuggerNonUserCodeAttribute]
     [global::System.CodeDom.Compiler.GeneratedCode("protoc", null)]
-    public BattleResult() {
+    public BattleDestroy() {
       OnConstruction();
     }
 
@@ -2707,149 +3286,3806 @@ namespace GoPureWithCsharp.Battle {
 
     [global::System.Diagnostics.DebuggerNonUserCodeAttribute]
     [global::System.CodeDom.Compiler.GeneratedCode("protoc", null)]
-    public BattleResult(BattleResult other) : this() {
-      winner_ = other.winner_;
-      loser_ = other.loser_;
-      atkDamage_ = other.atkDamage_;
-      defDamage_ = other.defDamage_;
-      kills_ = other.kills_.Clone();
-      duration_ = other.duration_;
-      battleScore_ = other.battleScore_;
+    public BattleDestroy(BattleDestroy other) : this() {
       _unknownFields = pb::UnknownFieldSet.Clone(other._unknownFields);
     }
 
     [global::System.Diagnostics.DebuggerNonUserCodeAttribute]
     [global::System.CodeDom.Compiler.GeneratedCode("protoc", null)]
-    public BattleResult Clone() {
-      return new BattleResult(this);
+    public BattleDestroy Clone() {
+      return new BattleDestroy(this);
     }
 
-    /// <summary>Field number for the "winner" field.</summary>
-    public const int WinnerFieldNumber = 1;
-    private uint winner_;
-    /// <summary>
-    /// 胜方队伍ID
-    /// </summary>
     [global::System.Diagnostics.DebuggerNonUserCodeAttribute]
     [global::System.CodeDom.Compiler.GeneratedCode("protoc", null)]
-    public uint Winner {
-      get { return winner_; }
-      set {
-        winner_ = value;
-      }
+    public override bool Equals(object other) {
+      return Equals(other as BattleDestroy);
     }
 
-    /// <summary>Field number for the "loser" field.</summary>
-    public const int LoserFieldNumber = 2;
-    private uint loser_;
-    /// <summary>
-    /// 败方队伍ID
-    /// </summary>
     [global::System.Diagnostics.DebuggerNonUserCodeAttribute]
     [global::System.CodeDom.Compiler.GeneratedCode("protoc", null)]
-    public uint Loser {
-      get { return loser_; }
-      set {
-        loser_ = value;
+    public bool Equals(BattleDestroy other) {
+      if (ReferenceEquals(other, null)) {
+        return false;
+      }
+      if (ReferenceEquals(other, this)) {
+        return true;
       }
+      return Equals(_unknownFields, other._unknownFields);
     }
 
-    /// <summary>Field number for the "atk_damage" field.</summary>
-    public const int AtkDamageFieldNumber = 3;
-    private int atkDamage_;
-    /// <summary>
-    /// 攻击方伤害
-    /// </summary>
     [global::System.Diagnostics.DebuggerNonUserCodeAttribute]
     [global::System.CodeDom.Compiler.GeneratedCode("protoc", null)]
-    public int AtkDamage {
-      get { return atkDamage_; }
-      set {
-        atkDamage_ = value;
+    public override int GetHashCode() {
+      int hash = 1;
+      if (_unknownFields != null) {
+        hash ^= _unknownFields.GetHashCode();
       }
+      return hash;
     }
 
-    /// <summary>Field number for the "def_damage" field.</summary>
-    public const int DefDamageFieldNumber = 4;
-    private int defDamage_;
-    /// <summary>
-    /// 防守方伤害
-    /// </summary>
     [global::System.Diagnostics.DebuggerNonUserCodeAttribute]
     [global::System.CodeDom.Compiler.GeneratedCode("protoc", null)]
-    public int DefDamage {
-      get { return defDamage_; }
-      set {
-        defDamage_ = value;
-      }
+    public override string ToString() {
+      return pb::JsonFormatter.ToDiagnosticString(this);
     }
 
-    /// <summary>Field number for the "kills" field.</summary>
-    public const int KillsFieldNumber = 5;
-    private static readonly pb::FieldCodec<uint> _repeated_kills_codec
-        = pb::FieldCodec.ForUInt32(42);
-    private readonly pbc::RepeatedField<uint> kills_ = new pbc::RepeatedField<uint>();
-    /// <summary>
-    /// 击杀列表
-    /// </summary>
     [global::System.Diagnostics.DebuggerNonUserCodeAttribute]
     [global::System.CodeDom.Compiler.GeneratedCode("protoc", null)]
-    public pbc::RepeatedField<uint> Kills {
-      get { return kills_; }
+    public void WriteTo(pb::CodedOutputStream output) {
+    #if !GOOGLE_PROTOBUF_REFSTRUCT_COMPATIBILITY_MODE
+      output.WriteRawMessage(this);
+    #else
+      if (_unknownFields != null) {
+        _unknownFields.WriteTo(output);
+      }
+    #endif
     }
 
-    /// <summary>Field number for the "duration" field.</summary>
-    public const int DurationFieldNumber = 6;
-    private long duration_;
-    /// <summary>
-    /// 战斗持续时间(毫秒)
-    /// </summary>
+    #if !GOOGLE_PROTOBUF_REFSTRUCT_COMPATIBILITY_MODE
     [global::System.Diagnostics.DebuggerNonUserCodeAttribute]
     [global::System.CodeDom.Compiler.GeneratedCode("protoc", null)]
-    public long Duration {
-      get { return duration_; }
-      set {
-        duration_ = value;
+    void pb::IBufferMessage.InternalWriteTo(ref pb::WriteContext output) {
+      if (_unknownFields != null) {
+        _unknownFields.WriteTo(ref output);
       }
     }
+    #endif
 
-    /// <summary>Field number for the "battle_score" field.</summary>
-    public const int BattleScoreFieldNumber = 7;
+    [global::System.Diagnostics.DebuggerNonUserCodeAttribute]
+    [global::System.CodeDom.Compiler.GeneratedCode("protoc", null)]
+    public int CalculateSize() {
+      int size = 0;
+      if (_unknownFields != null) {
+        size += _unknownFields.CalculateSize();
+      }
+      return size;
+    }
+
+    [global::System.Diagnostics.DebuggerNonUserCodeAttribute]
+    [global::System.CodeDom.Compiler.GeneratedCode("protoc", null)]
+    public void MergeFrom(BattleDestroy other) {
+      if (other == null) {
+        return;
+      }
+      _unknownFields = pb::UnknownFieldSet.MergeFrom(_unknownFields, other._unknownFields);
+    }
+
+    [global::System.Diagnostics.DebuggerNonUserCodeAttribute]
+    [global::System.CodeDom.Compiler.GeneratedCode("protoc", null)]
+    public void MergeFrom(pb::CodedInputStream input) {
+    #if !GOOGLE_PROTOBUF_REFSTRUCT_COMPATIBILITY_MODE
+      input.ReadRawMessage(this);
+    #else
+      uint tag;
+      while ((tag = input.ReadTag()) != 0) {
+        switch(tag) {
+          default:
+            _unknownFields = pb::UnknownFieldSet.MergeFieldFrom(_unknownFields, input);
+            break;
+        }
+      }
+    #endif
+    }
+
+    #if !GOOGLE_PROTOBUF_REFSTRUCT_COMPATIBILITY_MODE
+    [global::System.Diagnostics.DebuggerNonUserCodeAttribute]
+    [global::System.CodeDom.Compiler.GeneratedCode("protoc", null)]
+    void pb::IBufferMessage.InternalMergeFrom(ref pb::ParseContext input) {
+      uint tag;
+      while ((tag = input.ReadTag()) != 0) {
+        switch(tag) {
+          default:
+            _unknownFields = pb::UnknownFieldSet.MergeFieldFrom(_unknownFields, ref input);
+            break;
+        }
+      }
+    }
+    #endif
+
+  }
+
+  public sealed partial class BattleOutput : pb::IMessage<BattleOutput>
+  #if !GOOGLE_PROTOBUF_REFSTRUCT_COMPATIBILITY_MODE
+      , pb::IBufferMessage
+  #endif
+  {
+    private static readonly pb::MessageParser<BattleOutput> _parser = new pb::MessageParser<BattleOutput>(() => new BattleOutput());
+    private pb::UnknownFieldSet _unknownFields;
+    [global::System.Diagnostics.DebuggerNonUserCodeAttribute]
+    [global::System.CodeDom.Compiler.GeneratedCode("protoc", null)]
+    public static pb::MessageParser<BattleOutput> Parser { get { return _parser; } }
+
+    [global::System.Diagnostics.DebuggerNonUserCodeAttribute]
+    [global::System.CodeDom.Compiler.GeneratedCode("protoc", null)]
+    public static pbr::MessageDescriptor Descriptor {
+      get { return global::GoPureWithCsharp.Battle.BattleReflection.Descriptor.MessageTypes[11]; }
+    }
+
+    [global::System.Diagnostics.DebuggerNonUserCodeAttribute]
+    [global::System.CodeDom.Compiler.GeneratedCode("protoc", null)]
+    pbr::MessageDescriptor pb::IMessage.Descriptor {
+      get { return Descriptor; }
+    }
+
+    [global::System.Diagnostics.DebuggerNonUserCodeAttribute]
+    [global::System.CodeDom.Compiler.GeneratedCode("protoc", null)]
+    public BattleOutput() {
+      OnConstruction();
+    }
+
+    partial void OnConstruction();
+
+    [global::System.Diagnostics.DebuggerNonUserCodeAttribute]
+    [global::System.CodeDom.Compiler.GeneratedCode("protoc", null)]
+    public BattleOutput(BattleOutput other) : this() {
+      switch (other.OutputCase) {
+        case OutputOneofCase.Result:
+          Result = other.Result.Clone();
+          break;
+        case OutputOneofCase.Replay:
+          Replay = other.Replay.Clone();
+          break;
+      }
+
+      _unknownFields = pb::UnknownFieldSet.Clone(other._unknownFields);
+    }
+
+    [global::System.Diagnostics.DebuggerNonUserCodeAttribute]
+    [global::System.CodeDom.Compiler.GeneratedCode("protoc", null)]
+    public BattleOutput Clone() {
+      return new BattleOutput(this);
+    }
+
+    /// <summary>Field number for the "result" field.</summary>
+    public const int ResultFieldNumber = 1;
+    /// <summary>
+    /// 战斗结果
+    /// </summary>
+    [global::System.Diagnostics.DebuggerNonUserCodeAttribute]
+    [global::System.CodeDom.Compiler.GeneratedCode("protoc", null)]
+    public global::GoPureWithCsharp.Battle.BattleResult Result {
+      get { return outputCase_ == OutputOneofCase.Result ? (global::GoPureWithCsharp.Battle.BattleResult) output_ : null; }
+      set {
+        output_ = value;
+        outputCase_ = value == null ? OutputOneofCase.None : OutputOneofCase.Result;
+      }
+    }
+
+    /// <summary>Field number for the "replay" field.</summary>
+    public const int ReplayFieldNumber = 2;
+    /// <summary>
+    /// 战斗回放
+    /// </summary>
+    [global::System.Diagnostics.DebuggerNonUserCodeAttribute]
+    [global::System.CodeDom.Compiler.GeneratedCode("protoc", null)]
+    public global::GoPureWithCsharp.Battle.BattleReplay Replay {
+      get { return outputCase_ == OutputOneofCase.Replay ? (global::GoPureWithCsharp.Battle.BattleReplay) output_ : null; }
+      set {
+        output_ = value;
+        outputCase_ = value == null ? OutputOneofCase.None : OutputOneofCase.Replay;
+      }
+    }
+
+    private object output_;
+    /// <summary>Enum of possible cases for the "output" oneof.</summary>
+    public enum OutputOneofCase {
+      None = 0,
+      Result = 1,
+      Replay = 2,
+    }
+    private OutputOneofCase outputCase_ = OutputOneofCase.None;
+    [global::System.Diagnostics.DebuggerNonUserCodeAttribute]
+    [global::System.CodeDom.Compiler.GeneratedCode("protoc", null)]
+    public OutputOneofCase OutputCase {
+      get { return outputCase_; }
+    }
+
+    [global::System.Diagnostics.DebuggerNonUserCodeAttribute]
+    [global::System.CodeDom.Compiler.GeneratedCode("protoc", null)]
+    public void ClearOutput() {
+      outputCase_ = OutputOneofCase.None;
+      output_ = null;
+    }
+
+    [global::System.Diagnostics.DebuggerNonUserCodeAttribute]
+    [global::System.CodeDom.Compiler.GeneratedCode("protoc", null)]
+    public override bool Equals(object other) {
+      return Equals(other as BattleOutput);
+    }
+
+    [global::System.Diagnostics.DebuggerNonUserCodeAttribute]
+    [global::System.CodeDom.Compiler.GeneratedCode("protoc", null)]
+    public bool Equals(BattleOutput other) {
+      if (ReferenceEquals(other, null)) {
+        return false;
+      }
+      if (ReferenceEquals(other, this)) {
+        return true;
+      }
+      if (!object.Equals(Result, other.Result)) return false;
+      if (!object.Equals(Replay, other.Replay)) return false;
+      if (OutputCase != other.OutputCase) return false;
+      return Equals(_unknownFields, other._unknownFields);
+    }
+
+    [global::System.Diagnostics.DebuggerNonUserCodeAttribute]
+    [global::System.CodeDom.Compiler.GeneratedCode("protoc", null)]
+    public override int GetHashCode() {
+      int hash = 1;
+      if (outputCase_ == OutputOneofCase.Result) hash ^= Result.GetHashCode();
+      if (outputCase_ == OutputOneofCase.Replay) hash ^= Replay.GetHashCode();
+      hash ^= (int) outputCase_;
+      if (_unknownFields != null) {
+        hash ^= _unknownFields.GetHashCode();
+      }
+      return hash;
+    }
+
+    [global::System.Diagnostics.DebuggerNonUserCodeAttribute]
+    [global::System.CodeDom.Compiler.GeneratedCode("protoc", null)]
+    public override string ToString() {
+      return pb::JsonFormatter.ToDiagnosticString(this);
+    }
+
+    [global::System.Diagnostics.DebuggerNonUserCodeAttribute]
+    [global::System.CodeDom.Compiler.GeneratedCode("protoc", null)]
+    public void WriteTo(pb::CodedOutputStream output) {
+    #if !GOOGLE_PROTOBUF_REFSTRUCT_COMPATIBILITY_MODE
+      output.WriteRawMessage(this);
+    #else
+      if (outputCase_ == OutputOneofCase.Result) {
+        output.WriteRawTag(10);
+        output.WriteMessage(Result);
+      }
+      if (outputCase_ == OutputOneofCase.Replay) {
+        output.WriteRawTag(18);
+        output.WriteMessage(Replay);
+      }
+      if (_unknownFields != null) {
+        _unknownFields.WriteTo(output);
+      }
+    #endif
+    }
+
+    #if !GOOGLE_PROTOBUF_REFSTRUCT_COMPATIBILITY_MODE
+    [global::System.Diagnostics.DebuggerNonUserCodeAttribute]
+    [global::System.CodeDom.Compiler.GeneratedCode("protoc", null)]
+    void pb::IBufferMessage.InternalWriteTo(ref pb::WriteContext output) {
+      if (outputCase_ == OutputOneofCase.Result) {
+        output.WriteRawTag(10);
+        output.WriteMessage(Result);
+      }
+      if (outputCase_ == OutputOneofCase.Replay) {
+        output.WriteRawTag(18);
+        output.WriteMessage(Replay);
+      }
+      if (_unknownFields != null) {
+        _unknownFields.WriteTo(ref output);
+      }
+    }
+    #endif
+
+    [global::System.Diagnostics.DebuggerNonUserCodeAttribute]
+    [global::System.CodeDom.Compiler.GeneratedCode("protoc", null)]
+    public int CalculateSize() {
+      int size = 0;
+      if (outputCase_ == OutputOneofCase.Result) {
+        size += 1 + pb::CodedOutputStream.ComputeMessageSize(Result);
+      }
+      if (outputCase_ == OutputOneofCase.Replay) {
+        size += 1 + pb::CodedOutputStream.ComputeMessageSize(Replay);
+      }
+      if (_unknownFields != null) {
+        size += _unknownFields.CalculateSize();
+      }
+      return size;
+    }
+
+    [global::System.Diagnostics.DebuggerNonUserCodeAttribute]
+    [global::System.CodeDom.Compiler.GeneratedCode("protoc", null)]
+    public void MergeFrom(BattleOutput other) {
+      if (other == null) {
+        return;
+      }
+      switch (other.OutputCase) {
+        case OutputOneofCase.Result:
+          if (Result == null) {
+            Result = new global::GoPureWithCsharp.Battle.BattleResult();
+          }
+          Result.MergeFrom(other.Result);
+          break;
+        case OutputOneofCase.Replay:
+          if (Replay == null) {
+            Replay = new global::GoPureWithCsharp.Battle.BattleReplay();
+          }
+          Replay.MergeFrom(other.Replay);
+          break;
+      }
+
+      _unknownFields = pb::UnknownFieldSet.MergeFrom(_unknownFields, other._unknownFields);
+    }
+
+    [global::System.Diagnostics.DebuggerNonUserCodeAttribute]
+    [global::System.CodeDom.Compiler.GeneratedCode("protoc", null)]
+    public void MergeFrom(pb::CodedInputStream input) {
+    #if !GOOGLE_PROTOBUF_REFSTRUCT_COMPATIBILITY_MODE
+      input.ReadRawMessage(this);
+    #else
+      uint tag;
+      while ((tag = input.ReadTag()) != 0) {
+        switch(tag) {
+          default:
+            _unknownFields = pb::UnknownFieldSet.MergeFieldFrom(_unknownFields, input);
+            break;
+          case 10: {
+            global::GoPureWithCsharp.Battle.BattleResult subBuilder = new global::GoPureWithCsharp.Battle.BattleResult();
+            if (outputCase_ == OutputOneofCase.Result) {
+              subBuilder.MergeFrom(Result);
+            }
+            input.ReadMessage(subBuilder);
+            Result = subBuilder;
+            break;
+          }
+          case 18: {
+            global::GoPureWithCsharp.Battle.BattleReplay subBuilder = new global::GoPureWithCsharp.Battle.BattleReplay();
+            if (outputCase_ == OutputOneofCase.Replay) {
+              subBuilder.MergeFrom(Replay);
+            }
+            input.ReadMessage(subBuilder);
+            Replay = subBuilder;
+            break;
+          }
+        }
+      }
+    #endif
+    }
+
+    #if !GOOGLE_PROTOBUF_REFSTRUCT_COMPATIBILITY_MODE
+    [global::System.Diagnostics.DebuggerNonUserCodeAttribute]
+    [global::System.CodeDom.Compiler.GeneratedCode("protoc", null)]
+    void pb::IBufferMessage.InternalMergeFrom(ref pb::ParseContext input) {
+      uint tag;
+      while ((tag = input.ReadTag()) != 0) {
+        switch(tag) {
+          default:
+            _unknownFields = pb::UnknownFieldSet.MergeFieldFrom(_unknownFields, ref input);
+            break;
+          case 10: {
+            global::GoPureWithCsharp.Battle.BattleResult subBuilder = new global::GoPureWithCsharp.Battle.BattleResult();
+            if (outputCase_ == OutputOneofCase.Result) {
+              subBuilder.MergeFrom(Result);
+            }
+            input.ReadMessage(subBuilder);
+            Result = subBuilder;
+            break;
+          }
+          case 18: {
+            global::GoPureWithCsharp.Battle.BattleReplay subBuilder = new global::GoPureWithCsharp.Battle.BattleReplay();
+            if (outputCase_ == OutputOneofCase.Replay) {
+              subBuilder.MergeFrom(Replay);
+            }
+            input.ReadMessage(subBuilder);
+            Replay = subBuilder;
+            break;
+          }
+        }
+      }
+    }
+    #endif
+
+  }
+
+  /// <summary>
+  /// 战斗结果
+  /// tick 驱动的战斗 (CreateBattle + OnTick) 以逻辑帧计时: duration 为经历的逻辑帧数
+  /// </summary>
+  public sealed partial class BattleResult : pb::IMessage<BattleResult>
+  #if !GOOGLE_PROTOBUF_REFSTRUCT_COMPATIBILITY_MODE
+      , pb::IBufferMessage
+  #endif
+  {
+    private static readonly pb::MessageParser<BattleResult> _parser = new pb::MessageParser<BattleResult>(() => new BattleResult());
+    private pb::UnknownFieldSet _unknownFields;
+    [global::System.Diagnostics.DebuggerNonUserCodeAttribute]
+    [global::System.CodeDom.Compiler.GeneratedCode("protoc", null)]
+    public static pb::MessageParser<BattleResult> Parser { get { return _parser; } }
+
+    [global::System.Diagnostics.DebuggerNonUserCodeAttribute]
+    [global::System.CodeDom.Compiler.GeneratedCode("protoc", null)]
+    public static pbr::MessageDescriptor Descriptor {
+      get { return global::GoPureWithCsharp.Battle.BattleReflection.Descriptor.MessageTypes[12]; }
+    }
+
+    [global::System.Diagnostics.DebuggerNonUserCodeAttribute]
+    [global::System.CodeDom.Compiler.GeneratedCode("protoc", null)]
+    pbr::MessageDescriptor pb::IMessage.Descriptor {
+      get { return Descriptor; }
+    }
+
+    [global::System.Diagnostics.DebuggerNonUserCodeAttribute]
+    [global::System.CodeDom.Compiler.GeneratedCode("protoc", null)]
+    public BattleResult() {
+      OnConstruction();
+    }
+
+    partial void OnConstruction();
+
+    [global::System.Diagnostics.DebuggerNonUserCodeAttribute]
+    [global::System.CodeDom.Compiler.GeneratedCode("protoc", null)]
+    public BattleResult(BattleResult other) : this() {
+      winner_ = other.winner_;
+      loser_ = other.loser_;
+      atkDamage_ = other.atkDamage_;
+      defDamage_ = other.defDamage_;
+      kills_ = other.kills_.Clone();
+      duration_ = other.duration_;
+      battleScore_ = other.battleScore_;
+      timeout_ = other.timeout_;
+      _unknownFields = pb::UnknownFieldSet.Clone(other._unknownFields);
+    }
+
+    [global::System.Diagnostics.DebuggerNonUserCodeAttribute]
+    [global::System.CodeDom.Compiler.GeneratedCode("protoc", null)]
+    public BattleResult Clone() {
+      return new BattleResult(this);
+    }
+
+    /// <summary>Field number for the "winner" field.</summary>
+    public const int WinnerFieldNumber = 1;
+    private uint winner_;
+    /// <summary>
+    /// 胜方队伍ID
+    /// </summary>
+    [global::System.Diagnostics.DebuggerNonUserCodeAttribute]
+    [global::System.CodeDom.Compiler.GeneratedCode("protoc", null)]
+    public uint Winner {
+      get { return winner_; }
+      set {
+        winner_ = value;
+      }
+    }
+
+    /// <summary>Field number for the "loser" field.</summary>
+    public const int LoserFieldNumber = 2;
+    private uint loser_;
+    /// <summary>
+    /// 败方队伍ID
+    /// </summary>
+    [global::System.Diagnostics.DebuggerNonUserCodeAttribute]
+    [global::System.CodeDom.Compiler.GeneratedCode("protoc", null)]
+    public uint Loser {
+      get { return loser_; }
+      set {
+        loser_ = value;
+      }
+    }
+
+    /// <summary>Field number for the "atk_damage" field.</summary>
+    public const int AtkDamageFieldNumber = 3;
+    private int atkDamage_;
+    /// <summary>
+    /// 攻击方伤害
+    /// </summary>
+    [global::System.Diagnostics.DebuggerNonUserCodeAttribute]
+    [global::System.CodeDom.Compiler.GeneratedCode("protoc", null)]
+    public int AtkDamage {
+      get { return atkDamage_; }
+      set {
+        atkDamage_ = value;
+      }
+    }
+
+    /// <summary>Field number for the "def_damage" field.</summary>
+    public const int DefDamageFieldNumber = 4;
+    private int defDamage_;
+    /// <summary>
+    /// 防守方伤害
+    /// </summary>
+    [global::System.Diagnostics.DebuggerNonUserCodeAttribute]
+    [global::System.CodeDom.Compiler.GeneratedCode("protoc", null)]
+    public int DefDamage {
+      get { return defDamage_; }
+      set {
+        defDamage_ = value;
+      }
+    }
+
+    /// <summary>Field number for the "kills" field.</summary>
+    public const int KillsFieldNumber = 5;
+    private static readonly pb::FieldCodec<uint> _repeated_kills_codec
+        = pb::FieldCodec.ForUInt32(42);
+    private readonly pbc::RepeatedField<uint> kills_ = new pbc::RepeatedField<uint>();
+    /// <summary>
+    /// 被击败的单位ID (按被击败的顺序)
+    /// </summary>
+    [global::System.Diagnostics.DebuggerNonUserCodeAttribute]
+    [global::System.CodeDom.Compiler.GeneratedCode("protoc", null)]
+    public pbc::RepeatedField<uint> Kills {
+      get { return kills_; }
+    }
+
+    /// <summary>Field number for the "duration" field.</summary>
+    public const int DurationFieldNumber = 6;
+    private long duration_;
+    /// <summary>
+    /// 战斗持续时间(毫秒)
+    /// </summary>
+    [global::System.Diagnostics.DebuggerNonUserCodeAttribute]
+    [global::System.CodeDom.Compiler.GeneratedCode("protoc", null)]
+    public long Duration {
+      get { return duration_; }
+      set {
+        duration_ = value;
+      }
+    }
+
+    /// <summary>Field number for the "battle_score" field.</summary>
+    public const int BattleScoreFieldNumber = 7;
     private int battleScore_;
     /// <summary>
-    /// 战斗积分
+    /// 战斗积分
+    /// </summary>
+    [global::System.Diagnostics.DebuggerNonUserCodeAttribute]
+    [global::System.CodeDom.Compiler.GeneratedCode("protoc", null)]
+    public int BattleScore {
+      get { return battleScore_; }
+      set {
+        battleScore_ = value;
+      }
+    }
+
+    /// <summary>Field number for the "timeout" field.</summary>
+    public const int TimeoutFieldNumber = 8;
+    private bool timeout_;
+    /// <summary>
+    /// 超过最长战斗时长被强制结束 (TIMEOUT)
+    /// </summary>
+    [global::System.Diagnostics.DebuggerNonUserCodeAttribute]
+    [global::System.CodeDom.Compiler.GeneratedCode("protoc", null)]
+    public bool Timeout {
+      get { return timeout_; }
+      set {
+        timeout_ = value;
+      }
+    }
+
+    [global::System.Diagnostics.DebuggerNonUserCodeAttribute]
+    [global::System.CodeDom.Compiler.GeneratedCode("protoc", null)]
+    public override bool Equals(object other) {
+      return Equals(other as BattleResult);
+    }
+
+    [global::System.Diagnostics.DebuggerNonUserCodeAttribute]
+    [global::System.CodeDom.Compiler.GeneratedCode("protoc", null)]
+    public bool Equals(BattleResult other) {
+      if (ReferenceEquals(other, null)) {
+        return false;
+      }
+      if (ReferenceEquals(other, this)) {
+        return true;
+      }
+      if (Winner != other.Winner) return false;
+      if (Loser != other.Loser) return false;
+      if (AtkDamage != other.AtkDamage) return false;
+      if (DefDamage != other.DefDamage) return false;
+      if(!kills_.Equals(other.kills_)) return false;
+      if (Duration != other.Duration) return false;
+      if (BattleScore != other.BattleScore) return false;
+      if (Timeout != other.Timeout) return false;
+      return Equals(_unknownFields, other._unknownFields);
+    }
+
+    [global::System.Diagnostics.DebuggerNonUserCodeAttribute]
+    [global::System.CodeDom.Compiler.GeneratedCode("protoc", null)]
+    public override int GetHashCode() {
+      int hash = 1;
+      if (Winner != 0) hash ^= Winner.GetHashCode();
+      if (Loser != 0) hash ^= Loser.GetHashCode();
+      if (AtkDamage != 0) hash ^= AtkDamage.GetHashCode();
+      if (DefDamage != 0) hash ^= DefDamage.GetHashCode();
+      hash ^= kills_.GetHashCode();
+      if (Duration != 0L) hash ^= Duration.GetHashCode();
+      if (BattleScore != 0) hash ^= BattleScore.GetHashCode();
+      if (Timeout != false) hash ^= Timeout.GetHashCode();
+      if (_unknownFields != null) {
+        hash ^= _unknownFields.GetHashCode();
+      }
+      return hash;
+    }
+
+    [global::System.Diagnostics.DebuggerNonUserCodeAttribute]
+    [global::System.CodeDom.Compiler.GeneratedCode("protoc", null)]
+    public override string ToString() {
+      return pb::JsonFormatter.ToDiagnosticString(this);
+    }
+
+    [global::System.Diagnostics.DebuggerNonUserCodeAttribute]
+    [global::System.CodeDom.Compiler.GeneratedCode("protoc", null)]
+    public void WriteTo(pb::CodedOutputStream output) {
+    #if !GOOGLE_PROTOBUF_REFSTRUCT_COMPATIBILITY_MODE
+      output.WriteRawMessage(this);
+    #else
+      if (Winner != 0) {
+        output.WriteRawTag(8);
+        output.WriteUInt32(Winner);
+      }
+      if (Loser != 0) {
+        output.WriteRawTag(16);
+        output.WriteUInt32(Loser);
+      }
+      if (AtkDamage != 0) {
+        output.WriteRawTag(24);
+        output.WriteInt32(AtkDamage);
+      }
+      if (DefDamage != 0) {
+        output.WriteRawTag(32);
+        output.WriteInt32(DefDamage);
+      }
+      kills_.WriteTo(output, _repeated_kills_codec);
+      if (Duration != 0L) {
+        output.WriteRawTag(48);
+        output.WriteInt64(Duration);
+      }
+      if (BattleScore != 0) {
+        output.WriteRawTag(56);
+        output.WriteInt32(BattleScore);
+      }
+      if (Timeout != false) {
+        output.WriteRawTag(64);
+        output.WriteBool(Timeout);
+      }
+      if (_unknownFields != null) {
+        _unknownFields.WriteTo(output);
+      }
+    #endif
+    }
+
+    #if !GOOGLE_PROTOBUF_REFSTRUCT_COMPATIBILITY_MODE
+    [global::System.Diagnostics.DebuggerNonUserCodeAttribute]
+    [global::System.CodeDom.Compiler.GeneratedCode("protoc", null)]
+    void pb::IBufferMessage.InternalWriteTo(ref pb::WriteContext output) {
+      if (Winner != 0) {
+        output.WriteRawTag(8);
+        output.WriteUInt32(Winner);
+      }
+      if (Loser != 0) {
+        output.WriteRawTag(16);
+        output.WriteUInt32(Loser);
+      }
+      if (AtkDamage != 0) {
+        output.WriteRawTag(24);
+        output.WriteInt32(AtkDamage);
+      }
+      if (DefDamage != 0) {
+        output.WriteRawTag(32);
+        output.WriteInt32(DefDamage);
+      }
+      kills_.WriteTo(ref output, _repeated_kills_codec);
+      if (Duration != 0L) {
+        output.WriteRawTag(48);
+        output.WriteInt64(Duration);
+      }
+      if (BattleScore != 0) {
+        output.WriteRawTag(56);
+        output.WriteInt32(BattleScore);
+      }
+      if (Timeout != false) {
+        output.WriteRawTag(64);
+        output.WriteBool(Timeout);
+      }
+      if (_unknownFields != null) {
+        _unknownFields.WriteTo(ref output);
+      }
+    }
+    #endif
+
+    [global::System.Diagnostics.DebuggerNonUserCodeAttribute]
+    [global::System.CodeDom.Compiler.GeneratedCode("protoc", null)]
+    public int CalculateSize() {
+      int size = 0;
+      if (Winner != 0) {
+        size += 1 + pb::CodedOutputStream.ComputeUInt32Size(Winner);
+      }
+      if (Loser != 0) {
+        size += 1 + pb::CodedOutputStream.ComputeUInt32Size(Loser);
+      }
+      if (AtkDamage != 0) {
+        size += 1 + pb::CodedOutputStream.ComputeInt32Size(AtkDamage);
+      }
+      if (DefDamage != 0) {
+        size += 1 + pb::CodedOutputStream.ComputeInt32Size(DefDamage);
+      }
+      size += kills_.CalculateSize(_repeated_kills_codec);
+      if (Duration != 0L) {
+        size += 1 + pb::CodedOutputStream.ComputeInt64Size(Duration);
+      }
+      if (BattleScore != 0) {
+        size += 1 + pb::CodedOutputStream.ComputeInt32Size(BattleScore);
+      }
+      if (Timeout != false) {
+        size += 1 + 1;
+      }
+      if (_unknownFields != null) {
+        size += _unknownFields.CalculateSize();
+      }
+      return size;
+    }
+
+    [global::System.Diagnostics.DebuggerNonUserCodeAttribute]
+    [global::System.CodeDom.Compiler.GeneratedCode("protoc", null)]
+    public void MergeFrom(BattleResult other) {
+      if (other == null) {
+        return;
+      }
+      if (other.Winner != 0) {
+        Winner = other.Winner;
+      }
+      if (other.Loser != 0) {
+        Loser = other.Loser;
+      }
+      if (other.AtkDamage != 0) {
+        AtkDamage = other.AtkDamage;
+      }
+      if (other.DefDamage != 0) {
+        DefDamage = other.DefDamage;
+      }
+      kills_.Add(other.kills_);
+      if (other.Duration != 0L) {
+        Duration = other.Duration;
+      }
+      if (other.BattleScore != 0) {
+        BattleScore = other.BattleScore;
+      }
+      if (other.Timeout != false) {
+        Timeout = other.Timeout;
+      }
+      _unknownFields = pb::UnknownFieldSet.MergeFrom(_unknownFields, other._unknownFields);
+    }
+
+    [global::System.Diagnostics.DebuggerNonUserCodeAttribute]
+    [global::System.CodeDom.Compiler.GeneratedCode("protoc", null)]
+    public void MergeFrom(pb::CodedInputStream input) {
+    #if !GOOGLE_PROTOBUF_REFSTRUCT_COMPATIBILITY_MODE
+      input.ReadRawMessage(this);
+    #else
+      uint tag;
+      while ((tag = input.ReadTag()) != 0) {
+        switch(tag) {
+          default:
+            _unknownFields = pb::UnknownFieldSet.MergeFieldFrom(_unknownFields, input);
+            break;
+          case 8: {
+            Winner = input.ReadUInt32();
+            break;
+          }
+          case 16: {
+            Loser = input.ReadUInt32();
+            break;
+          }
+          case 24: {
+            AtkDamage = input.ReadInt32();
+            break;
+          }
+          case 32: {
+            DefDamage = input.ReadInt32();
+            break;
+          }
+          case 42:
+          case 40: {
+            kills_.AddEntriesFrom(input, _repeated_kills_codec);
+            break;
+          }
+          case 48: {
+            Duration = input.ReadInt64();
+            break;
+          }
+          case 56: {
+            BattleScore = input.ReadInt32();
+            break;
+          }
+          case 64: {
+            Timeout = input.ReadBool();
+            break;
+          }
+        }
+      }
+    #endif
+    }
+
+    #if !GOOGLE_PROTOBUF_REFSTRUCT_COMPATIBILITY_MODE
+    [global::System.Diagnostics.DebuggerNonUserCodeAttribute]
+    [global::System.CodeDom.Compiler.GeneratedCode("protoc", null)]
+    void pb::IBufferMessage.InternalMergeFrom(ref pb::ParseContext input) {
+      uint tag;
+      while ((tag = input.ReadTag()) != 0) {
+        switch(tag) {
+          default:
+            _unknownFields = pb::UnknownFieldSet.MergeFieldFrom(_unknownFields, ref input);
+            break;
+          case 8: {
+            Winner = input.ReadUInt32();
+            break;
+          }
+          case 16: {
+            Loser = input.ReadUInt32();
+            break;
+          }
+          case 24: {
+            AtkDamage = input.ReadInt32();
+            break;
+          }
+          case 32: {
+            DefDamage = input.ReadInt32();
+            break;
+          }
+          case 42:
+          case 40: {
+            kills_.AddEntriesFrom(ref input, _repeated_kills_codec);
+            break;
+          }
+          case 48: {
+            Duration = input.ReadInt64();
+            break;
+          }
+          case 56: {
+            BattleScore = input.ReadInt32();
+            break;
+          }
+          case 64: {
+            Timeout = input.ReadBool();
+            break;
+          }
+        }
+      }
+    }
+    #endif
+
+  }
+
+  /// <summary>
+  /// 战斗状态
+  /// </summary>
+  public sealed partial class BattleStatus : pb::IMessage<BattleStatus>
+  #if !GOOGLE_PROTOBUF_REFSTRUCT_COMPATIBILITY_MODE
+      , pb::IBufferMessage
+  #endif
+  {
+    private static readonly pb::MessageParser<BattleStatus> _parser = new pb::MessageParser<BattleStatus>(() => new BattleStatus());
+    private pb::UnknownFieldSet _unknownFields;
+    [global::System.Diagnostics.DebuggerNonUserCodeAttribute]
+    [global::System.CodeDom.Compiler.GeneratedCode("protoc", null)]
+    public static pb::MessageParser<BattleStatus> Parser { get { return _parser; } }
+
+    [global::System.Diagnostics.DebuggerNonUserCodeAttribute]
+    [global::System.CodeDom.Compiler.GeneratedCode("protoc", null)]
+    public static pbr::MessageDescriptor Descriptor {
+      get { return global::GoPureWithCsharp.Battle.BattleReflection.Descriptor.MessageTypes[13]; }
+    }
+
+    [global::System.Diagnostics.DebuggerNonUserCodeAttribute]
+    [global::System.CodeDom.Compiler.GeneratedCode("protoc", null)]
+    pbr::MessageDescriptor pb::IMessage.Descriptor {
+      get { return Descriptor; }
+    }
+
+    [global::System.Diagnostics.DebuggerNonUserCodeAttribute]
+    [global::System.CodeDom.Compiler.GeneratedCode("protoc", null)]
+    public BattleStatus() {
+      OnConstruction();
+    }
+
+    partial void OnConstruction();
+
+    [global::System.Diagnostics.DebuggerNonUserCodeAttribute]
+    [global::System.CodeDom.Compiler.GeneratedCode("protoc", null)]
+    public BattleStatus(BattleStatus other) : this() {
+      battleId_ = other.battleId_;
+      round_ = other.round_;
+      atkHealth_ = other.atkHealth_;
+      defHealth_ = other.defHealth_;
+      state_ = other.state_;
+      timestamp_ = other.timestamp_;
+      atkTeamId_ = other.atkTeamId_;
+      defTeamId_ = other.defTeamId_;
+      winner_ = other.winner_;
+      atkMaxHealth_ = other.atkMaxHealth_;
+      defMaxHealth_ = other.defMaxHealth_;
+      atkAttack_ = other.atkAttack_;
+      defAttack_ = other.defAttack_;
+      units_ = other.units_.Clone();
+      shardId_ = other.shardId_;
+      _unknownFields = pb::UnknownFieldSet.Clone(other._unknownFields);
+    }
+
+    [global::System.Diagnostics.DebuggerNonUserCodeAttribute]
+    [global::System.CodeDom.Compiler.GeneratedCode("protoc", null)]
+    public BattleStatus Clone() {
+      return new BattleStatus(this);
+    }
+
+    /// <summary>Field number for the "battle_id" field.</summary>
+    public const int BattleIdFieldNumber = 1;
+    private uint battleId_;
+    /// <summary>
+    /// 战斗ID
+    /// </summary>
+    [global::System.Diagnostics.DebuggerNonUserCodeAttribute]
+    [global::System.CodeDom.Compiler.GeneratedCode("protoc", null)]
+    public uint BattleId {
+      get { return battleId_; }
+      set {
+        battleId_ = value;
+      }
+    }
+
+    /// <summary>Field number for the "round" field.</summary>
+    public const int RoundFieldNumber = 2;
+    private int round_;
+    /// <summary>
+    /// 当前回合
+    /// </summary>
+    [global::System.Diagnostics.DebuggerNonUserCodeAttribute]
+    [global::System.CodeDom.Compiler.GeneratedCode("protoc", null)]
+    public int Round {
+      get { return round_; }
+      set {
+        round_ = value;
+      }
+    }
+
+    /// <summary>Field number for the "atk_health" field.</summary>
+    public const int AtkHealthFieldNumber = 3;
+    private int atkHealth_;
+    /// <summary>
+    /// 攻击方生命值 (存活单位之和)
+    /// </summary>
+    [global::System.Diagnostics.DebuggerNonUserCodeAttribute]
+    [global::System.CodeDom.Compiler.GeneratedCode("protoc", null)]
+    public int AtkHealth {
+      get { return atkHealth_; }
+      set {
+        atkHealth_ = value;
+      }
+    }
+
+    /// <summary>Field number for the "def_health" field.</summary>
+    public const int DefHealthFieldNumber = 4;
+    private int defHealth_;
+    /// <summary>
+    /// 防守方生命值
+    /// </summary>
+    [global::System.Diagnostics.DebuggerNonUserCodeAttribute]
+    [global::System.CodeDom.Compiler.GeneratedCode("protoc", null)]
+    public int DefHealth {
+      get { return defHealth_; }
+      set {
+        defHealth_ = value;
+      }
+    }
+
+    /// <summary>Field number for the "state" field.</summary>
+    public const int StateFieldNumber = 5;
+    private string state_ = "";
+    /// <summary>
+    /// 战斗状态 (running/paused/finished)
+    /// </summary>
+    [global::System.Diagnostics.DebuggerNonUserCodeAttribute]
+    [global::System.CodeDom.Compiler.GeneratedCode("protoc", null)]
+    public string State {
+      get { return state_; }
+      set {
+        state_ = pb::ProtoPreconditions.CheckNotNull(value, "value");
+      }
+    }
+
+    /// <summary>Field number for the "timestamp" field.</summary>
+    public const int TimestampFieldNumber = 6;
+    private long timestamp_;
+    /// <summary>
+    /// 时间戳
+    /// </summary>
+    [global::System.Diagnostics.DebuggerNonUserCodeAttribute]
+    [global::System.CodeDom.Compiler.GeneratedCode("protoc", null)]
+    public long Timestamp {
+      get { return timestamp_; }
+      set {
+        timestamp_ = value;
+      }
+    }
+
+    /// <summary>Field number for the "atk_team_id" field.</summary>
+    public const int AtkTeamIdFieldNumber = 7;
+    private uint atkTeamId_;
+    /// <summary>
+    /// 攻击方队伍ID
+    /// </summary>
+    [global::System.Diagnostics.DebuggerNonUserCodeAttribute]
+    [global::System.CodeDom.Compiler.GeneratedCode("protoc", null)]
+    public uint AtkTeamId {
+      get { return atkTeamId_; }
+      set {
+        atkTeamId_ = value;
+      }
+    }
+
+    /// <summary>Field number for the "def_team_id" field.</summary>
+    public const int DefTeamIdFieldNumber = 8;
+    private uint defTeamId_;
+    /// <summary>
+    /// 防守方队伍ID
+    /// </summary>
+    [global::System.Diagnostics.DebuggerNonUserCodeAttribute]
+    [global::System.CodeDom.Compiler.GeneratedCode("protoc", null)]
+    public uint DefTeamId {
+      get { return defTeamId_; }
+      set {
+        defTeamId_ = value;
+      }
+    }
+
+    /// <summary>Field number for the "winner" field.</summary>
+    public const int WinnerFieldNumber = 9;
+    private uint winner_;
+    /// <summary>
+    /// 获胜方队伍ID (finished 时有效)
+    /// </summary>
+    [global::System.Diagnostics.DebuggerNonUserCodeAttribute]
+    [global::System.CodeDom.Compiler.GeneratedCode("protoc", null)]
+    public uint Winner {
+      get { return winner_; }
+      set {
+        winner_ = value;
+      }
+    }
+
+    /// <summary>Field number for the "atk_max_health" field.</summary>
+    public const int AtkMaxHealthFieldNumber = 10;
+    private int atkMaxHealth_;
+    /// <summary>
+    /// 攻击方最大生命 (各单位之和，属性 3001)
+    /// </summary>
+    [global::System.Diagnostics.DebuggerNonUserCodeAttribute]
+    [global::System.CodeDom.Compiler.GeneratedCode("protoc", null)]
+    public int AtkMaxHealth {
+      get { return atkMaxHealth_; }
+      set {
+        atkMaxHealth_ = value;
+      }
+    }
+
+    /// <summary>Field number for the "def_max_health" field.</summary>
+    public const int DefMaxHealthFieldNumber = 11;
+    private int defMaxHealth_;
+    /// <summary>
+    /// 防守方最大生命
+    /// </summary>
+    [global::System.Diagnostics.DebuggerNonUserCodeAttribute]
+    [global::System.CodeDom.Compiler.GeneratedCode("protoc", null)]
+    public int DefMaxHealth {
+      get { return defMaxHealth_; }
+      set {
+        defMaxHealth_ = value;
+      }
+    }
+
+    /// <summary>Field number for the "atk_attack" field.</summary>
+    public const int AtkAttackFieldNumber = 12;
+    private int atkAttack_;
+    /// <summary>
+    /// 攻击方攻击力 (各单位之和，属性 3002)
+    /// </summary>
+    [global::System.Diagnostics.DebuggerNonUserCodeAttribute]
+    [global::System.CodeDom.Compiler.GeneratedCode("protoc", null)]
+    public int AtkAttack {
+      get { return atkAttack_; }
+      set {
+        atkAttack_ = value;
+      }
+    }
+
+    /// <summary>Field number for the "def_attack" field.</summary>
+    public const int DefAttackFieldNumber = 13;
+    private int defAttack_;
+    /// <summary>
+    /// 防守方攻击力
+    /// </summary>
+    [global::System.Diagnostics.DebuggerNonUserCodeAttribute]
+    [global::System.CodeDom.Compiler.GeneratedCode("protoc", null)]
+    public int DefAttack {
+      get { return defAttack_; }
+      set {
+        defAttack_ = value;
+      }
+    }
+
+    /// <summary>Field number for the "units" field.</summary>
+    public const int UnitsFieldNumber = 14;
+    private static readonly pb::FieldCodec<global::GoPureWithCsharp.Battle.BattleUnit> _repeated_units_codec
+        = pb::FieldCodec.ForMessage(114, global::GoPureWithCsharp.Battle.BattleUnit.Parser);
+    private readonly pbc::RepeatedField<global::GoPureWithCsharp.Battle.BattleUnit> units_ = new pbc::RepeatedField<global::GoPureWithCsharp.Battle.BattleUnit>();
+    /// <summary>
+    /// 所有单位，攻击方在前，按阵容顺序
+    /// </summary>
+    [global::System.Diagnostics.DebuggerNonUserCodeAttribute]
+    [global::System.CodeDom.Compiler.GeneratedCode("protoc", null)]
+    public pbc::RepeatedField<global::GoPureWithCsharp.Battle.BattleUnit> Units {
+      get { return units_; }
+    }
+
+    /// <summary>Field number for the "shard_id" field.</summary>
+    public const int ShardIdFieldNumber = 15;
+    private uint shardId_;
+    /// <summary>
+    /// 战斗所在的分片 (CreateShardBattle 指定，CreateBattle 为 0)
+    /// </summary>
+    [global::System.Diagnostics.DebuggerNonUserCodeAttribute]
+    [global::System.CodeDom.Compiler.GeneratedCode("protoc", null)]
+    public uint ShardId {
+      get { return shardId_; }
+      set {
+        shardId_ = value;
+      }
+    }
+
+    [global::System.Diagnostics.DebuggerNonUserCodeAttribute]
+    [global::System.CodeDom.Compiler.GeneratedCode("protoc", null)]
+    public override bool Equals(object other) {
+      return Equals(other as BattleStatus);
+    }
+
+    [global::System.Diagnostics.DebuggerNonUserCodeAttribute]
+    [global::System.CodeDom.Compiler.GeneratedCode("protoc", null)]
+    public bool Equals(BattleStatus other) {
+      if (ReferenceEquals(other, null)) {
+        return false;
+      }
+      if (ReferenceEquals(other, this)) {
+        return true;
+      }
+      if (BattleId != other.BattleId) return false;
+      if (Round != other.Round) return false;
+      if (AtkHealth != other.AtkHealth) return false;
+      if (DefHealth != other.DefHealth) return false;
+      if (State != other.State) return false;
+      if (Timestamp != other.Timestamp) return false;
+      if (AtkTeamId != other.AtkTeamId) return false;
+      if (DefTeamId != other.DefTeamId) return false;
+      if (Winner != other.Winner) return false;
+      if (AtkMaxHealth != other.AtkMaxHealth) return false;
+      if (DefMaxHealth != other.DefMaxHealth) return false;
+      if (AtkAttack != other.AtkAttack) return false;
+      if (DefAttack != other.DefAttack) return false;
+      if(!units_.Equals(other.units_)) return false;
+      if (ShardId != other.ShardId) return false;
+      return Equals(_unknownFields, other._unknownFields);
+    }
+
+    [global::System.Diagnostics.DebuggerNonUserCodeAttribute]
+    [global::System.CodeDom.Compiler.GeneratedCode("protoc", null)]
+    public override int GetHashCode() {
+      int hash = 1;
+      if (BattleId != 0) hash ^= BattleId.GetHashCode();
+      if (Round != 0) hash ^= Round.GetHashCode();
+      if (AtkHealth != 0) hash ^= AtkHealth.GetHashCode();
+      if (DefHealth != 0) hash ^= DefHealth.GetHashCode();
+      if (State.Length != 0) hash ^= State.GetHashCode();
+      if (Timestamp != 0L) hash ^= Timestamp.GetHashCode();
+      if (AtkTeamId != 0) hash ^= AtkTeamId.GetHashCode();
+      if (DefTeamId != 0) hash ^= DefTeamId.GetHashCode();
+      if (Winner != 0) hash ^= Winner.GetHashCode();
+      if (AtkMaxHealth != 0) hash ^= AtkMaxHealth.GetHashCode();
+      if (DefMaxHealth != 0) hash ^= DefMaxHealth.GetHashCode();
+      if (AtkAttack != 0) hash ^= AtkAttack.GetHashCode();
+      if (DefAttack != 0) hash ^= DefAttack.GetHashCode();
+      hash ^= units_.GetHashCode();
+      if (ShardId != 0) hash ^= ShardId.GetHashCode();
+      if (_unknownFields != null) {
+        hash ^= _unknownFields.GetHashCode();
+      }
+      return hash;
+    }
+
+    [global::System.Diagnostics.DebuggerNonUserCodeAttribute]
+    [global::System.CodeDom.Compiler.GeneratedCode("protoc", null)]
+    public override string ToString() {
+      return pb::JsonFormatter.ToDiagnosticString(this);
+    }
+
+    [global::System.Diagnostics.DebuggerNonUserCodeAttribute]
+    [global::System.CodeDom.Compiler.GeneratedCode("protoc", null)]
+    public void WriteTo(pb::CodedOutputStream output) {
+    #if !GOOGLE_PROTOBUF_REFSTRUCT_COMPATIBILITY_MODE
+      output.WriteRawMessage(this);
+    #else
+      if (BattleId != 0) {
+        output.WriteRawTag(8);
+        output.WriteUInt32(BattleId);
+      }
+      if (Round != 0) {
+        output.WriteRawTag(16);
+        output.WriteInt32(Round);
+      }
+      if (AtkHealth != 0) {
+        output.WriteRawTag(24);
+        output.WriteInt32(AtkHealth);
+      }
+      if (DefHealth != 0) {
+        output.WriteRawTag(32);
+        output.WriteInt32(DefHealth);
+      }
+      if (State.Length != 0) {
+        output.WriteRawTag(42);
+        output.WriteString(State);
+      }
+      if (Timestamp != 0L) {
+        output.WriteRawTag(48);
+        output.WriteInt64(Timestamp);
+      }
+      if (AtkTeamId != 0) {
+        output.WriteRawTag(56);
+        output.WriteUInt32(AtkTeamId);
+      }
+      if (DefTeamId != 0) {
+        output.WriteRawTag(64);
+        output.WriteUInt32(DefTeamId);
+      }
+      if (Winner != 0) {
+        output.WriteRawTag(72);
+        output.WriteUInt32(Winner);
+      }
+      if (AtkMaxHealth != 0) {
+        output.WriteRawTag(80);
+        output.WriteInt32(AtkMaxHealth);
+      }
+      if (DefMaxHealth != 0) {
+        output.WriteRawTag(88);
+        output.WriteInt32(DefMaxHealth);
+      }
+      if (AtkAttack != 0) {
+        output.WriteRawTag(96);
+        output.WriteInt32(AtkAttack);
+      }
+      if (DefAttack != 0) {
+        output.WriteRawTag(104);
+        output.WriteInt32(DefAttack);
+      }
+      units_.WriteTo(output, _repeated_units_codec);
+      if (ShardId != 0) {
+        output.WriteRawTag(120);
+        output.WriteUInt32(ShardId);
+      }
+      if (_unknownFields != null) {
+        _unknownFields.WriteTo(output);
+      }
+    #endif
+    }
+
+    #if !GOOGLE_PROTOBUF_REFSTRUCT_COMPATIBILITY_MODE
+    [global::System.Diagnostics.DebuggerNonUserCodeAttribute]
+    [global::System.CodeDom.Compiler.GeneratedCode("protoc", null)]
+    void pb::IBufferMessage.InternalWriteTo(ref pb::WriteContext output) {
+      if (BattleId != 0) {
+        output.WriteRawTag(8);
+        output.WriteUInt32(BattleId);
+      }
+      if (Round != 0) {
+        output.WriteRawTag(16);
+        output.WriteInt32(Round);
+      }
+      if (AtkHealth != 0) {
+        output.WriteRawTag(24);
+        output.WriteInt32(AtkHealth);
+      }
+      if (DefHealth != 0) {
+        output.WriteRawTag(32);
+        output.WriteInt32(DefHealth);
+      }
+      if (State.Length != 0) {
+        output.WriteRawTag(42);
+        output.WriteString(State);
+      }
+      if (Timestamp != 0L) {
+        output.WriteRawTag(48);
+        output.WriteInt64(Timestamp);
+      }
+      if (AtkTeamId != 0) {
+        output.WriteRawTag(56);
+        output.WriteUInt32(AtkTeamId);
+      }
+      if (DefTeamId != 0) {
+        output.WriteRawTag(64);
+        output.WriteUInt32(DefTeamId);
+      }
+      if (Winner != 0) {
+        output.WriteRawTag(72);
+        output.WriteUInt32(Winner);
+      }
+      if (AtkMaxHealth != 0) {
+        output.WriteRawTag(80);
+        output.WriteInt32(AtkMaxHealth);
+      }
+      if (DefMaxHealth != 0) {
+        output.WriteRawTag(88);
+        output.WriteInt32(DefMaxHealth);
+      }
+      if (AtkAttack != 0) {
+        output.WriteRawTag(96);
+        output.WriteInt32(AtkAttack);
+      }
+      if (DefAttack != 0) {
+        output.WriteRawTag(104);
+        output.WriteInt32(DefAttack);
+      }
+      units_.WriteTo(ref output, _repeated_units_codec);
+      if (ShardId != 0) {
+        output.WriteRawTag(120);
+        output.WriteUInt32(ShardId);
+      }
+      if (_unknownFields != null) {
+        _unknownFields.WriteTo(ref output);
+      }
+    }
+    #endif
+
+    [global::System.Diagnostics.DebuggerNonUserCodeAttribute]
+    [global::System.CodeDom.Compiler.GeneratedCode("protoc", null)]
+    public int CalculateSize() {
+      int size = 0;
+      if (BattleId != 0) {
+        size += 1 + pb::CodedOutputStream.ComputeUInt32Size(BattleId);
+      }
+      if (Round != 0) {
+        size += 1 + pb::CodedOutputStream.ComputeInt32Size(Round);
+      }
+      if (AtkHealth != 0) {
+        size += 1 + pb::CodedOutputStream.ComputeInt32Size(AtkHealth);
+      }
+      if (DefHealth != 0) {
+        size += 1 + pb::CodedOutputStream.ComputeInt32Size(DefHealth);
+      }
+      if (State.Length != 0) {
+        size += 1 + pb::CodedOutputStream.ComputeStringSize(State);
+      }
+      if (Timestamp != 0L) {
+        size += 1 + pb::CodedOutputStream.ComputeInt64Size(Timestamp);
+      }
+      if (AtkTeamId != 0) {
+        size += 1 + pb::CodedOutputStream.ComputeUInt32Size(AtkTeamId);
+      }
+      if (DefTeamId != 0) {
+        size += 1 + pb::CodedOutputStream.ComputeUInt32Size(DefTeamId);
+      }
+      if (Winner != 0) {
+        size += 1 + pb::CodedOutputStream.ComputeUInt32Size(Winner);
+      }
+      if (AtkMaxHealth != 0) {
+        size += 1 + pb::CodedOutputStream.ComputeInt32Size(AtkMaxHealth);
+      }
+      if (DefMaxHealth != 0) {
+        size += 1 + pb::CodedOutputStream.ComputeInt32Size(DefMaxHealth);
+      }
+      if (AtkAttack != 0) {
+        size += 1 + pb::CodedOutputStream.ComputeInt32Size(AtkAttack);
+      }
+      if (DefAttack != 0) {
+        size += 1 + pb::CodedOutputStream.ComputeInt32Size(DefAttack);
+      }
+      size += units_.CalculateSize(_repeated_units_codec);
+      if (ShardId != 0) {
+        size += 1 + pb::CodedOutputStream.ComputeUInt32Size(ShardId);
+      }
+      if (_unknownFields != null) {
+        size += _unknownFields.CalculateSize();
+      }
+      return size;
+    }
+
+    [global::System.Diagnostics.DebuggerNonUserCodeAttribute]
+    [global::System.CodeDom.Compiler.GeneratedCode("protoc", null)]
+    public void MergeFrom(BattleStatus other) {
+      if (other == null) {
+        return;
+      }
+      if (other.BattleId != 0) {
+        BattleId = other.BattleId;
+      }
+      if (other.Round != 0) {
+        Round = other.Round;
+      }
+      if (other.AtkHealth != 0) {
+        AtkHealth = other.AtkHealth;
+      }
+      if (other.DefHealth != 0) {
+        DefHealth = other.DefHealth;
+      }
+      if (other.State.Length != 0) {
+        State = other.State;
+      }
+      if (other.Timestamp != 0L) {
+        Timestamp = other.Timestamp;
+      }
+      if (other.AtkTeamId != 0) {
+        AtkTeamId = other.AtkTeamId;
+      }
+      if (other.DefTeamId != 0) {
+        DefTeamId = other.DefTeamId;
+      }
+      if (other.Winner != 0) {
+        Winner = other.Winner;
+      }
+      if (other.AtkMaxHealth != 0) {
+        AtkMaxHealth = other.AtkMaxHealth;
+      }
+      if (other.DefMaxHealth != 0) {
+        DefMaxHealth = other.DefMaxHealth;
+      }
+      if (other.AtkAttack != 0) {
+        AtkAttack = other.AtkAttack;
+      }
+      if (other.DefAttack != 0) {
+        DefAttack = other.DefAttack;
+      }
+      units_.Add(other.units_);
+      if (other.ShardId != 0) {
+        ShardId = other.ShardId;
+      }
+      _unknownFields = pb::UnknownFieldSet.MergeFrom(_unknownFields, other._unknownFields);
+    }
+
+    [global::System.Diagnostics.DebuggerNonUserCodeAttribute]
+    [global::System.CodeDom.Compiler.GeneratedCode("protoc", null)]
+    public void MergeFrom(pb::CodedInputStream input) {
+    #if !GOOGLE_PROTOBUF_REFSTRUCT_COMPATIBILITY_MODE
+      input.ReadRawMessage(this);
+    #else
+      uint tag;
+      while ((tag = input.ReadTag()) != 0) {
+        switch(tag) {
+          default:
+            _unknownFields = pb::UnknownFieldSet.MergeFieldFrom(_unknownFields, input);
+            break;
+          case 8: {
+            BattleId = input.ReadUInt32();
+            break;
+          }
+          case 16: {
+            Round = input.ReadInt32();
+            break;
+          }
+          case 24: {
+            AtkHealth = input.ReadInt32();
+            break;
+          }
+          case 32: {
+            DefHealth = input.ReadInt32();
+            break;
+          }
+          case 42: {
+            State = input.ReadString();
+            break;
+          }
+          case 48: {
+            Timestamp = input.ReadInt64();
+            break;
+          }
+          case 56: {
+            AtkTeamId = input.ReadUInt32();
+            break;
+          }
+          case 64: {
+            DefTeamId = input.ReadUInt32();
+            break;
+          }
+          case 72: {
+            Winner = input.ReadUInt32();
+            break;
+          }
+          case 80: {
+            AtkMaxHealth = input.ReadInt32();
+            break;
+          }
+          case 88: {
+            DefMaxHealth = input.ReadInt32();
+            break;
+          }
+          case 96: {
+            AtkAttack = input.ReadInt32();
+            break;
+          }
+          case 104: {
+            DefAttack = input.ReadInt32();
+            break;
+          }
+          case 114: {
+            units_.AddEntriesFrom(input, _repeated_units_codec);
+            break;
+          }
+          case 120: {
+            ShardId = input.ReadUInt32();
+            break;
+          }
+        }
+      }
+    #endif
+    }
+
+    #if !GOOGLE_PROTOBUF_REFSTRUCT_COMPATIBILITY_MODE
+    [global::System.Diagnostics.DebuggerNonUserCodeAttribute]
+    [global::System.CodeDom.Compiler.GeneratedCode("protoc", null)]
+    void pb::IBufferMessage.InternalMergeFrom(ref pb::ParseContext input) {
+      uint tag;
+      while ((tag = input.ReadTag()) != 0) {
+        switch(tag) {
+          default:
+            _unknownFields = pb::UnknownFieldSet.MergeFieldFrom(_unknownFields, ref input);
+            break;
+          case 8: {
+            BattleId = input.ReadUInt32();
+            break;
+          }
+          case 16: {
+            Round = input.ReadInt32();
+            break;
+          }
+          case 24: {
+            AtkHealth = input.ReadInt32();
+            break;
+          }
+          case 32: {
+            DefHealth = input.ReadInt32();
+            break;
+          }
+          case 42: {
+            State = input.ReadString();
+            break;
+          }
+          case 48: {
+            Timestamp = input.ReadInt64();
+            break;
+          }
+          case 56: {
+            AtkTeamId = input.ReadUInt32();
+            break;
+          }
+          case 64: {
+            DefTeamId = input.ReadUInt32();
+            break;
+          }
+          case 72: {
+            Winner = input.ReadUInt32();
+            break;
+          }
+          case 80: {
+            AtkMaxHealth = input.ReadInt32();
+            break;
+          }
+          case 88: {
+            DefMaxHealth = input.ReadInt32();
+            break;
+          }
+          case 96: {
+            AtkAttack = input.ReadInt32();
+            break;
+          }
+          case 104: {
+            DefAttack = input.ReadInt32();
+            break;
+          }
+          case 114: {
+            units_.AddEntriesFrom(ref input, _repeated_units_codec);
+            break;
+          }
+          case 120: {
+            ShardId = input.ReadUInt32();
+            break;
+          }
+        }
+      }
+    }
+    #endif
+
+  }
+
+  /// <summary>
+  /// 战斗状态列表 (ListBattleStatus 导出函数的响应)
+  /// </summary>
+  public sealed partial class BattleStatusList : pb::IMessage<BattleStatusList>
+  #if !GOOGLE_PROTOBUF_REFSTRUCT_COMPATIBILITY_MODE
+      , pb::IBufferMessage
+  #endif
+  {
+    private static readonly pb::MessageParser<BattleStatusList> _parser = new pb::MessageParser<BattleStatusList>(() => new BattleStatusList());
+    private pb::UnknownFieldSet _unknownFields;
+    [global::System.Diagnostics.DebuggerNonUserCodeAttribute]
+    [global::System.CodeDom.Compiler.GeneratedCode("protoc", null)]
+    public static pb::MessageParser<BattleStatusList> Parser { get { return _parser; } }
+
+    [global::System.Diagnostics.DebuggerNonUserCodeAttribute]
+    [global::System.CodeDom.Compiler.GeneratedCode("protoc", null)]
+    public static pbr::MessageDescriptor Descriptor {
+      get { return global::GoPureWithCsharp.Battle.BattleReflection.Descriptor.MessageTypes[14]; }
+    }
+
+    [global::System.Diagnostics.DebuggerNonUserCodeAttribute]
+    [global::System.CodeDom.Compiler.GeneratedCode("protoc", null)]
+    pbr::MessageDescriptor pb::IMessage.Descriptor {
+      get { return Descriptor; }
+    }
+
+    [global::System.Diagnostics.DebuggerNonUserCodeAttribute]
+    [global::System.CodeDom.Compiler.GeneratedCode("protoc", null)]
+    public BattleStatusList() {
+      OnConstruction();
+    }
+
+    partial void OnConstruction();
+
+    [global::System.Diagnostics.DebuggerNonUserCodeAttribute]
+    [global::System.CodeDom.Compiler.GeneratedCode("protoc", null)]
+    public BattleStatusList(BattleStatusList other) : this() {
+      battles_ = other.battles_.Clone();
+      _unknownFields = pb::UnknownFieldSet.Clone(other._unknownFields);
+    }
+
+    [global::System.Diagnostics.DebuggerNonUserCodeAttribute]
+    [global::System.CodeDom.Compiler.GeneratedCode("protoc", null)]
+    public BattleStatusList Clone() {
+      return new BattleStatusList(this);
+    }
+
+    /// <summary>Field number for the "battles" field.</summary>
+    public const int BattlesFieldNumber = 1;
+    private static readonly pb::FieldCodec<global::GoPureWithCsharp.Battle.BattleStatus> _repeated_battles_codec
+        = pb::FieldCodec.ForMessage(10, global::GoPureWithCsharp.Battle.BattleStatus.Parser);
+    private readonly pbc::RepeatedField<global::GoPureWithCsharp.Battle.BattleStatus> battles_ = new pbc::RepeatedField<global::GoPureWithCsharp.Battle.BattleStatus>();
+    [global::System.Diagnostics.DebuggerNonUserCodeAttribute]
+    [global::System.CodeDom.Compiler.GeneratedCode("protoc", null)]
+    public pbc::RepeatedField<global::GoPureWithCsharp.Battle.BattleStatus> Battles {
+      get { return battles_; }
+    }
+
+    [global::System.Diagnostics.DebuggerNonUserCodeAttribute]
+    [global::System.CodeDom.Compiler.GeneratedCode("protoc", null)]
+    public override bool Equals(object other) {
+      return Equals(other as BattleStatusList);
+    }
+
+    [global::System.Diagnostics.DebuggerNonUserCodeAttribute]
+    [global::System.CodeDom.Compiler.GeneratedCode("protoc", null)]
+    public bool Equals(BattleStatusList other) {
+      if (ReferenceEquals(other, null)) {
+        return false;
+      }
+      if (ReferenceEquals(other, this)) {
+        return true;
+      }
+      if(!battles_.Equals(other.battles_)) return false;
+      return Equals(_unknownFields, other._unknownFields);
+    }
+
+    [global::System.Diagnostics.DebuggerNonUserCodeAttribute]
+    [global::System.CodeDom.Compiler.GeneratedCode("protoc", null)]
+    public override int GetHashCode() {
+      int hash = 1;
+      hash ^= battles_.GetHashCode();
+      if (_unknownFields != null) {
+        hash ^= _unknownFields.GetHashCode();
+      }
+      return hash;
+    }
+
+    [global::System.Diagnostics.DebuggerNonUserCodeAttribute]
+    [global::System.CodeDom.Compiler.GeneratedCode("protoc", null)]
+    public override string ToString() {
+      return pb::JsonFormatter.ToDiagnosticString(this);
+    }
+
+    [global::System.Diagnostics.DebuggerNonUserCodeAttribute]
+    [global::System.CodeDom.Compiler.GeneratedCode("protoc", null)]
+    public void WriteTo(pb::CodedOutputStream output) {
+    #if !GOOGLE_PROTOBUF_REFSTRUCT_COMPATIBILITY_MODE
+      output.WriteRawMessage(this);
+    #else
+      battles_.WriteTo(output, _repeated_battles_codec);
+      if (_unknownFields != null) {
+        _unknownFields.WriteTo(output);
+      }
+    #endif
+    }
+
+    #if !GOOGLE_PROTOBUF_REFSTRUCT_COMPATIBILITY_MODE
+    [global::System.Diagnostics.DebuggerNonUserCodeAttribute]
+    [global::System.CodeDom.Compiler.GeneratedCode("protoc", null)]
+    void pb::IBufferMessage.InternalWriteTo(ref pb::WriteContext output) {
+      battles_.WriteTo(ref output, _repeated_battles_codec);
+      if (_unknownFields != null) {
+        _unknownFields.WriteTo(ref output);
+      }
+    }
+    #endif
+
+    [global::System.Diagnostics.DebuggerNonUserCodeAttribute]
+    [global::System.CodeDom.Compiler.GeneratedCode("protoc", null)]
+    public int CalculateSize() {
+      int size = 0;
+      size += battles_.CalculateSize(_repeated_battles_codec);
+      if (_unknownFields != null) {
+        size += _unknownFields.CalculateSize();
+      }
+      return size;
+    }
+
+    [global::System.Diagnostics.DebuggerNonUserCodeAttribute]
+    [global::System.CodeDom.Compiler.GeneratedCode("protoc", null)]
+    public void MergeFrom(BattleStatusList other) {
+      if (other == null) {
+        return;
+      }
+      battles_.Add(other.battles_);
+      _unknownFields = pb::UnknownFieldSet.MergeFrom(_unknownFields, other._unknownFields);
+    }
+
+    [global::System.Diagnostics.DebuggerNonUserCodeAttribute]
+    [global::System.CodeDom.Compiler.GeneratedCode("protoc", null)]
+    public void MergeFrom(pb::CodedInputStream input) {
+    #if !GOOGLE_PROTOBUF_REFSTRUCT_COMPATIBILITY_MODE
+      input.ReadRawMessage(this);
+    #else
+      uint tag;
+      while ((tag = input.ReadTag()) != 0) {
+        switch(tag) {
+          default:
+            _unknownFields = pb::UnknownFieldSet.MergeFieldFrom(_unknownFields, input);
+            break;
+          case 10: {
+            battles_.AddEntriesFrom(input, _repeated_battles_codec);
+            break;
+          }
+        }
+      }
+    #endif
+    }
+
+    #if !GOOGLE_PROTOBUF_REFSTRUCT_COMPATIBILITY_MODE
+    [global::System.Diagnostics.DebuggerNonUserCodeAttribute]
+    [global::System.CodeDom.Compiler.GeneratedCode("protoc", null)]
+    void pb::IBufferMessage.InternalMergeFrom(ref pb::ParseContext input) {
+      uint tag;
+      while ((tag = input.ReadTag()) != 0) {
+        switch(tag) {
+          default:
+            _unknownFields = pb::UnknownFieldSet.MergeFieldFrom(_unknownFields, ref input);
+            break;
+          case 10: {
+            battles_.AddEntriesFrom(ref input, _repeated_battles_codec);
+            break;
+          }
+        }
+      }
+    }
+    #endif
+
+  }
+
+  /// <summary>
+  /// 通用响应消息
+  /// </summary>
+  public sealed partial class BattleResponse : pb::IMessage<BattleResponse>
+  #if !GOOGLE_PROTOBUF_REFSTRUCT_COMPATIBILITY_MODE
+      , pb::IBufferMessage
+  #endif
+  {
+    private static readonly pb::MessageParser<BattleResponse> _parser = new pb::MessageParser<BattleResponse>(() => new BattleResponse());
+    private pb::UnknownFieldSet _unknownFields;
+    [global::System.Diagnostics.DebuggerNonUserCodeAttribute]
+    [global::System.CodeDom.Compiler.GeneratedCode("protoc", null)]
+    public static pb::MessageParser<BattleResponse> Parser { get { return _parser; } }
+
+    [global::System.Diagnostics.DebuggerNonUserCodeAttribute]
+    [global::System.CodeDom.Compiler.GeneratedCode("protoc", null)]
+    public static pbr::MessageDescriptor Descriptor {
+      get { return global::GoPureWithCsharp.Battle.BattleReflection.Descriptor.MessageTypes[15]; }
+    }
+
+    [global::System.Diagnostics.DebuggerNonUserCodeAttribute]
+    [global::System.CodeDom.Compiler.GeneratedCode("protoc", null)]
+    pbr::MessageDescriptor pb::IMessage.Descriptor {
+      get { return Descriptor; }
+    }
+
+    [global::System.Diagnostics.DebuggerNonUserCodeAttribute]
+    [global::System.CodeDom.Compiler.GeneratedCode("protoc", null)]
+    public BattleResponse() {
+      OnConstruction();
+    }
+
+    partial void OnConstruction();
+
+    [global::System.Diagnostics.DebuggerNonUserCodeAttribute]
+    [global::System.CodeDom.Compiler.GeneratedCode("protoc", null)]
+    public BattleResponse(BattleResponse other) : this() {
+      code_ = other.code_;
+      message_ = other.message_;
+      result_ = other.result_;
+      timestamp_ = other.timestamp_;
+      exception_ = other.exception_ != null ? other.exception_.Clone() : null;
+      _unknownFields = pb::UnknownFieldSet.Clone(other._unknownFields);
+    }
+
+    [global::System.Diagnostics.DebuggerNonUserCodeAttribute]
+    [global::System.CodeDom.Compiler.GeneratedCode("protoc", null)]
+    public BattleResponse Clone() {
+      return new BattleResponse(this);
+    }
+
+    /// <summary>Field number for the "code" field.</summary>
+    public const int CodeFieldNumber = 1;
+    private int code_;
+    /// <summary>
+    /// 错误码 (0=成功)
+    /// </summary>
+    [global::System.Diagnostics.DebuggerNonUserCodeAttribute]
+    [global::System.CodeDom.Compiler.GeneratedCode("protoc", null)]
+    public int Code {
+      get { return code_; }
+      set {
+        code_ = value;
+      }
+    }
+
+    /// <summary>Field number for the "message" field.</summary>
+    public const int MessageFieldNumber = 2;
+    private string message_ = "";
+    /// <summary>
+    /// 消息
+    /// </summary>
+    [global::System.Diagnostics.DebuggerNonUserCodeAttribute]
+    [global::System.CodeDom.Compiler.GeneratedCode("protoc", null)]
+    public string Message {
+      get { return message_; }
+      set {
+        message_ = pb::ProtoPreconditions.CheckNotNull(value, "value");
+      }
+    }
+
+    /// <summary>Field number for the "result" field.</summary>
+    public const int ResultFieldNumber = 3;
+    private pb::ByteString result_ = pb::ByteString.Empty;
+    /// <summary>
+    /// 结果数据(序列化的具体消息)
+    /// </summary>
+    [global::System.Diagnostics.DebuggerNonUserCodeAttribute]
+    [global::System.CodeDom.Compiler.GeneratedCode("protoc", null)]
+    public pb::ByteString Result {
+      get { return result_; }
+      set {
+        result_ = pb::ProtoPreconditions.CheckNotNull(value, "value");
+      }
+    }
+
+    /// <summary>Field number for the "timestamp" field.</summary>
+    public const int TimestampFieldNumber = 4;
+    private long timestamp_;
+    /// <summary>
+    /// 时间戳
+    /// </summary>
+    [global::System.Diagnostics.DebuggerNonUserCodeAttribute]
+    [global::System.CodeDom.Compiler.GeneratedCode("protoc", null)]
+    public long Timestamp {
+      get { return timestamp_; }
+      set {
+        timestamp_ = value;
+      }
+    }
+
+    /// <summary>Field number for the "exception" field.</summary>
+    public const int ExceptionFieldNumber = 5;
+    private global::GoPureWithCsharp.Battle.ManagedException exception_;
+    /// <summary>
+    /// 处理请求时抛出的托管异常 (没有时为空)
+    /// </summary>
+    [global::System.Diagnostics.DebuggerNonUserCodeAttribute]
+    [global::System.CodeDom.Compiler.GeneratedCode("protoc", null)]
+    public global::GoPureWithCsharp.Battle.ManagedException Exception {
+      get { return exception_; }
+      set {
+        exception_ = value;
+      }
+    }
+
+    [global::System.Diagnostics.DebuggerNonUserCodeAttribute]
+    [global::System.CodeDom.Compiler.GeneratedCode("protoc", null)]
+    public override bool Equals(object other) {
+      return Equals(other as BattleResponse);
+    }
+
+    [global::System.Diagnostics.DebuggerNonUserCodeAttribute]
+    [global::System.CodeDom.Compiler.GeneratedCode("protoc", null)]
+    public bool Equals(BattleResponse other) {
+      if (ReferenceEquals(other, null)) {
+        return false;
+      }
+      if (ReferenceEquals(other, this)) {
+        return true;
+      }
+      if (Code != other.Code) return false;
+      if (Message != other.Message) return false;
+      if (Result != other.Result) return false;
+      if (Timestamp != other.Timestamp) return false;
+      if (!object.Equals(Exception, other.Exception)) return false;
+      return Equals(_unknownFields, other._unknownFields);
+    }
+
+    [global::System.Diagnostics.DebuggerNonUserCodeAttribute]
+    [global::System.CodeDom.Compiler.GeneratedCode("protoc", null)]
+    public override int GetHashCode() {
+      int hash = 1;
+      if (Code != 0) hash ^= Code.GetHashCode();
+      if (Message.Length != 0) hash ^= Message.GetHashCode();
+      if (Result.Length != 0) hash ^= Result.GetHashCode();
+      if (Timestamp != 0L) hash ^= Timestamp.GetHashCode();
+      if (exception_ != null) hash ^= Exception.GetHashCode();
+      if (_unknownFields != null) {
+        hash ^= _unknownFields.GetHashCode();
+      }
+      return hash;
+    }
+
+    [global::System.Diagnostics.DebuggerNonUserCodeAttribute]
+    [global::System.CodeDom.Compiler.GeneratedCode("protoc", null)]
+    public override string ToString() {
+      return pb::JsonFormatter.ToDiagnosticString(this);
+    }
+
+    [global::System.Diagnostics.DebuggerNonUserCodeAttribute]
+    [global::System.CodeDom.Compiler.GeneratedCode("protoc", null)]
+    public void WriteTo(pb::CodedOutputStream output) {
+    #if !GOOGLE_PROTOBUF_REFSTRUCT_COMPATIBILITY_MODE
+      output.WriteRawMessage(this);
+    #else
+      if (Code != 0) {
+        output.WriteRawTag(8);
+        output.WriteInt32(Code);
+      }
+      if (Message.Length != 0) {
+        output.WriteRawTag(18);
+        output.WriteString(Message);
+      }
+      if (Result.Length != 0) {
+        output.WriteRawTag(26);
+        output.WriteBytes(Result);
+      }
+      if (Timestamp != 0L) {
+        output.WriteRawTag(32);
+        output.WriteInt64(Timestamp);
+      }
+      if (exception_ != null) {
+        output.WriteRawTag(42);
+        output.WriteMessage(Exception);
+      }
+      if (_unknownFields != null) {
+        _unknownFields.WriteTo(output);
+      }
+    #endif
+    }
+
+    #if !GOOGLE_PROTOBUF_REFSTRUCT_COMPATIBILITY_MODE
+    [global::System.Diagnostics.DebuggerNonUserCodeAttribute]
+    [global::System.CodeDom.Compiler.GeneratedCode("protoc", null)]
+    void pb::IBufferMessage.InternalWriteTo(ref pb::WriteContext output) {
+      if (Code != 0) {
+        output.WriteRawTag(8);
+        output.WriteInt32(Code);
+      }
+      if (Message.Length != 0) {
+        output.WriteRawTag(18);
+        output.WriteString(Message);
+      }
+      if (Result.Length != 0) {
+        output.WriteRawTag(26);
+        output.WriteBytes(Result);
+      }
+      if (Timestamp != 0L) {
+        output.WriteRawTag(32);
+        output.WriteInt64(Timestamp);
+      }
+      if (exception_ != null) {
+        output.WriteRawTag(42);
+        output.WriteMessage(Exception);
+      }
+      if (_unknownFields != null) {
+        _unknownFields.WriteTo(ref output);
+      }
+    }
+    #endif
+
+    [global::System.Diagnostics.DebuggerNonUserCodeAttribute]
+    [global::System.CodeDom.Compiler.GeneratedCode("protoc", null)]
+    public int CalculateSize() {
+      int size = 0;
+      if (Code != 0) {
+        size += 1 + pb::CodedOutputStream.ComputeInt32Size(Code);
+      }
+      if (Message.Length != 0) {
+        size += 1 + pb::CodedOutputStream.ComputeStringSize(Message);
+      }
+      if (Result.Length != 0) {
+        size += 1 + pb::CodedOutputStream.ComputeBytesSize(Result);
+      }
+      if (Timestamp != 0L) {
+        size += 1 + pb::CodedOutputStream.ComputeInt64Size(Timestamp);
+      }
+      if (exception_ != null) {
+        size += 1 + pb::CodedOutputStream.ComputeMessageSize(Exception);
+      }
+      if (_unknownFields != null) {
+        size += _unknownFields.CalculateSize();
+      }
+      return size;
+    }
+
+    [global::System.Diagnostics.DebuggerNonUserCodeAttribute]
+    [global::System.CodeDom.Compiler.GeneratedCode("protoc", null)]
+    public void MergeFrom(BattleResponse other) {
+      if (other == null) {
+        return;
+      }
+      if (other.Code != 0) {
+        Code = other.Code;
+      }
+      if (other.Message.Length != 0) {
+        Message = other.Message;
+      }
+      if (other.Result.Length != 0) {
+        Result = other.Result;
+      }
+      if (other.Timestamp != 0L) {
+        Timestamp = other.Timestamp;
+      }
+      if (other.exception_ != null) {
+        if (exception_ == null) {
+          Exception = new global::GoPureWithCsharp.Battle.ManagedException();
+        }
+        Exception.MergeFrom(other.Exception);
+      }
+      _unknownFields = pb::UnknownFieldSet.MergeFrom(_unknownFields, other._unknownFields);
+    }
+
+    [global::System.Diagnostics.DebuggerNonUserCodeAttribute]
+    [global::System.CodeDom.Compiler.GeneratedCode("protoc", null)]
+    public void MergeFrom(pb::CodedInputStream input) {
+    #if !GOOGLE_PROTOBUF_REFSTRUCT_COMPATIBILITY_MODE
+      input.ReadRawMessage(this);
+    #else
+      uint tag;
+      while ((tag = input.ReadTag()) != 0) {
+        switch(tag) {
+          default:
+            _unknownFields = pb::UnknownFieldSet.MergeFieldFrom(_unknownFields, input);
+            break;
+          case 8: {
+            Code = input.ReadInt32();
+            break;
+          }
+          case 18: {
+            Message = input.ReadString();
+            break;
+          }
+          case 26: {
+            Result = input.ReadBytes();
+            break;
+          }
+          case 32: {
+            Timestamp = input.ReadInt64();
+            break;
+          }
+          case 42: {
+            if (exception_ == null) {
+              Exception = new global::GoPureWithCsharp.Battle.ManagedException();
+            }
+            input.ReadMessage(Exception);
+            break;
+          }
+        }
+      }
+    #endif
+    }
+
+    #if !GOOGLE_PROTOBUF_REFSTRUCT_COMPATIBILITY_MODE
+    [global::System.Diagnostics.DebuggerNonUserCodeAttribute]
+    [global::System.CodeDom.Compiler.GeneratedCode("protoc", null)]
+    void pb::IBufferMessage.InternalMergeFrom(ref pb::ParseContext input) {
+      uint tag;
+      while ((tag = input.ReadTag()) != 0) {
+        switch(tag) {
+          default:
+            _unknownFields = pb::UnknownFieldSet.MergeFieldFrom(_unknownFields, ref input);
+            break;
+          case 8: {
+            Code = input.ReadInt32();
+            break;
+          }
+          case 18: {
+            Message = input.ReadString();
+            break;
+          }
+          case 26: {
+            Result = input.ReadBytes();
+            break;
+          }
+          case 32: {
+            Timestamp = input.ReadInt64();
+            break;
+          }
+          case 42: {
+            if (exception_ == null) {
+              Exception = new global::GoPureWithCsharp.Battle.ManagedException();
+            }
+            input.ReadMessage(Exception);
+            break;
+          }
+        }
+      }
+    }
+    #endif
+
+  }
+
+  /// <summary>
+  /// 批量战斗请求
+  /// </summary>
+  public sealed partial class BatchBattleRequest : pb::IMessage<BatchBattleRequest>
+  #if !GOOGLE_PROTOBUF_REFSTRUCT_COMPATIBILITY_MODE
+      , pb::IBufferMessage
+  #endif
+  {
+    private static readonly pb::MessageParser<BatchBattleRequest> _parser = new pb::MessageParser<BatchBattleRequest>(() => new BatchBattleRequest());
+    private pb::UnknownFieldSet _unknownFields;
+    [global::System.Diagnostics.DebuggerNonUserCodeAttribute]
+    [global::System.CodeDom.Compiler.GeneratedCode("protoc", null)]
+    public static pb::MessageParser<BatchBattleRequest> Parser { get { return _parser; } }
+
+    [global::System.Diagnostics.DebuggerNonUserCodeAttribute]
+    [global::System.CodeDom.Compiler.GeneratedCode("protoc", null)]
+    public static pbr::MessageDescriptor Descriptor {
+      get { return global::GoPureWithCsharp.Battle.BattleReflection.Descriptor.MessageTypes[16]; }
+    }
+
+    [global::System.Diagnostics.DebuggerNonUserCodeAttribute]
+    [global::System.CodeDom.Compiler.GeneratedCode("protoc", null)]
+    pbr::MessageDescriptor pb::IMessage.Descriptor {
+      get { return Descriptor; }
+    }
+
+    [global::System.Diagnostics.DebuggerNonUserCodeAttribute]
+    [global::System.CodeDom.Compiler.GeneratedCode("protoc", null)]
+    public BatchBattleRequest() {
+      OnConstruction();
+    }
+
+    partial void OnConstruction();
+
+    [global::System.Diagnostics.DebuggerNonUserCodeAttribute]
+    [global::System.CodeDom.Compiler.GeneratedCode("protoc", null)]
+    public BatchBattleRequest(BatchBattleRequest other) : this() {
+      battles_ = other.battles_.Clone();
+      batchId_ = other.batchId_;
+      parallel_ = other.parallel_;
+      _unknownFields = pb::UnknownFieldSet.Clone(other._unknownFields);
+    }
+
+    [global::System.Diagnostics.DebuggerNonUserCodeAttribute]
+    [global::System.CodeDom.Compiler.GeneratedCode("protoc", null)]
+    public BatchBattleRequest Clone() {
+      return new BatchBattleRequest(this);
+    }
+
+    /// <summary>Field number for the "battles" field.</summary>
+    public const int BattlesFieldNumber = 1;
+    private static readonly pb::FieldCodec<global::GoPureWithCsharp.Battle.StartBattle> _repeated_battles_codec
+        = pb::FieldCodec.ForMessage(10, global::GoPureWithCsharp.Battle.StartBattle.Parser);
+    private readonly pbc::RepeatedField<global::GoPureWithCsharp.Battle.StartBattle> battles_ = new pbc::RepeatedField<global::GoPureWithCsharp.Battle.StartBattle>();
+    /// <summary>
+    /// 战斗列表
+    /// </summary>
+    [global::System.Diagnostics.DebuggerNonUserCodeAttribute]
+    [global::System.CodeDom.Compiler.GeneratedCode("protoc", null)]
+    public pbc::RepeatedField<global::GoPureWithCsharp.Battle.StartBattle> Battles {
+      get { return battles_; }
+    }
+
+    /// <summary>Field number for the "batch_id" field.</summary>
+    public const int BatchIdFieldNumber = 2;
+    private string batchId_ = "";
+    /// <summary>
+    /// 批次ID
+    /// </summary>
+    [global::System.Diagnostics.DebuggerNonUserCodeAttribute]
+    [global::System.CodeDom.Compiler.GeneratedCode("protoc", null)]
+    public string BatchId {
+      get { return batchId_; }
+      set {
+        batchId_ = pb::ProtoPreconditions.CheckNotNull(value, "value");
+      }
+    }
+
+    /// <summary>Field number for the "parallel" field.</summary>
+    public const int ParallelFieldNumber = 3;
+    private int parallel_;
+    /// <summary>
+    /// 是否并行处理
+    /// </summary>
+    [global::System.Diagnostics.DebuggerNonUserCodeAttribute]
+    [global::System.CodeDom.Compiler.GeneratedCode("protoc", null)]
+    public int Parallel {
+      get { return parallel_; }
+      set {
+        parallel_ = value;
+      }
+    }
+
+    [global::System.Diagnostics.DebuggerNonUserCodeAttribute]
+    [global::System.CodeDom.Compiler.GeneratedCode("protoc", null)]
+    public override bool Equals(object other) {
+      return Equals(other as BatchBattleRequest);
+    }
+
+    [global::System.Diagnostics.DebuggerNonUserCodeAttribute]
+    [global::System.CodeDom.Compiler.GeneratedCode("protoc", null)]
+    public bool Equals(BatchBattleRequest other) {
+      if (ReferenceEquals(other, null)) {
+        return false;
+      }
+      if (ReferenceEquals(other, this)) {
+        return true;
+      }
+      if(!battles_.Equals(other.battles_)) return false;
+      if (BatchId != other.BatchId) return false;
+      if (Parallel != other.Parallel) return false;
+      return Equals(_unknownFields, other._unknownFields);
+    }
+
+    [global::System.Diagnostics.DebuggerNonUserCodeAttribute]
+    [global::System.CodeDom.Compiler.GeneratedCode("protoc", null)]
+    public override int GetHashCode() {
+      int hash = 1;
+      hash ^= battles_.GetHashCode();
+      if (BatchId.Length != 0) hash ^= BatchId.GetHashCode();
+      if (Parallel != 0) hash ^= Parallel.GetHashCode();
+      if (_unknownFields != null) {
+        hash ^= _unknownFields.GetHashCode();
+      }
+      return hash;
+    }
+
+    [global::System.Diagnostics.DebuggerNonUserCodeAttribute]
+    [global::System.CodeDom.Compiler.GeneratedCode("protoc", null)]
+    public override string ToString() {
+      return pb::JsonFormatter.ToDiagnosticString(this);
+    }
+
+    [global::System.Diagnostics.DebuggerNonUserCodeAttribute]
+    [global::System.CodeDom.Compiler.GeneratedCode("protoc", null)]
+    public void WriteTo(pb::CodedOutputStream output) {
+    #if !GOOGLE_PROTOBUF_REFSTRUCT_COMPATIBILITY_MODE
+      output.WriteRawMessage(this);
+    #else
+      battles_.WriteTo(output, _repeated_battles_codec);
+      if (BatchId.Length != 0) {
+        output.WriteRawTag(18);
+        output.WriteString(BatchId);
+      }
+      if (Parallel != 0) {
+        output.WriteRawTag(24);
+        output.WriteInt32(Parallel);
+      }
+      if (_unknownFields != null) {
+        _unknownFields.WriteTo(output);
+      }
+    #endif
+    }
+
+    #if !GOOGLE_PROTOBUF_REFSTRUCT_COMPATIBILITY_MODE
+    [global::System.Diagnostics.DebuggerNonUserCodeAttribute]
+    [global::System.CodeDom.Compiler.GeneratedCode("protoc", null)]
+    void pb::IBufferMessage.InternalWriteTo(ref pb::WriteContext output) {
+      battles_.WriteTo(ref output, _repeated_battles_codec);
+      if (BatchId.Length != 0) {
+        output.WriteRawTag(18);
+        output.WriteString(BatchId);
+      }
+      if (Parallel != 0) {
+        output.WriteRawTag(24);
+        output.WriteInt32(Parallel);
+      }
+      if (_unknownFields != null) {
+        _unknownFields.WriteTo(ref output);
+      }
+    }
+    #endif
+
+    [global::System.Diagnostics.DebuggerNonUserCodeAttribute]
+    [global::System.CodeDom.Compiler.GeneratedCode("protoc", null)]
+    public int CalculateSize() {
+      int size = 0;
+      size += battles_.CalculateSize(_repeated_battles_codec);
+      if (BatchId.Length != 0) {
+        size += 1 + pb::CodedOutputStream.ComputeStringSize(BatchId);
+      }
+      if (Parallel != 0) {
+        size += 1 + pb::CodedOutputStream.ComputeInt32Size(Parallel);
+      }
+      if (_unknownFields != null) {
+        size += _unknownFields.CalculateSize();
+      }
+      return size;
+    }
+
+    [global::System.Diagnostics.DebuggerNonUserCodeAttribute]
+    [global::System.CodeDom.Compiler.GeneratedCode("protoc", null)]
+    public void MergeFrom(BatchBattleRequest other) {
+      if (other == null) {
+        return;
+      }
+      battles_.Add(other.battles_);
+      if (other.BatchId.Length != 0) {
+        BatchId = other.BatchId;
+      }
+      if (other.Parallel != 0) {
+        Parallel = other.Parallel;
+      }
+      _unknownFields = pb::UnknownFieldSet.MergeFrom(_unknownFields, other._unknownFields);
+    }
+
+    [global::System.Diagnostics.DebuggerNonUserCodeAttribute]
+    [global::System.CodeDom.Compiler.GeneratedCode("protoc", null)]
+    public void MergeFrom(pb::CodedInputStream input) {
+    #if !GOOGLE_PROTOBUF_REFSTRUCT_COMPATIBILITY_MODE
+      input.ReadRawMessage(this);
+    #else
+      uint tag;
+      while ((tag = input.ReadTag()) != 0) {
+        switch(tag) {
+          default:
+            _unknownFields = pb::UnknownFieldSet.MergeFieldFrom(_unknownFields, input);
+            break;
+          case 10: {
+            battles_.AddEntriesFrom(input, _repeated_battles_codec);
+            break;
+          }
+          case 18: {
+            BatchId = input.ReadString();
+            break;
+          }
+          case 24: {
+            Parallel = input.ReadInt32();
+            break;
+          }
+        }
+      }
+    #endif
+    }
+
+    #if !GOOGLE_PROTOBUF_REFSTRUCT_COMPATIBILITY_MODE
+    [global::System.Diagnostics.DebuggerNonUserCodeAttribute]
+    [global::System.CodeDom.Compiler.GeneratedCode("protoc", null)]
+    void pb::IBufferMessage.InternalMergeFrom(ref pb::ParseContext input) {
+      uint tag;
+      while ((tag = input.ReadTag()) != 0) {
+        switch(tag) {
+          default:
+            _unknownFields = pb::UnknownFieldSet.MergeFieldFrom(_unknownFields, ref input);
+            break;
+          case 10: {
+            battles_.AddEntriesFrom(ref input, _repeated_battles_codec);
+            break;
+          }
+          case 18: {
+            BatchId = input.ReadString();
+            break;
+          }
+          case 24: {
+            Parallel = input.ReadInt32();
+            break;
+          }
+        }
+      }
+    }
+    #endif
+
+  }
+
+  /// <summary>
+  /// 批量战斗响应
+  /// </summary>
+  public sealed partial class BatchBattleResponse : pb::IMessage<BatchBattleResponse>
+  #if !GOOGLE_PROTOBUF_REFSTRUCT_COMPATIBILITY_MODE
+      , pb::IBufferMessage
+  #endif
+  {
+    private static readonly pb::MessageParser<BatchBattleResponse> _parser = new pb::MessageParser<BatchBattleResponse>(() => new BatchBattleResponse());
+    private pb::UnknownFieldSet _unknownFields;
+    [global::System.Diagnostics.DebuggerNonUserCodeAttribute]
+    [global::System.CodeDom.Compiler.GeneratedCode("protoc", null)]
+    public static pb::MessageParser<BatchBattleResponse> Parser { get { return _parser; } }
+
+    [global::System.Diagnostics.DebuggerNonUserCodeAttribute]
+    [global::System.CodeDom.Compiler.GeneratedCode("protoc", null)]
+    public static pbr::MessageDescriptor Descriptor {
+      get { return global::GoPureWithCsharp.Battle.BattleReflection.Descriptor.MessageTypes[17]; }
+    }
+
+    [global::System.Diagnostics.DebuggerNonUserCodeAttribute]
+    [global::System.CodeDom.Compiler.GeneratedCode("protoc", null)]
+    pbr::MessageDescriptor pb::IMessage.Descriptor {
+      get { return Descriptor; }
+    }
+
+    [global::System.Diagnostics.DebuggerNonUserCodeAttribute]
+    [global::System.CodeDom.Compiler.GeneratedCode("protoc", null)]
+    public BatchBattleResponse() {
+      OnConstruction();
+    }
+
+    partial void OnConstruction();
+
+    [global::System.Diagnostics.DebuggerNonUserCodeAttribute]
+    [global::System.CodeDom.Compiler.GeneratedCode("protoc", null)]
+    public BatchBattleResponse(BatchBattleResponse other) : this() {
+      results_ = other.results_.Clone();
+      batchId_ = other.batchId_;
+      successCount_ = other.successCount_;
+      failureCount_ = other.failureCount_;
+      totalDuration_ = other.totalDuration_;
+      _unknownFields = pb::UnknownFieldSet.Clone(other._unknownFields);
+    }
+
+    [global::System.Diagnostics.DebuggerNonUserCodeAttribute]
+    [global::System.CodeDom.Compiler.GeneratedCode("protoc", null)]
+    public BatchBattleResponse Clone() {
+      return new BatchBattleResponse(this);
+    }
+
+    /// <summary>Field number for the "results" field.</summary>
+    public const int ResultsFieldNumber = 1;
+    private static readonly pb::FieldCodec<global::GoPureWithCsharp.Battle.BattleResult> _repeated_results_codec
+        = pb::FieldCodec.ForMessage(10, global::GoPureWithCsharp.Battle.BattleResult.Parser);
+    private readonly pbc::RepeatedField<global::GoPureWithCsharp.Battle.BattleResult> results_ = new pbc::RepeatedField<global::GoPureWithCsharp.Battle.BattleResult>();
+    /// <summary>
+    /// 战斗结果列表
+    /// </summary>
+    [global::System.Diagnostics.DebuggerNonUserCodeAttribute]
+    [global::System.CodeDom.Compiler.GeneratedCode("protoc", null)]
+    public pbc::RepeatedField<global::GoPureWithCsharp.Battle.BattleResult> Results {
+      get { return results_; }
+    }
+
+    /// <summary>Field number for the "batch_id" field.</summary>
+    public const int BatchIdFieldNumber = 2;
+    private string batchId_ = "";
+    /// <summary>
+    /// 批次ID
+    /// </summary>
+    [global::System.Diagnostics.DebuggerNonUserCodeAttribute]
+    [global::System.CodeDom.Compiler.GeneratedCode("protoc", null)]
+    public string BatchId {
+      get { return batchId_; }
+      set {
+        batchId_ = pb::ProtoPreconditions.CheckNotNull(value, "value");
+      }
+    }
+
+    /// <summary>Field number for the "success_count" field.</summary>
+    public const int SuccessCountFieldNumber = 3;
+    private int successCount_;
+    /// <summary>
+    /// 成功数
+    /// </summary>
+    [global::System.Diagnostics.DebuggerNonUserCodeAttribute]
+    [global::System.CodeDom.Compiler.GeneratedCode("protoc", null)]
+    public int SuccessCount {
+      get { return successCount_; }
+      set {
+        successCount_ = value;
+      }
+    }
+
+    /// <summary>Field number for the "failure_count" field.</summary>
+    public const int FailureCountFieldNumber = 4;
+    private int failureCount_;
+    /// <summary>
+    /// 失败数
+    /// </summary>
+    [global::System.Diagnostics.DebuggerNonUserCodeAttribute]
+    [global::System.CodeDom.Compiler.GeneratedCode("protoc", null)]
+    public int FailureCount {
+      get { return failureCount_; }
+      set {
+        failureCount_ = value;
+      }
+    }
+
+    /// <summary>Field number for the "total_duration" field.</summary>
+    public const int TotalDurationFieldNumber = 5;
+    private long totalDuration_;
+    /// <summary>
+    /// 总耗时(毫秒)
+    /// </summary>
+    [global::System.Diagnostics.DebuggerNonUserCodeAttribute]
+    [global::System.CodeDom.Compiler.GeneratedCode("protoc", null)]
+    public long TotalDuration {
+      get { return totalDuration_; }
+      set {
+        totalDuration_ = value;
+      }
+    }
+
+    [global::System.Diagnostics.DebuggerNonUserCodeAttribute]
+    [global::System.CodeDom.Compiler.GeneratedCode("protoc", null)]
+    public override bool Equals(object other) {
+      return Equals(other as BatchBattleResponse);
+    }
+
+    [global::System.Diagnostics.DebuggerNonUserCodeAttribute]
+    [global::System.CodeDom.Compiler.GeneratedCode("protoc", null)]
+    public bool Equals(BatchBattleResponse other) {
+      if (ReferenceEquals(other, null)) {
+        return false;
+      }
+      if (ReferenceEquals(other, this)) {
+        return true;
+      }
+      if(!results_.Equals(other.results_)) return false;
+      if (BatchId != other.BatchId) return false;
+      if (SuccessCount != other.SuccessCount) return false;
+      if (FailureCount != other.FailureCount) return false;
+      if (TotalDuration != other.TotalDuration) return false;
+      return Equals(_unknownFields, other._unknownFields);
+    }
+
+    [global::System.Diagnostics.DebuggerNonUserCodeAttribute]
+    [global::System.CodeDom.Compiler.GeneratedCode("protoc", null)]
+    public override int GetHashCode() {
+      int hash = 1;
+      hash ^= results_.GetHashCode();
+      if (BatchId.Length != 0) hash ^= BatchId.GetHashCode();
+      if (SuccessCount != 0) hash ^= SuccessCount.GetHashCode();
+      if (FailureCount != 0) hash ^= FailureCount.GetHashCode();
+      if (TotalDuration != 0L) hash ^= TotalDuration.GetHashCode();
+      if (_unknownFields != null) {
+        hash ^= _unknownFields.GetHashCode();
+      }
+      return hash;
+    }
+
+    [global::System.Diagnostics.DebuggerNonUserCodeAttribute]
+    [global::System.CodeDom.Compiler.GeneratedCode("protoc", null)]
+    public override string ToString() {
+      return pb::JsonFormatter.ToDiagnosticString(this);
+    }
+
+    [global::System.Diagnostics.DebuggerNonUserCodeAttribute]
+    [global::System.CodeDom.Compiler.GeneratedCode("protoc", null)]
+    public void WriteTo(pb::CodedOutputStream output) {
+    #if !GOOGLE_PROTOBUF_REFSTRUCT_COMPATIBILITY_MODE
+      output.WriteRawMessage(this);
+    #else
+      results_.WriteTo(output, _repeated_results_codec);
+      if (BatchId.Length != 0) {
+        output.WriteRawTag(18);
+        output.WriteString(BatchId);
+      }
+      if (SuccessCount != 0) {
+        output.WriteRawTag(24);
+        output.WriteInt32(SuccessCount);
+      }
+      if (FailureCount != 0) {
+        output.WriteRawTag(32);
+        output.WriteInt32(FailureCount);
+      }
+      if (TotalDuration != 0L) {
+        output.WriteRawTag(40);
+        output.WriteInt64(TotalDuration);
+      }
+      if (_unknownFields != null) {
+        _unknownFields.WriteTo(output);
+      }
+    #endif
+    }
+
+    #if !GOOGLE_PROTOBUF_REFSTRUCT_COMPATIBILITY_MODE
+    [global::System.Diagnostics.DebuggerNonUserCodeAttribute]
+    [global::System.CodeDom.Compiler.GeneratedCode("protoc", null)]
+    void pb::IBufferMessage.InternalWriteTo(ref pb::WriteContext output) {
+      results_.WriteTo(ref output, _repeated_results_codec);
+      if (BatchId.Length != 0) {
+        output.WriteRawTag(18);
+        output.WriteString(BatchId);
+      }
+      if (SuccessCount != 0) {
+        output.WriteRawTag(24);
+        output.WriteInt32(SuccessCount);
+      }
+      if (FailureCount != 0) {
+        output.WriteRawTag(32);
+        output.WriteInt32(FailureCount);
+      }
+      if (TotalDuration != 0L) {
+        output.WriteRawTag(40);
+        output.WriteInt64(TotalDuration);
+      }
+      if (_unknownFields != null) {
+        _unknownFields.WriteTo(ref output);
+      }
+    }
+    #endif
+
+    [global::System.Diagnostics.DebuggerNonUserCodeAttribute]
+    [global::System.CodeDom.Compiler.GeneratedCode("protoc", null)]
+    public int CalculateSize() {
+      int size = 0;
+      size += results_.CalculateSize(_repeated_results_codec);
+      if (BatchId.Length != 0) {
+        size += 1 + pb::CodedOutputStream.ComputeStringSize(BatchId);
+      }
+      if (SuccessCount != 0) {
+        size += 1 + pb::CodedOutputStream.ComputeInt32Size(SuccessCount);
+      }
+      if (FailureCount != 0) {
+        size += 1 + pb::CodedOutputStream.ComputeInt32Size(FailureCount);
+      }
+      if (TotalDuration != 0L) {
+        size += 1 + pb::CodedOutputStream.ComputeInt64Size(TotalDuration);
+      }
+      if (_unknownFields != null) {
+        size += _unknownFields.CalculateSize();
+      }
+      return size;
+    }
+
+    [global::System.Diagnostics.DebuggerNonUserCodeAttribute]
+    [global::System.CodeDom.Compiler.GeneratedCode("protoc", null)]
+    public void MergeFrom(BatchBattleResponse other) {
+      if (other == null) {
+        return;
+      }
+      results_.Add(other.results_);
+      if (other.BatchId.Length != 0) {
+        BatchId = other.BatchId;
+      }
+      if (other.SuccessCount != 0) {
+        SuccessCount = other.SuccessCount;
+      }
+      if (other.FailureCount != 0) {
+        FailureCount = other.FailureCount;
+      }
+      if (other.TotalDuration != 0L) {
+        TotalDuration = other.TotalDuration;
+      }
+      _unknownFields = pb::UnknownFieldSet.MergeFrom(_unknownFields, other._unknownFields);
+    }
+
+    [global::System.Diagnostics.DebuggerNonUserCodeAttribute]
+    [global::System.CodeDom.Compiler.GeneratedCode("protoc", null)]
+    public void MergeFrom(pb::CodedInputStream input) {
+    #if !GOOGLE_PROTOBUF_REFSTRUCT_COMPATIBILITY_MODE
+      input.ReadRawMessage(this);
+    #else
+      uint tag;
+      while ((tag = input.ReadTag()) != 0) {
+        switch(tag) {
+          default:
+            _unknownFields = pb::UnknownFieldSet.MergeFieldFrom(_unknownFields, input);
+            break;
+          case 10: {
+            results_.AddEntriesFrom(input, _repeated_results_codec);
+            break;
+          }
+          case 18: {
+            BatchId = input.ReadString();
+            break;
+          }
+          case 24: {
+            SuccessCount = input.ReadInt32();
+            break;
+          }
+          case 32: {
+            FailureCount = input.ReadInt32();
+            break;
+          }
+          case 40: {
+            TotalDuration = input.ReadInt64();
+            break;
+          }
+        }
+      }
+    #endif
+    }
+
+    #if !GOOGLE_PROTOBUF_REFSTRUCT_COMPATIBILITY_MODE
+    [global::System.Diagnostics.DebuggerNonUserCodeAttribute]
+    [global::System.CodeDom.Compiler.GeneratedCode("protoc", null)]
+    void pb::IBufferMessage.InternalMergeFrom(ref pb::ParseContext input) {
+      uint tag;
+      while ((tag = input.ReadTag()) != 0) {
+        switch(tag) {
+          default:
+            _unknownFields = pb::UnknownFieldSet.MergeFieldFrom(_unknownFields, ref input);
+            break;
+          case 10: {
+            results_.AddEntriesFrom(ref input, _repeated_results_codec);
+            break;
+          }
+          case 18: {
+            BatchId = input.ReadString();
+            break;
+          }
+          case 24: {
+            SuccessCount = input.ReadInt32();
+            break;
+          }
+          case 32: {
+            FailureCount = input.ReadInt32();
+            break;
+          }
+          case 40: {
+            TotalDuration = input.ReadInt64();
+            break;
+          }
+        }
+      }
+    }
+    #endif
+
+  }
+
+  /// <summary>
+  /// 战斗事件 (用于回放)
+  /// tick 驱动的战斗中 timestamp 为事件发生的逻辑帧，extra["round"] 为回合数
+  /// "attack" 的 performer_id/target_id 为单位ID，击败目标时 extra["killed"] = "1"；"end" 的为胜方/败方队伍ID
+  /// 道具 ("item") 和用户操作 ("skill") 每修改一个属性记录一个事件: performer_id/target_id 为使用者单位，
+  /// value 为属性变化量，extra 中有 attribute_id 以及 item_id + quantity 或 operation
+  /// </summary>
+  public sealed partial class BattleEvent : pb::IMessage<BattleEvent>
+  #if !GOOGLE_PROTOBUF_REFSTRUCT_COMPATIBILITY_MODE
+      , pb::IBufferMessage
+  #endif
+  {
+    private static readonly pb::MessageParser<BattleEvent> _parser = new pb::MessageParser<BattleEvent>(() => new BattleEvent());
+    private pb::UnknownFieldSet _unknownFields;
+    [global::System.Diagnostics.DebuggerNonUserCodeAttribute]
+    [global::System.CodeDom.Compiler.GeneratedCode("protoc", null)]
+    public static pb::MessageParser<BattleEvent> Parser { get { return _parser; } }
+
+    [global::System.Diagnostics.DebuggerNonUserCodeAttribute]
+    [global::System.CodeDom.Compiler.GeneratedCode("protoc", null)]
+    public static pbr::MessageDescriptor Descriptor {
+      get { return global::GoPureWithCsharp.Battle.BattleReflection.Descriptor.MessageTypes[18]; }
+    }
+
+    [global::System.Diagnostics.DebuggerNonUserCodeAttribute]
+    [global::System.CodeDom.Compiler.GeneratedCode("protoc", null)]
+    pbr::MessageDescriptor pb::IMessage.Descriptor {
+      get { return Descriptor; }
+    }
+
+    [global::System.Diagnostics.DebuggerNonUserCodeAttribute]
+    [global::System.CodeDom.Compiler.GeneratedCode("protoc", null)]
+    public BattleEvent() {
+      OnConstruction();
+    }
+
+    partial void OnConstruction();
+
+    [global::System.Diagnostics.DebuggerNonUserCodeAttribute]
+    [global::System.CodeDom.Compiler.GeneratedCode("protoc", null)]
+    public BattleEvent(BattleEvent other) : this() {
+      timestamp_ = other.timestamp_;
+      eventType_ = other.eventType_;
+      performerId_ = other.performerId_;
+      targetId_ = other.targetId_;
+      value_ = other.value_;
+      extra_ = other.extra_.Clone();
+      _unknownFields = pb::UnknownFieldSet.Clone(other._unknownFields);
+    }
+
+    [global::System.Diagnostics.DebuggerNonUserCodeAttribute]
+    [global::System.CodeDom.Compiler.GeneratedCode("protoc", null)]
+    public BattleEvent Clone() {
+      return new BattleEvent(this);
+    }
+
+    /// <summary>Field number for the "timestamp" field.</summary>
+    public const int TimestampFieldNumber = 1;
+    private long timestamp_;
+    /// <summary>
+    /// 事件时间戳 (毫秒)
+    /// </summary>
+    [global::System.Diagnostics.DebuggerNonUserCodeAttribute]
+    [global::System.CodeDom.Compiler.GeneratedCode("protoc", null)]
+    public long Timestamp {
+      get { return timestamp_; }
+      set {
+        timestamp_ = value;
+      }
+    }
+
+    /// <summary>Field number for the "event_type" field.</summary>
+    public const int EventTypeFieldNumber = 2;
+    private string eventType_ = "";
+    /// <summary>
+    /// 事件类型: "attack"|"skill"|"item"|"heal"|"status"|"end"
+    /// </summary>
+    [global::System.Diagnostics.DebuggerNonUserCodeAttribute]
+    [global::System.CodeDom.Compiler.GeneratedCode("protoc", null)]
+    public string EventType {
+      get { return eventType_; }
+      set {
+        eventType_ = pb::ProtoPreconditions.CheckNotNull(value, "value");
+      }
+    }
+
+    /// <summary>Field number for the "performer_id" field.</summary>
+    public const int PerformerIdFieldNumber = 3;
+    private uint performerId_;
+    /// <summary>
+    /// 执行者ID (角色/队伍)
+    /// </summary>
+    [global::System.Diagnostics.DebuggerNonUserCodeAttribute]
+    [global::System.CodeDom.Compiler.GeneratedCode("protoc", null)]
+    public uint PerformerId {
+      get { return performerId_; }
+      set {
+        performerId_ = value;
+      }
+    }
+
+    /// <summary>Field number for the "target_id" field.</summary>
+    public const int TargetIdFieldNumber = 4;
+    private uint targetId_;
+    /// <summary>
+    /// 目标ID
+    /// </summary>
+    [global::System.Diagnostics.DebuggerNonUserCodeAttribute]
+    [global::System.CodeDom.Compiler.GeneratedCode("protoc", null)]
+    public uint TargetId {
+      get { return targetId_; }
+      set {
+        targetId_ = value;
+      }
+    }
+
+    /// <summary>Field number for the "value" field.</summary>
+    public const int ValueFieldNumber = 5;
+    private int value_;
+    /// <summary>
+    /// 事件值 (伤害/治疗/数值)
+    /// </summary>
+    [global::System.Diagnostics.DebuggerNonUserCodeAttribute]
+    [global::System.CodeDom.Compiler.GeneratedCode("protoc", null)]
+    public int Value {
+      get { return value_; }
+      set {
+        value_ = value;
+      }
+    }
+
+    /// <summary>Field number for the "extra" field.</summary>
+    public const int ExtraFieldNumber = 6;
+    private static readonly pbc::MapField<string, string>.Codec _map_extra_codec
+        = new pbc::MapField<string, string>.Codec(pb::FieldCodec.ForString(10, ""), pb::FieldCodec.ForString(18, ""), 50);
+    private readonly pbc::MapField<string, string> extra_ = new pbc::MapField<string, string>();
+    /// <summary>
+    /// 额外数据 (技能ID、道具ID等)
+    /// </summary>
+    [global::System.Diagnostics.DebuggerNonUserCodeAttribute]
+    [global::System.CodeDom.Compiler.GeneratedCode("protoc", null)]
+    public pbc::MapField<string, string> Extra {
+      get { return extra_; }
+    }
+
+    [global::System.Diagnostics.DebuggerNonUserCodeAttribute]
+    [global::System.CodeDom.Compiler.GeneratedCode("protoc", null)]
+    public override bool Equals(object other) {
+      return Equals(other as BattleEvent);
+    }
+
+    [global::System.Diagnostics.DebuggerNonUserCodeAttribute]
+    [global::System.CodeDom.Compiler.GeneratedCode("protoc", null)]
+    public bool Equals(BattleEvent other) {
+      if (ReferenceEquals(other, null)) {
+        return false;
+      }
+      if (ReferenceEquals(other, this)) {
+        return true;
+      }
+      if (Timestamp != other.Timestamp) return false;
+      if (EventType != other.EventType) return false;
+      if (PerformerId != other.PerformerId) return false;
+      if (TargetId != other.TargetId) return false;
+      if (Value != other.Value) return false;
+      if (!Extra.Equals(other.Extra)) return false;
+      return Equals(_unknownFields, other._unknownFields);
+    }
+
+    [global::System.Diagnostics.DebuggerNonUserCodeAttribute]
+    [global::System.CodeDom.Compiler.GeneratedCode("protoc", null)]
+    public override int GetHashCode() {
+      int hash = 1;
+      if (Timestamp != 0L) hash ^= Timestamp.GetHashCode();
+      if (EventType.Length != 0) hash ^= EventType.GetHashCode();
+      if (PerformerId != 0) hash ^= PerformerId.GetHashCode();
+      if (TargetId != 0) hash ^= TargetId.GetHashCode();
+      if (Value != 0) hash ^= Value.GetHashCode();
+      hash ^= Extra.GetHashCode();
+      if (_unknownFields != null) {
+        hash ^= _unknownFields.GetHashCode();
+      }
+      return hash;
+    }
+
+    [global::System.Diagnostics.DebuggerNonUserCodeAttribute]
+    [global::System.CodeDom.Compiler.GeneratedCode("protoc", null)]
+    public override string ToString() {
+      return pb::JsonFormatter.ToDiagnosticString(this);
+    }
+
+    [global::System.Diagnostics.DebuggerNonUserCodeAttribute]
+    [global::System.CodeDom.Compiler.GeneratedCode("protoc", null)]
+    public void WriteTo(pb::CodedOutputStream output) {
+    #if !GOOGLE_PROTOBUF_REFSTRUCT_COMPATIBILITY_MODE
+      output.WriteRawMessage(this);
+    #else
+      if (Timestamp != 0L) {
+        output.WriteRawTag(8);
+        output.WriteInt64(Timestamp);
+      }
+      if (EventType.Length != 0) {
+        output.WriteRawTag(18);
+        output.WriteString(EventType);
+      }
+      if (PerformerId != 0) {
+        output.WriteRawTag(24);
+        output.WriteUInt32(PerformerId);
+      }
+      if (TargetId != 0) {
+        output.WriteRawTag(32);
+        output.WriteUInt32(TargetId);
+      }
+      if (Value != 0) {
+        output.WriteRawTag(40);
+        output.WriteInt32(Value);
+      }
+      extra_.WriteTo(output, _map_extra_codec);
+      if (_unknownFields != null) {
+        _unknownFields.WriteTo(output);
+      }
+    #endif
+    }
+
+    #if !GOOGLE_PROTOBUF_REFSTRUCT_COMPATIBILITY_MODE
+    [global::System.Diagnostics.DebuggerNonUserCodeAttribute]
+    [global::System.CodeDom.Compiler.GeneratedCode("protoc", null)]
+    void pb::IBufferMessage.InternalWriteTo(ref pb::WriteContext output) {
+      if (Timestamp != 0L) {
+        output.WriteRawTag(8);
+        output.WriteInt64(Timestamp);
+      }
+      if (EventType.Length != 0) {
+        output.WriteRawTag(18);
+        output.WriteString(EventType);
+      }
+      if (PerformerId != 0) {
+        output.WriteRawTag(24);
+        output.WriteUInt32(PerformerId);
+      }
+      if (TargetId != 0) {
+        output.WriteRawTag(32);
+        output.WriteUInt32(TargetId);
+      }
+      if (Value != 0) {
+        output.WriteRawTag(40);
+        output.WriteInt32(Value);
+      }
+      extra_.WriteTo(ref output, _map_extra_codec);
+      if (_unknownFields != null) {
+        _unknownFields.WriteTo(ref output);
+      }
+    }
+    #endif
+
+    [global::System.Diagnostics.DebuggerNonUserCodeAttribute]
+    [global::System.CodeDom.Compiler.GeneratedCode("protoc", null)]
+    public int CalculateSize() {
+      int size = 0;
+      if (Timestamp != 0L) {
+        size += 1 + pb::CodedOutputStream.ComputeInt64Size(Timestamp);
+      }
+      if (EventType.Length != 0) {
+        size += 1 + pb::CodedOutputStream.ComputeStringSize(EventType);
+      }
+      if (PerformerId != 0) {
+        size += 1 + pb::CodedOutputStream.ComputeUInt32Size(PerformerId);
+      }
+      if (TargetId != 0) {
+        size += 1 + pb::CodedOutputStream.ComputeUInt32Size(TargetId);
+      }
+      if (Value != 0) {
+        size += 1 + pb::CodedOutputStream.ComputeInt32Size(Value);
+      }
+      size += extra_.CalculateSize(_map_extra_codec);
+      if (_unknownFields != null) {
+        size += _unknownFields.CalculateSize();
+      }
+      return size;
+    }
+
+    [global::System.Diagnostics.DebuggerNonUserCodeAttribute]
+    [global::System.CodeDom.Compiler.GeneratedCode("protoc", null)]
+    public void MergeFrom(BattleEvent other) {
+      if (other == null) {
+        return;
+      }
+      if (other.Timestamp != 0L) {
+        Timestamp = other.Timestamp;
+      }
+      if (other.EventType.Length != 0) {
+        EventType = other.EventType;
+      }
+      if (other.PerformerId != 0) {
+        PerformerId = other.PerformerId;
+      }
+      if (other.TargetId != 0) {
+        TargetId = other.TargetId;
+      }
+      if (other.Value != 0) {
+        Value = other.Value;
+      }
+      extra_.Add(other.extra_);
+      _unknownFields = pb::UnknownFieldSet.MergeFrom(_unknownFields, other._unknownFields);
+    }
+
+    [global::System.Diagnostics.DebuggerNonUserCodeAttribute]
+    [global::System.CodeDom.Compiler.GeneratedCode("protoc", null)]
+    public void MergeFrom(pb::CodedInputStream input) {
+    #if !GOOGLE_PROTOBUF_REFSTRUCT_COMPATIBILITY_MODE
+      input.ReadRawMessage(this);
+    #else
+      uint tag;
+      while ((tag = input.ReadTag()) != 0) {
+        switch(tag) {
+          default:
+            _unknownFields = pb::UnknownFieldSet.MergeFieldFrom(_unknownFields, input);
+            break;
+          case 8: {
+            Timestamp = input.ReadInt64();
+            break;
+          }
+          case 18: {
+            EventType = input.ReadString();
+            break;
+          }
+          case 24: {
+            PerformerId = input.ReadUInt32();
+            break;
+          }
+          case 32: {
+            TargetId = input.ReadUInt32();
+            break;
+          }
+          case 40: {
+            Value = input.ReadInt32();
+            break;
+          }
+          case 50: {
+            extra_.AddEntriesFrom(input, _map_extra_codec);
+            break;
+          }
+        }
+      }
+    #endif
+    }
+
+    #if !GOOGLE_PROTOBUF_REFSTRUCT_COMPATIBILITY_MODE
+    [global::System.Diagnostics.DebuggerNonUserCodeAttribute]
+    [global::System.CodeDom.Compiler.GeneratedCode("protoc", null)]
+    void pb::IBufferMessage.InternalMergeFrom(ref pb::ParseContext input) {
+      uint tag;
+      while ((tag = input.ReadTag()) != 0) {
+        switch(tag) {
+          default:
+            _unknownFields = pb::UnknownFieldSet.MergeFieldFrom(_unknownFields, ref input);
+            break;
+          case 8: {
+            Timestamp = input.ReadInt64();
+            break;
+          }
+          case 18: {
+            EventType = input.ReadString();
+            break;
+          }
+          case 24: {
+            PerformerId = input.ReadUInt32();
+            break;
+          }
+          case 32: {
+            TargetId = input.ReadUInt32();
+            break;
+          }
+          case 40: {
+            Value = input.ReadInt32();
+            break;
+          }
+          case 50: {
+            extra_.AddEntriesFrom(ref input, _map_extra_codec);
+            break;
+          }
+        }
+      }
+    }
+    #endif
+
+  }
+
+  /// <summary>
+  /// 战斗回放
+  /// tick 驱动的战斗中 start_time / end_time 为第一回合和最后一回合的逻辑帧
+  /// </summary>
+  public sealed partial class BattleReplay : pb::IMessage<BattleReplay>
+  #if !GOOGLE_PROTOBUF_REFSTRUCT_COMPATIBILITY_MODE
+      , pb::IBufferMessage
+  #endif
+  {
+    private static readonly pb::MessageParser<BattleReplay> _parser = new pb::MessageParser<BattleReplay>(() => new BattleReplay());
+    private pb::UnknownFieldSet _unknownFields;
+    [global::System.Diagnostics.DebuggerNonUserCodeAttribute]
+    [global::System.CodeDom.Compiler.GeneratedCode("protoc", null)]
+    public static pb::MessageParser<BattleReplay> Parser { get { return _parser; } }
+
+    [global::System.Diagnostics.DebuggerNonUserCodeAttribute]
+    [global::System.CodeDom.Compiler.GeneratedCode("protoc", null)]
+    public static pbr::MessageDescriptor Descriptor {
+      get { return global::GoPureWithCsharp.Battle.BattleReflection.Descriptor.MessageTypes[19]; }
+    }
+
+    [global::System.Diagnostics.DebuggerNonUserCodeAttribute]
+    [global::System.CodeDom.Compiler.GeneratedCode("protoc", null)]
+    pbr::MessageDescriptor pb::IMessage.Descriptor {
+      get { return Descriptor; }
+    }
+
+    [global::System.Diagnostics.DebuggerNonUserCodeAttribute]
+    [global::System.CodeDom.Compiler.GeneratedCode("protoc", null)]
+    public BattleReplay() {
+      OnConstruction();
+    }
+
+    partial void OnConstruction();
+
+    [global::System.Diagnostics.DebuggerNonUserCodeAttribute]
+    [global::System.CodeDom.Compiler.GeneratedCode("protoc", null)]
+    public BattleReplay(BattleReplay other) : this() {
+      battleId_ = other.battleId_;
+      startTime_ = other.startTime_;
+      endTime_ = other.endTime_;
+      atkTeam_ = other.atkTeam_ != null ? other.atkTeam_.Clone() : null;
+      defTeam_ = other.defTeam_ != null ? other.defTeam_.Clone() : null;
+      events_ = other.events_.Clone();
+      result_ = other.result_ != null ? other.result_.Clone() : null;
+      version_ = other.version_;
+      _unknownFields = pb::UnknownFieldSet.Clone(other._unknownFields);
+    }
+
+    [global::System.Diagnostics.DebuggerNonUserCodeAttribute]
+    [global::System.CodeDom.Compiler.GeneratedCode("protoc", null)]
+    public BattleReplay Clone() {
+      return new BattleReplay(this);
+    }
+
+    /// <summary>Field number for the "battle_id" field.</summary>
+    public const int BattleIdFieldNumber = 1;
+    private uint battleId_;
+    /// <summary>
+    /// 战斗ID
+    /// </summary>
+    [global::System.Diagnostics.DebuggerNonUserCodeAttribute]
+    [global::System.CodeDom.Compiler.GeneratedCode("protoc", null)]
+    public uint BattleId {
+      get { return battleId_; }
+      set {
+        battleId_ = value;
+      }
+    }
+
+    /// <summary>Field number for the "start_time" field.</summary>
+    public const int StartTimeFieldNumber = 2;
+    private long startTime_;
+    /// <summary>
+    /// 战斗开始时间戳
+    /// </summary>
+    [global::System.Diagnostics.DebuggerNonUserCodeAttribute]
+    [global::System.CodeDom.Compiler.GeneratedCode("protoc", null)]
+    public long StartTime {
+      get { return startTime_; }
+      set {
+        startTime_ = value;
+      }
+    }
+
+    /// <summary>Field number for the "end_time" field.</summary>
+    public const int EndTimeFieldNumber = 3;
+    private long endTime_;
+    /// <summary>
+    /// 战斗结束时间戳
+    /// </summary>
+    [global::System.Diagnostics.DebuggerNonUserCodeAttribute]
+    [global::System.CodeDom.Compiler.GeneratedCode("protoc", null)]
+    public long EndTime {
+      get { return endTime_; }
+      set {
+        endTime_ = value;
+      }
+    }
+
+    /// <summary>Field number for the "atk_team" field.</summary>
+    public const int AtkTeamFieldNumber = 4;
+    private global::GoPureWithCsharp.Battle.Team atkTeam_;
+    /// <summary>
+    /// 攻击方队伍
+    /// </summary>
+    [global::System.Diagnostics.DebuggerNonUserCodeAttribute]
+    [global::System.CodeDom.Compiler.GeneratedCode("protoc", null)]
+    public global::GoPureWithCsharp.Battle.Team AtkTeam {
+      get { return atkTeam_; }
+      set {
+        atkTeam_ = value;
+      }
+    }
+
+    /// <summary>Field number for the "def_team" field.</summary>
+    public const int DefTeamFieldNumber = 5;
+    private global::GoPureWithCsharp.Battle.Team defTeam_;
+    /// <summary>
+    /// 防守方队伍
+    /// </summary>
+    [global::System.Diagnostics.DebuggerNonUserCodeAttribute]
+    [global::System.CodeDom.Compiler.GeneratedCode("protoc", null)]
+    public global::GoPureWithCsharp.Battle.Team DefTeam {
+      get { return defTeam_; }
+      set {
+        defTeam_ = value;
+      }
+    }
+
+    /// <summary>Field number for the "events" field.</summary>
+    public const int EventsFieldNumber = 6;
+    private static readonly pb::FieldCodec<global::GoPureWithCsharp.Battle.BattleEvent> _repeated_events_codec
+        = pb::FieldCodec.ForMessage(50, global::GoPureWithCsharp.Battle.BattleEvent.Parser);
+    private readonly pbc::RepeatedField<global::GoPureWithCsharp.Battle.BattleEvent> events_ = new pbc::RepeatedField<global::GoPureWithCsharp.Battle.BattleEvent>();
+    /// <summary>
+    /// 事件时间线 (按时间戳排序)
+    /// </summary>
+    [global::System.Diagnostics.DebuggerNonUserCodeAttribute]
+    [global::System.CodeDom.Compiler.GeneratedCode("protoc", null)]
+    public pbc::RepeatedField<global::GoPureWithCsharp.Battle.BattleEvent> Events {
+      get { return events_; }
+    }
+
+    /// <summary>Field number for the "result" field.</summary>
+    public const int ResultFieldNumber = 7;
+    private global::GoPureWithCsharp.Battle.BattleResult result_;
+    /// <summary>
+    /// 最终战斗结果
+    /// </summary>
+    [global::System.Diagnostics.DebuggerNonUserCodeAttribute]
+    [global::System.CodeDom.Compiler.GeneratedCode("protoc", null)]
+    public global::GoPureWithCsharp.Battle.BattleResult Result {
+      get { return result_; }
+      set {
+        result_ = value;
+      }
+    }
+
+    /// <summary>Field number for the "version" field.</summary>
+    public const int VersionFieldNumber = 8;
+    private string version_ = "";
+    /// <summary>
+    /// 回放格式版本 (例如 "1.0")
+    /// </summary>
+    [global::System.Diagnostics.DebuggerNonUserCodeAttribute]
+    [global::System.CodeDom.Compiler.GeneratedCode("protoc", null)]
+    public string Version {
+      get { return version_; }
+      set {
+        version_ = pb::ProtoPreconditions.CheckNotNull(value, "value");
+      }
+    }
+
+    [global::System.Diagnostics.DebuggerNonUserCodeAttribute]
+    [global::System.CodeDom.Compiler.GeneratedCode("protoc", null)]
+    public override bool Equals(object other) {
+      return Equals(other as BattleReplay);
+    }
+
+    [global::System.Diagnostics.DebuggerNonUserCodeAttribute]
+    [global::System.CodeDom.Compiler.GeneratedCode("protoc", null)]
+    public bool Equals(BattleReplay other) {
+      if (ReferenceEquals(other, null)) {
+        return false;
+      }
+      if (ReferenceEquals(other, this)) {
+        return true;
+      }
+      if (BattleId != other.BattleId) return false;
+      if (StartTime != other.StartTime) return false;
+      if (EndTime != other.EndTime) return false;
+      if (!object.Equals(AtkTeam, other.AtkTeam)) return false;
+      if (!object.Equals(DefTeam, other.DefTeam)) return false;
+      if(!events_.Equals(other.events_)) return false;
+      if (!object.Equals(Result, other.Result)) return false;
+      if (Version != other.Version) return false;
+      return Equals(_unknownFields, other._unknownFields);
+    }
+
+    [global::System.Diagnostics.DebuggerNonUserCodeAttribute]
+    [global::System.CodeDom.Compiler.GeneratedCode("protoc", null)]
+    public override int GetHashCode() {
+      int hash = 1;
+      if (BattleId != 0) hash ^= BattleId.GetHashCode();
+      if (StartTime != 0L) hash ^= StartTime.GetHashCode();
+      if (EndTime != 0L) hash ^= EndTime.GetHashCode();
+      if (atkTeam_ != null) hash ^= AtkTeam.GetHashCode();
+      if (defTeam_ != null) hash ^= DefTeam.GetHashCode();
+      hash ^= events_.GetHashCode();
+      if (result_ != null) hash ^= Result.GetHashCode();
+      if (Version.Length != 0) hash ^= Version.GetHashCode();
+      if (_unknownFields != null) {
+        hash ^= _unknownFields.GetHashCode();
+      }
+      return hash;
+    }
+
+    [global::System.Diagnostics.DebuggerNonUserCodeAttribute]
+    [global::System.CodeDom.Compiler.GeneratedCode("protoc", null)]
+    public override string ToString() {
+      return pb::JsonFormatter.ToDiagnosticString(this);
+    }
+
+    [global::System.Diagnostics.DebuggerNonUserCodeAttribute]
+    [global::System.CodeDom.Compiler.GeneratedCode("protoc", null)]
+    public void WriteTo(pb::CodedOutputStream output) {
+    #if !GOOGLE_PROTOBUF_REFSTRUCT_COMPATIBILITY_MODE
+      output.WriteRawMessage(this);
+    #else
+      if (BattleId != 0) {
+        output.WriteRawTag(8);
+        output.WriteUInt32(BattleId);
+      }
+      if (StartTime != 0L) {
+        output.WriteRawTag(16);
+        output.WriteInt64(StartTime);
+      }
+      if (EndTime != 0L) {
+        output.WriteRawTag(24);
+        output.WriteInt64(EndTime);
+      }
+      if (atkTeam_ != null) {
+        output.WriteRawTag(34);
+        output.WriteMessage(AtkTeam);
+      }
+      if (defTeam_ != null) {
+        output.WriteRawTag(42);
+        output.WriteMessage(DefTeam);
+      }
+      events_.WriteTo(output, _repeated_events_codec);
+      if (result_ != null) {
+        output.WriteRawTag(58);
+        output.WriteMessage(Result);
+      }
+      if (Version.Length != 0) {
+        output.WriteRawTag(66);
+        output.WriteString(Version);
+      }
+      if (_unknownFields != null) {
+        _unknownFields.WriteTo(output);
+      }
+    #endif
+    }
+
+    #if !GOOGLE_PROTOBUF_REFSTRUCT_COMPATIBILITY_MODE
+    [global::System.Diagnostics.DebuggerNonUserCodeAttribute]
+    [global::System.CodeDom.Compiler.GeneratedCode("protoc", null)]
+    void pb::IBufferMessage.InternalWriteTo(ref pb::WriteContext output) {
+      if (BattleId != 0) {
+        output.WriteRawTag(8);
+        output.WriteUInt32(BattleId);
+      }
+      if (StartTime != 0L) {
+        output.WriteRawTag(16);
+        output.WriteInt64(StartTime);
+      }
+      if (EndTime != 0L) {
+        output.WriteRawTag(24);
+        output.WriteInt64(EndTime);
+      }
+      if (atkTeam_ != null) {
+        output.WriteRawTag(34);
+        output.WriteMessage(AtkTeam);
+      }
+      if (defTeam_ != null) {
+        output.WriteRawTag(42);
+        output.WriteMessage(DefTeam);
+      }
+      events_.WriteTo(ref output, _repeated_events_codec);
+      if (result_ != null) {
+        output.WriteRawTag(58);
+        output.WriteMessage(Result);
+      }
+      if (Version.Length != 0) {
+        output.WriteRawTag(66);
+        output.WriteString(Version);
+      }
+      if (_unknownFields != null) {
+        _unknownFields.WriteTo(ref output);
+      }
+    }
+    #endif
+
+    [global::System.Diagnostics.DebuggerNonUserCodeAttribute]
+    [global::System.CodeDom.Compiler.GeneratedCode("protoc", null)]
+    public int CalculateSize() {
+      int size = 0;
+      if (BattleId != 0) {
+        size += 1 + pb::CodedOutputStream.ComputeUInt32Size(BattleId);
+      }
+      if (StartTime != 0L) {
+        size += 1 + pb::CodedOutputStream.ComputeInt64Size(StartTime);
+      }
+      if (EndTime != 0L) {
+        size += 1 + pb::CodedOutputStream.ComputeInt64Size(EndTime);
+      }
+      if (atkTeam_ != null) {
+        size += 1 + pb::CodedOutputStream.ComputeMessageSize(AtkTeam);
+      }
+      if (defTeam_ != null) {
+        size += 1 + pb::CodedOutputStream.ComputeMessageSize(DefTeam);
+      }
+      size += events_.CalculateSize(_repeated_events_codec);
+      if (result_ != null) {
+        size += 1 + pb::CodedOutputStream.ComputeMessageSize(Result);
+      }
+      if (Version.Length != 0) {
+        size += 1 + pb::CodedOutputStream.ComputeStringSize(Version);
+      }
+      if (_unknownFields != null) {
+        size += _unknownFields.CalculateSize();
+      }
+      return size;
+    }
+
+    [global::System.Diagnostics.DebuggerNonUserCodeAttribute]
+    [global::System.CodeDom.Compiler.GeneratedCode("protoc", null)]
+    public void MergeFrom(BattleReplay other) {
+      if (other == null) {
+        return;
+      }
+      if (other.BattleId != 0) {
+        BattleId = other.BattleId;
+      }
+      if (other.StartTime != 0L) {
+        StartTime = other.StartTime;
+      }
+      if (other.EndTime != 0L) {
+        EndTime = other.EndTime;
+      }
+      if (other.atkTeam_ != null) {
+        if (atkTeam_ == null) {
+          AtkTeam = new global::GoPureWithCsharp.Battle.Team();
+        }
+        AtkTeam.MergeFrom(other.AtkTeam);
+      }
+      if (other.defTeam_ != null) {
+        if (defTeam_ == null) {
+          DefTeam = new global::GoPureWithCsharp.Battle.Team();
+        }
+        DefTeam.MergeFrom(other.DefTeam);
+      }
+      events_.Add(other.events_);
+      if (other.result_ != null) {
+        if (result_ == null) {
+          Result = new global::GoPureWithCsharp.Battle.BattleResult();
+        }
+        Result.MergeFrom(other.Result);
+      }
+      if (other.Version.Length != 0) {
+        Version = other.Version;
+      }
+      _unknownFields = pb::UnknownFieldSet.MergeFrom(_unknownFields, other._unknownFields);
+    }
+
+    [global::System.Diagnostics.DebuggerNonUserCodeAttribute]
+    [global::System.CodeDom.Compiler.GeneratedCode("protoc", null)]
+    public void MergeFrom(pb::CodedInputStream input) {
+    #if !GOOGLE_PROTOBUF_REFSTRUCT_COMPATIBILITY_MODE
+      input.ReadRawMessage(this);
+    #else
+      uint tag;
+      while ((tag = input.ReadTag()) != 0) {
+        switch(tag) {
+          default:
+            _unknownFields = pb::UnknownFieldSet.MergeFieldFrom(_unknownFields, input);
+            break;
+          case 8: {
+            BattleId = input.ReadUInt32();
+            break;
+          }
+          case 16: {
+            StartTime = input.ReadInt64();
+            break;
+          }
+          case 24: {
+            EndTime = input.ReadInt64();
+            break;
+          }
+          case 34: {
+            if (atkTeam_ == null) {
+              AtkTeam = new global::GoPureWithCsharp.Battle.Team();
+            }
+            input.ReadMessage(AtkTeam);
+            break;
+          }
+          case 42: {
+            if (defTeam_ == null) {
+              DefTeam = new global::GoPureWithCsharp.Battle.Team();
+            }
+            input.ReadMessage(DefTeam);
+            break;
+          }
+          case 50: {
+            events_.AddEntriesFrom(input, _repeated_events_codec);
+            break;
+          }
+          case 58: {
+            if (result_ == null) {
+              Result = new global::GoPureWithCsharp.Battle.BattleResult();
+            }
+            input.ReadMessage(Result);
+            break;
+          }
+          case 66: {
+            Version = input.ReadString();
+            break;
+          }
+        }
+      }
+    #endif
+    }
+
+    #if !GOOGLE_PROTOBUF_REFSTRUCT_COMPATIBILITY_MODE
+    [global::System.Diagnostics.DebuggerNonUserCodeAttribute]
+    [global::System.CodeDom.Compiler.GeneratedCode("protoc", null)]
+    void pb::IBufferMessage.InternalMergeFrom(ref pb::ParseContext input) {
+      uint tag;
+      while ((tag = input.ReadTag()) != 0) {
+        switch(tag) {
+          default:
+            _unknownFields = pb::UnknownFieldSet.MergeFieldFrom(_unknownFields, ref input);
+            break;
+          case 8: {
+            BattleId = input.ReadUInt32();
+            break;
+          }
+          case 16: {
+            StartTime = input.ReadInt64();
+            break;
+          }
+          case 24: {
+            EndTime = input.ReadInt64();
+            break;
+          }
+          case 34: {
+            if (atkTeam_ == null) {
+              AtkTeam = new global::GoPureWithCsharp.Battle.Team();
+            }
+            input.ReadMessage(AtkTeam);
+            break;
+          }
+          case 42: {
+            if (defTeam_ == null) {
+              DefTeam = new global::GoPureWithCsharp.Battle.Team();
+            }
+            input.ReadMessage(DefTeam);
+            break;
+          }
+          case 50: {
+            events_.AddEntriesFrom(ref input, _repeated_events_codec);
+            break;
+          }
+          case 58: {
+            if (result_ == null) {
+              Result = new global::GoPureWithCsharp.Battle.BattleResult();
+            }
+            input.ReadMessage(Result);
+            break;
+          }
+          case 66: {
+            Version = input.ReadString();
+            break;
+          }
+        }
+      }
+    }
+    #endif
+
+  }
+
+  /// <summary>
+  /// 战斗进度报告
+  /// </summary>
+  public sealed partial class ProgressReport : pb::IMessage<ProgressReport>
+  #if !GOOGLE_PROTOBUF_REFSTRUCT_COMPATIBILITY_MODE
+      , pb::IBufferMessage
+  #endif
+  {
+    private static readonly pb::MessageParser<ProgressReport> _parser = new pb::MessageParser<ProgressReport>(() => new ProgressReport());
+    private pb::UnknownFieldSet _unknownFields;
+    [global::System.Diagnostics.DebuggerNonUserCodeAttribute]
+    [global::System.CodeDom.Compiler.GeneratedCode("protoc", null)]
+    public static pb::MessageParser<ProgressReport> Parser { get { return _parser; } }
+
+    [global::System.Diagnostics.DebuggerNonUserCodeAttribute]
+    [global::System.CodeDom.Compiler.GeneratedCode("protoc", null)]
+    public static pbr::MessageDescriptor Descriptor {
+      get { return global::GoPureWithCsharp.Battle.BattleReflection.Descriptor.MessageTypes[20]; }
+    }
+
+    [global::System.Diagnostics.DebuggerNonUserCodeAttribute]
+    [global::System.CodeDom.Compiler.GeneratedCode("protoc", null)]
+    pbr::MessageDescriptor pb::IMessage.Descriptor {
+      get { return Descriptor; }
+    }
+
+    [global::System.Diagnostics.DebuggerNonUserCodeAttribute]
+    [global::System.CodeDom.Compiler.GeneratedCode("protoc", null)]
+    public ProgressReport() {
+      OnConstruction();
+    }
+
+    partial void OnConstruction();
+
+    [global::System.Diagnostics.DebuggerNonUserCodeAttribute]
+    [global::System.CodeDom.Compiler.GeneratedCode("protoc", null)]
+    public ProgressReport(ProgressReport other) : this() {
+      battleId_ = other.battleId_;
+      progressPercent_ = other.progressPercent_;
+      currentRound_ = other.currentRound_;
+      status_ = other.status_ != null ? other.status_.Clone() : null;
+      timestamp_ = other.timestamp_;
+      _unknownFields = pb::UnknownFieldSet.Clone(other._unknownFields);
+    }
+
+    [global::System.Diagnostics.DebuggerNonUserCodeAttribute]
+    [global::System.CodeDom.Compiler.GeneratedCode("protoc", null)]
+    public ProgressReport Clone() {
+      return new ProgressReport(this);
+    }
+
+    /// <summary>Field number for the "battle_id" field.</summary>
+    public const int BattleIdFieldNumber = 1;
+    private uint battleId_;
+    /// <summary>
+    /// 战斗ID
+    /// </summary>
+    [global::System.Diagnostics.DebuggerNonUserCodeAttribute]
+    [global::System.CodeDom.Compiler.GeneratedCode("protoc", null)]
+    public uint BattleId {
+      get { return battleId_; }
+      set {
+        battleId_ = value;
+      }
+    }
+
+    /// <summary>Field number for the "progress_percent" field.</summary>
+    public const int ProgressPercentFieldNumber = 2;
+    private int progressPercent_;
+    /// <summary>
+    /// 进度百分比 (0-100)
+    /// </summary>
+    [global::System.Diagnostics.DebuggerNonUserCodeAttribute]
+    [global::System.CodeDom.Compiler.GeneratedCode("protoc", null)]
+    public int ProgressPercent {
+      get { return progressPercent_; }
+      set {
+        progressPercent_ = value;
+      }
+    }
+
+    /// <summary>Field number for the "current_round" field.</summary>
+    public const int CurrentRoundFieldNumber = 3;
+    private int currentRound_;
+    /// <summary>
+    /// 当前回合
+    /// </summary>
+    [global::System.Diagnostics.DebuggerNonUserCodeAttribute]
+    [global::System.CodeDom.Compiler.GeneratedCode("protoc", null)]
+    public int CurrentRound {
+      get { return currentRound_; }
+      set {
+        currentRound_ = value;
+      }
+    }
+
+    /// <summary>Field number for the "status" field.</summary>
+    public const int StatusFieldNumber = 4;
+    private global::GoPureWithCsharp.Battle.BattleStatus status_;
+    /// <summary>
+    /// 当前战斗状态
     /// </summary>
     [global::System.Diagnostics.DebuggerNonUserCodeAttribute]
     [global::System.CodeDom.Compiler.GeneratedCode("protoc", null)]
-    public int BattleScore {
-      get { return battleScore_; }
+    public global::GoPureWithCsharp.Battle.BattleStatus Status {
+      get { return status_; }
       set {
-        battleScore_ = value;
+        status_ = value;
+      }
+    }
+
+    /// <summary>Field number for the "timestamp" field.</summary>
+    public const int TimestampFieldNumber = 5;
+    private long timestamp_;
+    /// <summary>
+    /// 时间戳
+    /// </summary>
+    [global::System.Diagnostics.DebuggerNonUserCodeAttribute]
+    [global::System.CodeDom.Compiler.GeneratedCode("protoc", null)]
+    public long Timestamp {
+      get { return timestamp_; }
+      set {
+        timestamp_ = value;
       }
     }
 
     [global::System.Diagnostics.DebuggerNonUserCodeAttribute]
     [global::System.CodeDom.Compiler.GeneratedCode("protoc", null)]
     public override bool Equals(object other) {
-      return Equals(other as BattleResult);
+      return Equals(other as ProgressReport);
     }
 
     [global::System.Diagnostics.DebuggerNonUserCodeAttribute]
     [global::System.CodeDom.Compiler.GeneratedCode("protoc", null)]
-    public bool Equals(BattleResult other) {
+    public bool Equals(ProgressReport other) {
       if (ReferenceEquals(other, null)) {
         return false;
       }
       if (ReferenceEquals(other, this)) {
         return true;
       }
-      if (Winner != other.Winner) return false;
-      if (Loser != other.Loser) return false;
-      if (AtkDamage != other.AtkDamage) return false;
-      if (DefDamage != other.DefDamage) return false;
-      if(!kills_.Equals(other.kills_)) return false;
-      if (Duration != other.Duration) return false;
-      if (BattleScore != other.BattleScore) return false;
+      if (BattleId != other.BattleId) return false;
+      if (ProgressPercent != other.ProgressPercent) return false;
+      if (CurrentRound != other.CurrentRound) return false;
+      if (!object.Equals(Status, other.Status)) return false;
+      if (Timestamp != other.Timestamp) return false;
       return Equals(_unknownFields, other._unknownFields);
     }
 
@@ -2857,13 +7093,11 @@ namespace GoPureWithCsharp.Battle {
     [global::System.CodeDom.Compiler.GeneratedCode("protoc", null)]
     public override int GetHashCode() {
       int hash = 1;
-      if (Winner != 0) hash ^= Winner.GetHashCode();
-      if (Loser != 0) hash ^= Loser.GetHashCode();
-      if (AtkDamage != 0) hash ^= AtkDamage.GetHashCode();
-      if (DefDamage != 0) hash ^= DefDamage.GetHashCode();
-      hash ^= kills_.GetHashCode();
-      if (Duration != 0L) hash ^= Duration.GetHashCode();
-      if (BattleScore != 0) hash ^= BattleScore.GetHashCode();
+      if (BattleId != 0) hash ^= BattleId.GetHashCode();
+      if (ProgressPercent != 0) hash ^= ProgressPercent.GetHashCode();
+      if (CurrentRound != 0) hash ^= CurrentRound.GetHashCode();
+      if (status_ != null) hash ^= Status.GetHashCode();
+      if (Timestamp != 0L) hash ^= Timestamp.GetHashCode();
       if (_unknownFields != null) {
         hash ^= _unknownFields.GetHashCode();
       }
@@ -2882,30 +7116,25 @@ namespace GoPureWithCsharp.Battle {
     #if !GOOGLE_PROTOBUF_REFSTRUCT_COMPATIBILITY_MODE
       output.WriteRawMessage(this);
     #else
-      if (Winner != 0) {
+      if (BattleId != 0) {
         output.WriteRawTag(8);
-        output.WriteUInt32(Winner);
+        output.WriteUInt32(BattleId);
       }
-      if (Loser != 0) {
+      if (ProgressPercent != 0) {
         output.WriteRawTag(16);
-        output.WriteUInt32(Loser);
+        output.WriteInt32(ProgressPercent);
       }
-      if (AtkDamage != 0) {
+      if (CurrentRound != 0) {
         output.WriteRawTag(24);
-        output.WriteInt32(AtkDamage);
-      }
-      if (DefDamage != 0) {
-        output.WriteRawTag(32);
-        output.WriteInt32(DefDamage);
+        output.WriteInt32(CurrentRound);
       }
-      kills_.WriteTo(output, _repeated_kills_codec);
-      if (Duration != 0L) {
-        output.WriteRawTag(48);
-        output.WriteInt64(Duration);
+      if (status_ != null) {
+        output.WriteRawTag(34);
+        output.WriteMessage(Status);
       }
-      if (BattleScore != 0) {
-        output.WriteRawTag(56);
-        output.WriteInt32(BattleScore);
+      if (Timestamp != 0L) {
+        output.WriteRawTag(40);
+        output.WriteInt64(Timestamp);
       }
       if (_unknownFields != null) {
         _unknownFields.WriteTo(output);
@@ -2917,30 +7146,25 @@ namespace GoPureWithCsharp.Battle {
     [global::System.Diagnostics.DebuggerNonUserCodeAttribute]
     [global::System.CodeDom.Compiler.GeneratedCode("protoc", null)]
     void pb::IBufferMessage.InternalWriteTo(ref pb::WriteContext output) {
-      if (Winner != 0) {
+      if (BattleId != 0) {
         output.WriteRawTag(8);
-        output.WriteUInt32(Winner);
+        output.WriteUInt32(BattleId);
       }
-      if (Loser != 0) {
+      if (ProgressPercent != 0) {
         output.WriteRawTag(16);
-        output.WriteUInt32(Loser);
+        output.WriteInt32(ProgressPercent);
       }
-      if (AtkDamage != 0) {
+      if (CurrentRound != 0) {
         output.WriteRawTag(24);
-        output.WriteInt32(AtkDamage);
-      }
-      if (DefDamage != 0) {
-        output.WriteRawTag(32);
-        output.WriteInt32(DefDamage);
+        output.WriteInt32(CurrentRound);
       }
-      kills_.WriteTo(ref output, _repeated_kills_codec);
-      if (Duration != 0L) {
-        output.WriteRawTag(48);
-        output.WriteInt64(Duration);
+      if (status_ != null) {
+        output.WriteRawTag(34);
+        output.WriteMessage(Status);
       }
-      if (BattleScore != 0) {
-        output.WriteRawTag(56);
-        output.WriteInt32(BattleScore);
+      if (Timestamp != 0L) {
+        output.WriteRawTag(40);
+        output.WriteInt64(Timestamp);
       }
       if (_unknownFields != null) {
         _unknownFields.WriteTo(ref output);
@@ -2952,24 +7176,20 @@ namespace GoPureWithCsharp.Battle {
     [global::System.CodeDom.Compiler.GeneratedCode("protoc", null)]
     public int CalculateSize() {
       int size = 0;
-      if (Winner != 0) {
-        size += 1 + pb::CodedOutputStream.ComputeUInt32Size(Winner);
-      }
-      if (Loser != 0) {
-        size += 1 + pb::CodedOutputStream.ComputeUInt32Size(Loser);
+      if (BattleId != 0) {
+        size += 1 + pb::CodedOutputStream.ComputeUInt32Size(BattleId);
       }
-      if (AtkDamage != 0) {
-        size += 1 + pb::CodedOutputStream.ComputeInt32Size(AtkDamage);
+      if (ProgressPercent != 0) {
+        size += 1 + pb::CodedOutputStream.ComputeInt32Size(ProgressPercent);
       }
-      if (DefDamage != 0) {
-        size += 1 + pb::CodedOutputStream.ComputeInt32Size(DefDamage);
+      if (CurrentRound != 0) {
+        size += 1 + pb::CodedOutputStream.ComputeInt32Size(CurrentRound);
       }
-      size += kills_.CalculateSize(_repeated_kills_codec);
-      if (Duration != 0L) {
-        size += 1 + pb::CodedOutputStream.ComputeInt64Size(Duration);
+      if (status_ != null) {
+        size += 1 + pb::CodedOutputStream.ComputeMessageSize(Status);
       }
-      if (BattleScore != 0) {
-        size += 1 + pb::CodedOutputStream.ComputeInt32Size(BattleScore);
+      if (Timestamp != 0L) {
+        size += 1 + pb::CodedOutputStream.ComputeInt64Size(Timestamp);
       }
       if (_unknownFields != null) {
         size += _unknownFields.CalculateSize();
@@ -2979,28 +7199,27 @@ namespace GoPureWithCsharp.Battle {
 
     [global::System.Diagnostics.DebuggerNonUserCodeAttribute]
     [global::System.CodeDom.Compiler.GeneratedCode("protoc", null)]
-    public void MergeFrom(BattleResult other) {
+    public void MergeFrom(ProgressReport other) {
       if (other == null) {
         return;
       }
-      if (other.Winner != 0) {
-        Winner = other.Winner;
-      }
-      if (other.Loser != 0) {
-        Loser = other.Loser;
+      if (other.BattleId != 0) {
+        BattleId = other.BattleId;
       }
-      if (other.AtkDamage != 0) {
-        AtkDamage = other.AtkDamage;
+      if (other.ProgressPercent != 0) {
+        ProgressPercent = other.ProgressPercent;
       }
-      if (other.DefDamage != 0) {
-        DefDamage = other.DefDamage;
+      if (other.CurrentRound != 0) {
+        CurrentRound = other.CurrentRound;
       }
-      kills_.Add(other.kills_);
-      if (other.Duration != 0L) {
-        Duration = other.Duration;
+      if (other.status_ != null) {
+        if (status_ == null) {
+          Status = new global::GoPureWithCsharp.Battle.BattleStatus();
+        }
+        Status.MergeFrom(other.Status);
       }
-      if (other.BattleScore != 0) {
-        BattleScore = other.BattleScore;
+      if (other.Timestamp != 0L) {
+        Timestamp = other.Timestamp;
       }
       _unknownFields = pb::UnknownFieldSet.MergeFrom(_unknownFields, other._unknownFields);
     }
@@ -3018,32 +7237,26 @@ namespace GoPureWithCsharp.Battle {
             _unknownFields = pb::UnknownFieldSet.MergeFieldFrom(_unknownFields, input);
             break;
           case 8: {
-            Winner = input.ReadUInt32();
+            BattleId = input.ReadUInt32();
             break;
           }
           case 16: {
-            Loser = input.ReadUInt32();
+            ProgressPercent = input.ReadInt32();
             break;
           }
           case 24: {
-            AtkDamage = input.ReadInt32();
+            CurrentRound = input.ReadInt32();
             break;
           }
-          case 32: {
-            DefDamage = input.ReadInt32();
+          case 34: {
+            if (status_ == null) {
+              Status = new global::GoPureWithCsharp.Battle.BattleStatus();
+            }
+            input.ReadMessage(Status);
             break;
           }
-          case 42:
           case 40: {
-            kills_.AddEntriesFrom(input, _repeated_kills_codec);
-            break;
-          }
-          case 48: {
-            Duration = input.ReadInt64();
-            break;
-          }
-          case 56: {
-            BattleScore = input.ReadInt32();
+            Timestamp = input.ReadInt64();
             break;
           }
         }
@@ -3062,32 +7275,26 @@ namespace GoPureWithCsharp.Battle {
             _unknownFields = pb::UnknownFieldSet.MergeFieldFrom(_unknownFields, ref input);
             break;
           case 8: {
-            Winner = input.ReadUInt32();
+            BattleId = input.ReadUInt32();
             break;
           }
           case 16: {
-            Loser = input.ReadUInt32();
+            ProgressPercent = input.ReadInt32();
             break;
           }
           case 24: {
-            AtkDamage = input.ReadInt32();
+            CurrentRound = input.ReadInt32();
             break;
           }
-          case 32: {
-            DefDamage = input.ReadInt32();
+          case 34: {
+            if (status_ == null) {
+              Status = new global::GoPureWithCsharp.Battle.BattleStatus();
+            }
+            input.ReadMessage(Status);
             break;
           }
-          case 42:
           case 40: {
-            kills_.AddEntriesFrom(ref input, _repeated_kills_codec);
-            break;
-          }
-          case 48: {
-            Duration = input.ReadInt64();
-            break;
-          }
-          case 56: {
-            BattleScore = input.ReadInt32();
+            Timestamp = input.ReadInt64();
             break;
           }
         }
@@ -3098,23 +7305,23 @@ namespace GoPureWithCsharp.Battle {
   }
 
   /// <summary>
-  /// 战斗状态
+  /// 战斗通知 (异步通知，由 C# 回调 Go)
   /// </summary>
-  public sealed partial class BattleStatus : pb::IMessage<BattleStatus>
+  public sealed partial class BattleNotification : pb::IMessage<BattleNotification>
   #if !GOOGLE_PROTOBUF_REFSTRUCT_COMPATIBILITY_MODE
       , pb::IBufferMessage
   #endif
   {
-    private static readonly pb::MessageParser<BattleStatus> _parser = new pb::MessageParser<BattleStatus>(() => new BattleStatus());
+    private static readonly pb::MessageParser<BattleNotification> _parser = new pb::MessageParser<BattleNotification>(() => new BattleNotification());
     private pb::UnknownFieldSet _unknownFields;
     [global::System.Diagnostics.DebuggerNonUserCodeAttribute]
     [global::System.CodeDom.Compiler.GeneratedCode("protoc", null)]
-    public static pb::MessageParser<BattleStatus> Parser { get { return _parser; } }
+    public static pb::MessageParser<BattleNotification> Parser { get { return _parser; } }
 
     [global::System.Diagnostics.DebuggerNonUserCodeAttribute]
     [global::System.CodeDom.Compiler.GeneratedCode("protoc", null)]
     public static pbr::MessageDescriptor Descriptor {
-      get { return global::GoPureWithCsharp.Battle.BattleReflection.Descriptor.MessageTypes[10]; }
+      get { return global::GoPureWithCsharp.Battle.BattleReflection.Descriptor.MessageTypes[21]; }
     }
 
     [global::System.Diagnostics.DebuggerNonUserCodeAttribute]
@@ -3125,7 +7332,7 @@ namespace GoPureWithCsharp.Battle {
 
     [global::System.Diagnostics.DebuggerNonUserCodeAttribute]
     [global::System.CodeDom.Compiler.GeneratedCode("protoc", null)]
-    public BattleStatus() {
+    public BattleNotification() {
       OnConstruction();
     }
 
@@ -3133,133 +7340,116 @@ namespace GoPureWithCsharp.Battle {
 
     [global::System.Diagnostics.DebuggerNonUserCodeAttribute]
     [global::System.CodeDom.Compiler.GeneratedCode("protoc", null)]
-    public BattleStatus(BattleStatus other) : this() {
-      battleId_ = other.battleId_;
-      round_ = other.round_;
-      atkHealth_ = other.atkHealth_;
-      defHealth_ = other.defHealth_;
-      state_ = other.state_;
+    public BattleNotification(BattleNotification other) : this() {
       timestamp_ = other.timestamp_;
-      _unknownFields = pb::UnknownFieldSet.Clone(other._unknownFields);
-    }
-
-    [global::System.Diagnostics.DebuggerNonUserCodeAttribute]
-    [global::System.CodeDom.Compiler.GeneratedCode("protoc", null)]
-    public BattleStatus Clone() {
-      return new BattleStatus(this);
-    }
-
-    /// <summary>Field number for the "battle_id" field.</summary>
-    public const int BattleIdFieldNumber = 1;
-    private uint battleId_;
-    /// <summary>
-    /// 战斗ID
-    /// </summary>
+      notificationType_ = other.notificationType_;
+      battleId_ = other.battleId_;
+      payload_ = other.payload_;
+      errorMessage_ = other.errorMessage_;
+      _unknownFields = pb::UnknownFieldSet.Clone(other._unknownFields);
+    }
+
     [global::System.Diagnostics.DebuggerNonUserCodeAttribute]
     [global::System.CodeDom.Compiler.GeneratedCode("protoc", null)]
-    public uint BattleId {
-      get { return battleId_; }
-      set {
-        battleId_ = value;
-      }
+    public BattleNotification Clone() {
+      return new BattleNotification(this);
     }
 
-    /// <summary>Field number for the "round" field.</summary>
-    public const int RoundFieldNumber = 2;
-    private int round_;
+    /// <summary>Field number for the "timestamp" field.</summary>
+    public const int TimestampFieldNumber = 1;
+    private long timestamp_;
     /// <summary>
-    /// 当前回合
+    /// 通知时间戳
     /// </summary>
     [global::System.Diagnostics.DebuggerNonUserCodeAttribute]
     [global::System.CodeDom.Compiler.GeneratedCode("protoc", null)]
-    public int Round {
-      get { return round_; }
+    public long Timestamp {
+      get { return timestamp_; }
       set {
-        round_ = value;
+        timestamp_ = value;
       }
     }
 
-    /// <summary>Field number for the "atk_health" field.</summary>
-    public const int AtkHealthFieldNumber = 3;
-    private int atkHealth_;
+    /// <summary>Field number for the "notification_type" field.</summary>
+    public const int NotificationTypeFieldNumber = 2;
+    private global::GoPureWithCsharp.Battle.NotificationType notificationType_ = global::GoPureWithCsharp.Battle.NotificationType.StatusUpdate;
     /// <summary>
-    /// 攻击方生命值
+    /// 通知类型
     /// </summary>
     [global::System.Diagnostics.DebuggerNonUserCodeAttribute]
     [global::System.CodeDom.Compiler.GeneratedCode("protoc", null)]
-    public int AtkHealth {
-      get { return atkHealth_; }
+    public global::GoPureWithCsharp.Battle.NotificationType NotificationType {
+      get { return notificationType_; }
       set {
-        atkHealth_ = value;
+        notificationType_ = value;
       }
     }
 
-    /// <summary>Field number for the "def_health" field.</summary>
-    public const int DefHealthFieldNumber = 4;
-    private int defHealth_;
+    /// <summary>Field number for the "battle_id" field.</summary>
+    public const int BattleIdFieldNumber = 3;
+    private uint battleId_;
     /// <summary>
-    /// 防守方生命值
+    /// 战斗ID
     /// </summary>
     [global::System.Diagnostics.DebuggerNonUserCodeAttribute]
     [global::System.CodeDom.Compiler.GeneratedCode("protoc", null)]
-    public int DefHealth {
-      get { return defHealth_; }
+    public uint BattleId {
+      get { return battleId_; }
       set {
-        defHealth_ = value;
+        battleId_ = value;
       }
     }
 
-    /// <summary>Field number for the "state" field.</summary>
-    public const int StateFieldNumber = 5;
-    private string state_ = "";
+    /// <summary>Field number for the "payload" field.</summary>
+    public const int PayloadFieldNumber = 4;
+    private pb::ByteString payload_ = pb::ByteString.Empty;
     /// <summary>
-    /// 战斗状态 (running/paused/finished)
+    /// 序列化的详细数据 (ProgressReport/BattleEvent/BattleReplay)
     /// </summary>
     [global::System.Diagnostics.DebuggerNonUserCodeAttribute]
     [global::System.CodeDom.Compiler.GeneratedCode("protoc", null)]
-    public string State {
-      get { return state_; }
+    public pb::ByteString Payload {
+      get { return payload_; }
       set {
-        state_ = pb::ProtoPreconditions.CheckNotNull(value, "value");
+        payload_ = pb::ProtoPreconditions.CheckNotNull(value, "value");
       }
     }
 
-    /// <summary>Field number for the "timestamp" field.</summary>
-    public const int TimestampFieldNumber = 6;
-    private long timestamp_;
+    /// <summary>Field number for the "error_message" field.</summary>
+    public const int ErrorMessageFieldNumber = 5;
+    private string errorMessage_ = "";
     /// <summary>
-    /// 时间戳
+    /// 错误消息 (仅在 ERROR_OCCURRED 时)
     /// </summary>
     [global::System.Diagnostics.DebuggerNonUserCodeAttribute]
     [global::System.CodeDom.Compiler.GeneratedCode("protoc", null)]
-    public long Timestamp {
-      get { return timestamp_; }
+    public string ErrorMessage {
+      get { return errorMessage_; }
       set {
-        timestamp_ = value;
+        errorMessage_ = pb::ProtoPreconditions.CheckNotNull(value, "value");
       }
     }
 
     [global::System.Diagnostics.DebuggerNonUserCodeAttribute]
     [global::System.CodeDom.Compiler.GeneratedCode("protoc", null)]
     public override bool Equals(object other) {
-      return Equals(other as BattleStatus);
+      return Equals(other as BattleNotification);
     }
 
     [global::System.Diagnostics.DebuggerNonUserCodeAttribute]
     [global::System.CodeDom.Compiler.GeneratedCode("protoc", null)]
-    public bool Equals(BattleStatus other) {
+    public bool Equals(BattleNotification other) {
       if (ReferenceEquals(other, null)) {
         return false;
       }
       if (ReferenceEquals(other, this)) {
         return true;
       }
-      if (BattleId != other.BattleId) return false;
-      if (Round != other.Round) return false;
-      if (AtkHealth != other.AtkHealth) return false;
-      if (DefHealth != other.DefHealth) return false;
-      if (State != other.State) return false;
       if (Timestamp != other.Timestamp) return false;
+      if (NotificationType != other.NotificationType) return false;
+      if (BattleId != other.BattleId) return false;
+      if (Payload != other.Payload) return false;
+      if (ErrorMessage != other.ErrorMessage) return false;
       return Equals(_unknownFields, other._unknownFields);
     }
 
@@ -3267,12 +7457,11 @@ namespace GoPureWithCsharp.Battle {
     [global::System.CodeDom.Compiler.GeneratedCode("protoc", null)]
     public override int GetHashCode() {
       int hash = 1;
-      if (BattleId != 0) hash ^= BattleId.GetHashCode();
-      if (Round != 0) hash ^= Round.GetHashCode();
-      if (AtkHealth != 0) hash ^= AtkHealth.GetHashCode();
-      if (DefHealth != 0) hash ^= DefHealth.GetHashCode();
-      if (State.Length != 0) hash ^= State.GetHashCode();
       if (Timestamp != 0L) hash ^= Timestamp.GetHashCode();
+      if (NotificationType != global::GoPureWithCsharp.Battle.NotificationType.StatusUpdate) hash ^= NotificationType.GetHashCode();
+      if (BattleId != 0) hash ^= BattleId.GetHashCode();
+      if (Payload.Length != 0) hash ^= Payload.GetHashCode();
+      if (ErrorMessage.Length != 0) hash ^= ErrorMessage.GetHashCode();
       if (_unknownFields != null) {
         hash ^= _unknownFields.GetHashCode();
       }
@@ -3291,29 +7480,25 @@ namespace GoPureWithCsharp.Battle {
     #if !GOOGLE_PROTOBUF_REFSTRUCT_COMPATIBILITY_MODE
       output.WriteRawMessage(this);
     #else
-      if (BattleId != 0) {
+      if (Timestamp != 0L) {
         output.WriteRawTag(8);
-        output.WriteUInt32(BattleId);
+        output.WriteInt64(Timestamp);
       }
-      if (Round != 0) {
+      if (NotificationType != global::GoPureWithCsharp.Battle.NotificationType.StatusUpdate) {
         output.WriteRawTag(16);
-        output.WriteInt32(Round);
+        output.WriteEnum((int) NotificationType);
       }
-      if (AtkHealth != 0) {
+      if (BattleId != 0) {
         output.WriteRawTag(24);
-        output.WriteInt32(AtkHealth);
+        output.WriteUInt32(BattleId);
       }
-      if (DefHealth != 0) {
-        output.WriteRawTag(32);
-        output.WriteInt32(DefHealth);
+      if (Payload.Length != 0) {
+        output.WriteRawTag(34);
+        output.WriteBytes(Payload);
       }
-      if (State.Length != 0) {
+      if (ErrorMessage.Length != 0) {
         output.WriteRawTag(42);
-        output.WriteString(State);
-      }
-      if (Timestamp != 0L) {
-        output.WriteRawTag(48);
-        output.WriteInt64(Timestamp);
+        output.WriteString(ErrorMessage);
       }
       if (_unknownFields != null) {
         _unknownFields.WriteTo(output);
@@ -3325,29 +7510,25 @@ namespace GoPureWithCsharp.Battle {
     [global::System.Diagnostics.DebuggerNonUserCodeAttribute]
     [global::System.CodeDom.Compiler.GeneratedCode("protoc", null)]
     void pb::IBufferMessage.InternalWriteTo(ref pb::WriteContext output) {
-      if (BattleId != 0) {
+      if (Timestamp != 0L) {
         output.WriteRawTag(8);
-        output.WriteUInt32(BattleId);
+        output.WriteInt64(Timestamp);
       }
-      if (Round != 0) {
+      if (NotificationType != global::GoPureWithCsharp.Battle.NotificationType.StatusUpdate) {
         output.WriteRawTag(16);
-        output.WriteInt32(Round);
+        output.WriteEnum((int) NotificationType);
       }
-      if (AtkHealth != 0) {
+      if (BattleId != 0) {
         output.WriteRawTag(24);
-        output.WriteInt32(AtkHealth);
+        output.WriteUInt32(BattleId);
       }
-      if (DefHealth != 0) {
-        output.WriteRawTag(32);
-        output.WriteInt32(DefHealth);
+      if (Payload.Length != 0) {
+        output.WriteRawTag(34);
+        output.WriteBytes(Payload);
       }
-      if (State.Length != 0) {
+      if (ErrorMessage.Length != 0) {
         output.WriteRawTag(42);
-        output.WriteString(State);
-      }
-      if (Timestamp != 0L) {
-        output.WriteRawTag(48);
-        output.WriteInt64(Timestamp);
+        output.WriteString(ErrorMessage);
       }
       if (_unknownFields != null) {
         _unknownFields.WriteTo(ref output);
@@ -3359,23 +7540,20 @@ namespace GoPureWithCsharp.Battle {
     [global::System.CodeDom.Compiler.GeneratedCode("protoc", null)]
     public int CalculateSize() {
       int size = 0;
-      if (BattleId != 0) {
-        size += 1 + pb::CodedOutputStream.ComputeUInt32Size(BattleId);
-      }
-      if (Round != 0) {
-        size += 1 + pb::CodedOutputStream.ComputeInt32Size(Round);
+      if (Timestamp != 0L) {
+        size += 1 + pb::CodedOutputStream.ComputeInt64Size(Timestamp);
       }
-      if (AtkHealth != 0) {
-        size += 1 + pb::CodedOutputStream.ComputeInt32Size(AtkHealth);
+      if (NotificationType != global::GoPureWithCsharp.Battle.NotificationType.StatusUpdate) {
+        size += 1 + pb::CodedOutputStream.ComputeEnumSize((int) NotificationType);
       }
-      if (DefHealth != 0) {
-        size += 1 + pb::CodedOutputStream.ComputeInt32Size(DefHealth);
+      if (BattleId != 0) {
+        size += 1 + pb::CodedOutputStream.ComputeUInt32Size(BattleId);
       }
-      if (State.Length != 0) {
-        size += 1 + pb::CodedOutputStream.ComputeStringSize(State);
+      if (Payload.Length != 0) {
+        size += 1 + pb::CodedOutputStream.ComputeBytesSize(Payload);
       }
-      if (Timestamp != 0L) {
-        size += 1 + pb::CodedOutputStream.ComputeInt64Size(Timestamp);
+      if (ErrorMessage.Length != 0) {
+        size += 1 + pb::CodedOutputStream.ComputeStringSize(ErrorMessage);
       }
       if (_unknownFields != null) {
         size += _unknownFields.CalculateSize();
@@ -3385,27 +7563,24 @@ namespace GoPureWithCsharp.Battle {
 
     [global::System.Diagnostics.DebuggerNonUserCodeAttribute]
     [global::System.CodeDom.Compiler.GeneratedCode("protoc", null)]
-    public void MergeFrom(BattleStatus other) {
+    public void MergeFrom(BattleNotification other) {
       if (other == null) {
         return;
       }
-      if (other.BattleId != 0) {
-        BattleId = other.BattleId;
-      }
-      if (other.Round != 0) {
-        Round = other.Round;
+      if (other.Timestamp != 0L) {
+        Timestamp = other.Timestamp;
       }
-      if (other.AtkHealth != 0) {
-        AtkHealth = other.AtkHealth;
+      if (other.NotificationType != global::GoPureWithCsharp.Battle.NotificationType.StatusUpdate) {
+        NotificationType = other.NotificationType;
       }
-      if (other.DefHealth != 0) {
-        DefHealth = other.DefHealth;
+      if (other.BattleId != 0) {
+        BattleId = other.BattleId;
       }
-      if (other.State.Length != 0) {
-        State = other.State;
+      if (other.Payload.Length != 0) {
+        Payload = other.Payload;
       }
-      if (other.Timestamp != 0L) {
-        Timestamp = other.Timestamp;
+      if (other.ErrorMessage.Length != 0) {
+        ErrorMessage = other.ErrorMessage;
       }
       _unknownFields = pb::UnknownFieldSet.MergeFrom(_unknownFields, other._unknownFields);
     }
@@ -3423,27 +7598,23 @@ namespace GoPureWithCsharp.Battle {
             _unknownFields = pb::UnknownFieldSet.MergeFieldFrom(_unknownFields, input);
             break;
           case 8: {
-            BattleId = input.ReadUInt32();
+            Timestamp = input.ReadInt64();
             break;
           }
           case 16: {
-            Round = input.ReadInt32();
+            NotificationType = (global::GoPureWithCsharp.Battle.NotificationType) input.ReadEnum();
             break;
           }
           case 24: {
-            AtkHealth = input.ReadInt32();
+            BattleId = input.ReadUInt32();
             break;
           }
-          case 32: {
-            DefHealth = input.ReadInt32();
+          case 34: {
+            Payload = input.ReadBytes();
             break;
           }
           case 42: {
-            State = input.ReadString();
-            break;
-          }
-          case 48: {
-            Timestamp = input.ReadInt64();
+            ErrorMessage = input.ReadString();
             break;
           }
         }
@@ -3462,27 +7633,23 @@ namespace GoPureWithCsharp.Battle {
             _unknownFields = pb::UnknownFieldSet.MergeFieldFrom(_unknownFields, ref input);
             break;
           case 8: {
-            BattleId = input.ReadUInt32();
+            Timestamp = input.ReadInt64();
             break;
           }
           case 16: {
-            Round = input.ReadInt32();
+            NotificationType = (global::GoPureWithCsharp.Battle.NotificationType) input.ReadEnum();
             break;
           }
           case 24: {
-            AtkHealth = input.ReadInt32();
+            BattleId = input.ReadUInt32();
             break;
           }
-          case 32: {
-            DefHealth = input.ReadInt32();
+          case 34: {
+            Payload = input.ReadBytes();
             break;
           }
           case 42: {
-            State = input.ReadString();
-            break;
-          }
-          case 48: {
-            Timestamp = input.ReadInt64();
+            ErrorMessage = input.ReadString();
             break;
           }
         }
@@ -3492,24 +7659,21 @@ namespace GoPureWithCsharp.Battle {
 
   }
 
-  /// <summary>
-  /// 通用响应消息
-  /// </summary>
-  public sealed partial class BattleResponse : pb::IMessage<BattleResponse>
+  public sealed partial class BattleContext : pb::IMessage<BattleContext>
   #if !GOOGLE_PROTOBUF_REFSTRUCT_COMPATIBILITY_MODE
       , pb::IBufferMessage
   #endif
   {
-    private static readonly pb::MessageParser<BattleResponse> _parser = new pb::MessageParser<BattleResponse>(() => new BattleResponse());
+    private static readonly pb::MessageParser<BattleContext> _parser = new pb::MessageParser<BattleContext>(() => new BattleContext());
     private pb::UnknownFieldSet _unknownFields;
     [global::System.Diagnostics.DebuggerNonUserCodeAttribute]
     [global::System.CodeDom.Compiler.GeneratedCode("protoc", null)]
-    public static pb::MessageParser<BattleResponse> Parser { get { return _parser; } }
+    public static pb::MessageParser<BattleContext> Parser { get { return _parser; } }
 
     [global::System.Diagnostics.DebuggerNonUserCodeAttribute]
     [global::System.CodeDom.Compiler.GeneratedCode("protoc", null)]
     public static pbr::MessageDescriptor Descriptor {
-      get { return global::GoPureWithCsharp.Battle.BattleReflection.Descriptor.MessageTypes[11]; }
+      get { return global::GoPureWithCsharp.Battle.BattleReflection.Descriptor.MessageTypes[22]; }
     }
 
     [global::System.Diagnostics.DebuggerNonUserCodeAttribute]
@@ -3520,107 +7684,136 @@ namespace GoPureWithCsharp.Battle {
 
     [global::System.Diagnostics.DebuggerNonUserCodeAttribute]
     [global::System.CodeDom.Compiler.GeneratedCode("protoc", null)]
-    public BattleResponse() {
+    public BattleContext() {
       OnConstruction();
     }
 
     partial void OnConstruction();
 
-    [global::System.Diagnostics.DebuggerNonUserCodeAttribute]
-    [global::System.CodeDom.Compiler.GeneratedCode("protoc", null)]
-    public BattleResponse(BattleResponse other) : this() {
-      code_ = other.code_;
-      message_ = other.message_;
-      result_ = other.result_;
-      timestamp_ = other.timestamp_;
+    [global::System.Diagnostics.DebuggerNonUserCodeAttribute]
+    [global::System.CodeDom.Compiler.GeneratedCode("protoc", null)]
+    public BattleContext(BattleContext other) : this() {
+      battleId_ = other.battleId_;
+      tick_ = other.tick_;
+      switch (other.OptionCase) {
+        case OptionOneofCase.BattleInput:
+          BattleInput = other.BattleInput.Clone();
+          break;
+        case OptionOneofCase.BattleOutput:
+          BattleOutput = other.BattleOutput.Clone();
+          break;
+      }
+
       _unknownFields = pb::UnknownFieldSet.Clone(other._unknownFields);
     }
 
     [global::System.Diagnostics.DebuggerNonUserCodeAttribute]
     [global::System.CodeDom.Compiler.GeneratedCode("protoc", null)]
-    public BattleResponse Clone() {
-      return new BattleResponse(this);
+    public BattleContext Clone() {
+      return new BattleContext(this);
     }
 
-    /// <summary>Field number for the "code" field.</summary>
-    public const int CodeFieldNumber = 1;
-    private int code_;
+    /// <summary>Field number for the "battle_id" field.</summary>
+    public const int BattleIdFieldNumber = 1;
+    private uint battleId_;
     /// <summary>
-    /// 错误码 (0=成功)
+    /// 战斗ID
     /// </summary>
     [global::System.Diagnostics.DebuggerNonUserCodeAttribute]
     [global::System.CodeDom.Compiler.GeneratedCode("protoc", null)]
-    public int Code {
-      get { return code_; }
+    public uint BattleId {
+      get { return battleId_; }
       set {
-        code_ = value;
+        battleId_ = value;
       }
     }
 
-    /// <summary>Field number for the "message" field.</summary>
-    public const int MessageFieldNumber = 2;
-    private string message_ = "";
+    /// <summary>Field number for the "tick" field.</summary>
+    public const int TickFieldNumber = 2;
+    private ulong tick_;
     /// <summary>
-    /// 消息
+    /// 当前Tick
     /// </summary>
     [global::System.Diagnostics.DebuggerNonUserCodeAttribute]
     [global::System.CodeDom.Compiler.GeneratedCode("protoc", null)]
-    public string Message {
-      get { return message_; }
+    public ulong Tick {
+      get { return tick_; }
       set {
-        message_ = pb::ProtoPreconditions.CheckNotNull(value, "value");
+        tick_ = value;
       }
     }
 
-    /// <summary>Field number for the "result" field.</summary>
-    public const int ResultFieldNumber = 3;
-    private pb::ByteString result_ = pb::ByteString.Empty;
+    /// <summary>Field number for the "battle_input" field.</summary>
+    public const int BattleInputFieldNumber = 3;
     /// <summary>
-    /// 结果数据(序列化的具体消息)
+    /// 通用战斗输入
     /// </summary>
     [global::System.Diagnostics.DebuggerNonUserCodeAttribute]
     [global::System.CodeDom.Compiler.GeneratedCode("protoc", null)]
-    public pb::ByteString Result {
-      get { return result_; }
+    public global::GoPureWithCsharp.Battle.BattleInput BattleInput {
+      get { return optionCase_ == OptionOneofCase.BattleInput ? (global::GoPureWithCsharp.Battle.BattleInput) option_ : null; }
       set {
-        result_ = pb::ProtoPreconditions.CheckNotNull(value, "value");
+        option_ = value;
+        optionCase_ = value == null ? OptionOneofCase.None : OptionOneofCase.BattleInput;
       }
     }
 
-    /// <summary>Field number for the "timestamp" field.</summary>
-    public const int TimestampFieldNumber = 4;
-    private long timestamp_;
+    /// <summary>Field number for the "battle_output" field.</summary>
+    public const int BattleOutputFieldNumber = 4;
     /// <summary>
-    /// 时间戳
+    /// 开始战斗请求
     /// </summary>
     [global::System.Diagnostics.DebuggerNonUserCodeAttribute]
     [global::System.CodeDom.Compiler.GeneratedCode("protoc", null)]
-    public long Timestamp {
-      get { return timestamp_; }
+    public global::GoPureWithCsharp.Battle.BattleOutput BattleOutput {
+      get { return optionCase_ == OptionOneofCase.BattleOutput ? (global::GoPureWithCsharp.Battle.BattleOutput) option_ : null; }
       set {
-        timestamp_ = value;
+        option_ = value;
+        optionCase_ = value == null ? OptionOneofCase.None : OptionOneofCase.BattleOutput;
       }
     }
 
+    private object option_;
+    /// <summary>Enum of possible cases for the "option" oneof.</summary>
+    public enum OptionOneofCase {
+      None = 0,
+      BattleInput = 3,
+      BattleOutput = 4,
+    }
+    private OptionOneofCase optionCase_ = OptionOneofCase.None;
+    [global::System.Diagnostics.DebuggerNonUserCodeAttribute]
+    [global::System.CodeDom.Compiler.GeneratedCode("protoc", null)]
+    public OptionOneofCase OptionCase {
+      get { return optionCase_; }
+    }
+
+    [global::System.Diagnostics.DebuggerNonUserCodeAttribute]
+    [global::System.CodeDom.Compiler.GeneratedCode("protoc", null)]
+    public void ClearOption() {
+      optionCase_ = OptionOneofCase.None;
+      option_ = null;
+    }
+
     [global::System.Diagnostics.DebuggerNonUserCodeAttribute]
     [global::System.CodeDom.Compiler.GeneratedCode("protoc", null)]
     public override bool Equals(object other) {
-      return Equals(other as BattleResponse);
+      return Equals(other as BattleContext);
     }
 
     [global::System.Diagnostics.DebuggerNonUserCodeAttribute]
     [global::System.CodeDom.Compiler.GeneratedCode("protoc", null)]
-    public bool Equals(BattleResponse other) {
+    public bool Equals(BattleContext other) {
       if (ReferenceEquals(other, null)) {
         return false;
       }
       if (ReferenceEquals(other, this)) {
         return true;
       }
-      if (Code != other.Code) return false;
-      if (Message != other.Message) return false;
-      if (Result != other.Result) return false;
-      if (Timestamp != other.Timestamp) return false;
+      if (BattleId != other.BattleId) return false;
+      if (Tick != other.Tick) return false;
+      if (!object.Equals(BattleInput, other.BattleInput)) return false;
+      if (!object.Equals(BattleOutput, other.BattleOutput)) return false;
+      if (OptionCase != other.OptionCase) return false;
       return Equals(_unknownFields, other._unknownFields);
     }
 
@@ -3628,10 +7821,11 @@ namespace GoPureWithCsharp.Battle {
     [global::System.CodeDom.Compiler.GeneratedCode("protoc", null)]
     public override int GetHashCode() {
       int hash = 1;
-      if (Code != 0) hash ^= Code.GetHashCode();
-      if (Message.Length != 0) hash ^= Message.GetHashCode();
-      if (Result.Length != 0) hash ^= Result.GetHashCode();
-      if (Timestamp != 0L) hash ^= Timestamp.GetHashCode();
+      if (BattleId != 0) hash ^= BattleId.GetHashCode();
+      if (Tick != 0UL) hash ^= Tick.GetHashCode();
+      if (optionCase_ == OptionOneofCase.BattleInput) hash ^= BattleInput.GetHashCode();
+      if (optionCase_ == OptionOneofCase.BattleOutput) hash ^= BattleOutput.GetHashCode();
+      hash ^= (int) optionCase_;
       if (_unknownFields != null) {
         hash ^= _unknownFields.GetHashCode();
       }
@@ -3650,21 +7844,21 @@ namespace GoPureWithCsharp.Battle {
     #if !GOOGLE_PROTOBUF_REFSTRUCT_COMPATIBILITY_MODE
       output.WriteRawMessage(this);
     #else
-      if (Code != 0) {
+      if (BattleId != 0) {
         output.WriteRawTag(8);
-        output.WriteInt32(Code);
+        output.WriteUInt32(BattleId);
       }
-      if (Message.Length != 0) {
-        output.WriteRawTag(18);
-        output.WriteString(Message);
+      if (Tick != 0UL) {
+        output.WriteRawTag(16);
+        output.WriteUInt64(Tick);
       }
-      if (Result.Length != 0) {
+      if (optionCase_ == OptionOneofCase.BattleInput) {
         output.WriteRawTag(26);
-        output.WriteBytes(Result);
+        output.WriteMessage(BattleInput);
       }
-      if (Timestamp != 0L) {
-        output.WriteRawTag(32);
-        output.WriteInt64(Timestamp);
+      if (optionCase_ == OptionOneofCase.BattleOutput) {
+        output.WriteRawTag(34);
+        output.WriteMessage(BattleOutput);
       }
       if (_unknownFields != null) {
         _unknownFields.WriteTo(output);
@@ -3676,21 +7870,21 @@ namespace GoPureWithCsharp.Battle {
     [global::System.Diagnostics.DebuggerNonUserCodeAttribute]
     [global::System.CodeDom.Compiler.GeneratedCode("protoc", null)]
     void pb::IBufferMessage.InternalWriteTo(ref pb::WriteContext output) {
-      if (Code != 0) {
+      if (BattleId != 0) {
         output.WriteRawTag(8);
-        output.WriteInt32(Code);
+        output.WriteUInt32(BattleId);
       }
-      if (Message.Length != 0) {
-        output.WriteRawTag(18);
-        output.WriteString(Message);
+      if (Tick != 0UL) {
+        output.WriteRawTag(16);
+        output.WriteUInt64(Tick);
       }
-      if (Result.Length != 0) {
+      if (optionCase_ == OptionOneofCase.BattleInput) {
         output.WriteRawTag(26);
-        output.WriteBytes(Result);
+        output.WriteMessage(BattleInput);
       }
-      if (Timestamp != 0L) {
-        output.WriteRawTag(32);
-        output.WriteInt64(Timestamp);
+      if (optionCase_ == OptionOneofCase.BattleOutput) {
+        output.WriteRawTag(34);
+        output.WriteMessage(BattleOutput);
       }
       if (_unknownFields != null) {
         _unknownFields.WriteTo(ref output);
@@ -3702,17 +7896,17 @@ namespace GoPureWithCsharp.Battle {
     [global::System.CodeDom.Compiler.GeneratedCode("protoc", null)]
     public int CalculateSize() {
       int size = 0;
-      if (Code != 0) {
-        size += 1 + pb::CodedOutputStream.ComputeInt32Size(Code);
+      if (BattleId != 0) {
+        size += 1 + pb::CodedOutputStream.ComputeUInt32Size(BattleId);
       }
-      if (Message.Length != 0) {
-        size += 1 + pb::CodedOutputStream.ComputeStringSize(Message);
+      if (Tick != 0UL) {
+        size += 1 + pb::CodedOutputStream.ComputeUInt64Size(Tick);
       }
-      if (Result.Length != 0) {
-        size += 1 + pb::CodedOutputStream.ComputeBytesSize(Result);
+      if (optionCase_ == OptionOneofCase.BattleInput) {
+        size += 1 + pb::CodedOutputStream.ComputeMessageSize(BattleInput);
       }
-      if (Timestamp != 0L) {
-        size += 1 + pb::CodedOutputStream.ComputeInt64Size(Timestamp);
+      if (optionCase_ == OptionOneofCase.BattleOutput) {
+        size += 1 + pb::CodedOutputStream.ComputeMessageSize(BattleOutput);
       }
       if (_unknownFields != null) {
         size += _unknownFields.CalculateSize();
@@ -3722,22 +7916,31 @@ namespace GoPureWithCsharp.Battle {
 
     [global::System.Diagnostics.DebuggerNonUserCodeAttribute]
     [global::System.CodeDom.Compiler.GeneratedCode("protoc", null)]
-    public void MergeFrom(BattleResponse other) {
+    public void MergeFrom(BattleContext other) {
       if (other == null) {
         return;
       }
-      if (other.Code != 0) {
-        Code = other.Code;
-      }
-      if (other.Message.Length != 0) {
-        Message = other.Message;
+      if (other.BattleId != 0) {
+        BattleId = other.BattleId;
       }
-      if (other.Result.Length != 0) {
-        Result = other.Result;
+      if (other.Tick != 0UL) {
+        Tick = other.Tick;
       }
-      if (other.Timestamp != 0L) {
-        Timestamp = other.Timestamp;
+      switch (other.OptionCase) {
+        case OptionOneofCase.BattleInput:
+          if (BattleInput == null) {
+            BattleInput = new global::GoPureWithCsharp.Battle.BattleInput();
+          }
+          BattleInput.MergeFrom(other.BattleInput);
+          break;
+        case OptionOneofCase.BattleOutput:
+          if (BattleOutput == null) {
+            BattleOutput = new global::GoPureWithCsharp.Battle.BattleOutput();
+          }
+          BattleOutput.MergeFrom(other.BattleOutput);
+          break;
       }
+
       _unknownFields = pb::UnknownFieldSet.MergeFrom(_unknownFields, other._unknownFields);
     }
 
@@ -3754,19 +7957,29 @@ namespace GoPureWithCsharp.Battle {
             _unknownFields = pb::UnknownFieldSet.MergeFieldFrom(_unknownFields, input);
             break;
           case 8: {
-            Code = input.ReadInt32();
+            BattleId = input.ReadUInt32();
             break;
           }
-          case 18: {
-            Message = input.ReadString();
+          case 16: {
+            Tick = input.ReadUInt64();
             break;
           }
           case 26: {
-            Result = input.ReadBytes();
+            global::GoPureWithCsharp.Battle.BattleInput subBuilder = new global::GoPureWithCsharp.Battle.BattleInput();
+            if (optionCase_ == OptionOneofCase.BattleInput) {
+              subBuilder.MergeFrom(BattleInput);
+            }
+            input.ReadMessage(subBuilder);
+            BattleInput = subBuilder;
             break;
           }
-          case 32: {
-            Timestamp = input.ReadInt64();
+          case 34: {
+            global::GoPureWithCsharp.Battle.BattleOutput subBuilder = new global::GoPureWithCsharp.Battle.BattleOutput();
+            if (optionCase_ == OptionOneofCase.BattleOutput) {
+              subBuilder.MergeFrom(BattleOutput);
+            }
+            input.ReadMessage(subBuilder);
+            BattleOutput = subBuilder;
             break;
           }
         }
@@ -3785,19 +7998,29 @@ namespace GoPureWithCsharp.Battle {
             _unknownFields = pb::UnknownFieldSet.MergeFieldFrom(_unknownFields, ref input);
             break;
           case 8: {
-            Code = input.ReadInt32();
+            BattleId = input.ReadUInt32();
             break;
           }
-          case 18: {
-            Message = input.ReadString();
+          case 16: {
+            Tick = input.ReadUInt64();
             break;
           }
           case 26: {
-            Result = input.ReadBytes();
+            global::GoPureWithCsharp.Battle.BattleInput subBuilder = new global::GoPureWithCsharp.Battle.BattleInput();
+            if (optionCase_ == OptionOneofCase.BattleInput) {
+              subBuilder.MergeFrom(BattleInput);
+            }
+            input.ReadMessage(subBuilder);
+            BattleInput = subBuilder;
             break;
           }
-          case 32: {
-            Timestamp = input.ReadInt64();
+          case 34: {
+            global::GoPureWithCsharp.Battle.BattleOutput subBuilder = new global::GoPureWithCsharp.Battle.BattleOutput();
+            if (optionCase_ == OptionOneofCase.BattleOutput) {
+              subBuilder.MergeFrom(BattleOutput);
+            }
+            input.ReadMessage(subBuilder);
+            BattleOutput = subBuilder;
             break;
           }
         }
@@ -3808,23 +8031,23 @@ namespace GoPureWithCsharp.Battle {
   }
 
   /// <summary>
-  /// 批量战斗请求
+  /// 单场战斗快照 (对应 C# BattleInstance)
   /// </summary>
-  public sealed partial class BatchBattleRequest : pb::IMessage<BatchBattleRequest>
+  public sealed partial class BattleSnapshot : pb::IMessage<BattleSnapshot>
   #if !GOOGLE_PROTOBUF_REFSTRUCT_COMPATIBILITY_MODE
       , pb::IBufferMessage
   #endif
   {
-    private static readonly pb::MessageParser<BatchBattleRequest> _parser = new pb::MessageParser<BatchBattleRequest>(() => new BatchBattleRequest());
+    private static readonly pb::MessageParser<BattleSnapshot> _parser = new pb::MessageParser<BattleSnapshot>(() => new BattleSnapshot());
     private pb::UnknownFieldSet _unknownFields;
     [global::System.Diagnostics.DebuggerNonUserCodeAttribute]
     [global::System.CodeDom.Compiler.GeneratedCode("protoc", null)]
-    public static pb::MessageParser<BatchBattleRequest> Parser { get { return _parser; } }
+    public static pb::MessageParser<BattleSnapshot> Parser { get { return _parser; } }
 
     [global::System.Diagnostics.DebuggerNonUserCodeAttribute]
     [global::System.CodeDom.Compiler.GeneratedCode("protoc", null)]
     public static pbr::MessageDescriptor Descriptor {
-      get { return global::GoPureWithCsharp.Battle.BattleReflection.Descriptor.MessageTypes[12]; }
+      get { return global::GoPureWithCsharp.Battle.BattleReflection.Descriptor.MessageTypes[23]; }
     }
 
     [global::System.Diagnostics.DebuggerNonUserCodeAttribute]
@@ -3835,7 +8058,7 @@ namespace GoPureWithCsharp.Battle {
 
     [global::System.Diagnostics.DebuggerNonUserCodeAttribute]
     [global::System.CodeDom.Compiler.GeneratedCode("protoc", null)]
-    public BatchBattleRequest() {
+    public BattleSnapshot() {
       OnConstruction();
     }
 
@@ -3843,379 +8066,420 @@ namespace GoPureWithCsharp.Battle {
 
     [global::System.Diagnostics.DebuggerNonUserCodeAttribute]
     [global::System.CodeDom.Compiler.GeneratedCode("protoc", null)]
-    public BatchBattleRequest(BatchBattleRequest other) : this() {
-      battles_ = other.battles_.Clone();
-      batchId_ = other.batchId_;
-      parallel_ = other.parallel_;
+    public BattleSnapshot(BattleSnapshot other) : this() {
+      battleId_ = other.battleId_;
+      atkTeamId_ = other.atkTeamId_;
+      defTeamId_ = other.defTeamId_;
+      atkHealth_ = other.atkHealth_;
+      defHealth_ = other.defHealth_;
+      currentRound_ = other.currentRound_;
+      isFinished_ = other.isFinished_;
+      winner_ = other.winner_;
+      seed_ = other.seed_;
+      rngState_ = other.rngState_;
+      startFrame_ = other.startFrame_;
+      events_ = other.events_.Clone();
+      state_ = other.state_;
+      endRequested_ = other.endRequested_;
+      atkMaxHealth_ = other.atkMaxHealth_;
+      defMaxHealth_ = other.defMaxHealth_;
+      atkAttack_ = other.atkAttack_;
+      defAttack_ = other.defAttack_;
+      units_ = other.units_.Clone();
+      atkTeam_ = other.atkTeam_ != null ? other.atkTeam_.Clone() : null;
+      defTeam_ = other.defTeam_ != null ? other.defTeam_.Clone() : null;
+      shardId_ = other.shardId_;
+      timeout_ = other.timeout_;
       _unknownFields = pb::UnknownFieldSet.Clone(other._unknownFields);
     }
 
     [global::System.Diagnostics.DebuggerNonUserCodeAttribute]
     [global::System.CodeDom.Compiler.GeneratedCode("protoc", null)]
-    public BatchBattleRequest Clone() {
-      return new BatchBattleRequest(this);
+    public BattleSnapshot Clone() {
+      return new BattleSnapshot(this);
     }
 
-    /// <summary>Field number for the "battles" field.</summary>
-    public const int BattlesFieldNumber = 1;
-    private static readonly pb::FieldCodec<global::GoPureWithCsharp.Battle.StartBattle> _repeated_battles_codec
-        = pb::FieldCodec.ForMessage(10, global::GoPureWithCsharp.Battle.StartBattle.Parser);
-    private readonly pbc::RepeatedField<global::GoPureWithCsharp.Battle.StartBattle> battles_ = new pbc::RepeatedField<global::GoPureWithCsharp.Battle.StartBattle>();
+    /// <summary>Field number for the "battle_id" field.</summary>
+    public const int BattleIdFieldNumber = 1;
+    private uint battleId_;
     /// <summary>
-    /// 战斗列表
+    /// 战斗ID
     /// </summary>
     [global::System.Diagnostics.DebuggerNonUserCodeAttribute]
     [global::System.CodeDom.Compiler.GeneratedCode("protoc", null)]
-    public pbc::RepeatedField<global::GoPureWithCsharp.Battle.StartBattle> Battles {
-      get { return battles_; }
+    public uint BattleId {
+      get { return battleId_; }
+      set {
+        battleId_ = value;
+      }
     }
 
-    /// <summary>Field number for the "batch_id" field.</summary>
-    public const int BatchIdFieldNumber = 2;
-    private string batchId_ = "";
+    /// <summary>Field number for the "atk_team_id" field.</summary>
+    public const int AtkTeamIdFieldNumber = 2;
+    private uint atkTeamId_;
     /// <summary>
-    /// 批次ID
+    /// 攻击方队伍ID
     /// </summary>
     [global::System.Diagnostics.DebuggerNonUserCodeAttribute]
     [global::System.CodeDom.Compiler.GeneratedCode("protoc", null)]
-    public string BatchId {
-      get { return batchId_; }
+    public uint AtkTeamId {
+      get { return atkTeamId_; }
       set {
-        batchId_ = pb::ProtoPreconditions.CheckNotNull(value, "value");
+        atkTeamId_ = value;
       }
     }
 
-    /// <summary>Field number for the "parallel" field.</summary>
-    public const int ParallelFieldNumber = 3;
-    private int parallel_;
+    /// <summary>Field number for the "def_team_id" field.</summary>
+    public const int DefTeamIdFieldNumber = 3;
+    private uint defTeamId_;
     /// <summary>
-    /// 是否并行处理
+    /// 防守方队伍ID
     /// </summary>
     [global::System.Diagnostics.DebuggerNonUserCodeAttribute]
     [global::System.CodeDom.Compiler.GeneratedCode("protoc", null)]
-    public int Parallel {
-      get { return parallel_; }
+    public uint DefTeamId {
+      get { return defTeamId_; }
       set {
-        parallel_ = value;
+        defTeamId_ = value;
       }
     }
 
+    /// <summary>Field number for the "atk_health" field.</summary>
+    public const int AtkHealthFieldNumber = 4;
+    private int atkHealth_;
+    /// <summary>
+    /// 攻击方剩余血量
+    /// </summary>
     [global::System.Diagnostics.DebuggerNonUserCodeAttribute]
     [global::System.CodeDom.Compiler.GeneratedCode("protoc", null)]
-    public override bool Equals(object other) {
-      return Equals(other as BatchBattleRequest);
+    public int AtkHealth {
+      get { return atkHealth_; }
+      set {
+        atkHealth_ = value;
+      }
     }
 
+    /// <summary>Field number for the "def_health" field.</summary>
+    public const int DefHealthFieldNumber = 5;
+    private int defHealth_;
+    /// <summary>
+    /// 防守方剩余血量
+    /// </summary>
     [global::System.Diagnostics.DebuggerNonUserCodeAttribute]
     [global::System.CodeDom.Compiler.GeneratedCode("protoc", null)]
-    public bool Equals(BatchBattleRequest other) {
-      if (ReferenceEquals(other, null)) {
-        return false;
-      }
-      if (ReferenceEquals(other, this)) {
-        return true;
+    public int DefHealth {
+      get { return defHealth_; }
+      set {
+        defHealth_ = value;
       }
-      if(!battles_.Equals(other.battles_)) return false;
-      if (BatchId != other.BatchId) return false;
-      if (Parallel != other.Parallel) return false;
-      return Equals(_unknownFields, other._unknownFields);
     }
 
+    /// <summary>Field number for the "current_round" field.</summary>
+    public const int CurrentRoundFieldNumber = 6;
+    private int currentRound_;
+    /// <summary>
+    /// 当前回合
+    /// </summary>
     [global::System.Diagnostics.DebuggerNonUserCodeAttribute]
     [global::System.CodeDom.Compiler.GeneratedCode("protoc", null)]
-    public override int GetHashCode() {
-      int hash = 1;
-      hash ^= battles_.GetHashCode();
-      if (BatchId.Length != 0) hash ^= BatchId.GetHashCode();
-      if (Parallel != 0) hash ^= Parallel.GetHashCode();
-      if (_unknownFields != null) {
-        hash ^= _unknownFields.GetHashCode();
+    public int CurrentRound {
+      get { return currentRound_; }
+      set {
+        currentRound_ = value;
       }
-      return hash;
     }
 
+    /// <summary>Field number for the "is_finished" field.</summary>
+    public const int IsFinishedFieldNumber = 7;
+    private bool isFinished_;
+    /// <summary>
+    /// 是否已结束
+    /// </summary>
     [global::System.Diagnostics.DebuggerNonUserCodeAttribute]
     [global::System.CodeDom.Compiler.GeneratedCode("protoc", null)]
-    public override string ToString() {
-      return pb::JsonFormatter.ToDiagnosticString(this);
+    public bool IsFinished {
+      get { return isFinished_; }
+      set {
+        isFinished_ = value;
+      }
     }
 
+    /// <summary>Field number for the "winner" field.</summary>
+    public const int WinnerFieldNumber = 8;
+    private uint winner_;
+    /// <summary>
+    /// 获胜方队伍ID (未结束时为 0)
+    /// </summary>
     [global::System.Diagnostics.DebuggerNonUserCodeAttribute]
     [global::System.CodeDom.Compiler.GeneratedCode("protoc", null)]
-    public void WriteTo(pb::CodedOutputStream output) {
-    #if !GOOGLE_PROTOBUF_REFSTRUCT_COMPATIBILITY_MODE
-      output.WriteRawMessage(this);
-    #else
-      battles_.WriteTo(output, _repeated_battles_codec);
-      if (BatchId.Length != 0) {
-        output.WriteRawTag(18);
-        output.WriteString(BatchId);
-      }
-      if (Parallel != 0) {
-        output.WriteRawTag(24);
-        output.WriteInt32(Parallel);
-      }
-      if (_unknownFields != null) {
-        _unknownFields.WriteTo(output);
+    public uint Winner {
+      get { return winner_; }
+      set {
+        winner_ = value;
       }
-    #endif
     }
 
-    #if !GOOGLE_PROTOBUF_REFSTRUCT_COMPATIBILITY_MODE
+    /// <summary>Field number for the "seed" field.</summary>
+    public const int SeedFieldNumber = 9;
+    private ulong seed_;
+    /// <summary>
+    /// 创建战斗时的随机种子
+    /// </summary>
     [global::System.Diagnostics.DebuggerNonUserCodeAttribute]
     [global::System.CodeDom.Compiler.GeneratedCode("protoc", null)]
-    void pb::IBufferMessage.InternalWriteTo(ref pb::WriteContext output) {
-      battles_.WriteTo(ref output, _repeated_battles_codec);
-      if (BatchId.Length != 0) {
-        output.WriteRawTag(18);
-        output.WriteString(BatchId);
-      }
-      if (Parallel != 0) {
-        output.WriteRawTag(24);
-        output.WriteInt32(Parallel);
-      }
-      if (_unknownFields != null) {
-        _unknownFields.WriteTo(ref output);
+    public ulong Seed {
+      get { return seed_; }
+      set {
+        seed_ = value;
       }
     }
-    #endif
 
+    /// <summary>Field number for the "rng_state" field.</summary>
+    public const int RngStateFieldNumber = 10;
+    private ulong rngState_;
+    /// <summary>
+    /// 随机数生成器当前状态
+    /// </summary>
     [global::System.Diagnostics.DebuggerNonUserCodeAttribute]
     [global::System.CodeDom.Compiler.GeneratedCode("protoc", null)]
-    public int CalculateSize() {
-      int size = 0;
-      size += battles_.CalculateSize(_repeated_battles_codec);
-      if (BatchId.Length != 0) {
-        size += 1 + pb::CodedOutputStream.ComputeStringSize(BatchId);
-      }
-      if (Parallel != 0) {
-        size += 1 + pb::CodedOutputStream.ComputeInt32Size(Parallel);
-      }
-      if (_unknownFields != null) {
-        size += _unknownFields.CalculateSize();
+    public ulong RngState {
+      get { return rngState_; }
+      set {
+        rngState_ = value;
       }
-      return size;
     }
 
+    /// <summary>Field number for the "start_frame" field.</summary>
+    public const int StartFrameFieldNumber = 11;
+    private ulong startFrame_;
+    /// <summary>
+    /// 第一回合的逻辑帧 (未开始时为 0)
+    /// </summary>
     [global::System.Diagnostics.DebuggerNonUserCodeAttribute]
     [global::System.CodeDom.Compiler.GeneratedCode("protoc", null)]
-    public void MergeFrom(BatchBattleRequest other) {
-      if (other == null) {
-        return;
-      }
-      battles_.Add(other.battles_);
-      if (other.BatchId.Length != 0) {
-        BatchId = other.BatchId;
-      }
-      if (other.Parallel != 0) {
-        Parallel = other.Parallel;
+    public ulong StartFrame {
+      get { return startFrame_; }
+      set {
+        startFrame_ = value;
       }
-      _unknownFields = pb::UnknownFieldSet.MergeFrom(_unknownFields, other._unknownFields);
     }
 
+    /// <summary>Field number for the "events" field.</summary>
+    public const int EventsFieldNumber = 12;
+    private static readonly pb::FieldCodec<global::GoPureWithCsharp.Battle.BattleEvent> _repeated_events_codec
+        = pb::FieldCodec.ForMessage(98, global::GoPureWithCsharp.Battle.BattleEvent.Parser);
+    private readonly pbc::RepeatedField<global::GoPureWithCsharp.Battle.BattleEvent> events_ = new pbc::RepeatedField<global::GoPureWithCsharp.Battle.BattleEvent>();
+    /// <summary>
+    /// 已记录的回放事件
+    /// </summary>
     [global::System.Diagnostics.DebuggerNonUserCodeAttribute]
     [global::System.CodeDom.Compiler.GeneratedCode("protoc", null)]
-    public void MergeFrom(pb::CodedInputStream input) {
-    #if !GOOGLE_PROTOBUF_REFSTRUCT_COMPATIBILITY_MODE
-      input.ReadRawMessage(this);
-    #else
-      uint tag;
-      while ((tag = input.ReadTag()) != 0) {
-        switch(tag) {
-          default:
-            _unknownFields = pb::UnknownFieldSet.MergeFieldFrom(_unknownFields, input);
-            break;
-          case 10: {
-            battles_.AddEntriesFrom(input, _repeated_battles_codec);
-            break;
-          }
-          case 18: {
-            BatchId = input.ReadString();
-            break;
-          }
-          case 24: {
-            Parallel = input.ReadInt32();
-            break;
-          }
-        }
-      }
-    #endif
+    public pbc::RepeatedField<global::GoPureWithCsharp.Battle.BattleEvent> Events {
+      get { return events_; }
     }
 
-    #if !GOOGLE_PROTOBUF_REFSTRUCT_COMPATIBILITY_MODE
+    /// <summary>Field number for the "state" field.</summary>
+    public const int StateFieldNumber = 13;
+    private string state_ = "";
+    /// <summary>
+    /// 战斗状态 (running/paused/finished/destroyed，为空时由 is_finished 推断)
+    /// </summary>
     [global::System.Diagnostics.DebuggerNonUserCodeAttribute]
     [global::System.CodeDom.Compiler.GeneratedCode("protoc", null)]
-    void pb::IBufferMessage.InternalMergeFrom(ref pb::ParseContext input) {
-      uint tag;
-      while ((tag = input.ReadTag()) != 0) {
-        switch(tag) {
-          default:
-            _unknownFields = pb::UnknownFieldSet.MergeFieldFrom(_unknownFields, ref input);
-            break;
-          case 10: {
-            battles_.AddEntriesFrom(ref input, _repeated_battles_codec);
-            break;
-          }
-          case 18: {
-            BatchId = input.ReadString();
-            break;
-          }
-          case 24: {
-            Parallel = input.ReadInt32();
-            break;
-          }
-        }
+    public string State {
+      get { return state_; }
+      set {
+        state_ = pb::ProtoPreconditions.CheckNotNull(value, "value");
       }
     }
-    #endif
-
-  }
-
-  /// <summary>
-  /// 批量战斗响应
-  /// </summary>
-  public sealed partial class BatchBattleResponse : pb::IMessage<BatchBattleResponse>
-  #if !GOOGLE_PROTOBUF_REFSTRUCT_COMPATIBILITY_MODE
-      , pb::IBufferMessage
-  #endif
-  {
-    private static readonly pb::MessageParser<BatchBattleResponse> _parser = new pb::MessageParser<BatchBattleResponse>(() => new BatchBattleResponse());
-    private pb::UnknownFieldSet _unknownFields;
-    [global::System.Diagnostics.DebuggerNonUserCodeAttribute]
-    [global::System.CodeDom.Compiler.GeneratedCode("protoc", null)]
-    public static pb::MessageParser<BatchBattleResponse> Parser { get { return _parser; } }
 
+    /// <summary>Field number for the "end_requested" field.</summary>
+    public const int EndRequestedFieldNumber = 14;
+    private bool endRequested_;
+    /// <summary>
+    /// 已强制结束，下一个逻辑帧输出结果
+    /// </summary>
     [global::System.Diagnostics.DebuggerNonUserCodeAttribute]
     [global::System.CodeDom.Compiler.GeneratedCode("protoc", null)]
-    public static pbr::MessageDescriptor Descriptor {
-      get { return global::GoPureWithCsharp.Battle.BattleReflection.Descriptor.MessageTypes[13]; }
+    public bool EndRequested {
+      get { return endRequested_; }
+      set {
+        endRequested_ = value;
+      }
     }
 
+    /// <summary>Field number for the "atk_max_health" field.</summary>
+    public const int AtkMaxHealthFieldNumber = 15;
+    private int atkMaxHealth_;
+    /// <summary>
+    /// 攻击方最大生命 (为 0 时取默认血量)
+    /// </summary>
     [global::System.Diagnostics.DebuggerNonUserCodeAttribute]
     [global::System.CodeDom.Compiler.GeneratedCode("protoc", null)]
-    pbr::MessageDescriptor pb::IMessage.Descriptor {
-      get { return Descriptor; }
+    public int AtkMaxHealth {
+      get { return atkMaxHealth_; }
+      set {
+        atkMaxHealth_ = value;
+      }
     }
 
+    /// <summary>Field number for the "def_max_health" field.</summary>
+    public const int DefMaxHealthFieldNumber = 16;
+    private int defMaxHealth_;
+    /// <summary>
+    /// 防守方最大生命
+    /// </summary>
     [global::System.Diagnostics.DebuggerNonUserCodeAttribute]
     [global::System.CodeDom.Compiler.GeneratedCode("protoc", null)]
-    public BatchBattleResponse() {
-      OnConstruction();
+    public int DefMaxHealth {
+      get { return defMaxHealth_; }
+      set {
+        defMaxHealth_ = value;
+      }
     }
 
-    partial void OnConstruction();
-
+    /// <summary>Field number for the "atk_attack" field.</summary>
+    public const int AtkAttackFieldNumber = 17;
+    private int atkAttack_;
+    /// <summary>
+    /// 攻击方攻击力
+    /// </summary>
     [global::System.Diagnostics.DebuggerNonUserCodeAttribute]
     [global::System.CodeDom.Compiler.GeneratedCode("protoc", null)]
-    public BatchBattleResponse(BatchBattleResponse other) : this() {
-      results_ = other.results_.Clone();
-      batchId_ = other.batchId_;
-      successCount_ = other.successCount_;
-      failureCount_ = other.failureCount_;
-      totalDuration_ = other.totalDuration_;
-      _unknownFields = pb::UnknownFieldSet.Clone(other._unknownFields);
+    public int AtkAttack {
+      get { return atkAttack_; }
+      set {
+        atkAttack_ = value;
+      }
     }
 
+    /// <summary>Field number for the "def_attack" field.</summary>
+    public const int DefAttackFieldNumber = 18;
+    private int defAttack_;
+    /// <summary>
+    /// 防守方攻击力
+    /// </summary>
     [global::System.Diagnostics.DebuggerNonUserCodeAttribute]
     [global::System.CodeDom.Compiler.GeneratedCode("protoc", null)]
-    public BatchBattleResponse Clone() {
-      return new BatchBattleResponse(this);
+    public int DefAttack {
+      get { return defAttack_; }
+      set {
+        defAttack_ = value;
+      }
     }
 
-    /// <summary>Field number for the "results" field.</summary>
-    public const int ResultsFieldNumber = 1;
-    private static readonly pb::FieldCodec<global::GoPureWithCsharp.Battle.BattleResult> _repeated_results_codec
-        = pb::FieldCodec.ForMessage(10, global::GoPureWithCsharp.Battle.BattleResult.Parser);
-    private readonly pbc::RepeatedField<global::GoPureWithCsharp.Battle.BattleResult> results_ = new pbc::RepeatedField<global::GoPureWithCsharp.Battle.BattleResult>();
+    /// <summary>Field number for the "units" field.</summary>
+    public const int UnitsFieldNumber = 19;
+    private static readonly pb::FieldCodec<global::GoPureWithCsharp.Battle.BattleUnit> _repeated_units_codec
+        = pb::FieldCodec.ForMessage(154, global::GoPureWithCsharp.Battle.BattleUnit.Parser);
+    private readonly pbc::RepeatedField<global::GoPureWithCsharp.Battle.BattleUnit> units_ = new pbc::RepeatedField<global::GoPureWithCsharp.Battle.BattleUnit>();
     /// <summary>
-    /// 战斗结果列表
+    /// 所有单位 (为空时由 4/5/15~18 恢复为每队一个单位)
     /// </summary>
     [global::System.Diagnostics.DebuggerNonUserCodeAttribute]
     [global::System.CodeDom.Compiler.GeneratedCode("protoc", null)]
-    public pbc::RepeatedField<global::GoPureWithCsharp.Battle.BattleResult> Results {
-      get { return results_; }
+    public pbc::RepeatedField<global::GoPureWithCsharp.Battle.BattleUnit> Units {
+      get { return units_; }
     }
 
-    /// <summary>Field number for the "batch_id" field.</summary>
-    public const int BatchIdFieldNumber = 2;
-    private string batchId_ = "";
+    /// <summary>Field number for the "atk_team" field.</summary>
+    public const int AtkTeamFieldNumber = 20;
+    private global::GoPureWithCsharp.Battle.Team atkTeam_;
     /// <summary>
-    /// 批次ID
+    /// 攻击方队伍 (阵容和名称，用于回放)
     /// </summary>
     [global::System.Diagnostics.DebuggerNonUserCodeAttribute]
     [global::System.CodeDom.Compiler.GeneratedCode("protoc", null)]
-    public string BatchId {
-      get { return batchId_; }
+    public global::GoPureWithCsharp.Battle.Team AtkTeam {
+      get { return atkTeam_; }
       set {
-        batchId_ = pb::ProtoPreconditions.CheckNotNull(value, "value");
+        atkTeam_ = value;
       }
     }
 
-    /// <summary>Field number for the "success_count" field.</summary>
-    public const int SuccessCountFieldNumber = 3;
-    private int successCount_;
+    /// <summary>Field number for the "def_team" field.</summary>
+    public const int DefTeamFieldNumber = 21;
+    private global::GoPureWithCsharp.Battle.Team defTeam_;
     /// <summary>
-    /// 成功数
+    /// 防守方队伍
     /// </summary>
     [global::System.Diagnostics.DebuggerNonUserCodeAttribute]
     [global::System.CodeDom.Compiler.GeneratedCode("protoc", null)]
-    public int SuccessCount {
-      get { return successCount_; }
+    public global::GoPureWithCsharp.Battle.Team DefTeam {
+      get { return defTeam_; }
       set {
-        successCount_ = value;
+        defTeam_ = value;
       }
     }
 
-    /// <summary>Field number for the "failure_count" field.</summary>
-    public const int FailureCountFieldNumber = 4;
-    private int failureCount_;
+    /// <summary>Field number for the "shard_id" field.</summary>
+    public const int ShardIdFieldNumber = 22;
+    private uint shardId_;
     /// <summary>
-    /// 失败数
+    /// 战斗所在的分片
     /// </summary>
     [global::System.Diagnostics.DebuggerNonUserCodeAttribute]
     [global::System.CodeDom.Compiler.GeneratedCode("protoc", null)]
-    public int FailureCount {
-      get { return failureCount_; }
+    public uint ShardId {
+      get { return shardId_; }
       set {
-        failureCount_ = value;
+        shardId_ = value;
       }
     }
 
-    /// <summary>Field number for the "total_duration" field.</summary>
-    public const int TotalDurationFieldNumber = 5;
-    private long totalDuration_;
+    /// <summary>Field number for the "timeout" field.</summary>
+    public const int TimeoutFieldNumber = 23;
+    private bool timeout_;
     /// <summary>
-    /// 总耗时(毫秒)
+    /// 超时强制结束 (BattleEnd.timeout)
     /// </summary>
     [global::System.Diagnostics.DebuggerNonUserCodeAttribute]
     [global::System.CodeDom.Compiler.GeneratedCode("protoc", null)]
-    public long TotalDuration {
-      get { return totalDuration_; }
+    public bool Timeout {
+      get { return timeout_; }
       set {
-        totalDuration_ = value;
+        timeout_ = value;
       }
     }
 
     [global::System.Diagnostics.DebuggerNonUserCodeAttribute]
     [global::System.CodeDom.Compiler.GeneratedCode("protoc", null)]
     public override bool Equals(object other) {
-      return Equals(other as BatchBattleResponse);
+      return Equals(other as BattleSnapshot);
     }
 
     [global::System.Diagnostics.DebuggerNonUserCodeAttribute]
     [global::System.CodeDom.Compiler.GeneratedCode("protoc", null)]
-    public bool Equals(BatchBattleResponse other) {
+    public bool Equals(BattleSnapshot other) {
       if (ReferenceEquals(other, null)) {
         return false;
       }
       if (ReferenceEquals(other, this)) {
         return true;
       }
-      if(!results_.Equals(other.results_)) return false;
-      if (BatchId != other.BatchId) return false;
-      if (SuccessCount != other.SuccessCount) return false;
-      if (FailureCount != other.FailureCount) return false;
-      if (TotalDuration != other.TotalDuration) return false;
+      if (BattleId != other.BattleId) return false;
+      if (AtkTeamId != other.AtkTeamId) return false;
+      if (DefTeamId != other.DefTeamId) return false;
+      if (AtkHealth != other.AtkHealth) return false;
+      if (DefHealth != other.DefHealth) return false;
+      if (CurrentRound != other.CurrentRound) return false;
+      if (IsFinished != other.IsFinished) return false;
+      if (Winner != other.Winner) return false;
+      if (Seed != other.Seed) return false;
+      if (RngState != other.RngState) return false;
+      if (StartFrame != other.StartFrame) return false;
+      if(!events_.Equals(other.events_)) return false;
+      if (State != other.State) return false;
+      if (EndRequested != other.EndRequested) return false;
+      if (AtkMaxHealth != other.AtkMaxHealth) return false;
+      if (DefMaxHealth != other.DefMaxHealth) return false;
+      if (AtkAttack != other.AtkAttack) return false;
+      if (DefAttack != other.DefAttack) return false;
+      if(!units_.Equals(other.units_)) return false;
+      if (!object.Equals(AtkTeam, other.AtkTeam)) return false;
+      if (!object.Equals(DefTeam, other.DefTeam)) return false;
+      if (ShardId != other.ShardId) return false;
+      if (Timeout != other.Timeout) return false;
       return Equals(_unknownFields, other._unknownFields);
     }
 
@@ -4223,11 +8487,29 @@ namespace GoPureWithCsharp.Battle {
     [global::System.CodeDom.Compiler.GeneratedCode("protoc", null)]
     public override int GetHashCode() {
       int hash = 1;
-      hash ^= results_.GetHashCode();
-      if (BatchId.Length != 0) hash ^= BatchId.GetHashCode();
-      if (SuccessCount != 0) hash ^= SuccessCount.GetHashCode();
-      if (FailureCount != 0) hash ^= FailureCount.GetHashCode();
-      if (TotalDuration != 0L) hash ^= TotalDuration.GetHashCode();
+      if (BattleId != 0) hash ^= BattleId.GetHashCode();
+      if (AtkTeamId != 0) hash ^= AtkTeamId.GetHashCode();
+      if (DefTeamId != 0) hash ^= DefTeamId.GetHashCode();
+      if (AtkHealth != 0) hash ^= AtkHealth.GetHashCode();
+      if (DefHealth != 0) hash ^= DefHealth.GetHashCode();
+      if (CurrentRound != 0) hash ^= CurrentRound.GetHashCode();
+      if (IsFinished != false) hash ^= IsFinished.GetHashCode();
+      if (Winner != 0) hash ^= Winner.GetHashCode();
+      if (Seed != 0UL) hash ^= Seed.GetHashCode();
+      if (RngState != 0UL) hash ^= RngState.GetHashCode();
+      if (StartFrame != 0UL) hash ^= StartFrame.GetHashCode();
+      hash ^= events_.GetHashCode();
+      if (State.Length != 0) hash ^= State.GetHashCode();
+      if (EndRequested != false) hash ^= EndRequested.GetHashCode();
+      if (AtkMaxHealth != 0) hash ^= AtkMaxHealth.GetHashCode();
+      if (DefMaxHealth != 0) hash ^= DefMaxHealth.GetHashCode();
+      if (AtkAttack != 0) hash ^= AtkAttack.GetHashCode();
+      if (DefAttack != 0) hash ^= DefAttack.GetHashCode();
+      hash ^= units_.GetHashCode();
+      if (atkTeam_ != null) hash ^= AtkTeam.GetHashCode();
+      if (defTeam_ != null) hash ^= DefTeam.GetHashCode();
+      if (ShardId != 0) hash ^= ShardId.GetHashCode();
+      if (Timeout != false) hash ^= Timeout.GetHashCode();
       if (_unknownFields != null) {
         hash ^= _unknownFields.GetHashCode();
       }
@@ -4246,22 +8528,91 @@ namespace GoPureWithCsharp.Battle {
     #if !GOOGLE_PROTOBUF_REFSTRUCT_COMPATIBILITY_MODE
       output.WriteRawMessage(this);
     #else
-      results_.WriteTo(output, _repeated_results_codec);
-      if (BatchId.Length != 0) {
-        output.WriteRawTag(18);
-        output.WriteString(BatchId);
+      if (BattleId != 0) {
+        output.WriteRawTag(8);
+        output.WriteUInt32(BattleId);
       }
-      if (SuccessCount != 0) {
+      if (AtkTeamId != 0) {
+        output.WriteRawTag(16);
+        output.WriteUInt32(AtkTeamId);
+      }
+      if (DefTeamId != 0) {
         output.WriteRawTag(24);
-        output.WriteInt32(SuccessCount);
+        output.WriteUInt32(DefTeamId);
       }
-      if (FailureCount != 0) {
+      if (AtkHealth != 0) {
         output.WriteRawTag(32);
-        output.WriteInt32(FailureCount);
+        output.WriteInt32(AtkHealth);
       }
-      if (TotalDuration != 0L) {
+      if (DefHealth != 0) {
         output.WriteRawTag(40);
-        output.WriteInt64(TotalDuration);
+        output.WriteInt32(DefHealth);
+      }
+      if (CurrentRound != 0) {
+        output.WriteRawTag(48);
+        output.WriteInt32(CurrentRound);
+      }
+      if (IsFinished != false) {
+        output.WriteRawTag(56);
+        output.WriteBool(IsFinished);
+      }
+      if (Winner != 0) {
+        output.WriteRawTag(64);
+        output.WriteUInt32(Winner);
+      }
+      if (Seed != 0UL) {
+        output.WriteRawTag(72);
+        output.WriteUInt64(Seed);
+      }
+      if (RngState != 0UL) {
+        output.WriteRawTag(80);
+        output.WriteUInt64(RngState);
+      }
+      if (StartFrame != 0UL) {
+        output.WriteRawTag(88);
+        output.WriteUInt64(StartFrame);
+      }
+      events_.WriteTo(output, _repeated_events_codec);
+      if (State.Length != 0) {
+        output.WriteRawTag(106);
+        output.WriteString(State);
+      }
+      if (EndRequested != false) {
+        output.WriteRawTag(112);
+        output.WriteBool(EndRequested);
+      }
+      if (AtkMaxHealth != 0) {
+        output.WriteRawTag(120);
+        output.WriteInt32(AtkMaxHealth);
+      }
+      if (DefMaxHealth != 0) {
+        output.WriteRawTag(128, 1);
+        output.WriteInt32(DefMaxHealth);
+      }
+      if (AtkAttack != 0) {
+        output.WriteRawTag(136, 1);
+        output.WriteInt32(AtkAttack);
+      }
+      if (DefAttack != 0) {
+        output.WriteRawTag(144, 1);
+        output.WriteInt32(DefAttack);
+      }
+      units_.WriteTo(output, _repeated_units_codec);
+      if (atkTeam_ != null) {
+        output.WriteRawTag(162, 1);
+        output.WriteMessage(AtkTeam);
+      }
+      if (defTeam_ != null) {
+        output.WriteRawTag(170, 1);
+        output.WriteMessage(DefTeam);
+      }
+      if (ShardId != 0) {
+        output.WriteRawTag(176, 1);
+        output.WriteUInt32(ShardId);
+      }
+      if (Timeout != false) {
+        output.WriteRawTag(184, 1);
+        output.WriteBool(Timeout);
       }
       if (_unknownFields != null) {
         _unknownFields.WriteTo(output);
@@ -4273,22 +8624,91 @@ namespace GoPureWithCsharp.Battle {
     [global::System.Diagnostics.DebuggerNonUserCodeAttribute]
     [global::System.CodeDom.Compiler.GeneratedCode("protoc", null)]
     void pb::IBufferMessage.InternalWriteTo(ref pb::WriteContext output) {
-      results_.WriteTo(ref output, _repeated_results_codec);
-      if (BatchId.Length != 0) {
-        output.WriteRawTag(18);
-        output.WriteString(BatchId);
+      if (BattleId != 0) {
+        output.WriteRawTag(8);
+        output.WriteUInt32(BattleId);
       }
-      if (SuccessCount != 0) {
+      if (AtkTeamId != 0) {
+        output.WriteRawTag(16);
+        output.WriteUInt32(AtkTeamId);
+      }
+      if (DefTeamId != 0) {
         output.WriteRawTag(24);
-        output.WriteInt32(SuccessCount);
+        output.WriteUInt32(DefTeamId);
       }
-      if (FailureCount != 0) {
+      if (AtkHealth != 0) {
         output.WriteRawTag(32);
-        output.WriteInt32(FailureCount);
+        output.WriteInt32(AtkHealth);
       }
-      if (TotalDuration != 0L) {
+      if (DefHealth != 0) {
         output.WriteRawTag(40);
-        output.WriteInt64(TotalDuration);
+        output.WriteInt32(DefHealth);
+      }
+      if (CurrentRound != 0) {
+        output.WriteRawTag(48);
+        output.WriteInt32(CurrentRound);
+      }
+      if (IsFinished != false) {
+        output.WriteRawTag(56);
+        output.WriteBool(IsFinished);
+      }
+      if (Winner != 0) {
+        output.WriteRawTag(64);
+        output.WriteUInt32(Winner);
+      }
+      if (Seed != 0UL) {
+        output.WriteRawTag(72);
+        output.WriteUInt64(Seed);
+      }
+      if (RngState != 0UL) {
+        output.WriteRawTag(80);
+        output.WriteUInt64(RngState);
+      }
+      if (StartFrame != 0UL) {
+        output.WriteRawTag(88);
+        output.WriteUInt64(StartFrame);
+      }
+      events_.WriteTo(ref output, _repeated_events_codec);
+      if (State.Length != 0) {
+        output.WriteRawTag(106);
+        output.WriteString(State);
+      }
+      if (EndRequested != false) {
+        output.WriteRawTag(112);
+        output.WriteBool(EndRequested);
+      }
+      if (AtkMaxHealth != 0) {
+        output.WriteRawTag(120);
+        output.WriteInt32(AtkMaxHealth);
+      }
+      if (DefMaxHealth != 0) {
+        output.WriteRawTag(128, 1);
+        output.WriteInt32(DefMaxHealth);
+      }
+      if (AtkAttack != 0) {
+        output.WriteRawTag(136, 1);
+        output.WriteInt32(AtkAttack);
+      }
+      if (DefAttack != 0) {
+        output.WriteRawTag(144, 1);
+        output.WriteInt32(DefAttack);
+      }
+      units_.WriteTo(ref output, _repeated_units_codec);
+      if (atkTeam_ != null) {
+        output.WriteRawTag(162, 1);
+        output.WriteMessage(AtkTeam);
+      }
+      if (defTeam_ != null) {
+        output.WriteRawTag(170, 1);
+        output.WriteMessage(DefTeam);
+      }
+      if (ShardId != 0) {
+        output.WriteRawTag(176, 1);
+        output.WriteUInt32(ShardId);
+      }
+      if (Timeout != false) {
+        output.WriteRawTag(184, 1);
+        output.WriteBool(Timeout);
       }
       if (_unknownFields != null) {
         _unknownFields.WriteTo(ref output);
@@ -4300,43 +8720,153 @@ namespace GoPureWithCsharp.Battle {
     [global::System.CodeDom.Compiler.GeneratedCode("protoc", null)]
     public int CalculateSize() {
       int size = 0;
-      size += results_.CalculateSize(_repeated_results_codec);
-      if (BatchId.Length != 0) {
-        size += 1 + pb::CodedOutputStream.ComputeStringSize(BatchId);
+      if (BattleId != 0) {
+        size += 1 + pb::CodedOutputStream.ComputeUInt32Size(BattleId);
+      }
+      if (AtkTeamId != 0) {
+        size += 1 + pb::CodedOutputStream.ComputeUInt32Size(AtkTeamId);
+      }
+      if (DefTeamId != 0) {
+        size += 1 + pb::CodedOutputStream.ComputeUInt32Size(DefTeamId);
+      }
+      if (AtkHealth != 0) {
+        size += 1 + pb::CodedOutputStream.ComputeInt32Size(AtkHealth);
+      }
+      if (DefHealth != 0) {
+        size += 1 + pb::CodedOutputStream.ComputeInt32Size(DefHealth);
+      }
+      if (CurrentRound != 0) {
+        size += 1 + pb::CodedOutputStream.ComputeInt32Size(CurrentRound);
+      }
+      if (IsFinished != false) {
+        size += 1 + 1;
+      }
+      if (Winner != 0) {
+        size += 1 + pb::CodedOutputStream.ComputeUInt32Size(Winner);
+      }
+      if (Seed != 0UL) {
+        size += 1 + pb::CodedOutputStream.ComputeUInt64Size(Seed);
+      }
+      if (RngState != 0UL) {
+        size += 1 + pb::CodedOutputStream.ComputeUInt64Size(RngState);
+      }
+      if (StartFrame != 0UL) {
+        size += 1 + pb::CodedOutputStream.ComputeUInt64Size(StartFrame);
+      }
+      size += events_.CalculateSize(_repeated_events_codec);
+      if (State.Length != 0) {
+        size += 1 + pb::CodedOutputStream.ComputeStringSize(State);
+      }
+      if (EndRequested != false) {
+        size += 1 + 1;
+      }
+      if (AtkMaxHealth != 0) {
+        size += 1 + pb::CodedOutputStream.ComputeInt32Size(AtkMaxHealth);
+      }
+      if (DefMaxHealth != 0) {
+        size += 2 + pb::CodedOutputStream.ComputeInt32Size(DefMaxHealth);
+      }
+      if (AtkAttack != 0) {
+        size += 2 + pb::CodedOutputStream.ComputeInt32Size(AtkAttack);
+      }
+      if (DefAttack != 0) {
+        size += 2 + pb::CodedOutputStream.ComputeInt32Size(DefAttack);
+      }
+      size += units_.CalculateSize(_repeated_units_codec);
+      if (atkTeam_ != null) {
+        size += 2 + pb::CodedOutputStream.ComputeMessageSize(AtkTeam);
+      }
+      if (defTeam_ != null) {
+        size += 2 + pb::CodedOutputStream.ComputeMessageSize(DefTeam);
+      }
+      if (ShardId != 0) {
+        size += 2 + pb::CodedOutputStream.ComputeUInt32Size(ShardId);
+      }
+      if (Timeout != false) {
+        size += 2 + 1;
+      }
+      if (_unknownFields != null) {
+        size += _unknownFields.CalculateSize();
+      }
+      return size;
+    }
+
+    [global::System.Diagnostics.DebuggerNonUserCodeAttribute]
+    [global::System.CodeDom.Compiler.GeneratedCode("protoc", null)]
+    public void MergeFrom(BattleSnapshot other) {
+      if (other == null) {
+        return;
+      }
+      if (other.BattleId != 0) {
+        BattleId = other.BattleId;
+      }
+      if (other.AtkTeamId != 0) {
+        AtkTeamId = other.AtkTeamId;
+      }
+      if (other.DefTeamId != 0) {
+        DefTeamId = other.DefTeamId;
+      }
+      if (other.AtkHealth != 0) {
+        AtkHealth = other.AtkHealth;
+      }
+      if (other.DefHealth != 0) {
+        DefHealth = other.DefHealth;
+      }
+      if (other.CurrentRound != 0) {
+        CurrentRound = other.CurrentRound;
       }
-      if (SuccessCount != 0) {
-        size += 1 + pb::CodedOutputStream.ComputeInt32Size(SuccessCount);
+      if (other.IsFinished != false) {
+        IsFinished = other.IsFinished;
       }
-      if (FailureCount != 0) {
-        size += 1 + pb::CodedOutputStream.ComputeInt32Size(FailureCount);
+      if (other.Winner != 0) {
+        Winner = other.Winner;
       }
-      if (TotalDuration != 0L) {
-        size += 1 + pb::CodedOutputStream.ComputeInt64Size(TotalDuration);
+      if (other.Seed != 0UL) {
+        Seed = other.Seed;
       }
-      if (_unknownFields != null) {
-        size += _unknownFields.CalculateSize();
+      if (other.RngState != 0UL) {
+        RngState = other.RngState;
       }
-      return size;
-    }
-
-    [global::System.Diagnostics.DebuggerNonUserCodeAttribute]
-    [global::System.CodeDom.Compiler.GeneratedCode("protoc", null)]
-    public void MergeFrom(BatchBattleResponse other) {
-      if (other == null) {
-        return;
+      if (other.StartFrame != 0UL) {
+        StartFrame = other.StartFrame;
       }
-      results_.Add(other.results_);
-      if (other.BatchId.Length != 0) {
-        BatchId = other.BatchId;
+      events_.Add(other.events_);
+      if (other.State.Length != 0) {
+        State = other.State;
       }
-      if (other.SuccessCount != 0) {
-        SuccessCount = other.SuccessCount;
+      if (other.EndRequested != false) {
+        EndRequested = other.EndRequested;
       }
-      if (other.FailureCount != 0) {
-        FailureCount = other.FailureCount;
+      if (other.AtkMaxHealth != 0) {
+        AtkMaxHealth = other.AtkMaxHealth;
       }
-      if (other.TotalDuration != 0L) {
-        TotalDuration = other.TotalDuration;
+      if (other.DefMaxHealth != 0) {
+        DefMaxHealth = other.DefMaxHealth;
+      }
+      if (other.AtkAttack != 0) {
+        AtkAttack = other.AtkAttack;
+      }
+      if (other.DefAttack != 0) {
+        DefAttack = other.DefAttack;
+      }
+      units_.Add(other.units_);
+      if (other.atkTeam_ != null) {
+        if (atkTeam_ == null) {
+          AtkTeam = new global::GoPureWithCsharp.Battle.Team();
+        }
+        AtkTeam.MergeFrom(other.AtkTeam);
+      }
+      if (other.defTeam_ != null) {
+        if (defTeam_ == null) {
+          DefTeam = new global::GoPureWithCsharp.Battle.Team();
+        }
+        DefTeam.MergeFrom(other.DefTeam);
+      }
+      if (other.ShardId != 0) {
+        ShardId = other.ShardId;
+      }
+      if (other.Timeout != false) {
+        Timeout = other.Timeout;
       }
       _unknownFields = pb::UnknownFieldSet.MergeFrom(_unknownFields, other._unknownFields);
     }
@@ -4353,24 +8883,102 @@ namespace GoPureWithCsharp.Battle {
           default:
             _unknownFields = pb::UnknownFieldSet.MergeFieldFrom(_unknownFields, input);
             break;
-          case 10: {
-            results_.AddEntriesFrom(input, _repeated_results_codec);
+          case 8: {
+            BattleId = input.ReadUInt32();
             break;
           }
-          case 18: {
-            BatchId = input.ReadString();
+          case 16: {
+            AtkTeamId = input.ReadUInt32();
             break;
           }
           case 24: {
-            SuccessCount = input.ReadInt32();
+            DefTeamId = input.ReadUInt32();
             break;
           }
           case 32: {
-            FailureCount = input.ReadInt32();
+            AtkHealth = input.ReadInt32();
             break;
           }
           case 40: {
-            TotalDuration = input.ReadInt64();
+            DefHealth = input.ReadInt32();
+            break;
+          }
+          case 48: {
+            CurrentRound = input.ReadInt32();
+            break;
+          }
+          case 56: {
+            IsFinished = input.ReadBool();
+            break;
+          }
+          case 64: {
+            Winner = input.ReadUInt32();
+            break;
+          }
+          case 72: {
+            Seed = input.ReadUInt64();
+            break;
+          }
+          case 80: {
+            RngState = input.ReadUInt64();
+            break;
+          }
+          case 88: {
+            StartFrame = input.ReadUInt64();
+            break;
+          }
+          case 98: {
+            events_.AddEntriesFrom(input, _repeated_events_codec);
+            break;
+          }
+          case 106: {
+            State = input.ReadString();
+            break;
+          }
+          case 112: {
+            EndRequested = input.ReadBool();
+            break;
+          }
+          case 120: {
+            AtkMaxHealth = input.ReadInt32();
+            break;
+          }
+          case 128: {
+            DefMaxHealth = input.ReadInt32();
+            break;
+          }
+          case 136: {
+            AtkAttack = input.ReadInt32();
+            break;
+          }
+          case 144: {
+            DefAttack = input.ReadInt32();
+            break;
+          }
+          case 154: {
+            units_.AddEntriesFrom(input, _repeated_units_codec);
+            break;
+          }
+          case 162: {
+            if (atkTeam_ == null) {
+              AtkTeam = new global::GoPureWithCsharp.Battle.Team();
+            }
+            input.ReadMessage(AtkTeam);
+            break;
+          }
+          case 170: {
+            if (defTeam_ == null) {
+              DefTeam = new global::GoPureWithCsharp.Battle.Team();
+            }
+            input.ReadMessage(DefTeam);
+            break;
+          }
+          case 176: {
+            ShardId = input.ReadUInt32();
+            break;
+          }
+          case 184: {
+            Timeout = input.ReadBool();
             break;
           }
         }
@@ -4388,24 +8996,102 @@ namespace GoPureWithCsharp.Battle {
           default:
             _unknownFields = pb::UnknownFieldSet.MergeFieldFrom(_unknownFields, ref input);
             break;
-          case 10: {
-            results_.AddEntriesFrom(ref input, _repeated_results_codec);
+          case 8: {
+            BattleId = input.ReadUInt32();
             break;
           }
-          case 18: {
-            BatchId = input.ReadString();
+          case 16: {
+            AtkTeamId = input.ReadUInt32();
             break;
           }
           case 24: {
-            SuccessCount = input.ReadInt32();
+            DefTeamId = input.ReadUInt32();
             break;
           }
           case 32: {
-            FailureCount = input.ReadInt32();
+            AtkHealth = input.ReadInt32();
             break;
           }
           case 40: {
-            TotalDuration = input.ReadInt64();
+            DefHealth = input.ReadInt32();
+            break;
+          }
+          case 48: {
+            CurrentRound = input.ReadInt32();
+            break;
+          }
+          case 56: {
+            IsFinished = input.ReadBool();
+            break;
+          }
+          case 64: {
+            Winner = input.ReadUInt32();
+            break;
+          }
+          case 72: {
+            Seed = input.ReadUInt64();
+            break;
+          }
+          case 80: {
+            RngState = input.ReadUInt64();
+            break;
+          }
+          case 88: {
+            StartFrame = input.ReadUInt64();
+            break;
+          }
+          case 98: {
+            events_.AddEntriesFrom(ref input, _repeated_events_codec);
+            break;
+          }
+          case 106: {
+            State = input.ReadString();
+            break;
+          }
+          case 112: {
+            EndRequested = input.ReadBool();
+            break;
+          }
+          case 120: {
+            AtkMaxHealth = input.ReadInt32();
+            break;
+          }
+          case 128: {
+            DefMaxHealth = input.ReadInt32();
+            break;
+          }
+          case 136: {
+            AtkAttack = input.ReadInt32();
+            break;
+          }
+          case 144: {
+            DefAttack = input.ReadInt32();
+            break;
+          }
+          case 154: {
+            units_.AddEntriesFrom(ref input, _repeated_units_codec);
+            break;
+          }
+          case 162: {
+            if (atkTeam_ == null) {
+              AtkTeam = new global::GoPureWithCsharp.Battle.Team();
+            }
+            input.ReadMessage(AtkTeam);
+            break;
+          }
+          case 170: {
+            if (defTeam_ == null) {
+              DefTeam = new global::GoPureWithCsharp.Battle.Team();
+            }
+            input.ReadMessage(DefTeam);
+            break;
+          }
+          case 176: {
+            ShardId = input.ReadUInt32();
+            break;
+          }
+          case 184: {
+            Timeout = input.ReadBool();
             break;
           }
         }
@@ -4416,23 +9102,23 @@ namespace GoPureWithCsharp.Battle {
   }
 
   /// <summary>
-  /// 战斗事件 (用于回放)
+  /// 战斗状态快照 (ExportBattleState / ImportBattleState)
   /// </summary>
-  public sealed partial class BattleEvent : pb::IMessage<BattleEvent>
+  public sealed partial class BattleStateSnapshot : pb::IMessage<BattleStateSnapshot>
   #if !GOOGLE_PROTOBUF_REFSTRUCT_COMPATIBILITY_MODE
       , pb::IBufferMessage
   #endif
   {
-    private static readonly pb::MessageParser<BattleEvent> _parser = new pb::MessageParser<BattleEvent>(() => new BattleEvent());
+    private static readonly pb::MessageParser<BattleStateSnapshot> _parser = new pb::MessageParser<BattleStateSnapshot>(() => new BattleStateSnapshot());
     private pb::UnknownFieldSet _unknownFields;
     [global::System.Diagnostics.DebuggerNonUserCodeAttribute]
     [global::System.CodeDom.Compiler.GeneratedCode("protoc", null)]
-    public static pb::MessageParser<BattleEvent> Parser { get { return _parser; } }
+    public static pb::MessageParser<BattleStateSnapshot> Parser { get { return _parser; } }
 
     [global::System.Diagnostics.DebuggerNonUserCodeAttribute]
     [global::System.CodeDom.Compiler.GeneratedCode("protoc", null)]
     public static pbr::MessageDescriptor Descriptor {
-      get { return global::GoPureWithCsharp.Battle.BattleReflection.Descriptor.MessageTypes[14]; }
+      get { return global::GoPureWithCsharp.Battle.BattleReflection.Descriptor.MessageTypes[24]; }
     }
 
     [global::System.Diagnostics.DebuggerNonUserCodeAttribute]
@@ -4443,7 +9129,7 @@ namespace GoPureWithCsharp.Battle {
 
     [global::System.Diagnostics.DebuggerNonUserCodeAttribute]
     [global::System.CodeDom.Compiler.GeneratedCode("protoc", null)]
-    public BattleEvent() {
+    public BattleStateSnapshot() {
       OnConstruction();
     }
 
@@ -4451,120 +9137,56 @@ namespace GoPureWithCsharp.Battle {
 
     [global::System.Diagnostics.DebuggerNonUserCodeAttribute]
     [global::System.CodeDom.Compiler.GeneratedCode("protoc", null)]
-    public BattleEvent(BattleEvent other) : this() {
+    public BattleStateSnapshot(BattleStateSnapshot other) : this() {
       timestamp_ = other.timestamp_;
-      eventType_ = other.eventType_;
-      performerId_ = other.performerId_;
-      targetId_ = other.targetId_;
-      value_ = other.value_;
-      extra_ = other.extra_.Clone();
+      battles_ = other.battles_.Clone();
       _unknownFields = pb::UnknownFieldSet.Clone(other._unknownFields);
     }
 
     [global::System.Diagnostics.DebuggerNonUserCodeAttribute]
     [global::System.CodeDom.Compiler.GeneratedCode("protoc", null)]
-    public BattleEvent Clone() {
-      return new BattleEvent(this);
+    public BattleStateSnapshot Clone() {
+      return new BattleStateSnapshot(this);
     }
 
     /// <summary>Field number for the "timestamp" field.</summary>
     public const int TimestampFieldNumber = 1;
     private long timestamp_;
     /// <summary>
-    /// 事件时间戳 (毫秒)
+    /// 快照时间戳 (毫秒)
     /// </summary>
     [global::System.Diagnostics.DebuggerNonUserCodeAttribute]
     [global::System.CodeDom.Compiler.GeneratedCode("protoc", null)]
     public long Timestamp {
       get { return timestamp_; }
       set {
-        timestamp_ = value;
-      }
-    }
-
-    /// <summary>Field number for the "event_type" field.</summary>
-    public const int EventTypeFieldNumber = 2;
-    private string eventType_ = "";
-    /// <summary>
-    /// 事件类型: "attack"|"skill"|"item"|"heal"|"status"|"end"
-    /// </summary>
-    [global::System.Diagnostics.DebuggerNonUserCodeAttribute]
-    [global::System.CodeDom.Compiler.GeneratedCode("protoc", null)]
-    public string EventType {
-      get { return eventType_; }
-      set {
-        eventType_ = pb::ProtoPreconditions.CheckNotNull(value, "value");
-      }
-    }
-
-    /// <summary>Field number for the "performer_id" field.</summary>
-    public const int PerformerIdFieldNumber = 3;
-    private uint performerId_;
-    /// <summary>
-    /// 执行者ID (角色/队伍)
-    /// </summary>
-    [global::System.Diagnostics.DebuggerNonUserCodeAttribute]
-    [global::System.CodeDom.Compiler.GeneratedCode("protoc", null)]
-    public uint PerformerId {
-      get { return performerId_; }
-      set {
-        performerId_ = value;
-      }
-    }
-
-    /// <summary>Field number for the "target_id" field.</summary>
-    public const int TargetIdFieldNumber = 4;
-    private uint targetId_;
-    /// <summary>
-    /// 目标ID
-    /// </summary>
-    [global::System.Diagnostics.DebuggerNonUserCodeAttribute]
-    [global::System.CodeDom.Compiler.GeneratedCode("protoc", null)]
-    public uint TargetId {
-      get { return targetId_; }
-      set {
-        targetId_ = value;
-      }
-    }
-
-    /// <summary>Field number for the "value" field.</summary>
-    public const int ValueFieldNumber = 5;
-    private int value_;
-    /// <summary>
-    /// 事件值 (伤害/治疗/数值)
-    /// </summary>
-    [global::System.Diagnostics.DebuggerNonUserCodeAttribute]
-    [global::System.CodeDom.Compiler.GeneratedCode("protoc", null)]
-    public int Value {
-      get { return value_; }
-      set {
-        value_ = value;
-      }
-    }
-
-    /// <summary>Field number for the "extra" field.</summary>
-    public const int ExtraFieldNumber = 6;
-    private static readonly pbc::MapField<string, string>.Codec _map_extra_codec
-        = new pbc::MapField<string, string>.Codec(pb::FieldCodec.ForString(10, ""), pb::FieldCodec.ForString(18, ""), 50);
-    private readonly pbc::MapField<string, string> extra_ = new pbc::MapField<string, string>();
+        timestamp_ = value;
+      }
+    }
+
+    /// <summary>Field number for the "battles" field.</summary>
+    public const int BattlesFieldNumber = 2;
+    private static readonly pb::FieldCodec<global::GoPureWithCsharp.Battle.BattleSnapshot> _repeated_battles_codec
+        = pb::FieldCodec.ForMessage(18, global::GoPureWithCsharp.Battle.BattleSnapshot.Parser);
+    private readonly pbc::RepeatedField<global::GoPureWithCsharp.Battle.BattleSnapshot> battles_ = new pbc::RepeatedField<global::GoPureWithCsharp.Battle.BattleSnapshot>();
     /// <summary>
-    /// 额外数据 (技能ID、道具ID等)
+    /// 所有存活的战斗
     /// </summary>
     [global::System.Diagnostics.DebuggerNonUserCodeAttribute]
     [global::System.CodeDom.Compiler.GeneratedCode("protoc", null)]
-    public pbc::MapField<string, string> Extra {
-      get { return extra_; }
+    public pbc::RepeatedField<global::GoPureWithCsharp.Battle.BattleSnapshot> Battles {
+      get { return battles_; }
     }
 
     [global::System.Diagnostics.DebuggerNonUserCodeAttribute]
     [global::System.CodeDom.Compiler.GeneratedCode("protoc", null)]
     public override bool Equals(object other) {
-      return Equals(other as BattleEvent);
+      return Equals(other as BattleStateSnapshot);
     }
 
     [global::System.Diagnostics.DebuggerNonUserCodeAttribute]
     [global::System.CodeDom.Compiler.GeneratedCode("protoc", null)]
-    public bool Equals(BattleEvent other) {
+    public bool Equals(BattleStateSnapshot other) {
       if (ReferenceEquals(other, null)) {
         return false;
       }
@@ -4572,11 +9194,7 @@ namespace GoPureWithCsharp.Battle {
         return true;
       }
       if (Timestamp != other.Timestamp) return false;
-      if (EventType != other.EventType) return false;
-      if (PerformerId != other.PerformerId) return false;
-      if (TargetId != other.TargetId) return false;
-      if (Value != other.Value) return false;
-      if (!Extra.Equals(other.Extra)) return false;
+      if(!battles_.Equals(other.battles_)) return false;
       return Equals(_unknownFields, other._unknownFields);
     }
 
@@ -4585,11 +9203,7 @@ namespace GoPureWithCsharp.Battle {
     public override int GetHashCode() {
       int hash = 1;
       if (Timestamp != 0L) hash ^= Timestamp.GetHashCode();
-      if (EventType.Length != 0) hash ^= EventType.GetHashCode();
-      if (PerformerId != 0) hash ^= PerformerId.GetHashCode();
-      if (TargetId != 0) hash ^= TargetId.GetHashCode();
-      if (Value != 0) hash ^= Value.GetHashCode();
-      hash ^= Extra.GetHashCode();
+      hash ^= battles_.GetHashCode();
       if (_unknownFields != null) {
         hash ^= _unknownFields.GetHashCode();
       }
@@ -4612,23 +9226,7 @@ namespace GoPureWithCsharp.Battle {
         output.WriteRawTag(8);
         output.WriteInt64(Timestamp);
       }
-      if (EventType.Length != 0) {
-        output.WriteRawTag(18);
-        output.WriteString(EventType);
-      }
-      if (PerformerId != 0) {
-        output.WriteRawTag(24);
-        output.WriteUInt32(PerformerId);
-      }
-      if (TargetId != 0) {
-        output.WriteRawTag(32);
-        output.WriteUInt32(TargetId);
-      }
-      if (Value != 0) {
-        output.WriteRawTag(40);
-        output.WriteInt32(Value);
-      }
-      extra_.WriteTo(output, _map_extra_codec);
+      battles_.WriteTo(output, _repeated_battles_codec);
       if (_unknownFields != null) {
         _unknownFields.WriteTo(output);
       }
@@ -4643,23 +9241,7 @@ namespace GoPureWithCsharp.Battle {
         output.WriteRawTag(8);
         output.WriteInt64(Timestamp);
       }
-      if (EventType.Length != 0) {
-        output.WriteRawTag(18);
-        output.WriteString(EventType);
-      }
-      if (PerformerId != 0) {
-        output.WriteRawTag(24);
-        output.WriteUInt32(PerformerId);
-      }
-      if (TargetId != 0) {
-        output.WriteRawTag(32);
-        output.WriteUInt32(TargetId);
-      }
-      if (Value != 0) {
-        output.WriteRawTag(40);
-        output.WriteInt32(Value);
-      }
-      extra_.WriteTo(ref output, _map_extra_codec);
+      battles_.WriteTo(ref output, _repeated_battles_codec);
       if (_unknownFields != null) {
         _unknownFields.WriteTo(ref output);
       }
@@ -4673,19 +9255,7 @@ namespace GoPureWithCsharp.Battle {
       if (Timestamp != 0L) {
         size += 1 + pb::CodedOutputStream.ComputeInt64Size(Timestamp);
       }
-      if (EventType.Length != 0) {
-        size += 1 + pb::CodedOutputStream.ComputeStringSize(EventType);
-      }
-      if (PerformerId != 0) {
-        size += 1 + pb::CodedOutputStream.ComputeUInt32Size(PerformerId);
-      }
-      if (TargetId != 0) {
-        size += 1 + pb::CodedOutputStream.ComputeUInt32Size(TargetId);
-      }
-      if (Value != 0) {
-        size += 1 + pb::CodedOutputStream.ComputeInt32Size(Value);
-      }
-      size += extra_.CalculateSize(_map_extra_codec);
+      size += battles_.CalculateSize(_repeated_battles_codec);
       if (_unknownFields != null) {
         size += _unknownFields.CalculateSize();
       }
@@ -4694,26 +9264,14 @@ namespace GoPureWithCsharp.Battle {
 
     [global::System.Diagnostics.DebuggerNonUserCodeAttribute]
     [global::System.CodeDom.Compiler.GeneratedCode("protoc", null)]
-    public void MergeFrom(BattleEvent other) {
+    public void MergeFrom(BattleStateSnapshot other) {
       if (other == null) {
         return;
       }
       if (other.Timestamp != 0L) {
         Timestamp = other.Timestamp;
       }
-      if (other.EventType.Length != 0) {
-        EventType = other.EventType;
-      }
-      if (other.PerformerId != 0) {
-        PerformerId = other.PerformerId;
-      }
-      if (other.TargetId != 0) {
-        TargetId = other.TargetId;
-      }
-      if (other.Value != 0) {
-        Value = other.Value;
-      }
-      extra_.Add(other.extra_);
+      battles_.Add(other.battles_);
       _unknownFields = pb::UnknownFieldSet.MergeFrom(_unknownFields, other._unknownFields);
     }
 
@@ -4734,23 +9292,7 @@ namespace GoPureWithCsharp.Battle {
             break;
           }
           case 18: {
-            EventType = input.ReadString();
-            break;
-          }
-          case 24: {
-            PerformerId = input.ReadUInt32();
-            break;
-          }
-          case 32: {
-            TargetId = input.ReadUInt32();
-            break;
-          }
-          case 40: {
-            Value = input.ReadInt32();
-            break;
-          }
-          case 50: {
-            extra_.AddEntriesFrom(input, _map_extra_codec);
+            battles_.AddEntriesFrom(input, _repeated_battles_codec);
             break;
           }
         }
@@ -4773,23 +9315,7 @@ namespace GoPureWithCsharp.Battle {
             break;
           }
           case 18: {
-            EventType = input.ReadString();
-            break;
-          }
-          case 24: {
-            PerformerId = input.ReadUInt32();
-            break;
-          }
-          case 32: {
-            TargetId = input.ReadUInt32();
-            break;
-          }
-          case 40: {
-            Value = input.ReadInt32();
-            break;
-          }
-          case 50: {
-            extra_.AddEntriesFrom(ref input, _map_extra_codec);
+            battles_.AddEntriesFrom(ref input, _repeated_battles_codec);
             break;
           }
         }
@@ -4800,23 +9326,23 @@ namespace GoPureWithCsharp.Battle {
   }
 
   /// <summary>
-  /// 战斗回放
+  /// C# 库信息，Go 在加载库时校验
   /// </summary>
-  public sealed partial class BattleReplay : pb::IMessage<BattleReplay>
+  public sealed partial class LibraryInfo : pb::IMessage<LibraryInfo>
   #if !GOOGLE_PROTOBUF_REFSTRUCT_COMPATIBILITY_MODE
       , pb::IBufferMessage
   #endif
   {
-    private static readonly pb::MessageParser<BattleReplay> _parser = new pb::MessageParser<BattleReplay>(() => new BattleReplay());
+    private static readonly pb::MessageParser<LibraryInfo> _parser = new pb::MessageParser<LibraryInfo>(() => new LibraryInfo());
     private pb::UnknownFieldSet _unknownFields;
     [global::System.Diagnostics.DebuggerNonUserCodeAttribute]
     [global::System.CodeDom.Compiler.GeneratedCode("protoc", null)]
-    public static pb::MessageParser<BattleReplay> Parser { get { return _parser; } }
+    public static pb::MessageParser<LibraryInfo> Parser { get { return _parser; } }
 
     [global::System.Diagnostics.DebuggerNonUserCodeAttribute]
     [global::System.CodeDom.Compiler.GeneratedCode("protoc", null)]
     public static pbr::MessageDescriptor Descriptor {
-      get { return global::GoPureWithCsharp.Battle.BattleReflection.Descriptor.MessageTypes[15]; }
+      get { return global::GoPureWithCsharp.Battle.BattleReflection.Descriptor.MessageTypes[25]; }
     }
 
     [global::System.Diagnostics.DebuggerNonUserCodeAttribute]
@@ -4827,7 +9353,7 @@ namespace GoPureWithCsharp.Battle {
 
     [global::System.Diagnostics.DebuggerNonUserCodeAttribute]
     [global::System.CodeDom.Compiler.GeneratedCode("protoc", null)]
-    public BattleReplay() {
+    public LibraryInfo() {
       OnConstruction();
     }
 
@@ -4835,181 +9361,109 @@ namespace GoPureWithCsharp.Battle {
 
     [global::System.Diagnostics.DebuggerNonUserCodeAttribute]
     [global::System.CodeDom.Compiler.GeneratedCode("protoc", null)]
-    public BattleReplay(BattleReplay other) : this() {
-      battleId_ = other.battleId_;
-      startTime_ = other.startTime_;
-      endTime_ = other.endTime_;
-      atkTeam_ = other.atkTeam_ != null ? other.atkTeam_.Clone() : null;
-      defTeam_ = other.defTeam_ != null ? other.defTeam_.Clone() : null;
-      events_ = other.events_.Clone();
-      result_ = other.result_ != null ? other.result_.Clone() : null;
+    public LibraryInfo(LibraryInfo other) : this() {
       version_ = other.version_;
+      abiVersion_ = other.abiVersion_;
+      schemaHash_ = other.schemaHash_;
+      features_ = other.features_.Clone();
       _unknownFields = pb::UnknownFieldSet.Clone(other._unknownFields);
     }
 
     [global::System.Diagnostics.DebuggerNonUserCodeAttribute]
     [global::System.CodeDom.Compiler.GeneratedCode("protoc", null)]
-    public BattleReplay Clone() {
-      return new BattleReplay(this);
-    }
-
-    /// <summary>Field number for the "battle_id" field.</summary>
-    public const int BattleIdFieldNumber = 1;
-    private uint battleId_;
-    /// <summary>
-    /// 战斗ID
-    /// </summary>
-    [global::System.Diagnostics.DebuggerNonUserCodeAttribute]
-    [global::System.CodeDom.Compiler.GeneratedCode("protoc", null)]
-    public uint BattleId {
-      get { return battleId_; }
-      set {
-        battleId_ = value;
-      }
-    }
-
-    /// <summary>Field number for the "start_time" field.</summary>
-    public const int StartTimeFieldNumber = 2;
-    private long startTime_;
-    /// <summary>
-    /// 战斗开始时间戳
-    /// </summary>
-    [global::System.Diagnostics.DebuggerNonUserCodeAttribute]
-    [global::System.CodeDom.Compiler.GeneratedCode("protoc", null)]
-    public long StartTime {
-      get { return startTime_; }
-      set {
-        startTime_ = value;
-      }
-    }
-
-    /// <summary>Field number for the "end_time" field.</summary>
-    public const int EndTimeFieldNumber = 3;
-    private long endTime_;
-    /// <summary>
-    /// 战斗结束时间戳
-    /// </summary>
-    [global::System.Diagnostics.DebuggerNonUserCodeAttribute]
-    [global::System.CodeDom.Compiler.GeneratedCode("protoc", null)]
-    public long EndTime {
-      get { return endTime_; }
-      set {
-        endTime_ = value;
-      }
+    public LibraryInfo Clone() {
+      return new LibraryInfo(this);
     }
 
-    /// <summary>Field number for the "atk_team" field.</summary>
-    public const int AtkTeamFieldNumber = 4;
-    private global::GoPureWithCsharp.Battle.Team atkTeam_;
+    /// <summary>Field number for the "version" field.</summary>
+    public const int VersionFieldNumber = 1;
+    private string version_ = "";
     /// <summary>
-    /// 攻击方队伍
+    /// 库版本 (与 GetLibVersion 一致)
     /// </summary>
     [global::System.Diagnostics.DebuggerNonUserCodeAttribute]
     [global::System.CodeDom.Compiler.GeneratedCode("protoc", null)]
-    public global::GoPureWithCsharp.Battle.Team AtkTeam {
-      get { return atkTeam_; }
+    public string Version {
+      get { return version_; }
       set {
-        atkTeam_ = value;
+        version_ = pb::ProtoPreconditions.CheckNotNull(value, "value");
       }
     }
 
-    /// <summary>Field number for the "def_team" field.</summary>
-    public const int DefTeamFieldNumber = 5;
-    private global::GoPureWithCsharp.Battle.Team defTeam_;
+    /// <summary>Field number for the "abi_version" field.</summary>
+    public const int AbiVersionFieldNumber = 2;
+    private uint abiVersion_;
     /// <summary>
-    /// 防守方队伍
+    /// 导出函数 ABI 版本，导出函数签名变化时递增
     /// </summary>
     [global::System.Diagnostics.DebuggerNonUserCodeAttribute]
     [global::System.CodeDom.Compiler.GeneratedCode("protoc", null)]
-    public global::GoPureWithCsharp.Battle.Team DefTeam {
-      get { return defTeam_; }
+    public uint AbiVersion {
+      get { return abiVersion_; }
       set {
-        defTeam_ = value;
+        abiVersion_ = value;
       }
     }
 
-    /// <summary>Field number for the "events" field.</summary>
-    public const int EventsFieldNumber = 6;
-    private static readonly pb::FieldCodec<global::GoPureWithCsharp.Battle.BattleEvent> _repeated_events_codec
-        = pb::FieldCodec.ForMessage(50, global::GoPureWithCsharp.Battle.BattleEvent.Parser);
-    private readonly pbc::RepeatedField<global::GoPureWithCsharp.Battle.BattleEvent> events_ = new pbc::RepeatedField<global::GoPureWithCsharp.Battle.BattleEvent>();
-    /// <summary>
-    /// 事件时间线 (按时间戳排序)
-    /// </summary>
-    [global::System.Diagnostics.DebuggerNonUserCodeAttribute]
-    [global::System.CodeDom.Compiler.GeneratedCode("protoc", null)]
-    public pbc::RepeatedField<global::GoPureWithCsharp.Battle.BattleEvent> Events {
-      get { return events_; }
-    }
-
-    /// <summary>Field number for the "result" field.</summary>
-    public const int ResultFieldNumber = 7;
-    private global::GoPureWithCsharp.Battle.BattleResult result_;
+    /// <summary>Field number for the "schema_hash" field.</summary>
+    public const int SchemaHashFieldNumber = 3;
+    private string schemaHash_ = "";
     /// <summary>
-    /// 最终战斗结果
+    /// battle.proto 描述符的 SHA-256 (十六进制)
     /// </summary>
     [global::System.Diagnostics.DebuggerNonUserCodeAttribute]
     [global::System.CodeDom.Compiler.GeneratedCode("protoc", null)]
-    public global::GoPureWithCsharp.Battle.BattleResult Result {
-      get { return result_; }
+    public string SchemaHash {
+      get { return schemaHash_; }
       set {
-        result_ = value;
+        schemaHash_ = pb::ProtoPreconditions.CheckNotNull(value, "value");
       }
     }
 
-    /// <summary>Field number for the "version" field.</summary>
-    public const int VersionFieldNumber = 8;
-    private string version_ = "";
+    /// <summary>Field number for the "features" field.</summary>
+    public const int FeaturesFieldNumber = 4;
+    private static readonly pb::FieldCodec<string> _repeated_features_codec
+        = pb::FieldCodec.ForString(34);
+    private readonly pbc::RepeatedField<string> features_ = new pbc::RepeatedField<string>();
     /// <summary>
-    /// 回放格式版本 (例如 "1.0")
+    /// 支持的可选特性 (例如 "hot_reload")
     /// </summary>
     [global::System.Diagnostics.DebuggerNonUserCodeAttribute]
     [global::System.CodeDom.Compiler.GeneratedCode("protoc", null)]
-    public string Version {
-      get { return version_; }
-      set {
-        version_ = pb::ProtoPreconditions.CheckNotNull(value, "value");
-      }
+    public pbc::RepeatedField<string> Features {
+      get { return features_; }
     }
 
     [global::System.Diagnostics.DebuggerNonUserCodeAttribute]
     [global::System.CodeDom.Compiler.GeneratedCode("protoc", null)]
     public override bool Equals(object other) {
-      return Equals(other as BattleReplay);
+      return Equals(other as LibraryInfo);
     }
 
     [global::System.Diagnostics.DebuggerNonUserCodeAttribute]
     [global::System.CodeDom.Compiler.GeneratedCode("protoc", null)]
-    public bool Equals(BattleReplay other) {
+    public bool Equals(LibraryInfo other) {
       if (ReferenceEquals(other, null)) {
         return false;
       }
       if (ReferenceEquals(other, this)) {
         return true;
       }
-      if (BattleId != other.BattleId) return false;
-      if (StartTime != other.StartTime) return false;
-      if (EndTime != other.EndTime) return false;
-      if (!object.Equals(AtkTeam, other.AtkTeam)) return false;
-      if (!object.Equals(DefTeam, other.DefTeam)) return false;
-      if(!events_.Equals(other.events_)) return false;
-      if (!object.Equals(Result, other.Result)) return false;
       if (Version != other.Version) return false;
+      if (AbiVersion != other.AbiVersion) return false;
+      if (SchemaHash != other.SchemaHash) return false;
+      if(!features_.Equals(other.features_)) return false;
       return Equals(_unknownFields, other._unknownFields);
-    }
-
-    [global::System.Diagnostics.DebuggerNonUserCodeAttribute]
-    [global::System.CodeDom.Compiler.GeneratedCode("protoc", null)]
-    public override int GetHashCode() {
-      int hash = 1;
-      if (BattleId != 0) hash ^= BattleId.GetHashCode();
-      if (StartTime != 0L) hash ^= StartTime.GetHashCode();
-      if (EndTime != 0L) hash ^= EndTime.GetHashCode();
-      if (atkTeam_ != null) hash ^= AtkTeam.GetHashCode();
-      if (defTeam_ != null) hash ^= DefTeam.GetHashCode();
-      hash ^= events_.GetHashCode();
-      if (result_ != null) hash ^= Result.GetHashCode();
+    }
+
+    [global::System.Diagnostics.DebuggerNonUserCodeAttribute]
+    [global::System.CodeDom.Compiler.GeneratedCode("protoc", null)]
+    public override int GetHashCode() {
+      int hash = 1;
       if (Version.Length != 0) hash ^= Version.GetHashCode();
+      if (AbiVersion != 0) hash ^= AbiVersion.GetHashCode();
+      if (SchemaHash.Length != 0) hash ^= SchemaHash.GetHashCode();
+      hash ^= features_.GetHashCode();
       if (_unknownFields != null) {
         hash ^= _unknownFields.GetHashCode();
       }
@@ -5028,35 +9482,19 @@ namespace GoPureWithCsharp.Battle {
     #if !GOOGLE_PROTOBUF_REFSTRUCT_COMPATIBILITY_MODE
       output.WriteRawMessage(this);
     #else
-      if (BattleId != 0) {
-        output.WriteRawTag(8);
-        output.WriteUInt32(BattleId);
+      if (Version.Length != 0) {
+        output.WriteRawTag(10);
+        output.WriteString(Version);
       }
-      if (StartTime != 0L) {
+      if (AbiVersion != 0) {
         output.WriteRawTag(16);
-        output.WriteInt64(StartTime);
-      }
-      if (EndTime != 0L) {
-        output.WriteRawTag(24);
-        output.WriteInt64(EndTime);
-      }
-      if (atkTeam_ != null) {
-        output.WriteRawTag(34);
-        output.WriteMessage(AtkTeam);
-      }
-      if (defTeam_ != null) {
-        output.WriteRawTag(42);
-        output.WriteMessage(DefTeam);
-      }
-      events_.WriteTo(output, _repeated_events_codec);
-      if (result_ != null) {
-        output.WriteRawTag(58);
-        output.WriteMessage(Result);
+        output.WriteUInt32(AbiVersion);
       }
-      if (Version.Length != 0) {
-        output.WriteRawTag(66);
-        output.WriteString(Version);
+      if (SchemaHash.Length != 0) {
+        output.WriteRawTag(26);
+        output.WriteString(SchemaHash);
       }
+      features_.WriteTo(output, _repeated_features_codec);
       if (_unknownFields != null) {
         _unknownFields.WriteTo(output);
       }
@@ -5067,35 +9505,19 @@ namespace GoPureWithCsharp.Battle {
     [global::System.Diagnostics.DebuggerNonUserCodeAttribute]
     [global::System.CodeDom.Compiler.GeneratedCode("protoc", null)]
     void pb::IBufferMessage.InternalWriteTo(ref pb::WriteContext output) {
-      if (BattleId != 0) {
-        output.WriteRawTag(8);
-        output.WriteUInt32(BattleId);
+      if (Version.Length != 0) {
+        output.WriteRawTag(10);
+        output.WriteString(Version);
       }
-      if (StartTime != 0L) {
+      if (AbiVersion != 0) {
         output.WriteRawTag(16);
-        output.WriteInt64(StartTime);
-      }
-      if (EndTime != 0L) {
-        output.WriteRawTag(24);
-        output.WriteInt64(EndTime);
-      }
-      if (atkTeam_ != null) {
-        output.WriteRawTag(34);
-        output.WriteMessage(AtkTeam);
-      }
-      if (defTeam_ != null) {
-        output.WriteRawTag(42);
-        output.WriteMessage(DefTeam);
-      }
-      events_.WriteTo(ref output, _repeated_events_codec);
-      if (result_ != null) {
-        output.WriteRawTag(58);
-        output.WriteMessage(Result);
+        output.WriteUInt32(AbiVersion);
       }
-      if (Version.Length != 0) {
-        output.WriteRawTag(66);
-        output.WriteString(Version);
+      if (SchemaHash.Length != 0) {
+        output.WriteRawTag(26);
+        output.WriteString(SchemaHash);
       }
+      features_.WriteTo(ref output, _repeated_features_codec);
       if (_unknownFields != null) {
         _unknownFields.WriteTo(ref output);
       }
@@ -5106,28 +9528,16 @@ namespace GoPureWithCsharp.Battle {
     [global::System.CodeDom.Compiler.GeneratedCode("protoc", null)]
     public int CalculateSize() {
       int size = 0;
-      if (BattleId != 0) {
-        size += 1 + pb::CodedOutputStream.ComputeUInt32Size(BattleId);
-      }
-      if (StartTime != 0L) {
-        size += 1 + pb::CodedOutputStream.ComputeInt64Size(StartTime);
-      }
-      if (EndTime != 0L) {
-        size += 1 + pb::CodedOutputStream.ComputeInt64Size(EndTime);
-      }
-      if (atkTeam_ != null) {
-        size += 1 + pb::CodedOutputStream.ComputeMessageSize(AtkTeam);
-      }
-      if (defTeam_ != null) {
-        size += 1 + pb::CodedOutputStream.ComputeMessageSize(DefTeam);
-      }
-      size += events_.CalculateSize(_repeated_events_codec);
-      if (result_ != null) {
-        size += 1 + pb::CodedOutputStream.ComputeMessageSize(Result);
-      }
       if (Version.Length != 0) {
         size += 1 + pb::CodedOutputStream.ComputeStringSize(Version);
       }
+      if (AbiVersion != 0) {
+        size += 1 + pb::CodedOutputStream.ComputeUInt32Size(AbiVersion);
+      }
+      if (SchemaHash.Length != 0) {
+        size += 1 + pb::CodedOutputStream.ComputeStringSize(SchemaHash);
+      }
+      size += features_.CalculateSize(_repeated_features_codec);
       if (_unknownFields != null) {
         size += _unknownFields.CalculateSize();
       }
@@ -5136,41 +9546,20 @@ namespace GoPureWithCsharp.Battle {
 
     [global::System.Diagnostics.DebuggerNonUserCodeAttribute]
     [global::System.CodeDom.Compiler.GeneratedCode("protoc", null)]
-    public void MergeFrom(BattleReplay other) {
+    public void MergeFrom(LibraryInfo other) {
       if (other == null) {
         return;
       }
-      if (other.BattleId != 0) {
-        BattleId = other.BattleId;
-      }
-      if (other.StartTime != 0L) {
-        StartTime = other.StartTime;
-      }
-      if (other.EndTime != 0L) {
-        EndTime = other.EndTime;
-      }
-      if (other.atkTeam_ != null) {
-        if (atkTeam_ == null) {
-          AtkTeam = new global::GoPureWithCsharp.Battle.Team();
-        }
-        AtkTeam.MergeFrom(other.AtkTeam);
-      }
-      if (other.defTeam_ != null) {
-        if (defTeam_ == null) {
-          DefTeam = new global::GoPureWithCsharp.Battle.Team();
-        }
-        DefTeam.MergeFrom(other.DefTeam);
-      }
-      events_.Add(other.events_);
-      if (other.result_ != null) {
-        if (result_ == null) {
-          Result = new global::GoPureWithCsharp.Battle.BattleResult();
-        }
-        Result.MergeFrom(other.Result);
-      }
       if (other.Version.Length != 0) {
         Version = other.Version;
       }
+      if (other.AbiVersion != 0) {
+        AbiVersion = other.AbiVersion;
+      }
+      if (other.SchemaHash.Length != 0) {
+        SchemaHash = other.SchemaHash;
+      }
+      features_.Add(other.features_);
       _unknownFields = pb::UnknownFieldSet.MergeFrom(_unknownFields, other._unknownFields);
     }
 
@@ -5186,45 +9575,20 @@ namespace GoPureWithCsharp.Battle {
           default:
             _unknownFields = pb::UnknownFieldSet.MergeFieldFrom(_unknownFields, input);
             break;
-          case 8: {
-            BattleId = input.ReadUInt32();
+          case 10: {
+            Version = input.ReadString();
             break;
           }
           case 16: {
-            StartTime = input.ReadInt64();
+            AbiVersion = input.ReadUInt32();
             break;
           }
-          case 24: {
-            EndTime = input.ReadInt64();
+          case 26: {
+            SchemaHash = input.ReadString();
             break;
           }
           case 34: {
-            if (atkTeam_ == null) {
-              AtkTeam = new global::GoPureWithCsharp.Battle.Team();
-            }
-            input.ReadMessage(AtkTeam);
-            break;
-          }
-          case 42: {
-            if (defTeam_ == null) {
-              DefTeam = new global::GoPureWithCsharp.Battle.Team();
-            }
-            input.ReadMessage(DefTeam);
-            break;
-          }
-          case 50: {
-            events_.AddEntriesFrom(input, _repeated_events_codec);
-            break;
-          }
-          case 58: {
-            if (result_ == null) {
-              Result = new global::GoPureWithCsharp.Battle.BattleResult();
-            }
-            input.ReadMessage(Result);
-            break;
-          }
-          case 66: {
-            Version = input.ReadString();
+            features_.AddEntriesFrom(input, _repeated_features_codec);
             break;
           }
         }
@@ -5242,45 +9606,20 @@ namespace GoPureWithCsharp.Battle {
           default:
             _unknownFields = pb::UnknownFieldSet.MergeFieldFrom(_unknownFields, ref input);
             break;
-          case 8: {
-            BattleId = input.ReadUInt32();
+          case 10: {
+            Version = input.ReadString();
             break;
           }
           case 16: {
-            StartTime = input.ReadInt64();
+            AbiVersion = input.ReadUInt32();
             break;
           }
-          case 24: {
-            EndTime = input.ReadInt64();
+          case 26: {
+            SchemaHash = input.ReadString();
             break;
           }
           case 34: {
-            if (atkTeam_ == null) {
-              AtkTeam = new global::GoPureWithCsharp.Battle.Team();
-            }
-            input.ReadMessage(AtkTeam);
-            break;
-          }
-          case 42: {
-            if (defTeam_ == null) {
-              DefTeam = new global::GoPureWithCsharp.Battle.Team();
-            }
-            input.ReadMessage(DefTeam);
-            break;
-          }
-          case 50: {
-            events_.AddEntriesFrom(ref input, _repeated_events_codec);
-            break;
-          }
-          case 58: {
-            if (result_ == null) {
-              Result = new global::GoPureWithCsharp.Battle.BattleResult();
-            }
-            input.ReadMessage(Result);
-            break;
-          }
-          case 66: {
-            Version = input.ReadString();
+            features_.AddEntriesFrom(ref input, _repeated_features_codec);
             break;
           }
         }
@@ -5291,23 +9630,23 @@ namespace GoPureWithCsharp.Battle {
   }
 
   /// <summary>
-  /// 战斗进度报告
+  /// C# 托管异常报告
   /// </summary>
-  public sealed partial class ProgressReport : pb::IMessage<ProgressReport>
+  public sealed partial class ManagedException : pb::IMessage<ManagedException>
   #if !GOOGLE_PROTOBUF_REFSTRUCT_COMPATIBILITY_MODE
       , pb::IBufferMessage
   #endif
   {
-    private static readonly pb::MessageParser<ProgressReport> _parser = new pb::MessageParser<ProgressReport>(() => new ProgressReport());
+    private static readonly pb::MessageParser<ManagedException> _parser = new pb::MessageParser<ManagedException>(() => new ManagedException());
     private pb::UnknownFieldSet _unknownFields;
     [global::System.Diagnostics.DebuggerNonUserCodeAttribute]
     [global::System.CodeDom.Compiler.GeneratedCode("protoc", null)]
-    public static pb::MessageParser<ProgressReport> Parser { get { return _parser; } }
+    public static pb::MessageParser<ManagedException> Parser { get { return _parser; } }
 
     [global::System.Diagnostics.DebuggerNonUserCodeAttribute]
     [global::System.CodeDom.Compiler.GeneratedCode("protoc", null)]
     public static pbr::MessageDescriptor Descriptor {
-      get { return global::GoPureWithCsharp.Battle.BattleReflection.Descriptor.MessageTypes[16]; }
+      get { return global::GoPureWithCsharp.Battle.BattleReflection.Descriptor.MessageTypes[26]; }
     }
 
     [global::System.Diagnostics.DebuggerNonUserCodeAttribute]
@@ -5318,7 +9657,7 @@ namespace GoPureWithCsharp.Battle {
 
     [global::System.Diagnostics.DebuggerNonUserCodeAttribute]
     [global::System.CodeDom.Compiler.GeneratedCode("protoc", null)]
-    public ProgressReport() {
+    public ManagedException() {
       OnConstruction();
     }
 
@@ -5326,86 +9665,118 @@ namespace GoPureWithCsharp.Battle {
 
     [global::System.Diagnostics.DebuggerNonUserCodeAttribute]
     [global::System.CodeDom.Compiler.GeneratedCode("protoc", null)]
-    public ProgressReport(ProgressReport other) : this() {
+    public ManagedException(ManagedException other) : this() {
+      type_ = other.type_;
+      message_ = other.message_;
+      stackTrace_ = other.stackTrace_;
       battleId_ = other.battleId_;
-      progressPercent_ = other.progressPercent_;
-      currentRound_ = other.currentRound_;
-      status_ = other.status_ != null ? other.status_.Clone() : null;
+      export_ = other.export_;
+      inner_ = other.inner_;
       timestamp_ = other.timestamp_;
       _unknownFields = pb::UnknownFieldSet.Clone(other._unknownFields);
     }
 
     [global::System.Diagnostics.DebuggerNonUserCodeAttribute]
     [global::System.CodeDom.Compiler.GeneratedCode("protoc", null)]
-    public ProgressReport Clone() {
-      return new ProgressReport(this);
+    public ManagedException Clone() {
+      return new ManagedException(this);
     }
 
-    /// <summary>Field number for the "battle_id" field.</summary>
-    public const int BattleIdFieldNumber = 1;
-    private uint battleId_;
+    /// <summary>Field number for the "type" field.</summary>
+    public const int TypeFieldNumber = 1;
+    private string type_ = "";
     /// <summary>
-    /// 战斗ID
+    /// 异常类型全名 (例如 System.IndexOutOfRangeException)
     /// </summary>
     [global::System.Diagnostics.DebuggerNonUserCodeAttribute]
     [global::System.CodeDom.Compiler.GeneratedCode("protoc", null)]
-    public uint BattleId {
-      get { return battleId_; }
+    public string Type {
+      get { return type_; }
       set {
-        battleId_ = value;
+        type_ = pb::ProtoPreconditions.CheckNotNull(value, "value");
       }
     }
 
-    /// <summary>Field number for the "progress_percent" field.</summary>
-    public const int ProgressPercentFieldNumber = 2;
-    private int progressPercent_;
+    /// <summary>Field number for the "message" field.</summary>
+    public const int MessageFieldNumber = 2;
+    private string message_ = "";
     /// <summary>
-    /// 进度百分比 (0-100)
+    /// 异常消息
     /// </summary>
     [global::System.Diagnostics.DebuggerNonUserCodeAttribute]
     [global::System.CodeDom.Compiler.GeneratedCode("protoc", null)]
-    public int ProgressPercent {
-      get { return progressPercent_; }
+    public string Message {
+      get { return message_; }
       set {
-        progressPercent_ = value;
+        message_ = pb::ProtoPreconditions.CheckNotNull(value, "value");
       }
     }
 
-    /// <summary>Field number for the "current_round" field.</summary>
-    public const int CurrentRoundFieldNumber = 3;
-    private int currentRound_;
+    /// <summary>Field number for the "stack_trace" field.</summary>
+    public const int StackTraceFieldNumber = 3;
+    private string stackTrace_ = "";
     /// <summary>
-    /// 当前回合
+    /// 托管调用栈
     /// </summary>
     [global::System.Diagnostics.DebuggerNonUserCodeAttribute]
     [global::System.CodeDom.Compiler.GeneratedCode("protoc", null)]
-    public int CurrentRound {
-      get { return currentRound_; }
+    public string StackTrace {
+      get { return stackTrace_; }
       set {
-        currentRound_ = value;
+        stackTrace_ = pb::ProtoPreconditions.CheckNotNull(value, "value");
       }
     }
 
-    /// <summary>Field number for the "status" field.</summary>
-    public const int StatusFieldNumber = 4;
-    private global::GoPureWithCsharp.Battle.BattleStatus status_;
+    /// <summary>Field number for the "battle_id" field.</summary>
+    public const int BattleIdFieldNumber = 4;
+    private uint battleId_;
     /// <summary>
-    /// 当前战斗状态
+    /// 异常所属的战斗ID (与战斗无关时为 0)
     /// </summary>
     [global::System.Diagnostics.DebuggerNonUserCodeAttribute]
     [global::System.CodeDom.Compiler.GeneratedCode("protoc", null)]
-    public global::GoPureWithCsharp.Battle.BattleStatus Status {
-      get { return status_; }
+    public uint BattleId {
+      get { return battleId_; }
       set {
-        status_ = value;
+        battleId_ = value;
+      }
+    }
+
+    /// <summary>Field number for the "export" field.</summary>
+    public const int ExportFieldNumber = 5;
+    private string export_ = "";
+    /// <summary>
+    /// 抛出异常时正在执行的导出函数
+    /// </summary>
+    [global::System.Diagnostics.DebuggerNonUserCodeAttribute]
+    [global::System.CodeDom.Compiler.GeneratedCode("protoc", null)]
+    public string Export {
+      get { return export_; }
+      set {
+        export_ = pb::ProtoPreconditions.CheckNotNull(value, "value");
+      }
+    }
+
+    /// <summary>Field number for the "inner" field.</summary>
+    public const int InnerFieldNumber = 6;
+    private string inner_ = "";
+    /// <summary>
+    /// 内部异常 (InnerException.ToString()，没有时为空)
+    /// </summary>
+    [global::System.Diagnostics.DebuggerNonUserCodeAttribute]
+    [global::System.CodeDom.Compiler.GeneratedCode("protoc", null)]
+    public string Inner {
+      get { return inner_; }
+      set {
+        inner_ = pb::ProtoPreconditions.CheckNotNull(value, "value");
       }
     }
 
     /// <summary>Field number for the "timestamp" field.</summary>
-    public const int TimestampFieldNumber = 5;
+    public const int TimestampFieldNumber = 7;
     private long timestamp_;
     /// <summary>
-    /// 时间戳
+    /// 时间戳 (毫秒)
     /// </summary>
     [global::System.Diagnostics.DebuggerNonUserCodeAttribute]
     [global::System.CodeDom.Compiler.GeneratedCode("protoc", null)]
@@ -5419,22 +9790,24 @@ namespace GoPureWithCsharp.Battle {
     [global::System.Diagnostics.DebuggerNonUserCodeAttribute]
     [global::System.CodeDom.Compiler.GeneratedCode("protoc", null)]
     public override bool Equals(object other) {
-      return Equals(other as ProgressReport);
+      return Equals(other as ManagedException);
     }
 
     [global::System.Diagnostics.DebuggerNonUserCodeAttribute]
     [global::System.CodeDom.Compiler.GeneratedCode("protoc", null)]
-    public bool Equals(ProgressReport other) {
+    public bool Equals(ManagedException other) {
       if (ReferenceEquals(other, null)) {
         return false;
       }
       if (ReferenceEquals(other, this)) {
         return true;
       }
+      if (Type != other.Type) return false;
+      if (Message != other.Message) return false;
+      if (StackTrace != other.StackTrace) return false;
       if (BattleId != other.BattleId) return false;
-      if (ProgressPercent != other.ProgressPercent) return false;
-      if (CurrentRound != other.CurrentRound) return false;
-      if (!object.Equals(Status, other.Status)) return false;
+      if (Export != other.Export) return false;
+      if (Inner != other.Inner) return false;
       if (Timestamp != other.Timestamp) return false;
       return Equals(_unknownFields, other._unknownFields);
     }
@@ -5443,10 +9816,12 @@ namespace GoPureWithCsharp.Battle {
     [global::System.CodeDom.Compiler.GeneratedCode("protoc", null)]
     public override int GetHashCode() {
       int hash = 1;
+      if (Type.Length != 0) hash ^= Type.GetHashCode();
+      if (Message.Length != 0) hash ^= Message.GetHashCode();
+      if (StackTrace.Length != 0) hash ^= StackTrace.GetHashCode();
       if (BattleId != 0) hash ^= BattleId.GetHashCode();
-      if (ProgressPercent != 0) hash ^= ProgressPercent.GetHashCode();
-      if (CurrentRound != 0) hash ^= CurrentRound.GetHashCode();
-      if (status_ != null) hash ^= Status.GetHashCode();
+      if (Export.Length != 0) hash ^= Export.GetHashCode();
+      if (Inner.Length != 0) hash ^= Inner.GetHashCode();
       if (Timestamp != 0L) hash ^= Timestamp.GetHashCode();
       if (_unknownFields != null) {
         hash ^= _unknownFields.GetHashCode();
@@ -5466,24 +9841,32 @@ namespace GoPureWithCsharp.Battle {
     #if !GOOGLE_PROTOBUF_REFSTRUCT_COMPATIBILITY_MODE
       output.WriteRawMessage(this);
     #else
+      if (Type.Length != 0) {
+        output.WriteRawTag(10);
+        output.WriteString(Type);
+      }
+      if (Message.Length != 0) {
+        output.WriteRawTag(18);
+        output.WriteString(Message);
+      }
+      if (StackTrace.Length != 0) {
+        output.WriteRawTag(26);
+        output.WriteString(StackTrace);
+      }
       if (BattleId != 0) {
-        output.WriteRawTag(8);
+        output.WriteRawTag(32);
         output.WriteUInt32(BattleId);
       }
-      if (ProgressPercent != 0) {
-        output.WriteRawTag(16);
-        output.WriteInt32(ProgressPercent);
-      }
-      if (CurrentRound != 0) {
-        output.WriteRawTag(24);
-        output.WriteInt32(CurrentRound);
+      if (Export.Length != 0) {
+        output.WriteRawTag(42);
+        output.WriteString(Export);
       }
-      if (status_ != null) {
-        output.WriteRawTag(34);
-        output.WriteMessage(Status);
+      if (Inner.Length != 0) {
+        output.WriteRawTag(50);
+        output.WriteString(Inner);
       }
       if (Timestamp != 0L) {
-        output.WriteRawTag(40);
+        output.WriteRawTag(56);
         output.WriteInt64(Timestamp);
       }
       if (_unknownFields != null) {
@@ -5496,24 +9879,32 @@ namespace GoPureWithCsharp.Battle {
     [global::System.Diagnostics.DebuggerNonUserCodeAttribute]
     [global::System.CodeDom.Compiler.GeneratedCode("protoc", null)]
     void pb::IBufferMessage.InternalWriteTo(ref pb::WriteContext output) {
+      if (Type.Length != 0) {
+        output.WriteRawTag(10);
+        output.WriteString(Type);
+      }
+      if (Message.Length != 0) {
+        output.WriteRawTag(18);
+        output.WriteString(Message);
+      }
+      if (StackTrace.Length != 0) {
+        output.WriteRawTag(26);
+        output.WriteString(StackTrace);
+      }
       if (BattleId != 0) {
-        output.WriteRawTag(8);
+        output.WriteRawTag(32);
         output.WriteUInt32(BattleId);
       }
-      if (ProgressPercent != 0) {
-        output.WriteRawTag(16);
-        output.WriteInt32(ProgressPercent);
-      }
-      if (CurrentRound != 0) {
-        output.WriteRawTag(24);
-        output.WriteInt32(CurrentRound);
+      if (Export.Length != 0) {
+        output.WriteRawTag(42);
+        output.WriteString(Export);
       }
-      if (status_ != null) {
-        output.WriteRawTag(34);
-        output.WriteMessage(Status);
+      if (Inner.Length != 0) {
+        output.WriteRawTag(50);
+        output.WriteString(Inner);
       }
       if (Timestamp != 0L) {
-        output.WriteRawTag(40);
+        output.WriteRawTag(56);
         output.WriteInt64(Timestamp);
       }
       if (_unknownFields != null) {
@@ -5526,17 +9917,23 @@ namespace GoPureWithCsharp.Battle {
     [global::System.CodeDom.Compiler.GeneratedCode("protoc", null)]
     public int CalculateSize() {
       int size = 0;
+      if (Type.Length != 0) {
+        size += 1 + pb::CodedOutputStream.ComputeStringSize(Type);
+      }
+      if (Message.Length != 0) {
+        size += 1 + pb::CodedOutputStream.ComputeStringSize(Message);
+      }
+      if (StackTrace.Length != 0) {
+        size += 1 + pb::CodedOutputStream.ComputeStringSize(StackTrace);
+      }
       if (BattleId != 0) {
         size += 1 + pb::CodedOutputStream.ComputeUInt32Size(BattleId);
       }
-      if (ProgressPercent != 0) {
-        size += 1 + pb::CodedOutputStream.ComputeInt32Size(ProgressPercent);
-      }
-      if (CurrentRound != 0) {
-        size += 1 + pb::CodedOutputStream.ComputeInt32Size(CurrentRound);
+      if (Export.Length != 0) {
+        size += 1 + pb::CodedOutputStream.ComputeStringSize(Export);
       }
-      if (status_ != null) {
-        size += 1 + pb::CodedOutputStream.ComputeMessageSize(Status);
+      if (Inner.Length != 0) {
+        size += 1 + pb::CodedOutputStream.ComputeStringSize(Inner);
       }
       if (Timestamp != 0L) {
         size += 1 + pb::CodedOutputStream.ComputeInt64Size(Timestamp);
@@ -5549,24 +9946,27 @@ namespace GoPureWithCsharp.Battle {
 
     [global::System.Diagnostics.DebuggerNonUserCodeAttribute]
     [global::System.CodeDom.Compiler.GeneratedCode("protoc", null)]
-    public void MergeFrom(ProgressReport other) {
+    public void MergeFrom(ManagedException other) {
       if (other == null) {
         return;
       }
+      if (other.Type.Length != 0) {
+        Type = other.Type;
+      }
+      if (other.Message.Length != 0) {
+        Message = other.Message;
+      }
+      if (other.StackTrace.Length != 0) {
+        StackTrace = other.StackTrace;
+      }
       if (other.BattleId != 0) {
         BattleId = other.BattleId;
       }
-      if (other.ProgressPercent != 0) {
-        ProgressPercent = other.ProgressPercent;
-      }
-      if (other.CurrentRound != 0) {
-        CurrentRound = other.CurrentRound;
+      if (other.Export.Length != 0) {
+        Export = other.Export;
       }
-      if (other.status_ != null) {
-        if (status_ == null) {
-          Status = new global::GoPureWithCsharp.Battle.BattleStatus();
-        }
-        Status.MergeFrom(other.Status);
+      if (other.Inner.Length != 0) {
+        Inner = other.Inner;
       }
       if (other.Timestamp != 0L) {
         Timestamp = other.Timestamp;
@@ -5586,26 +9986,31 @@ namespace GoPureWithCsharp.Battle {
           default:
             _unknownFields = pb::UnknownFieldSet.MergeFieldFrom(_unknownFields, input);
             break;
-          case 8: {
-            BattleId = input.ReadUInt32();
+          case 10: {
+            Type = input.ReadString();
             break;
           }
-          case 16: {
-            ProgressPercent = input.ReadInt32();
+          case 18: {
+            Message = input.ReadString();
             break;
           }
-          case 24: {
-            CurrentRound = input.ReadInt32();
+          case 26: {
+            StackTrace = input.ReadString();
             break;
           }
-          case 34: {
-            if (status_ == null) {
-              Status = new global::GoPureWithCsharp.Battle.BattleStatus();
-            }
-            input.ReadMessage(Status);
+          case 32: {
+            BattleId = input.ReadUInt32();
             break;
           }
-          case 40: {
+          case 42: {
+            Export = input.ReadString();
+            break;
+          }
+          case 50: {
+            Inner = input.ReadString();
+            break;
+          }
+          case 56: {
             Timestamp = input.ReadInt64();
             break;
           }
@@ -5624,26 +10029,31 @@ namespace GoPureWithCsharp.Battle {
           default:
             _unknownFields = pb::UnknownFieldSet.MergeFieldFrom(_unknownFields, ref input);
             break;
-          case 8: {
-            BattleId = input.ReadUInt32();
+          case 10: {
+            Type = input.ReadString();
             break;
           }
-          case 16: {
-            ProgressPercent = input.ReadInt32();
+          case 18: {
+            Message = input.ReadString();
             break;
           }
-          case 24: {
-            CurrentRound = input.ReadInt32();
+          case 26: {
+            StackTrace = input.ReadString();
             break;
           }
-          case 34: {
-            if (status_ == null) {
-              Status = new global::GoPureWithCsharp.Battle.BattleStatus();
-            }
-            input.ReadMessage(Status);
+          case 32: {
+            BattleId = input.ReadUInt32();
             break;
           }
-          case 40: {
+          case 42: {
+            Export = input.ReadString();
+            break;
+          }
+          case 50: {
+            Inner = input.ReadString();
+            break;
+          }
+          case 56: {
             Timestamp = input.ReadInt64();
             break;
           }
@@ -5655,23 +10065,24 @@ namespace GoPureWithCsharp.Battle {
   }
 
   /// <summary>
-  /// 战斗通知 (异步通知，由 C# 回调 Go)
+  /// 一帧内收集的战斗输入，一次 FFI 调用提交
+  /// 每条输入的结果以 BattleErrorCode 写入 Go 提供的 int32 数组，顺序与 contexts 一致
   /// </summary>
-  public sealed partial class BattleNotification : pb::IMessage<BattleNotification>
+  public sealed partial class BattleContextBatch : pb::IMessage<BattleContextBatch>
   #if !GOOGLE_PROTOBUF_REFSTRUCT_COMPATIBILITY_MODE
       , pb::IBufferMessage
   #endif
   {
-    private static readonly pb::MessageParser<BattleNotification> _parser = new pb::MessageParser<BattleNotification>(() => new BattleNotification());
+    private static readonly pb::MessageParser<BattleContextBatch> _parser = new pb::MessageParser<BattleContextBatch>(() => new BattleContextBatch());
     private pb::UnknownFieldSet _unknownFields;
     [global::System.Diagnostics.DebuggerNonUserCodeAttribute]
     [global::System.CodeDom.Compiler.GeneratedCode("protoc", null)]
-    public static pb::MessageParser<BattleNotification> Parser { get { return _parser; } }
+    public static pb::MessageParser<BattleContextBatch> Parser { get { return _parser; } }
 
     [global::System.Diagnostics.DebuggerNonUserCodeAttribute]
     [global::System.CodeDom.Compiler.GeneratedCode("protoc", null)]
     public static pbr::MessageDescriptor Descriptor {
-      get { return global::GoPureWithCsharp.Battle.BattleReflection.Descriptor.MessageTypes[17]; }
+      get { return global::GoPureWithCsharp.Battle.BattleReflection.Descriptor.MessageTypes[27]; }
     }
 
     [global::System.Diagnostics.DebuggerNonUserCodeAttribute]
@@ -5682,7 +10093,7 @@ namespace GoPureWithCsharp.Battle {
 
     [global::System.Diagnostics.DebuggerNonUserCodeAttribute]
     [global::System.CodeDom.Compiler.GeneratedCode("protoc", null)]
-    public BattleNotification() {
+    public BattleContextBatch() {
       OnConstruction();
     }
 
@@ -5690,116 +10101,47 @@ namespace GoPureWithCsharp.Battle {
 
     [global::System.Diagnostics.DebuggerNonUserCodeAttribute]
     [global::System.CodeDom.Compiler.GeneratedCode("protoc", null)]
-    public BattleNotification(BattleNotification other) : this() {
-      timestamp_ = other.timestamp_;
-      notificationType_ = other.notificationType_;
-      battleId_ = other.battleId_;
-      payload_ = other.payload_;
-      errorMessage_ = other.errorMessage_;
+    public BattleContextBatch(BattleContextBatch other) : this() {
+      contexts_ = other.contexts_.Clone();
       _unknownFields = pb::UnknownFieldSet.Clone(other._unknownFields);
     }
 
     [global::System.Diagnostics.DebuggerNonUserCodeAttribute]
     [global::System.CodeDom.Compiler.GeneratedCode("protoc", null)]
-    public BattleNotification Clone() {
-      return new BattleNotification(this);
-    }
-
-    /// <summary>Field number for the "timestamp" field.</summary>
-    public const int TimestampFieldNumber = 1;
-    private long timestamp_;
-    /// <summary>
-    /// 通知时间戳
-    /// </summary>
-    [global::System.Diagnostics.DebuggerNonUserCodeAttribute]
-    [global::System.CodeDom.Compiler.GeneratedCode("protoc", null)]
-    public long Timestamp {
-      get { return timestamp_; }
-      set {
-        timestamp_ = value;
-      }
-    }
-
-    /// <summary>Field number for the "notification_type" field.</summary>
-    public const int NotificationTypeFieldNumber = 2;
-    private global::GoPureWithCsharp.Battle.NotificationType notificationType_ = global::GoPureWithCsharp.Battle.NotificationType.StatusUpdate;
-    /// <summary>
-    /// 通知类型
-    /// </summary>
-    [global::System.Diagnostics.DebuggerNonUserCodeAttribute]
-    [global::System.CodeDom.Compiler.GeneratedCode("protoc", null)]
-    public global::GoPureWithCsharp.Battle.NotificationType NotificationType {
-      get { return notificationType_; }
-      set {
-        notificationType_ = value;
-      }
-    }
-
-    /// <summary>Field number for the "battle_id" field.</summary>
-    public const int BattleIdFieldNumber = 3;
-    private uint battleId_;
-    /// <summary>
-    /// 战斗ID
-    /// </summary>
-    [global::System.Diagnostics.DebuggerNonUserCodeAttribute]
-    [global::System.CodeDom.Compiler.GeneratedCode("protoc", null)]
-    public uint BattleId {
-      get { return battleId_; }
-      set {
-        battleId_ = value;
-      }
-    }
-
-    /// <summary>Field number for the "payload" field.</summary>
-    public const int PayloadFieldNumber = 4;
-    private pb::ByteString payload_ = pb::ByteString.Empty;
-    /// <summary>
-    /// 序列化的详细数据 (ProgressReport/BattleEvent/BattleReplay)
-    /// </summary>
-    [global::System.Diagnostics.DebuggerNonUserCodeAttribute]
-    [global::System.CodeDom.Compiler.GeneratedCode("protoc", null)]
-    public pb::ByteString Payload {
-      get { return payload_; }
-      set {
-        payload_ = pb::ProtoPreconditions.CheckNotNull(value, "value");
-      }
+    public BattleContextBatch Clone() {
+      return new BattleContextBatch(this);
     }
 
-    /// <summary>Field number for the "error_message" field.</summary>
-    public const int ErrorMessageFieldNumber = 5;
-    private string errorMessage_ = "";
+    /// <summary>Field number for the "contexts" field.</summary>
+    public const int ContextsFieldNumber = 1;
+    private static readonly pb::FieldCodec<global::GoPureWithCsharp.Battle.BattleContext> _repeated_contexts_codec
+        = pb::FieldCodec.ForMessage(10, global::GoPureWithCsharp.Battle.BattleContext.Parser);
+    private readonly pbc::RepeatedField<global::GoPureWithCsharp.Battle.BattleContext> contexts_ = new pbc::RepeatedField<global::GoPureWithCsharp.Battle.BattleContext>();
     /// <summary>
-    /// 错误消息 (仅在 ERROR_OCCURRED 时)
+    /// 只接受 BattleInput 类型的 BattleContext
     /// </summary>
     [global::System.Diagnostics.DebuggerNonUserCodeAttribute]
     [global::System.CodeDom.Compiler.GeneratedCode("protoc", null)]
-    public string ErrorMessage {
-      get { return errorMessage_; }
-      set {
-        errorMessage_ = pb::ProtoPreconditions.CheckNotNull(value, "value");
-      }
+    public pbc::RepeatedField<global::GoPureWithCsharp.Battle.BattleContext> Contexts {
+      get { return contexts_; }
     }
 
     [global::System.Diagnostics.DebuggerNonUserCodeAttribute]
     [global::System.CodeDom.Compiler.GeneratedCode("protoc", null)]
     public override bool Equals(object other) {
-      return Equals(other as BattleNotification);
+      return Equals(other as BattleContextBatch);
     }
 
     [global::System.Diagnostics.DebuggerNonUserCodeAttribute]
     [global::System.CodeDom.Compiler.GeneratedCode("protoc", null)]
-    public bool Equals(BattleNotification other) {
+    public bool Equals(BattleContextBatch other) {
       if (ReferenceEquals(other, null)) {
         return false;
       }
       if (ReferenceEquals(other, this)) {
         return true;
       }
-      if (Timestamp != other.Timestamp) return false;
-      if (NotificationType != other.NotificationType) return false;
-      if (BattleId != other.BattleId) return false;
-      if (Payload != other.Payload) return false;
-      if (ErrorMessage != other.ErrorMessage) return false;
+      if(!contexts_.Equals(other.contexts_)) return false;
       return Equals(_unknownFields, other._unknownFields);
     }
 
@@ -5807,11 +10149,7 @@ namespace GoPureWithCsharp.Battle {
     [global::System.CodeDom.Compiler.GeneratedCode("protoc", null)]
     public override int GetHashCode() {
       int hash = 1;
-      if (Timestamp != 0L) hash ^= Timestamp.GetHashCode();
-      if (NotificationType != global::GoPureWithCsharp.Battle.NotificationType.StatusUpdate) hash ^= NotificationType.GetHashCode();
-      if (BattleId != 0) hash ^= BattleId.GetHashCode();
-      if (Payload.Length != 0) hash ^= Payload.GetHashCode();
-      if (ErrorMessage.Length != 0) hash ^= ErrorMessage.GetHashCode();
+      hash ^= contexts_.GetHashCode();
       if (_unknownFields != null) {
         hash ^= _unknownFields.GetHashCode();
       }
@@ -5821,35 +10159,16 @@ namespace GoPureWithCsharp.Battle {
     [global::System.Diagnostics.DebuggerNonUserCodeAttribute]
     [global::System.CodeDom.Compiler.GeneratedCode("protoc", null)]
     public override string ToString() {
-      return pb::JsonFormatter.ToDiagnosticString(this);
-    }
-
-    [global::System.Diagnostics.DebuggerNonUserCodeAttribute]
-    [global::System.CodeDom.Compiler.GeneratedCode("protoc", null)]
-    public void WriteTo(pb::CodedOutputStream output) {
-    #if !GOOGLE_PROTOBUF_REFSTRUCT_COMPATIBILITY_MODE
-      output.WriteRawMessage(this);
-    #else
-      if (Timestamp != 0L) {
-        output.WriteRawTag(8);
-        output.WriteInt64(Timestamp);
-      }
-      if (NotificationType != global::GoPureWithCsharp.Battle.NotificationType.StatusUpdate) {
-        output.WriteRawTag(16);
-        output.WriteEnum((int) NotificationType);
-      }
-      if (BattleId != 0) {
-        output.WriteRawTag(24);
-        output.WriteUInt32(BattleId);
-      }
-      if (Payload.Length != 0) {
-        output.WriteRawTag(34);
-        output.WriteBytes(Payload);
-      }
-      if (ErrorMessage.Length != 0) {
-        output.WriteRawTag(42);
-        output.WriteString(ErrorMessage);
-      }
+      return pb::JsonFormatter.ToDiagnosticString(this);
+    }
+
+    [global::System.Diagnostics.DebuggerNonUserCodeAttribute]
+    [global::System.CodeDom.Compiler.GeneratedCode("protoc", null)]
+    public void WriteTo(pb::CodedOutputStream output) {
+    #if !GOOGLE_PROTOBUF_REFSTRUCT_COMPATIBILITY_MODE
+      output.WriteRawMessage(this);
+    #else
+      contexts_.WriteTo(output, _repeated_contexts_codec);
       if (_unknownFields != null) {
         _unknownFields.WriteTo(output);
       }
@@ -5860,26 +10179,7 @@ namespace GoPureWithCsharp.Battle {
     [global::System.Diagnostics.DebuggerNonUserCodeAttribute]
     [global::System.CodeDom.Compiler.GeneratedCode("protoc", null)]
     void pb::IBufferMessage.InternalWriteTo(ref pb::WriteContext output) {
-      if (Timestamp != 0L) {
-        output.WriteRawTag(8);
-        output.WriteInt64(Timestamp);
-      }
-      if (NotificationType != global::GoPureWithCsharp.Battle.NotificationType.StatusUpdate) {
-        output.WriteRawTag(16);
-        output.WriteEnum((int) NotificationType);
-      }
-      if (BattleId != 0) {
-        output.WriteRawTag(24);
-        output.WriteUInt32(BattleId);
-      }
-      if (Payload.Length != 0) {
-        output.WriteRawTag(34);
-        output.WriteBytes(Payload);
-      }
-      if (ErrorMessage.Length != 0) {
-        output.WriteRawTag(42);
-        output.WriteString(ErrorMessage);
-      }
+      contexts_.WriteTo(ref output, _repeated_contexts_codec);
       if (_unknownFields != null) {
         _unknownFields.WriteTo(ref output);
       }
@@ -5890,21 +10190,7 @@ namespace GoPureWithCsharp.Battle {
     [global::System.CodeDom.Compiler.GeneratedCode("protoc", null)]
     public int CalculateSize() {
       int size = 0;
-      if (Timestamp != 0L) {
-        size += 1 + pb::CodedOutputStream.ComputeInt64Size(Timestamp);
-      }
-      if (NotificationType != global::GoPureWithCsharp.Battle.NotificationType.StatusUpdate) {
-        size += 1 + pb::CodedOutputStream.ComputeEnumSize((int) NotificationType);
-      }
-      if (BattleId != 0) {
-        size += 1 + pb::CodedOutputStream.ComputeUInt32Size(BattleId);
-      }
-      if (Payload.Length != 0) {
-        size += 1 + pb::CodedOutputStream.ComputeBytesSize(Payload);
-      }
-      if (ErrorMessage.Length != 0) {
-        size += 1 + pb::CodedOutputStream.ComputeStringSize(ErrorMessage);
-      }
+      size += contexts_.CalculateSize(_repeated_contexts_codec);
       if (_unknownFields != null) {
         size += _unknownFields.CalculateSize();
       }
@@ -5913,25 +10199,11 @@ namespace GoPureWithCsharp.Battle {
 
     [global::System.Diagnostics.DebuggerNonUserCodeAttribute]
     [global::System.CodeDom.Compiler.GeneratedCode("protoc", null)]
-    public void MergeFrom(BattleNotification other) {
+    public void MergeFrom(BattleContextBatch other) {
       if (other == null) {
         return;
       }
-      if (other.Timestamp != 0L) {
-        Timestamp = other.Timestamp;
-      }
-      if (other.NotificationType != global::GoPureWithCsharp.Battle.NotificationType.StatusUpdate) {
-        NotificationType = other.NotificationType;
-      }
-      if (other.BattleId != 0) {
-        BattleId = other.BattleId;
-      }
-      if (other.Payload.Length != 0) {
-        Payload = other.Payload;
-      }
-      if (other.ErrorMessage.Length != 0) {
-        ErrorMessage = other.ErrorMessage;
-      }
+      contexts_.Add(other.contexts_);
       _unknownFields = pb::UnknownFieldSet.MergeFrom(_unknownFields, other._unknownFields);
     }
 
@@ -5947,24 +10219,8 @@ namespace GoPureWithCsharp.Battle {
           default:
             _unknownFields = pb::UnknownFieldSet.MergeFieldFrom(_unknownFields, input);
             break;
-          case 8: {
-            Timestamp = input.ReadInt64();
-            break;
-          }
-          case 16: {
-            NotificationType = (global::GoPureWithCsharp.Battle.NotificationType) input.ReadEnum();
-            break;
-          }
-          case 24: {
-            BattleId = input.ReadUInt32();
-            break;
-          }
-          case 34: {
-            Payload = input.ReadBytes();
-            break;
-          }
-          case 42: {
-            ErrorMessage = input.ReadString();
+          case 10: {
+            contexts_.AddEntriesFrom(input, _repeated_contexts_codec);
             break;
           }
         }
@@ -5982,24 +10238,8 @@ namespace GoPureWithCsharp.Battle {
           default:
             _unknownFields = pb::UnknownFieldSet.MergeFieldFrom(_unknownFields, ref input);
             break;
-          case 8: {
-            Timestamp = input.ReadInt64();
-            break;
-          }
-          case 16: {
-            NotificationType = (global::GoPureWithCsharp.Battle.NotificationType) input.ReadEnum();
-            break;
-          }
-          case 24: {
-            BattleId = input.ReadUInt32();
-            break;
-          }
-          case 34: {
-            Payload = input.ReadBytes();
-            break;
-          }
-          case 42: {
-            ErrorMessage = input.ReadString();
+          case 10: {
+            contexts_.AddEntriesFrom(ref input, _repeated_contexts_codec);
             break;
           }
         }
@@ -6009,21 +10249,24 @@ namespace GoPureWithCsharp.Battle {
 
   }
 
-  public sealed partial class BattleContext : pb::IMessage<BattleContext>
+  /// <summary>
+  /// 战斗日志条目
+  /// </summary>
+  public sealed partial class JournalEntry : pb::IMessage<JournalEntry>
   #if !GOOGLE_PROTOBUF_REFSTRUCT_COMPATIBILITY_MODE
       , pb::IBufferMessage
   #endif
   {
-    private static readonly pb::MessageParser<BattleContext> _parser = new pb::MessageParser<BattleContext>(() => new BattleContext());
+    private static readonly pb::MessageParser<JournalEntry> _parser = new pb::MessageParser<JournalEntry>(() => new JournalEntry());
     private pb::UnknownFieldSet _unknownFields;
     [global::System.Diagnostics.DebuggerNonUserCodeAttribute]
     [global::System.CodeDom.Compiler.GeneratedCode("protoc", null)]
-    public static pb::MessageParser<BattleContext> Parser { get { return _parser; } }
+    public static pb::MessageParser<JournalEntry> Parser { get { return _parser; } }
 
     [global::System.Diagnostics.DebuggerNonUserCodeAttribute]
     [global::System.CodeDom.Compiler.GeneratedCode("protoc", null)]
     public static pbr::MessageDescriptor Descriptor {
-      get { return global::GoPureWithCsharp.Battle.BattleReflection.Descriptor.MessageTypes[18]; }
+      get { return global::GoPureWithCsharp.Battle.BattleReflection.Descriptor.MessageTypes[28]; }
     }
 
     [global::System.Diagnostics.DebuggerNonUserCodeAttribute]
@@ -6034,7 +10277,7 @@ namespace GoPureWithCsharp.Battle {
 
     [global::System.Diagnostics.DebuggerNonUserCodeAttribute]
     [global::System.CodeDom.Compiler.GeneratedCode("protoc", null)]
-    public BattleContext() {
+    public JournalEntry() {
       OnConstruction();
     }
 
@@ -6042,15 +10285,14 @@ namespace GoPureWithCsharp.Battle {
 
     [global::System.Diagnostics.DebuggerNonUserCodeAttribute]
     [global::System.CodeDom.Compiler.GeneratedCode("protoc", null)]
-    public BattleContext(BattleContext other) : this() {
-      battleId_ = other.battleId_;
-      tick_ = other.tick_;
-      switch (other.OptionCase) {
-        case OptionOneofCase.BattleInput:
-          BattleInput = other.BattleInput.Clone();
+    public JournalEntry(JournalEntry other) : this() {
+      frame_ = other.frame_;
+      switch (other.EntryCase) {
+        case EntryOneofCase.Env:
+          Env = other.Env.Clone();
           break;
-        case OptionOneofCase.BattleOutput:
-          BattleOutput = other.BattleOutput.Clone();
+        case EntryOneofCase.Context:
+          Context = other.Context.Clone();
           break;
       }
 
@@ -6059,111 +10301,95 @@ namespace GoPureWithCsharp.Battle {
 
     [global::System.Diagnostics.DebuggerNonUserCodeAttribute]
     [global::System.CodeDom.Compiler.GeneratedCode("protoc", null)]
-    public BattleContext Clone() {
-      return new BattleContext(this);
-    }
-
-    /// <summary>Field number for the "battle_id" field.</summary>
-    public const int BattleIdFieldNumber = 1;
-    private uint battleId_;
-    /// <summary>
-    /// 战斗ID
-    /// </summary>
-    [global::System.Diagnostics.DebuggerNonUserCodeAttribute]
-    [global::System.CodeDom.Compiler.GeneratedCode("protoc", null)]
-    public uint BattleId {
-      get { return battleId_; }
-      set {
-        battleId_ = value;
-      }
+    public JournalEntry Clone() {
+      return new JournalEntry(this);
     }
 
-    /// <summary>Field number for the "tick" field.</summary>
-    public const int TickFieldNumber = 2;
-    private ulong tick_;
+    /// <summary>Field number for the "frame" field.</summary>
+    public const int FrameFieldNumber = 1;
+    private ulong frame_;
     /// <summary>
-    /// 当前Tick
+    /// 条目所属的逻辑帧
     /// </summary>
     [global::System.Diagnostics.DebuggerNonUserCodeAttribute]
     [global::System.CodeDom.Compiler.GeneratedCode("protoc", null)]
-    public ulong Tick {
-      get { return tick_; }
+    public ulong Frame {
+      get { return frame_; }
       set {
-        tick_ = value;
+        frame_ = value;
       }
     }
 
-    /// <summary>Field number for the "battle_input" field.</summary>
-    public const int BattleInputFieldNumber = 3;
+    /// <summary>Field number for the "env" field.</summary>
+    public const int EnvFieldNumber = 2;
     /// <summary>
-    /// 通用战斗输入
+    /// 创建战斗，frame 为战斗执行第一回合的帧
     /// </summary>
     [global::System.Diagnostics.DebuggerNonUserCodeAttribute]
     [global::System.CodeDom.Compiler.GeneratedCode("protoc", null)]
-    public global::GoPureWithCsharp.Battle.BattleInput BattleInput {
-      get { return optionCase_ == OptionOneofCase.BattleInput ? (global::GoPureWithCsharp.Battle.BattleInput) option_ : null; }
+    public global::GoPureWithCsharp.Battle.BattleEnv Env {
+      get { return entryCase_ == EntryOneofCase.Env ? (global::GoPureWithCsharp.Battle.BattleEnv) entry_ : null; }
       set {
-        option_ = value;
-        optionCase_ = value == null ? OptionOneofCase.None : OptionOneofCase.BattleInput;
+        entry_ = value;
+        entryCase_ = value == null ? EntryOneofCase.None : EntryOneofCase.Env;
       }
     }
 
-    /// <summary>Field number for the "battle_output" field.</summary>
-    public const int BattleOutputFieldNumber = 4;
+    /// <summary>Field number for the "context" field.</summary>
+    public const int ContextFieldNumber = 3;
     /// <summary>
-    /// 开始战斗请求
+    /// 跨越 Go/C# 边界的输入 (Tick 为应用它的帧) 或输出
     /// </summary>
     [global::System.Diagnostics.DebuggerNonUserCodeAttribute]
     [global::System.CodeDom.Compiler.GeneratedCode("protoc", null)]
-    public global::GoPureWithCsharp.Battle.BattleOutput BattleOutput {
-      get { return optionCase_ == OptionOneofCase.BattleOutput ? (global::GoPureWithCsharp.Battle.BattleOutput) option_ : null; }
+    public global::GoPureWithCsharp.Battle.BattleContext Context {
+      get { return entryCase_ == EntryOneofCase.Context ? (global::GoPureWithCsharp.Battle.BattleContext) entry_ : null; }
       set {
-        option_ = value;
-        optionCase_ = value == null ? OptionOneofCase.None : OptionOneofCase.BattleOutput;
+        entry_ = value;
+        entryCase_ = value == null ? EntryOneofCase.None : EntryOneofCase.Context;
       }
     }
 
-    private object option_;
-    /// <summary>Enum of possible cases for the "option" oneof.</summary>
-    public enum OptionOneofCase {
+    private object entry_;
+    /// <summary>Enum of possible cases for the "entry" oneof.</summary>
+    public enum EntryOneofCase {
       None = 0,
-      BattleInput = 3,
-      BattleOutput = 4,
+      Env = 2,
+      Context = 3,
     }
-    private OptionOneofCase optionCase_ = OptionOneofCase.None;
+    private EntryOneofCase entryCase_ = EntryOneofCase.None;
     [global::System.Diagnostics.DebuggerNonUserCodeAttribute]
     [global::System.CodeDom.Compiler.GeneratedCode("protoc", null)]
-    public OptionOneofCase OptionCase {
-      get { return optionCase_; }
+    public EntryOneofCase EntryCase {
+      get { return entryCase_; }
     }
 
     [global::System.Diagnostics.DebuggerNonUserCodeAttribute]
     [global::System.CodeDom.Compiler.GeneratedCode("protoc", null)]
-    public void ClearOption() {
-      optionCase_ = OptionOneofCase.None;
-      option_ = null;
+    public void ClearEntry() {
+      entryCase_ = EntryOneofCase.None;
+      entry_ = null;
     }
 
     [global::System.Diagnostics.DebuggerNonUserCodeAttribute]
     [global::System.CodeDom.Compiler.GeneratedCode("protoc", null)]
     public override bool Equals(object other) {
-      return Equals(other as BattleContext);
+      return Equals(other as JournalEntry);
     }
 
     [global::System.Diagnostics.DebuggerNonUserCodeAttribute]
     [global::System.CodeDom.Compiler.GeneratedCode("protoc", null)]
-    public bool Equals(BattleContext other) {
+    public bool Equals(JournalEntry other) {
       if (ReferenceEquals(other, null)) {
         return false;
       }
       if (ReferenceEquals(other, this)) {
         return true;
       }
-      if (BattleId != other.BattleId) return false;
-      if (Tick != other.Tick) return false;
-      if (!object.Equals(BattleInput, other.BattleInput)) return false;
-      if (!object.Equals(BattleOutput, other.BattleOutput)) return false;
-      if (OptionCase != other.OptionCase) return false;
+      if (Frame != other.Frame) return false;
+      if (!object.Equals(Env, other.Env)) return false;
+      if (!object.Equals(Context, other.Context)) return false;
+      if (EntryCase != other.EntryCase) return false;
       return Equals(_unknownFields, other._unknownFields);
     }
 
@@ -6171,11 +10397,10 @@ namespace GoPureWithCsharp.Battle {
     [global::System.CodeDom.Compiler.GeneratedCode("protoc", null)]
     public override int GetHashCode() {
       int hash = 1;
-      if (BattleId != 0) hash ^= BattleId.GetHashCode();
-      if (Tick != 0UL) hash ^= Tick.GetHashCode();
-      if (optionCase_ == OptionOneofCase.BattleInput) hash ^= BattleInput.GetHashCode();
-      if (optionCase_ == OptionOneofCase.BattleOutput) hash ^= BattleOutput.GetHashCode();
-      hash ^= (int) optionCase_;
+      if (Frame != 0UL) hash ^= Frame.GetHashCode();
+      if (entryCase_ == EntryOneofCase.Env) hash ^= Env.GetHashCode();
+      if (entryCase_ == EntryOneofCase.Context) hash ^= Context.GetHashCode();
+      hash ^= (int) entryCase_;
       if (_unknownFields != null) {
         hash ^= _unknownFields.GetHashCode();
       }
@@ -6194,21 +10419,17 @@ namespace GoPureWithCsharp.Battle {
     #if !GOOGLE_PROTOBUF_REFSTRUCT_COMPATIBILITY_MODE
       output.WriteRawMessage(this);
     #else
-      if (BattleId != 0) {
+      if (Frame != 0UL) {
         output.WriteRawTag(8);
-        output.WriteUInt32(BattleId);
+        output.WriteUInt64(Frame);
       }
-      if (Tick != 0UL) {
-        output.WriteRawTag(16);
-        output.WriteUInt64(Tick);
+      if (entryCase_ == EntryOneofCase.Env) {
+        output.WriteRawTag(18);
+        output.WriteMessage(Env);
       }
-      if (optionCase_ == OptionOneofCase.BattleInput) {
+      if (entryCase_ == EntryOneofCase.Context) {
         output.WriteRawTag(26);
-        output.WriteMessage(BattleInput);
-      }
-      if (optionCase_ == OptionOneofCase.BattleOutput) {
-        output.WriteRawTag(34);
-        output.WriteMessage(BattleOutput);
+        output.WriteMessage(Context);
       }
       if (_unknownFields != null) {
         _unknownFields.WriteTo(output);
@@ -6220,21 +10441,17 @@ namespace GoPureWithCsharp.Battle {
     [global::System.Diagnostics.DebuggerNonUserCodeAttribute]
     [global::System.CodeDom.Compiler.GeneratedCode("protoc", null)]
     void pb::IBufferMessage.InternalWriteTo(ref pb::WriteContext output) {
-      if (BattleId != 0) {
+      if (Frame != 0UL) {
         output.WriteRawTag(8);
-        output.WriteUInt32(BattleId);
+        output.WriteUInt64(Frame);
       }
-      if (Tick != 0UL) {
-        output.WriteRawTag(16);
-        output.WriteUInt64(Tick);
+      if (entryCase_ == EntryOneofCase.Env) {
+        output.WriteRawTag(18);
+        output.WriteMessage(Env);
       }
-      if (optionCase_ == OptionOneofCase.BattleInput) {
+      if (entryCase_ == EntryOneofCase.Context) {
         output.WriteRawTag(26);
-        output.WriteMessage(BattleInput);
-      }
-      if (optionCase_ == OptionOneofCase.BattleOutput) {
-        output.WriteRawTag(34);
-        output.WriteMessage(BattleOutput);
+        output.WriteMessage(Context);
       }
       if (_unknownFields != null) {
         _unknownFields.WriteTo(ref output);
@@ -6246,17 +10463,14 @@ namespace GoPureWithCsharp.Battle {
     [global::System.CodeDom.Compiler.GeneratedCode("protoc", null)]
     public int CalculateSize() {
       int size = 0;
-      if (BattleId != 0) {
-        size += 1 + pb::CodedOutputStream.ComputeUInt32Size(BattleId);
+      if (Frame != 0UL) {
+        size += 1 + pb::CodedOutputStream.ComputeUInt64Size(Frame);
       }
-      if (Tick != 0UL) {
-        size += 1 + pb::CodedOutputStream.ComputeUInt64Size(Tick);
-      }
-      if (optionCase_ == OptionOneofCase.BattleInput) {
-        size += 1 + pb::CodedOutputStream.ComputeMessageSize(BattleInput);
+      if (entryCase_ == EntryOneofCase.Env) {
+        size += 1 + pb::CodedOutputStream.ComputeMessageSize(Env);
       }
-      if (optionCase_ == OptionOneofCase.BattleOutput) {
-        size += 1 + pb::CodedOutputStream.ComputeMessageSize(BattleOutput);
+      if (entryCase_ == EntryOneofCase.Context) {
+        size += 1 + pb::CodedOutputStream.ComputeMessageSize(Context);
       }
       if (_unknownFields != null) {
         size += _unknownFields.CalculateSize();
@@ -6266,28 +10480,25 @@ namespace GoPureWithCsharp.Battle {
 
     [global::System.Diagnostics.DebuggerNonUserCodeAttribute]
     [global::System.CodeDom.Compiler.GeneratedCode("protoc", null)]
-    public void MergeFrom(BattleContext other) {
+    public void MergeFrom(JournalEntry other) {
       if (other == null) {
         return;
       }
-      if (other.BattleId != 0) {
-        BattleId = other.BattleId;
-      }
-      if (other.Tick != 0UL) {
-        Tick = other.Tick;
+      if (other.Frame != 0UL) {
+        Frame = other.Frame;
       }
-      switch (other.OptionCase) {
-        case OptionOneofCase.BattleInput:
-          if (BattleInput == null) {
-            BattleInput = new global::GoPureWithCsharp.Battle.BattleInput();
+      switch (other.EntryCase) {
+        case EntryOneofCase.Env:
+          if (Env == null) {
+            Env = new global::GoPureWithCsharp.Battle.BattleEnv();
           }
-          BattleInput.MergeFrom(other.BattleInput);
+          Env.MergeFrom(other.Env);
           break;
-        case OptionOneofCase.BattleOutput:
-          if (BattleOutput == null) {
-            BattleOutput = new global::GoPureWithCsharp.Battle.BattleOutput();
+        case EntryOneofCase.Context:
+          if (Context == null) {
+            Context = new global::GoPureWithCsharp.Battle.BattleContext();
           }
-          BattleOutput.MergeFrom(other.BattleOutput);
+          Context.MergeFrom(other.Context);
           break;
       }
 
@@ -6307,29 +10518,25 @@ namespace GoPureWithCsharp.Battle {
             _unknownFields = pb::UnknownFieldSet.MergeFieldFrom(_unknownFields, input);
             break;
           case 8: {
-            BattleId = input.ReadUInt32();
-            break;
-          }
-          case 16: {
-            Tick = input.ReadUInt64();
+            Frame = input.ReadUInt64();
             break;
           }
-          case 26: {
-            global::GoPureWithCsharp.Battle.BattleInput subBuilder = new global::GoPureWithCsharp.Battle.BattleInput();
-            if (optionCase_ == OptionOneofCase.BattleInput) {
-              subBuilder.MergeFrom(BattleInput);
+          case 18: {
+            global::GoPureWithCsharp.Battle.BattleEnv subBuilder = new global::GoPureWithCsharp.Battle.BattleEnv();
+            if (entryCase_ == EntryOneofCase.Env) {
+              subBuilder.MergeFrom(Env);
             }
             input.ReadMessage(subBuilder);
-            BattleInput = subBuilder;
+            Env = subBuilder;
             break;
           }
-          case 34: {
-            global::GoPureWithCsharp.Battle.BattleOutput subBuilder = new global::GoPureWithCsharp.Battle.BattleOutput();
-            if (optionCase_ == OptionOneofCase.BattleOutput) {
-              subBuilder.MergeFrom(BattleOutput);
+          case 26: {
+            global::GoPureWithCsharp.Battle.BattleContext subBuilder = new global::GoPureWithCsharp.Battle.BattleContext();
+            if (entryCase_ == EntryOneofCase.Context) {
+              subBuilder.MergeFrom(Context);
             }
             input.ReadMessage(subBuilder);
-            BattleOutput = subBuilder;
+            Context = subBuilder;
             break;
           }
         }
@@ -6348,29 +10555,25 @@ namespace GoPureWithCsharp.Battle {
             _unknownFields = pb::UnknownFieldSet.MergeFieldFrom(_unknownFields, ref input);
             break;
           case 8: {
-            BattleId = input.ReadUInt32();
+            Frame = input.ReadUInt64();
             break;
           }
-          case 16: {
-            Tick = input.ReadUInt64();
-            break;
-          }
-          case 26: {
-            global::GoPureWithCsharp.Battle.BattleInput subBuilder = new global::GoPureWithCsharp.Battle.BattleInput();
-            if (optionCase_ == OptionOneofCase.BattleInput) {
-              subBuilder.MergeFrom(BattleInput);
+          case 18: {
+            global::GoPureWithCsharp.Battle.BattleEnv subBuilder = new global::GoPureWithCsharp.Battle.BattleEnv();
+            if (entryCase_ == EntryOneofCase.Env) {
+              subBuilder.MergeFrom(Env);
             }
             input.ReadMessage(subBuilder);
-            BattleInput = subBuilder;
+            Env = subBuilder;
             break;
           }
-          case 34: {
-            global::GoPureWithCsharp.Battle.BattleOutput subBuilder = new global::GoPureWithCsharp.Battle.BattleOutput();
-            if (optionCase_ == OptionOneofCase.BattleOutput) {
-              subBuilder.MergeFrom(BattleOutput);
+          case 26: {
+            global::GoPureWithCsharp.Battle.BattleContext subBuilder = new global::GoPureWithCsharp.Battle.BattleContext();
+            if (entryCase_ == EntryOneofCase.Context) {
+              subBuilder.MergeFrom(Context);
             }
             input.ReadMessage(subBuilder);
-            BattleOutput = subBuilder;
+            Context = subBuilder;
             break;
           }
         }
@@ -0,0 +1,146 @@
+using System;
+using System.Buffers.Binary;
+using System.Threading;
+using Google.Protobuf;
+
+namespace GoPureWithCsharp
+{
+    /// <summary>
+    /// Go 分配的共享内存单生产者单消费者环形缓冲区 (布局与 Go 侧 csharp/shared_ring.go 一致)
+    ///
+    /// [0, 4) magic 'RING', [4, 8) version, [8, 16) capacity,
+    /// [64, 72) head (消费者写), [128, 136) tail (生产者写), [192, ...) 数据区
+    ///
+    /// 帧格式: uint32 小端长度 + protobuf 数据，整帧按 8 字节对齐；
+    /// 长度为 WrapMarker 表示跳到数据区开头
+    /// </summary>
+    public sealed unsafe class SharedRing
+    {
+        public const uint Magic = 0x474E4952;
+        public const uint Version = 1;
+        private const int HeaderSize = 192;
+        private const int HeadOffset = 64;
+        private const int TailOffset = 128;
+        private const int FrameHead = 4;
+        private const int FrameAlign = 8;
+        private const uint WrapMarker = 0xFFFFFFFF;
+
+        /// <summary>
+        /// 帧处理委托，frame 直接引用共享内存，只在调用期间有效
+        /// </summary>
+        public delegate void FrameHandler(ReadOnlySpan<byte> frame);
+
+        private readonly byte* _base;
+        private readonly byte* _data;
+        private readonly ulong _capacity;
+        private readonly ulong _mask;
+
+        private SharedRing(byte* basePtr, ulong capacity)
+        {
+            _base = basePtr;
+            _data = basePtr + HeaderSize;
+            _capacity = capacity;
+            _mask = capacity - 1;
+        }
+
+        /// <summary>
+        /// 绑定 Go 分配的共享内存，头部校验失败时返回 null
+        /// </summary>
+        public static SharedRing? Attach(IntPtr ptr, long size)
+        {
+            if (ptr == IntPtr.Zero || size <= HeaderSize)
+            {
+                return null;
+            }
+
+            byte* basePtr = (byte*)ptr;
+            var header = new ReadOnlySpan<byte>(basePtr, HeaderSize);
+            ulong capacity = BinaryPrimitives.ReadUInt64LittleEndian(header.Slice(8));
+            if (BinaryPrimitives.ReadUInt32LittleEndian(header) != Magic ||
+                BinaryPrimitives.ReadUInt32LittleEndian(header.Slice(4)) != Version ||
+                capacity == 0 || (capacity & (capacity - 1)) != 0 ||
+                (ulong)size < HeaderSize + capacity)
+            {
+                return null;
+            }
+            return new SharedRing(basePtr, capacity);
+        }
+
+        private ref long Head => ref *(long*)(_base + HeadOffset);
+        private ref long Tail => ref *(long*)(_base + TailOffset);
+
+        private static ulong AlignFrame(int n) => ((ulong)n + FrameHead + FrameAlign - 1) & ~(ulong)(FrameAlign - 1);
+
+        /// <summary>
+        /// 将消息直接序列化到环形缓冲区，空间不足时返回 false
+        /// </summary>
+        public bool TryWrite(IMessage message)
+        {
+            int n = message.CalculateSize();
+            ulong need = AlignFrame(n);
+            if (need > _capacity / 2)
+            {
+                return false;
+            }
+
+            ulong tail = (ulong)Tail;
+            ulong head = (ulong)Volatile.Read(ref Head);
+            ulong pos = tail & _mask;
+
+            ulong skip = 0;
+            if (need > _capacity - pos)
+            {
+                skip = _capacity - pos;
+            }
+            if (tail + skip + need - head > _capacity)
+            {
+                return false;
+            }
+
+            if (skip > 0)
+            {
+                BinaryPrimitives.WriteUInt32LittleEndian(new Span<byte>(_data + pos, FrameHead), WrapMarker);
+                pos = 0;
+            }
+            BinaryPrimitives.WriteUInt32LittleEndian(new Span<byte>(_data + pos, FrameHead), (uint)n);
+            message.WriteTo(new Span<byte>(_data + pos + FrameHead, n));
+
+            Volatile.Write(ref Tail, (long)(tail + skip + need));
+            return true;
+        }
+
+        /// <summary>
+        /// 依次读取所有已提交的帧，返回读取的帧数
+        /// </summary>
+        public int Drain(FrameHandler handler)
+        {
+            ulong head = (ulong)Head;
+            ulong tail = (ulong)Volatile.Read(ref Tail);
+
+            int count = 0;
+            while (head != tail)
+            {
+                ulong pos = head & _mask;
+                uint n = BinaryPrimitives.ReadUInt32LittleEndian(new ReadOnlySpan<byte>(_data + pos, FrameHead));
+                if (n == WrapMarker)
+                {
+                    head += _capacity - pos;
+                    Volatile.Write(ref Head, (long)head);
+                    continue;
+                }
+
+                try
+                {
+                    handler(new ReadOnlySpan<byte>(_data + pos + FrameHead, (int)n));
+                }
+                finally
+                {
+                    head += AlignFrame((int)n);
+                    Volatile.Write(ref Head, (long)head);
+                }
+                count++;
+            }
+            return count;
+        }
+    }
+}
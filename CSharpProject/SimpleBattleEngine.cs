@@ -11,8 +11,6 @@ namespace GoPureWithCsharp
     /// </summary>
     public class SimpleBattleEngine
     {
-        private static readonly Random _random = new Random();
-
         /// <summary>
         /// 执行战斗
         /// </summary>
@@ -24,6 +22,7 @@ namespace GoPureWithCsharp
 
             // 初始化战斗状态
             var events = new List<BattleEvent>();
+            var random = new BattleRandom(request.Seed);
             long startTime = DateTimeOffset.Now.ToUnixTimeMilliseconds();
 
             // Demo: 模拟 3 回合战斗
@@ -33,7 +32,7 @@ namespace GoPureWithCsharp
             for (int round = 1; round <= 3; round++)
             {
                 // ATK 攻击 DEF
-                int atkDamage = _random.Next(20, 50);
+                int atkDamage = random.Next(20, 50);
                 defHealth -= atkDamage;
                 events.Add(new BattleEvent
                 {
@@ -45,7 +44,7 @@ namespace GoPureWithCsharp
                 });
 
                 // DEF 反击 ATK
-                int defDamage = _random.Next(15, 40);
+                int defDamage = random.Next(15, 40);
                 atkHealth -= defDamage;
                 events.Add(new BattleEvent
                 {
@@ -152,6 +151,7 @@ namespace GoPureWithCsharp
                 catch (Exception ex)
                 {
                     Console.WriteLine($"[Battle] 战斗执行失败: {ex.Message}");
+                    NativeAOTExceptionInjector.ReportException(ex, "ProcessBatchProtoMessage", battleReq.BattleId);
                     response.FailureCount++;
                 }
             }
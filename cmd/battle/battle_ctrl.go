@@ -1,10 +1,11 @@
 package main
 
 import (
+	"context"
 	"fmt"
 	"goPureWithCsharp/csharp"
 	pb "goPureWithCsharp/csharp/proto"
-	"unsafe"
+	"time"
 
 	"google.golang.org/protobuf/proto"
 )
@@ -40,6 +41,11 @@ type BattleController struct {
 
 	BattleMsgContextBuilder
 
+	engine           csharp.Engine
+	transport        *csharp.RingTransport // 绑定时输入写入环形缓冲区，由下一次 OnTick 处理
+	batch            *InputBatch           // 未绑定环形缓冲区时输入先收集，processTick 时批量提交
+	effects          *csharp.EffectTable   // 道具和用户操作入队前的校验，为空时只由引擎校验
+	callTimeout      time.Duration         // 立即应用的输入每次 C# 调用的超时，为 0 时使用 defaultCallTimeout
 	battleMap        map[uint64]*BattleInfo
 	inputBuffHander  []byte
 	outputBuffHander []byte
@@ -49,10 +55,11 @@ func (bc *BattleController) GetInputBuffer() (buff []byte, maxLen int) {
 	return bc.inputBuffHander, len(bc.inputBuffHander)
 }
 
-func NewBattleController(p FrameSeqProvider, o BattleOutput) *BattleController {
+func NewBattleController(p FrameSeqProvider, o BattleOutput, e csharp.Engine) *BattleController {
 	ctrl := &BattleController{
 		FrameSeqProvider: p,
 		BattleOutput:     o,
+		engine:           e,
 		battleMap:        make(map[uint64]*BattleInfo),
 		inputBuffHander:  make([]byte, INPUT_BUFFER_SIZE),
 		outputBuffHander: make([]byte, OUTPUT_BUFFER_SIZE),
@@ -69,30 +76,90 @@ func (bc *BattleController) CreateBattle(battleId uint64, env *pb.BattleEnv) err
 	return nil
 }
 
+// BattleInput 提交一条输入
+// 生命周期输入 (Pause/Resume/End/Destroy) 立即应用，当前战斗状态不允许时返回对应 BattleErrorCode 的 *csharp.BattleError；
+// 道具和用户操作先按效果表校验，未知道具、数量越界或未知操作返回 INVALID_REQUEST；
+// 其他输入写入环形缓冲区或收集到 batch，由下一次 processTick 应用
 func (bc *BattleController) BattleInput(battleId uint64, input proto.Message) error {
 	if input == nil {
 		return fmt.Errorf("input cannot be nil")
 	}
 
+	if bc.transport != nil || bc.batch != nil {
+		ctx, err := bc.BuildInput(uint32(battleId), input)
+		if err != nil {
+			fmt.Printf("[Battle] 构建输入消息失败: %v\n", err)
+			return err
+		}
+		if bc.effects != nil {
+			if code := bc.effects.Validate(ctx.GetBattleInput()); code != pb.BattleErrorCode_SUCCESS {
+				return &csharp.BattleError{Op: "InputBattle", Code: code}
+			}
+		}
+		switch {
+		case isLifecycleInput(ctx):
+			return bc.applyNow(ctx)
+		case bc.transport != nil:
+			return bc.transport.Input.WriteMessage(ctx)
+		default:
+			bc.batch.Add(ctx)
+			return nil
+		}
+	}
+
 	inputBuffLen, err := bc.InjectInput(uint32(battleId), input)
 	if err != nil {
 		fmt.Printf("[Battle] 构建输入消息失败: %v\n", err)
 		return err
 	}
 
-	err = csharp.PrcessBattleContextInput(unsafe.Pointer(&bc.inputBuffHander[0]), uint32(inputBuffLen))
+	callCtx, cancel := bc.callContext()
+	defer cancel()
+	err = csharp.Supervise(bc.engine).ProcessBattleContextInput(callCtx, bc.inputBuffHander[:inputBuffLen])
 	if err != nil {
 		return err
 	}
 	return nil
 }
 
-func (bc *BattleController) OnTick(logicFrameSeq uint64) {
-	// TODO : 调用 C# 的 OnTick 函数 传入逻辑帧数
-	csharp.OnTick()
+// callContext 分片事件循环中单次 C# 调用的超时，超时后引擎不健康，与 processTick 相同
+func (bc *BattleController) callContext() (context.Context, context.CancelFunc) {
+	timeout := bc.callTimeout
+	if timeout <= 0 {
+		timeout = defaultCallTimeout
+	}
+	return context.WithTimeout(context.Background(), timeout)
+}
+
+// applyNow 立即提交一条输入，被拒绝时返回带结果码的错误
+func (bc *BattleController) applyNow(ctx *pb.BattleContext) error {
+	callCtx, cancel := bc.callContext()
+	defer cancel()
+	codes, err := csharp.Supervise(bc.engine).ProcessBattleContextBatch(callCtx, []*pb.BattleContext{ctx})
+	if err != nil {
+		return err
+	}
+	if codes[0] != pb.BattleErrorCode_SUCCESS {
+		return &csharp.BattleError{Op: "InputBattle", Code: codes[0]}
+	}
+	return nil
+}
+
+// isLifecycleInput 改变战斗状态 (running/paused/finished/destroyed) 的输入
+func isLifecycleInput(ctx *pb.BattleContext) bool {
+	switch ctx.GetBattleInput().GetInput().(type) {
+	case *pb.BattleInput_Pause, *pb.BattleInput_Resume, *pb.BattleInput_End, *pb.BattleInput_Destroy:
+		return true
+	}
+	return false
+}
+
+// OnTick 以逻辑帧 logicFrameSeq 推进引擎，本帧产生的输出都标记为该帧
+func (bc *BattleController) OnTick(logicFrameSeq uint64) (int32, error) {
+	return bc.engine.OnTick(logicFrameSeq)
 }
 
 func (bc *BattleController) DestroyBattle(battleId uint64) {
-	csharp.DestroyBattle(battleId)
+	bc.engine.DestroyBattle(battleId)
 	bc.battleMap[battleId] = nil
 }
@@ -39,18 +39,28 @@ func NewBattleContextBuilder(host BattleContextHost) *BattleMsgContextBuilder {
 	}
 }
 
-// InjectInput 注入战斗输入
-// inputType: 输入类型（对应 BattleInputOperation）
-// inputData: 输入数据（会写入外部提供的缓冲）
-// 返回: 写入的字节数，错误信息
-func (bcb *BattleMsgContextBuilder) InjectInput(battleID uint32, inputData proto.Message) (int, error) {
+// BuildInput 构建战斗输入 BattleContext
+// inputData: BattleInput 的 oneof 成员，或已构建好的输入 BattleContext (原样返回)
+func (bcb *BattleMsgContextBuilder) BuildInput(battleID uint32, inputData proto.Message) (*pb.BattleContext, error) {
+	return buildInputContext(battleID, bcb.host.GetCurrentFrame(), inputData)
+}
+
+// buildInputContext 把 BattleInput 的 oneof 成员包装为第 frame 帧的输入 BattleContext
+// 已构建好的输入 BattleContext 原样返回
+func buildInputContext(battleID uint32, frame uint64, inputData proto.Message) (*pb.BattleContext, error) {
 	if inputData == nil {
-		return 0, fmt.Errorf("inputData cannot be nil")
+		return nil, fmt.Errorf("inputData cannot be nil")
 	}
 
 	// 根据不同的操作类型设置 oneof 字段
 	battleInput := &pb.BattleInput{}
 	switch input := inputData.(type) {
+	case *pb.BattleContext:
+		if input.GetBattleInput() == nil {
+			return nil, fmt.Errorf("BattleContext is not an input: %v", input)
+		}
+		return input, nil
+
 	case *pb.BattleUseItem:
 		battleInput.Input = &pb.BattleInput_Use{Use: input}
 
@@ -63,15 +73,32 @@ func (bcb *BattleMsgContextBuilder) InjectInput(battleID uint32, inputData proto
 	case *pb.BattleUserOp:
 		battleInput.Input = &pb.BattleInput_UserOp{UserOp: input}
 
+	case *pb.BattleEnd:
+		battleInput.Input = &pb.BattleInput_End{End: input}
+
+	case *pb.BattleDestroy:
+		battleInput.Input = &pb.BattleInput_Destroy{Destroy: input}
+
 	default:
-		return 0, fmt.Errorf("unsupported input operation: %v", inputData)
+		return nil, fmt.Errorf("unsupported input operation: %v", inputData)
 	}
 
 	// 根据输入类型构建对应的 BattleInput 对象
-	battleInputCtx := &pb.BattleContext{
+	return &pb.BattleContext{
 		BattleId: battleID,
-		Tick:     bcb.host.GetCurrentFrame(),
+		Tick:     frame,
 		Option:   &pb.BattleContext_BattleInput{BattleInput: battleInput},
+	}, nil
+}
+
+// InjectInput 注入战斗输入
+// inputType: 输入类型（对应 BattleInputOperation）
+// inputData: 输入数据（会写入外部提供的缓冲）
+// 返回: 写入的字节数，错误信息
+func (bcb *BattleMsgContextBuilder) InjectInput(battleID uint32, inputData proto.Message) (int, error) {
+	battleInputCtx, err := bcb.BuildInput(battleID, inputData)
+	if err != nil {
+		return 0, err
 	}
 
 	// 获取外部提供的输入缓冲
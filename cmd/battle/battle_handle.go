@@ -0,0 +1,265 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+
+	pb "goPureWithCsharp/csharp/proto"
+
+	"google.golang.org/protobuf/proto"
+)
+
+// ============================================================================
+// BattleHandle - Submit 返回的单场战斗句柄
+// ============================================================================
+//
+// 句柄在创建战斗之前订阅该战斗的输出，收到回放 (战斗的最后一条输出) 后完成。
+// Submit 的 ctx 在战斗完成前取消，或调用 Cancel 时销毁战斗，Wait 返回 ErrBattleCanceled；
+// 战斗没有输出回放就被分片销毁 (BattleDestroy 输入、保留期满清理等) 时，Wait 返回 ErrBattleDestroyed。
+
+var (
+	// ErrBattleCanceled 战斗在完成前被取消并销毁
+	ErrBattleCanceled = errors.New("战斗已取消")
+	// ErrBattleDestroyed 战斗在输出回放前被销毁
+	ErrBattleDestroyed = errors.New("战斗已销毁")
+	// ErrBattleManagerStopped BattleManager 在战斗完成前停止
+	ErrBattleManagerStopped = errors.New("BattleManager 已停止")
+)
+
+// BattleHandle 一场已提交的战斗
+type BattleHandle struct {
+	id  uint64
+	bm  *BattleManager
+	sub *Subscription
+
+	mu       sync.Mutex
+	canceled bool          // Cancel 已发出销毁，销毁时以 ErrBattleCanceled 完成
+	done     chan struct{} // 完成、取消或失败时关闭
+	result   *pb.BattleResult
+	replay   *pb.BattleReplay
+	err      error
+}
+
+// Submit 创建一场战斗并返回它的句柄
+// env.BattleId 为 0 时由 GenerateBattleID 分配；战斗创建失败时返回错误
+// ctx 在战斗完成前取消时销毁战斗
+func (bm *BattleManager) Submit(ctx context.Context, env *pb.BattleEnv) (*BattleHandle, error) {
+	if !bm.IsRunning() {
+		return nil, fmt.Errorf("BattleManager 未运行")
+	}
+
+	bm.assignBattleID(env)
+	h := &BattleHandle{
+		id:   uint64(env.BattleId),
+		bm:   bm,
+		done: make(chan struct{}),
+	}
+	// 先订阅再创建，不会错过很快结束的战斗的输出
+	h.sub = bm.Subscribe(EventFilter{BattleIDs: []uint32{env.BattleId}, Topics: TopicOutput}, 2, LaneOptions{Policy: OverflowBlock})
+	bm.trackHandle(h)
+
+	cmd := &CreateBattleCommand{Env: env, ResChan: make(chan error, 1)}
+	select {
+	case bm.shardFor(h.id).createChan <- cmd:
+	case <-ctx.Done():
+		h.finish(nil, nil, ctx.Err())
+		return nil, ctx.Err()
+	case <-bm.stopChan:
+		h.finish(nil, nil, ErrBattleManagerStopped)
+		return nil, ErrBattleManagerStopped
+	}
+
+	select {
+	case err := <-cmd.ResChan:
+		if err != nil {
+			h.finish(nil, nil, err)
+			return nil, err
+		}
+	case <-ctx.Done():
+		// 创建命令已发出，创建成功后立即销毁
+		go func() {
+			if err := <-cmd.ResChan; err == nil {
+				h.Cancel()
+			} else {
+				h.finish(nil, nil, err)
+			}
+		}()
+		return nil, ctx.Err()
+	case <-bm.stopChan:
+		h.finish(nil, nil, ErrBattleManagerStopped)
+		return nil, ErrBattleManagerStopped
+	}
+
+	go h.watch(ctx)
+	return h, nil
+}
+
+// ID 战斗 ID
+func (h *BattleHandle) ID() uint64 {
+	return h.id
+}
+
+// Input 在战斗所在分片提交一条输入
+// input 为 BattleInput 的 oneof 成员 (BattleUseItem、BattlePause 等)；生命周期输入被拒绝时返回带 BattleErrorCode 的错误
+func (h *BattleHandle) Input(input proto.Message) error {
+	ctx, err := buildInputContext(uint32(h.id), h.bm.fpsProvider.GetCurrentFrame(), input)
+	if err != nil {
+		return err
+	}
+
+	cmd := &InputCommand{Ctx: ctx, ResChan: make(chan error, 1)}
+	select {
+	case h.bm.shardFor(h.id).inputChan <- cmd:
+	case <-h.done:
+		return fmt.Errorf("战斗 %d 已结束: %w", h.id, h.err)
+	case <-h.bm.stopChan:
+		return ErrBattleManagerStopped
+	}
+	select {
+	case err := <-cmd.ResChan:
+		return err
+	case <-h.bm.stopChan:
+		return ErrBattleManagerStopped
+	}
+}
+
+// Wait 等待战斗结束，返回战斗结果和回放
+// 战斗被取消时返回 ErrBattleCanceled，ctx 结束时返回 ctx.Err() (战斗继续进行)
+func (h *BattleHandle) Wait(ctx context.Context) (*pb.BattleResult, *pb.BattleReplay, error) {
+	select {
+	case <-h.done:
+		return h.result, h.replay, h.err
+	case <-ctx.Done():
+		return nil, nil, ctx.Err()
+	}
+}
+
+// Done 战斗完成、取消或失败时关闭
+func (h *BattleHandle) Done() <-chan struct{} {
+	return h.done
+}
+
+// Cancel 在战斗所在分片的事件循环中销毁尚未结束的战斗，之后 Wait 返回 ErrBattleCanceled；已结束的战斗不受影响
+func (h *BattleHandle) Cancel() error {
+	h.mu.Lock()
+	if h.canceled || h.isDone() {
+		h.mu.Unlock()
+		return nil
+	}
+	h.canceled = true
+	h.mu.Unlock()
+
+	err := h.destroy()
+	h.finish(nil, nil, ErrBattleCanceled)
+	return err
+}
+
+// destroyed 分片销毁战斗后调用，尚未完成的句柄以 ErrBattleDestroyed 完成 (Cancel 发起的为 ErrBattleCanceled)
+func (h *BattleHandle) destroyed() {
+	h.mu.Lock()
+	err := ErrBattleDestroyed
+	if h.canceled {
+		err = ErrBattleCanceled
+	}
+	h.mu.Unlock()
+	h.finish(nil, nil, err)
+}
+
+// destroy 在战斗所在分片的事件循环中销毁战斗
+func (h *BattleHandle) destroy() error {
+	cmd := &InputCommand{Ctx: &pb.BattleContext{BattleId: uint32(h.id)}, Destroy: true, ResChan: make(chan error, 1)}
+	select {
+	case h.bm.shardFor(h.id).inputChan <- cmd:
+	case <-h.bm.stopChan:
+		return ErrBattleManagerStopped
+	}
+	select {
+	case err := <-cmd.ResChan:
+		return err
+	case <-h.bm.stopChan:
+		return ErrBattleManagerStopped
+	}
+}
+
+// watch 收集战斗输出，ctx 在战斗结束前取消时销毁战斗
+func (h *BattleHandle) watch(ctx context.Context) {
+	var result *pb.BattleResult
+	for {
+		select {
+		case event, ok := <-h.sub.C():
+			if !ok {
+				h.finish(nil, nil, ErrBattleManagerStopped)
+				return
+			}
+			output := event.GetBattleOutput()
+			if r := output.GetResult(); r != nil {
+				result = r
+			}
+			if replay := output.GetReplay(); replay != nil {
+				// 回放先于结果到达时使用回放中的结果
+				if result == nil {
+					result = replay.GetResult()
+				}
+				h.finish(result, replay, nil)
+				return
+			}
+		case <-ctx.Done():
+			h.Cancel()
+			return
+		case <-h.done:
+			return
+		}
+	}
+}
+
+// finish 只记录第一次完成，之后取消订阅并不再跟踪该句柄
+func (h *BattleHandle) finish(result *pb.BattleResult, replay *pb.BattleReplay, err error) {
+	h.mu.Lock()
+	if h.isDone() {
+		h.mu.Unlock()
+		return
+	}
+	h.result, h.replay, h.err = result, replay, err
+	close(h.done)
+	h.mu.Unlock()
+
+	h.sub.Unsubscribe()
+	h.bm.untrackHandle(h)
+}
+
+// isDone 调用方需持有 h.mu
+func (h *BattleHandle) isDone() bool {
+	select {
+	case <-h.done:
+		return true
+	default:
+		return false
+	}
+}
+
+// trackHandle 记录尚未完成的句柄，战斗被分片销毁时完成它
+func (bm *BattleManager) trackHandle(h *BattleHandle) {
+	bm.handlesMu.Lock()
+	defer bm.handlesMu.Unlock()
+	bm.handles[h.id] = h
+}
+
+func (bm *BattleManager) untrackHandle(h *BattleHandle) {
+	bm.handlesMu.Lock()
+	defer bm.handlesMu.Unlock()
+	if bm.handles[h.id] == h {
+		delete(bm.handles, h.id)
+	}
+}
+
+// battleDestroyed 战斗被销毁后调用，完成该战斗尚未完成的句柄
+func (bm *BattleManager) battleDestroyed(battleID uint64) {
+	bm.handlesMu.Lock()
+	h := bm.handles[battleID]
+	bm.handlesMu.Unlock()
+	if h != nil {
+		h.destroyed()
+	}
+}
@@ -0,0 +1,175 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+
+	"goPureWithCsharp/csharp"
+	pb "goPureWithCsharp/csharp/proto"
+
+	"google.golang.org/protobuf/proto"
+)
+
+// ============================================================================
+// BattleJournal - 按战斗记录环境、输入和输出，供 cmd/replay 重新执行
+// ============================================================================
+//
+// 战斗在创建后的第一次 processTick 才执行第一回合，输入在下一次 processTick 才被应用，
+// 因此环境和输入先暂存，由 Tick 以实际的帧号写入。
+// 生命周期输入在提交时立即应用，早于同一帧收集的其他输入，写入时排在它们之前，复算按相同顺序应用。
+// 战斗结束时依次输出结果和回放，回放写入后关闭该战斗的日志文件；
+// 没有输出回放就被销毁的战斗 (取消、保留期满清理) 在 Destroyed 中关闭。
+
+// BattleJournal 战斗日志目录，为空指针时所有方法都不做任何事
+type BattleJournal struct {
+	mu               sync.Mutex
+	dir              string
+	writers          map[uint32]*csharp.JournalWriter
+	pendingEnvs      []*pb.BattleEnv     // 等待执行第一回合的战斗
+	pendingInputs    []*pb.BattleContext // 等待下一帧应用的输入
+	pendingLifecycle []*pb.BattleContext // 已立即应用的生命周期输入
+}
+
+// NewBattleJournal 在 dir 下记录战斗日志，目录不存在时创建
+func NewBattleJournal(dir string) (*BattleJournal, error) {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, fmt.Errorf("创建战斗日志目录失败: %w", err)
+	}
+	return &BattleJournal{
+		dir:     dir,
+		writers: make(map[uint32]*csharp.JournalWriter),
+	}, nil
+}
+
+// JournalPath 战斗日志文件路径
+func (j *BattleJournal) JournalPath(battleID uint32) string {
+	return filepath.Join(j.dir, fmt.Sprintf("battle_%d.journal", battleID))
+}
+
+// Begin 战斗创建成功后调用，环境在下一次 Tick 时写入
+func (j *BattleJournal) Begin(battleID uint32, env *pb.BattleEnv) {
+	if j == nil {
+		return
+	}
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	w, err := csharp.CreateJournal(j.JournalPath(battleID))
+	if err != nil {
+		fmt.Printf("[BattleJournal] 战斗 %d: %v\n", battleID, err)
+		return
+	}
+	if old, ok := j.writers[battleID]; ok {
+		_ = old.Close()
+	}
+	j.writers[battleID] = w
+
+	env = proto.Clone(env).(*pb.BattleEnv)
+	env.BattleId = battleID
+	j.pendingEnvs = append(j.pendingEnvs, env)
+}
+
+// Input 输入提交成功后调用，在下一次 Tick 时以应用它的帧写入
+func (j *BattleJournal) Input(ctx *pb.BattleContext) {
+	if j == nil {
+		return
+	}
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	ctx = proto.Clone(ctx).(*pb.BattleContext)
+	if isLifecycleInput(ctx) {
+		j.pendingLifecycle = append(j.pendingLifecycle, ctx)
+		return
+	}
+	j.pendingInputs = append(j.pendingInputs, ctx)
+}
+
+// Tick 在第 frame 帧的 OnTick 之前调用，写入暂存的环境和输入
+func (j *BattleJournal) Tick(frame uint64) {
+	if j == nil {
+		return
+	}
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	for _, env := range j.pendingEnvs {
+		if w, ok := j.writers[env.GetBattleId()]; ok {
+			j.report(env.GetBattleId(), w.WriteEnv(env, frame))
+		}
+	}
+	for _, pending := range [][]*pb.BattleContext{j.pendingLifecycle, j.pendingInputs} {
+		for _, ctx := range pending {
+			if w, ok := j.writers[ctx.GetBattleId()]; ok {
+				ctx.Tick = frame
+				j.report(ctx.GetBattleId(), w.WriteContext(ctx))
+			}
+		}
+	}
+	j.pendingEnvs = j.pendingEnvs[:0]
+	j.pendingLifecycle = j.pendingLifecycle[:0]
+	j.pendingInputs = j.pendingInputs[:0]
+
+	// 每帧落盘一次，进程退出时最多丢失最后一帧
+	for battleID, w := range j.writers {
+		j.report(battleID, w.Flush())
+	}
+}
+
+// Output 写入战斗输出，回放 (战斗的最后一条输出) 写入后关闭该战斗的日志
+func (j *BattleJournal) Output(ctx *pb.BattleContext) {
+	if j == nil {
+		return
+	}
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	battleID := ctx.GetBattleId()
+	w, ok := j.writers[battleID]
+	if !ok {
+		return
+	}
+	j.report(battleID, w.WriteContext(ctx))
+	if ctx.GetBattleOutput().GetReplay() != nil {
+		j.report(battleID, w.Close())
+		delete(j.writers, battleID)
+	}
+}
+
+// Destroyed 战斗从 Proxy 中销毁后调用，关闭该战斗的日志，之后的 Tick 不再落盘它
+// 已写入回放的战斗日志已关闭，不做任何事
+func (j *BattleJournal) Destroyed(battleID uint32) {
+	if j == nil {
+		return
+	}
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	if w, ok := j.writers[battleID]; ok {
+		j.report(battleID, w.Close())
+		delete(j.writers, battleID)
+	}
+}
+
+// Close 关闭所有未结束战斗的日志
+func (j *BattleJournal) Close() {
+	if j == nil {
+		return
+	}
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	for battleID, w := range j.writers {
+		j.report(battleID, w.Close())
+	}
+	j.writers = make(map[uint32]*csharp.JournalWriter)
+}
+
+// report 日志写入失败不影响战斗，只打印
+func (j *BattleJournal) report(battleID uint32, err error) {
+	if err != nil {
+		fmt.Printf("[BattleJournal] 战斗 %d: %v\n", battleID, err)
+	}
+}
@@ -1,7 +1,13 @@
 package main
 
 import (
+	"context"
+	"errors"
 	"fmt"
+	"maps"
+	"os"
+	"path/filepath"
+	"slices"
 	"sync"
 	"time"
 
@@ -11,12 +17,23 @@ import (
 	"google.golang.org/protobuf/proto"
 )
 
-// CreateBattleCommand 创建战斗命令
+// CreateBattleCommand 创建战斗命令，在战斗所在分片的事件循环中执行
 type CreateBattleCommand struct {
-	BattleID  uint64
-	AtkTeamID uint32
-	DefTeamID uint32
-	ResChan   chan error // 返回错误结果
+	Env     *pb.BattleEnv // BattleId 已分配
+	ResChan chan error    // 返回错误结果，为空时不返回
+}
+
+// InputCommand 战斗输入命令，在战斗所在分片的事件循环中执行
+type InputCommand struct {
+	Ctx     *pb.BattleContext
+	Destroy bool       // 为 true 时不提交输入，从 Proxy 和 C# 中销毁 Ctx 所属的战斗
+	ResChan chan error // 返回错误结果
+}
+
+// ReloadCommand 热重载命令，在事件循环中执行
+type ReloadCommand struct {
+	Opts    csharp.LoadOptions // 新库的查找选项
+	ResChan chan error         // 返回错误结果
 }
 
 // BattleOutputEvent 战斗输出事件
@@ -36,56 +53,8 @@ const (
 	StateStopped
 )
 
-// ============================================================================
-type EventBus interface {
-	SubscribeCtx() <-chan *pb.BattleContext
-	Publish(outputEv *pb.BattleContext)
-}
-
-// EventBusImpl 事件总线实现
-type EventBusImpl struct {
-	eventChan chan *pb.BattleContext
-	mu        sync.RWMutex
-	closed    bool
-}
-
-func NewEventBus(bufferSize int) *EventBusImpl {
-	return &EventBusImpl{
-		eventChan: make(chan *pb.BattleContext, bufferSize),
-	}
-}
-
-func (eb *EventBusImpl) SubscribeCtx() <-chan *pb.BattleContext {
-	return eb.eventChan
-}
-
-func (eb *EventBusImpl) Publish(event *pb.BattleContext) {
-	eb.mu.RLock()
-	closed := eb.closed
-	eb.mu.RUnlock()
-	if closed {
-		return
-	}
-	select {
-	case eb.eventChan <- event:
-	default:
-		switch event.Option.(type) {
-		case *pb.BattleContext_BattleInput:
-			fmt.Printf("[EventBus] !!!!!!!!!!!!!!!事件队列已满， battId %d 丢弃输入\n", event.BattleId)
-		case *pb.BattleContext_BattleOutput:
-			fmt.Printf("[EventBus] !!!!!!!!!!!!!!!事件队列已满， battId %d 丢弃输出\n", event.BattleId)
-		}
-	}
-}
-
-func (eb *EventBusImpl) Close() {
-	eb.mu.Lock()
-	defer eb.mu.Unlock()
-	if !eb.closed {
-		close(eb.eventChan)
-		eb.closed = true
-	}
-}
+// defaultCallTimeout 事件循环中单次 C# 调用的默认超时
+const defaultCallTimeout = 5 * time.Second
 
 // ============================================================================
 // BattleDispatcher - 战斗调度接口
@@ -102,15 +71,128 @@ type Proxy struct {
 	mu                sync.RWMutex
 	bcMap             map[uint64]*BattleController
 	frameSeqGenerator FrameSeqProvider
+	engine            csharp.Engine         // 战斗所在的引擎实例，由 BattleManager.Init 绑定
+	transport         *csharp.RingTransport // 引擎绑定的环形缓冲区，为空时输入收集到所在分片的 batch
+	batches           []*InputBatch         // 每个分片一帧内收集的输入，分片 processTick 时批量提交
+	effects           *csharp.EffectTable   // 与引擎加载的配置相同的效果表，输入入队前校验
+	callTimeout       time.Duration         // 立即应用的输入每次 C# 调用的超时，由 BattleManager.Init 绑定
+	onDestroy         func(battleID uint64) // 战斗销毁后调用，由 BattleManager.Init 绑定
+}
+
+// EngineBinder 由需要访问引擎实例的调度器实现
+type EngineBinder interface {
+	BindEngine(e csharp.Engine)
+}
+
+// CallTimeoutBinder 由在分片事件循环中直接调用引擎的调度器实现，调用受 BattleManager 的超时约束
+type CallTimeoutBinder interface {
+	BindCallTimeout(d time.Duration)
+}
+
+// TransportBinder 由支持环形缓冲区输入的调度器实现
+type TransportBinder interface {
+	BindTransport(t *csharp.RingTransport)
+}
+
+// EffectTableBinder 由需要在输入入队前校验道具和用户操作的调度器实现
+type EffectTableBinder interface {
+	BindEffectTable(t *csharp.EffectTable)
+}
+
+// DestroyHookBinder 由需要在战斗销毁后通知 BattleManager 的调度器实现 (关闭战斗日志等)
+type DestroyHookBinder interface {
+	BindDestroyHook(fn func(battleID uint64))
+}
+
+// BattleTracker 由记录了存活战斗的调度器实现，不健康的库被替换后据此清理丢失的战斗
+type BattleTracker interface {
+	TrackedBattles() []uint64
+}
+
+// ShardBinder 由按分片创建战斗和收集输入的调度器实现
+// 在创建任何战斗之前调用，分片数与 BattleManager 相同
+type ShardBinder interface {
+	BindShards(n int)
+}
+
+// BattleStatusProvider 由能查询存活战斗状态的调度器实现
+type BattleStatusProvider interface {
+	GetBattleStatus(battleID uint64) (*pb.BattleStatus, error)
+	ListBattles() ([]*pb.BattleStatus, error)
 }
 
 func NewProxy(frameSeqGenerator FrameSeqProvider) *Proxy {
 	return &Proxy{
 		bcMap:             make(map[uint64]*BattleController),
 		frameSeqGenerator: frameSeqGenerator,
+		batches:           []*InputBatch{NewInputBatch()},
+	}
+}
+
+// BindShards 实现 ShardBinder 接口，每个分片一个输入批次
+func (p *Proxy) BindShards(n int) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	p.batches = make([]*InputBatch, max(n, 1))
+	for i := range p.batches {
+		p.batches[i] = NewInputBatch()
+	}
+}
+
+// BindEngine 绑定战斗引擎实例，之后创建的战斗都路由到该实例
+// 热重载后重新绑定时，已有的战斗控制器也切换到新实例
+func (p *Proxy) BindEngine(e csharp.Engine) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	p.engine = e
+	for _, bc := range p.bcMap {
+		bc.engine = e
 	}
 }
 
+// BindTransport 绑定环形缓冲区，之后的输入写入环形缓冲区
+func (p *Proxy) BindTransport(t *csharp.RingTransport) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	p.transport = t
+	for _, bc := range p.bcMap {
+		bc.transport = t
+	}
+}
+
+// BindEffectTable 绑定效果表，之后的道具和用户操作输入在入队前校验
+func (p *Proxy) BindEffectTable(t *csharp.EffectTable) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	p.effects = t
+	for _, bc := range p.bcMap {
+		bc.effects = t
+	}
+}
+
+// BindCallTimeout 实现 CallTimeoutBinder 接口，之后立即应用的输入受该超时约束
+func (p *Proxy) BindCallTimeout(d time.Duration) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	p.callTimeout = d
+	for _, bc := range p.bcMap {
+		bc.callTimeout = d
+	}
+}
+
+// BindDestroyHook 实现 DestroyHookBinder 接口，DestroyBattle 成功后调用 fn
+func (p *Proxy) BindDestroyHook(fn func(battleID uint64)) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	p.onDestroy = fn
+}
+
 func (p *Proxy) CreateBattle(battleID uint64, env *pb.BattleEnv) error {
 	p.mu.Lock()
 	defer p.mu.Unlock()
@@ -119,17 +201,24 @@ func (p *Proxy) CreateBattle(battleID uint64, env *pb.BattleEnv) error {
 		return fmt.Errorf("战斗 %d 已存在", battleID)
 	}
 
-	bc := NewBattleController(p.frameSeqGenerator, p)
-
-	var atkTeamID, defTeamID uint32
-	if env.Atk != nil {
-		atkTeamID = env.Atk.TeamId
+	if p.engine == nil {
+		return fmt.Errorf("战斗引擎未绑定")
 	}
-	if env.Def != nil {
-		defTeamID = env.Def.TeamId
+
+	shard := shardOf(battleID, len(p.batches))
+	bc := NewBattleController(p.frameSeqGenerator, p, p.engine)
+	bc.transport = p.transport
+	bc.batch = p.batches[shard]
+	bc.effects = p.effects
+	bc.callTimeout = p.callTimeout
+
+	// 战斗 ID 和未指定的种子写回 env，复算时使用
+	env.BattleId = uint32(battleID)
+	if env.Seed == 0 {
+		env.Seed = csharp.NewBattleSeed()
 	}
 
-	if err := csharp.CreateBattle(uint32(battleID), atkTeamID, defTeamID); err != nil {
+	if err := p.engine.CreateShardBattle(shard, env); err != nil {
 		return fmt.Errorf("C# 创建战斗失败: %w", err)
 	}
 
@@ -138,6 +227,9 @@ func (p *Proxy) CreateBattle(battleID uint64, env *pb.BattleEnv) error {
 	return nil
 }
 
+// InputBattle 提交一条战斗输入
+// 生命周期输入立即应用，当前战斗状态不允许时返回的错误可用 csharp.ErrorCode 取得 BattleErrorCode
+// (例如 INVALID_BATTLE_STATE)；其他输入在下一帧应用，被拒绝时只记录日志
 func (p *Proxy) InputBattle(battleID uint64, inputData proto.Message) error {
 	p.mu.RLock()
 	bc, exists := p.bcMap[battleID]
@@ -154,20 +246,67 @@ func (p *Proxy) DestroyBattle(battleID uint64) error {
 	p.mu.Lock()
 	defer p.mu.Unlock()
 
-	_, exists := p.bcMap[battleID]
+	bc, exists := p.bcMap[battleID]
 	if !exists {
 		return fmt.Errorf("战斗 %d 不存在", battleID)
 	}
 
-	if err := csharp.DestroyBattle(battleID); err != nil {
+	// 引擎中已没有该战斗 (不健康的库被替换后丢失) 时只移除控制器
+	if err := bc.engine.DestroyBattle(battleID); err != nil && !errors.Is(err, csharp.ErrBattleNotFound) {
 		return fmt.Errorf("C# 销毁战斗失败: %w", err)
 	}
 
 	delete(p.bcMap, battleID)
+	if p.onDestroy != nil {
+		p.onDestroy(battleID)
+	}
 
 	return nil
 }
 
+// FlushInputs 实现 InputFlusher 接口，一次提交 shardID 分片本帧收集的全部输入
+func (p *Proxy) FlushInputs(ctx context.Context, shardID uint32, frame uint64) (int, error) {
+	p.mu.RLock()
+	engine := p.engine
+	batch := p.batches[shardID]
+	p.mu.RUnlock()
+
+	return batch.Flush(ctx, engine, frame)
+}
+
+// GetBattleStatus 实现 BattleStatusProvider 接口，从引擎读取战斗的回合、血量和状态
+func (p *Proxy) GetBattleStatus(battleID uint64) (*pb.BattleStatus, error) {
+	p.mu.RLock()
+	_, exists := p.bcMap[battleID]
+	engine := p.engine
+	p.mu.RUnlock()
+
+	if !exists {
+		return nil, fmt.Errorf("战斗 %d 不存在", battleID)
+	}
+	return engine.GetBattleStatus(uint32(battleID))
+}
+
+// ListBattles 实现 BattleStatusProvider 接口，返回引擎中所有战斗的状态
+func (p *Proxy) ListBattles() ([]*pb.BattleStatus, error) {
+	p.mu.RLock()
+	engine := p.engine
+	p.mu.RUnlock()
+
+	if engine == nil {
+		return nil, fmt.Errorf("战斗引擎未绑定")
+	}
+	return engine.ListBattles()
+}
+
+// TrackedBattles 实现 BattleTracker 接口，返回所有战斗控制器的 ID
+func (p *Proxy) TrackedBattles() []uint64 {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+
+	return slices.Collect(maps.Keys(p.bcMap))
+}
+
 // GetBattleController 获取战斗控制器（内部使用）
 func (p *Proxy) GetBattleController(battleID uint64) (*BattleController, bool) {
 	p.mu.RLock()
@@ -183,8 +322,8 @@ func (p *Proxy) DisptcherShutDown() error {
 	defer p.mu.Unlock()
 
 	// 销毁所有战斗
-	for battleID := range p.bcMap {
-		if err := csharp.DestroyBattle(battleID); err != nil {
+	for battleID, bc := range p.bcMap {
+		if err := bc.engine.DestroyBattle(battleID); err != nil {
 			fmt.Printf("[Proxy] 销毁战斗 %d 失败: %v\n", battleID, err)
 		}
 	}
@@ -251,19 +390,40 @@ func ResetBattleManager() {
 // ============================================================================
 // BattleManager - 战斗管理器
 // ============================================================================
+// 战斗按 ID 哈希到多个 BattleShard，每个分片在自己的 goroutine 中创建、输入和驱动战斗；
+// run 只把创建命令和事件总线上的 BattleContext 路由到所在分片，并串行执行热重载
 type BattleManager struct {
 	EventBus // 嵌入 EventBus 接口
 
-	fpsProvider FrameSeqProvider
-	createChan  chan *pb.BattleEnv // 创建战斗命令通道
-	outPutChan  chan *pb.BattleContext
+	fpsProvider   FrameSeqProvider
+	frameInterval time.Duration // 逻辑帧间隔，每个分片的事件循环按此间隔唤醒自己的 tickDriver
+	shards        []*BattleShard
+	createChan    chan *pb.BattleEnv // 创建战斗命令通道
+	inputSub      *Subscription      // 路由循环订阅的战斗输入
+	reloadChan    chan *ReloadCommand
+	outPutChan    chan *pb.BattleContext
 
 	battleCtrls BattleDisptcher
+	output      BattleOutput       // 接收战斗结果和回放，为空时只透传到 outPutChan
+	engine      csharp.Engine      // 为空时 Init 按 loadOpts 打开 C# 动态库；运行后经 currentEngine 读取
+	loadOpts    csharp.LoadOptions // 默认加载 Release 版
+	callTimeout time.Duration      // 单次 C# 调用超时，超时后引擎不健康，只能 Reload
+
+	ringCapacity int                   // 环形缓冲区大小，0 表示不使用
+	transport    *csharp.RingTransport // 引擎支持且只有一个分片时在 Init 中绑定
+
+	journalDir string // 战斗日志目录，为空时不记录；每个分片在 Init 中创建自己的 BattleJournal
+
+	retention RetentionPolicy // 结束的战斗的保留策略，每个分片按此清理自己的战斗
+
+	handlesMu sync.Mutex
+	handles   map[uint64]*BattleHandle // Submit 返回的尚未完成的句柄
 
 	// 状态管理
 	mu       sync.RWMutex
 	state    BattleManagerState
 	stopChan chan struct{}
+	loops    sync.WaitGroup // 路由和分片事件循环，Stop 等它们退出后再释放引擎
 }
 
 func NewBattleManager(fps int64) *BattleManager {
@@ -274,28 +434,224 @@ func NewBattleManager(fps int64) *BattleManager {
 
 func (bm *BattleManager) Init() error {
 
-	err := csharp.InitCSharpLib("Release")
-	if err != nil {
-		fmt.Printf("[Battle] ✗ C# 库加载失败: %v\n", err)
+	if bm.engine == nil {
+		lib, err := csharp.OpenLibraryWithOptions(bm.loadOpts)
+		if err != nil {
+			fmt.Printf("[Battle] ✗ C# 库加载失败: %v\n", err)
+			return err
+		}
+		bm.engine = lib
+	}
+
+	if len(bm.shards) > 1 && !bm.engine.HasFeature(csharp.FeatureShards) {
+		err := fmt.Errorf("引擎不支持 %s，无法使用 %d 个分片", csharp.FeatureShards, len(bm.shards))
+		fmt.Printf("[Battle] ✗ %v\n", err)
 		return err
 	}
 
-	err = bm.prepareCallback()
+	if binder, ok := bm.battleCtrls.(EngineBinder); ok {
+		binder.BindEngine(bm.engine)
+	}
+	if binder, ok := bm.battleCtrls.(CallTimeoutBinder); ok {
+		binder.BindCallTimeout(bm.callTimeout)
+	}
+
+	err := bm.prepareCallback()
 	if err != nil {
 		fmt.Printf("[Battle] ✗ 回调准备失败: %v\n", err)
 		return err
 	}
 
+	if err := bm.loadBattleConfigs(); err != nil {
+		fmt.Printf("[Battle] ✗ 战斗配置加载失败: %v\n", err)
+		return err
+	}
+
+	if err := bm.attachTransport(); err != nil {
+		fmt.Printf("[Battle] ✗ 环形缓冲区绑定失败: %v\n", err)
+		return err
+	}
+
+	if bm.journalDir != "" {
+		for _, shard := range bm.shards {
+			journal, err := NewBattleJournal(bm.journalDir)
+			if err != nil {
+				fmt.Printf("[Battle] ✗ %v\n", err)
+				return err
+			}
+			shard.journal = journal
+		}
+	}
+	if binder, ok := bm.battleCtrls.(DestroyHookBinder); ok {
+		binder.BindDestroyHook(func(battleID uint64) {
+			bm.shardFor(battleID).journal.Destroyed(uint32(battleID))
+		})
+	}
+
+	return nil
+}
+
+// loadBattleConfigs 引擎通过配置加载器读取并解析单位表和道具、用户操作效果表
+// 效果表也在 Go 侧解析，绑定给调度器在输入入队前校验；热重载时由 csharp.Library 重新加载
+func (bm *BattleManager) loadBattleConfigs() error {
+	effects := csharp.NewEffectTable()
+	for _, name := range csharp.BattleConfigs {
+		if err := bm.engine.LoadConfig(name); err != nil {
+			return fmt.Errorf("引擎加载 %s 失败: %w", name, err)
+		}
+		data, err := os.ReadFile(filepath.Join(configDir, name))
+		if err != nil {
+			return err
+		}
+		if err := effects.Load(name, data); err != nil {
+			return err
+		}
+	}
+
+	if binder, ok := bm.battleCtrls.(EffectTableBinder); ok {
+		binder.BindEffectTable(effects)
+	}
+	return nil
+}
+
+// attachTransport 引擎支持时分配并绑定环形缓冲区，输入输出不再逐条跨越 FFI
+// 环形缓冲区是单生产者单消费者的，多个分片时不绑定；热重载时由 csharp.Library 自动绑定到新库
+func (bm *BattleManager) attachTransport() error {
+	if bm.ringCapacity <= 0 {
+		return nil
+	}
+	if len(bm.shards) > 1 {
+		fmt.Printf("[BattleManager] %d 个分片并行 Tick，不使用环形缓冲区\n", len(bm.shards))
+		return nil
+	}
+	if !bm.engine.HasFeature(csharp.FeatureRingTransport) {
+		fmt.Println("[BattleManager] 引擎不支持环形缓冲区，输入输出逐条调用")
+		return nil
+	}
+
+	t, err := csharp.NewRingTransport(bm.ringCapacity, bm.ringCapacity)
+	if err != nil {
+		return err
+	}
+	if err := bm.engine.AttachRingTransport(t); err != nil {
+		_ = t.Close()
+		return err
+	}
+
+	bm.transport = t
+	if binder, ok := bm.battleCtrls.(TransportBinder); ok {
+		binder.BindTransport(t)
+	}
 	return nil
 }
 
+// Reload 热重载 C# 动态库，存活的战斗和回调迁移到 opts 指定的新库
+// 在路由事件循环中执行；csharp.Library 在迁移期间阻塞各分片的调用，失败时继续使用旧库
+func (bm *BattleManager) Reload(opts csharp.LoadOptions) error {
+	if !bm.IsRunning() {
+		return fmt.Errorf("BattleManager 未运行")
+	}
+
+	cmd := &ReloadCommand{Opts: opts, ResChan: make(chan error, 1)}
+	select {
+	case bm.reloadChan <- cmd:
+	case <-bm.stopChan:
+		return fmt.Errorf("BattleManager 已停止")
+	}
+
+	select {
+	case err := <-cmd.ResChan:
+		return err
+	case <-bm.stopChan:
+		return fmt.Errorf("BattleManager 已停止")
+	}
+}
+
+// handleReload 处理热重载命令
+func (bm *BattleManager) handleReload(opts csharp.LoadOptions) error {
+	lib, ok := bm.engine.(*csharp.Library)
+	if !ok {
+		return fmt.Errorf("当前引擎 %T 不支持热重载", bm.engine)
+	}
+
+	// 不健康的库不迁移战斗，替换后清理它们
+	unhealthy := lib.Health()
+	next, err := lib.Reload(opts)
+	if err != nil {
+		fmt.Printf("[BattleManager] 热重载失败，继续使用旧库: %v\n", err)
+		return err
+	}
+
+	bm.mu.Lock()
+	bm.engine = next
+	bm.loadOpts = opts
+	bm.mu.Unlock()
+	if binder, ok := bm.battleCtrls.(EngineBinder); ok {
+		binder.BindEngine(next)
+	}
+	if unhealthy != nil {
+		bm.dropLostBattles()
+	}
+	fmt.Printf("[BattleManager] ✓ 热重载完成: %s\n", next.Path())
+	return nil
+}
+
+// dropLostBattles 替换不健康的库后调用，新库中没有旧库的战斗
+// 在各自分片的事件循环中销毁这些战斗，移除控制器、清理记录并以 ErrBattleDestroyed 完成它们的句柄
+func (bm *BattleManager) dropLostBattles() {
+	var battleIDs []uint64
+	if tracker, ok := bm.battleCtrls.(BattleTracker); ok {
+		battleIDs = tracker.TrackedBattles()
+	}
+	bm.handlesMu.Lock()
+	for battleID := range bm.handles {
+		if !slices.Contains(battleIDs, battleID) {
+			battleIDs = append(battleIDs, battleID)
+		}
+	}
+	bm.handlesMu.Unlock()
+
+	cmds := make([]*InputCommand, 0, len(battleIDs))
+	for _, battleID := range battleIDs {
+		cmd := &InputCommand{Ctx: &pb.BattleContext{BattleId: uint32(battleID)}, Destroy: true, ResChan: make(chan error, 1)}
+		select {
+		case bm.shardFor(battleID).inputChan <- cmd:
+			cmds = append(cmds, cmd)
+		case <-bm.stopChan:
+			return
+		}
+	}
+	for _, cmd := range cmds {
+		select {
+		case <-cmd.ResChan:
+		case <-bm.stopChan:
+			return
+		}
+	}
+	fmt.Printf("[BattleManager] 旧库不健康，已丢弃 %d 场未迁移的战斗\n", len(cmds))
+}
+
 func (bm *BattleManager) Dispose() error {
-	return csharp.CloseCSharpLib()
+	if bm.engine == nil {
+		return nil
+	}
+	if bm.transport != nil {
+		_ = bm.engine.AttachRingTransport(nil)
+	}
+	if err := bm.engine.Close(); err != nil {
+		// 引擎可能仍在访问共享内存，不释放环形缓冲区
+		return err
+	}
+	if bm.transport != nil {
+		_ = bm.transport.Close()
+		bm.transport = nil
+	}
+	return nil
 }
 
 func (bm *BattleManager) prepareCallback() error {
 
-	err := csharp.RegisterConfigLoader(loadConfig)
+	err := bm.engine.RegisterConfigLoader(loadConfig)
 	if err != nil {
 		return err
 	}
@@ -317,7 +673,8 @@ func (bm *BattleManager) prepareCallback() error {
 	// 	return err
 	// }
 
-	err = csharp.RegisterBattleEndNotify(battleOutput)
+	// 输出交给本实例中战斗所在的分片，而不是全局单例
+	err = bm.engine.RegisterBattleEndNotify(newBattleOutput(bm.collectOutput))
 	if err != nil {
 		return err
 	}
@@ -337,7 +694,10 @@ func (bm *BattleManager) Start() error {
 
 	}
 
-	go bm.run()
+	for _, shard := range bm.shards {
+		bm.loops.Go(shard.run)
+	}
+	bm.loops.Go(bm.run)
 	return nil
 }
 
@@ -353,22 +713,44 @@ func (bm *BattleManager) Stop() {
 
 	fmt.Println("[BattleManager] 停止运行...")
 
+	// 先等待事件循环退出，分片不再访问战斗后再销毁
+	close(bm.stopChan)
+	bm.loops.Wait()
+
 	// 销毁所有战斗
 	bm.battleCtrls.DisptcherShutDown()
 
 	// 关闭通道
 	close(bm.createChan)
+	bm.inputSub.Unsubscribe()
 	if eb, ok := bm.EventBus.(*EventBusImpl); ok {
 		eb.Close()
 	}
 
-	// 等待事件循环退出
-	close(bm.stopChan)
-
 	bm.Dispose()
+	for _, shard := range bm.shards {
+		shard.journal.Close()
+	}
 	fmt.Println("[BattleManager] ✓ 已停止")
 }
 
+// currentEngine 返回当前引擎，分片事件循环在热重载后读到新引擎
+func (bm *BattleManager) currentEngine() csharp.Engine {
+	bm.mu.RLock()
+	defer bm.mu.RUnlock()
+	return bm.engine
+}
+
+// Shards 返回所有分片，下标即分片 ID
+func (bm *BattleManager) Shards() []*BattleShard {
+	return bm.shards
+}
+
+// shardFor 返回战斗所在的分片
+func (bm *BattleManager) shardFor(battleID uint64) *BattleShard {
+	return bm.shards[shardOf(battleID, len(bm.shards))]
+}
+
 // IsRunning 返回是否正在运行
 func (bm *BattleManager) IsRunning() bool {
 	bm.mu.RLock()
@@ -376,12 +758,31 @@ func (bm *BattleManager) IsRunning() bool {
 	return bm.state == StateRunning
 }
 
+// GetBattleStatus 查询一场存活战斗的状态，供运维工具和匹配使用
+func (bm *BattleManager) GetBattleStatus(battleID uint64) (*pb.BattleStatus, error) {
+	provider, ok := bm.battleCtrls.(BattleStatusProvider)
+	if !ok {
+		return nil, fmt.Errorf("调度器不支持查询战斗状态")
+	}
+	return provider.GetBattleStatus(battleID)
+}
+
+// ListBattles 查询所有存活战斗的状态，供运维工具和匹配使用
+func (bm *BattleManager) ListBattles() ([]*pb.BattleStatus, error) {
+	provider, ok := bm.battleCtrls.(BattleStatusProvider)
+	if !ok {
+		return nil, fmt.Errorf("调度器不支持查询战斗状态")
+	}
+	return provider.ListBattles()
+}
+
 // 便捷访问方法
 func (bm *BattleManager) GetCreateChannel() chan<- *pb.BattleEnv {
 	return bm.createChan
 }
 
-// run 主事件循环
+// run 路由事件循环，创建命令和订阅到的战斗输入按战斗 ID 转发到所在分片
+// 战斗输出由所在分片在 OnTick 返回后直接处理，不经过路由循环
 func (bm *BattleManager) run() {
 	fmt.Println("[BattleManager] 启动事件循环")
 	defer func() {
@@ -394,16 +795,22 @@ func (bm *BattleManager) run() {
 		fmt.Println("[BattleManager] 事件循环已退出")
 	}()
 
-	ticker := time.NewTicker(time.Second * 1) // 每5秒处理一次逻辑帧 以免业务堆积
-	defer ticker.Stop()
+	inputs := bm.inputSub.C()
 	for {
 		select {
-		case cmd := <-bm.createChan:
-			bm.handleCreateBattle(cmd)
-		case ctx := <-bm.SubscribeCtx():
-			bm.handleProcessBattleCtx(ctx)
-		case <-ticker.C:
-			bm.processTick()
+		case env, ok := <-bm.createChan:
+			if !ok {
+				return
+			}
+			bm.assignBattleID(env)
+			route(bm.shardFor(uint64(env.BattleId)).createChan, &CreateBattleCommand{Env: env}, bm.stopChan)
+		case cmd := <-bm.reloadChan:
+			cmd.ResChan <- bm.handleReload(cmd.Opts)
+		case ctx, ok := <-inputs:
+			if !ok {
+				return
+			}
+			route(bm.shardFor(uint64(ctx.GetBattleId())).ctxChan, ctx, bm.stopChan)
 		case <-bm.stopChan:
 			fmt.Println("[BattleManager] 收到停止信号，退出事件循环")
 			return
@@ -411,60 +818,56 @@ func (bm *BattleManager) run() {
 	}
 }
 
-// handleCreateBattle 处理创建战斗命令
-func (bm *BattleManager) handleCreateBattle(e *pb.BattleEnv) error {
-	bId := uint64(e.BattleId)
-	if bId == 0 {
-		bId = uint64(GenerateBattleID())
-	}
-	fmt.Printf("[BattleManager] 创建战斗命令 - ID: %d\n", bId)
-	err := bm.battleCtrls.CreateBattle(bId, e)
-	if err != nil {
-		fmt.Printf("[BattleManager] 创建战斗失败: %v\n", err)
-		return err
+// route 把消息发送到分片的通道，分片通道满时等待，停止时放弃
+func route[T any](ch chan<- T, v T, stop <-chan struct{}) {
+	select {
+	case ch <- v:
+	case <-stop:
 	}
-	return nil
 }
 
-// processTick 处理逻辑帧事件
-func (bm *BattleManager) processTick() {
-	processed, err := csharp.OnTick()
-	if err != nil {
-		fmt.Printf("[BattleManager] OnTick 失败: %v\n", err)
-		return
-	}
-
-	if processed > 0 {
-		fmt.Printf("[BattleManager] 处理了 %d 场战斗\n", processed)
+// assignBattleID 未指定战斗 ID 时分配一个，分片按该 ID 选择
+func (bm *BattleManager) assignBattleID(e *pb.BattleEnv) {
+	if e.BattleId == 0 {
+		e.BattleId = uint32(GenerateBattleID())
 	}
 }
 
-func (bm *BattleManager) handleProcessBattleCtx(e *pb.BattleContext) error {
-	e.Tick = e.GetTick()
+// handleCreateBattle 在战斗所在的分片中同步创建战斗
+func (bm *BattleManager) handleCreateBattle(e *pb.BattleEnv) error {
+	bm.assignBattleID(e)
+	return bm.shardFor(uint64(e.BattleId)).handleCreateBattle(e)
+}
 
-	switch e.Option.(type) {
-	case *pb.BattleContext_BattleInput:
-		err := bm.battleCtrls.InputBattle(uint64(e.GetBattleId()), e)
-		if err != nil {
-			fmt.Printf("[BattleManager] 输入战斗失败: %v\n", err)
-			return err
+// processTick 依次执行所有分片的一个逻辑帧，返回各分片的错误
+func (bm *BattleManager) processTick(frame uint64) error {
+	var errs []error
+	for _, shard := range bm.shards {
+		if err := shard.processTick(frame); err != nil {
+			errs = append(errs, err)
 		}
-	case *pb.BattleContext_BattleOutput:
-
-		switch output := e.GetBattleOutput().Output.(type) {
-		case *pb.BattleOutput_Result:
-			fmt.Printf("[BattleManager] 战斗输出 - 结果: BattleID=%d, Winner=%d, Loser=%d\n", e.GetBattleId(),
-				output.Result.GetWinner(), output.Result.GetLoser())
-			//TODO 定时删除 结束的战斗
-		case *pb.BattleOutput_Replay:
-			fmt.Printf("[BattleManager] 战斗输出 - 回放: BattleID=%d, Events=%d\n", e.GetBattleId(),
-				len(output.Replay.GetEvents()))
-		}
-		bm.outPutChan <- e // 透传
-	default:
-		fmt.Printf("[BattleManager] 未知的 BattleContext 类型 - BattleID: %d, Tick: %d\n", e.GetBattleId(), e.GetTick())
-		return fmt.Errorf("未知的 BattleContext 类型")
-
 	}
+	return errors.Join(errs...)
+}
+
+// collectOutput 把引擎的战斗输出交给战斗所在的分片，在该分片的 OnTick 返回后处理
+// 回调在 OnTick 期间调用 (C# 持有分片锁)，不能等待任何事件循环
+func (bm *BattleManager) collectOutput(ctx *pb.BattleContext) error {
+	bm.shardFor(uint64(ctx.GetBattleId())).collectOutput(ctx)
 	return nil
 }
+
+// drainOutputs 读取输出环形缓冲区中的战斗输出，交给战斗所在的分片
+func (bm *BattleManager) drainOutputs() {
+	if bm.transport == nil {
+		return
+	}
+	bm.transport.Output.Drain(func(frame []byte) {
+		outPutCtx := &pb.BattleContext{}
+		if err := proto.Unmarshal(frame, outPutCtx); err != nil {
+			fmt.Printf("[BattleManager] ✗ 反序列化战斗输出失败: %v\n", err)
+			return
+		}
+		bm.collectOutput(outPutCtx)
+	})
+}
@@ -0,0 +1,273 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"goPureWithCsharp/csharp"
+	pb "goPureWithCsharp/csharp/proto"
+)
+
+// ============================================================================
+// BattleShard - BattleManager 的一个分片
+// ============================================================================
+//
+// 战斗 ID 按 shardOf 哈希到 N 个分片，每个分片有自己的事件循环、定时器和 TickDriver，
+// 在 C# 侧对应同 ID 的 BattleShard: 战斗用 CreateShardBattle 创建，用 OnTickShard 驱动。
+// 分片之间的 OnTick、输入提交和输出处理并行执行，一个分片中的慢战斗只阻塞该分片。
+// BattleManager.run 只负责把创建命令和事件总线上的战斗输入路由到所在分片。
+// 引擎在 OnTick 期间 (C# 持有分片锁) 经回调产生的输出先暂存在分片中，OnTick 返回后由分片直接处理，
+// 再发布到事件总线供订阅者使用，输出不会等待分片自己的事件循环。
+//
+// 环形缓冲区是单生产者单消费者的，且只由 OnTick 处理，因此只在只有一个分片时绑定，
+// 此时分片 0 用 OnTick 驱动。
+
+// defaultShards 默认分片数
+const defaultShards = 1
+
+// shardOf 把战斗 ID 哈希到 [0, shards) 中的一个分片
+// 连续分配的战斗 ID 也能均匀分布；调度器和 BattleManager 必须使用相同的分片数
+func shardOf(battleID uint64, shards int) uint32 {
+	if shards <= 1 {
+		return 0
+	}
+	h := battleID * 0x9E3779B97F4A7C15 // Fibonacci 哈希
+	return uint32((h >> 32) % uint64(shards))
+}
+
+// BattleShard 分片事件循环，只在自己的 goroutine 中访问 tickDriver 和 journal
+type BattleShard struct {
+	id         uint32
+	bm         *BattleManager
+	tickDriver *TickDriver
+	createChan chan *CreateBattleCommand // 路由到本分片的创建命令
+	inputChan  chan *InputCommand        // BattleHandle 提交的输入
+	ctxChan    chan *pb.BattleContext    // 路由到本分片的输入
+	journal    *BattleJournal            // 本分片战斗的日志，在 Init 中创建
+	sweeper    *battleSweeper            // 回收本分片结束的战斗
+
+	outputMu sync.Mutex
+	outputs  []*pb.BattleContext // OnTick 期间收到的输出，回调可能在其他线程调用
+}
+
+func newBattleShard(id uint32, bm *BattleManager, tickDriver *TickDriver, bufferSize int) *BattleShard {
+	return &BattleShard{
+		id:         id,
+		bm:         bm,
+		tickDriver: tickDriver,
+		createChan: make(chan *CreateBattleCommand, bufferSize),
+		inputChan:  make(chan *InputCommand, bufferSize),
+		ctxChan:    make(chan *pb.BattleContext, bufferSize),
+		sweeper:    newBattleSweeper(bm.retention),
+	}
+}
+
+// ID 分片 ID，即 C# 侧的分片 ID
+func (s *BattleShard) ID() uint32 {
+	return s.id
+}
+
+// run 分片事件循环，BattleManager 停止时退出
+func (s *BattleShard) run() {
+	fmt.Printf("[BattleShard-%d] 启动事件循环\n", s.id)
+	defer func() {
+		if r := recover(); r != nil {
+			fmt.Printf("[BattleShard-%d] 事件循环崩溃: %v\n", s.id, r)
+		}
+		fmt.Printf("[BattleShard-%d] 事件循环已退出\n", s.id)
+	}()
+
+	ticker := time.NewTicker(s.bm.frameInterval)
+	defer ticker.Stop()
+	var sweep <-chan time.Time // 为空时不自动清理
+	if interval := s.bm.retention.Interval; interval > 0 {
+		sweepTicker := time.NewTicker(interval)
+		defer sweepTicker.Stop()
+		sweep = sweepTicker.C
+	}
+	for {
+		select {
+		case cmd := <-s.createChan:
+			err := s.handleCreateBattle(cmd.Env)
+			if cmd.ResChan != nil {
+				cmd.ResChan <- err
+			}
+		case cmd := <-s.inputChan:
+			if cmd.Destroy {
+				cmd.ResChan <- s.destroyBattle(cmd.Ctx.GetBattleId())
+			} else {
+				cmd.ResChan <- s.handleInput(cmd.Ctx)
+			}
+		case ctx := <-s.ctxChan:
+			s.handleProcessBattleCtx(ctx)
+		case <-ticker.C:
+			s.advanceFrames()
+		case now := <-sweep:
+			s.sweep(now)
+		case <-s.bm.stopChan:
+			return
+		}
+	}
+}
+
+// handleCreateBattle 在本分片中创建战斗，env.BattleId 已由 BattleManager 分配
+func (s *BattleShard) handleCreateBattle(e *pb.BattleEnv) error {
+	bId := uint64(e.BattleId)
+	fmt.Printf("[BattleShard-%d] 创建战斗命令 - ID: %d\n", s.id, bId)
+	err := s.bm.battleCtrls.CreateBattle(bId, e)
+	if err != nil {
+		fmt.Printf("[BattleShard-%d] 创建战斗失败: %v\n", s.id, err)
+		return err
+	}
+	s.journal.Begin(uint32(bId), e)
+	s.sweeper.begin(uint32(bId), time.Now())
+	return nil
+}
+
+// advanceFrames 执行自上次唤醒以来到达的每个逻辑帧
+// 失败的帧不前进，下次唤醒时重试；引擎长时间不可用时由 tickDriver 跳过过旧的帧
+func (s *BattleShard) advanceFrames() {
+	_, err := s.tickDriver.Advance(s.processTick)
+	if err != nil && !errors.Is(err, csharp.ErrUnhealthy) {
+		// 不健康的引擎每个 Tick 都会快速失败，等待 Reload
+		fmt.Printf("[BattleShard-%d] OnTick 失败: %v\n", s.id, err)
+	}
+}
+
+// processTick 处理本分片的一个逻辑帧
+// OnTickShard 之前批量提交本分片收集的输入；绑定环形缓冲区时用 OnTick 驱动并在返回后读取输出
+// 每次 C# 调用受 callTimeout 约束，C# 侧卡住时不会阻塞事件循环
+func (s *BattleShard) processTick(frame uint64) error {
+	bm := s.bm
+	s.journal.Tick(frame)
+	s.flushInputs(frame)
+
+	ctx, cancel := context.WithTimeout(context.Background(), bm.callTimeout)
+	defer cancel()
+
+	engine := csharp.Supervise(bm.currentEngine())
+	var processed int32
+	var err error
+	if bm.transport != nil {
+		processed, err = engine.OnTick(ctx, frame)
+		bm.drainOutputs()
+	} else {
+		processed, err = engine.OnTickShard(ctx, s.id, frame)
+	}
+	// 超时的调用之后才产生的输出在下一帧处理
+	s.flushOutputs()
+	if err != nil {
+		return fmt.Errorf("分片 %d 第 %d 帧: %w", s.id, frame, err)
+	}
+
+	if processed > 0 {
+		fmt.Printf("[BattleShard-%d] 第 %d 帧处理了 %d 场战斗\n", s.id, frame, processed)
+	}
+	return nil
+}
+
+// flushInputs 调度器收集了本分片的输入时一次提交
+func (s *BattleShard) flushInputs(frame uint64) {
+	flusher, ok := s.bm.battleCtrls.(InputFlusher)
+	if !ok {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), s.bm.callTimeout)
+	defer cancel()
+
+	if _, err := flusher.FlushInputs(ctx, s.id, frame); err != nil && !errors.Is(err, csharp.ErrUnhealthy) {
+		fmt.Printf("[BattleShard-%d] %v\n", s.id, err)
+	}
+}
+
+// collectOutput 暂存引擎产生的输出，可在任意线程调用
+func (s *BattleShard) collectOutput(e *pb.BattleContext) {
+	s.outputMu.Lock()
+	s.outputs = append(s.outputs, e)
+	s.outputMu.Unlock()
+}
+
+// flushOutputs 处理暂存的输出，再发布到事件总线供订阅者使用
+func (s *BattleShard) flushOutputs() {
+	s.outputMu.Lock()
+	outputs := s.outputs
+	s.outputs = nil
+	s.outputMu.Unlock()
+
+	for _, e := range outputs {
+		s.handleOutput(e)
+		if err := s.bm.Publish(e); err != nil {
+			fmt.Printf("[BattleShard-%d] 发布战斗输出失败: BattleID=%d, %v\n", s.id, e.GetBattleId(), err)
+		}
+	}
+}
+
+// handleInput 提交一条输入，成功后记入日志；被 BattleDestroy 销毁的战斗不再输出结果，从此开始计算保留时间
+func (s *BattleShard) handleInput(e *pb.BattleContext) error {
+	err := s.bm.battleCtrls.InputBattle(uint64(e.GetBattleId()), e)
+	if err != nil {
+		fmt.Printf("[BattleShard-%d] 输入战斗失败: %v\n", s.id, err)
+		return err
+	}
+	s.journal.Input(e)
+	if e.GetBattleInput().GetDestroy() != nil {
+		s.sweeper.finish(e.GetBattleId(), time.Now())
+		s.bm.battleDestroyed(uint64(e.GetBattleId()))
+	}
+	return nil
+}
+
+// destroyBattle 从 Proxy 和 C# 中销毁战斗，不再清理它；销毁失败时该战斗的句柄同样完成，不会一直等待
+func (s *BattleShard) destroyBattle(battleID uint32) error {
+	s.sweeper.forget(battleID)
+	defer s.bm.battleDestroyed(uint64(battleID))
+	if err := s.bm.battleCtrls.DestroyBattle(uint64(battleID)); err != nil {
+		fmt.Printf("[BattleShard-%d] 销毁战斗失败: %v\n", s.id, err)
+		return err
+	}
+	return nil
+}
+
+// handleProcessBattleCtx 处理路由到本分片的输入
+func (s *BattleShard) handleProcessBattleCtx(e *pb.BattleContext) error {
+	switch e.Option.(type) {
+	case *pb.BattleContext_BattleInput:
+		return s.handleInput(e)
+	default:
+		fmt.Printf("[BattleShard-%d] 未知的 BattleContext 类型 - BattleID: %d, Tick: %d\n", s.id, e.GetBattleId(), e.GetTick())
+		return fmt.Errorf("未知的 BattleContext 类型")
+	}
+}
+
+// handleOutput 记录战斗输出，交给 BattleOutput 并透传到 outPutChan
+func (s *BattleShard) handleOutput(e *pb.BattleContext) {
+	bm := s.bm
+	s.journal.Output(e)
+
+	switch output := e.GetBattleOutput().GetOutput().(type) {
+	case *pb.BattleOutput_Result:
+		fmt.Printf("[BattleShard-%d] 战斗输出 - 结果: BattleID=%d, Winner=%d, Loser=%d\n", s.id, e.GetBattleId(),
+			output.Result.GetWinner(), output.Result.GetLoser())
+		if bm.output != nil {
+			if err := bm.output.OutPutResult(output.Result); err != nil {
+				fmt.Printf("[BattleShard-%d] 输出战斗结果失败: BattleID=%d, %v\n", s.id, e.GetBattleId(), err)
+			}
+		}
+		s.sweeper.finish(e.GetBattleId(), time.Now())
+	case *pb.BattleOutput_Replay:
+		fmt.Printf("[BattleShard-%d] 战斗输出 - 回放: BattleID=%d, Events=%d\n", s.id, e.GetBattleId(),
+			len(output.Replay.GetEvents()))
+		if bm.output != nil {
+			if err := bm.output.OutPutReply(output.Replay); err != nil {
+				fmt.Printf("[BattleShard-%d] 输出战斗回放失败: BattleID=%d, %v\n", s.id, e.GetBattleId(), err)
+			}
+		}
+	}
+	if bm.outPutChan != nil {
+		route(bm.outPutChan, e, bm.stopChan) // 透传
+	}
+}
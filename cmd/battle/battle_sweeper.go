@@ -0,0 +1,162 @@
+package main
+
+import (
+	"fmt"
+	"slices"
+	"sync/atomic"
+	"time"
+
+	pb "goPureWithCsharp/csharp/proto"
+)
+
+// ============================================================================
+// battleSweeper - 回收结束的战斗
+// ============================================================================
+//
+// 战斗输出结果后仍留在 Proxy 和 C# 的战斗表中，OnTick 每帧都会遍历它们。
+// 每个分片在自己的事件循环中按 RetentionPolicy.Interval 清理一次:
+// 输出结果 (或被 BattleDestroy 输入销毁) 超过 Retention 的战斗用 DestroyBattle 从两侧移除，
+// 创建后超过 MaxDuration 仍未结束的战斗用 BattleEnd{timeout: true} 强制结束，
+// 结果的 timeout 为 true，之后和正常结束的战斗一样在保留期满后销毁。
+// 超时结束的输入与其他输入一样记入战斗日志，复算时得到相同的结果。
+
+const (
+	defaultBattleRetention   = time.Minute
+	defaultMaxBattleDuration = 30 * time.Minute
+	defaultSweepInterval     = time.Second
+)
+
+// RetentionPolicy 结束的战斗的保留策略
+type RetentionPolicy struct {
+	Retention   time.Duration // 输出结果后保留多久再销毁，为 0 时下次清理即销毁
+	MaxDuration time.Duration // 战斗最长时长，超过时以超时结果强制结束，为 0 时不限制
+	Interval    time.Duration // 清理间隔，为 0 时不自动清理
+}
+
+// DefaultRetentionPolicy 默认保留 1 分钟，战斗最长 30 分钟，每秒清理一次
+func DefaultRetentionPolicy() RetentionPolicy {
+	return RetentionPolicy{
+		Retention:   defaultBattleRetention,
+		MaxDuration: defaultMaxBattleDuration,
+		Interval:    defaultSweepInterval,
+	}
+}
+
+// SweepStats 清理计数
+type SweepStats struct {
+	Destroyed uint64 // 保留期满后销毁的战斗
+	TimedOut  uint64 // 超过最长时长被强制结束的战斗
+}
+
+// battleSweeper 分片中战斗的开始和结束时间，只在分片的 goroutine 中访问 running 和 finished
+type battleSweeper struct {
+	policy   RetentionPolicy
+	running  map[uint32]time.Time // 尚未输出结果的战斗 -> 创建时间
+	finished map[uint32]time.Time // 已输出结果的战斗 -> 输出结果的时间
+
+	destroyed atomic.Uint64
+	timedOut  atomic.Uint64
+}
+
+func newBattleSweeper(policy RetentionPolicy) *battleSweeper {
+	return &battleSweeper{
+		policy:   policy,
+		running:  make(map[uint32]time.Time),
+		finished: make(map[uint32]time.Time),
+	}
+}
+
+// begin 战斗创建成功后调用
+func (w *battleSweeper) begin(battleID uint32, now time.Time) {
+	w.running[battleID] = now
+}
+
+// finish 战斗输出结果或被销毁后调用，从此开始计算保留时间
+func (w *battleSweeper) finish(battleID uint32, now time.Time) {
+	delete(w.running, battleID)
+	if _, ok := w.finished[battleID]; !ok {
+		w.finished[battleID] = now
+	}
+}
+
+// forget 战斗被其他途径销毁后调用，不再清理
+func (w *battleSweeper) forget(battleID uint32) {
+	delete(w.running, battleID)
+	delete(w.finished, battleID)
+}
+
+// expired 返回超过最长时长的战斗和保留期满的战斗，按 ID 排序
+func (w *battleSweeper) expired(now time.Time) (overdue, retired []uint32) {
+	if w.policy.MaxDuration > 0 {
+		for battleID, started := range w.running {
+			if now.Sub(started) >= w.policy.MaxDuration {
+				overdue = append(overdue, battleID)
+			}
+		}
+	}
+	for battleID, finished := range w.finished {
+		if now.Sub(finished) >= w.policy.Retention {
+			retired = append(retired, battleID)
+		}
+	}
+	slices.Sort(overdue)
+	slices.Sort(retired)
+	return overdue, retired
+}
+
+// stats 累计清理计数
+func (w *battleSweeper) stats() SweepStats {
+	return SweepStats{
+		Destroyed: w.destroyed.Load(),
+		TimedOut:  w.timedOut.Load(),
+	}
+}
+
+// sweep 强制结束超时的战斗，销毁保留期满的战斗，返回本次清理的计数
+// 已被其他途径销毁的战斗 (BattleHandle.Cancel 等) 只移除记录，不计数
+func (s *BattleShard) sweep(now time.Time) SweepStats {
+	var swept SweepStats
+	overdue, retired := s.sweeper.expired(now)
+
+	for _, battleID := range overdue {
+		// 结果输出后由 finish 记录，强制结束失败的战斗不再重试
+		delete(s.sweeper.running, battleID)
+		ctx, err := buildInputContext(battleID, s.bm.fpsProvider.GetCurrentFrame(), &pb.BattleEnd{Timeout: true})
+		if err != nil {
+			continue
+		}
+		if err := s.handleInput(ctx); err != nil {
+			continue
+		}
+		swept.TimedOut++
+	}
+
+	for _, battleID := range retired {
+		delete(s.sweeper.finished, battleID)
+		// 已被其他途径销毁的战斗也关闭日志，不依赖调度器的销毁通知
+		s.journal.Destroyed(battleID)
+		if err := s.destroyBattle(battleID); err != nil {
+			continue
+		}
+		swept.Destroyed++
+	}
+
+	s.sweeper.destroyed.Add(swept.Destroyed)
+	s.sweeper.timedOut.Add(swept.TimedOut)
+	if swept.Destroyed > 0 || swept.TimedOut > 0 {
+		fmt.Printf("[BattleShard-%d] 清理战斗: 销毁 %d 场, 超时结束 %d 场, 剩余 %d 场进行中\n",
+			s.id, swept.Destroyed, swept.TimedOut, len(s.sweeper.running))
+	}
+	return swept
+}
+
+// SweepStats 返回所有分片累计的清理计数
+func (bm *BattleManager) SweepStats() SweepStats {
+	var total SweepStats
+	for _, shard := range bm.shards {
+		stats := shard.sweeper.stats()
+		total.Destroyed += stats.Destroyed
+		total.TimedOut += stats.TimedOut
+	}
+	return total
+}
@@ -1,12 +1,33 @@
 package main
 
 import (
+	"context"
+	"errors"
 	"fmt"
+	"goPureWithCsharp/csharp"
 	pb "goPureWithCsharp/csharp/proto"
+	"os"
+	"path/filepath"
 	"sync"
 	"testing"
+	"time"
+
+	"google.golang.org/protobuf/proto"
 )
 
+// testEngine BATTLE_ENGINE=sim 时返回模拟引擎，否则返回 nil 使用 C# 库，找不到 C# 库时测试失败
+func testEngine(t *testing.T) csharp.Engine {
+	t.Helper()
+	if os.Getenv("BATTLE_ENGINE") == "sim" {
+		return csharp.NewSimEngine()
+	}
+	libPath := filepath.Join("..", "..", "lib", "TestExport_Release.so")
+	if _, err := os.Stat(libPath); err != nil {
+		t.Fatalf("找不到 C# 库 %s (设置 BATTLE_ENGINE=sim 使用模拟引擎): %v", libPath, err)
+	}
+	return nil
+}
+
 func Test_Battle(t *testing.T) {
 
 	outChan := make(chan *pb.BattleContext, OUTPUT_BUFFER_SIZE)
@@ -14,6 +35,7 @@ func Test_Battle(t *testing.T) {
 	bm := NewBattleManagerBuilder().
 		WithBattleOutputChan(outChan).
 		WithFPS(120).
+		WithEngine(testEngine(t)).
 		BuildAsSingleton()
 
 	fmt.Printf("[Main] BattleManager 单例已初始化: %p\n", bm)
@@ -55,7 +77,10 @@ func Test_Battle(t *testing.T) {
 		for outChanput := range outChan {
 
 			fmt.Printf("[BattleOutput] 战斗输出: %+v\n", outChanput)
-			waitgroup.Done()
+			// 每场战斗依次输出结果和回放，以结果计数
+			if outChanput.GetBattleOutput().GetResult() != nil {
+				waitgroup.Done()
+			}
 			// endCh <- struct{}{}
 		}
 	}()
@@ -67,3 +92,1113 @@ func Test_Battle(t *testing.T) {
 
 	waitgroup.Wait()
 }
+
+// Test_InputBatch 测试未绑定环形缓冲区时输入先收集，FlushInputs 一次提交
+func Test_InputBatch(t *testing.T) {
+	engine := csharp.NewSimEngine()
+	engine.DisableBattleLogging()
+	defer engine.Close()
+
+	proxy := NewProxy(NewFrameSeqGenerator(30))
+	proxy.BindEngine(engine)
+	if err := proxy.CreateBattle(1, &pb.BattleEnv{Atk: &pb.Team{TeamId: 100}, Def: &pb.Team{TeamId: 101}}); err != nil {
+		t.Fatalf("创建战斗失败: %v", err)
+	}
+
+	for i := 0; i < 3; i++ {
+		if err := proxy.InputBattle(1, &pb.BattleUserOp{Operation: "attack"}); err != nil {
+			t.Fatalf("输入失败: %v", err)
+		}
+	}
+	if proxy.batches[0].Len() != 3 {
+		t.Fatalf("期望收集 3 条输入, 实际 %d", proxy.batches[0].Len())
+	}
+
+	// 战斗在提交前被销毁，整批输入都被拒绝
+	if err := engine.DestroyBattle(1); err != nil {
+		t.Fatalf("销毁战斗失败: %v", err)
+	}
+	rejected, err := proxy.FlushInputs(context.Background(), 0, 1)
+	if err != nil {
+		t.Fatalf("批量提交失败: %v", err)
+	}
+	if rejected != 3 || proxy.batches[0].Len() != 0 {
+		t.Errorf("期望 3 条被拒绝且批次清空, 实际拒绝 %d, 剩余 %d", rejected, proxy.batches[0].Len())
+	}
+}
+
+// manualTime 手动推进的时间提供者
+type manualTime struct {
+	now time.Time
+}
+
+func (m *manualTime) Now() time.Time                  { return m.now }
+func (m *manualTime) Since(t time.Time) time.Duration { return m.now.Sub(t) }
+func (m *manualTime) Sleep(d time.Duration)           { m.now = m.now.Add(d) }
+
+// Test_TickDriver 测试每个逻辑帧恰好执行一次，漏帧补执行，落后过多时跳过，失败的帧重试
+func Test_TickDriver(t *testing.T) {
+	clock := &manualTime{now: time.Unix(0, 0)}
+	frames := NewFrameSeqGeneratorWithTimeProvider(10, clock)
+	driver := NewTickDriver(frames, 5)
+
+	var ticked []uint64
+	tick := func(frame uint64) error {
+		ticked = append(ticked, frame)
+		return nil
+	}
+	advance := func(d time.Duration) int {
+		clock.Sleep(d)
+		n, err := driver.Advance(tick)
+		if err != nil {
+			t.Fatalf("Advance 失败: %v", err)
+		}
+		return n
+	}
+
+	// 首次从当前帧开始，同一帧内重复唤醒不再执行
+	if n := advance(0); n != 1 || ticked[0] != 0 {
+		t.Fatalf("首次应执行第 0 帧, 实际 %d 帧 %v", n, ticked)
+	}
+	if n := advance(50 * time.Millisecond); n != 0 {
+		t.Fatalf("同一帧内不应重复执行, 实际 %d 帧", n)
+	}
+
+	// 漏掉 3 帧，一次补上
+	if n := advance(250 * time.Millisecond); n != 3 {
+		t.Fatalf("期望补执行 3 帧, 实际 %d", n)
+	}
+
+	// 落后 20 帧，只执行最近的 5 帧
+	if n := advance(2 * time.Second); n != 5 || driver.Skipped() != 15 {
+		t.Fatalf("期望执行 5 帧跳过 15 帧, 实际执行 %d 跳过 %d", n, driver.Skipped())
+	}
+	want := []uint64{0, 1, 2, 3, 19, 20, 21, 22, 23}
+	if fmt.Sprint(ticked) != fmt.Sprint(want) {
+		t.Fatalf("帧序列错误: 期望 %v, 实际 %v", want, ticked)
+	}
+
+	// 失败的帧不前进，下次唤醒重试
+	clock.Sleep(200 * time.Millisecond)
+	failed := errors.New("tick failed")
+	if n, err := driver.Advance(func(frame uint64) error { return failed }); n != 0 || !errors.Is(err, failed) {
+		t.Fatalf("期望失败且不执行任何帧, 实际 %d, %v", n, err)
+	}
+	if driver.NextFrame() != 24 {
+		t.Fatalf("失败后下一帧应仍为 24, 实际 %d", driver.NextFrame())
+	}
+	if n := advance(0); n != 2 {
+		t.Errorf("重试时应执行第 24、25 帧, 实际 %d 帧", n)
+	}
+}
+
+// recordingOutput 记录收到的战斗结果和回放
+type recordingOutput struct {
+	mu      sync.Mutex
+	results []*pb.BattleResult
+	replays []*pb.BattleReplay
+}
+
+func (o *recordingOutput) OutPutResult(result *pb.BattleResult) error {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	o.results = append(o.results, result)
+	return nil
+}
+
+func (o *recordingOutput) OutPutReply(replay *pb.BattleReplay) error {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	o.replays = append(o.replays, replay)
+	return nil
+}
+
+// Test_BattleJournal 测试战斗日志记录环境、输入和输出，重新执行得到相同的输出
+// 同时测试结果和回放送到 BattleOutput
+func Test_BattleJournal(t *testing.T) {
+	engine := csharp.NewSimEngine()
+	engine.DisableBattleLogging()
+
+	dir := t.TempDir()
+	outChan := make(chan *pb.BattleContext, 16)
+	output := &recordingOutput{}
+	bm := NewBattleManagerBuilder().
+		WithEngine(engine).
+		WithFPS(200).
+		WithJournalDir(dir).
+		WithBattleOutputChan(outChan).
+		WithBattleOutput(output).
+		Build()
+	if err := bm.Start(); err != nil {
+		t.Fatalf("启动失败: %v", err)
+	}
+
+	const battleID = 4242
+	bm.GetCreateChannel() <- &pb.BattleEnv{
+		BattleId: battleID,
+		Atk:      &pb.Team{TeamId: 100},
+		Def:      &pb.Team{TeamId: 101},
+	}
+	// 创建命令和输入走不同的通道，等战斗创建后再输入
+	for {
+		if _, ok := bm.battleCtrls.(*Proxy).GetBattleController(battleID); ok {
+			break
+		}
+		time.Sleep(time.Millisecond)
+	}
+	for _, in := range []*pb.BattleInput{
+		{Input: &pb.BattleInput_Pause{Pause: &pb.BattlePause{}}},
+		{Input: &pb.BattleInput_Resume{Resume: &pb.BattleResume{}}},
+		{Input: &pb.BattleInput_UserOp{UserOp: &pb.BattleUserOp{Operation: "attack"}}},
+	} {
+		bm.Publish(&pb.BattleContext{
+			BattleId: battleID,
+			Option:   &pb.BattleContext_BattleInput{BattleInput: in},
+		})
+	}
+
+	for _, want := range []string{"结果", "回放"} {
+		select {
+		case out := <-outChan:
+			got := out.GetBattleOutput()
+			if (want == "结果" && got.GetResult() == nil) || (want == "回放" && got.GetReplay() == nil) {
+				t.Fatalf("期望战斗%s, 实际 %v", want, out)
+			}
+		case <-time.After(5 * time.Second):
+			t.Fatalf("等待战斗%s超时", want)
+		}
+	}
+	bm.Stop()
+
+	output.mu.Lock()
+	if len(output.results) != 1 || len(output.replays) != 1 ||
+		!proto.Equal(output.replays[0].GetResult(), output.results[0]) || len(output.replays[0].GetEvents()) == 0 {
+		t.Errorf("BattleOutput 应收到 1 个结果和包含事件的 1 个回放, 实际 %v / %v", output.results, output.replays)
+	}
+	output.mu.Unlock()
+
+	journal, err := csharp.LoadJournal(filepath.Join(dir, fmt.Sprintf("battle_%d.journal", battleID)))
+	if err != nil {
+		t.Fatalf("读取战斗日志失败: %v", err)
+	}
+	if journal.Env.GetSeed() == 0 || len(journal.Inputs) != 3 || len(journal.Outputs) != 2 {
+		t.Fatalf("日志内容错误: 种子 %d, %d 条输入, %d 条输出", journal.Env.GetSeed(), len(journal.Inputs), len(journal.Outputs))
+	}
+
+	replay := csharp.NewSimEngine()
+	replay.DisableBattleLogging()
+	defer replay.Close()
+	if err := csharp.VerifyBattleResult(replay, &journal.BattleRecord, journal.Outputs[0].GetBattleOutput().GetResult()); err != nil {
+		t.Errorf("重新执行结果不一致: %v", err)
+	}
+}
+
+// Test_BattleJournalDestroyed 测试没有输出回放就被销毁的战斗关闭日志
+func Test_BattleJournalDestroyed(t *testing.T) {
+	engine := csharp.NewSimEngine()
+	engine.DisableBattleLogging()
+
+	bm := NewBattleManagerBuilder().
+		WithEngine(engine).
+		WithFPS(200).
+		WithJournalDir(t.TempDir()).
+		Build()
+	if err := bm.Start(); err != nil {
+		t.Fatalf("启动失败: %v", err)
+	}
+	defer bm.Stop()
+
+	h, err := bm.Submit(context.Background(), &pb.BattleEnv{
+		Atk: &pb.Team{TeamId: 100},
+		Def: &pb.Team{TeamId: 101},
+	})
+	if err != nil {
+		t.Fatalf("提交战斗失败: %v", err)
+	}
+	journal := bm.shardFor(h.ID()).journal
+	openWriters := func() int {
+		journal.mu.Lock()
+		defer journal.mu.Unlock()
+		return len(journal.writers)
+	}
+	if openWriters() != 1 {
+		t.Fatalf("创建战斗后应打开日志")
+	}
+	if err := h.Cancel(); err != nil {
+		t.Fatalf("取消失败: %v", err)
+	}
+	if n := openWriters(); n != 0 {
+		t.Errorf("取消的战斗应关闭日志, 仍有 %d 个打开", n)
+	}
+}
+
+// Test_BattleStatus 测试通过 BattleManager 查询存活战斗的状态
+func Test_BattleStatus(t *testing.T) {
+	engine := csharp.NewSimEngine()
+	engine.DisableBattleLogging()
+
+	bm := NewBattleManagerBuilder().
+		WithEngine(engine).
+		WithBattleOutputChan(make(chan *pb.BattleContext, 16)).
+		Build()
+	if err := bm.Init(); err != nil {
+		t.Fatalf("初始化失败: %v", err)
+	}
+	defer bm.Dispose()
+
+	if err := bm.handleCreateBattle(&pb.BattleEnv{BattleId: 11, Atk: &pb.Team{TeamId: 100}, Def: &pb.Team{TeamId: 101}}); err != nil {
+		t.Fatalf("创建战斗失败: %v", err)
+	}
+	if err := bm.processTick(1); err != nil {
+		t.Fatalf("processTick 失败: %v", err)
+	}
+
+	status, err := bm.GetBattleStatus(11)
+	if err != nil {
+		t.Fatalf("查询战斗状态失败: %v", err)
+	}
+	if status.GetRound() != 1 || status.GetState() != csharp.BattleStateRunning || status.GetAtkTeamId() != 100 {
+		t.Errorf("战斗状态异常: %v", status)
+	}
+	if _, err := bm.GetBattleStatus(12); err == nil {
+		t.Errorf("不存在的战斗应返回错误")
+	}
+
+	list, err := bm.ListBattles()
+	if err != nil || len(list) != 1 || list[0].GetBattleId() != 11 {
+		t.Errorf("期望 1 场战斗, 实际 %v (%v)", list, err)
+	}
+}
+
+// Test_BattleLifecycle 测试暂停、恢复、强制结束和销毁的状态迁移，非法迁移通过 Proxy.InputBattle 返回错误码
+func Test_BattleLifecycle(t *testing.T) {
+	engine := csharp.NewSimEngine()
+	engine.DisableBattleLogging()
+
+	bm := NewBattleManagerBuilder().
+		WithEngine(engine).
+		WithBattleOutputChan(make(chan *pb.BattleContext, 16)).
+		Build()
+	if err := bm.Init(); err != nil {
+		t.Fatalf("初始化失败: %v", err)
+	}
+	defer bm.Dispose()
+
+	const battleID = 21
+	if err := bm.handleCreateBattle(&pb.BattleEnv{BattleId: battleID, Atk: &pb.Team{TeamId: 100}, Def: &pb.Team{TeamId: 101}}); err != nil {
+		t.Fatalf("创建战斗失败: %v", err)
+	}
+	proxy := bm.battleCtrls.(*Proxy)
+	expectState := func(state string, round int32) {
+		t.Helper()
+		status, err := bm.GetBattleStatus(battleID)
+		if err != nil {
+			t.Fatalf("查询战斗状态失败: %v", err)
+		}
+		if status.GetState() != state || status.GetRound() != round {
+			t.Fatalf("期望状态 %s 回合 %d, 实际 %v", state, round, status)
+		}
+	}
+	expectCode := func(input proto.Message, code pb.BattleErrorCode) {
+		t.Helper()
+		if got := csharp.ErrorCode(proxy.InputBattle(battleID, input)); got != code {
+			t.Fatalf("输入 %T: 期望 %s, 实际 %s", input, code, got)
+		}
+	}
+
+	bm.processTick(1)
+	expectState(csharp.BattleStateRunning, 1)
+
+	// 暂停的战斗不执行回合
+	expectCode(&pb.BattleResume{}, pb.BattleErrorCode_INVALID_BATTLE_STATE)
+	expectCode(&pb.BattlePause{}, pb.BattleErrorCode_SUCCESS)
+	expectCode(&pb.BattlePause{}, pb.BattleErrorCode_INVALID_BATTLE_STATE)
+	expectCode(&pb.BattleUserOp{Operation: "attack"}, pb.BattleErrorCode_SUCCESS) // 下一帧应用时被拒绝
+	bm.processTick(2)
+	expectState(csharp.BattleStatePaused, 1)
+
+	expectCode(&pb.BattleResume{}, pb.BattleErrorCode_SUCCESS)
+	bm.processTick(3)
+	expectState(csharp.BattleStateRunning, 2)
+
+	// 强制结束: 指定的胜方必须是参战队伍，结果在下一帧输出
+	expectCode(&pb.BattleEnd{Winner: 999}, pb.BattleErrorCode_INVALID_REQUEST)
+	expectCode(&pb.BattleEnd{Winner: 101}, pb.BattleErrorCode_SUCCESS)
+	expectCode(&pb.BattlePause{}, pb.BattleErrorCode_INVALID_BATTLE_STATE)
+	results := bm.Subscribe(EventFilter{BattleIDs: []uint32{battleID}, Topics: TopicResult}, 4, LaneOptions{})
+	defer results.Unsubscribe()
+	bm.processTick(4)
+	expectState(csharp.BattleStateFinished, 2)
+
+	var result *pb.BattleResult
+	select {
+	case out := <-results.C():
+		result = out.GetBattleOutput().GetResult()
+	case <-time.After(5 * time.Second):
+		t.Fatalf("等待战斗结果超时")
+	}
+	if result.GetWinner() != 101 || result.GetDuration() != 4 {
+		t.Errorf("强制结束的结果异常: %v", result)
+	}
+
+	expectCode(&pb.BattleDestroy{}, pb.BattleErrorCode_SUCCESS)
+	expectCode(&pb.BattleDestroy{}, pb.BattleErrorCode_INVALID_BATTLE_STATE)
+	expectState(csharp.BattleStateDestroyed, 2)
+	expectCode(&pb.BattleResume{}, pb.BattleErrorCode_INVALID_BATTLE_STATE)
+}
+
+// Test_InputValidation 测试道具和用户操作在入队前按效果表校验
+func Test_InputValidation(t *testing.T) {
+	engine := csharp.NewSimEngine()
+	engine.DisableBattleLogging()
+
+	bm := NewBattleManagerBuilder().
+		WithEngine(engine).
+		WithBattleOutputChan(make(chan *pb.BattleContext, 16)).
+		Build()
+	if err := bm.Init(); err != nil {
+		t.Fatalf("初始化失败: %v", err)
+	}
+	defer bm.Dispose()
+
+	const battleID = 31
+	if err := bm.handleCreateBattle(&pb.BattleEnv{BattleId: battleID, Atk: &pb.Team{TeamId: 100}, Def: &pb.Team{TeamId: 101}}); err != nil {
+		t.Fatalf("创建战斗失败: %v", err)
+	}
+	proxy := bm.battleCtrls.(*Proxy)
+
+	for _, input := range []proto.Message{
+		&pb.BattleUseItem{ItemIds: []uint32{9999}, UserId: 100, Quantity: 1},
+		&pb.BattleUseItem{ItemIds: []uint32{5001}, UserId: 100, Quantity: 4},
+		&pb.BattleUserOp{CharId: 100, Operation: "dance"},
+	} {
+		if code := csharp.ErrorCode(proxy.InputBattle(battleID, input)); code != pb.BattleErrorCode_INVALID_REQUEST {
+			t.Errorf("输入 %v: 期望 INVALID_REQUEST, 实际 %s", input, code)
+		}
+	}
+	if proxy.batches[0].Len() != 0 {
+		t.Fatalf("校验失败的输入不应入队, 实际 %d 条", proxy.batches[0].Len())
+	}
+
+	if err := proxy.InputBattle(battleID, &pb.BattleUseItem{ItemIds: []uint32{5002}, UserId: 100, Quantity: 1}); err != nil {
+		t.Fatalf("提交道具失败: %v", err)
+	}
+	bm.processTick(1)
+	status, err := bm.GetBattleStatus(battleID)
+	if err != nil {
+		t.Fatalf("查询战斗状态失败: %v", err)
+	}
+	if status.GetAtkMaxHealth() != 400 {
+		t.Errorf("道具未生效: %v", status)
+	}
+}
+
+// hangingBatchEngine ProcessBattleContextBatch 卡住直到 release 关闭，模拟卡住的 C# 调用
+type hangingBatchEngine struct {
+	*csharp.SimEngine
+	release chan struct{}
+}
+
+func (e *hangingBatchEngine) ProcessBattleContextBatch(contexts []*pb.BattleContext) ([]pb.BattleErrorCode, error) {
+	<-e.release
+	return e.SimEngine.ProcessBattleContextBatch(contexts)
+}
+
+// Test_LifecycleInputTimeout 测试立即应用的生命周期输入受调用超时约束，卡住时不会一直阻塞分片
+func Test_LifecycleInputTimeout(t *testing.T) {
+	engine := &hangingBatchEngine{SimEngine: csharp.NewSimEngine(), release: make(chan struct{})}
+	engine.DisableBattleLogging()
+	defer close(engine.release)
+
+	bc := NewBattleController(NewFrameSeqGenerator(60), nil, engine)
+	bc.batch = NewInputBatch()
+	bc.callTimeout = 50 * time.Millisecond
+
+	start := time.Now()
+	err := bc.BattleInput(1, &pb.BattlePause{})
+	if !errors.Is(err, csharp.ErrTimeout) {
+		t.Fatalf("卡住的调用期望 ErrTimeout, 实际 %v", err)
+	}
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Errorf("应在调用超时后返回, 耗时 %v", elapsed)
+	}
+	if !errors.Is(engine.Health(), csharp.ErrUnhealthy) {
+		t.Errorf("超时后引擎应不健康, 实际 %v", engine.Health())
+	}
+}
+
+// Test_ShardedBattleManager 测试战斗按 ID 哈希到分片，在 C# 侧的同一分片中创建，分片 Tick 只推进自己的战斗
+func Test_ShardedBattleManager(t *testing.T) {
+	engine := csharp.NewSimEngine()
+	engine.DisableBattleLogging()
+
+	const shards = 4
+	bm := NewBattleManagerBuilder().
+		WithEngine(engine).
+		WithShards(shards).
+		WithBattleOutputChan(make(chan *pb.BattleContext, 16)).
+		Build()
+	if err := bm.Init(); err != nil {
+		t.Fatalf("初始化失败: %v", err)
+	}
+	defer bm.Dispose()
+	if bm.transport != nil {
+		t.Fatalf("多个分片时不应绑定环形缓冲区")
+	}
+
+	perShard := make(map[uint32]int)
+	for id := uint64(100); id < 116; id++ {
+		if err := bm.handleCreateBattle(&pb.BattleEnv{BattleId: uint32(id), Atk: &pb.Team{TeamId: 100}, Def: &pb.Team{TeamId: 101}}); err != nil {
+			t.Fatalf("创建战斗 %d 失败: %v", id, err)
+		}
+		perShard[shardOf(id, shards)]++
+	}
+	if len(perShard) != shards {
+		t.Fatalf("16 场战斗应分布到全部 %d 个分片, 实际 %v", shards, perShard)
+	}
+
+	// 只推进分片 1，输入也只提交分片 1 的批次
+	proxy := bm.battleCtrls.(*Proxy)
+	for id := uint64(100); id < 116; id++ {
+		if err := proxy.InputBattle(id, &pb.BattleUserOp{Operation: "attack"}); err != nil {
+			t.Fatalf("输入失败: %v", err)
+		}
+	}
+	if err := bm.Shards()[1].processTick(1); err != nil {
+		t.Fatalf("分片 1 processTick 失败: %v", err)
+	}
+	if proxy.batches[1].Len() != 0 || proxy.batches[0].Len() != perShard[0] {
+		t.Errorf("只应提交分片 1 的输入, 剩余 %d / %d", proxy.batches[1].Len(), proxy.batches[0].Len())
+	}
+
+	list, err := bm.ListBattles()
+	if err != nil || len(list) != 16 {
+		t.Fatalf("期望 16 场战斗, 实际 %d (%v)", len(list), err)
+	}
+	for _, status := range list {
+		shard := shardOf(uint64(status.GetBattleId()), shards)
+		if status.GetShardId() != shard {
+			t.Errorf("战斗 %d 应在分片 %d, 实际 %d", status.GetBattleId(), shard, status.GetShardId())
+		}
+		want := int32(0)
+		if shard == 1 {
+			want = 1
+		}
+		if status.GetRound() != want {
+			t.Errorf("分片 %d 的战斗 %d 应执行 %d 回合, 实际 %d", shard, status.GetBattleId(), want, status.GetRound())
+		}
+	}
+
+	// processTick 推进所有分片
+	if err := bm.processTick(2); err != nil {
+		t.Fatalf("processTick 失败: %v", err)
+	}
+	if status, _ := bm.GetBattleStatus(100); status.GetRound() == 0 {
+		t.Errorf("processTick 后所有分片的战斗都应执行回合: %v", status)
+	}
+}
+
+// Test_ShardedBattleOutputs 测试多个分片 (不使用环形缓冲区) 时战斗输出经回调发布到本实例
+func Test_ShardedBattleOutputs(t *testing.T) {
+	engine := csharp.NewSimEngine()
+	engine.DisableBattleLogging()
+
+	const shards = 2
+	outChan := make(chan *pb.BattleContext, 16)
+	bm := NewBattleManagerBuilder().
+		WithEngine(engine).
+		WithFPS(200).
+		WithShards(shards).
+		WithBattleOutputChan(outChan).
+		Build()
+	if err := bm.Start(); err != nil {
+		t.Fatalf("启动失败: %v", err)
+	}
+	defer bm.Stop()
+	if bm.transport != nil {
+		t.Fatalf("多个分片时不应绑定环形缓冲区")
+	}
+
+	waitCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	perShard := make(map[uint32]int)
+	var handles []*BattleHandle
+	for id := uint32(300); id < 304; id++ {
+		h, err := bm.Submit(context.Background(), &pb.BattleEnv{BattleId: id, Atk: &pb.Team{TeamId: 100}, Def: &pb.Team{TeamId: 101}})
+		if err != nil {
+			t.Fatalf("提交战斗 %d 失败: %v", id, err)
+		}
+		if err := h.Input(&pb.BattleEnd{Winner: 101}); err != nil {
+			t.Fatalf("输入失败: %v", err)
+		}
+		perShard[shardOf(uint64(id), shards)]++
+		handles = append(handles, h)
+	}
+	if len(perShard) != shards {
+		t.Fatalf("战斗应分布到全部 %d 个分片, 实际 %v", shards, perShard)
+	}
+
+	for _, h := range handles {
+		result, replay, err := h.Wait(waitCtx)
+		if err != nil || result.GetWinner() != 101 || replay == nil {
+			t.Fatalf("战斗 %d 的输出异常: %v / %v, %v", h.ID(), result, replay, err)
+		}
+	}
+	// 结果和回放同样透传到 outPutChan
+	for range 2 * len(handles) {
+		select {
+		case <-outChan:
+		case <-waitCtx.Done():
+			t.Fatalf("等待透传的战斗输出超时")
+		}
+	}
+	if stats := bm.EventBus.(*EventBusImpl).Stats(); stats.Output.Published != uint64(2*len(handles)) {
+		t.Errorf("本实例的输出车道应发布 %d 条输出, 实际 %+v", 2*len(handles), stats.Output)
+	}
+}
+
+// Test_TickOutputBurst 测试一帧内的输出远多于各级缓冲区时，OnTick 期间的输出不等待分片自己的事件循环
+func Test_TickOutputBurst(t *testing.T) {
+	engine := csharp.NewSimEngine()
+	engine.DisableBattleLogging()
+
+	// 1 FPS: 所有战斗在同一帧结束，每场输出结果和回放
+	bm := NewBattleManagerBuilder().
+		WithEngine(engine).
+		WithFPS(1).
+		WithShards(2).
+		WithBufferSize(2).
+		Build()
+	if err := bm.Start(); err != nil {
+		t.Fatalf("启动失败: %v", err)
+	}
+	defer bm.Stop()
+
+	var handles []*BattleHandle
+	for range 20 {
+		h, err := bm.Submit(context.Background(), &pb.BattleEnv{Atk: &pb.Team{TeamId: 100}, Def: &pb.Team{TeamId: 101}})
+		if err != nil {
+			t.Fatalf("提交战斗失败: %v", err)
+		}
+		if err := h.Input(&pb.BattleEnd{Winner: 100}); err != nil {
+			t.Fatalf("输入失败: %v", err)
+		}
+		handles = append(handles, h)
+	}
+
+	waitCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	for _, h := range handles {
+		if result, _, err := h.Wait(waitCtx); err != nil || result.GetWinner() != 100 {
+			t.Fatalf("战斗 %d 的输出异常: %v, %v", h.ID(), result, err)
+		}
+	}
+	if stats := bm.EventBus.(*EventBusImpl).Stats(); stats.Output.TimedOut != 0 {
+		t.Errorf("输出不应等待超时: %+v", stats.Output)
+	}
+}
+
+// testOutput 测试用的战斗输出事件
+func testOutput(id uint32) *pb.BattleContext {
+	return &pb.BattleContext{BattleId: id, Option: &pb.BattleContext_BattleOutput{BattleOutput: &pb.BattleOutput{}}}
+}
+
+// Test_EventBusOverflow 测试车道各溢出策略的丢弃计数和返回给发布方的错误
+func Test_EventBusOverflow(t *testing.T) {
+	done := make(chan struct{})
+	defer close(done)
+	drain := func(ch <-chan *pb.BattleContext) []uint32 {
+		var ids []uint32
+		for len(ch) > 0 {
+			ids = append(ids, (<-ch).GetBattleId())
+		}
+		return ids
+	}
+	fill := func(l *eventLane) {
+		for id := uint32(1); id <= 2; id++ {
+			if err := l.publish(testOutput(id), done); err != nil {
+				t.Fatalf("发布失败: %v", err)
+			}
+		}
+	}
+
+	// 阻塞等待超时
+	var overflow *OverflowError
+	l := newEventLane(LaneOutput, LaneOptions{Policy: OverflowBlock, Timeout: 10 * time.Millisecond}, 2, "", done)
+	fill(l)
+	if err := l.publish(testOutput(3), done); !errors.As(err, &overflow) || overflow.Policy != OverflowBlock || !errors.Is(err, ErrEventBusFull) {
+		t.Errorf("车道满时应超时返回错误, 实际 %v", err)
+	}
+	if stats := l.stats(); stats.TimedOut != 1 || stats.Published != 2 {
+		t.Errorf("计数异常: %+v", stats)
+	}
+
+	// 丢弃新事件
+	l = newEventLane(LaneInput, LaneOptions{Policy: OverflowDropNewest}, 2, "", done)
+	fill(l)
+	if err := l.publish(testOutput(3), done); !errors.As(err, &overflow) || overflow.BattleID != 3 || overflow.Lane != LaneInput {
+		t.Errorf("车道满时应丢弃新事件, 实际 %v", err)
+	}
+	if got := drain(l.ch); fmt.Sprint(got) != "[1 2]" || l.stats().DroppedNewest != 1 {
+		t.Errorf("丢弃新事件后内容异常: %v, %+v", got, l.stats())
+	}
+
+	// 丢弃最早的事件，错误中记录被挤掉的战斗
+	l = newEventLane(LaneOutput, LaneOptions{Policy: OverflowDropOldest}, 2, "", done)
+	fill(l)
+	if err := l.publish(testOutput(3), done); !errors.As(err, &overflow) || overflow.BattleID != 1 {
+		t.Errorf("应挤掉战斗 1 的输出, 实际 %v", err)
+	}
+	if got := drain(l.ch); fmt.Sprint(got) != "[2 3]" || l.stats().DroppedOldest != 1 {
+		t.Errorf("丢弃最早的事件后内容异常: %v, %+v", got, l.stats())
+	}
+
+	// 订阅者阻塞时总线车道积压，最终由发布方的策略处理
+	eb := NewEventBusWithOptions(EventBusOptions{BufferSize: 1, Output: LaneOptions{Policy: OverflowDropNewest}})
+	sub := eb.Subscribe(EventFilter{}, 1, LaneOptions{Policy: OverflowBlock})
+	var err error
+	for id := uint32(1); id <= 10 && err == nil; id++ {
+		err = eb.Publish(testOutput(id))
+	}
+	if !errors.Is(err, ErrEventBusFull) || eb.Stats().Output.DroppedNewest != 1 {
+		t.Errorf("订阅者阻塞时发布方应收到错误, 实际 %v, %+v", err, eb.Stats().Output)
+	}
+	if (<-sub.C()).GetBattleId() != 1 {
+		t.Errorf("订阅者应按顺序收到事件")
+	}
+	eb.Close()
+	for range sub.C() { // 关闭总线后订阅通道关闭
+	}
+	if err := eb.Publish(testOutput(11)); !errors.Is(err, ErrEventBusClosed) {
+		t.Errorf("关闭后发布应返回 ErrEventBusClosed, 实际 %v", err)
+	}
+}
+
+// Test_EventBusSpill 测试车道满时事件写入磁盘，之后按发布顺序全部送回
+func Test_EventBusSpill(t *testing.T) {
+	dir := t.TempDir()
+	done := make(chan struct{})
+	l := newEventLane(LaneOutput, LaneOptions{Policy: OverflowSpill}, 2, dir, done)
+
+	const total = 10
+	for id := uint32(1); id <= total; id++ {
+		if err := l.publish(testOutput(id), done); err != nil {
+			t.Fatalf("溢出策略不应返回错误: %v", err)
+		}
+	}
+	if stats := l.stats(); stats.Published != total || stats.Spilled == 0 {
+		t.Fatalf("应有事件写入磁盘: %+v", stats)
+	}
+
+	var got []uint32
+	for len(got) < total {
+		select {
+		case ctx := <-l.ch:
+			got = append(got, ctx.GetBattleId())
+		case <-time.After(5 * time.Second):
+			t.Fatalf("等待溢出事件超时, 已收到 %v", got)
+		}
+	}
+	for i, id := range got {
+		if id != uint32(i+1) {
+			t.Fatalf("溢出事件顺序错误: %v", got)
+		}
+	}
+
+	close(done)
+	<-l.pumped
+	l.spill.close()
+	if files, _ := os.ReadDir(dir); len(files) != 0 {
+		t.Errorf("全部送回后溢出文件应删除, 实际 %d 个文件", len(files))
+	}
+}
+
+// Test_EventBusSubscriptions 测试按战斗和事件类型过滤的订阅、扇出投递和取消订阅
+func Test_EventBusSubscriptions(t *testing.T) {
+	eb := NewEventBus(16)
+	defer eb.Close()
+
+	all := eb.Subscribe(EventFilter{}, 16, LaneOptions{})
+	results := eb.Subscribe(EventFilter{Topics: TopicResult}, 16, LaneOptions{})
+	battle2 := eb.Subscribe(EventFilter{BattleIDs: []uint32{2}, Topics: TopicInput | TopicReplay}, 16, LaneOptions{})
+
+	result := func(id uint32) *pb.BattleContext {
+		return &pb.BattleContext{BattleId: id, Option: &pb.BattleContext_BattleOutput{BattleOutput: &pb.BattleOutput{
+			Output: &pb.BattleOutput_Result{Result: &pb.BattleResult{}}}}}
+	}
+	replay := func(id uint32) *pb.BattleContext {
+		return &pb.BattleContext{BattleId: id, Option: &pb.BattleContext_BattleOutput{BattleOutput: &pb.BattleOutput{
+			Output: &pb.BattleOutput_Replay{Replay: &pb.BattleReplay{}}}}}
+	}
+	input := func(id uint32) *pb.BattleContext {
+		return &pb.BattleContext{BattleId: id, Option: &pb.BattleContext_BattleInput{BattleInput: &pb.BattleInput{}}}
+	}
+
+	// 同一车道内按发布顺序投递
+	for _, event := range []*pb.BattleContext{result(1), replay(1), result(2), replay(2)} {
+		if err := eb.Publish(event); err != nil {
+			t.Fatalf("发布失败: %v", err)
+		}
+	}
+	receive := func(sub *Subscription, n int) []string {
+		t.Helper()
+		var got []string
+		for len(got) < n {
+			select {
+			case event := <-sub.C():
+				got = append(got, fmt.Sprintf("%d:%d", event.GetBattleId(), topicOf(event)))
+			case <-time.After(5 * time.Second):
+				t.Fatalf("等待事件超时, 已收到 %v", got)
+			}
+		}
+		return got
+	}
+	if got := receive(all, 4); fmt.Sprint(got) != "[1:2 1:4 2:2 2:4]" {
+		t.Errorf("无过滤的订阅者应收到全部事件: %v", got)
+	}
+	if got := receive(results, 2); fmt.Sprint(got) != "[1:2 2:2]" {
+		t.Errorf("结果订阅者只应收到结果: %v", got)
+	}
+	if got := receive(battle2, 1); fmt.Sprint(got) != "[2:4]" {
+		t.Errorf("战斗 2 的订阅者只应收到战斗 2 的回放: %v", got)
+	}
+
+	// 取消订阅后通道关闭，其他订阅者不受影响
+	results.Unsubscribe()
+	results.Unsubscribe()
+	if _, ok := <-results.C(); ok {
+		t.Errorf("取消订阅后通道应关闭")
+	}
+	eb.Publish(input(2))
+	if got := receive(battle2, 1); fmt.Sprint(got) != "[2:1]" {
+		t.Errorf("战斗 2 的订阅者应收到输入: %v", got)
+	}
+	if got := receive(all, 1); fmt.Sprint(got) != "[2:1]" {
+		t.Errorf("无过滤的订阅者应收到输入: %v", got)
+	}
+}
+
+// Test_EventBusSlowSubscriber 测试不读取的订阅者只会让投递等待有限时间，不影响订阅和其他订阅者
+func Test_EventBusSlowSubscriber(t *testing.T) {
+	eb := NewEventBus(16)
+	defer eb.Close()
+
+	slow := eb.Subscribe(EventFilter{}, 1, LaneOptions{Policy: OverflowBlock})
+	fast := eb.Subscribe(EventFilter{}, 16, LaneOptions{})
+	eb.Publish(testOutput(1))
+	eb.Publish(testOutput(2)) // slow 的缓冲区已满，投递在 slow 上等待
+
+	subscribed := make(chan *Subscription)
+	go func() { subscribed <- eb.Subscribe(EventFilter{}, 1, LaneOptions{}) }()
+	select {
+	case late := <-subscribed:
+		late.Unsubscribe()
+	case <-time.After(defaultPublishTimeout / 2):
+		t.Fatalf("投递等待订阅者时不应阻塞 Subscribe")
+	}
+
+	for id := uint32(1); id <= 2; id++ {
+		select {
+		case ctx := <-fast.C():
+			if ctx.GetBattleId() != id {
+				t.Fatalf("应按顺序收到战斗 %d, 实际 %d", id, ctx.GetBattleId())
+			}
+		case <-time.After(5 * defaultPublishTimeout):
+			t.Fatalf("不读取的订阅者不应让其他订阅者一直收不到事件")
+		}
+	}
+	if stats := slow.Stats(); stats.TimedOut != 1 {
+		t.Errorf("不读取的订阅者应等待超时一次: %+v", stats)
+	}
+}
+
+// Test_BattleHandle 测试 Submit 返回的战斗句柄: 分配 ID、输入、等待结果和取消
+func Test_BattleHandle(t *testing.T) {
+	engine := csharp.NewSimEngine()
+	engine.DisableBattleLogging()
+
+	bm := NewBattleManagerBuilder().
+		WithEngine(engine).
+		WithFPS(200).
+		Build()
+	if err := bm.Start(); err != nil {
+		t.Fatalf("启动失败: %v", err)
+	}
+	defer bm.Stop()
+
+	newEnv := func() *pb.BattleEnv {
+		return &pb.BattleEnv{Atk: &pb.Team{TeamId: 100}, Def: &pb.Team{TeamId: 101}}
+	}
+	waitCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	// 未指定 ID 时由 GenerateBattleID 分配，强制结束后 Wait 返回结果和回放
+	h, err := bm.Submit(context.Background(), newEnv())
+	if err != nil {
+		t.Fatalf("提交战斗失败: %v", err)
+	}
+	if h.ID() == 0 {
+		t.Fatalf("应分配战斗 ID")
+	}
+	if err := h.Input(&pb.BattleEnd{Winner: 101}); err != nil {
+		t.Fatalf("输入失败: %v", err)
+	}
+	result, replay, err := h.Wait(waitCtx)
+	if err != nil {
+		t.Fatalf("等待战斗结束失败: %v", err)
+	}
+	if result.GetWinner() != 101 || !proto.Equal(replay.GetResult(), result) {
+		t.Errorf("结果异常: %v / %v", result, replay.GetResult())
+	}
+	if err := h.Cancel(); err != nil {
+		t.Errorf("已结束的战斗取消应无操作: %v", err)
+	}
+	if _, _, err := h.Wait(waitCtx); err != nil {
+		t.Errorf("已结束的战斗取消后 Wait 结果不应改变: %v", err)
+	}
+
+	// Cancel 销毁战斗
+	h, err = bm.Submit(context.Background(), newEnv())
+	if err != nil {
+		t.Fatalf("提交战斗失败: %v", err)
+	}
+	if err := h.Input(&pb.BattlePause{}); err != nil {
+		t.Fatalf("输入失败: %v", err)
+	}
+	if err := h.Cancel(); err != nil {
+		t.Fatalf("取消失败: %v", err)
+	}
+	if _, _, err := h.Wait(waitCtx); !errors.Is(err, ErrBattleCanceled) {
+		t.Errorf("期望 ErrBattleCanceled, 实际 %v", err)
+	}
+	if _, err := bm.GetBattleStatus(h.ID()); err == nil {
+		t.Errorf("取消的战斗应已销毁")
+	}
+	if err := h.Input(&pb.BattleResume{}); err == nil {
+		t.Errorf("取消的战斗不应接受输入")
+	}
+
+	// BattleDestroy 输入销毁的战斗不输出回放，Wait 返回 ErrBattleDestroyed
+	h, err = bm.Submit(context.Background(), newEnv())
+	if err != nil {
+		t.Fatalf("提交战斗失败: %v", err)
+	}
+	if err := h.Input(&pb.BattleDestroy{}); err != nil {
+		t.Fatalf("输入失败: %v", err)
+	}
+	if _, _, err := h.Wait(waitCtx); !errors.Is(err, ErrBattleDestroyed) {
+		t.Errorf("期望 ErrBattleDestroyed, 实际 %v", err)
+	}
+
+	// Submit 的 ctx 取消时销毁战斗
+	submitCtx, cancelSubmit := context.WithCancel(context.Background())
+	h, err = bm.Submit(submitCtx, newEnv())
+	if err != nil {
+		t.Fatalf("提交战斗失败: %v", err)
+	}
+	if err := h.Input(&pb.BattlePause{}); err != nil {
+		t.Fatalf("输入失败: %v", err)
+	}
+	cancelSubmit()
+	if _, _, err := h.Wait(waitCtx); !errors.Is(err, ErrBattleCanceled) {
+		t.Errorf("期望 ErrBattleCanceled, 实际 %v", err)
+	}
+	if _, err := bm.GetBattleStatus(h.ID()); err == nil {
+		t.Errorf("ctx 取消的战斗应已销毁")
+	}
+}
+
+// Test_BattleHandleReplayFirst 测试回放先于结果到达时 Wait 返回回放中的结果
+func Test_BattleHandleReplayFirst(t *testing.T) {
+	eb := NewEventBus(4)
+	defer eb.Close()
+
+	h := &BattleHandle{
+		id:   7,
+		bm:   &BattleManager{handles: make(map[uint64]*BattleHandle)},
+		done: make(chan struct{}),
+		sub:  eb.Subscribe(EventFilter{BattleIDs: []uint32{7}, Topics: TopicOutput}, 2, LaneOptions{}),
+	}
+	replay := &pb.BattleReplay{BattleId: 7, Result: &pb.BattleResult{Winner: 101}}
+	eb.Publish(&pb.BattleContext{BattleId: 7, Option: &pb.BattleContext_BattleOutput{
+		BattleOutput: &pb.BattleOutput{Output: &pb.BattleOutput_Replay{Replay: replay}},
+	}})
+	go h.watch(context.Background())
+
+	waitCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	result, _, err := h.Wait(waitCtx)
+	if err != nil || result.GetWinner() != 101 {
+		t.Errorf("应返回回放中的结果, 实际 %v, %v", result, err)
+	}
+}
+
+// dropReplayBus 不发布回放的事件总线，模拟订阅者没有收到回放
+type dropReplayBus struct {
+	*EventBusImpl
+}
+
+func (b dropReplayBus) Publish(event *pb.BattleContext) error {
+	if event.GetBattleOutput().GetReplay() != nil {
+		return nil
+	}
+	return b.EventBusImpl.Publish(event)
+}
+
+// Test_BattleSweeperFinishesHandle 测试没有收到回放的句柄在战斗保留期满被销毁时完成
+func Test_BattleSweeperFinishesHandle(t *testing.T) {
+	engine := csharp.NewSimEngine()
+	engine.DisableBattleLogging()
+
+	eb := NewEventBus(16)
+	defer eb.Close()
+	bm := NewBattleManagerBuilder().
+		WithEngine(engine).
+		WithEventBus(dropReplayBus{eb}).
+		WithFPS(200).
+		WithRetentionPolicy(RetentionPolicy{Retention: 50 * time.Millisecond, Interval: 10 * time.Millisecond}).
+		Build()
+	if err := bm.Start(); err != nil {
+		t.Fatalf("启动失败: %v", err)
+	}
+	defer bm.Stop()
+
+	h, err := bm.Submit(context.Background(), &pb.BattleEnv{Atk: &pb.Team{TeamId: 100}, Def: &pb.Team{TeamId: 101}})
+	if err != nil {
+		t.Fatalf("提交战斗失败: %v", err)
+	}
+	if err := h.Input(&pb.BattleEnd{Winner: 100}); err != nil {
+		t.Fatalf("输入失败: %v", err)
+	}
+
+	waitCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if _, _, err := h.Wait(waitCtx); !errors.Is(err, ErrBattleDestroyed) {
+		t.Errorf("保留期满销毁后期望 ErrBattleDestroyed, 实际 %v", err)
+	}
+	if stats := bm.SweepStats(); stats.Destroyed != 1 {
+		t.Errorf("清理计数错误: %+v", stats)
+	}
+}
+
+// Test_DropLostBattles 测试替换不健康的库后丢弃未迁移的战斗，句柄完成且不再接受输入
+func Test_DropLostBattles(t *testing.T) {
+	engine := csharp.NewSimEngine()
+	engine.DisableBattleLogging()
+
+	bm := NewBattleManagerBuilder().
+		WithEngine(engine).
+		WithFPS(200).
+		WithShards(2).
+		Build()
+	if err := bm.Start(); err != nil {
+		t.Fatalf("启动失败: %v", err)
+	}
+	defer bm.Stop()
+
+	var handles []*BattleHandle
+	for i := 0; i < 4; i++ {
+		h, err := bm.Submit(context.Background(), &pb.BattleEnv{Atk: &pb.Team{TeamId: 100}, Def: &pb.Team{TeamId: 101}})
+		if err != nil {
+			t.Fatalf("提交战斗失败: %v", err)
+		}
+		handles = append(handles, h)
+	}
+
+	// 与不健康的库被替换后相同: 新引擎中没有任何战斗
+	defer engine.Close()
+	next := csharp.NewSimEngine()
+	next.DisableBattleLogging()
+	bm.mu.Lock()
+	bm.engine = next
+	bm.mu.Unlock()
+	bm.battleCtrls.(EngineBinder).BindEngine(next)
+	bm.dropLostBattles()
+
+	waitCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	for _, h := range handles {
+		if _, _, err := h.Wait(waitCtx); !errors.Is(err, ErrBattleDestroyed) {
+			t.Errorf("战斗 %d 期望 ErrBattleDestroyed, 实际 %v", h.ID(), err)
+		}
+		if _, ok := bm.battleCtrls.(*Proxy).GetBattleController(h.ID()); ok {
+			t.Errorf("战斗 %d 的控制器应已移除", h.ID())
+		}
+	}
+	if n := len(bm.battleCtrls.(*Proxy).TrackedBattles()); n != 0 {
+		t.Errorf("不应再记录任何战斗, 实际 %d 场", n)
+	}
+}
+
+// Test_BattleSweeper 测试结束的战斗在保留期满后销毁，超过最长时长的战斗以超时结果强制结束
+func Test_BattleSweeper(t *testing.T) {
+	engine := csharp.NewSimEngine()
+	engine.DisableBattleLogging()
+
+	bm := NewBattleManagerBuilder().
+		WithEngine(engine).
+		WithFPS(200).
+		WithRetentionPolicy(RetentionPolicy{
+			Retention:   50 * time.Millisecond,
+			MaxDuration: 200 * time.Millisecond,
+			Interval:    10 * time.Millisecond,
+		}).
+		Build()
+	if err := bm.Start(); err != nil {
+		t.Fatalf("启动失败: %v", err)
+	}
+	defer bm.Stop()
+
+	waitCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	submit := func() *BattleHandle {
+		t.Helper()
+		h, err := bm.Submit(context.Background(), &pb.BattleEnv{Atk: &pb.Team{TeamId: 100}, Def: &pb.Team{TeamId: 101}})
+		if err != nil {
+			t.Fatalf("提交战斗失败: %v", err)
+		}
+		return h
+	}
+
+	// 正常结束的战斗没有超时标记
+	ended := submit()
+	if err := ended.Input(&pb.BattleEnd{Winner: 100}); err != nil {
+		t.Fatalf("输入失败: %v", err)
+	}
+	// 暂停的战斗不会自己结束，超过最长时长后被强制结束
+	stuck := submit()
+	if err := stuck.Input(&pb.BattlePause{}); err != nil {
+		t.Fatalf("输入失败: %v", err)
+	}
+
+	result, _, err := ended.Wait(waitCtx)
+	if err != nil || result.GetWinner() != 100 || result.GetTimeout() {
+		t.Fatalf("正常结束的结果异常: %v, %v", result, err)
+	}
+	result, replay, err := stuck.Wait(waitCtx)
+	if err != nil || !result.GetTimeout() || !replay.GetResult().GetTimeout() {
+		t.Fatalf("超时结束的结果应有超时标记: %v, %v", result, err)
+	}
+
+	// 保留期满后两侧都不再有这两场战斗
+	for {
+		battles, err := bm.ListBattles()
+		if err != nil {
+			t.Fatalf("查询战斗失败: %v", err)
+		}
+		if len(battles) == 0 {
+			break
+		}
+		select {
+		case <-waitCtx.Done():
+			t.Fatalf("等待清理超时, 剩余 %v", battles)
+		case <-time.After(10 * time.Millisecond):
+		}
+	}
+	if _, ok := bm.battleCtrls.(*Proxy).GetBattleController(ended.ID()); ok {
+		t.Errorf("Proxy 中的战斗应已销毁")
+	}
+	if stats := bm.SweepStats(); stats != (SweepStats{Destroyed: 2, TimedOut: 1}) {
+		t.Errorf("清理计数错误: %+v", stats)
+	}
+}
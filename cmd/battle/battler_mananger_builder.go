@@ -1,21 +1,40 @@
 package main
 
 import (
+	"time"
+
+	"goPureWithCsharp/csharp"
 	pb "goPureWithCsharp/csharp/proto"
 )
 
 type BattleManagerBuilder struct {
-	eventBus   EventBus
-	dispatcher BattleDisptcher
-	fps        int64
-	bufferSize int
-	outPutChan chan *pb.BattleContext
+	eventBus    EventBus
+	dispatcher  BattleDisptcher
+	fps         int64
+	bufferSize  int
+	outPutChan  chan *pb.BattleContext
+	engine      csharp.Engine
+	loadOpts    csharp.LoadOptions
+	callTimeout time.Duration
+	ringSize    int
+	maxCatchUp  int
+	journalDir  string
+	output      BattleOutput
+	shards      int
+	busOpts     EventBusOptions
+	retention   RetentionPolicy
 }
 
 func NewBattleManagerBuilder() *BattleManagerBuilder {
 	return &BattleManagerBuilder{
-		fps:        30,
-		bufferSize: 100,
+		fps:         30,
+		bufferSize:  100,
+		callTimeout: defaultCallTimeout,
+		ringSize:    csharp.DefaultRingCapacity,
+		maxCatchUp:  defaultMaxCatchUpFrames,
+		shards:      defaultShards,
+		busOpts:     DefaultEventBusOptions(0), // 车道容量在 Build 时取 bufferSize
+		retention:   DefaultRetentionPolicy(),
 	}
 }
 
@@ -24,6 +43,31 @@ func (b *BattleManagerBuilder) WithEventBus(eb EventBus) *BattleManagerBuilder {
 	return b
 }
 
+// WithOverflowPolicy 指定默认事件总线中 lane 车道满时的处理策略
+// 默认输出车道 OverflowBlock，输入车道 OverflowDropNewest；指定 WithEventBus 时无效
+func (b *BattleManagerBuilder) WithOverflowPolicy(lane EventLane, policy OverflowPolicy) *BattleManagerBuilder {
+	switch lane {
+	case LaneInput:
+		b.busOpts.Input.Policy = policy
+	case LaneOutput:
+		b.busOpts.Output.Policy = policy
+	}
+	return b
+}
+
+// WithPublishTimeout 指定 OverflowBlock 车道满时发布方最多等待的时间
+func (b *BattleManagerBuilder) WithPublishTimeout(d time.Duration) *BattleManagerBuilder {
+	b.busOpts.Input.Timeout = d
+	b.busOpts.Output.Timeout = d
+	return b
+}
+
+// WithSpillDir 指定 OverflowSpill 溢出文件所在的目录
+func (b *BattleManagerBuilder) WithSpillDir(dir string) *BattleManagerBuilder {
+	b.busOpts.SpillDir = dir
+	return b
+}
+
 func (b *BattleManagerBuilder) WithDispatcher(d BattleDisptcher) *BattleManagerBuilder {
 	b.dispatcher = d
 	return b
@@ -34,6 +78,68 @@ func (b *BattleManagerBuilder) WithBattleOutputChan(ch chan *pb.BattleContext) *
 	return b
 }
 
+// WithEngine 指定战斗引擎后端 (例如 csharp.NewSimEngine())
+// 不指定时 Start 按 WithLoadOptions 打开 C# 动态库；同一进程可为不同 BattleManager 指定不同的 csharp.Library
+func (b *BattleManagerBuilder) WithEngine(e csharp.Engine) *BattleManagerBuilder {
+	b.engine = e
+	return b
+}
+
+// WithLoadOptions 指定未设置引擎时加载 C# 动态库的查找路径和 dlopen 标志
+func (b *BattleManagerBuilder) WithLoadOptions(opts csharp.LoadOptions) *BattleManagerBuilder {
+	b.loadOpts = opts
+	return b
+}
+
+// WithCallTimeout 指定事件循环中每次 C# 调用 (OnTick 等) 的超时时间
+// 超时后引擎被标记为不健康，需要 Reload 替换
+func (b *BattleManagerBuilder) WithCallTimeout(d time.Duration) *BattleManagerBuilder {
+	b.callTimeout = d
+	return b
+}
+
+// WithRingTransport 指定输入/输出环形缓冲区大小 (字节)，0 表示不使用
+// 引擎支持 csharp.FeatureRingTransport 时输入写入共享内存由 OnTick 批量处理，输出在 OnTick 后批量读取
+func (b *BattleManagerBuilder) WithRingTransport(size int) *BattleManagerBuilder {
+	b.ringSize = size
+	return b
+}
+
+// WithMaxCatchUpFrames 指定事件循环一次唤醒最多补执行的逻辑帧数
+// 落后更多时跳过最早的帧
+func (b *BattleManagerBuilder) WithMaxCatchUpFrames(n int) *BattleManagerBuilder {
+	b.maxCatchUp = n
+	return b
+}
+
+// WithJournalDir 指定战斗日志目录，每场战斗的环境、输入和输出写入 battle_<id>.journal
+// 日志可用 cmd/replay 重新执行并比较输出；为空时不记录
+func (b *BattleManagerBuilder) WithJournalDir(dir string) *BattleManagerBuilder {
+	b.journalDir = dir
+	return b
+}
+
+// WithBattleOutput 指定接收战斗结果和回放的 BattleOutput
+// 不指定时使用实现了 BattleOutput 的 dispatcher (Proxy)
+func (b *BattleManagerBuilder) WithBattleOutput(o BattleOutput) *BattleManagerBuilder {
+	b.output = o
+	return b
+}
+
+// WithShards 指定分片数，战斗 ID 哈希到各分片，每个分片有自己的事件循环并行驱动战斗
+// 多于一个分片时引擎需支持 csharp.FeatureShards，且不使用环形缓冲区
+func (b *BattleManagerBuilder) WithShards(n int) *BattleManagerBuilder {
+	b.shards = n
+	return b
+}
+
+// WithRetentionPolicy 指定结束的战斗的保留时间、战斗最长时长和清理间隔
+// 默认 DefaultRetentionPolicy；Interval 为 0 时不自动清理，战斗留到 Stop
+func (b *BattleManagerBuilder) WithRetentionPolicy(p RetentionPolicy) *BattleManagerBuilder {
+	b.retention = p
+	return b
+}
+
 func (b *BattleManagerBuilder) WithFPS(fps int64) *BattleManagerBuilder {
 	b.fps = fps
 	return b
@@ -47,7 +153,8 @@ func (b *BattleManagerBuilder) WithBufferSize(size int) *BattleManagerBuilder {
 func (b *BattleManagerBuilder) Build() *BattleManager {
 
 	if b.eventBus == nil {
-		b.eventBus = NewEventBus(b.bufferSize)
+		b.busOpts.BufferSize = b.bufferSize
+		b.eventBus = NewEventBusWithOptions(b.busOpts)
 	}
 
 	// 创建 FrameSeqGenerator
@@ -56,19 +163,47 @@ func (b *BattleManagerBuilder) Build() *BattleManager {
 	if b.dispatcher == nil {
 		b.dispatcher = NewProxy(fpsProvider)
 	}
+	if b.output == nil {
+		b.output, _ = b.dispatcher.(BattleOutput)
+	}
+	if b.shards <= 0 {
+		b.shards = defaultShards
+	}
+	if binder, ok := b.dispatcher.(ShardBinder); ok {
+		binder.BindShards(b.shards)
+	}
 
 	// 创建命令通道
 	createChan := make(chan *pb.BattleEnv, b.bufferSize)
 
-	return &BattleManager{
-		EventBus:    b.eventBus,
-		fpsProvider: fpsProvider,
-		createChan:  createChan,
-		outPutChan:  b.outPutChan,
-		battleCtrls: b.dispatcher,
-		state:       StateCreated,
-		stopChan:    make(chan struct{}),
+	bm := &BattleManager{
+		EventBus:      b.eventBus,
+		fpsProvider:   fpsProvider,
+		frameInterval: fpsProvider.frameTime,
+		createChan:    createChan,
+		reloadChan:    make(chan *ReloadCommand),
+		outPutChan:    b.outPutChan,
+		battleCtrls:   b.dispatcher,
+		output:        b.output,
+		engine:        b.engine,
+		loadOpts:      b.loadOpts,
+		callTimeout:   b.callTimeout,
+		ringCapacity:  b.ringSize,
+		journalDir:    b.journalDir,
+		retention:     b.retention,
+		handles:       make(map[uint64]*BattleHandle),
+		state:         StateCreated,
+		stopChan:      make(chan struct{}),
+	}
+
+	// 路由循环与其他订阅者一样从事件总线扇出收到输入，缓冲区满时阻塞投递而不丢弃
+	bm.inputSub = b.eventBus.Subscribe(EventFilter{Topics: TopicInput}, b.bufferSize, LaneOptions{Policy: OverflowBlock})
+
+	// 每个分片有自己的 TickDriver，落后和补帧互不影响
+	for i := 0; i < b.shards; i++ {
+		bm.shards = append(bm.shards, newBattleShard(uint32(i), bm, NewTickDriver(fpsProvider, b.maxCatchUp), b.bufferSize))
 	}
+	return bm
 } // BuildAsSingleton 构建并初始化为全局单例
 // 如果单例已存在，直接返回现有实例，不会再次构建
 func (b *BattleManagerBuilder) BuildAsSingleton() *BattleManager {
@@ -0,0 +1,471 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	pb "goPureWithCsharp/csharp/proto"
+)
+
+// ============================================================================
+// EventBus - 战斗输入和输出的事件总线
+// ============================================================================
+//
+// 发布的事件先按类型进入输入或输出车道，总线的投递 goroutine 优先取出输出车道的事件，
+// 扇出给所有过滤条件匹配的订阅者 (见 Subscription)。
+// 车道满时按 OverflowPolicy 处理，丢弃或超时都以 *OverflowError 返回给发布方并计数:
+//
+//	OverflowBlock      阻塞等待空位，超过 Timeout 返回错误 (Timeout 为 0 时使用 defaultPublishTimeout)
+//	OverflowDropOldest 丢弃车道中最早的事件，返回的错误记录被丢弃的战斗
+//	OverflowDropNewest 丢弃正在发布的事件
+//	OverflowSpill      写入磁盘溢出文件，车道有空位时按发布顺序送回，不丢弃
+
+// EventLane 事件车道
+type EventLane int
+
+const (
+	LaneInput  EventLane = iota // BattleInput 及未知类型
+	LaneOutput                  // BattleOutput (结果、回放)
+)
+
+func (l EventLane) String() string {
+	switch l {
+	case LaneInput:
+		return "input"
+	case LaneOutput:
+		return "output"
+	}
+	return fmt.Sprintf("EventLane(%d)", int(l))
+}
+
+// laneOf 事件所在的车道
+func laneOf(event *pb.BattleContext) EventLane {
+	if _, ok := event.GetOption().(*pb.BattleContext_BattleOutput); ok {
+		return LaneOutput
+	}
+	return LaneInput
+}
+
+// OverflowPolicy 车道满时的处理策略
+type OverflowPolicy int
+
+const (
+	OverflowBlock OverflowPolicy = iota
+	OverflowDropOldest
+	OverflowDropNewest
+	OverflowSpill
+)
+
+func (p OverflowPolicy) String() string {
+	switch p {
+	case OverflowBlock:
+		return "block"
+	case OverflowDropOldest:
+		return "drop-oldest"
+	case OverflowDropNewest:
+		return "drop-newest"
+	case OverflowSpill:
+		return "spill"
+	}
+	return fmt.Sprintf("OverflowPolicy(%d)", int(p))
+}
+
+// defaultPublishTimeout OverflowBlock 默认的等待时间
+const defaultPublishTimeout = time.Second
+
+var (
+	// ErrEventBusFull 车道已满，事件被丢弃或等待超时，*OverflowError 可用 errors.Is 匹配
+	ErrEventBusFull = errors.New("事件队列已满")
+	// ErrEventBusClosed 事件总线已关闭
+	ErrEventBusClosed = errors.New("事件总线已关闭")
+)
+
+// OverflowError 车道满时按 Policy 丢弃了一个事件
+// OverflowDropOldest 时 BattleID 为被挤掉的旧事件所属战斗，正在发布的事件已入队
+type OverflowError struct {
+	Lane     EventLane
+	Policy   OverflowPolicy
+	BattleID uint32
+}
+
+func (e *OverflowError) Error() string {
+	return fmt.Sprintf("%s 车道已满 (%s): 丢弃战斗 %d 的事件", e.Lane, e.Policy, e.BattleID)
+}
+
+func (e *OverflowError) Unwrap() error {
+	return ErrEventBusFull
+}
+
+// LaneOptions 单条车道或订阅者缓冲区的溢出策略
+type LaneOptions struct {
+	Policy  OverflowPolicy
+	Timeout time.Duration // OverflowBlock 的等待时间，为 0 时使用 defaultPublishTimeout
+}
+
+// EventBusOptions 事件总线选项
+type EventBusOptions struct {
+	BufferSize int // 每条车道的容量
+	Input      LaneOptions
+	Output     LaneOptions
+	SpillDir   string // OverflowSpill 溢出文件所在目录，为空时使用系统临时目录
+}
+
+// DefaultEventBusOptions 输出车道阻塞等待，避免丢失战斗结果；输入车道满时拒绝新输入
+func DefaultEventBusOptions(bufferSize int) EventBusOptions {
+	return EventBusOptions{
+		BufferSize: bufferSize,
+		Input:      LaneOptions{Policy: OverflowDropNewest},
+		Output:     LaneOptions{Policy: OverflowBlock, Timeout: defaultPublishTimeout},
+	}
+}
+
+// LaneStats 单条车道的计数
+type LaneStats struct {
+	Published     uint64 // 入队 (含溢出到磁盘) 的事件
+	TimedOut      uint64 // OverflowBlock 等待超时
+	DroppedOldest uint64 // OverflowDropOldest 挤掉的旧事件
+	DroppedNewest uint64 // OverflowDropNewest 拒绝的新事件
+	Spilled       uint64 // OverflowSpill 写入磁盘的事件
+}
+
+// EventBusStats 事件总线计数
+type EventBusStats struct {
+	Input  LaneStats
+	Output LaneStats
+}
+
+type EventBus interface {
+	// Subscribe 订阅匹配 filter 的事件，订阅者有自己的 bufferSize 缓冲区，满时按 overflow 处理
+	Subscribe(filter EventFilter, bufferSize int, overflow LaneOptions) *Subscription
+	// Publish 按事件类型发布到车道，车道满且事件被丢弃时返回 *OverflowError
+	Publish(event *pb.BattleContext) error
+}
+
+// EventBusImpl 事件总线实现
+type EventBusImpl struct {
+	lanes      [2]*eventLane
+	spillDir   string
+	done       chan struct{} // Close 时关闭，唤醒阻塞的发布方和溢出泵
+	dispatched chan struct{} // 投递 goroutine 退出时关闭
+	once       sync.Once
+	mu         sync.RWMutex // 发布方持有读锁，Close 持有写锁关闭车道通道
+	closed     bool
+
+	subMu sync.RWMutex // 保护 subs，投递前复制一份，投递期间不持有
+	subs  []*Subscription
+}
+
+// NewEventBus 使用默认溢出策略创建事件总线
+func NewEventBus(bufferSize int) *EventBusImpl {
+	return NewEventBusWithOptions(DefaultEventBusOptions(bufferSize))
+}
+
+// NewEventBusWithOptions 按 opts 创建事件总线
+// OverflowSpill 的溢出文件在第一次溢出时创建，创建失败时该事件以 *OverflowError 返回
+func NewEventBusWithOptions(opts EventBusOptions) *EventBusImpl {
+	eb := &EventBusImpl{
+		spillDir:   opts.SpillDir,
+		done:       make(chan struct{}),
+		dispatched: make(chan struct{}),
+	}
+	for lane, laneOpts := range map[EventLane]LaneOptions{LaneInput: opts.Input, LaneOutput: opts.Output} {
+		if laneOpts.Timeout <= 0 {
+			laneOpts.Timeout = defaultPublishTimeout
+		}
+		eb.lanes[lane] = newEventLane(lane, laneOpts, opts.BufferSize, opts.SpillDir, eb.done)
+	}
+	go eb.dispatch()
+	return eb
+}
+
+func newEventLane(lane EventLane, opts LaneOptions, bufferSize int, spillDir string, done <-chan struct{}) *eventLane {
+	l := &eventLane{
+		lane: lane,
+		opts: opts,
+		ch:   make(chan *pb.BattleContext, bufferSize),
+	}
+	if opts.Policy == OverflowSpill {
+		l.spill = newSpillQueue(spillDir, lane)
+		l.pumped = make(chan struct{})
+		go l.pump(done)
+	}
+	return l
+}
+
+// Subscribe 订阅匹配 filter 的事件，只收到订阅之后发布的事件
+// 订阅者的 OverflowSpill 溢出文件写在总线的 SpillDir 中
+// OverflowBlock 的等待时间有上限，一个不读取的订阅者不会让其他订阅者一直收不到事件
+func (eb *EventBusImpl) Subscribe(filter EventFilter, bufferSize int, overflow LaneOptions) *Subscription {
+	if overflow.Timeout <= 0 {
+		overflow.Timeout = defaultPublishTimeout
+	}
+	s := &Subscription{
+		bus:    eb,
+		filter: filter,
+		done:   make(chan struct{}),
+	}
+	lane := LaneInput
+	if filter.Topics != 0 && filter.Topics&TopicInput == 0 {
+		lane = LaneOutput
+	}
+	s.lane = newEventLane(lane, overflow, bufferSize, eb.spillDir, s.done)
+
+	eb.mu.RLock()
+	defer eb.mu.RUnlock()
+	eb.subMu.Lock()
+	defer eb.subMu.Unlock()
+	if eb.closed {
+		s.stop()
+		s.closeChan()
+		return s
+	}
+	eb.subs = append(eb.subs, s)
+
+	// 总线关闭时唤醒阻塞在该订阅者上的投递
+	go func() {
+		select {
+		case <-eb.done:
+			s.stop()
+		case <-s.done:
+		}
+	}()
+	return s
+}
+
+// unsubscribe 停止向 s 投递并关闭其通道
+func (eb *EventBusImpl) unsubscribe(s *Subscription) {
+	// 先唤醒可能阻塞在该订阅者上的投递，closeChan 等待投递结束
+	s.stop()
+
+	eb.subMu.Lock()
+	i := slices.Index(eb.subs, s)
+	if i >= 0 {
+		eb.subs = slices.Delete(eb.subs, i, i+1)
+	}
+	eb.subMu.Unlock()
+
+	if i >= 0 {
+		s.closeChan()
+	}
+}
+
+// dispatch 投递 goroutine，优先取出输出车道的事件并扇出给订阅者，总线关闭时退出
+func (eb *EventBusImpl) dispatch() {
+	defer close(eb.dispatched)
+
+	outputs, inputs := eb.lanes[LaneOutput].ch, eb.lanes[LaneInput].ch
+	for {
+		var event *pb.BattleContext
+		select {
+		case event = <-outputs:
+		default:
+			select {
+			case event = <-outputs:
+			case event = <-inputs:
+			case <-eb.done:
+				return
+			}
+		}
+
+		// 投递可能等待订阅者，不持有 subMu，等待期间仍可订阅和取消订阅
+		eb.subMu.RLock()
+		subs := slices.Clone(eb.subs)
+		eb.subMu.RUnlock()
+		for _, s := range subs {
+			s.deliver(event)
+		}
+	}
+}
+
+func (eb *EventBusImpl) Publish(event *pb.BattleContext) error {
+	eb.mu.RLock()
+	defer eb.mu.RUnlock()
+	if eb.closed {
+		return ErrEventBusClosed
+	}
+
+	err := eb.lanes[laneOf(event)].publish(event, eb.done)
+	if err != nil {
+		fmt.Printf("[EventBus] 发布失败 battleId=%d: %v\n", event.GetBattleId(), err)
+	}
+	return err
+}
+
+// Stats 返回各车道的发布和丢弃计数
+func (eb *EventBusImpl) Stats() EventBusStats {
+	return EventBusStats{
+		Input:  eb.lanes[LaneInput].stats(),
+		Output: eb.lanes[LaneOutput].stats(),
+	}
+}
+
+// Close 关闭所有车道和订阅；阻塞的发布方返回 ErrEventBusClosed，尚未送回的溢出事件留在溢出文件中
+// 车道中尚未投递的事件丢弃
+func (eb *EventBusImpl) Close() {
+	// 关闭 done 同时唤醒阻塞在订阅者上的投递，等待投递 goroutine 退出
+	eb.once.Do(func() { close(eb.done) })
+	<-eb.dispatched
+
+	eb.mu.Lock()
+	defer eb.mu.Unlock()
+	if eb.closed {
+		return
+	}
+	for _, l := range eb.lanes {
+		if l.spill != nil {
+			<-l.pumped // 溢出泵退出后才能关闭车道通道
+			l.spill.close()
+		}
+		close(l.ch)
+	}
+
+	eb.subMu.Lock()
+	for _, s := range eb.subs {
+		s.closeChan()
+	}
+	eb.subs = nil
+	eb.subMu.Unlock()
+	eb.closed = true
+}
+
+// eventLane 一条车道
+type eventLane struct {
+	lane   EventLane
+	opts   LaneOptions
+	ch     chan *pb.BattleContext
+	mu     sync.Mutex    // 串行化需要多步完成的溢出处理 (挤掉旧事件、写入溢出文件)
+	spill  *spillQueue   // OverflowSpill 时使用
+	pumped chan struct{} // 溢出泵退出时关闭
+
+	published     atomic.Uint64
+	timedOut      atomic.Uint64
+	droppedOldest atomic.Uint64
+	droppedNewest atomic.Uint64
+	spilled       atomic.Uint64
+}
+
+func (l *eventLane) publish(event *pb.BattleContext, done <-chan struct{}) error {
+	if l.opts.Policy != OverflowSpill {
+		select {
+		case l.ch <- event:
+			l.published.Add(1)
+			return nil
+		default:
+		}
+	}
+
+	switch l.opts.Policy {
+	case OverflowBlock:
+		if l.opts.Timeout <= 0 {
+			select {
+			case l.ch <- event:
+				l.published.Add(1)
+				return nil
+			case <-done:
+				return ErrEventBusClosed
+			}
+		}
+		timer := time.NewTimer(l.opts.Timeout)
+		defer timer.Stop()
+		select {
+		case l.ch <- event:
+			l.published.Add(1)
+			return nil
+		case <-done:
+			return ErrEventBusClosed
+		case <-timer.C:
+			l.timedOut.Add(1)
+			return l.overflow(event.GetBattleId())
+		}
+
+	case OverflowDropOldest:
+		l.mu.Lock()
+		defer l.mu.Unlock()
+		var evicted *pb.BattleContext
+		for {
+			select {
+			case l.ch <- event:
+				l.published.Add(1)
+				if evicted == nil {
+					return nil
+				}
+				return l.overflow(evicted.GetBattleId())
+			default:
+			}
+			select {
+			case old := <-l.ch:
+				evicted = old
+				l.droppedOldest.Add(1)
+			default:
+			}
+		}
+
+	case OverflowSpill:
+		l.mu.Lock()
+		defer l.mu.Unlock()
+		// 已有溢出的事件时新事件也写入文件，保持发布顺序
+		if l.spill.pending() == 0 {
+			select {
+			case l.ch <- event:
+				l.published.Add(1)
+				return nil
+			default:
+			}
+		}
+		if err := l.spill.push(event); err != nil {
+			fmt.Printf("[EventBus] 写入溢出文件失败: %v\n", err)
+			l.droppedNewest.Add(1)
+			return l.overflow(event.GetBattleId())
+		}
+		l.published.Add(1)
+		l.spilled.Add(1)
+		return nil
+	}
+
+	l.droppedNewest.Add(1)
+	return l.overflow(event.GetBattleId())
+}
+
+func (l *eventLane) overflow(battleID uint32) error {
+	return &OverflowError{Lane: l.lane, Policy: l.opts.Policy, BattleID: battleID}
+}
+
+// pump 按顺序把溢出文件中的事件送回车道，总线关闭时退出
+func (l *eventLane) pump(done <-chan struct{}) {
+	defer close(l.pumped)
+	for {
+		select {
+		case <-l.spill.signal:
+		case <-done:
+			return
+		}
+		for {
+			event, ok := l.spill.peek()
+			if !ok {
+				break
+			}
+			if event != nil {
+				select {
+				case l.ch <- event:
+				case <-done:
+					return
+				}
+			}
+			l.spill.ack()
+		}
+	}
+}
+
+func (l *eventLane) stats() LaneStats {
+	return LaneStats{
+		Published:     l.published.Load(),
+		TimedOut:      l.timedOut.Load(),
+		DroppedOldest: l.droppedOldest.Load(),
+		DroppedNewest: l.droppedNewest.Load(),
+		Spilled:       l.spilled.Load(),
+	}
+}
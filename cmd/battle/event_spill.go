@@ -0,0 +1,145 @@
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"sync"
+
+	pb "goPureWithCsharp/csharp/proto"
+
+	"google.golang.org/protobuf/encoding/protodelim"
+)
+
+// ============================================================================
+// spillQueue - OverflowSpill 车道的磁盘溢出队列
+// ============================================================================
+//
+// 溢出文件是只追加的长度前缀 (varint) BattleContext 流，与战斗日志格式相同。
+// 车道的溢出泵按顺序读回并送入车道，全部送回后清空文件从头写入。
+
+// spillQueue 磁盘溢出队列，文件在第一次 push 时创建
+type spillQueue struct {
+	mu     sync.Mutex
+	dir    string
+	lane   EventLane
+	w      *os.File // 追加写
+	rf     *os.File // 顺序读
+	r      *bufio.Reader
+	count  int           // 已写入但尚未送回的事件数
+	signal chan struct{} // push 后通知溢出泵
+}
+
+func newSpillQueue(dir string, lane EventLane) *spillQueue {
+	return &spillQueue{
+		dir:    dir,
+		lane:   lane,
+		signal: make(chan struct{}, 1),
+	}
+}
+
+// open 在 dir 下创建溢出文件，调用方需持有 mu
+func (q *spillQueue) open() error {
+	if q.w != nil {
+		return nil
+	}
+	w, err := os.CreateTemp(q.dir, fmt.Sprintf("eventbus_%s_*.spill", q.lane))
+	if err != nil {
+		return fmt.Errorf("创建溢出文件失败: %w", err)
+	}
+	rf, err := os.Open(w.Name())
+	if err != nil {
+		_ = w.Close()
+		_ = os.Remove(w.Name())
+		return fmt.Errorf("打开溢出文件失败: %w", err)
+	}
+	q.w, q.rf, q.r = w, rf, bufio.NewReader(rf)
+	return nil
+}
+
+// pending 尚未送回车道的事件数
+func (q *spillQueue) pending() int {
+	if q == nil {
+		return 0
+	}
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	return q.count
+}
+
+// push 追加一个事件并通知溢出泵
+func (q *spillQueue) push(event *pb.BattleContext) error {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	if err := q.open(); err != nil {
+		return err
+	}
+	if _, err := q.w.Seek(0, io.SeekEnd); err != nil {
+		return fmt.Errorf("写入溢出文件失败: %w", err)
+	}
+	if _, err := protodelim.MarshalTo(q.w, event); err != nil {
+		return fmt.Errorf("写入溢出文件失败: %w", err)
+	}
+	q.count++
+
+	select {
+	case q.signal <- struct{}{}:
+	default:
+	}
+	return nil
+}
+
+// peek 读出下一个待送回的事件，没有时 ok 为 false
+// 损坏的条目返回空事件，由调用方 ack 跳过
+func (q *spillQueue) peek() (event *pb.BattleContext, ok bool) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	if q.count == 0 {
+		return nil, false
+	}
+	event = &pb.BattleContext{}
+	if err := protodelim.UnmarshalFrom(q.r, event); err != nil {
+		fmt.Printf("[EventBus] 读取溢出文件失败，丢弃该事件: %v\n", err)
+		return nil, true
+	}
+	return event, true
+}
+
+// ack peek 读出的事件已送回车道；全部送回后清空文件
+func (q *spillQueue) ack() {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	q.count--
+	if q.count > 0 {
+		return
+	}
+	if err := q.w.Truncate(0); err != nil {
+		fmt.Printf("[EventBus] 清空溢出文件失败: %v\n", err)
+		return
+	}
+	if _, err := q.rf.Seek(0, io.SeekStart); err == nil {
+		q.r.Reset(q.rf)
+	}
+}
+
+// close 关闭溢出文件，没有未送回的事件时删除
+func (q *spillQueue) close() {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	if q.w == nil {
+		return
+	}
+	_ = q.w.Close()
+	_ = q.rf.Close()
+	if q.count == 0 {
+		_ = os.Remove(q.w.Name())
+	} else {
+		fmt.Printf("[EventBus] %d 个未送回的事件保留在 %s\n", q.count, q.w.Name())
+	}
+	q.w = nil
+}
@@ -0,0 +1,123 @@
+package main
+
+import (
+	"slices"
+	"sync"
+
+	pb "goPureWithCsharp/csharp/proto"
+)
+
+// ============================================================================
+// Subscription - 事件总线的订阅
+// ============================================================================
+//
+// 每个订阅者有自己的缓冲区，事件总线把每个事件投递给所有匹配的订阅者 (扇出)。
+// 订阅者缓冲区满时按订阅的 LaneOptions 处理: OverflowBlock 会让投递最多等待 Timeout，
+// 期间总线车道积压，由发布方的溢出策略处理，超时后该事件对这个订阅者丢弃；丢弃策略只影响该订阅者。
+// 同一个 *pb.BattleContext 会投递给多个订阅者，订阅者不应修改收到的事件。
+
+// Topic 事件类型，可按位组合
+type Topic uint8
+
+const (
+	TopicInput  Topic = 1 << iota // BattleInput
+	TopicResult                   // BattleOutput.result
+	TopicReplay                   // BattleOutput.replay
+
+	TopicOutput = TopicResult | TopicReplay
+)
+
+// topicOf 事件的类型，未知类型为 0，只有不按类型过滤的订阅者能收到
+func topicOf(event *pb.BattleContext) Topic {
+	switch option := event.GetOption().(type) {
+	case *pb.BattleContext_BattleInput:
+		return TopicInput
+	case *pb.BattleContext_BattleOutput:
+		switch option.BattleOutput.GetOutput().(type) {
+		case *pb.BattleOutput_Result:
+			return TopicResult
+		case *pb.BattleOutput_Replay:
+			return TopicReplay
+		}
+	}
+	return 0
+}
+
+// EventFilter 订阅过滤条件，零值匹配所有事件
+type EventFilter struct {
+	BattleIDs []uint32 // 只接收这些战斗的事件，为空时不按战斗过滤
+	Topics    Topic    // 只接收这些类型的事件，为 0 时不按类型过滤
+}
+
+// Match 事件是否满足过滤条件
+func (f EventFilter) Match(event *pb.BattleContext) bool {
+	if f.Topics != 0 && topicOf(event)&f.Topics == 0 {
+		return false
+	}
+	return len(f.BattleIDs) == 0 || slices.Contains(f.BattleIDs, event.GetBattleId())
+}
+
+// Subscription 一个订阅者
+type Subscription struct {
+	bus    *EventBusImpl
+	filter EventFilter
+	lane   *eventLane    // 订阅者自己的缓冲区和溢出策略
+	done   chan struct{} // 取消订阅或总线关闭时关闭，唤醒等待中的投递
+	once   sync.Once
+
+	mu     sync.RWMutex // 投递持有读锁，关闭通道持有写锁
+	closed bool
+}
+
+// C 返回订阅的事件通道，取消订阅或总线关闭后通道关闭
+func (s *Subscription) C() <-chan *pb.BattleContext {
+	return s.lane.ch
+}
+
+// Filter 返回订阅的过滤条件
+func (s *Subscription) Filter() EventFilter {
+	return s.filter
+}
+
+// Stats 返回投递到该订阅者的计数
+func (s *Subscription) Stats() LaneStats {
+	return s.lane.stats()
+}
+
+// Unsubscribe 取消订阅，之后不再投递，通道关闭；可重复调用
+func (s *Subscription) Unsubscribe() {
+	s.bus.unsubscribe(s)
+}
+
+// stop 唤醒等待中的投递
+func (s *Subscription) stop() {
+	s.once.Do(func() { close(s.done) })
+}
+
+// deliver 投递一个事件，丢弃时计入订阅者自己的计数
+func (s *Subscription) deliver(event *pb.BattleContext) {
+	if !s.filter.Match(event) {
+		return
+	}
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	if s.closed {
+		return
+	}
+	_ = s.lane.publish(event, s.done)
+}
+
+// closeChan 投递停止后关闭订阅通道，调用前先 stop 唤醒等待中的投递
+func (s *Subscription) closeChan() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.closed {
+		return
+	}
+	s.closed = true
+	if s.lane.spill != nil {
+		<-s.lane.pumped
+		s.lane.spill.close()
+	}
+	close(s.lane.ch)
+}
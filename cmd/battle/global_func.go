@@ -6,6 +6,7 @@ import (
 	"path/filepath"
 	"unsafe"
 
+	"goPureWithCsharp/csharp"
 	pb "goPureWithCsharp/csharp/proto"
 
 	"google.golang.org/protobuf/proto"
@@ -59,24 +60,29 @@ func loadConfig(
 	return 0
 }
 
+// newBattleOutput 返回提交给C#调用的战斗输出回调，输出发布到注册它的 BattleManager
 // 需要函数可重入
-func battleOutput(
-	outDataPtrPtr unsafe.Pointer, // C# battle output
-	len int32) int {
-	fmt.Printf(" battleOutput 数据地址 %p, 数据长度 %d\n", outDataPtrPtr, len)
-	outPutCtx := &pb.BattleContext{}
-	// 从指针读取结果数据
-	if outDataPtrPtr != nil && len != 0 {
-		dataLen := len
-		dataSlice := unsafe.Slice((*byte)(outDataPtrPtr), dataLen)
+func newBattleOutput(publish func(*pb.BattleContext) error) csharp.RegisterNotifyCb {
+	return func(
+		outDataPtrPtr unsafe.Pointer, // C# battle output
+		len int32) int {
+		fmt.Printf(" battleOutput 数据地址 %p, 数据长度 %d\n", outDataPtrPtr, len)
+		outPutCtx := &pb.BattleContext{}
+		// 从指针读取结果数据
+		if outDataPtrPtr != nil && len != 0 {
+			dataLen := len
+			dataSlice := unsafe.Slice((*byte)(outDataPtrPtr), dataLen)
 
-		err := proto.Unmarshal(dataSlice, outPutCtx)
-		if err != nil {
-			fmt.Printf("[Battle] ✗ 反序列化战斗结果失败: %v\n", err)
+			err := proto.Unmarshal(dataSlice, outPutCtx)
+			if err != nil {
+				fmt.Printf("[Battle] ✗ 反序列化战斗结果失败: %v\n", err)
+				return -1
+			}
+		}
+
+		if err := publish(outPutCtx); err != nil {
 			return -1
 		}
+		return 0
 	}
-
-	GetBattleManager().Publish(outPutCtx)
-	return 0
 }
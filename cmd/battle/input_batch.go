@@ -0,0 +1,77 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	"goPureWithCsharp/csharp"
+	pb "goPureWithCsharp/csharp/proto"
+)
+
+// ============================================================================
+// InputBatch - 收集一帧内的战斗输入，processTick 时一次提交
+// ============================================================================
+
+// InputFlusher 由收集输入的调度器实现，分片 processTick 在 OnTickShard 之前调用
+type InputFlusher interface {
+	// FlushInputs 在 shardID 分片第 frame 帧的 OnTickShard 之前提交该分片自上次 Tick 以来收集的输入，返回被拒绝的输入数量
+	FlushInputs(ctx context.Context, shardID uint32, frame uint64) (int, error)
+}
+
+// InputBatch 待提交的战斗输入
+type InputBatch struct {
+	mu       sync.Mutex
+	contexts []*pb.BattleContext
+}
+
+func NewInputBatch() *InputBatch {
+	return &InputBatch{}
+}
+
+// Add 加入一条输入，下一次 Flush 时提交
+func (b *InputBatch) Add(ctx *pb.BattleContext) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.contexts = append(b.contexts, ctx)
+}
+
+// Len 待提交的输入数量
+func (b *InputBatch) Len() int {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	return len(b.contexts)
+}
+
+// Flush 通过一次 ProcessBattleContextBatch 调用提交全部输入，返回被拒绝的输入数量
+// 输入的 Tick 改为应用它的帧 frame，复算时按该帧重放；调用失败时这一批输入丢弃，不会在下一帧重复提交
+func (b *InputBatch) Flush(ctx context.Context, e csharp.Engine, frame uint64) (int, error) {
+	b.mu.Lock()
+	pending := b.contexts
+	b.contexts = nil
+	b.mu.Unlock()
+
+	if len(pending) == 0 {
+		return 0, nil
+	}
+	for _, input := range pending {
+		input.Tick = frame
+	}
+
+	codes, err := csharp.Supervise(e).ProcessBattleContextBatch(ctx, pending)
+	if err != nil {
+		return 0, fmt.Errorf("批量提交 %d 条输入失败: %w", len(pending), err)
+	}
+
+	rejected := 0
+	for i, code := range codes {
+		if code != pb.BattleErrorCode_SUCCESS {
+			rejected++
+			fmt.Printf("[InputBatch] 输入被拒绝 - BattleID: %d, Tick: %d, 错误码: %s\n",
+				pending[i].GetBattleId(), pending[i].GetTick(), code)
+		}
+	}
+	return rejected, nil
+}
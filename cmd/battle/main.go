@@ -49,7 +49,10 @@ func main() {
 	go func() {
 		for outChanput := range outChan {
 			fmt.Printf("[BattleOutput] 战斗输出: %+v\n", outChanput)
-			endCh <- struct{}{}
+			// 结果之后还有回放，收到回放时战斗的输出才全部完成
+			if outChanput.GetBattleOutput().GetReplay() != nil {
+				endCh <- struct{}{}
+			}
 		}
 	}()
 
@@ -0,0 +1,75 @@
+package main
+
+import (
+	"fmt"
+)
+
+// ============================================================================
+// TickDriver - 按逻辑帧驱动 OnTick
+// ============================================================================
+//
+// 事件循环的定时器只负责唤醒，真正执行哪些帧由 FrameSeqProvider 决定:
+// 每个逻辑帧恰好执行一次，定时器延迟或事件循环阻塞导致的漏帧在下次唤醒时补上。
+// 落后超过 maxCatchUp 帧时跳过最早的帧，避免长时间阻塞后一次补帧过多。
+
+// defaultMaxCatchUpFrames 一次唤醒最多补执行的帧数
+const defaultMaxCatchUpFrames = 30
+
+// TickDriver 逻辑帧驱动器，只在事件循环中使用
+type TickDriver struct {
+	frames     FrameSeqProvider
+	maxCatchUp uint64
+	next       uint64 // 下一个待执行的帧
+	started    bool
+	skipped    uint64 // 因落后过多而跳过的帧数
+}
+
+func NewTickDriver(frames FrameSeqProvider, maxCatchUp int) *TickDriver {
+	if maxCatchUp <= 0 {
+		maxCatchUp = defaultMaxCatchUpFrames
+	}
+	return &TickDriver{
+		frames:     frames,
+		maxCatchUp: uint64(maxCatchUp),
+	}
+}
+
+// Advance 依次执行从上次执行到当前逻辑帧之间的每一帧，返回执行的帧数
+// 首次调用从当前帧开始；tick 失败时停止，该帧在下次 Advance 时重试
+func (d *TickDriver) Advance(tick func(frame uint64) error) (int, error) {
+	current := d.frames.GetCurrentFrame()
+	if !d.started {
+		d.next = current
+		d.started = true
+	}
+	if current < d.next {
+		return 0, nil
+	}
+
+	if behind := current - d.next + 1; behind > d.maxCatchUp {
+		skip := behind - d.maxCatchUp
+		fmt.Printf("[TickDriver] 落后 %d 帧，跳过第 %d ~ %d 帧\n", behind, d.next, d.next+skip-1)
+		d.next += skip
+		d.skipped += skip
+	}
+
+	ticks := 0
+	for d.next <= current {
+		if err := tick(d.next); err != nil {
+			return ticks, err
+		}
+		d.next++
+		ticks++
+	}
+	return ticks, nil
+}
+
+// NextFrame 下一个待执行的帧
+func (d *TickDriver) NextFrame() uint64 {
+	return d.next
+}
+
+// Skipped 因落后过多而跳过的帧数
+func (d *TickDriver) Skipped() uint64 {
+	return d.skipped
+}
@@ -5,6 +5,7 @@ import (
 	"log"
 
 	"goPureWithCsharp/csharp"
+	proto_pb "goPureWithCsharp/csharp/proto"
 )
 
 func main() {
@@ -26,4 +27,31 @@ func main() {
 		fmt.Println("✓ Debug 版本已加载")
 	}
 	fmt.Println()
+
+	// ========== 示例 4: 多个库并存 ==========
+	fmt.Println("========== Release 与 Debug 并存 ==========")
+	release, err := csharp.OpenLibraryVersion("Release")
+	if err != nil {
+		log.Printf("打开 Release 失败: %v", err)
+		return
+	}
+	defer release.Close()
+
+	debug, err := csharp.OpenLibraryVersion("Debug")
+	if err != nil {
+		log.Printf("打开 Debug 失败: %v", err)
+		return
+	}
+	defer debug.Close()
+
+	// 战斗可以按需路由到任一实例
+	if err := release.CreateBattle(&proto_pb.BattleEnv{BattleId: 1, Atk: &proto_pb.Team{TeamId: 100}, Def: &proto_pb.Team{TeamId: 101}}); err != nil {
+		log.Printf("Release 创建战斗失败: %v", err)
+	}
+	if err := debug.CreateBattle(&proto_pb.BattleEnv{BattleId: 2, Atk: &proto_pb.Team{TeamId: 200}, Def: &proto_pb.Team{TeamId: 201}}); err != nil {
+		log.Printf("Debug 创建战斗失败: %v", err)
+	}
+	releaseCount, _ := release.GetBattleCount()
+	debugCount, _ := debug.GetBattleCount()
+	fmt.Printf("✓ Release 战斗数: %d, Debug 战斗数: %d\n", releaseCount, debugCount)
 }
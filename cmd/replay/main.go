@@ -0,0 +1,169 @@
+package main
+
+// replay 重新执行战斗日志并比较输出
+//
+//	go run ./cmd/replay -engine sim -speed 0 journal/battle_1001.journal
+//
+// 日志由 BattleManagerBuilder.WithJournalDir 记录。每个日志在独立的引擎中按记录的种子、
+// 开始帧和输入重新执行，逐帧比较产生的输出与记录的输出，任一日志不一致时退出码为 1。
+// 引擎加载 config 目录中的道具和用户操作效果配置，应与记录日志时使用的配置相同。
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"time"
+	"unsafe"
+
+	"goPureWithCsharp/csharp"
+	pb "goPureWithCsharp/csharp/proto"
+
+	"google.golang.org/protobuf/proto"
+)
+
+var (
+	engineFlag = flag.String("engine", "sim", "战斗引擎: sim (纯 Go 模拟引擎) 或 C# 库版本 Release / Debug")
+	speedFlag  = flag.Float64("speed", 0, "回放倍速，1 为按 fps 实时回放，0 为不等待")
+	fpsFlag    = flag.Int64("fps", 30, "记录日志时的逻辑帧率，与 -speed 一起决定每帧间隔")
+	quietFlag  = flag.Bool("quiet", true, "关闭引擎的战斗日志")
+)
+
+func main() {
+	flag.Usage = func() {
+		fmt.Fprintf(flag.CommandLine.Output(), "用法: %s [选项] <battle_xxx.journal>...\n", os.Args[0])
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+	if flag.NArg() == 0 || *fpsFlag <= 0 || *speedFlag < 0 {
+		flag.Usage()
+		os.Exit(2)
+	}
+
+	var interval time.Duration
+	if *speedFlag > 0 {
+		interval = time.Duration(float64(time.Second) / float64(*fpsFlag) / *speedFlag)
+	}
+
+	failed := 0
+	for _, path := range flag.Args() {
+		if err := replayFile(path, interval); err != nil {
+			fmt.Printf("✗ %s: %v\n", path, err)
+			failed++
+			continue
+		}
+		fmt.Printf("✓ %s: 输出一致\n", path)
+	}
+
+	if failed > 0 {
+		fmt.Printf("%d / %d 个日志不一致\n", failed, flag.NArg())
+		os.Exit(1)
+	}
+}
+
+// openEngine 每个日志使用独立的引擎，避免战斗之间互相影响
+func openEngine() (csharp.Engine, error) {
+	var e csharp.Engine
+	if *engineFlag == "sim" {
+		e = csharp.NewSimEngine()
+	} else {
+		lib, err := csharp.OpenLibraryVersion(*engineFlag)
+		if err != nil {
+			return nil, err
+		}
+		e = lib
+	}
+	if *quietFlag {
+		_ = e.DisableBattleLogging()
+	}
+	if err := loadBattleConfigs(e); err != nil {
+		e.Close()
+		return nil, err
+	}
+	return e, nil
+}
+
+// loadBattleConfigs 单位属性、道具和用户操作的效果由配置决定，复算前加载与线上相同的配置
+func loadBattleConfigs(e csharp.Engine) error {
+	if err := e.RegisterConfigLoader(readConfig); err != nil {
+		return err
+	}
+	for _, name := range csharp.BattleConfigs {
+		if err := e.LoadConfig(name); err != nil {
+			return fmt.Errorf("加载配置 %s 失败: %w", name, err)
+		}
+	}
+	return nil
+}
+
+// readConfig 配置加载器，返回 config 目录中的配置
+// 数据由 csharp.LoadConfigFile 缓存，写出的指针在进程内一直有效
+func readConfig(configNamePtr unsafe.Pointer, configNameLen int32, outDataPtrPtr unsafe.Pointer, outDataLenPtr unsafe.Pointer) int32 {
+	data, err := csharp.LoadConfigFile(unsafe.String((*byte)(configNamePtr), int(configNameLen)))
+	if err != nil || len(data) == 0 {
+		return -1
+	}
+	*(*unsafe.Pointer)(outDataPtrPtr) = unsafe.Pointer(&data[0])
+	*(*int32)(outDataLenPtr) = int32(len(data))
+	return 0
+}
+
+// replayFile 重新执行一个日志，逐帧比较输出
+func replayFile(path string, interval time.Duration) error {
+	journal, err := csharp.LoadJournal(path)
+	if err != nil {
+		return err
+	}
+
+	e, err := openEngine()
+	if err != nil {
+		return err
+	}
+	defer e.Close()
+
+	// 按帧分组记录的输出，回放到最后一条输出或输入所在的帧
+	expected := make(map[uint64][]*pb.BattleContext)
+	lastFrame := journal.StartFrame
+	for _, ctx := range journal.Outputs {
+		expected[ctx.GetTick()] = append(expected[ctx.GetTick()], ctx)
+		lastFrame = max(lastFrame, ctx.GetTick())
+	}
+	for _, ctx := range journal.Inputs {
+		lastFrame = max(lastFrame, ctx.GetTick())
+	}
+
+	fmt.Printf("[Replay] %s: 战斗 %d, 种子 %d, 第 %d ~ %d 帧, %d 条输入, %d 条输出\n", path,
+		journal.Env.GetBattleId(), journal.Env.GetSeed(), journal.StartFrame, lastFrame,
+		len(journal.Inputs), len(journal.Outputs))
+
+	diffs := 0
+	err = csharp.ReplayBattle(e, &journal.BattleRecord, func(frame uint64, outputs []*pb.BattleContext) bool {
+		if diff := diffOutputs(expected[frame], outputs); diff != "" {
+			fmt.Printf("  第 %d 帧: %s\n", frame, diff)
+			diffs++
+		}
+		if interval > 0 {
+			time.Sleep(interval)
+		}
+		return frame < lastFrame
+	})
+	if err != nil {
+		return err
+	}
+	if diffs > 0 {
+		return fmt.Errorf("%d 帧输出不一致", diffs)
+	}
+	return nil
+}
+
+// diffOutputs 比较一帧内记录的输出与重新执行产生的输出，一致时返回空字符串
+func diffOutputs(want, got []*pb.BattleContext) string {
+	if len(want) != len(got) {
+		return fmt.Sprintf("记录 %d 条输出, 重新执行 %d 条: 记录 %v, 重新执行 %v", len(want), len(got), want, got)
+	}
+	for i := range want {
+		if !proto.Equal(want[i], got[i]) {
+			return fmt.Sprintf("第 %d 条输出: 记录 {%v}, 重新执行 {%v}", i, want[i], got[i])
+		}
+	}
+	return ""
+}
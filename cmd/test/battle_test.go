@@ -15,33 +15,34 @@ func TestMain(m *testing.M) {
 	fmt.Println("========== Go ↔ C# 双向调用集成测试 ==========")
 	fmt.Println()
 
-	// ========== 准备阶段：编译 C# 库 ==========
-	if err := SetupCSharpLibrary(); err != nil {
-		log.Fatalf("C# 库准备失败: %v", err)
+	// BATTLE_ENGINE=sim 时直接使用纯 Go 模拟引擎，无需 .NET SDK；否则 C# 库准备失败时测试失败
+	if os.Getenv("BATTLE_ENGINE") == "sim" {
+		csharp.UseSimEngine()
+		fmt.Println("✓ 已启用模拟引擎")
+		fmt.Println()
+	} else {
+		// ========== 准备阶段：编译 C# 库 ==========
+		if err := SetupCSharpLibrary(); err != nil {
+			log.Fatalf("C# 库准备失败: %v", err)
+		}
+
+		// ========== 验证阶段：检查库文件 ==========
+		if err := VerifyCSharpLibrary(); err != nil {
+			log.Fatalf("C# 库验证失败: %v", err)
+		}
+
+		// ========== 初始化阶段 ==========
+		fmt.Println("[初始化] 加载 C# 库")
+		if err := csharp.InitCSharpLib("Release"); err != nil {
+			log.Fatalf("库初始化失败: %v", err)
+		}
+		fmt.Println("✓ C# 库已初始化")
+		fmt.Println()
 	}
 
-	// ========== 验证阶段：检查库文件 ==========
-	if err := VerifyCSharpLibrary(); err != nil {
-		log.Fatalf("C# 库验证失败: %v", err)
-	}
-
-	// ========== 初始化阶段 ==========
-	fmt.Println("[初始化] 加载 C# 库")
-	err := csharp.InitCSharpLib("Release")
-	if err != nil {
-		log.Fatalf("库初始化失败: %v", err)
-	}
-
-	// defer 必须在函数返回前执行，确保库在所有测试之后关闭
-	defer func() {
-		csharp.CloseCSharpLib()
-	}()
-
-	fmt.Println("✓ C# 库已初始化")
-	fmt.Println()
-
 	// 运行所有测试
 	code := m.Run()
+	csharp.CloseCSharpLib()
 
 	fmt.Println()
 	fmt.Println("========== 所有测试完成 ==========")
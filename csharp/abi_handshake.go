@@ -0,0 +1,213 @@
+package csharp
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"slices"
+	"strings"
+	"sync"
+	"unsafe"
+
+	proto_pb "goPureWithCsharp/csharp/proto"
+
+	"github.com/ebitengine/purego"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protodesc"
+	"google.golang.org/protobuf/types/descriptorpb"
+)
+
+// ============================================================================
+// ABI 握手 - 加载库时校验导出 ABI 版本、proto schema 和可选特性
+// ============================================================================
+//
+// validateLibrary 只能确认符号存在，无法发现参数列表变化或基于旧 battle.proto 编译的库。
+// 握手通过 GetLibInfo 读取库自报的 LibraryInfo 并与 Go 侧期望逐项比较。
+
+const (
+	// AbiVersion Go 侧期望的导出函数 ABI 版本
+	// 任何导出函数的参数列表变化时递增，并同步修改 C# LibraryCapabilities.AbiVersion
+	AbiVersion uint32 = 5
+
+	libInfoBufferSize = 1024 // GetLibInfo 初始缓冲区
+)
+
+// 可选特性，与 C# LibraryCapabilities.Features 对应
+const (
+	FeatureResponseNegotiation = "response_negotiation" // 响应缓冲区大小协商
+	FeatureHotReload           = "hot_reload"           // ExportBattleState / ImportBattleState
+	FeatureExceptionReports    = "exception_reports"    // TakeManagedException 与全局异常回调
+	FeatureRingTransport       = "ring_transport"       // AttachRingTransport 共享内存输入/输出
+	FeatureContextBatch        = "context_batch"        // ProcessBattleContextBatch 批量输入
+	FeatureBattleStatus        = "battle_status"        // ListBattleStatus 战斗状态查询
+	FeatureShards              = "shards"               // CreateShardBattle / OnTickShard 按分片创建和驱动战斗
+)
+
+// optionalExports 不在 requiredFuncs 中的导出函数
+// 旧版本库可能缺少这些导出，调用前应通过 HasExport 确认
+var optionalExports = []string{
+	"GetLibInfo",
+	"GetLibVersion",
+	"InjectedExceptionContext",
+	"TakeManagedException",
+	"RegisterConfigLoader",
+	"LoadConfig",
+	"GetConfigLoaderDataCSharp",
+	"ProcessBattleContextInput",
+	"ExportBattleState",
+	"ImportBattleState",
+	"CallGoGlobalHandleBattleNotification",
+	"CallGoSimpleGlobalFunction",
+	"CallGoCalculateSum",
+	"AttachRingTransport",
+	"ProcessBattleContextBatch",
+	"ListBattleStatus",
+	"CreateShardBattle",
+	"OnTickShard",
+}
+
+// SchemaHash 返回 Go 侧编译进来的 battle.proto 描述符的 SHA-256 (十六进制小写)
+// 描述符去掉 json_name 和源码信息后确定性序列化，与 C# BattleReflection.Descriptor.SerializedData 一致
+var SchemaHash = sync.OnceValue(func() string {
+	fdp := protodesc.ToFileDescriptorProto(proto_pb.File_battle_proto)
+	fdp.SourceCodeInfo = nil
+	for _, m := range fdp.MessageType {
+		clearJSONName(m)
+	}
+
+	data, err := proto.MarshalOptions{Deterministic: true}.Marshal(fdp)
+	if err != nil {
+		panic(fmt.Sprintf("battle.proto 描述符序列化失败: %v", err))
+	}
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+})
+
+// clearJSONName 清除字段的 json_name，protoc 生成 C# 代码时不嵌入该字段
+func clearJSONName(m *descriptorpb.DescriptorProto) {
+	for _, f := range m.Field {
+		f.JsonName = nil
+	}
+	for _, nested := range m.NestedType {
+		clearJSONName(nested)
+	}
+}
+
+// ExpectedLibraryInfo 返回 Go 侧期望的库信息
+func ExpectedLibraryInfo() *proto_pb.LibraryInfo {
+	return &proto_pb.LibraryInfo{
+		AbiVersion: AbiVersion,
+		SchemaHash: SchemaHash(),
+	}
+}
+
+// CompatibilityError 库与 Go 侧不兼容时返回，Error 列出每一项差异
+type CompatibilityError struct {
+	Path     string
+	Expected *proto_pb.LibraryInfo // Go 侧期望
+	Actual   *proto_pb.LibraryInfo // 库报告的信息，库未导出 GetLibInfo 时为 nil
+}
+
+func (e *CompatibilityError) Error() string {
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "C# 库与 Go 侧不兼容: %s", e.Path)
+
+	if e.Actual == nil {
+		fmt.Fprintf(&sb, "\n  - 库未导出 GetLibInfo (ABI 版本 0)，Go 期望 ABI 版本 %d", e.Expected.GetAbiVersion())
+		return sb.String()
+	}
+
+	if e.Actual.GetAbiVersion() != e.Expected.GetAbiVersion() {
+		fmt.Fprintf(&sb, "\n  - ABI 版本: Go=%d, 库=%d", e.Expected.GetAbiVersion(), e.Actual.GetAbiVersion())
+	}
+	if e.Actual.GetSchemaHash() != e.Expected.GetSchemaHash() {
+		fmt.Fprintf(&sb, "\n  - battle.proto schema: Go=%s, 库=%s", e.Expected.GetSchemaHash(), e.Actual.GetSchemaHash())
+	}
+	fmt.Fprintf(&sb, "\n  库版本: %s, 特性: %v", e.Actual.GetVersion(), e.Actual.GetFeatures())
+	return sb.String()
+}
+
+// handshake 读取库信息并与 Go 侧期望比较，在 openLibrary 中调用
+func (l *Library) handshake() error {
+	expected := ExpectedLibraryInfo()
+
+	if !l.HasExport("GetLibInfo") {
+		return &CompatibilityError{Path: l.path, Expected: expected}
+	}
+
+	l.mu.RLock()
+	info, err := l.readLibInfo()
+	l.mu.RUnlock()
+	if err != nil {
+		return fmt.Errorf("读取库信息失败: %w", err)
+	}
+
+	if info.GetAbiVersion() != expected.GetAbiVersion() || info.GetSchemaHash() != expected.GetSchemaHash() {
+		return &CompatibilityError{Path: l.path, Expected: expected, Actual: info}
+	}
+
+	l.info = info
+	fmt.Printf("[Go] ABI 握手成功: %s (ABI=%d, 特性=%v)\n", info.GetVersion(), info.GetAbiVersion(), info.GetFeatures())
+	return nil
+}
+
+// readLibInfo 调用 GetLibInfo，调用方需持有 l.mu 读锁
+func (l *Library) readLibInfo() (*proto_pb.LibraryInfo, error) {
+	fnPtr, err := l.getCachedFunction("GetLibInfo")
+	if err != nil {
+		return nil, err
+	}
+
+	data, err := negotiateResponse("GetLibInfo", libInfoBufferSize, func(buf []byte) int32 {
+		bufLen := int32(len(buf))
+		purego.SyscallN(
+			fnPtr,
+			uintptr(unsafe.Pointer(&buf[0])),
+			uintptr(unsafe.Pointer(&bufLen)),
+		)
+		return bufLen
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	info := &proto_pb.LibraryInfo{}
+	if err := proto.Unmarshal(data, info); err != nil {
+		return nil, fmt.Errorf("库信息反序列化失败: %w", err)
+	}
+	return info, nil
+}
+
+// Info 返回握手时读取的库信息
+func (l *Library) Info() *proto_pb.LibraryInfo {
+	return proto.Clone(l.info).(*proto_pb.LibraryInfo)
+}
+
+// HasExport 库是否导出了指定函数，调用 optionalExports 中的函数前使用
+func (l *Library) HasExport(name string) bool {
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+
+	if l.handle == 0 {
+		return false
+	}
+	_, err := l.getCachedFunction(name)
+	return err == nil
+}
+
+// HasFeature 库是否支持指定的可选特性 (Feature* 常量)
+func (l *Library) HasFeature(feature string) bool {
+	return slices.Contains(l.info.GetFeatures(), feature)
+}
+
+// HasExport 当前引擎是否提供指定导出函数
+func HasExport(name string) bool {
+	e := CurrentEngine()
+	return e != nil && e.HasExport(name)
+}
+
+// HasFeature 当前引擎是否支持指定的可选特性
+func HasFeature(feature string) bool {
+	e := CurrentEngine()
+	return e != nil && e.HasFeature(feature)
+}
@@ -0,0 +1,92 @@
+package csharp
+
+import (
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"os"
+	"regexp"
+	"strings"
+	"testing"
+
+	proto_pb "goPureWithCsharp/csharp/proto"
+)
+
+// TestSchemaHashMatchesCSharp 测试 Go 侧 schema 哈希与 C# 生成代码中嵌入的描述符一致
+// 只重新生成了一侧的 proto 代码时失败
+func TestSchemaHashMatchesCSharp(t *testing.T) {
+	src, err := os.ReadFile("../CSharpProject/Proto/Battle.g.cs")
+	if err != nil {
+		t.Skipf("跳过: 找不到 C# 生成代码: %v", err)
+	}
+
+	code := string(src)
+	start := strings.Index(code, "string.Concat(")
+	if start < 0 {
+		t.Fatalf("❌ 找不到 C# 描述符数据")
+	}
+	end := strings.Index(code[start:], "));")
+	if end < 0 {
+		t.Fatalf("❌ C# 描述符数据不完整")
+	}
+
+	var encoded strings.Builder
+	for _, m := range regexp.MustCompile(`"([^"]*)"`).FindAllStringSubmatch(code[start:start+end], -1) {
+		encoded.WriteString(m[1])
+	}
+	data, err := base64.StdEncoding.DecodeString(encoded.String())
+	if err != nil {
+		t.Fatalf("❌ 描述符解码失败: %v", err)
+	}
+
+	sum := sha256.Sum256(data)
+	if csHash := hex.EncodeToString(sum[:]); csHash != SchemaHash() {
+		t.Errorf("❌ schema 哈希不一致: Go=%s, C#=%s", SchemaHash(), csHash)
+	}
+}
+
+// TestCompatibilityErrorDiff 测试不兼容错误列出每一项差异
+func TestCompatibilityErrorDiff(t *testing.T) {
+	expected := ExpectedLibraryInfo()
+
+	err := &CompatibilityError{
+		Path:     "lib/TestExport_Old.so",
+		Expected: expected,
+		Actual:   &proto_pb.LibraryInfo{Version: "old", AbiVersion: 0, SchemaHash: "deadbeef"},
+	}
+	msg := err.Error()
+	for _, want := range []string{"lib/TestExport_Old.so", fmt.Sprintf("ABI 版本: Go=%d, 库=0", AbiVersion), "Go=" + expected.GetSchemaHash() + ", 库=deadbeef"} {
+		if !strings.Contains(msg, want) {
+			t.Errorf("❌ 错误信息缺少 %q:\n%s", want, msg)
+		}
+	}
+
+	// 只有 schema 不一致时不报告 ABI 版本
+	err.Actual = &proto_pb.LibraryInfo{AbiVersion: AbiVersion, SchemaHash: "deadbeef"}
+	if strings.Contains(err.Error(), "ABI 版本") {
+		t.Errorf("❌ ABI 版本一致时不应报告:\n%s", err.Error())
+	}
+
+	// 旧库未导出 GetLibInfo
+	err.Actual = nil
+	if !strings.Contains(err.Error(), "GetLibInfo") {
+		t.Errorf("❌ 应提示缺少 GetLibInfo:\n%s", err.Error())
+	}
+}
+
+// TestSimEngineCapabilities 测试模拟引擎的导出函数和特性查询
+func TestSimEngineCapabilities(t *testing.T) {
+	prev := UseEngine(NewSimEngine())
+	defer UseEngine(prev)
+
+	if !HasExport("ProcessProtoMessage") || !HasExport("ExportBattleState") {
+		t.Errorf("❌ 模拟引擎应提供所有已知导出函数")
+	}
+	if HasExport("NoSuchExport") {
+		t.Errorf("❌ 未知导出函数应返回 false")
+	}
+	if !HasFeature(FeatureHotReload) || HasFeature("no_such_feature") {
+		t.Errorf("❌ 特性查询结果异常")
+	}
+}
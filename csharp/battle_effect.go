@@ -0,0 +1,241 @@
+package csharp
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+
+	proto_pb "goPureWithCsharp/csharp/proto"
+)
+
+// ============================================================================
+// 道具和操作效果 - 由配置表驱动，修改战斗中的属性
+// ============================================================================
+//
+// ability_attribute.json 定义属性 ID，battle_item.json 定义道具 (items) 和用户操作 (operations)
+// 对属性的修改。引擎在 LoadConfig 时解析这两个配置 (C# BattleEffectTable)，应用 BattleUseItem /
+// BattleUserOp 时修改使用者的属性，每个属性变化记录一个回放事件。
+//
+// BattleUseItem.user_id 和 BattleUserOp.char_id 为使用者的单位 ID (见 battle_unit.go)。
+
+// 效果表依赖的配置文件
+const (
+	AbilityAttributeConfig = "ability_attribute.json"
+	BattleItemConfig       = "battle_item.json"
+)
+
+// BattleConfigs 战斗依赖的配置 (效果表和单位表)，按加载顺序排列 (道具表引用属性表中的属性 ID)
+var BattleConfigs = []string{AbilityAttributeConfig, BattleItemConfig, UnitConfig}
+
+// 战斗中生效的属性 ID，与 C# BattleEffectTable.Attribute* 常量对应
+const (
+	AttributeMaxHealth uint32 = 3001 // 最大生命，增加时当前生命同步增加
+	AttributeAttack    uint32 = 3002 // 攻击力，叠加到单位每次攻击的伤害上
+	AttributeHealth    uint32 = 4001 // 当前生命，不超过最大生命
+)
+
+// abilityAttributeSheet ability_attribute.json 中属性表的名称
+const abilityAttributeSheet = "proy.config.ExcelAbilityAttribute"
+
+// AbilityAttribute ability_attribute.json 中的一个属性
+type AbilityAttribute struct {
+	AttributeID uint32 `json:"attribute_id"`
+	Name        string `json:"name"`
+}
+
+// AttributeEffect 对一个属性的修改
+type AttributeEffect struct {
+	AttributeID uint32 `json:"attributeId"`
+	Value       int32  `json:"value"`
+}
+
+// ItemConfig battle_item.json 中的一个道具，每次使用的数量为 1 ~ MaxQuantity
+type ItemConfig struct {
+	ItemID      uint32            `json:"itemId"`
+	Name        string            `json:"name"`
+	MaxQuantity int32             `json:"maxQuantity"`
+	Effects     []AttributeEffect `json:"effects"`
+}
+
+// OperationConfig battle_item.json 中的一个用户操作
+type OperationConfig struct {
+	Operation string            `json:"operation"`
+	Effects   []AttributeEffect `json:"effects"`
+}
+
+// battleItemFile battle_item.json 的格式
+type battleItemFile struct {
+	Version    string             `json:"version"`
+	Items      []*ItemConfig      `json:"items"`
+	Operations []*OperationConfig `json:"operations"`
+}
+
+// EffectTable 道具和操作效果表，对应 C# BattleEffectTable
+// 引擎用它应用输入，调用方也可以用它在提交输入前校验
+type EffectTable struct {
+	Attributes map[uint32]string           // 属性 ID -> 名称
+	Items      map[uint32]*ItemConfig      // 道具 ID -> 道具
+	Operations map[string]*OperationConfig // 操作类型 -> 操作
+}
+
+// NewEffectTable 创建空的效果表，所有道具和操作都无效
+func NewEffectTable() *EffectTable {
+	return &EffectTable{
+		Attributes: make(map[uint32]string),
+		Items:      make(map[uint32]*ItemConfig),
+		Operations: make(map[string]*OperationConfig),
+	}
+}
+
+// Load 解析效果表依赖的配置，configName 不是效果表依赖的配置时忽略
+// 解析失败时效果表保持不变
+func (t *EffectTable) Load(configName string, data []byte) error {
+	switch configName {
+	case AbilityAttributeConfig:
+		return t.loadAttributes(data)
+	case BattleItemConfig:
+		return t.loadItems(data)
+	}
+	return nil
+}
+
+// loadAttributes 解析 ability_attribute.json: [文件头, {表名: [属性...]}, 表名]
+func (t *EffectTable) loadAttributes(data []byte) error {
+	var sections []json.RawMessage
+	if err := json.Unmarshal(data, &sections); err != nil {
+		return fmt.Errorf("解析 %s 失败: %w", AbilityAttributeConfig, err)
+	}
+	for _, section := range sections {
+		var sheet map[string][]AbilityAttribute
+		if json.Unmarshal(section, &sheet) != nil {
+			continue
+		}
+		rows, ok := sheet[abilityAttributeSheet]
+		if !ok {
+			continue
+		}
+		attributes := make(map[uint32]string, len(rows))
+		for _, row := range rows {
+			attributes[row.AttributeID] = row.Name
+		}
+		t.Attributes = attributes
+		return nil
+	}
+	return fmt.Errorf("%s 中没有属性表 %s", AbilityAttributeConfig, abilityAttributeSheet)
+}
+
+// loadItems 解析 battle_item.json，效果引用的属性必须已在属性表中且在战斗中生效
+func (t *EffectTable) loadItems(data []byte) error {
+	var file battleItemFile
+	if err := json.Unmarshal(data, &file); err != nil {
+		return fmt.Errorf("解析 %s 失败: %w", BattleItemConfig, err)
+	}
+
+	items := make(map[uint32]*ItemConfig, len(file.Items))
+	for _, item := range file.Items {
+		if item.ItemID == 0 || items[item.ItemID] != nil {
+			return fmt.Errorf("%s: 道具 ID %d 为 0 或重复", BattleItemConfig, item.ItemID)
+		}
+		if item.MaxQuantity <= 0 {
+			return fmt.Errorf("%s: 道具 %d 的最大数量 %d 无效", BattleItemConfig, item.ItemID, item.MaxQuantity)
+		}
+		if err := t.checkEffects(item.Effects); err != nil {
+			return fmt.Errorf("%s: 道具 %d: %w", BattleItemConfig, item.ItemID, err)
+		}
+		items[item.ItemID] = item
+	}
+
+	operations := make(map[string]*OperationConfig, len(file.Operations))
+	for _, op := range file.Operations {
+		if op.Operation == "" || operations[op.Operation] != nil {
+			return fmt.Errorf("%s: 操作 %q 为空或重复", BattleItemConfig, op.Operation)
+		}
+		if err := t.checkEffects(op.Effects); err != nil {
+			return fmt.Errorf("%s: 操作 %s: %w", BattleItemConfig, op.Operation, err)
+		}
+		operations[op.Operation] = op
+	}
+
+	t.Items = items
+	t.Operations = operations
+	return nil
+}
+
+func (t *EffectTable) checkEffects(effects []AttributeEffect) error {
+	if len(effects) == 0 {
+		return fmt.Errorf("没有效果")
+	}
+	for _, effect := range effects {
+		if _, ok := t.Attributes[effect.AttributeID]; !ok {
+			return fmt.Errorf("属性 %d 不在 %s 中", effect.AttributeID, AbilityAttributeConfig)
+		}
+		switch effect.AttributeID {
+		case AttributeMaxHealth, AttributeAttack, AttributeHealth:
+		default:
+			return fmt.Errorf("属性 %d (%s) 在战斗中不生效", effect.AttributeID, t.Attributes[effect.AttributeID])
+		}
+	}
+	return nil
+}
+
+// Validate 校验道具和用户操作输入: 道具和操作存在、数量在范围内
+// 使用者单位和战斗状态由引擎应用输入时检查；其他输入返回 SUCCESS
+func (t *EffectTable) Validate(input *proto_pb.BattleInput) proto_pb.BattleErrorCode {
+	_, code := t.resolve(input)
+	return code
+}
+
+// inputEffect 一条输入产生的一次属性修改，对应一个回放事件
+type inputEffect struct {
+	user        uint32
+	eventType   string // "item" 或 "skill"
+	attributeID uint32
+	value       int32 // 已乘以数量
+	extra       map[string]string
+}
+
+// resolve 校验道具和用户操作输入并展开为属性修改，对应 C# BattleEffectTable.Resolve
+// 任一道具无效时整条输入被拒绝，不会只应用一部分
+func (t *EffectTable) resolve(input *proto_pb.BattleInput) ([]inputEffect, proto_pb.BattleErrorCode) {
+	var effects []inputEffect
+	switch in := input.GetInput().(type) {
+	case *proto_pb.BattleInput_Use:
+		use := in.Use
+		if len(use.GetItemIds()) == 0 {
+			return nil, proto_pb.BattleErrorCode_INVALID_REQUEST
+		}
+		for _, itemID := range use.GetItemIds() {
+			item, ok := t.Items[itemID]
+			if !ok || use.GetQuantity() < 1 || use.GetQuantity() > item.MaxQuantity {
+				return nil, proto_pb.BattleErrorCode_INVALID_REQUEST
+			}
+			for _, effect := range item.Effects {
+				effects = append(effects, inputEffect{
+					user:        use.GetUserId(),
+					eventType:   "item",
+					attributeID: effect.AttributeID,
+					value:       effect.Value * use.GetQuantity(),
+					extra: map[string]string{
+						"item_id":  strconv.FormatUint(uint64(itemID), 10),
+						"quantity": strconv.Itoa(int(use.GetQuantity())),
+					},
+				})
+			}
+		}
+	case *proto_pb.BattleInput_UserOp:
+		op, ok := t.Operations[in.UserOp.GetOperation()]
+		if !ok {
+			return nil, proto_pb.BattleErrorCode_INVALID_REQUEST
+		}
+		for _, effect := range op.Effects {
+			effects = append(effects, inputEffect{
+				user:        uint32(in.UserOp.GetCharId()),
+				eventType:   "skill",
+				attributeID: effect.AttributeID,
+				value:       effect.Value,
+				extra:       map[string]string{"operation": op.Operation},
+			})
+		}
+	}
+	return effects, proto_pb.BattleErrorCode_SUCCESS
+}
@@ -0,0 +1,211 @@
+package csharp
+
+import (
+	"errors"
+	"testing"
+	"unsafe"
+
+	proto_pb "goPureWithCsharp/csharp/proto"
+
+	"google.golang.org/protobuf/proto"
+)
+
+// loadTestEffects 为引擎注册从 config 目录读取的配置加载器并加载效果配置
+func loadTestEffects(t *testing.T, e Engine) {
+	t.Helper()
+	err := e.RegisterConfigLoader(func(namePtr unsafe.Pointer, nameLen int32, outDataPtrPtr unsafe.Pointer, outDataLenPtr unsafe.Pointer) int32 {
+		data, err := LoadConfigFile(unsafe.String((*byte)(namePtr), int(nameLen)))
+		if err != nil || len(data) == 0 {
+			return -1
+		}
+		*(*unsafe.Pointer)(outDataPtrPtr) = unsafe.Pointer(&data[0])
+		*(*int32)(outDataLenPtr) = int32(len(data))
+		return 0
+	})
+	if err != nil {
+		t.Fatalf("❌ 注册配置加载器失败: %v", err)
+	}
+	for _, name := range BattleConfigs {
+		if err := e.LoadConfig(name); err != nil {
+			t.Fatalf("❌ 加载 %s 失败: %v", name, err)
+		}
+	}
+}
+
+func effectInput(battleID uint32, tick uint64, in *proto_pb.BattleInput) *proto_pb.BattleContext {
+	return &proto_pb.BattleContext{BattleId: battleID, Tick: tick, Option: &proto_pb.BattleContext_BattleInput{BattleInput: in}}
+}
+
+func useItem(userID uint32, quantity int32, itemIDs ...uint32) *proto_pb.BattleInput {
+	return &proto_pb.BattleInput{Input: &proto_pb.BattleInput_Use{Use: &proto_pb.BattleUseItem{ItemIds: itemIDs, UserId: userID, Quantity: quantity}}}
+}
+
+func userOp(charID int32, operation string) *proto_pb.BattleInput {
+	return &proto_pb.BattleInput{Input: &proto_pb.BattleInput_UserOp{UserOp: &proto_pb.BattleUserOp{CharId: charID, Operation: operation}}}
+}
+
+// TestEffectTableLoad 测试解析 config 目录中的效果配置，以及道具、数量和操作的校验
+func TestEffectTableLoad(t *testing.T) {
+	table := NewEffectTable()
+	if code := table.Validate(useItem(100, 1, 5001)); code != proto_pb.BattleErrorCode_INVALID_REQUEST {
+		t.Errorf("❌ 未加载配置时道具应无效, 实际 %s", code)
+	}
+
+	// 道具表引用属性表，必须先加载属性表
+	items, err := LoadConfigFile(BattleItemConfig)
+	if err != nil {
+		t.Fatalf("❌ 读取 %s 失败: %v", BattleItemConfig, err)
+	}
+	if err := table.Load(BattleItemConfig, items); err == nil {
+		t.Errorf("❌ 属性表未加载时道具表应加载失败")
+	}
+	attributes, err := LoadConfigFile(AbilityAttributeConfig)
+	if err != nil {
+		t.Fatalf("❌ 读取 %s 失败: %v", AbilityAttributeConfig, err)
+	}
+	if err := table.Load(AbilityAttributeConfig, attributes); err != nil {
+		t.Fatalf("❌ 加载属性表失败: %v", err)
+	}
+	if err := table.Load(BattleItemConfig, items); err != nil {
+		t.Fatalf("❌ 加载道具表失败: %v", err)
+	}
+	if table.Attributes[AttributeMaxHealth] != "最大生命" || table.Attributes[AttributeAttack] != "攻击力" {
+		t.Errorf("❌ 属性表内容异常: %v", table.Attributes)
+	}
+
+	// 在属性表中但在战斗中不生效的属性
+	bad := []byte(`{"items":[{"itemId":1,"maxQuantity":1,"effects":[{"attributeId":2001,"value":1}]}]}`)
+	if err := table.Load(BattleItemConfig, bad); err == nil {
+		t.Errorf("❌ 引用不生效属性的道具表应加载失败")
+	}
+	if table.Items[5001] == nil {
+		t.Errorf("❌ 加载失败时效果表不应改变")
+	}
+
+	cases := []struct {
+		name  string
+		input *proto_pb.BattleInput
+		code  proto_pb.BattleErrorCode
+	}{
+		{"道具", useItem(100, 3, 5001), proto_pb.BattleErrorCode_SUCCESS},
+		{"多个道具", useItem(100, 1, 5001, 5002), proto_pb.BattleErrorCode_SUCCESS},
+		{"未知道具", useItem(100, 1, 9999), proto_pb.BattleErrorCode_INVALID_REQUEST},
+		{"没有道具", useItem(100, 1), proto_pb.BattleErrorCode_INVALID_REQUEST},
+		{"数量为 0", useItem(100, 0, 5001), proto_pb.BattleErrorCode_INVALID_REQUEST},
+		{"数量超过上限", useItem(100, 4, 5001), proto_pb.BattleErrorCode_INVALID_REQUEST},
+		{"其中一个道具数量超过上限", useItem(100, 2, 5001, 5002), proto_pb.BattleErrorCode_INVALID_REQUEST},
+		{"操作", userOp(100, "defend"), proto_pb.BattleErrorCode_SUCCESS},
+		{"未知操作", userOp(100, "dance"), proto_pb.BattleErrorCode_INVALID_REQUEST},
+		{"生命周期输入", &proto_pb.BattleInput{Input: &proto_pb.BattleInput_Pause{Pause: &proto_pb.BattlePause{}}}, proto_pb.BattleErrorCode_SUCCESS},
+	}
+	for _, c := range cases {
+		if code := table.Validate(c.input); code != c.code {
+			t.Errorf("❌ %s: 期望 %s, 实际 %s", c.name, c.code, code)
+		}
+	}
+}
+
+// TestSimEngineItemEffects 测试道具和用户操作修改属性、记录回放事件，并且复算得到相同的结果
+func TestSimEngineItemEffects(t *testing.T) {
+	engine := NewSimEngine()
+	engine.DisableBattleLogging()
+	defer engine.Close()
+	loadTestEffects(t, engine)
+
+	var result *proto_pb.BattleResult
+	var replay *proto_pb.BattleReplay
+	engine.RegisterBattleEndNotify(func(dataPtr unsafe.Pointer, dataLen int32) int {
+		ctx := &proto_pb.BattleContext{}
+		proto.Unmarshal(unsafe.Slice((*byte)(dataPtr), dataLen), ctx)
+		if r := ctx.GetBattleOutput().GetResult(); r != nil {
+			result = r
+		}
+		if r := ctx.GetBattleOutput().GetReplay(); r != nil {
+			replay = r
+		}
+		return 0
+	})
+
+	env := &proto_pb.BattleEnv{BattleId: 1, Atk: &proto_pb.Team{TeamId: 100}, Def: &proto_pb.Team{TeamId: 101}, Seed: 9}
+	engine.CreateBattle(env)
+	engine.OnTick(1)
+	before, _ := engine.GetBattleStatus(1)
+
+	inputs := []*proto_pb.BattleContext{
+		effectInput(1, 2, useItem(100, 1, 5002)),
+		effectInput(1, 2, useItem(101, 2, 5003)),
+		effectInput(1, 2, userOp(100, "defend")),
+		effectInput(1, 2, useItem(100, 4, 5001)),
+		effectInput(1, 2, useItem(555, 1, 5001)),
+		effectInput(1, 2, userOp(101, "dance")),
+	}
+	codes, _ := engine.ProcessBattleContextBatch(inputs)
+	want := []proto_pb.BattleErrorCode{
+		proto_pb.BattleErrorCode_SUCCESS,
+		proto_pb.BattleErrorCode_SUCCESS,
+		proto_pb.BattleErrorCode_SUCCESS,
+		proto_pb.BattleErrorCode_INVALID_REQUEST,
+		proto_pb.BattleErrorCode_TEAM_NOT_FOUND,
+		proto_pb.BattleErrorCode_INVALID_REQUEST,
+	}
+	for i := range want {
+		if codes[i] != want[i] {
+			t.Errorf("❌ 第 %d 条输入: 期望 %s, 实际 %s", i, want[i], codes[i])
+		}
+	}
+
+	status, _ := engine.GetBattleStatus(1)
+	if status.GetAtkMaxHealth() != 400 || status.GetAtkHealth() != before.GetAtkHealth()+115 ||
+		status.GetDefAttack() != 10 || status.GetDefMaxHealth() != 300 || status.GetAtkAttack() != 0 {
+		t.Errorf("❌ 属性修改异常: %v -> %v", before, status)
+	}
+
+	// 防守方攻击力加成叠加到伤害上
+	engine.OnTick(2)
+	for frame := uint64(3); result == nil && frame < 100; frame++ {
+		engine.OnTick(frame)
+	}
+	if replay == nil {
+		t.Fatalf("❌ 战斗未结束")
+	}
+	var items, skills int
+	for _, evt := range replay.GetEvents() {
+		switch evt.GetEventType() {
+		case "item":
+			items++
+			if evt.GetTimestamp() != 2 || evt.GetExtra()["item_id"] == "" || evt.GetExtra()["attribute_id"] == "" {
+				t.Errorf("❌ 道具事件异常: %v", evt)
+			}
+		case "skill":
+			skills++
+			if evt.GetPerformerId() != 100 || evt.GetValue() != 15 || evt.GetExtra()["operation"] != "defend" || evt.GetExtra()["attribute_id"] != "4001" {
+				t.Errorf("❌ 操作事件异常: %v", evt)
+			}
+		case "attack":
+			if evt.GetPerformerId() == 101 && evt.GetTimestamp() >= 2 && evt.GetValue() < simMinDamage+10 {
+				t.Errorf("❌ 攻击力加成未生效: %v", evt)
+			}
+		}
+	}
+	if items != 2 || skills != 1 {
+		t.Errorf("❌ 期望 2 个道具事件和 1 个操作事件, 实际 %d / %d", items, skills)
+	}
+
+	// 复算引擎加载相同的配置，得到相同的结果
+	resim := NewSimEngine()
+	resim.DisableBattleLogging()
+	defer resim.Close()
+	loadTestEffects(t, resim)
+	rec := &BattleRecord{Env: env, StartFrame: 1, Inputs: inputs}
+	if err := VerifyBattleResult(resim, rec, result); err != nil {
+		t.Errorf("❌ 复算失败: %v", err)
+	}
+
+	// 没有加载效果配置的引擎拒绝全部道具，复算结果不同
+	bare := NewSimEngine()
+	bare.DisableBattleLogging()
+	defer bare.Close()
+	if err := VerifyBattleResult(bare, rec, result); !errors.Is(err, ErrResultMismatch) {
+		t.Errorf("❌ 未加载效果配置的复算应不一致, 实际 %v", err)
+	}
+}
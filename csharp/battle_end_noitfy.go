@@ -4,41 +4,81 @@ import (
 	"fmt"
 	"unsafe"
 
+	proto_pb "goPureWithCsharp/csharp/proto"
+
 	"github.com/ebitengine/purego"
 )
 
+// RegisterNotifyCb 战斗结束通知回调签名，对应 C# BattleResultCallback
+// 参数为序列化的 BattleContext，返回 0 表示成功
 type RegisterNotifyCb func(
 	outDataPtrPtr unsafe.Pointer,
 	DataLen int32) int
 
+// RegisterBattleEndNotify 注册战斗结束通知
+// 与 RegisterBattleResultCallback 注册的是 C# 同一个回调，后注册的生效
 func RegisterBattleEndNotify(fn RegisterNotifyCb) error {
+	e, err := currentEngine()
+	if err != nil {
+		return err
+	}
+	return e.RegisterBattleEndNotify(fn)
+}
+
+func (l *Library) RegisterBattleEndNotify(fn RegisterNotifyCb) error {
 
-	libMutex.RLock()
-	defer libMutex.RUnlock()
+	l.mu.RLock()
+	defer l.mu.RUnlock()
 
-	if libHandle == 0 {
-		return fmt.Errorf("c# 库未初始化")
+	if l.handle == 0 {
+		return ErrNotInitialized
 	}
 
-	// 获取 C# 侧的 RegisterConfigLoader 导出函数
-	rgPtr, err := purego.Dlsym(libHandle, "RegisterBattleResultCallback")
+	return l.registerBattleResult("RegisterBattleEndNotify", fn)
+}
+
+// registerBattleResult 将 fn 绑定到 RegisterBattleResultCallback 的跳板并注册给 C#
+// 已注册过时跳板指针不变，只替换 Go 侧处理函数
+func (l *Library) registerBattleResult(op string, fn RegisterNotifyCb) error {
+	// 获取 C# 侧的 RegisterBattleResultCallback 导出函数
+	rgPtr, err := purego.Dlsym(l.handle, "RegisterBattleResultCallback")
 	if err != nil {
-		return fmt.Errorf("找不到函数: RegisterBattleResultCallback - %w", err)
+		return fmt.Errorf("%w: RegisterBattleResultCallback - %w", ErrExportNotFound, err)
 	}
 
-	// 使用 purego.NewCallback 将 Go 函数转换为 C 可调用的函数指针
-	callbackPtr := purego.NewCallback(fn)
+	callbackPtr, err := bindCallback(l, "RegisterBattleResultCallback", battleResultCallbacks, fn)
+	if err != nil {
+		return err
+	}
 
-	// 调用 C# 的 RegisterConfigLoader，将回调指针传过去
+	// 调用 C# 的 RegisterBattleResultCallback，将回调指针传过去
 	result, _, _ := purego.SyscallN(
 		uintptr(rgPtr),
 		callbackPtr,
 	)
-	// ox 打印 callbackPtr
 	if result != 0 {
-		return fmt.Errorf("RegisterBattleEndNotify 返回错误: %d", result)
+		return exportError(op, int32(result), proto_pb.BattleErrorCode_INVALID_REQUEST)
 	}
-
-	// fmt.Printf("[Go] 战斗结束通知已注册给 C# 回调地址 : %p", callbackPtr)
+	l.saveCallback("RegisterBattleResultCallback", callbackPtr)
 	return nil
 }
+
+// battleResultAdapter 将 BattleResultCallbackFunc 适配为 RegisterNotifyCb
+func battleResultAdapter(fn BattleResultCallbackFunc) RegisterNotifyCb {
+	return func(dataPtr unsafe.Pointer, dataLen int32) int {
+		return int(fn(dataPtr, dataLen))
+	}
+}
+
+// UnregisterBattleEndNotify 注销战斗结束通知 (包括 RegisterBattleResultCallback 注册的回调) 并归还回调跳板
+func UnregisterBattleEndNotify() error {
+	e, err := currentEngine()
+	if err != nil {
+		return err
+	}
+	return e.UnregisterBattleEndNotify()
+}
+
+func (l *Library) UnregisterBattleEndNotify() error {
+	return l.unregisterCallback("RegisterBattleResultCallback")
+}
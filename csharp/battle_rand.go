@@ -0,0 +1,48 @@
+package csharp
+
+import (
+	"math/rand/v2"
+)
+
+// ============================================================================
+// battleRand - 每场战斗独立的确定性随机数生成器
+// ============================================================================
+//
+// SplitMix64，与 C# BattleRandom 算法一致: 相同种子在两侧产生相同的序列，
+// 因此 SimEngine 可以复算 C# 库中进行的战斗。状态只有一个 uint64，随战斗快照迁移。
+
+const splitMix64Gamma = 0x9E3779B97F4A7C15
+
+type battleRand struct {
+	state uint64
+}
+
+func newBattleRand(seed uint64) *battleRand {
+	return &battleRand{state: seed}
+}
+
+// next 返回下一个 64 位随机数
+func (r *battleRand) next() uint64 {
+	r.state += splitMix64Gamma
+	z := r.state
+	z = (z ^ (z >> 30)) * 0xBF58476D1CE4E5B9
+	z = (z ^ (z >> 27)) * 0x94D049BB133111EB
+	return z ^ (z >> 31)
+}
+
+// between 返回 [min, max) 内的随机数，对应 C# BattleRandom.Next(min, max)
+func (r *battleRand) between(min, max int32) int32 {
+	if max <= min {
+		return min
+	}
+	return min + int32(r.next()%uint64(max-min))
+}
+
+// NewBattleSeed 生成新的战斗随机种子，BattleEnv.Seed 为 0 时使用
+func NewBattleSeed() uint64 {
+	for {
+		if seed := rand.Uint64(); seed != 0 {
+			return seed
+		}
+	}
+}
@@ -0,0 +1,131 @@
+package csharp
+
+import (
+	"fmt"
+	"runtime"
+	"unsafe"
+
+	proto_pb "goPureWithCsharp/csharp/proto"
+
+	"github.com/ebitengine/purego"
+	"google.golang.org/protobuf/proto"
+)
+
+// ============================================================================
+// 战斗分片 - 按分片创建和驱动战斗，不同分片的 OnTick 可以并行
+// ============================================================================
+//
+// C# BattleManager 把战斗划分到多个分片，每个分片有自己的锁和输出缓冲区。
+// CreateShardBattle 在指定分片中创建战斗，OnTickShard 只执行该分片中的战斗，
+// 一个分片中的慢战斗不会阻塞其他分片。战斗 ID 在所有分片中唯一，
+// 销毁、输入和状态查询按战斗 ID 找到所在的分片，不需要指定分片。
+//
+// CreateBattle 等价于在分片 0 中创建；OnTick 先处理环形缓冲区中累积的输入，再依次执行所有分片。
+// 环形缓冲区是单生产者单消费者的，多个分片并行 OnTickShard 时不应绑定环形缓冲区。
+//
+// C 签名:
+//
+//	int32_t CreateShardBattle(uint32_t shard_id, const uint8_t* env, int32_t env_len);
+//	返回: 与 CreateBattle 相同
+//	int32_t OnTickShard(uint32_t shard_id, uint64_t frame);
+//	返回: 处理的战斗数量, -3 托管异常
+//
+// 旧版本库不支持 FeatureShards 时，分片 0 退回 CreateBattle / OnTick，其他分片返回 ErrExportNotFound。
+
+// CreateShardBattle 在当前引擎的 shardID 分片中创建战斗
+func CreateShardBattle(shardID uint32, env *proto_pb.BattleEnv) error {
+	e, err := currentEngine()
+	if err != nil {
+		return err
+	}
+	return e.CreateShardBattle(shardID, env)
+}
+
+// OnTickShard 执行当前引擎 shardID 分片中的战斗一个逻辑帧
+func OnTickShard(shardID uint32, frame uint64) (int32, error) {
+	e, err := currentEngine()
+	if err != nil {
+		return -1, err
+	}
+	return e.OnTickShard(shardID, frame)
+}
+
+func (l *Library) CreateShardBattle(shardID uint32, env *proto_pb.BattleEnv) error {
+	if !l.HasFeature(FeatureShards) {
+		if shardID == 0 {
+			return l.CreateBattle(env)
+		}
+		return fmt.Errorf("%w: CreateShardBattle (库不支持 %s)", ErrExportNotFound, FeatureShards)
+	}
+
+	data, err := proto.Marshal(env)
+	if err != nil {
+		return fmt.Errorf("序列化 BattleEnv 失败: %w", err)
+	}
+	if len(data) == 0 {
+		return fmt.Errorf("%w: BattleEnv 为空", ErrInvalidRequest)
+	}
+
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+
+	if l.handle == 0 {
+		return ErrNotInitialized
+	}
+
+	fnPtr, err := l.getCachedFunction("CreateShardBattle")
+	if err != nil {
+		return err
+	}
+
+	// 锁定 OS 线程，失败时在同一线程上取回托管异常
+	runtime.LockOSThread()
+	defer runtime.UnlockOSThread()
+
+	result, _, _ := purego.SyscallN(
+		fnPtr,
+		uintptr(shardID),
+		uintptr(unsafe.Pointer(&data[0])),
+		uintptr(len(data)),
+	)
+
+	if result := int32(result); result != 0 {
+		return l.attachException(exportError("CreateShardBattle", result, createBattleErrorCode(result)))
+	}
+	goLog(LogLevelInfo, "[Go] 战斗已创建: ID=%d, 分片=%d, ATK=%d, DEF=%d, Seed=%d\n",
+		env.GetBattleId(), shardID, env.GetAtk().GetTeamId(), env.GetDef().GetTeamId(), env.GetSeed())
+	return nil
+}
+
+func (l *Library) OnTickShard(shardID uint32, frame uint64) (int32, error) {
+	if !l.HasFeature(FeatureShards) {
+		if shardID == 0 {
+			return l.OnTick(frame)
+		}
+		return -1, fmt.Errorf("%w: OnTickShard (库不支持 %s)", ErrExportNotFound, FeatureShards)
+	}
+
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+
+	if l.handle == 0 {
+		return -1, ErrNotInitialized
+	}
+
+	fnPtr, err := l.getCachedFunction("OnTickShard")
+	if err != nil {
+		return -1, err
+	}
+
+	// 锁定 OS 线程，失败时在同一线程上取回托管异常
+	runtime.LockOSThread()
+	defer runtime.UnlockOSThread()
+
+	result, _, _ := purego.SyscallN(fnPtr, uintptr(shardID), uintptr(frame))
+
+	processed := int32(result)
+	if processed < 0 {
+		return -1, l.attachException(exportError("OnTickShard", processed, proto_pb.BattleErrorCode_INTERNAL_ERROR))
+	}
+	return processed, nil
+}
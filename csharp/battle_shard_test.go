@@ -0,0 +1,76 @@
+package csharp
+
+import (
+	"errors"
+	"testing"
+)
+
+// TestSimEngineShards 测试 OnTickShard 只执行该分片的战斗、战斗 ID 在所有分片中唯一，
+// 以及分片经过状态查询和快照保持不变
+func TestSimEngineShards(t *testing.T) {
+	engine := NewSimEngine()
+	engine.DisableBattleLogging()
+	defer engine.Close()
+
+	if !engine.HasFeature(FeatureShards) {
+		t.Fatalf("❌ 模拟引擎应支持 %s", FeatureShards)
+	}
+
+	for battleID, shard := range map[uint32]uint32{1: 0, 2: 1, 3: 1, 4: 2} {
+		if err := engine.CreateShardBattle(shard, teamEnv(battleID, 100, 101, uint64(battleID))); err != nil {
+			t.Fatalf("❌ 在分片 %d 创建战斗 %d 失败: %v", shard, battleID, err)
+		}
+	}
+	if err := engine.CreateShardBattle(2, teamEnv(2, 100, 101, 1)); !errors.Is(err, ErrDuplicateBattle) {
+		t.Errorf("❌ 其他分片中的战斗 ID 应冲突, 实际 %v", err)
+	}
+
+	rounds := func() map[uint32]int32 {
+		list, _ := engine.ListBattles()
+		m := make(map[uint32]int32, len(list))
+		for _, status := range list {
+			m[status.GetBattleId()] = status.GetRound()
+		}
+		return m
+	}
+
+	if n, err := engine.OnTickShard(1, 1); err != nil || n != 2 {
+		t.Fatalf("❌ 分片 1 应执行 2 场战斗, 实际 %d (%v)", n, err)
+	}
+	if got := rounds(); got[1] != 0 || got[2] != 1 || got[3] != 1 || got[4] != 0 {
+		t.Errorf("❌ 只有分片 1 的战斗应执行回合: %v", got)
+	}
+	if n, _ := engine.OnTickShard(7, 2); n != 0 {
+		t.Errorf("❌ 空分片不应执行战斗, 实际 %d", n)
+	}
+
+	// OnTick 执行所有分片
+	if n, _ := engine.OnTick(2); n != 4 {
+		t.Errorf("❌ OnTick 应执行全部 4 场战斗, 实际 %d", n)
+	}
+	if got := rounds(); got[1] != 1 || got[2] != 2 || got[4] != 1 {
+		t.Errorf("❌ OnTick 后回合异常: %v", got)
+	}
+
+	status, _ := engine.GetBattleStatus(4)
+	if status.GetShardId() != 2 {
+		t.Errorf("❌ 战斗 4 应在分片 2, 实际 %d", status.GetShardId())
+	}
+
+	snapshot, err := engine.ExportBattleState()
+	if err != nil {
+		t.Fatalf("❌ 导出失败: %v", err)
+	}
+	dst := NewSimEngine()
+	dst.DisableBattleLogging()
+	defer dst.Close()
+	if err := dst.ImportBattleState(snapshot); err != nil {
+		t.Fatalf("❌ 导入失败: %v", err)
+	}
+	if n, _ := dst.OnTickShard(1, 3); n != 2 {
+		t.Errorf("❌ 导入后分片 1 应有 2 场战斗, 实际 %d", n)
+	}
+	if status, _ := dst.GetBattleStatus(3); status.GetShardId() != 1 || status.GetRound() != 3 {
+		t.Errorf("❌ 导入后战斗 3 的分片或回合异常: %v", status)
+	}
+}
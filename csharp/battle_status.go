@@ -0,0 +1,101 @@
+package csharp
+
+import (
+	"fmt"
+	"unsafe"
+
+	proto_pb "goPureWithCsharp/csharp/proto"
+
+	"github.com/ebitengine/purego"
+	"google.golang.org/protobuf/proto"
+)
+
+// ============================================================================
+// 战斗状态查询 - 读取存活战斗的回合、血量和状态
+// ============================================================================
+//
+// C# 导出 ListBattleStatus(battle_id, buffer, buffer_len) 按响应缓冲区协商返回
+// BattleStatusList，battle_id 为 0 时包含所有战斗。旧版本库没有该导出时返回 ErrExportNotFound。
+
+// BattleStatus.State 取值，与 C# BattleInstance.State* 常量对应
+//
+//	running ⇄ paused (Pause / Resume)，running/paused → finished (分出胜负或 End)，任意状态 → destroyed (Destroy)
+const (
+	BattleStateRunning   = "running"
+	BattleStatePaused    = "paused"
+	BattleStateFinished  = "finished"
+	BattleStateDestroyed = "destroyed"
+)
+
+const battleStatusBufferSize = 4096 // ListBattleStatus 初始缓冲区
+
+// GetBattleStatus 查询当前引擎中一场战斗的状态，战斗不存在时返回 ErrBattleNotFound
+func GetBattleStatus(battleID uint32) (*proto_pb.BattleStatus, error) {
+	e, err := currentEngine()
+	if err != nil {
+		return nil, err
+	}
+	return e.GetBattleStatus(battleID)
+}
+
+// ListBattles 查询当前引擎中所有战斗的状态
+func ListBattles() ([]*proto_pb.BattleStatus, error) {
+	e, err := currentEngine()
+	if err != nil {
+		return nil, err
+	}
+	return e.ListBattles()
+}
+
+func (l *Library) GetBattleStatus(battleID uint32) (*proto_pb.BattleStatus, error) {
+	if battleID == 0 {
+		return nil, exportError("GetBattleStatus", -1, proto_pb.BattleErrorCode_BATTLE_NOT_FOUND)
+	}
+	list, err := l.listBattleStatus(battleID)
+	if err != nil {
+		return nil, err
+	}
+	if len(list) == 0 {
+		return nil, exportError("GetBattleStatus", -1, proto_pb.BattleErrorCode_BATTLE_NOT_FOUND)
+	}
+	return list[0], nil
+}
+
+func (l *Library) ListBattles() ([]*proto_pb.BattleStatus, error) {
+	return l.listBattleStatus(0)
+}
+
+// listBattleStatus 调用 ListBattleStatus 导出，battleID 为 0 时返回所有战斗
+func (l *Library) listBattleStatus(battleID uint32) ([]*proto_pb.BattleStatus, error) {
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+
+	if l.handle == 0 {
+		return nil, ErrNotInitialized
+	}
+
+	fnPtr, err := l.getCachedFunction("ListBattleStatus")
+	if err != nil {
+		return nil, err
+	}
+
+	data, err := negotiateResponse("ListBattleStatus", battleStatusBufferSize, func(buf []byte) int32 {
+		bufLen := int32(len(buf))
+		purego.SyscallN(
+			fnPtr,
+			uintptr(battleID),
+			uintptr(unsafe.Pointer(&buf[0])),
+			uintptr(unsafe.Pointer(&bufLen)),
+		)
+		return bufLen
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	list := &proto_pb.BattleStatusList{}
+	if err := proto.Unmarshal(data, list); err != nil {
+		return nil, fmt.Errorf("战斗状态反序列化失败: %w", err)
+	}
+	return list.GetBattles(), nil
+}
@@ -0,0 +1,219 @@
+package csharp
+
+import (
+	"encoding/json"
+	"fmt"
+
+	proto_pb "goPureWithCsharp/csharp/proto"
+
+	"google.golang.org/protobuf/proto"
+)
+
+// ============================================================================
+// 单位模型 - 按 Team.lineup 创建单位，逐个单位攻击
+// ============================================================================
+//
+// 每个单位的初始属性为 unit_config.json 的默认值 (defaultHealth / defaultAttack / defaultDefense)，
+// units 中按单位 ID 配置的属性 (3001 最大生命、3002 攻击力) 覆盖默认值。
+// 阵容为空的队伍整个作为一个单位 (单位 ID 为队伍 ID，300 血、无攻击和防御加成)，与旧版本的战斗一致。
+// 算法与 C# UnitTable / BattleInstance 一致，SimEngine 可以复算 C# 库中进行的战斗。
+
+// UnitConfig 单位配置文件
+const UnitConfig = "unit_config.json"
+
+// 未加载 unit_config.json 时的单位默认属性，与 config/unit_config.json 一致
+const (
+	defaultMaxUnits    = 100
+	defaultUnitHealth  = 100
+	defaultUnitAttack  = 10
+	defaultUnitDefense = 5
+)
+
+// UnitTemplate unit_config.json 中按单位 ID 配置的属性
+type UnitTemplate struct {
+	UnitID     uint32            `json:"unitId"`
+	Name       string            `json:"name"`
+	Attributes []AttributeEffect `json:"attributes"`
+}
+
+// unitConfigFile unit_config.json 的格式
+type unitConfigFile struct {
+	Version    string `json:"version"`
+	UnitConfig struct {
+		MaxUnits       int32 `json:"maxUnits"`
+		DefaultHealth  int32 `json:"defaultHealth"`
+		DefaultAttack  int32 `json:"defaultAttack"`
+		DefaultDefense int32 `json:"defaultDefense"`
+	} `json:"unitConfig"`
+	Units []*UnitTemplate `json:"units"`
+}
+
+// UnitTable 单位初始属性表，对应 C# UnitTable
+type UnitTable struct {
+	MaxUnits       int32 // 一场战斗最多的单位数
+	DefaultHealth  int32
+	DefaultAttack  int32
+	DefaultDefense int32
+	Units          map[uint32]*UnitTemplate // 单位 ID -> 配置的属性
+}
+
+// NewUnitTable 创建只有默认属性的单位表
+func NewUnitTable() *UnitTable {
+	return &UnitTable{
+		MaxUnits:       defaultMaxUnits,
+		DefaultHealth:  defaultUnitHealth,
+		DefaultAttack:  defaultUnitAttack,
+		DefaultDefense: defaultUnitDefense,
+		Units:          make(map[uint32]*UnitTemplate),
+	}
+}
+
+// Load 解析 unit_config.json，其他配置忽略；解析失败时单位表保持不变
+func (t *UnitTable) Load(configName string, data []byte) error {
+	if configName != UnitConfig {
+		return nil
+	}
+
+	var file unitConfigFile
+	if err := json.Unmarshal(data, &file); err != nil {
+		return fmt.Errorf("解析 %s 失败: %w", UnitConfig, err)
+	}
+	cfg := file.UnitConfig
+	if cfg.MaxUnits <= 0 || cfg.DefaultHealth <= 0 || cfg.DefaultAttack < 0 || cfg.DefaultDefense < 0 {
+		return fmt.Errorf("%s: 默认属性无效: %+v", UnitConfig, cfg)
+	}
+
+	units := make(map[uint32]*UnitTemplate, len(file.Units))
+	for _, unit := range file.Units {
+		if unit.UnitID == 0 || units[unit.UnitID] != nil {
+			return fmt.Errorf("%s: 单位 ID %d 为 0 或重复", UnitConfig, unit.UnitID)
+		}
+		for _, attr := range unit.Attributes {
+			switch {
+			case attr.AttributeID == AttributeMaxHealth && attr.Value > 0:
+			case attr.AttributeID == AttributeAttack && attr.Value >= 0:
+			default:
+				return fmt.Errorf("%s: 单位 %d 的属性 %d = %d 无效 (只能配置 3001 最大生命和 3002 攻击力)",
+					UnitConfig, unit.UnitID, attr.AttributeID, attr.Value)
+			}
+		}
+		units[unit.UnitID] = unit
+	}
+
+	t.MaxUnits = cfg.MaxUnits
+	t.DefaultHealth = cfg.DefaultHealth
+	t.DefaultAttack = cfg.DefaultAttack
+	t.DefaultDefense = cfg.DefaultDefense
+	t.Units = units
+	return nil
+}
+
+// newUnits 按双方阵容创建单位，攻击方在前，对应 C# UnitTable.CreateUnits
+// 单位 ID 为 0 或在同一场战斗中重复时返回 INVALID_REQUEST，单位总数超过 MaxUnits 时返回 INVALID_TEAM_SIZE
+func (t *UnitTable) newUnits(atk, def *proto_pb.Team) ([]*proto_pb.BattleUnit, proto_pb.BattleErrorCode) {
+	if len(atk.GetLineup())+len(def.GetLineup()) > int(t.MaxUnits) {
+		return nil, proto_pb.BattleErrorCode_INVALID_TEAM_SIZE
+	}
+
+	var units []*proto_pb.BattleUnit
+	seen := make(map[uint32]bool)
+	for _, team := range []*proto_pb.Team{atk, def} {
+		if len(team.GetLineup()) == 0 {
+			// 整个队伍作为一个单位
+			if seen[team.GetTeamId()] {
+				return nil, proto_pb.BattleErrorCode_INVALID_REQUEST
+			}
+			seen[team.GetTeamId()] = true
+			units = append(units, teamUnit(team.GetTeamId(), simInitialHealth, simInitialHealth, 0))
+			continue
+		}
+		for _, unitID := range team.GetLineup() {
+			if unitID == 0 || seen[unitID] {
+				return nil, proto_pb.BattleErrorCode_INVALID_REQUEST
+			}
+			seen[unitID] = true
+			units = append(units, t.newUnit(unitID, team.GetTeamId()))
+		}
+	}
+	return units, proto_pb.BattleErrorCode_SUCCESS
+}
+
+// newUnit 以默认属性创建单位，再应用按单位 ID 配置的属性
+func (t *UnitTable) newUnit(unitID, teamID uint32) *proto_pb.BattleUnit {
+	unit := &proto_pb.BattleUnit{
+		UnitId:    unitID,
+		TeamId:    teamID,
+		MaxHealth: t.DefaultHealth,
+		Attack:    t.DefaultAttack,
+		Defense:   t.DefaultDefense,
+	}
+	if tmpl, ok := t.Units[unitID]; ok {
+		for _, attr := range tmpl.Attributes {
+			switch attr.AttributeID {
+			case AttributeMaxHealth:
+				unit.MaxHealth = attr.Value
+			case AttributeAttack:
+				unit.Attack = attr.Value
+			}
+		}
+	}
+	unit.Health = unit.MaxHealth
+	return unit
+}
+
+// teamUnit 整个队伍作为一个单位，单位 ID 为队伍 ID
+func teamUnit(teamID uint32, health, maxHealth, attack int32) *proto_pb.BattleUnit {
+	return &proto_pb.BattleUnit{UnitId: teamID, TeamId: teamID, MaxHealth: maxHealth, Health: health, Attack: attack}
+}
+
+// unitDamage 一次攻击的伤害: 随机伤害 + 攻击力 - 目标防御力，至少为 1
+func unitDamage(roll int32, attacker, target *proto_pb.BattleUnit) int32 {
+	return max(roll+attacker.GetAttack()-target.GetDefense(), 1)
+}
+
+// pickTarget 选择 teamID 一方的攻击目标: 对方存活单位中生命最低的，相同时取阵容中靠前的
+// 对方没有存活单位时返回 nil，对应 C# BattleInstance.PickTarget
+func (b *simBattle) pickTarget(teamID uint32) *proto_pb.BattleUnit {
+	var target *proto_pb.BattleUnit
+	for _, unit := range b.units {
+		if unit.GetTeamId() == teamID || unit.GetHealth() <= 0 {
+			continue
+		}
+		if target == nil || unit.GetHealth() < target.GetHealth() {
+			target = unit
+		}
+	}
+	return target
+}
+
+// findUnit 按单位 ID 查找单位
+func (b *simBattle) findUnit(unitID uint32) *proto_pb.BattleUnit {
+	for _, unit := range b.units {
+		if unit.GetUnitId() == unitID {
+			return unit
+		}
+	}
+	return nil
+}
+
+// teamStats 一方存活单位的生命之和、最大生命之和与攻击力之和
+func (b *simBattle) teamStats(teamID uint32) (health, maxHealth, attack int32) {
+	for _, unit := range b.units {
+		if unit.GetTeamId() != teamID {
+			continue
+		}
+		health += max(unit.GetHealth(), 0)
+		maxHealth += unit.GetMaxHealth()
+		attack += unit.GetAttack()
+	}
+	return health, maxHealth, attack
+}
+
+// cloneUnits 复制单位状态 (快照和状态查询)
+func cloneUnits(units []*proto_pb.BattleUnit) []*proto_pb.BattleUnit {
+	cloned := make([]*proto_pb.BattleUnit, len(units))
+	for i, unit := range units {
+		cloned[i] = proto.Clone(unit).(*proto_pb.BattleUnit)
+	}
+	return cloned
+}
@@ -0,0 +1,225 @@
+package csharp
+
+import (
+	"errors"
+	"slices"
+	"testing"
+	"unsafe"
+
+	proto_pb "goPureWithCsharp/csharp/proto"
+
+	"google.golang.org/protobuf/proto"
+)
+
+// TestUnitTableLoad 测试解析 unit_config.json，以及按阵容创建单位的校验
+func TestUnitTableLoad(t *testing.T) {
+	table := NewUnitTable()
+	data, err := LoadConfigFile(UnitConfig)
+	if err != nil {
+		t.Fatalf("❌ 读取 %s 失败: %v", UnitConfig, err)
+	}
+	if err := table.Load(UnitConfig, data); err != nil {
+		t.Fatalf("❌ 加载单位表失败: %v", err)
+	}
+
+	bad := []byte(`{"unitConfig":{"maxUnits":10,"defaultHealth":100},"units":[{"unitId":1,"attributes":[{"attributeId":4001,"value":1}]}]}`)
+	if err := table.Load(UnitConfig, bad); err == nil {
+		t.Errorf("❌ 配置不可配置属性的单位表应加载失败")
+	}
+	if table.MaxUnits != 100 || table.Units[1001] == nil {
+		t.Errorf("❌ 加载失败时单位表不应改变")
+	}
+
+	atk := &proto_pb.Team{TeamId: 100, Lineup: []uint32{1001, 7}}
+	units, code := table.newUnits(atk, &proto_pb.Team{TeamId: 101})
+	if code != proto_pb.BattleErrorCode_SUCCESS || len(units) != 3 {
+		t.Fatalf("❌ 创建单位失败: %s, %v", code, units)
+	}
+	want := []*proto_pb.BattleUnit{
+		{UnitId: 1001, TeamId: 100, MaxHealth: 150, Health: 150, Attack: 12, Defense: 5},
+		{UnitId: 7, TeamId: 100, MaxHealth: 100, Health: 100, Attack: 10, Defense: 5},
+		{UnitId: 101, TeamId: 101, MaxHealth: simInitialHealth, Health: simInitialHealth},
+	}
+	for i := range want {
+		if !proto.Equal(units[i], want[i]) {
+			t.Errorf("❌ 第 %d 个单位: 期望 %v, 实际 %v", i, want[i], units[i])
+		}
+	}
+
+	cases := []struct {
+		name     string
+		atk, def *proto_pb.Team
+		code     proto_pb.BattleErrorCode
+	}{
+		{"单位 ID 重复", atk, &proto_pb.Team{TeamId: 101, Lineup: []uint32{7}}, proto_pb.BattleErrorCode_INVALID_REQUEST},
+		{"单位 ID 与队伍单位重复", atk, &proto_pb.Team{TeamId: 7}, proto_pb.BattleErrorCode_INVALID_REQUEST},
+		{"单位 ID 为 0", &proto_pb.Team{TeamId: 100, Lineup: []uint32{0}}, &proto_pb.Team{TeamId: 101}, proto_pb.BattleErrorCode_INVALID_REQUEST},
+		{"单位过多", &proto_pb.Team{TeamId: 100, Lineup: make([]uint32, 101)}, &proto_pb.Team{TeamId: 101}, proto_pb.BattleErrorCode_INVALID_TEAM_SIZE},
+	}
+	for _, c := range cases {
+		if _, code := table.newUnits(c.atk, c.def); code != c.code {
+			t.Errorf("❌ %s: 期望 %s, 实际 %s", c.name, c.code, code)
+		}
+	}
+}
+
+// TestSimEngineUnitBattle 测试按阵容逐个单位战斗: 状态中的单位、以单位为目标的道具、
+// 以被击败单位为击杀列表的结果，并且复算得到相同的结果
+func TestSimEngineUnitBattle(t *testing.T) {
+	engine := NewSimEngine()
+	engine.DisableBattleLogging()
+	defer engine.Close()
+	loadTestEffects(t, engine)
+
+	var result *proto_pb.BattleResult
+	var replay *proto_pb.BattleReplay
+	engine.RegisterBattleEndNotify(func(dataPtr unsafe.Pointer, dataLen int32) int {
+		ctx := &proto_pb.BattleContext{}
+		proto.Unmarshal(unsafe.Slice((*byte)(dataPtr), dataLen), ctx)
+		if r := ctx.GetBattleOutput().GetResult(); r != nil {
+			result = r
+		}
+		if r := ctx.GetBattleOutput().GetReplay(); r != nil {
+			replay = r
+		}
+		return 0
+	})
+
+	for _, c := range []struct {
+		name string
+		env  *proto_pb.BattleEnv
+		err  error
+	}{
+		{"缺少防守方", &proto_pb.BattleEnv{BattleId: 2, Atk: &proto_pb.Team{TeamId: 100}}, ErrTeamNotFound},
+		{"队伍 ID 相同", teamEnv(2, 100, 100, 0), ErrInvalidRequest},
+		{"阵容过大", &proto_pb.BattleEnv{BattleId: 2, Atk: &proto_pb.Team{TeamId: 100, Lineup: make([]uint32, 200)}, Def: &proto_pb.Team{TeamId: 101}}, ErrInvalidTeamSize},
+	} {
+		if err := engine.CreateBattle(c.env); !errors.Is(err, c.err) {
+			t.Errorf("❌ %s: 期望 %v, 实际 %v", c.name, c.err, err)
+		}
+	}
+
+	env := &proto_pb.BattleEnv{
+		BattleId: 1,
+		Atk:      &proto_pb.Team{TeamId: 100, Lineup: []uint32{1001, 1002}},
+		Def:      &proto_pb.Team{TeamId: 101, Lineup: []uint32{2001, 2002, 2003}},
+		Seed:     5,
+	}
+	if err := engine.CreateBattle(env); err != nil {
+		t.Fatalf("❌ 创建战斗失败: %v", err)
+	}
+	status, _ := engine.GetBattleStatus(1)
+	if len(status.GetUnits()) != 5 || status.GetAtkMaxHealth() != 230 || status.GetDefMaxHealth() != 300 || status.GetAtkAttack() != 30 {
+		t.Errorf("❌ 初始状态异常: %v", status)
+	}
+
+	inputs := []*proto_pb.BattleContext{
+		effectInput(1, 1, useItem(1002, 1, 5002)),
+		effectInput(1, 1, useItem(101, 1, 5001)),
+	}
+	codes, _ := engine.ProcessBattleContextBatch(inputs)
+	if codes[0] != proto_pb.BattleErrorCode_SUCCESS || codes[1] != proto_pb.BattleErrorCode_TEAM_NOT_FOUND {
+		t.Errorf("❌ 道具使用者应为单位 ID: %v", codes)
+	}
+	status, _ = engine.GetBattleStatus(1)
+	if u := status.GetUnits()[1]; u.GetUnitId() != 1002 || u.GetMaxHealth() != 180 || u.GetHealth() != 180 {
+		t.Errorf("❌ 道具应只修改单位 1002: %v", u)
+	}
+
+	for frame := uint64(1); result == nil && frame < 100; frame++ {
+		engine.OnTick(frame)
+	}
+	if result == nil || replay == nil {
+		t.Fatalf("❌ 战斗未结束")
+	}
+
+	// 击杀列表为败方全部单位，与 killed 攻击事件一致
+	loserUnits := map[uint32][]uint32{100: env.GetAtk().GetLineup(), 101: env.GetDef().GetLineup()}[result.GetLoser()]
+	killed := slices.Clone(result.GetKills())
+	slices.Sort(killed)
+	for _, id := range loserUnits {
+		if _, ok := slices.BinarySearch(killed, id); !ok {
+			t.Errorf("❌ 败方单位 %d 不在击杀列表 %v 中", id, result.GetKills())
+		}
+	}
+	var attacks int
+	var damage int32
+	for _, evt := range replay.GetEvents() {
+		if evt.GetEventType() != "attack" {
+			continue
+		}
+		attacks++
+		if evt.GetPerformerId() < 1000 || evt.GetTargetId() < 1000 {
+			t.Errorf("❌ 攻击事件应为单位之间: %v", evt)
+		}
+		damage += evt.GetValue()
+	}
+	if attacks == 0 || damage != result.GetAtkDamage()+result.GetDefDamage() {
+		t.Errorf("❌ 伤害统计异常: %d 次攻击共 %d, 结果 %v", attacks, damage, result)
+	}
+	if len(replay.GetAtkTeam().GetLineup()) != 2 || len(replay.GetDefTeam().GetLineup()) != 3 {
+		t.Errorf("❌ 回放应包含双方阵容: %v / %v", replay.GetAtkTeam(), replay.GetDefTeam())
+	}
+
+	// 被击败的单位不能再使用道具
+	b := &simBattle{units: []*proto_pb.BattleUnit{{UnitId: 1, TeamId: 100, MaxHealth: 10}}}
+	heal := []inputEffect{{user: 1, eventType: "item", attributeID: AttributeHealth, value: 5}}
+	if code := b.applyEffects(1, heal); code != proto_pb.BattleErrorCode_INVALID_REQUEST || b.units[0].GetHealth() != 0 {
+		t.Errorf("❌ 被击败的单位使用道具应被拒绝: %s, %v", code, b.units[0])
+	}
+
+	resim := NewSimEngine()
+	resim.DisableBattleLogging()
+	defer resim.Close()
+	loadTestEffects(t, resim)
+	rec := &BattleRecord{Env: env, StartFrame: 1, Inputs: inputs}
+	if err := VerifyBattleResult(resim, rec, result); err != nil {
+		t.Errorf("❌ 复算失败: %v", err)
+	}
+}
+
+// TestSimEngineUnitSnapshot 测试单位状态经过导出和导入保持不变，旧版本快照恢复为每队一个单位
+func TestSimEngineUnitSnapshot(t *testing.T) {
+	src := NewSimEngine()
+	src.DisableBattleLogging()
+	defer src.Close()
+
+	env := &proto_pb.BattleEnv{
+		BattleId: 1,
+		Atk:      &proto_pb.Team{TeamId: 100, Lineup: []uint32{1, 2}},
+		Def:      &proto_pb.Team{TeamId: 101},
+		Seed:     3,
+	}
+	src.CreateBattle(env)
+	src.OnTick(1)
+	snapshot, err := src.ExportBattleState()
+	if err != nil {
+		t.Fatalf("❌ 导出失败: %v", err)
+	}
+
+	dst := NewSimEngine()
+	dst.DisableBattleLogging()
+	defer dst.Close()
+	if err := dst.ImportBattleState(snapshot); err != nil {
+		t.Fatalf("❌ 导入失败: %v", err)
+	}
+	want, _ := src.GetBattleStatus(1)
+	got, _ := dst.GetBattleStatus(1)
+	if !slices.EqualFunc(want.GetUnits(), got.GetUnits(), func(a, b *proto_pb.BattleUnit) bool { return proto.Equal(a, b) }) {
+		t.Errorf("❌ 导入后单位不同: %v -> %v", want.GetUnits(), got.GetUnits())
+	}
+
+	// 旧版本快照只有双方血量
+	legacy := &proto_pb.BattleStateSnapshot{Battles: []*proto_pb.BattleSnapshot{
+		{BattleId: 2, AtkTeamId: 200, DefTeamId: 201, AtkHealth: 50, DefHealth: 80, State: BattleStateRunning},
+	}}
+	if err := dst.ImportBattleState(legacy); err != nil {
+		t.Fatalf("❌ 导入旧版本快照失败: %v", err)
+	}
+	status, _ := dst.GetBattleStatus(2)
+	units := status.GetUnits()
+	if len(units) != 2 || units[0].GetUnitId() != 200 || units[0].GetHealth() != 50 ||
+		units[1].GetUnitId() != 201 || units[1].GetMaxHealth() != simInitialHealth {
+		t.Errorf("❌ 旧版本快照应恢复为每队一个单位: %v", units)
+	}
+}
@@ -0,0 +1,221 @@
+package csharp
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"unsafe"
+
+	"github.com/ebitengine/purego"
+)
+
+// ============================================================================
+// 回调注册表 - 可复用的 purego 回调跳板
+// ============================================================================
+//
+// purego.NewCallback 生成的函数指针在进程内永不释放，且总数有硬上限 (约 2000)。
+// 每次注册都调用 NewCallback 会在反复注册、热重载和测试中耗尽回调槽位。
+//
+// 注册表按签名维护跳板池:
+//   - 跳板是固定的 C 函数指针，调用时分发到可替换的 Go 处理函数
+//   - 每个 Library 对每个注册导出函数持有一个跳板，重新注册只替换处理函数
+//   - 注销或关闭库时跳板归还到池中，之后的注册复用它
+//   - 热重载时新库接管旧库的跳板，C# 侧拿到的仍是同一个函数指针
+
+const maxCallbackSlots = 256 // 注册表最多创建的跳板数量
+
+// ErrCallbackLimit 跳板数量达到上限
+var ErrCallbackLimit = errors.New("回调跳板数量已达上限")
+
+var callbackSlotsCreated atomic.Int32
+
+// callbackSlot 一个 C 可调用的跳板，分发到当前的 Go 处理函数
+type callbackSlot[F any] struct {
+	ptr     uintptr           // purego.NewCallback 生成的函数指针
+	handler atomic.Pointer[F] // 当前处理函数，nil 表示已注销
+	pool    *callbackPool[F]
+}
+
+// load 返回当前处理函数
+func (s *callbackSlot[F]) load() (F, bool) {
+	if fn := s.handler.Load(); fn != nil {
+		return *fn, true
+	}
+	var zero F
+	return zero, false
+}
+
+// release 清空处理函数并归还到池中
+func (s *callbackSlot[F]) release() {
+	s.handler.Store(nil)
+	s.pool.put(s)
+}
+
+// callbackPool 同一签名的跳板池
+type callbackPool[F any] struct {
+	name string
+
+	// trampoline 为跳板生成传给 purego.NewCallback 的函数
+	// 处理函数已注销时应返回失败值，而不是调用空函数
+	trampoline func(slot *callbackSlot[F]) any
+
+	mu   sync.Mutex
+	free []*callbackSlot[F]
+}
+
+// acquire 取出空闲跳板并设置处理函数，没有空闲跳板时创建新的
+func (p *callbackPool[F]) acquire(fn F) (*callbackSlot[F], error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	var slot *callbackSlot[F]
+	if n := len(p.free); n > 0 {
+		slot = p.free[n-1]
+		p.free = p.free[:n-1]
+	} else {
+		if callbackSlotsCreated.Add(1) > maxCallbackSlots {
+			callbackSlotsCreated.Add(-1)
+			return nil, fmt.Errorf("%w: %s (%d)", ErrCallbackLimit, p.name, maxCallbackSlots)
+		}
+		slot = &callbackSlot[F]{pool: p}
+		slot.ptr = purego.NewCallback(p.trampoline(slot))
+	}
+	slot.handler.Store(&fn)
+	return slot, nil
+}
+
+// put 归还跳板
+func (p *callbackPool[F]) put(slot *callbackSlot[F]) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	p.free = append(p.free, slot)
+}
+
+// idle 池中空闲跳板数量
+func (p *callbackPool[F]) idle() int {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	return len(p.free)
+}
+
+// ============================================================================
+// 跳板池
+// ============================================================================
+
+// configLoaderCallbacks RegisterConfigLoader 的跳板池
+var configLoaderCallbacks = &callbackPool[RegisterConfigLoaderFunc]{
+	name: "RegisterConfigLoader",
+	trampoline: func(slot *callbackSlot[RegisterConfigLoaderFunc]) any {
+		return func(configNamePtr unsafe.Pointer, configNameLen int32, outDataPtrPtr unsafe.Pointer, outDataLenPtr unsafe.Pointer) int32 {
+			fn, ok := slot.load()
+			if !ok {
+				return -1
+			}
+			return fn(configNamePtr, configNameLen, outDataPtrPtr, outDataLenPtr)
+		}
+	},
+}
+
+// battleResultCallbacks RegisterBattleResultCallback 的跳板池
+// RegisterBattleEndNotify 与 RegisterBattleResultCallback 注册的是 C# 同一个回调
+var battleResultCallbacks = &callbackPool[RegisterNotifyCb]{
+	name: "RegisterBattleResultCallback",
+	trampoline: func(slot *callbackSlot[RegisterNotifyCb]) any {
+		return func(dataPtr unsafe.Pointer, dataLen int32) int {
+			fn, ok := slot.load()
+			if !ok {
+				return -1
+			}
+			return fn(dataPtr, dataLen)
+		}
+	},
+}
+
+// ============================================================================
+// Library 回调跳板
+// ============================================================================
+
+// releasableCallback Library 持有的跳板
+type releasableCallback interface {
+	release()
+}
+
+// bindCallback 为 l 的注册导出函数 export 设置处理函数，返回传给 C# 的函数指针
+// 已持有跳板时只替换处理函数，指针不变
+func bindCallback[F any](l *Library, export string, pool *callbackPool[F], fn F) (uintptr, error) {
+	l.callbackMu.Lock()
+	defer l.callbackMu.Unlock()
+
+	if slot, ok := l.slots[export].(*callbackSlot[F]); ok {
+		slot.handler.Store(&fn)
+		return slot.ptr, nil
+	}
+
+	slot, err := pool.acquire(fn)
+	if err != nil {
+		return 0, err
+	}
+	l.slots[export] = slot
+	return slot.ptr, nil
+}
+
+// releaseCallback 归还 export 的跳板并删除保存的注册参数
+func (l *Library) releaseCallback(export string) {
+	l.callbackMu.Lock()
+	defer l.callbackMu.Unlock()
+
+	if slot, ok := l.slots[export]; ok {
+		slot.release()
+		delete(l.slots, export)
+	}
+	delete(l.callbacks, export)
+}
+
+// releaseCallbacksLocked 归还所有跳板，调用方需持有 l.callbackMu
+func (l *Library) releaseCallbacksLocked() {
+	for export, slot := range l.slots {
+		slot.release()
+		delete(l.slots, export)
+	}
+}
+
+// adoptCallbacks 接管 prev 的跳板，热重载成功后调用
+// prev 关闭时不再归还这些跳板，C# 新库继续使用同一批函数指针
+func (l *Library) adoptCallbacks(prev *Library) {
+	prev.callbackMu.Lock()
+	slots := prev.slots
+	prev.slots = make(map[string]releasableCallback)
+	prev.callbackMu.Unlock()
+
+	l.callbackMu.Lock()
+	defer l.callbackMu.Unlock()
+
+	for export, slot := range slots {
+		if old, ok := l.slots[export]; ok && old != slot {
+			old.release()
+		}
+		l.slots[export] = slot
+	}
+}
+
+// unregisterCallback 通知 C# 注销 export 注册的回调并归还跳板
+// C# 侧的注册导出函数收到空指针时清除回调
+func (l *Library) unregisterCallback(export string) error {
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+
+	if l.handle == 0 {
+		return ErrNotInitialized
+	}
+
+	fnPtr, err := l.getCachedFunction(export)
+	if err != nil {
+		return err
+	}
+	purego.SyscallN(fnPtr, 0)
+	l.releaseCallback(export)
+	return nil
+}
@@ -0,0 +1,147 @@
+package csharp
+
+import (
+	"testing"
+	"unsafe"
+
+	"github.com/ebitengine/purego"
+)
+
+// TestCallbackPoolReuse 测试跳板归还后被复用，处理函数可替换，注销后返回失败值
+func TestCallbackPoolReuse(t *testing.T) {
+	pool := &callbackPool[RegisterNotifyCb]{
+		name:       "TestCallbackPoolReuse",
+		trampoline: battleResultCallbacks.trampoline,
+	}
+	call := func(ptr uintptr) int32 {
+		ret, _, _ := purego.SyscallN(ptr, 0, 0)
+		return int32(ret)
+	}
+
+	created := callbackSlotsCreated.Load()
+	slot, err := pool.acquire(func(unsafe.Pointer, int32) int { return 1 })
+	if err != nil {
+		t.Fatalf("❌ 取得跳板失败: %v", err)
+	}
+	if got := call(slot.ptr); got != 1 {
+		t.Errorf("❌ 期望处理函数返回 1, 实际 %d", got)
+	}
+
+	// 替换处理函数，指针不变
+	fn := RegisterNotifyCb(func(unsafe.Pointer, int32) int { return 2 })
+	slot.handler.Store(&fn)
+	if got := call(slot.ptr); got != 2 {
+		t.Errorf("❌ 替换后期望返回 2, 实际 %d", got)
+	}
+
+	ptr := slot.ptr
+	slot.release()
+	if got := call(ptr); got != -1 {
+		t.Errorf("❌ 注销后期望返回 -1, 实际 %d", got)
+	}
+
+	// 反复注册注销不再创建新跳板
+	for i := 0; i < 100; i++ {
+		slot, err = pool.acquire(func(unsafe.Pointer, int32) int { return 3 })
+		if err != nil {
+			t.Fatalf("❌ 取得跳板失败: %v", err)
+		}
+		if slot.ptr != ptr {
+			t.Fatalf("❌ 期望复用跳板 0x%x, 实际 0x%x", ptr, slot.ptr)
+		}
+		slot.release()
+	}
+	if n := callbackSlotsCreated.Load() - created; n != 1 {
+		t.Errorf("❌ 期望只创建 1 个跳板, 实际 %d", n)
+	}
+	if pool.idle() != 1 {
+		t.Errorf("❌ 期望池中 1 个空闲跳板, 实际 %d", pool.idle())
+	}
+}
+
+// TestLibraryCallbackLifetime 测试库重新注册复用跳板，热重载转交跳板，关闭时归还
+func TestLibraryCallbackLifetime(t *testing.T) {
+	newLib := func() *Library {
+		return &Library{
+			callbacks: make(map[string][]uintptr),
+			slots:     make(map[string]releasableCallback),
+		}
+	}
+	pool := &callbackPool[RegisterNotifyCb]{
+		name:       "TestLibraryCallbackLifetime",
+		trampoline: battleResultCallbacks.trampoline,
+	}
+	const export = "RegisterBattleResultCallback"
+
+	prev := newLib()
+	first, err := bindCallback(prev, export, pool, func(unsafe.Pointer, int32) int { return 1 })
+	if err != nil {
+		t.Fatalf("❌ 绑定回调失败: %v", err)
+	}
+	second, err := bindCallback(prev, export, pool, func(unsafe.Pointer, int32) int { return 2 })
+	if err != nil {
+		t.Fatalf("❌ 重新绑定回调失败: %v", err)
+	}
+	if first != second {
+		t.Fatalf("❌ 重新注册应复用跳板: 0x%x != 0x%x", first, second)
+	}
+
+	// 热重载: 新库接管跳板，旧库关闭不归还
+	next := newLib()
+	next.adoptCallbacks(prev)
+	prev.releaseCallbacksLocked()
+	if pool.idle() != 0 {
+		t.Fatalf("❌ 旧库不应归还已转交的跳板")
+	}
+	if ret, _, _ := purego.SyscallN(first, 0, 0); ret != 2 {
+		t.Errorf("❌ 转交后期望返回 2, 实际 %d", ret)
+	}
+
+	next.releaseCallback(export)
+	if pool.idle() != 1 {
+		t.Errorf("❌ 注销后跳板应归还到池中, 空闲 %d", pool.idle())
+	}
+	if _, ok := next.slots[export]; ok {
+		t.Errorf("❌ 注销后库不应再持有跳板")
+	}
+}
+
+// TestSimEngineUnregisterBattleEndNotify 测试结果回调注册与注销
+func TestSimEngineUnregisterBattleEndNotify(t *testing.T) {
+	engine := NewSimEngine()
+	engine.DisableBattleLogging()
+	defer engine.Close()
+
+	calls := 0
+	if err := engine.RegisterBattleResultCallback(func(dataPtr unsafe.Pointer, dataLen int32) int32 {
+		calls++
+		return 0
+	}); err != nil {
+		t.Fatalf("❌ 注册回调失败: %v", err)
+	}
+
+	runBattle := func(battleID uint32) {
+		if err := engine.CreateBattle(teamEnv(battleID, 100, 101, 0)); err != nil {
+			t.Fatalf("❌ 创建战斗失败: %v", err)
+		}
+		for i := 0; i < 20; i++ {
+			if _, err := engine.OnTick(uint64(i)); err != nil {
+				t.Fatalf("❌ OnTick 失败: %v", err)
+			}
+		}
+	}
+
+	// 结果和回放各回调一次
+	runBattle(1)
+	if calls != 2 {
+		t.Fatalf("❌ 期望回调 2 次, 实际 %d", calls)
+	}
+
+	if err := engine.UnregisterBattleEndNotify(); err != nil {
+		t.Fatalf("❌ 注销回调失败: %v", err)
+	}
+	runBattle(2)
+	if calls != 2 {
+		t.Errorf("❌ 注销后不应再回调, 实际 %d 次", calls)
+	}
+}
@@ -2,8 +2,7 @@ package csharp
 
 import (
 	"fmt"
-	"os"
-	"path/filepath"
+	"runtime"
 	"sync"
 	"unsafe"
 
@@ -14,16 +13,10 @@ import (
 )
 
 var (
-	libHandle uintptr
-	libMutex  sync.RWMutex
-
 	// Go 侧日志级别控制
 	goLogLevel = LogLevelInfo
 	goLogMutex sync.RWMutex
 
-	// 函数指针缓存 - 加速 SyscallN 调用
-	fnCache sync.Map
-
 	requiredFuncs = []string{
 		// 低级 API
 		"ProcessProtoMessage",
@@ -61,201 +54,142 @@ func goLog(level int, format string, args ...interface{}) {
 }
 
 // ============================================================================
-// 函数指针缓存和加速机制
+// 低级 API (与 C# 直接交互)
 // ============================================================================
 
-// getCachedFunction 从缓存获取函数指针，如果不存在则加载并缓存
-// 这个函数加快了重复调用的速度，避免每次都调用 Dlsym
-// 使用 sync.Map 自动处理并发，无需手动管理锁
-func getCachedFunction(libHandle uintptr, funcName string) (uintptr, error) {
-	// 先尝试从缓存读取
-	if fnPtr, ok := fnCache.Load(funcName); ok {
-		return fnPtr.(uintptr), nil
-	}
-
-	// 缓存未命中，从库中加载函数指针
-	fnPtr, err := purego.Dlsym(libHandle, funcName)
+// ProcessProtoMessage 处理单个 Protobuf 消息 (低级 API)
+// 使用缓存的函数指针加速调用
+func ProcessProtoMessage(requestData []byte) ([]byte, error) {
+	e, err := currentEngine()
 	if err != nil {
-		return 0, fmt.Errorf("找不到函数: %s - %w", funcName, err)
+		return nil, err
 	}
-
-	// 写入缓存（sync.Map 内部自动处理并发）
-	fnCache.Store(funcName, fnPtr)
-	return fnPtr, nil
+	return e.ProcessProtoMessage(requestData)
 }
 
-// validateLibrary 验证 SO 文件是否包含所有必需的导出函数
-// 在库初始化时调用，确保 SO 文件完整
-func validateLibrary(libHandle uintptr) error {
-	missingFuncs := []string{}
+func (l *Library) ProcessProtoMessage(requestData []byte) ([]byte, error) {
+	l.mu.RLock()
+	defer l.mu.RUnlock()
 
-	for _, funcName := range requiredFuncs {
-		if _, err := getCachedFunction(libHandle, funcName); err != nil {
-			missingFuncs = append(missingFuncs, funcName)
-		}
+	if l.handle == 0 {
+		return nil, ErrNotInitialized
 	}
 
-	if len(missingFuncs) > 0 {
-		return fmt.Errorf("SO 文件缺少以下导出函数: %v", missingFuncs)
+	// 获取缓存的函数指针
+	fnPtr, err := l.getCachedFunction("ProcessProtoMessage")
+	if err != nil {
+		return nil, err
 	}
 
-	fmt.Println("[Go] SO 文件验证成功，所有必需函数已找到")
-	return nil
-}
-
-// clearFunctionCache 清空函数指针缓存
-// 在库卸载时调用
-func clearFunctionCache() {
-	// sync.Map 没有直接的清空方法
-	// 使用 Range + Delete 遍历清空
-	fnCache.Range(func(key, value interface{}) bool {
-		fnCache.Delete(key)
-		return true
+	// 响应超过缓冲区时按 C# 报告的长度扩容重试
+	return l.negotiatePendingResponse("ProcessProtoMessage", singleResponseBufferSize, func(respBuffer []byte) int32 {
+		respLen := int32(len(respBuffer))
+		purego.SyscallN(
+			fnPtr,
+			uintptr(unsafe.Pointer(&requestData[0])),
+			uintptr(len(requestData)),
+			uintptr(unsafe.Pointer(&respBuffer[0])),
+			uintptr(unsafe.Pointer(&respLen)),
+		)
+		return respLen
 	})
 }
 
-// ============================================================================
-// 低级 API (与 C# 直接交互)
-// ============================================================================
-
-// ProcessProtoMessage 处理单个 Protobuf 消息 (低级 API)
+// ProcessBatchProtoMessage 批量处理 Protobuf 消息 (低级 API)
 // 使用缓存的函数指针加速调用
-func ProcessProtoMessage(requestData []byte) ([]byte, error) {
-	libMutex.RLock()
-	defer libMutex.RUnlock()
-
-	if libHandle == 0 {
-		return nil, fmt.Errorf("C# 库未初始化")
-	}
-
-	// 获取缓存的函数指针
-	fnPtr, err := getCachedFunction(libHandle, "ProcessProtoMessage")
+func ProcessBatchProtoMessage(requestData []byte) ([]byte, error) {
+	e, err := currentEngine()
 	if err != nil {
 		return nil, err
 	}
-
-	// 准备响应缓冲区
-	respBuffer := make([]byte, 10240)
-	respLen := int32(len(respBuffer))
-
-	// 构建函数调用
-	fn := unsafe.Pointer(fnPtr)
-	purego.SyscallN(
-		uintptr(fn),
-		uintptr(unsafe.Pointer(&requestData[0])),
-		uintptr(len(requestData)),
-		uintptr(unsafe.Pointer(&respBuffer[0])),
-		uintptr(unsafe.Pointer(&respLen)),
-	)
-
-	return respBuffer[:respLen], nil
+	return e.ProcessBatchProtoMessage(requestData)
 }
 
-// ProcessBatchProtoMessage 批量处理 Protobuf 消息 (低级 API)
-// 使用缓存的函数指针加速调用
-func ProcessBatchProtoMessage(requestData []byte) ([]byte, error) {
-	libMutex.RLock()
-	defer libMutex.RUnlock()
+func (l *Library) ProcessBatchProtoMessage(requestData []byte) ([]byte, error) {
+	l.mu.RLock()
+	defer l.mu.RUnlock()
 
-	if libHandle == 0 {
-		return nil, fmt.Errorf("C# 库未初始化")
+	if l.handle == 0 {
+		return nil, ErrNotInitialized
 	}
 
 	// 获取缓存的函数指针
-	fnPtr, err := getCachedFunction(libHandle, "ProcessBatchProtoMessage")
+	fnPtr, err := l.getCachedFunction("ProcessBatchProtoMessage")
 	if err != nil {
 		return nil, err
 	}
 
-	// 准备响应缓冲区
-	respBuffer := make([]byte, 102400)
-	respLen := int32(len(respBuffer))
-
-	// 构建函数调用
-	fn := unsafe.Pointer(fnPtr)
-	purego.SyscallN(
-		uintptr(fn),
-		uintptr(unsafe.Pointer(&requestData[0])),
-		uintptr(len(requestData)),
-		uintptr(unsafe.Pointer(&respBuffer[0])),
-		uintptr(unsafe.Pointer(&respLen)),
-	)
-
-	return respBuffer[:respLen], nil
+	// 响应超过缓冲区时按 C# 报告的长度扩容重试
+	return l.negotiatePendingResponse("ProcessBatchProtoMessage", batchResponseBufferSize, func(respBuffer []byte) int32 {
+		respLen := int32(len(respBuffer))
+		purego.SyscallN(
+			fnPtr,
+			uintptr(unsafe.Pointer(&requestData[0])),
+			uintptr(len(requestData)),
+			uintptr(unsafe.Pointer(&respBuffer[0])),
+			uintptr(unsafe.Pointer(&respLen)),
+		)
+		return respLen
+	})
 }
 
 // RegisterCallback 注册 Go 回调函数到 C#
 func RegisterCallback(callbackPtr unsafe.Pointer) error {
-	libMutex.RLock()
-	defer libMutex.RUnlock()
-
-	if libHandle == 0 {
-		return fmt.Errorf("C# 库未初始化")
-	}
-
-	fnPtr, err := getCachedFunction(libHandle, "RegisterCallback")
+	e, err := currentEngine()
 	if err != nil {
-		return fmt.Errorf("找不到函数: RegisterCallback - %w", err)
+		return err
 	}
-
-	fn := unsafe.Pointer(fnPtr)
-	purego.SyscallN(
-		uintptr(fn),
-		uintptr(callbackPtr),
-	)
-
-	fmt.Println("[Go] 回调函数已注册给 C#")
-	return nil
+	return e.RegisterCallback(callbackPtr)
 }
 
-type NotifyException func()
+func (l *Library) RegisterCallback(callbackPtr unsafe.Pointer) error {
+	l.mu.RLock()
+	defer l.mu.RUnlock()
 
-type CSharpExceptionContext struct {
-	CallbackPtr           NotifyException
-	NotifyExceptionBuffer []byte
-}
-
-func InjectCSharpExceptionCallback(globalCtx CSharpExceptionContext) error {
-	libMutex.RLock()
-	defer libMutex.RUnlock()
-
-	if libHandle == 0 {
-		return fmt.Errorf("C# 库未初始化")
+	if l.handle == 0 {
+		return ErrNotInitialized
 	}
 
-	fnPtr, err := purego.Dlsym(libHandle, "InjectedExceptionContext")
+	fnPtr, err := l.getCachedFunction("RegisterCallback")
 	if err != nil {
-		return fmt.Errorf("找不到函数: InjectedExceptionContext - %w", err)
+		return err
 	}
 
-	callbackPtr := purego.NewCallback(globalCtx.CallbackPtr)
 	purego.SyscallN(
-		uintptr(fnPtr),
-		callbackPtr,
-		uintptr(unsafe.Pointer(&globalCtx.NotifyExceptionBuffer[0])),
-		uintptr(len(globalCtx.NotifyExceptionBuffer)),
+		fnPtr,
+		uintptr(callbackPtr),
 	)
+	l.saveCallback("RegisterCallback", uintptr(callbackPtr))
+
+	fmt.Println("[Go] 回调函数已注册给 C#")
 	return nil
 }
 
 // TestNotifyCallback 测试 C# 侧触发回调
 // 用于验证 Go 回调是否正确工作
 func TestNotifyCallback(notificationType int32, battleID int64, timestamp int64) (int32, error) {
-	libMutex.RLock()
-	defer libMutex.RUnlock()
+	e, err := currentEngine()
+	if err != nil {
+		return -1, err
+	}
+	return e.TestNotifyCallback(notificationType, battleID, timestamp)
+}
+
+func (l *Library) TestNotifyCallback(notificationType int32, battleID int64, timestamp int64) (int32, error) {
+	l.mu.RLock()
+	defer l.mu.RUnlock()
 
-	if libHandle == 0 {
-		return -1, fmt.Errorf("C# 库未初始化")
+	if l.handle == 0 {
+		return -1, ErrNotInitialized
 	}
 
-	fnPtr, err := getCachedFunction(libHandle, "TestNotifyCallback")
+	fnPtr, err := l.getCachedFunction("TestNotifyCallback")
 	if err != nil {
-		return -1, fmt.Errorf("找不到函数: TestNotifyCallback - %w", err)
+		return -1, err
 	}
 
-	fn := unsafe.Pointer(fnPtr)
 	result, _, _ := purego.SyscallN(
-		uintptr(fn),
+		fnPtr,
 		uintptr(notificationType),
 		uintptr(battleID),
 		uintptr(timestamp),
@@ -269,120 +203,69 @@ func TestNotifyCallback(notificationType int32, battleID int64, timestamp int64)
 // 库生命周期管理
 // ============================================================================
 
-// InitCSharpLib 初始化 C# 动态库
+// InitCSharpLib 按版本打开 C# 动态库并设为当前激活的引擎
+// 新库加载并验证成功后才会替换旧引擎，旧引擎随后被显式关闭
+// 需要多个库并存时请直接使用 OpenLibraryVersion
 func InitCSharpLib(version string) error {
-	libMutex.Lock()
-	defer libMutex.Unlock()
-
-	// 如果已经初始化，先关闭旧的库句柄
-	if libHandle != 0 {
-		fmt.Printf("[InitCSharpLib] 检测到旧库句柄 %d，正在关闭...\n", libHandle)
-		// 尝试关闭旧的句柄，但不失败
-		_ = purego.Dlclose(libHandle)
-		libHandle = 0
-		fmt.Println("[InitCSharpLib] 旧库句柄已关闭")
-	}
-
-	if version == "" {
-		version = "Release"
-	}
-
-	// 先尝试相对路径（从项目根目录运行）
-	libPath := filepath.Join("lib", fmt.Sprintf("TestExport_%s.so", version))
-
-	// 检查文件是否存在
-	if _, err := os.Stat(libPath); err != nil {
-		// 如果相对路径不存在，尝试从当前工作目录向上查找项目根目录
-		// 这适用于从 cmd/test 等子目录运行测试的情况
-		wd, err2 := os.Getwd()
-		if err2 == nil {
-			// 尝试向上一级
-			projectRoot := filepath.Dir(wd)
-			altPath := filepath.Join(projectRoot, "lib", fmt.Sprintf("TestExport_%s.so", version))
-			if _, err3 := os.Stat(altPath); err3 == nil {
-				libPath = altPath
-			} else {
-				// 再尝试向上一级（从 cmd/test 到项目根目录）
-				projectRoot = filepath.Dir(projectRoot)
-				altPath = filepath.Join(projectRoot, "lib", fmt.Sprintf("TestExport_%s.so", version))
-				if _, err4 := os.Stat(altPath); err4 == nil {
-					libPath = altPath
-				} else {
-					return fmt.Errorf("库文件不存在: %s - %w", libPath, err)
-				}
-			}
-		} else {
-			return fmt.Errorf("库文件不存在: %s - %w", libPath, err)
-		}
-	}
-
-	// 打开动态库
-	handle, err := purego.Dlopen(libPath, purego.RTLD_NOW|purego.RTLD_GLOBAL)
+	lib, err := OpenLibraryVersion(version)
 	if err != nil {
-		return fmt.Errorf("打开库失败: %s - %w", libPath, err)
-	}
-
-	libHandle = handle
-	fmt.Printf("[Go] C# 库已加载: %s (handle=%d)\n", libPath, libHandle)
-
-	// 验证库中所有必需的导出函数
-	if err := validateLibrary(libHandle); err != nil {
-		// 关闭库并清空句柄
-		_ = purego.Dlclose(libHandle)
-		libHandle = 0
-		clearFunctionCache()
 		return err
 	}
+	return activateLibrary(lib)
+}
 
+// activateLibrary 将已打开的库设为当前激活的引擎，并关闭被替换的旧引擎
+func activateLibrary(lib *Library) error {
+	if prev := UseEngine(lib); prev != nil {
+		fmt.Printf("[InitCSharpLib] 替换旧引擎 %T，正在关闭...\n", prev)
+		if err := prev.Close(); err != nil {
+			return fmt.Errorf("关闭旧引擎失败: %w", err)
+		}
+	}
 	return nil
 }
 
-// CloseCSharpLib 关闭 C# 动态库
+// CloseCSharpLib 关闭当前激活的引擎 (C# 动态库或模拟引擎)
 func CloseCSharpLib() error {
-	libMutex.Lock()
-	defer libMutex.Unlock()
-
-	if libHandle == 0 {
+	e := UseEngine(nil)
+	if e == nil {
 		// 库未初始化，不是错误
 		return nil
 	}
+	return e.Close()
+}
 
-	// 关闭库
-	err := purego.Dlclose(libHandle)
-	libHandle = 0
-
-	// 清空函数指针缓存
-	clearFunctionCache()
-
+func CallCSharpPainc() error {
+	e, err := currentEngine()
 	if err != nil {
-		// 记录错误但继续，因为库句柄已经清空
-		fmt.Printf("[Go] 关闭库时出错: %v\n", err)
-		return nil
+		return err
 	}
-
-	fmt.Println("[Go] C# 库已关闭")
-	return nil
+	return e.CallPanic()
 }
 
-func CallCSharpPainc() error {
-	libMutex.RLock()
-	defer libMutex.RUnlock()
+func (l *Library) CallPanic() error {
+	l.mu.RLock()
+	defer l.mu.RUnlock()
 
-	if libHandle == 0 {
-		return fmt.Errorf("C# 库未初始化")
+	if l.handle == 0 {
+		return ErrNotInitialized
 	}
 
-	fnPtr, err := getCachedFunction(libHandle, "CsharpPanic")
+	fnPtr, err := l.getCachedFunction("CsharpPanic")
 	if err != nil {
-		return fmt.Errorf("找不到函数: CsharpPanic - %w", err)
+		return err
 	}
 
+	// 锁定 OS 线程，失败时在同一线程上取回托管异常
+	runtime.LockOSThread()
+	defer runtime.UnlockOSThread()
+
 	result, _, _ := purego.SyscallN(
 		uintptr(fnPtr),
 	)
 
 	if result != 0 {
-		return fmt.Errorf("CsharpPanic 返回错误: %d", result)
+		return l.attachException(exportError("CsharpPanic", int32(result), proto_pb.BattleErrorCode_INTERNAL_ERROR))
 	}
 	return nil
 }
@@ -391,153 +274,215 @@ func CallCSharpPainc() error {
 // 战斗管理 API (BattleManager 导出)
 // ============================================================================
 
-// BattleResultCallbackFunc Go 侧的战斗结果回调签名
-// 返回: 返回值码 (0=成功, -1=失败), BattleContext 数据指针, 数据长度
-type BattleResultCallbackFunc func() (int32, unsafe.Pointer, int32)
+// BattleResultCallbackFunc Go 侧的战斗结果回调签名，对应 C# BattleResultCallback
+// 参数: BattleContext 数据指针, 数据长度
+// 返回: 0=成功, -1=失败
+type BattleResultCallbackFunc func(dataPtr unsafe.Pointer, dataLen int32) int32
 
 // RegisterBattleResultCallback 注册战斗结果回调
+// 与 RegisterBattleEndNotify 注册的是 C# 同一个回调，后注册的生效
 func RegisterBattleResultCallback(fn BattleResultCallbackFunc) error {
-	libMutex.RLock()
-	defer libMutex.RUnlock()
-
-	if libHandle == 0 {
-		return fmt.Errorf("C# 库未初始化")
-	}
-
-	fnPtr, err := getCachedFunction(libHandle, "RegisterBattleResultCallback")
+	e, err := currentEngine()
 	if err != nil {
-		return fmt.Errorf("找不到函数: RegisterBattleResultCallback - %w", err)
+		return err
 	}
+	return e.RegisterBattleResultCallback(fn)
+}
 
-	callbackPtr := unsafe.Pointer(&fn)
+func (l *Library) RegisterBattleResultCallback(fn BattleResultCallbackFunc) error {
+	l.mu.RLock()
+	defer l.mu.RUnlock()
 
-	fn2 := unsafe.Pointer(fnPtr)
-	result, _, _ := purego.SyscallN(
-		uintptr(fn2),
-		uintptr(callbackPtr),
-	)
+	if l.handle == 0 {
+		return ErrNotInitialized
+	}
 
-	if result != 0 {
-		return fmt.Errorf("RegisterBattleResultCallback 返回错误: %d", result)
+	if err := l.registerBattleResult("RegisterBattleResultCallback", battleResultAdapter(fn)); err != nil {
+		return err
 	}
 	fmt.Println("[Go] 战斗结果回调已注册")
 	return nil
 }
 
-// CreateBattle 创建战斗
-func CreateBattle(battleId, atkTeamId, defTeamId uint32) error {
-	libMutex.RLock()
-	defer libMutex.RUnlock()
+// CreateBattle 按 env 创建战斗: env.BattleId 为战斗 ID，双方阵容 (Team.lineup) 中的每个 ID 创建一个单位
+// 战斗内的随机数由 env.Seed 决定，相同的 env 和输入序列得到相同的战斗过程
+func CreateBattle(env *proto_pb.BattleEnv) error {
+	e, err := currentEngine()
+	if err != nil {
+		return err
+	}
+	return e.CreateBattle(env)
+}
+
+func (l *Library) CreateBattle(env *proto_pb.BattleEnv) error {
+	data, err := proto.Marshal(env)
+	if err != nil {
+		return fmt.Errorf("序列化 BattleEnv 失败: %w", err)
+	}
+	if len(data) == 0 {
+		return fmt.Errorf("%w: BattleEnv 为空", ErrInvalidRequest)
+	}
+
+	l.mu.RLock()
+	defer l.mu.RUnlock()
 
-	if libHandle == 0 {
-		return fmt.Errorf("C# 库未初始化")
+	if l.handle == 0 {
+		return ErrNotInitialized
 	}
 
-	fnPtr, err := getCachedFunction(libHandle, "CreateBattle")
+	fnPtr, err := l.getCachedFunction("CreateBattle")
 	if err != nil {
-		return fmt.Errorf("找不到函数: CreateBattle - %w", err)
+		return err
 	}
 
-	fn := unsafe.Pointer(fnPtr)
+	// 锁定 OS 线程，失败时在同一线程上取回托管异常
+	runtime.LockOSThread()
+	defer runtime.UnlockOSThread()
+
 	result, _, _ := purego.SyscallN(
-		uintptr(fn),
-		uintptr(battleId),
-		uintptr(atkTeamId),
-		uintptr(defTeamId),
+		fnPtr,
+		uintptr(unsafe.Pointer(&data[0])),
+		uintptr(len(data)),
 	)
 
 	if result != 0 {
-		return fmt.Errorf("CreateBattle 返回错误: %d", result)
+		return l.attachException(exportError("CreateBattle", int32(result), createBattleErrorCode(int32(result))))
 	}
-	goLog(LogLevelInfo, "[Go] 战斗已创建: ID=%d, ATK=%d, DEF=%d\n", battleId, atkTeamId, defTeamId)
+	goLog(LogLevelInfo, "[Go] 战斗已创建: ID=%d, ATK=%d, DEF=%d, Seed=%d\n",
+		env.GetBattleId(), env.GetAtk().GetTeamId(), env.GetDef().GetTeamId(), env.GetSeed())
 	return nil
 }
 
 // DestroyBattle 销毁战斗
 func DestroyBattle(battleId uint64) error {
-	libMutex.RLock()
-	defer libMutex.RUnlock()
+	e, err := currentEngine()
+	if err != nil {
+		return err
+	}
+	return e.DestroyBattle(battleId)
+}
 
-	if libHandle == 0 {
-		return fmt.Errorf("C# 库未初始化")
+func (l *Library) DestroyBattle(battleId uint64) error {
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+
+	if l.handle == 0 {
+		return ErrNotInitialized
 	}
 
-	fnPtr, err := getCachedFunction(libHandle, "DestroyBattle")
+	fnPtr, err := l.getCachedFunction("DestroyBattle")
 	if err != nil {
-		return fmt.Errorf("找不到函数: DestroyBattle - %w", err)
+		return err
 	}
 
-	fn := unsafe.Pointer(fnPtr)
+	// 锁定 OS 线程，失败时在同一线程上取回托管异常
+	runtime.LockOSThread()
+	defer runtime.UnlockOSThread()
+
 	result, _, _ := purego.SyscallN(
-		uintptr(fn),
+		fnPtr,
 		uintptr(battleId),
 	)
 
 	if result != 0 {
-		return fmt.Errorf("DestroyBattle 返回错误: %d", result)
+		return l.attachException(exportError("DestroyBattle", int32(result), proto_pb.BattleErrorCode_BATTLE_NOT_FOUND))
 	}
 	goLog(LogLevelInfo, "[Go] 战斗已销毁: ID=%d\n", battleId)
 	return nil
 }
 
-// OnTick 推动战斗进行一个 Tick，返回处理的战斗数量
-func OnTick() (int32, error) {
-	libMutex.RLock()
-	defer libMutex.RUnlock()
+// OnTick 推动战斗进行逻辑帧 frame，返回处理的战斗数量
+// 本帧产生的输出 BattleContext.Tick 为 frame
+func OnTick(frame uint64) (int32, error) {
+	e, err := currentEngine()
+	if err != nil {
+		return -1, err
+	}
+	return e.OnTick(frame)
+}
+
+func (l *Library) OnTick(frame uint64) (int32, error) {
+	l.mu.RLock()
+	defer l.mu.RUnlock()
 
-	if libHandle == 0 {
-		return -1, fmt.Errorf("C# 库未初始化")
+	if l.handle == 0 {
+		return -1, ErrNotInitialized
 	}
 
-	fnPtr, err := getCachedFunction(libHandle, "OnTick")
+	fnPtr, err := l.getCachedFunction("OnTick")
 	goLog(LogLevelInfo, "[Go] 战斗已创建: OnTick 函数指针=%d\n", fnPtr)
 	if err != nil {
-		return -1, fmt.Errorf("找不到函数: OnTick - %w", err)
+		return -1, err
 	}
 
-	fn := unsafe.Pointer(fnPtr)
-	result, _, _ := purego.SyscallN(uintptr(fn))
+	// 锁定 OS 线程，失败时在同一线程上取回托管异常
+	runtime.LockOSThread()
+	defer runtime.UnlockOSThread()
 
-	return int32(result), nil
+	result, _, _ := purego.SyscallN(fnPtr, uintptr(frame))
+
+	processed := int32(result)
+	if processed < 0 {
+		return -1, l.attachException(exportError("OnTick", processed, proto_pb.BattleErrorCode_INTERNAL_ERROR))
+	}
+	return processed, nil
 }
 
 // GetBattleCount 获取当前战斗数量
 func GetBattleCount() (int32, error) {
-	libMutex.RLock()
-	defer libMutex.RUnlock()
+	e, err := currentEngine()
+	if err != nil {
+		return -1, err
+	}
+	return e.GetBattleCount()
+}
+
+func (l *Library) GetBattleCount() (int32, error) {
+	l.mu.RLock()
+	defer l.mu.RUnlock()
 
-	if libHandle == 0 {
-		return -1, fmt.Errorf("C# 库未初始化")
+	if l.handle == 0 {
+		return -1, ErrNotInitialized
 	}
 
-	fnPtr, err := getCachedFunction(libHandle, "GetBattleCount")
+	fnPtr, err := l.getCachedFunction("GetBattleCount")
 	if err != nil {
-		return -1, fmt.Errorf("找不到函数: GetBattleCount - %w", err)
+		return -1, err
 	}
 
-	fn := unsafe.Pointer(fnPtr)
-	result, _, _ := purego.SyscallN(uintptr(fn))
+	result, _, _ := purego.SyscallN(fnPtr)
 
 	return int32(result), nil
 }
 
 // ProcessBattleInput 处理战斗输入
 func ProcessBattleInput(battleId uint32, teamId uint32, actionType byte, actionValue int32) error {
-	libMutex.RLock()
-	defer libMutex.RUnlock()
+	e, err := currentEngine()
+	if err != nil {
+		return err
+	}
+	return e.ProcessBattleInput(battleId, teamId, actionType, actionValue)
+}
 
-	if libHandle == 0 {
-		return fmt.Errorf("C# 库未初始化")
+func (l *Library) ProcessBattleInput(battleId uint32, teamId uint32, actionType byte, actionValue int32) error {
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+
+	if l.handle == 0 {
+		return ErrNotInitialized
 	}
 
-	fnPtr, err := getCachedFunction(libHandle, "ProcessBattleInput")
+	fnPtr, err := l.getCachedFunction("ProcessBattleInput")
 	if err != nil {
-		return fmt.Errorf("找不到函数: ProcessBattleInput - %w", err)
+		return err
 	}
 
-	fn := unsafe.Pointer(fnPtr)
+	// 锁定 OS 线程，失败时在同一线程上取回托管异常
+	runtime.LockOSThread()
+	defer runtime.UnlockOSThread()
+
 	result, _, _ := purego.SyscallN(
-		uintptr(fn),
+		fnPtr,
 		uintptr(battleId),
 		uintptr(teamId),
 		uintptr(actionType),
@@ -545,33 +490,49 @@ func ProcessBattleInput(battleId uint32, teamId uint32, actionType byte, actionV
 	)
 
 	if result != 0 {
-		return fmt.Errorf("ProcessBattleInput 返回错误: %d", result)
+		return l.attachException(exportError("ProcessBattleInput", int32(result), battleInputErrorCode(int32(result))))
 	}
 	return nil
 }
 
+// PrcessBattleContextInput 处理序列化后的 BattleContext 输入
 func PrcessBattleContextInput(inputBuff unsafe.Pointer, bufflen uint32) error {
-	libMutex.RLock()
-	defer libMutex.RUnlock()
+	e, err := currentEngine()
+	if err != nil {
+		return err
+	}
+	return e.ProcessBattleContextInput(unsafe.Slice((*byte)(inputBuff), bufflen))
+}
+
+func (l *Library) ProcessBattleContextInput(input []byte) error {
+	if len(input) == 0 {
+		return fmt.Errorf("%w: 输入数据为空", ErrInvalidRequest)
+	}
 
-	if libHandle == 0 {
-		return fmt.Errorf("C# 库未初始化")
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+
+	if l.handle == 0 {
+		return ErrNotInitialized
 	}
 
-	fnPtr, err := getCachedFunction(libHandle, "ProcessBattleContextInput")
+	fnPtr, err := l.getCachedFunction("ProcessBattleContextInput")
 	if err != nil {
-		return fmt.Errorf("找不到函数: ProcessBattleContextInput - %w", err)
+		return err
 	}
 
-	fn := unsafe.Pointer(fnPtr)
+	// 锁定 OS 线程，失败时在同一线程上取回托管异常
+	runtime.LockOSThread()
+	defer runtime.UnlockOSThread()
+
 	result, _, _ := purego.SyscallN(
-		uintptr(fn),
-		uintptr(inputBuff),
-		uintptr(bufflen),
+		fnPtr,
+		uintptr(unsafe.Pointer(&input[0])),
+		uintptr(len(input)),
 	)
 
 	if result != 0 {
-		return fmt.Errorf("ProcessBattleInput 返回错误: %d", result)
+		return l.attachException(exportError("ProcessBattleContextInput", int32(result), contextInputErrorCode(int32(result))))
 	}
 	return nil
 }
@@ -599,46 +560,60 @@ func SetBattleLogLevel(level int) error {
 		goLogMutex.Unlock()
 	}
 
-	libMutex.RLock()
-	defer libMutex.RUnlock()
+	e, err := currentEngine()
+	if err != nil {
+		return err
+	}
+	return e.SetBattleLogLevel(level)
+}
+
+func (l *Library) SetBattleLogLevel(level int) error {
+	l.mu.RLock()
+	defer l.mu.RUnlock()
 
-	if libHandle == 0 {
-		return fmt.Errorf("C# 库未初始化")
+	if l.handle == 0 {
+		return ErrNotInitialized
 	}
 
-	fnPtr, err := getCachedFunction(libHandle, "SetBattleLogLevel")
+	fnPtr, err := l.getCachedFunction("SetBattleLogLevel")
 	if err != nil {
-		return fmt.Errorf("找不到函数: SetBattleLogLevel - %w", err)
+		return err
 	}
 
-	fn := unsafe.Pointer(fnPtr)
 	result, _, _ := purego.SyscallN(
-		uintptr(fn),
+		fnPtr,
 		uintptr(level),
 	)
 
 	if result != 0 {
-		return fmt.Errorf("SetBattleLogLevel 返回错误: %d", result)
+		return exportError("SetBattleLogLevel", int32(result), proto_pb.BattleErrorCode_INVALID_REQUEST)
 	}
 	return nil
 }
 
 // GetBattleLogLevel 获取当前战斗日志级别
 func GetBattleLogLevel() (int, error) {
-	libMutex.RLock()
-	defer libMutex.RUnlock()
+	e, err := currentEngine()
+	if err != nil {
+		return -1, err
+	}
+	return e.GetBattleLogLevel()
+}
 
-	if libHandle == 0 {
-		return -1, fmt.Errorf("C# 库未初始化")
+func (l *Library) GetBattleLogLevel() (int, error) {
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+
+	if l.handle == 0 {
+		return -1, ErrNotInitialized
 	}
 
-	fnPtr, err := getCachedFunction(libHandle, "GetBattleLogLevel")
+	fnPtr, err := l.getCachedFunction("GetBattleLogLevel")
 	if err != nil {
-		return -1, fmt.Errorf("找不到函数: GetBattleLogLevel - %w", err)
+		return -1, err
 	}
 
-	fn := unsafe.Pointer(fnPtr)
-	result, _, _ := purego.SyscallN(uintptr(fn))
+	result, _, _ := purego.SyscallN(fnPtr)
 
 	return int(result), nil
 }
@@ -650,20 +625,27 @@ func EnableBattleLogging() error {
 	goLogLevel = LogLevelDebug
 	goLogMutex.Unlock()
 
-	libMutex.RLock()
-	defer libMutex.RUnlock()
+	e, err := currentEngine()
+	if err != nil {
+		return err
+	}
+	return e.EnableBattleLogging()
+}
+
+func (l *Library) EnableBattleLogging() error {
+	l.mu.RLock()
+	defer l.mu.RUnlock()
 
-	if libHandle == 0 {
-		return fmt.Errorf("C# 库未初始化")
+	if l.handle == 0 {
+		return ErrNotInitialized
 	}
 
-	fnPtr, err := getCachedFunction(libHandle, "EnableBattleLogging")
+	fnPtr, err := l.getCachedFunction("EnableBattleLogging")
 	if err != nil {
-		return fmt.Errorf("找不到函数: EnableBattleLogging - %w", err)
+		return err
 	}
 
-	fn := unsafe.Pointer(fnPtr)
-	purego.SyscallN(uintptr(fn))
+	purego.SyscallN(fnPtr)
 	return nil
 }
 
@@ -674,20 +656,27 @@ func DisableBattleLogging() error {
 	goLogLevel = LogLevelNone
 	goLogMutex.Unlock()
 
-	libMutex.RLock()
-	defer libMutex.RUnlock()
+	e, err := currentEngine()
+	if err != nil {
+		return err
+	}
+	return e.DisableBattleLogging()
+}
+
+func (l *Library) DisableBattleLogging() error {
+	l.mu.RLock()
+	defer l.mu.RUnlock()
 
-	if libHandle == 0 {
-		return fmt.Errorf("C# 库未初始化")
+	if l.handle == 0 {
+		return ErrNotInitialized
 	}
 
-	fnPtr, err := getCachedFunction(libHandle, "DisableBattleLogging")
+	fnPtr, err := l.getCachedFunction("DisableBattleLogging")
 	if err != nil {
-		return fmt.Errorf("找不到函数: DisableBattleLogging - %w", err)
+		return err
 	}
 
-	fn := unsafe.Pointer(fnPtr)
-	purego.SyscallN(uintptr(fn))
+	purego.SyscallN(fnPtr)
 	return nil
 }
 
@@ -697,6 +686,14 @@ func DisableBattleLogging() error {
 
 // ExecBattle 执行单场战斗
 func ExecBattle(battleReq *proto_pb.StartBattle) (*proto_pb.BattleResult, error) {
+	e, err := currentEngine()
+	if err != nil {
+		return nil, err
+	}
+	return execBattle(e, battleReq)
+}
+
+func execBattle(e Engine, battleReq *proto_pb.StartBattle) (*proto_pb.BattleResult, error) {
 	// 序列化请求
 	reqData, err := proto.Marshal(battleReq)
 	if err != nil {
@@ -704,7 +701,7 @@ func ExecBattle(battleReq *proto_pb.StartBattle) (*proto_pb.BattleResult, error)
 	}
 
 	// 调用 C# 函数
-	respData, err := ProcessProtoMessage(reqData)
+	respData, err := e.ProcessProtoMessage(reqData)
 	if err != nil {
 		return nil, fmt.Errorf("C# 调用失败: %w", err)
 	}
@@ -716,8 +713,8 @@ func ExecBattle(battleReq *proto_pb.StartBattle) (*proto_pb.BattleResult, error)
 	}
 
 	// 检查错误码
-	if resp.Code != 0 {
-		return nil, fmt.Errorf("C# 返回错误 (Code=%d): %s", resp.Code, resp.Message)
+	if err := responseError("ExecBattle", resp); err != nil {
+		return nil, err
 	}
 
 	// 解析战斗结果
@@ -731,6 +728,14 @@ func ExecBattle(battleReq *proto_pb.StartBattle) (*proto_pb.BattleResult, error)
 
 // ExecBatchBattle 执行批量战斗
 func ExecBatchBattle(batchReq *proto_pb.BatchBattleRequest) (*proto_pb.BatchBattleResponse, error) {
+	e, err := currentEngine()
+	if err != nil {
+		return nil, err
+	}
+	return execBatchBattle(e, batchReq)
+}
+
+func execBatchBattle(e Engine, batchReq *proto_pb.BatchBattleRequest) (*proto_pb.BatchBattleResponse, error) {
 	// 序列化请求
 	reqData, err := proto.Marshal(batchReq)
 	if err != nil {
@@ -738,7 +743,7 @@ func ExecBatchBattle(batchReq *proto_pb.BatchBattleRequest) (*proto_pb.BatchBatt
 	}
 
 	// 调用 C# 函数
-	respData, err := ProcessBatchProtoMessage(reqData)
+	respData, err := e.ProcessBatchProtoMessage(reqData)
 	if err != nil {
 		return nil, fmt.Errorf("C# 调用失败: %w", err)
 	}
@@ -750,8 +755,8 @@ func ExecBatchBattle(batchReq *proto_pb.BatchBattleRequest) (*proto_pb.BatchBatt
 	}
 
 	// 检查错误码
-	if resp.Code != 0 {
-		return nil, fmt.Errorf("C# 返回错误 (Code=%d): %s", resp.Code, resp.Message)
+	if err := responseError("ExecBatchBattle", resp); err != nil {
+		return nil, err
 	}
 
 	// 解析批量战斗结果
@@ -784,23 +789,30 @@ func Export2CSharpBattleEndNotify(battleNotification *proto_pb.BattleNotificatio
 // CallCSharpGlobalFunction 调用 C# 侧导出的全局函数
 // 这个函数演示如何从 Go 侧调用 C# 的导出函数
 func CallCSharpGlobalFunction(functionName string, battleID uint32, notificationType int, timestamp int64) (int32, error) {
-	libMutex.RLock()
-	defer libMutex.RUnlock()
+	e, err := currentEngine()
+	if err != nil {
+		return -1, err
+	}
+	return e.CallGlobalFunction(functionName, battleID, notificationType, timestamp)
+}
+
+func (l *Library) CallGlobalFunction(functionName string, battleID uint32, notificationType int, timestamp int64) (int32, error) {
+	l.mu.RLock()
+	defer l.mu.RUnlock()
 
-	if libHandle == 0 {
-		return -1, fmt.Errorf("C# 库未初始化")
+	if l.handle == 0 {
+		return -1, ErrNotInitialized
 	}
 
 	switch functionName {
 	case "CallGoGlobalHandleBattleNotification":
-		fnPtr, err := getCachedFunction(libHandle, "CallGoGlobalHandleBattleNotification")
+		fnPtr, err := l.getCachedFunction("CallGoGlobalHandleBattleNotification")
 		if err != nil {
-			return -1, fmt.Errorf("找不到函数: %s - %w", functionName, err)
+			return -1, err
 		}
 
-		fn := unsafe.Pointer(fnPtr)
 		result, _, _ := purego.SyscallN(
-			uintptr(fn),
+			fnPtr,
 			uintptr(battleID),
 			uintptr(notificationType),
 			uintptr(timestamp),
@@ -810,15 +822,14 @@ func CallCSharpGlobalFunction(functionName string, battleID uint32, notification
 		return int32(result), nil
 
 	case "CallGoCalculateSum":
-		fnPtr, err := getCachedFunction(libHandle, "CallGoCalculateSum")
+		fnPtr, err := l.getCachedFunction("CallGoCalculateSum")
 		if err != nil {
-			return -1, fmt.Errorf("找不到函数: %s - %w", functionName, err)
+			return -1, err
 		}
 
 		// 这个函数接收两个 int32，返回结果
-		fn := unsafe.Pointer(fnPtr)
 		result, _, _ := purego.SyscallN(
-			uintptr(fn),
+			fnPtr,
 			uintptr(int32(battleID)),         // 作为第一个整数
 			uintptr(int32(notificationType)), // 作为第二个整数
 		)
@@ -833,24 +844,31 @@ func CallCSharpGlobalFunction(functionName string, battleID uint32, notification
 
 // CallCSharpSimpleGlobalFunction 调用 C# 侧的简单全局函数
 func CallCSharpSimpleGlobalFunction(battleID uint32, action string) (int32, error) {
-	libMutex.RLock()
-	defer libMutex.RUnlock()
+	e, err := currentEngine()
+	if err != nil {
+		return -1, err
+	}
+	return e.CallSimpleGlobalFunction(battleID, action)
+}
+
+func (l *Library) CallSimpleGlobalFunction(battleID uint32, action string) (int32, error) {
+	l.mu.RLock()
+	defer l.mu.RUnlock()
 
-	if libHandle == 0 {
-		return -1, fmt.Errorf("C# 库未初始化")
+	if l.handle == 0 {
+		return -1, ErrNotInitialized
 	}
 
-	fnPtr, err := purego.Dlsym(libHandle, "CallGoSimpleGlobalFunction")
+	fnPtr, err := purego.Dlsym(l.handle, "CallGoSimpleGlobalFunction")
 	if err != nil {
-		return -1, fmt.Errorf("找不到函数: CallGoSimpleGlobalFunction - %w", err)
+		return -1, fmt.Errorf("%w: CallGoSimpleGlobalFunction - %w", ErrExportNotFound, err)
 	}
 
 	// 将字符串转为字节数组
 	actionBytes := []byte(action)
 
-	fn := unsafe.Pointer(fnPtr)
 	result, _, _ := purego.SyscallN(
-		uintptr(fn),
+		fnPtr,
 		uintptr(battleID),
 		uintptr(unsafe.Pointer(&actionBytes[0])),
 		uintptr(len(actionBytes)),
@@ -867,28 +885,35 @@ func CallCSharpSimpleGlobalFunction(battleID uint32, action string) (int32, erro
 // 3. Go 侧回调加载文件并返回数据给 C#
 // 4. C# 将数据返回给 Go
 func CallCSharpGetConfigLoaderData(configName string) ([]byte, error) {
-	libMutex.RLock()
-	defer libMutex.RUnlock()
+	e, err := currentEngine()
+	if err != nil {
+		return nil, err
+	}
+	return e.GetConfigLoaderData(configName)
+}
+
+func (l *Library) GetConfigLoaderData(configName string) ([]byte, error) {
+	l.mu.RLock()
+	defer l.mu.RUnlock()
 
-	if libHandle == 0 {
-		return nil, fmt.Errorf("C# 库未初始化")
+	if l.handle == 0 {
+		return nil, ErrNotInitialized
 	}
 
-	fnPtr, err := getCachedFunction(libHandle, "GetConfigLoaderDataCSharp")
+	fnPtr, err := l.getCachedFunction("GetConfigLoaderDataCSharp")
 	if err != nil {
-		return nil, fmt.Errorf("找不到函数: GetConfigLoaderDataCSharp - %w", err)
+		return nil, err
 	}
 
 	// 转换配置名称为字节数组
 	nameBytes := []byte(configName)
 
 	// 准备输出参数的指针
-	var outDataPtr uintptr
+	var outDataPtr unsafe.Pointer
 	var outDataLen int32
 
-	fn := unsafe.Pointer(fnPtr)
 	result, _, _ := purego.SyscallN(
-		uintptr(fn),
+		fnPtr,
 		uintptr(unsafe.Pointer(&nameBytes[0])),
 		uintptr(len(nameBytes)),
 		uintptr(unsafe.Pointer(&outDataPtr)),
@@ -896,16 +921,16 @@ func CallCSharpGetConfigLoaderData(configName string) ([]byte, error) {
 	)
 
 	if result != 0 {
-		return nil, fmt.Errorf("GetConfigLoaderDataCSharp 返回错误代码: %d", result)
+		return nil, exportError("GetConfigLoaderDataCSharp", int32(result), proto_pb.BattleErrorCode_INVALID_REQUEST)
 	}
 
 	// 从指针复制数据到 Go 切片
-	if outDataPtr == 0 || outDataLen <= 0 {
+	if outDataPtr == nil || outDataLen <= 0 {
 		return nil, nil
 	}
 
 	data := make([]byte, outDataLen)
-	copy(data, (*[1 << 30]byte)(unsafe.Pointer(outDataPtr))[:outDataLen:outDataLen])
+	copy(data, unsafe.Slice((*byte)(outDataPtr), outDataLen))
 
 	fmt.Printf("[Go] 通过 C# 获取配置数据: %s (%d 字节)\n", configName, outDataLen)
 
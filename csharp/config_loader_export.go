@@ -2,14 +2,14 @@ package csharp
 
 import (
 	"fmt"
+	"runtime"
 	"unsafe"
 
+	proto_pb "goPureWithCsharp/csharp/proto"
+
 	"github.com/ebitengine/purego"
 )
 
-// 全局保存所有回调指针，防止被 GC 回收
-var savedCallbacks []uintptr
-
 // RegisterConfigLoaderFunc Go 侧的配置加载器回调函数签名
 // 参数:
 //
@@ -39,29 +39,37 @@ type RegisterConfigLoaderFunc func(
 // ════════════════════════════════════════════════════════════════
 // 1. Go 侧定义全局函数：globalTestConfigReader(configNamePtr, configNameLen, outDataPtrPtr, outDataLenPtr) int32
 // 2. Go 侧调用此函数注册：RegisterConfigLoader(globalTestConfigReader)
-// 3. 此函数使用 purego.NewCallback 包装 Go 函数为 C 可调用的函数指针
+// 3. 此函数从回调注册表取得跳板 (C 可调用的函数指针)，跳板分发到 fn；重复注册只替换 fn
 // 4. 将函数指针通过 purego.SyscallN 传给 C# 的 RegisterConfigLoader 导出函数
 // 5. C# 侧使用 Marshal.GetDelegateForFunctionPointer 解析并存储这个回调
 // 6. 后续 Go 调用 C# 的 LoadConfig 时，C# 会调用这个回调来获取配置数据
 func RegisterConfigLoader(fn RegisterConfigLoaderFunc) error {
+	e, err := currentEngine()
+	if err != nil {
+		return err
+	}
+	return e.RegisterConfigLoader(fn)
+}
 
-	libMutex.RLock()
-	defer libMutex.RUnlock()
+func (l *Library) RegisterConfigLoader(fn RegisterConfigLoaderFunc) error {
 
-	if libHandle == 0 {
-		return fmt.Errorf("C# 库未初始化")
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+
+	if l.handle == 0 {
+		return ErrNotInitialized
 	}
 
 	// 获取 C# 侧的 RegisterConfigLoader 导出函数
-	rgPtr, err := purego.Dlsym(libHandle, "RegisterConfigLoader")
+	rgPtr, err := purego.Dlsym(l.handle, "RegisterConfigLoader")
+	if err != nil {
+		return fmt.Errorf("%w: RegisterConfigLoader - %w", ErrExportNotFound, err)
+	}
+	callbackPtr, err := bindCallback(l, "RegisterConfigLoader", configLoaderCallbacks, fn)
 	if err != nil {
-		return fmt.Errorf("找不到函数: RegisterConfigLoader - %w", err)
+		return err
 	}
-	// rgPtr, err := getCachedFunction(libHandle, "RegisterConfigLoader")
-	// if err != nil {
-	// 	return fmt.Errorf("找不到函数: RegisterConfigLoader - %w", err)
-	// }
-	callbackPtr := purego.NewCallback(fn)
+	l.saveCallback("RegisterConfigLoader", callbackPtr)
 	// 调用 C# 的 RegisterConfigLoader，将回调指针传过去
 	// 注意：现在返回类型是 void，所以只调用，不处理返回值
 	purego.SyscallN(
@@ -74,36 +82,62 @@ func RegisterConfigLoader(fn RegisterConfigLoaderFunc) error {
 	return nil
 }
 
+// UnregisterConfigLoader 注销配置加载器并归还回调跳板
+// 之后 C# 加载配置会失败，直到重新注册
+func UnregisterConfigLoader() error {
+	e, err := currentEngine()
+	if err != nil {
+		return err
+	}
+	return e.UnregisterConfigLoader()
+}
+
+func (l *Library) UnregisterConfigLoader() error {
+	return l.unregisterCallback("RegisterConfigLoader")
+}
+
 // LoadConfig 加载配置
 func LoadConfig(configName string) error {
+	e, err := currentEngine()
+	if err != nil {
+		return err
+	}
+	return e.LoadConfig(configName)
+}
+
+func (l *Library) LoadConfig(configName string) error {
 	// 首先检查是否有已注册的配置加载器（在 Go 侧）
 
-	libMutex.RLock()
-	defer libMutex.RUnlock()
+	l.mu.RLock()
+	defer l.mu.RUnlock()
 
-	if libHandle == 0 {
-		return fmt.Errorf("C# 库未初始化")
+	if l.handle == 0 {
+		return ErrNotInitialized
 	}
 
-	fnPtr, err := purego.Dlsym(libHandle, "LoadConfig")
+	fnPtr, err := purego.Dlsym(l.handle, "LoadConfig")
 	if err != nil {
-		return fmt.Errorf("找不到函数: LoadConfig - %w", err)
+		return fmt.Errorf("%w: LoadConfig - %w", ErrExportNotFound, err)
 	}
 
 	nameBytes := []byte(configName)
 	namePtr := unsafe.Pointer(&nameBytes[0])
 	nameLen := int32(len(nameBytes))
 
-	fn := unsafe.Pointer(fnPtr)
+	// 锁定 OS 线程，失败时在同一线程上取回托管异常
+	runtime.LockOSThread()
+	defer runtime.UnlockOSThread()
+
 	result, _, _ := purego.SyscallN(
-		uintptr(fn),
+		fnPtr,
 		uintptr(namePtr),
 		uintptr(nameLen),
 	)
 
 	if result != 0 {
-		return fmt.Errorf("LoadConfig 返回错误: %d", result)
+		return l.attachException(exportError("LoadConfig", int32(result), proto_pb.BattleErrorCode_INVALID_REQUEST))
 	}
+	l.saveConfigName(configName)
 	fmt.Printf("[Go] 配置已加载: %s\n", configName)
 	return nil
 }
@@ -0,0 +1,108 @@
+package csharp
+
+import (
+	"fmt"
+	"runtime"
+	"unsafe"
+
+	proto_pb "goPureWithCsharp/csharp/proto"
+
+	"github.com/ebitengine/purego"
+	"google.golang.org/protobuf/proto"
+)
+
+// ============================================================================
+// 批量输入 - 一次 FFI 调用提交一帧内收集的全部 BattleContext 输入
+// ============================================================================
+//
+// 逐条 ProcessBattleContextInput 每条输入都要跨越一次 FFI，数千场战斗时开销显著。
+// ProcessBattleContextBatch 序列化为一个 BattleContextBatch 提交，
+// 每条输入的结果以 BattleErrorCode 写入 Go 提供的 int32 数组，顺序与输入一致。
+//
+// C 签名:
+//
+//	int32_t ProcessBattleContextBatch(
+//	    const uint8_t* batch, int32_t batch_len,   // 序列化的 BattleContextBatch
+//	    int32_t* codes, int32_t codes_cap          // 出: 每条输入的 BattleErrorCode
+//	);
+//	返回: 处理的条数, -1 数据格式错误, -2 codes 容量不足, -3 托管异常
+
+// ProcessBattleContextBatch 在当前引擎上批量处理 BattleContext 输入
+// 返回每条输入的结果码，顺序与 contexts 一致
+func ProcessBattleContextBatch(contexts []*proto_pb.BattleContext) ([]proto_pb.BattleErrorCode, error) {
+	e, err := currentEngine()
+	if err != nil {
+		return nil, err
+	}
+	return e.ProcessBattleContextBatch(contexts)
+}
+
+// ProcessBattleContextBatch 批量处理 BattleContext 输入
+// 库不支持 FeatureContextBatch 时退回逐条调用 ProcessBattleContextInput
+func (l *Library) ProcessBattleContextBatch(contexts []*proto_pb.BattleContext) ([]proto_pb.BattleErrorCode, error) {
+	if len(contexts) == 0 {
+		return nil, nil
+	}
+	if !l.HasFeature(FeatureContextBatch) {
+		return processContextsOneByOne(l, contexts), nil
+	}
+
+	data, err := proto.Marshal(&proto_pb.BattleContextBatch{Contexts: contexts})
+	if err != nil {
+		return nil, fmt.Errorf("序列化批量输入失败: %w", err)
+	}
+
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+
+	if l.handle == 0 {
+		return nil, ErrNotInitialized
+	}
+
+	fnPtr, err := l.getCachedFunction("ProcessBattleContextBatch")
+	if err != nil {
+		return nil, err
+	}
+
+	codes := make([]int32, len(contexts))
+
+	// 锁定 OS 线程，失败时在同一线程上取回托管异常
+	runtime.LockOSThread()
+	defer runtime.UnlockOSThread()
+
+	result, _, _ := purego.SyscallN(
+		fnPtr,
+		uintptr(unsafe.Pointer(&data[0])),
+		uintptr(len(data)),
+		uintptr(unsafe.Pointer(&codes[0])),
+		uintptr(len(codes)),
+	)
+
+	processed := int32(result)
+	if processed < 0 {
+		return nil, l.attachException(exportError("ProcessBattleContextBatch", processed, contextBatchErrorCode(processed)))
+	}
+	if int(processed) != len(contexts) {
+		return nil, fmt.Errorf("批量输入结果数量不一致: 提交 %d 条, 返回 %d 条", len(contexts), processed)
+	}
+
+	results := make([]proto_pb.BattleErrorCode, len(codes))
+	for i, code := range codes {
+		results[i] = proto_pb.BattleErrorCode(code)
+	}
+	return results, nil
+}
+
+// processContextsOneByOne 逐条调用 ProcessBattleContextInput，兼容不支持批量输入的旧版本库
+func processContextsOneByOne(e Engine, contexts []*proto_pb.BattleContext) []proto_pb.BattleErrorCode {
+	codes := make([]proto_pb.BattleErrorCode, len(contexts))
+	for i, ctx := range contexts {
+		data, err := proto.Marshal(ctx)
+		if err != nil {
+			codes[i] = proto_pb.BattleErrorCode_INVALID_PROTO_FORMAT
+			continue
+		}
+		codes[i] = ErrorCode(e.ProcessBattleContextInput(data))
+	}
+	return codes
+}
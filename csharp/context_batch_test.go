@@ -0,0 +1,58 @@
+package csharp
+
+import (
+	"slices"
+	"testing"
+
+	proto_pb "goPureWithCsharp/csharp/proto"
+)
+
+// TestContextBatchCodes 测试批量输入按顺序返回每条输入的结果码，逐条退回路径结果一致
+func TestContextBatchCodes(t *testing.T) {
+	engine := NewSimEngine()
+	engine.DisableBattleLogging()
+	defer engine.Close()
+
+	if err := engine.CreateBattle(teamEnv(1, 100, 101, 0)); err != nil {
+		t.Fatalf("❌ 创建战斗失败: %v", err)
+	}
+
+	pause := &proto_pb.BattleInput{Input: &proto_pb.BattleInput_Pause{Pause: &proto_pb.BattlePause{}}}
+	resume := &proto_pb.BattleInput{Input: &proto_pb.BattleInput_Resume{Resume: &proto_pb.BattleResume{}}}
+	input := func(battleID uint32, in *proto_pb.BattleInput) *proto_pb.BattleContext {
+		return &proto_pb.BattleContext{
+			BattleId: battleID,
+			Option:   &proto_pb.BattleContext_BattleInput{BattleInput: in},
+		}
+	}
+	contexts := []*proto_pb.BattleContext{
+		input(1, pause),
+		input(2, pause),
+		{BattleId: 1}, // 不是输入
+		input(1, resume),
+	}
+
+	codes, err := engine.ProcessBattleContextBatch(contexts)
+	if err != nil {
+		t.Fatalf("❌ 批量输入失败: %v", err)
+	}
+	want := []proto_pb.BattleErrorCode{
+		proto_pb.BattleErrorCode_SUCCESS,
+		proto_pb.BattleErrorCode_BATTLE_NOT_FOUND,
+		proto_pb.BattleErrorCode_INVALID_REQUEST,
+		proto_pb.BattleErrorCode_SUCCESS,
+	}
+	if !slices.Equal(codes, want) {
+		t.Errorf("❌ 结果码错误: 期望 %v, 实际 %v", want, codes)
+	}
+
+	// 逐条退回路径: ProcessBattleContextInput 返回被拒绝输入的错误码
+	fallback := processContextsOneByOne(engine, contexts)
+	if !slices.Equal(fallback, want) {
+		t.Errorf("❌ 逐条提交结果码错误: 期望 %v, 实际 %v", want, fallback)
+	}
+
+	if codes, err := engine.ProcessBattleContextBatch(nil); err != nil || len(codes) != 0 {
+		t.Errorf("❌ 空批次应直接返回, 实际 %v, %v", codes, err)
+	}
+}
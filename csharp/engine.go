@@ -0,0 +1,107 @@
+package csharp
+
+import (
+	"sync"
+	"unsafe"
+
+	proto_pb "goPureWithCsharp/csharp/proto"
+)
+
+// ============================================================================
+// Engine - 战斗引擎后端抽象
+// ============================================================================
+
+// Engine 覆盖 C# 动态库导出的全部能力
+// 包级函数 (CreateBattle / OnTick / ProcessProtoMessage ...) 都转发到当前激活的 Engine
+//   - *Library:  通过 purego 调用 NativeAOT 编译的 .so，可同时打开多个
+//   - SimEngine: 纯 Go 模拟实现，无需 .NET SDK，用于测试
+type Engine interface {
+	// 低级 API
+	ProcessProtoMessage(requestData []byte) ([]byte, error)
+	ProcessBatchProtoMessage(requestData []byte) ([]byte, error)
+	RegisterCallback(callbackPtr unsafe.Pointer) error
+	TestNotifyCallback(notificationType int32, battleID int64, timestamp int64) (int32, error)
+
+	// 异常处理
+	CallPanic() error
+
+	// 回调注册 (C# -> Go)
+	RegisterConfigLoader(fn RegisterConfigLoaderFunc) error
+	LoadConfig(configName string) error
+	GetConfigLoaderData(configName string) ([]byte, error)
+	RegisterBattleEndNotify(fn RegisterNotifyCb) error
+	RegisterBattleResultCallback(fn BattleResultCallbackFunc) error
+	UnregisterConfigLoader() error
+	UnregisterBattleEndNotify() error
+
+	// 战斗管理
+	CreateBattle(env *proto_pb.BattleEnv) error
+	CreateShardBattle(shardID uint32, env *proto_pb.BattleEnv) error
+	DestroyBattle(battleId uint64) error
+	OnTick(frame uint64) (int32, error)
+	OnTickShard(shardID uint32, frame uint64) (int32, error)
+	GetBattleCount() (int32, error)
+	GetBattleStatus(battleID uint32) (*proto_pb.BattleStatus, error)
+	ListBattles() ([]*proto_pb.BattleStatus, error)
+	ProcessBattleInput(battleId uint32, teamId uint32, actionType byte, actionValue int32) error
+	ProcessBattleContextInput(input []byte) error
+	ProcessBattleContextBatch(contexts []*proto_pb.BattleContext) ([]proto_pb.BattleErrorCode, error)
+	AttachRingTransport(t *RingTransport) error
+
+	// 状态迁移 (热重载)
+	ExportBattleState() (*proto_pb.BattleStateSnapshot, error)
+	ImportBattleState(snapshot *proto_pb.BattleStateSnapshot) error
+
+	// 日志控制
+	SetBattleLogLevel(level int) error
+	GetBattleLogLevel() (int, error)
+	EnableBattleLogging() error
+	DisableBattleLogging() error
+
+	// 全局函数
+	CallGlobalFunction(functionName string, battleID uint32, notificationType int, timestamp int64) (int32, error)
+	CallSimpleGlobalFunction(battleID uint32, action string) (int32, error)
+
+	// 能力查询
+	HasExport(name string) bool
+	HasFeature(feature string) bool
+
+	// Health 健康时返回 nil；受监督调用超时后返回包装了 ErrUnhealthy 的错误
+	Health() error
+
+	// Close 释放引擎持有的资源
+	Close() error
+}
+
+var (
+	activeEngine Engine
+	engineMutex  sync.RWMutex
+)
+
+// UseEngine 切换当前激活的引擎，返回之前的引擎 (可能为 nil)
+// 旧引擎不会被关闭，由调用方决定其生命周期
+func UseEngine(e Engine) Engine {
+	engineMutex.Lock()
+	defer engineMutex.Unlock()
+
+	prev := activeEngine
+	activeEngine = e
+	return prev
+}
+
+// CurrentEngine 返回当前激活的引擎，未初始化时返回 nil
+func CurrentEngine() Engine {
+	engineMutex.RLock()
+	defer engineMutex.RUnlock()
+
+	return activeEngine
+}
+
+// currentEngine 获取当前引擎，未初始化时返回错误
+func currentEngine() (Engine, error) {
+	e := CurrentEngine()
+	if e == nil {
+		return nil, ErrNotInitialized
+	}
+	return e, nil
+}
@@ -0,0 +1,218 @@
+package csharp
+
+import (
+	"errors"
+	"fmt"
+
+	proto_pb "goPureWithCsharp/csharp/proto"
+)
+
+// ============================================================================
+// 错误模型 - BattleErrorCode 与 Go error 的映射
+// ============================================================================
+//
+// 每个 BattleErrorCode 对应一个哨兵错误，C# 返回的失败统一包装为 *BattleError:
+//
+//	if errors.Is(err, csharp.ErrDuplicateBattle) { ... }
+//
+//	var be *csharp.BattleError
+//	if errors.As(err, &be) && be.Retryable() { ... }
+//
+// IsRetryable 区分可重试 (TIMEOUT / INTERNAL_ERROR) 与永久性失败。
+
+// BattleErrorCode 对应的哨兵错误
+var (
+	ErrInvalidRequest     = errors.New("请求格式错误")
+	ErrTeamNotFound       = errors.New("队伍未找到")
+	ErrInvalidTeamSize    = errors.New("队伍大小无效")
+	ErrBattleNotFound     = errors.New("战斗未找到")
+	ErrDuplicateBattle    = errors.New("战斗重复")
+	ErrInternal           = errors.New("内部错误")
+	ErrTimeout            = errors.New("超时")
+	ErrInvalidProtoFormat = errors.New("Protobuf 格式错误")
+	ErrInvalidBattleState = errors.New("当前战斗状态不允许该操作")
+)
+
+// 与错误码无关的哨兵错误
+var (
+	// ErrNotInitialized 引擎未初始化或库已关闭
+	ErrNotInitialized = errors.New("C# 库未初始化")
+	// ErrExportNotFound 库中找不到导出函数
+	ErrExportNotFound = errors.New("找不到函数")
+	// ErrUnhealthy 引擎中有原生调用超时未返回，热重载前所有调用立即失败
+	ErrUnhealthy = errors.New("C# 库不健康")
+)
+
+// codeSentinels BattleErrorCode -> 哨兵错误
+var codeSentinels = map[proto_pb.BattleErrorCode]error{
+	proto_pb.BattleErrorCode_INVALID_REQUEST:      ErrInvalidRequest,
+	proto_pb.BattleErrorCode_TEAM_NOT_FOUND:       ErrTeamNotFound,
+	proto_pb.BattleErrorCode_INVALID_TEAM_SIZE:    ErrInvalidTeamSize,
+	proto_pb.BattleErrorCode_BATTLE_NOT_FOUND:     ErrBattleNotFound,
+	proto_pb.BattleErrorCode_DUPLICATE_BATTLE:     ErrDuplicateBattle,
+	proto_pb.BattleErrorCode_INTERNAL_ERROR:       ErrInternal,
+	proto_pb.BattleErrorCode_TIMEOUT:              ErrTimeout,
+	proto_pb.BattleErrorCode_INVALID_PROTO_FORMAT: ErrInvalidProtoFormat,
+	proto_pb.BattleErrorCode_INVALID_BATTLE_STATE: ErrInvalidBattleState,
+}
+
+// BattleError C# 侧返回的失败
+//   - 协议错误: BattleResponse.Code 非 0，Message 为 C# 返回的错误信息
+//   - 导出函数错误: 返回值非 0，Result 为原始返回值，Code 由调用方按导出函数语义映射
+type BattleError struct {
+	Op      string                   // 出错的导出函数或 API
+	Code    proto_pb.BattleErrorCode // 错误码
+	Message string                   // C# 返回的错误信息
+	Result  int32                    // 导出函数的原始返回值，协议错误时为 0
+
+	// Exception 导致这次失败的 C# 托管异常，没有时为 nil
+	Exception *ManagedException
+}
+
+func (e *BattleError) Error() string {
+	var s string
+	if e.Result != 0 {
+		s = fmt.Sprintf("%s 返回错误: %d (%s)", e.Op, e.Result, e.Code)
+	} else {
+		msg := e.Message
+		if msg == "" {
+			if sentinel := codeSentinels[e.Code]; sentinel != nil {
+				msg = sentinel.Error()
+			}
+		}
+		s = fmt.Sprintf("%s 返回错误 (Code=%d %s): %s", e.Op, int32(e.Code), e.Code, msg)
+	}
+	if e.Exception != nil {
+		s += ": " + e.Exception.Error()
+	}
+	return s
+}
+
+// Unwrap 返回错误码对应的哨兵错误和托管异常，使 errors.Is / errors.As 可用
+func (e *BattleError) Unwrap() []error {
+	errs := []error{}
+	if sentinel := codeSentinels[e.Code]; sentinel != nil {
+		errs = append(errs, sentinel)
+	}
+	if e.Exception != nil {
+		errs = append(errs, e.Exception)
+	}
+	return errs
+}
+
+// Retryable 相同请求稍后重试是否可能成功
+func (e *BattleError) Retryable() bool {
+	switch e.Code {
+	case proto_pb.BattleErrorCode_TIMEOUT, proto_pb.BattleErrorCode_INTERNAL_ERROR:
+		return true
+	}
+	return false
+}
+
+// IsRetryable 判断错误是否可重试
+// 只有 *BattleError 可能可重试，其他错误 (参数错误、库未初始化等) 都视为永久性失败
+func IsRetryable(err error) bool {
+	var be *BattleError
+	return errors.As(err, &be) && be.Retryable()
+}
+
+// ErrorCode 返回错误对应的 BattleErrorCode
+// err 为 nil 时返回 SUCCESS，无法识别时返回 INTERNAL_ERROR
+func ErrorCode(err error) proto_pb.BattleErrorCode {
+	if err == nil {
+		return proto_pb.BattleErrorCode_SUCCESS
+	}
+
+	var be *BattleError
+	if errors.As(err, &be) {
+		return be.Code
+	}
+	for code, sentinel := range codeSentinels {
+		if errors.Is(err, sentinel) {
+			return code
+		}
+	}
+	return proto_pb.BattleErrorCode_INTERNAL_ERROR
+}
+
+// responseError 将非 0 的 BattleResponse.Code 转换为 *BattleError
+func responseError(op string, resp *proto_pb.BattleResponse) error {
+	if resp.GetCode() == int32(proto_pb.BattleErrorCode_SUCCESS) {
+		return nil
+	}
+	return &BattleError{
+		Op:        op,
+		Code:      proto_pb.BattleErrorCode(resp.GetCode()),
+		Message:   resp.GetMessage(),
+		Exception: newManagedException(resp.GetException()),
+	}
+}
+
+// exportError 将导出函数的非 0 返回值转换为 *BattleError
+func exportError(op string, result int32, code proto_pb.BattleErrorCode) error {
+	return &BattleError{Op: op, Code: code, Result: result}
+}
+
+// importStateErrorCode C# ImportBattleState 返回值 -> 错误码
+//
+//	-1 战斗 ID 冲突, -2 数据格式错误
+func importStateErrorCode(result int32) proto_pb.BattleErrorCode {
+	if result == -2 {
+		return proto_pb.BattleErrorCode_INVALID_PROTO_FORMAT
+	}
+	return proto_pb.BattleErrorCode_DUPLICATE_BATTLE
+}
+
+// createBattleErrorCode C# CreateBattle 返回值 -> 错误码
+//
+//	-1 战斗 ID 冲突, -2 数据格式错误, -3 托管异常, 正数为 BattleEnv 被拒绝的 BattleErrorCode (例如 INVALID_TEAM_SIZE)
+func createBattleErrorCode(result int32) proto_pb.BattleErrorCode {
+	switch {
+	case result > 0:
+		return proto_pb.BattleErrorCode(result)
+	case result == -2:
+		return proto_pb.BattleErrorCode_INVALID_PROTO_FORMAT
+	case result == -3:
+		return proto_pb.BattleErrorCode_INTERNAL_ERROR
+	}
+	return proto_pb.BattleErrorCode_DUPLICATE_BATTLE
+}
+
+// contextBatchErrorCode C# ProcessBattleContextBatch 返回值 -> 错误码
+//
+//	-1 数据格式错误, -2 结果数组容量不足, -3 托管异常
+func contextBatchErrorCode(result int32) proto_pb.BattleErrorCode {
+	switch result {
+	case -1:
+		return proto_pb.BattleErrorCode_INVALID_PROTO_FORMAT
+	case -2:
+		return proto_pb.BattleErrorCode_INVALID_REQUEST
+	}
+	return proto_pb.BattleErrorCode_INTERNAL_ERROR
+}
+
+// contextInputErrorCode C# ProcessBattleContextInput 返回值 -> 错误码
+//
+//	-1 无效输入, -3 托管异常, 正数为输入被拒绝的 BattleErrorCode
+func contextInputErrorCode(result int32) proto_pb.BattleErrorCode {
+	switch {
+	case result > 0:
+		return proto_pb.BattleErrorCode(result)
+	case result == -3:
+		return proto_pb.BattleErrorCode_INTERNAL_ERROR
+	}
+	return proto_pb.BattleErrorCode_INVALID_REQUEST
+}
+
+// battleInputErrorCode C# BattleInputHandler.ProcessBattleInput 返回值 -> 错误码
+//
+//	-1 战斗不存在, -2 战斗已结束, -3 无效操作类型
+func battleInputErrorCode(result int32) proto_pb.BattleErrorCode {
+	switch result {
+	case -1:
+		return proto_pb.BattleErrorCode_BATTLE_NOT_FOUND
+	case -2:
+		return proto_pb.BattleErrorCode_INVALID_BATTLE_STATE
+	}
+	return proto_pb.BattleErrorCode_INVALID_REQUEST
+}
@@ -0,0 +1,96 @@
+package csharp
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	proto_pb "goPureWithCsharp/csharp/proto"
+)
+
+// TestBattleErrorMapping 测试每个错误码都能映射到哨兵错误，并区分可重试与永久性失败
+func TestBattleErrorMapping(t *testing.T) {
+	retryable := map[proto_pb.BattleErrorCode]bool{
+		proto_pb.BattleErrorCode_TIMEOUT:        true,
+		proto_pb.BattleErrorCode_INTERNAL_ERROR: true,
+	}
+
+	for value := range proto_pb.BattleErrorCode_name {
+		code := proto_pb.BattleErrorCode(value)
+		if code == proto_pb.BattleErrorCode_SUCCESS {
+			continue
+		}
+
+		sentinel, ok := codeSentinels[code]
+		if !ok {
+			t.Errorf("❌ 错误码 %s 没有对应的哨兵错误", code)
+			continue
+		}
+
+		err := fmt.Errorf("包装: %w", responseError("ExecBattle", &proto_pb.BattleResponse{Code: int32(code)}))
+		if !errors.Is(err, sentinel) {
+			t.Errorf("❌ %s: errors.Is 应匹配 %v", code, sentinel)
+		}
+		if got := ErrorCode(err); got != code {
+			t.Errorf("❌ ErrorCode 期望 %s, 实际 %s", code, got)
+		}
+		if IsRetryable(err) != retryable[code] {
+			t.Errorf("❌ %s: IsRetryable 期望 %v", code, retryable[code])
+		}
+	}
+
+	if err := responseError("ExecBattle", &proto_pb.BattleResponse{}); err != nil {
+		t.Errorf("❌ SUCCESS 不应返回错误: %v", err)
+	}
+	if got := ErrorCode(nil); got != proto_pb.BattleErrorCode_SUCCESS {
+		t.Errorf("❌ ErrorCode(nil) 期望 SUCCESS, 实际 %s", got)
+	}
+	if got := ErrorCode(fmt.Errorf("%w: 输入数据为空", ErrInvalidRequest)); got != proto_pb.BattleErrorCode_INVALID_REQUEST {
+		t.Errorf("❌ 包装哨兵错误的 ErrorCode 期望 INVALID_REQUEST, 实际 %s", got)
+	}
+	if IsRetryable(ErrNotInitialized) {
+		t.Errorf("❌ 库未初始化不应可重试")
+	}
+}
+
+// TestSimEngineTypedErrors 测试模拟引擎返回与 C# 库一致的类型化错误
+func TestSimEngineTypedErrors(t *testing.T) {
+	engine := NewSimEngine()
+	engine.DisableBattleLogging()
+	defer engine.Close()
+
+	if err := engine.CreateBattle(teamEnv(1, 100, 101, 0)); err != nil {
+		t.Fatalf("❌ 创建战斗失败: %v", err)
+	}
+
+	err := engine.CreateBattle(teamEnv(1, 100, 101, 0))
+	if !errors.Is(err, ErrDuplicateBattle) {
+		t.Errorf("❌ 重复创建战斗期望 ErrDuplicateBattle, 实际 %v", err)
+	}
+	var be *BattleError
+	if !errors.As(err, &be) || be.Op != "CreateBattle" || be.Result != -1 {
+		t.Errorf("❌ 期望 CreateBattle 返回 -1 的 *BattleError, 实际 %#v", be)
+	}
+
+	if err := engine.DestroyBattle(2); !errors.Is(err, ErrBattleNotFound) {
+		t.Errorf("❌ 销毁不存在的战斗期望 ErrBattleNotFound, 实际 %v", err)
+	}
+	if err := engine.ProcessBattleInput(2, 100, 0, 0); !errors.Is(err, ErrBattleNotFound) {
+		t.Errorf("❌ 向不存在的战斗输入期望 ErrBattleNotFound, 实际 %v", err)
+	}
+	if err := engine.ProcessBattleInput(1, 100, 3, 0); !errors.Is(err, ErrInvalidRequest) {
+		t.Errorf("❌ 无效操作类型期望 ErrInvalidRequest, 实际 %v", err)
+	}
+	pause := &proto_pb.BattleContext{BattleId: 1, Option: &proto_pb.BattleContext_BattleInput{
+		BattleInput: &proto_pb.BattleInput{Input: &proto_pb.BattleInput_Pause{Pause: &proto_pb.BattlePause{}}},
+	}}
+	if _, err := engine.ProcessBattleContextBatch([]*proto_pb.BattleContext{pause}); err != nil {
+		t.Fatalf("❌ 暂停战斗失败: %v", err)
+	}
+	if err := engine.ProcessBattleInput(1, 100, 0, 0); !errors.Is(err, ErrInvalidBattleState) {
+		t.Errorf("❌ 向暂停的战斗输入期望 ErrInvalidBattleState, 实际 %v", err)
+	}
+	if err := engine.SetBattleLogLevel(-1); IsRetryable(err) || !errors.Is(err, ErrInvalidRequest) {
+		t.Errorf("❌ 无效日志级别期望不可重试的 ErrInvalidRequest, 实际 %v", err)
+	}
+}
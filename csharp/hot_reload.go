@@ -0,0 +1,293 @@
+package csharp
+
+import (
+	"fmt"
+	"slices"
+	"unsafe"
+
+	proto_pb "goPureWithCsharp/csharp/proto"
+
+	"github.com/ebitengine/purego"
+	"google.golang.org/protobuf/proto"
+)
+
+// ============================================================================
+// 热重载 - 替换 C# 动态库而不丢失存活的战斗
+// ============================================================================
+//
+// 流程 (Library.Reload):
+//  1. 持有旧库写锁，阻塞其他调用
+//  2. ExportBattleState 导出所有战斗快照
+//  3. 打开新库并通过 validateLibrary 和 ABI 握手验证
+//  4. 用保存的注册参数在新库上重新注册回调、绑定环形缓冲区，并重新加载配置
+//  5. ImportBattleState 恢复战斗
+//  6. 全部成功后新库接管旧库的回调跳板，然后关闭旧库；任一步失败则关闭新库，旧库保持可用
+//
+// 旧库不健康 (有原生调用超时未返回) 时无法导出状态，也不能获取写锁:
+// 只打开新库并重新注册回调，旧库中的战斗丢失，旧库句柄保留不卸载
+
+const battleStateBufferSize = 10240 // ExportBattleState 初始缓冲区
+
+// callbackExports 回调注册导出函数，热重载时按此顺序重新注册
+// checkResult 表示导出函数返回 int (0 成功)，其余为 void
+// 全局异常回调在 openLibrary 中自动注册，不在此列
+var callbackExports = []struct {
+	name        string
+	checkResult bool
+}{
+	{"RegisterConfigLoader", false},
+	{"RegisterBattleResultCallback", true},
+	{"RegisterCallback", false},
+}
+
+// ExportBattleState 导出当前引擎中所有战斗的状态快照
+func ExportBattleState() (*proto_pb.BattleStateSnapshot, error) {
+	e, err := currentEngine()
+	if err != nil {
+		return nil, err
+	}
+	return e.ExportBattleState()
+}
+
+// ImportBattleState 将快照中的战斗恢复到当前引擎
+func ImportBattleState(snapshot *proto_pb.BattleStateSnapshot) error {
+	e, err := currentEngine()
+	if err != nil {
+		return err
+	}
+	return e.ImportBattleState(snapshot)
+}
+
+// ReloadCSharpLib 热重载当前激活的 C# 动态库
+// 新库替换成功后才切换当前引擎并关闭旧库
+func ReloadCSharpLib(opts LoadOptions) error {
+	lib, ok := CurrentEngine().(*Library)
+	if !ok {
+		return fmt.Errorf("当前引擎不是 C# 动态库，无法热重载")
+	}
+
+	_, err := lib.reload(opts, func(next *Library) {
+		UseEngine(next)
+	})
+	return err
+}
+
+func (l *Library) ExportBattleState() (*proto_pb.BattleStateSnapshot, error) {
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+
+	if l.handle == 0 {
+		return nil, ErrNotInitialized
+	}
+	return l.exportBattleState()
+}
+
+// exportBattleState 调用方需持有 l.mu
+func (l *Library) exportBattleState() (*proto_pb.BattleStateSnapshot, error) {
+	fnPtr, err := l.getCachedFunction("ExportBattleState")
+	if err != nil {
+		return nil, err
+	}
+
+	data, err := negotiateResponse("ExportBattleState", battleStateBufferSize, func(buf []byte) int32 {
+		bufLen := int32(len(buf))
+		purego.SyscallN(
+			fnPtr,
+			uintptr(unsafe.Pointer(&buf[0])),
+			uintptr(unsafe.Pointer(&bufLen)),
+		)
+		return bufLen
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	snapshot := &proto_pb.BattleStateSnapshot{}
+	if err := proto.Unmarshal(data, snapshot); err != nil {
+		return nil, fmt.Errorf("战斗状态反序列化失败: %w", err)
+	}
+	return snapshot, nil
+}
+
+func (l *Library) ImportBattleState(snapshot *proto_pb.BattleStateSnapshot) error {
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+
+	if l.handle == 0 {
+		return ErrNotInitialized
+	}
+
+	fnPtr, err := l.getCachedFunction("ImportBattleState")
+	if err != nil {
+		return err
+	}
+
+	data, err := proto.Marshal(snapshot)
+	if err != nil {
+		return fmt.Errorf("战斗状态序列化失败: %w", err)
+	}
+	if len(data) == 0 {
+		// 空快照，没有需要恢复的战斗
+		return nil
+	}
+
+	result, _, _ := purego.SyscallN(
+		fnPtr,
+		uintptr(unsafe.Pointer(&data[0])),
+		uintptr(len(data)),
+	)
+
+	imported := int32(result)
+	if imported < 0 {
+		return exportError("ImportBattleState", imported, importStateErrorCode(imported))
+	}
+	if int(imported) != len(snapshot.GetBattles()) {
+		return fmt.Errorf("%w: ImportBattleState 导入数量不符: %d/%d", ErrInternal, imported, len(snapshot.GetBattles()))
+	}
+	return nil
+}
+
+// Reload 打开 opts 指定的新库，迁移所有战斗和回调后关闭当前库
+// 成功时返回新库，当前库不再可用；失败时当前库保持不变
+// 新库必须是不同的文件路径，dlopen 对同一路径会返回已加载的句柄
+func (l *Library) Reload(opts LoadOptions) (*Library, error) {
+	return l.reload(opts, nil)
+}
+
+// reload 执行热重载，swap 在关闭旧库之前调用，用于切换引用
+func (l *Library) reload(opts LoadOptions, swap func(next *Library)) (*Library, error) {
+	if cause := l.Health(); cause != nil {
+		return l.replaceUnhealthy(opts, swap, cause)
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if l.handle == 0 {
+		return nil, ErrNotInitialized
+	}
+	if !l.HasFeature(FeatureHotReload) {
+		return nil, fmt.Errorf("当前库不支持热重载: %s", l.path)
+	}
+
+	// 1. 导出战斗快照
+	snapshot, err := l.exportBattleState()
+	if err != nil {
+		return nil, fmt.Errorf("导出战斗状态失败: %w", err)
+	}
+
+	// 2. 打开并验证新库
+	next, err := OpenLibraryWithOptions(opts)
+	if err != nil {
+		return nil, err
+	}
+	if next.handle == l.handle {
+		_ = next.Close()
+		return nil, fmt.Errorf("新库与当前库是同一个已加载的实例: %s", next.path)
+	}
+	if !next.HasFeature(FeatureHotReload) {
+		_ = next.Close()
+		return nil, fmt.Errorf("新库不支持热重载: %s", next.path)
+	}
+
+	// 3. 重新注册回调和配置
+	if err := next.restoreRegistrations(l); err != nil {
+		_ = next.Close()
+		return nil, err
+	}
+
+	// 4. 恢复战斗
+	if err := next.ImportBattleState(snapshot); err != nil {
+		_ = next.Close()
+		return nil, fmt.Errorf("恢复战斗状态失败: %w", err)
+	}
+	next.adoptCallbacks(l)
+
+	if swap != nil {
+		swap(next)
+	}
+
+	// 5. 替换成功，关闭旧库
+	fmt.Printf("[Go] 热重载完成: %s -> %s, 迁移 %d 场战斗\n", l.path, next.path, len(snapshot.GetBattles()))
+	if err := l.closeLocked(); err != nil {
+		return next, fmt.Errorf("关闭旧库失败: %w", err)
+	}
+	return next, nil
+}
+
+// replaceUnhealthy 用新库替换不健康的库
+// 卡住的调用仍持有 l.mu 读锁，这里不获取写锁，也不卸载旧库
+// 战斗无法从旧库导出，新库中没有它们，调用方需丢弃自己仍记录的战斗
+func (l *Library) replaceUnhealthy(opts LoadOptions, swap func(next *Library), cause error) (*Library, error) {
+	next, err := OpenLibraryWithOptions(opts)
+	if err != nil {
+		return nil, err
+	}
+	if next.handle == l.handle {
+		// 同一个已加载的实例，卡住的调用仍在其中；Close 只减少引用计数
+		_ = next.Close()
+		return nil, fmt.Errorf("新库与当前库是同一个已加载的实例: %s", next.path)
+	}
+
+	if err := next.restoreRegistrations(l); err != nil {
+		_ = next.Close()
+		return nil, err
+	}
+	next.adoptCallbacks(l)
+
+	if swap != nil {
+		swap(next)
+	}
+
+	fmt.Printf("[Go] 热重载完成: %s -> %s, 旧库不健康，战斗未迁移 (%v)\n", l.path, next.path, cause)
+	return next, nil
+}
+
+// restoreRegistrations 用 prev 保存的注册参数在当前库上重新注册回调、绑定环形缓冲区并加载配置
+// Go 侧的回调指针是注册表中的跳板，不随动态库卸载失效，可以直接复用
+// 跳板仍归 prev 所有，重载成功后由 adoptCallbacks 转交，失败时关闭新库不会归还它们
+func (l *Library) restoreRegistrations(prev *Library) error {
+	prev.callbackMu.Lock()
+	callbacks := make(map[string][]uintptr, len(prev.callbacks))
+	for name, args := range prev.callbacks {
+		callbacks[name] = args
+	}
+	configNames := slices.Clone(prev.configNames)
+	transport := prev.transport
+	prev.callbackMu.Unlock()
+
+	l.mu.RLock()
+	for _, export := range callbackExports {
+		args, ok := callbacks[export.name]
+		if !ok {
+			continue
+		}
+
+		fnPtr, err := l.getCachedFunction(export.name)
+		if err != nil {
+			l.mu.RUnlock()
+			return fmt.Errorf("重新注册回调失败: %w", err)
+		}
+
+		result, _, _ := purego.SyscallN(fnPtr, args...)
+		if export.checkResult && result != 0 {
+			l.mu.RUnlock()
+			return fmt.Errorf("重新注册回调失败: %w", exportError(export.name, int32(result), proto_pb.BattleErrorCode_INVALID_REQUEST))
+		}
+		l.saveCallback(export.name, args...)
+	}
+	l.mu.RUnlock()
+
+	if transport != nil {
+		if err := l.AttachRingTransport(transport); err != nil {
+			return fmt.Errorf("重新绑定环形缓冲区失败: %w", err)
+		}
+	}
+
+	for _, configName := range configNames {
+		if err := l.LoadConfig(configName); err != nil {
+			return fmt.Errorf("重新加载配置失败: %w", err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,149 @@
+package csharp
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"sync"
+
+	proto_pb "goPureWithCsharp/csharp/proto"
+
+	"google.golang.org/protobuf/encoding/protodelim"
+)
+
+// ============================================================================
+// 战斗日志 - 记录跨越 Go/C# 边界的每一条 BattleContext
+// ============================================================================
+//
+// 每场战斗一个文件，内容是只追加的长度前缀 (varint) JournalEntry 流:
+//
+//	env     创建战斗，frame 为战斗执行第一回合的帧
+//	context 输入 (Tick 为应用它的帧) 或输出 (Tick 为产生它的帧)
+//
+// ReadJournal 读回后得到 BattleRecord 和记录的输出，可用 ReplayBattle 重新执行并比较。
+
+// JournalWriter 单场战斗的日志文件
+type JournalWriter struct {
+	mu   sync.Mutex
+	file *os.File
+	w    *bufio.Writer
+}
+
+// CreateJournal 创建 path 处的日志文件，已存在时清空，之后只追加
+func CreateJournal(path string) (*JournalWriter, error) {
+	f, err := os.OpenFile(path, os.O_WRONLY|os.O_APPEND|os.O_CREATE|os.O_TRUNC, 0o644)
+	if err != nil {
+		return nil, fmt.Errorf("打开战斗日志失败: %w", err)
+	}
+	return &JournalWriter{file: f, w: bufio.NewWriter(f)}, nil
+}
+
+// WriteEnv 记录创建战斗的环境，startFrame 为战斗执行第一回合的帧
+func (j *JournalWriter) WriteEnv(env *proto_pb.BattleEnv, startFrame uint64) error {
+	return j.write(&proto_pb.JournalEntry{
+		Frame: startFrame,
+		Entry: &proto_pb.JournalEntry_Env{Env: env},
+	})
+}
+
+// WriteContext 记录一条输入或输出
+func (j *JournalWriter) WriteContext(ctx *proto_pb.BattleContext) error {
+	return j.write(&proto_pb.JournalEntry{
+		Frame: ctx.GetTick(),
+		Entry: &proto_pb.JournalEntry_Context{Context: ctx},
+	})
+}
+
+func (j *JournalWriter) write(entry *proto_pb.JournalEntry) error {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	if j.file == nil {
+		return fmt.Errorf("战斗日志已关闭")
+	}
+	if _, err := protodelim.MarshalTo(j.w, entry); err != nil {
+		return fmt.Errorf("写入战斗日志失败: %w", err)
+	}
+	return nil
+}
+
+// Flush 将缓冲的条目写入文件
+func (j *JournalWriter) Flush() error {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	if j.file == nil {
+		return nil
+	}
+	return j.w.Flush()
+}
+
+// Close 写入缓冲的条目并关闭文件
+func (j *JournalWriter) Close() error {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	if j.file == nil {
+		return nil
+	}
+	err := errors.Join(j.w.Flush(), j.file.Close())
+	j.file = nil
+	return err
+}
+
+// Journal 读回的单场战斗日志
+type Journal struct {
+	BattleRecord
+	Outputs []*proto_pb.BattleContext // 记录的输出，按写入顺序
+}
+
+// ReadJournal 从 r 读取一场战斗的日志
+// 末尾不完整的条目 (写入中途进程退出) 被忽略
+func ReadJournal(r io.Reader) (*Journal, error) {
+	br := bufio.NewReader(r)
+	j := &Journal{}
+	for {
+		entry := &proto_pb.JournalEntry{}
+		err := protodelim.UnmarshalFrom(br, entry)
+		if err == io.EOF || errors.Is(err, io.ErrUnexpectedEOF) {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("读取战斗日志失败: %w", err)
+		}
+
+		switch e := entry.Entry.(type) {
+		case *proto_pb.JournalEntry_Env:
+			if j.Env != nil {
+				return nil, fmt.Errorf("战斗日志中有多个战斗环境")
+			}
+			j.Env = e.Env
+			j.StartFrame = entry.GetFrame()
+		case *proto_pb.JournalEntry_Context:
+			switch e.Context.Option.(type) {
+			case *proto_pb.BattleContext_BattleInput:
+				j.Inputs = append(j.Inputs, e.Context)
+			case *proto_pb.BattleContext_BattleOutput:
+				j.Outputs = append(j.Outputs, e.Context)
+			}
+		}
+	}
+
+	if j.Env == nil {
+		return nil, fmt.Errorf("战斗日志中没有战斗环境")
+	}
+	return j, nil
+}
+
+// LoadJournal 读取 path 处的战斗日志
+func LoadJournal(path string) (*Journal, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("打开战斗日志失败: %w", err)
+	}
+	defer f.Close()
+
+	return ReadJournal(f)
+}
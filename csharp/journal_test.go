@@ -0,0 +1,63 @@
+package csharp
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	proto_pb "goPureWithCsharp/csharp/proto"
+
+	"google.golang.org/protobuf/proto"
+)
+
+// TestJournalRoundTrip 测试日志写入后读回，末尾不完整的条目被忽略
+func TestJournalRoundTrip(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "battle_7.journal")
+	w, err := CreateJournal(path)
+	if err != nil {
+		t.Fatalf("❌ 创建日志失败: %v", err)
+	}
+
+	env := &proto_pb.BattleEnv{BattleId: 7, Atk: &proto_pb.Team{TeamId: 100}, Def: &proto_pb.Team{TeamId: 101}, Seed: 42}
+	input := &proto_pb.BattleContext{
+		BattleId: 7,
+		Tick:     12,
+		Option: &proto_pb.BattleContext_BattleInput{
+			BattleInput: &proto_pb.BattleInput{Input: &proto_pb.BattleInput_Pause{Pause: &proto_pb.BattlePause{}}},
+		},
+	}
+	output := &proto_pb.BattleContext{
+		BattleId: 7,
+		Tick:     20,
+		Option: &proto_pb.BattleContext_BattleOutput{
+			BattleOutput: &proto_pb.BattleOutput{Output: &proto_pb.BattleOutput_Result{Result: &proto_pb.BattleResult{Winner: 100}}},
+		},
+	}
+	for _, err := range []error{w.WriteEnv(env, 10), w.WriteContext(input), w.WriteContext(output), w.Close()} {
+		if err != nil {
+			t.Fatalf("❌ 写入日志失败: %v", err)
+		}
+	}
+	if err := w.WriteContext(output); err == nil {
+		t.Errorf("❌ 关闭后写入应失败")
+	}
+
+	// 模拟写入中途退出: 末尾追加半条记录
+	f, _ := os.OpenFile(path, os.O_WRONLY|os.O_APPEND, 0)
+	f.Write([]byte{0x20, 0x01})
+	f.Close()
+
+	j, err := LoadJournal(path)
+	if err != nil {
+		t.Fatalf("❌ 读取日志失败: %v", err)
+	}
+	if !proto.Equal(j.Env, env) || j.StartFrame != 10 {
+		t.Errorf("❌ 战斗环境错误: %v, 开始帧 %d", j.Env, j.StartFrame)
+	}
+	if len(j.Inputs) != 1 || !proto.Equal(j.Inputs[0], input) {
+		t.Errorf("❌ 输入错误: %v", j.Inputs)
+	}
+	if len(j.Outputs) != 1 || !proto.Equal(j.Outputs[0], output) {
+		t.Errorf("❌ 输出错误: %v", j.Outputs)
+	}
+}
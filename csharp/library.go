@@ -0,0 +1,223 @@
+package csharp
+
+import (
+	"fmt"
+	"slices"
+	"sync"
+
+	proto_pb "goPureWithCsharp/csharp/proto"
+
+	"github.com/ebitengine/purego"
+)
+
+// ============================================================================
+// Library - 单个已加载的 C# 动态库实例
+// ============================================================================
+
+// Library 一个独立加载的 C# 动态库
+// 每个实例拥有自己的库句柄、函数指针缓存、回调和生命周期
+// 多个 Library 可以并存 (例如 Release 与 Debug 同时加载)，互不影响
+type Library struct {
+	path string
+
+	mu     sync.RWMutex // 保护 handle，调用期间持读锁，关闭时持写锁
+	handle uintptr
+
+	// 函数指针缓存 - 加速 SyscallN 调用
+	fnCache sync.Map
+
+	// 握手时读取的库信息，之后只读
+	info *proto_pb.LibraryInfo
+
+	// 受监督调用超时后标记为不健康，之后的调用立即失败
+	health
+
+	// 已注册给 C# 的回调，按导出函数名保存注册参数，防止被 GC 回收
+	// 热重载时用这些参数在新库上重新注册
+	callbackMu  sync.Mutex
+	callbacks   map[string][]uintptr
+	slots       map[string]releasableCallback // 该库持有的回调跳板，见 callback_registry.go
+	configNames []string                      // 成功加载过的配置 (按首次加载顺序)，热重载时依次重新加载
+	transport   *RingTransport                // 已绑定的环形缓冲区，热重载时绑定到新库
+}
+
+var _ Engine = (*Library)(nil)
+
+// OpenLibrary 打开指定路径的 C# 动态库并验证导出函数
+// 使用 RTLD_NOW|RTLD_LOCAL 加载，同名导出符号不会在多个库之间互相覆盖
+func OpenLibrary(libPath string) (*Library, error) {
+	return openLibrary(libPath, defaultDlopenFlags)
+}
+
+// OpenLibraryVersion 按版本 (Release / Debug) 查找 TestExport_<version>.so 并打开
+// 查找规则见 LoadOptions
+func OpenLibraryVersion(version string) (*Library, error) {
+	return OpenLibraryWithOptions(LoadOptions{Version: version})
+}
+
+// openLibrary 以指定 dlopen 标志打开库并验证导出函数
+func openLibrary(libPath string, flags int) (*Library, error) {
+	handle, err := purego.Dlopen(libPath, flags)
+	if err != nil {
+		return nil, fmt.Errorf("打开库失败: %s - %w", libPath, err)
+	}
+
+	l := &Library{
+		path:      libPath,
+		handle:    handle,
+		callbacks: make(map[string][]uintptr),
+		slots:     make(map[string]releasableCallback),
+	}
+	fmt.Printf("[Go] C# 库已加载: %s (handle=%d)\n", libPath, handle)
+
+	// 验证库中所有必需的导出函数
+	if err := validateLibrary(l); err != nil {
+		_ = l.Close()
+		return nil, err
+	}
+
+	// 校验 ABI 版本和 proto schema
+	if err := l.handshake(); err != nil {
+		_ = l.Close()
+		return nil, err
+	}
+
+	// 注册全局异常回调，OnTick 等导出函数中不中断调用的异常通过它报告
+	if err := l.injectExceptionCallback(); err != nil {
+		_ = l.Close()
+		return nil, err
+	}
+	return l, nil
+}
+
+// Path 返回库文件路径
+func (l *Library) Path() string {
+	return l.path
+}
+
+// Loaded 库是否仍处于加载状态
+func (l *Library) Loaded() bool {
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+
+	return l.handle != 0
+}
+
+// Close 卸载动态库，清空函数指针缓存和回调
+// 重复调用是安全的
+// 不健康的库中仍有原生调用未返回，不会被卸载
+func (l *Library) Close() error {
+	if err := l.Health(); err != nil {
+		return fmt.Errorf("不能卸载仍在执行原生调用的库: %s - %w", l.path, err)
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	return l.closeLocked()
+}
+
+// closeLocked 卸载动态库，调用方需持有 l.mu 写锁
+func (l *Library) closeLocked() error {
+	if l.handle == 0 {
+		// 库已关闭，不是错误
+		return nil
+	}
+
+	// 关闭库
+	err := purego.Dlclose(l.handle)
+	l.handle = 0
+
+	// 清空函数指针缓存和回调，归还回调跳板
+	l.clearFunctionCache()
+	l.callbackMu.Lock()
+	clear(l.callbacks)
+	l.releaseCallbacksLocked()
+	l.transport = nil
+	l.configNames = nil
+	l.callbackMu.Unlock()
+
+	if err != nil {
+		// 记录错误但继续，因为库句柄已经清空
+		fmt.Printf("[Go] 关闭库时出错: %s - %v\n", l.path, err)
+		return nil
+	}
+
+	fmt.Printf("[Go] C# 库已关闭: %s\n", l.path)
+	return nil
+}
+
+// ============================================================================
+// 函数指针缓存和加速机制
+// ============================================================================
+
+// getCachedFunction 从缓存获取函数指针，如果不存在则加载并缓存
+// 这个函数加快了重复调用的速度，避免每次都调用 Dlsym
+// 调用方需持有 l.mu 读锁；库不健康时直接返回错误，使所有导出函数调用快速失败
+func (l *Library) getCachedFunction(funcName string) (uintptr, error) {
+	if err := l.Health(); err != nil {
+		return 0, err
+	}
+
+	// 先尝试从缓存读取
+	if fnPtr, ok := l.fnCache.Load(funcName); ok {
+		return fnPtr.(uintptr), nil
+	}
+
+	// 缓存未命中，从库中加载函数指针
+	fnPtr, err := purego.Dlsym(l.handle, funcName)
+	if err != nil {
+		return 0, fmt.Errorf("%w: %s - %w", ErrExportNotFound, funcName, err)
+	}
+
+	// 写入缓存（sync.Map 内部自动处理并发）
+	l.fnCache.Store(funcName, fnPtr)
+	return fnPtr, nil
+}
+
+// clearFunctionCache 清空函数指针缓存
+// 在库卸载时调用
+func (l *Library) clearFunctionCache() {
+	l.fnCache.Clear()
+}
+
+// saveCallback 保存已注册给 C# 的回调及其注册参数 (第一个参数为回调指针)
+// 同名回调重新注册时覆盖旧值
+func (l *Library) saveCallback(exportName string, args ...uintptr) {
+	l.callbackMu.Lock()
+	defer l.callbackMu.Unlock()
+
+	l.callbacks[exportName] = args
+}
+
+// saveConfigName 记录成功加载的配置名
+func (l *Library) saveConfigName(configName string) {
+	l.callbackMu.Lock()
+	defer l.callbackMu.Unlock()
+
+	if !slices.Contains(l.configNames, configName) {
+		l.configNames = append(l.configNames, configName)
+	}
+}
+
+// validateLibrary 验证 SO 文件是否包含所有必需的导出函数
+// 在库初始化时调用，确保 SO 文件完整
+func validateLibrary(l *Library) error {
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+
+	missingFuncs := []string{}
+
+	for _, funcName := range requiredFuncs {
+		if _, err := l.getCachedFunction(funcName); err != nil {
+			missingFuncs = append(missingFuncs, funcName)
+		}
+	}
+
+	if len(missingFuncs) > 0 {
+		return fmt.Errorf("SO 文件缺少以下导出函数: %v", missingFuncs)
+	}
+
+	fmt.Println("[Go] SO 文件验证成功，所有必需函数已找到")
+	return nil
+}
@@ -0,0 +1,186 @@
+package csharp
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/ebitengine/purego"
+)
+
+// ============================================================================
+// LoadOptions - 动态库查找与加载选项
+// ============================================================================
+
+const (
+	// DefaultLibraryEnvVar 默认的库路径覆盖环境变量
+	// 值可以是 .so 文件路径，也可以是包含 TestExport_<version>.so 的目录
+	DefaultLibraryEnvVar = "CSHARP_LIB_PATH"
+
+	defaultDlopenFlags = purego.RTLD_NOW | purego.RTLD_LOCAL
+)
+
+// LoadOptions 控制 C# 动态库的查找路径和 dlopen 标志
+//
+// 查找顺序 (命中第一个可成功打开的路径即停止):
+//  1. 环境变量覆盖 (EnvVar，默认 CSHARP_LIB_PATH)；设置后只尝试该路径，打开失败时不再查找其他路径
+//  2. Path 指定的库文件；设置 Path 后不再查找目录
+//  3. SearchDirs 中的 TestExport_<version>.so；为空时使用 lib、../lib、../../lib
+//  4. LD_LIBRARY_PATH 中的各目录
+type LoadOptions struct {
+	Version    string   // 库版本 (Release / Debug)，默认 Release
+	Path       string   // 库文件路径，通常为绝对路径
+	SearchDirs []string // 查找目录列表，按顺序尝试
+
+	EnvVar              string // 覆盖用的环境变量名，默认 DefaultLibraryEnvVar
+	IgnoreEnv           bool   // 忽略环境变量覆盖
+	IgnoreLDLibraryPath bool   // 不查找 LD_LIBRARY_PATH
+
+	Flags int // dlopen 标志，默认 RTLD_NOW|RTLD_LOCAL
+}
+
+// fileName 返回版本对应的库文件名
+func (o LoadOptions) fileName() string {
+	version := o.Version
+	if version == "" {
+		version = "Release"
+	}
+	return fmt.Sprintf("TestExport_%s.so", version)
+}
+
+// candidates 按查找顺序生成候选路径 (已去重)
+func (o LoadOptions) candidates() []string {
+	fileName := o.fileName()
+	paths := []string{}
+	seen := map[string]bool{}
+	add := func(p string) {
+		if p != "" && !seen[p] {
+			seen[p] = true
+			paths = append(paths, p)
+		}
+	}
+
+	// 1. 环境变量覆盖
+	if !o.IgnoreEnv {
+		envVar := o.EnvVar
+		if envVar == "" {
+			envVar = DefaultLibraryEnvVar
+		}
+		if v := os.Getenv(envVar); v != "" {
+			if info, err := os.Stat(v); err == nil && info.IsDir() {
+				add(filepath.Join(v, fileName))
+			} else {
+				add(v)
+			}
+			return paths
+		}
+	}
+
+	// 2. 显式路径
+	if o.Path != "" {
+		add(o.Path)
+		return paths
+	}
+
+	// 3. 查找目录
+	dirs := o.SearchDirs
+	if len(dirs) == 0 {
+		dirs = defaultSearchDirs()
+	}
+	for _, dir := range dirs {
+		add(filepath.Join(dir, fileName))
+	}
+
+	// 4. LD_LIBRARY_PATH
+	if !o.IgnoreLDLibraryPath {
+		for _, dir := range filepath.SplitList(os.Getenv("LD_LIBRARY_PATH")) {
+			if dir != "" {
+				add(filepath.Join(dir, fileName))
+			}
+		}
+	}
+	return paths
+}
+
+// defaultSearchDirs 默认查找目录：当前目录及向上两级的 lib 目录
+// 适用于从项目根目录或 cmd/test 等子目录运行的情况
+func defaultSearchDirs() []string {
+	dirs := []string{"lib"}
+	dir, err := os.Getwd()
+	if err != nil {
+		return dirs
+	}
+	for i := 0; i < 2; i++ {
+		dir = filepath.Dir(dir)
+		dirs = append(dirs, filepath.Join(dir, "lib"))
+	}
+	return dirs
+}
+
+// LoadAttempt 一次加载尝试的路径和失败原因
+type LoadAttempt struct {
+	Path string
+	Err  error
+}
+
+// LibraryLoadError 所有候选路径都加载失败时返回，列出每一次尝试
+type LibraryLoadError struct {
+	Attempts []LoadAttempt
+}
+
+func (e *LibraryLoadError) Error() string {
+	if len(e.Attempts) == 0 {
+		return "加载 C# 库失败: 没有可尝试的路径"
+	}
+
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "加载 C# 库失败，已尝试 %d 个路径:", len(e.Attempts))
+	for _, a := range e.Attempts {
+		fmt.Fprintf(&sb, "\n  - %s: %v", a.Path, a.Err)
+	}
+	return sb.String()
+}
+
+// Unwrap 返回每次尝试的错误，便于 errors.Is 判断 (例如 os.ErrNotExist)
+func (e *LibraryLoadError) Unwrap() []error {
+	errs := make([]error, 0, len(e.Attempts))
+	for _, a := range e.Attempts {
+		errs = append(errs, a.Err)
+	}
+	return errs
+}
+
+// OpenLibraryWithOptions 按选项查找并打开 C# 动态库
+// 全部候选路径失败时返回 *LibraryLoadError
+func OpenLibraryWithOptions(opts LoadOptions) (*Library, error) {
+	flags := opts.Flags
+	if flags == 0 {
+		flags = defaultDlopenFlags
+	}
+
+	loadErr := &LibraryLoadError{}
+	for _, path := range opts.candidates() {
+		if _, err := os.Stat(path); err != nil {
+			loadErr.Attempts = append(loadErr.Attempts, LoadAttempt{Path: path, Err: err})
+			continue
+		}
+
+		lib, err := openLibrary(path, flags)
+		if err != nil {
+			loadErr.Attempts = append(loadErr.Attempts, LoadAttempt{Path: path, Err: err})
+			continue
+		}
+		return lib, nil
+	}
+	return nil, loadErr
+}
+
+// InitCSharpLibWithOptions 按选项打开 C# 动态库并设为当前激活的引擎
+func InitCSharpLibWithOptions(opts LoadOptions) error {
+	lib, err := OpenLibraryWithOptions(opts)
+	if err != nil {
+		return err
+	}
+	return activateLibrary(lib)
+}
@@ -0,0 +1,180 @@
+package csharp
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+)
+
+// TestOpenLibraryMissing 测试打开不存在的库文件返回错误且不影响当前引擎
+func TestOpenLibraryMissing(t *testing.T) {
+	prev := CurrentEngine()
+
+	if _, err := OpenLibrary("/nonexistent/TestExport_Missing.so"); err == nil {
+		t.Fatalf("❌ 打开不存在的库应返回错误")
+	}
+	if _, err := OpenLibraryVersion("Missing"); err == nil {
+		t.Fatalf("❌ 查找不存在的版本应返回错误")
+	}
+	if CurrentEngine() != prev {
+		t.Errorf("❌ 打开失败不应替换当前引擎")
+	}
+}
+
+// TestOpenLibrarySideBySide 测试 Release 与 Debug 两个库实例并存、独立关闭
+func TestOpenLibrarySideBySide(t *testing.T) {
+	release, err := OpenLibraryVersion("Release")
+	if err != nil {
+		t.Skipf("跳过: Release 库不可用: %v", err)
+	}
+	defer release.Close()
+
+	debug, err := OpenLibraryVersion("Debug")
+	if err != nil {
+		t.Skipf("跳过: Debug 库不可用: %v", err)
+	}
+	defer debug.Close()
+
+	if err := release.CreateBattle(teamEnv(70001, 1, 2, 0)); err != nil {
+		t.Fatalf("❌ Release 创建战斗失败: %v", err)
+	}
+	defer release.DestroyBattle(70001)
+
+	// Debug 库拥有独立的托管运行时，看不到 Release 中的战斗
+	if count, err := debug.GetBattleCount(); err != nil || count != 0 {
+		t.Errorf("❌ Debug 战斗数量期望 0, 实际 %d (err=%v)", count, err)
+	}
+
+	if err := debug.Close(); err != nil {
+		t.Fatalf("❌ 关闭 Debug 库失败: %v", err)
+	}
+	if debug.Loaded() {
+		t.Errorf("❌ 关闭后 Loaded 应为 false")
+	}
+	if _, err := debug.GetBattleCount(); err == nil {
+		t.Errorf("❌ 已关闭的库调用应返回错误")
+	}
+	if _, err := release.GetBattleCount(); err != nil {
+		t.Errorf("❌ 关闭 Debug 不应影响 Release: %v", err)
+	}
+}
+
+// TestLoadOptionsCandidates 测试候选路径的查找顺序：环境变量 (设置后只尝试它) > 查找目录 > LD_LIBRARY_PATH
+func TestLoadOptionsCandidates(t *testing.T) {
+	envDir := t.TempDir()
+	t.Setenv("TEST_CSHARP_LIB", envDir)
+	t.Setenv("LD_LIBRARY_PATH", "/opt/a"+string(filepath.ListSeparator)+"/opt/b")
+
+	opts := LoadOptions{
+		Version:    "Debug",
+		SearchDirs: []string{"/srv/lib", "/opt/a"},
+		EnvVar:     "TEST_CSHARP_LIB",
+	}
+	if got, want := opts.candidates(), []string{filepath.Join(envDir, "TestExport_Debug.so")}; !slices.Equal(got, want) {
+		t.Errorf("❌ 设置环境变量时只应尝试它\n期望 %v\n实际 %v", want, got)
+	}
+
+	opts.IgnoreEnv = true
+	want := []string{
+		"/srv/lib/TestExport_Debug.so",
+		"/opt/a/TestExport_Debug.so",
+		"/opt/b/TestExport_Debug.so",
+	}
+	if got := opts.candidates(); !slices.Equal(got, want) {
+		t.Errorf("❌ 候选路径\n期望 %v\n实际 %v", want, got)
+	}
+
+	opts.IgnoreLDLibraryPath = true
+	if got := opts.candidates(); !slices.Equal(got, want[:2]) {
+		t.Errorf("❌ 忽略环境变量后候选路径异常: %v", got)
+	}
+
+	// 设置 Path 后只尝试该路径
+	opts.Path = "/abs/TestExport_Custom.so"
+	if got := opts.candidates(); !slices.Equal(got, []string{opts.Path}) {
+		t.Errorf("❌ 显式路径时候选路径异常: %v", got)
+	}
+}
+
+// TestOpenLibraryWithOptionsReportsAttempts 测试加载失败时报告所有尝试过的路径
+func TestOpenLibraryWithOptionsReportsAttempts(t *testing.T) {
+	dir := t.TempDir()
+	// 存在但不是合法 ELF 的文件，dlopen 会失败
+	bogus := filepath.Join(dir, "TestExport_Release.so")
+	if err := os.WriteFile(bogus, []byte("not a library"), 0o644); err != nil {
+		t.Fatalf("❌ 写入测试文件失败: %v", err)
+	}
+
+	_, err := OpenLibraryWithOptions(LoadOptions{
+		SearchDirs:          []string{"/nonexistent", dir},
+		IgnoreEnv:           true,
+		IgnoreLDLibraryPath: true,
+	})
+
+	var loadErr *LibraryLoadError
+	if !errors.As(err, &loadErr) {
+		t.Fatalf("❌ 期望 *LibraryLoadError, 实际 %v", err)
+	}
+	if len(loadErr.Attempts) != 2 {
+		t.Fatalf("❌ 期望尝试 2 个路径, 实际 %d", len(loadErr.Attempts))
+	}
+	if !errors.Is(err, os.ErrNotExist) {
+		t.Errorf("❌ 错误链中应包含 os.ErrNotExist")
+	}
+	for _, path := range []string{"/nonexistent/TestExport_Release.so", bogus} {
+		if !strings.Contains(err.Error(), path) {
+			t.Errorf("❌ 错误信息缺少路径 %s: %v", path, err)
+		}
+	}
+
+	// 环境变量指定的库打开失败时不回退到查找目录
+	t.Setenv(DefaultLibraryEnvVar, bogus)
+	_, err = OpenLibraryWithOptions(LoadOptions{
+		SearchDirs:          []string{"/nonexistent"},
+		IgnoreLDLibraryPath: true,
+	})
+	if !errors.As(err, &loadErr) || len(loadErr.Attempts) != 1 || loadErr.Attempts[0].Path != bogus {
+		t.Errorf("❌ 环境变量指定的库打开失败时应只报告该路径, 实际 %v", err)
+	}
+}
+
+// TestLibraryReload 测试热重载迁移存活的战斗，失败时旧库保持可用
+func TestLibraryReload(t *testing.T) {
+	release, err := OpenLibraryVersion("Release")
+	if err != nil {
+		t.Skipf("跳过: Release 库不可用: %v", err)
+	}
+	defer release.Close()
+
+	if err := release.CreateBattle(teamEnv(70002, 1, 2, 0)); err != nil {
+		t.Fatalf("❌ 创建战斗失败: %v", err)
+	}
+
+	// 新库不存在，重载失败，旧库不受影响
+	if _, err := release.Reload(LoadOptions{Path: "/nonexistent/TestExport_Missing.so", IgnoreEnv: true}); err == nil {
+		t.Fatalf("❌ 重载不存在的库应返回错误")
+	}
+	if count, err := release.GetBattleCount(); err != nil || count != 1 {
+		t.Fatalf("❌ 重载失败后旧库战斗数量期望 1, 实际 %d (err=%v)", count, err)
+	}
+
+	next, err := release.Reload(LoadOptions{Version: "Debug"})
+	if err != nil {
+		t.Skipf("跳过: Debug 库不可用: %v", err)
+	}
+	defer next.Close()
+
+	if release.Loaded() {
+		t.Errorf("❌ 重载成功后旧库应已关闭")
+	}
+	snapshot, err := next.ExportBattleState()
+	if err != nil {
+		t.Fatalf("❌ 导出战斗状态失败: %v", err)
+	}
+	if len(snapshot.GetBattles()) != 1 || snapshot.GetBattles()[0].GetBattleId() != 70002 {
+		t.Errorf("❌ 新库战斗状态异常: %v", snapshot)
+	}
+}
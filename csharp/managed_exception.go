@@ -0,0 +1,193 @@
+package csharp
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"time"
+	"unsafe"
+
+	proto_pb "goPureWithCsharp/csharp/proto"
+
+	"github.com/ebitengine/purego"
+	"google.golang.org/protobuf/proto"
+)
+
+// ============================================================================
+// 托管异常 - C# 异常报告传递到 Go
+// ============================================================================
+//
+// C# 侧 NativeAOTExceptionInjector 把异常分为两类:
+//   - 导出函数失败: WrapExportFunction 将报告保存在抛出异常的线程上，导出函数返回失败值。
+//     Go 调用导出函数时锁定 OS 线程，失败后在同一线程上调用 TakeManagedException 取回报告，
+//     作为 *BattleError.Exception 从触发异常的那次调用返回
+//   - 不中断导出函数的异常 (OnTick 中单场战斗、批量战斗中的单场): 通过全局回调报告，
+//     交给 SetExceptionHook 设置的钩子
+//
+//	var exc *csharp.ManagedException
+//	if errors.As(err, &exc) { log.Println(exc.Type, exc.StackTrace) }
+
+const managedExceptionBufferSize = 4096 // TakeManagedException 初始缓冲区
+
+// ManagedException C# 托管异常报告
+type ManagedException struct {
+	Type       string    // 异常类型全名
+	Message    string    // 异常消息
+	StackTrace string    // 托管调用栈
+	BattleID   uint32    // 异常所属的战斗，与战斗无关时为 0
+	Export     string    // 抛出异常时正在执行的导出函数
+	Inner      string    // 内部异常，没有时为空
+	Timestamp  time.Time // C# 侧捕获异常的时间
+}
+
+// newManagedException 从 proto 报告转换，report 为 nil 时返回 nil
+func newManagedException(report *proto_pb.ManagedException) *ManagedException {
+	if report == nil {
+		return nil
+	}
+	return &ManagedException{
+		Type:       report.GetType(),
+		Message:    report.GetMessage(),
+		StackTrace: report.GetStackTrace(),
+		BattleID:   report.GetBattleId(),
+		Export:     report.GetExport(),
+		Inner:      report.GetInner(),
+		Timestamp:  time.UnixMilli(report.GetTimestamp()),
+	}
+}
+
+func (e *ManagedException) Error() string {
+	if e.BattleID != 0 {
+		return fmt.Sprintf("C# 托管异常 %s: %s (导出函数=%s, 战斗=%d)", e.Type, e.Message, e.Export, e.BattleID)
+	}
+	return fmt.Sprintf("C# 托管异常 %s: %s (导出函数=%s)", e.Type, e.Message, e.Export)
+}
+
+// ============================================================================
+// 全局异常钩子
+// ============================================================================
+
+// ExceptionHook 接收没有关联到某次调用的托管异常
+// 在 C# 调用 Go 的线程上同步执行，不应阻塞
+type ExceptionHook func(exc *ManagedException)
+
+var exceptionHook atomic.Pointer[ExceptionHook]
+
+// SetExceptionHook 设置全局异常钩子，对所有已加载的库生效
+// hook 为 nil 时恢复默认行为 (打印到标准输出)
+func SetExceptionHook(hook ExceptionHook) {
+	if hook == nil {
+		exceptionHook.Store(nil)
+		return
+	}
+	exceptionHook.Store(&hook)
+}
+
+// dispatchException 将异常交给全局钩子
+func dispatchException(exc *ManagedException) {
+	if hook := exceptionHook.Load(); hook != nil {
+		(*hook)(exc)
+		return
+	}
+	fmt.Printf("[Go] 捕获到 C# 异常: %v\n%s\n", exc, exc.StackTrace)
+}
+
+// onManagedException C# 全局异常回调，参数为序列化的 ManagedException
+func onManagedException(dataPtr unsafe.Pointer, dataLen int32) {
+	if dataPtr == nil || dataLen <= 0 {
+		return
+	}
+
+	report := &proto_pb.ManagedException{}
+	if err := proto.Unmarshal(unsafe.Slice((*byte)(dataPtr), dataLen), report); err != nil {
+		fmt.Printf("[Go] 托管异常报告反序列化失败: %v\n", err)
+		return
+	}
+	dispatchException(newManagedException(report))
+}
+
+// exceptionCallback 所有库共用的全局异常回调指针
+var exceptionCallback = sync.OnceValue(func() uintptr {
+	return purego.NewCallback(onManagedException)
+})
+
+// ============================================================================
+// Library 异常报告
+// ============================================================================
+
+// injectExceptionCallback 向库注册全局异常回调，在 openLibrary 中调用
+func (l *Library) injectExceptionCallback() error {
+	if !l.HasExport("InjectedExceptionContext") {
+		return nil
+	}
+
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+
+	fnPtr, err := l.getCachedFunction("InjectedExceptionContext")
+	if err != nil {
+		return err
+	}
+	purego.SyscallN(fnPtr, exceptionCallback())
+	return nil
+}
+
+// attachException 在导出函数失败后取回同一线程上的托管异常并附加到 err
+// 调用方需持有 l.mu 读锁，并在导出函数调用前后锁定 OS 线程 (runtime.LockOSThread)
+func (l *Library) attachException(err error) error {
+	if !l.HasFeature(FeatureExceptionReports) {
+		return err
+	}
+
+	exc, takeErr := l.takeManagedException()
+	if takeErr != nil {
+		fmt.Printf("[Go] 取回托管异常失败: %v\n", takeErr)
+		return err
+	}
+	if exc == nil {
+		return err
+	}
+	return withManagedException(err, exc)
+}
+
+// takeManagedException 调用 TakeManagedException，没有异常时返回 nil
+func (l *Library) takeManagedException() (*ManagedException, error) {
+	fnPtr, err := l.getCachedFunction("TakeManagedException")
+	if err != nil {
+		return nil, err
+	}
+
+	data, err := negotiateResponse("TakeManagedException", managedExceptionBufferSize, func(buf []byte) int32 {
+		bufLen := int32(len(buf))
+		purego.SyscallN(
+			fnPtr,
+			uintptr(unsafe.Pointer(&buf[0])),
+			uintptr(unsafe.Pointer(&bufLen)),
+		)
+		return bufLen
+	})
+	if err != nil {
+		return nil, err
+	}
+	if len(data) == 0 {
+		return nil, nil
+	}
+
+	report := &proto_pb.ManagedException{}
+	if err := proto.Unmarshal(data, report); err != nil {
+		return nil, fmt.Errorf("托管异常报告反序列化失败: %w", err)
+	}
+	return newManagedException(report), nil
+}
+
+// withManagedException 将托管异常附加到调用错误，异常导致的失败统一视为 INTERNAL_ERROR
+func withManagedException(err error, exc *ManagedException) error {
+	var be *BattleError
+	if errors.As(err, &be) {
+		be.Code = proto_pb.BattleErrorCode_INTERNAL_ERROR
+		be.Exception = exc
+		return err
+	}
+	return fmt.Errorf("%w: %w", err, exc)
+}
@@ -1,46 +1,75 @@
 package csharp
 
 import (
-	"os"
-	"runtime/debug"
+	"errors"
+	"strings"
 	"testing"
-)
+	"unsafe"
 
-var globalBuff = make([]byte, 1024*10)
+	proto_pb "goPureWithCsharp/csharp/proto"
 
-func ExecptHandler() {
-	exceptmsg := string(globalBuff)
-	println("[Go] 捕获到 C# 异常:", exceptmsg)
-}
+	"google.golang.org/protobuf/proto"
+)
 
 func Test_CSharpPainc(t *testing.T) {
 
 	cleanup := setupConfigLoaderTest(t)
 	defer cleanup()
 
-	f, err := os.OpenFile("crash.log", os.O_RDWR|os.O_CREATE|os.O_APPEND, 0666)
-	if err != nil {
-		t.Fatalf("无法创建 crash.log 文件: %v", err)
-		t.Fail()
+	// CsharpPanic 触发托管数组越界，异常报告从这次调用返回
+	err := CallCSharpPainc()
+	if err == nil {
+		t.Fatalf("❌ CsharpPanic 应返回托管异常")
 	}
 
-	debug.SetCrashOutput(f, debug.CrashOptions{})
+	var exc *ManagedException
+	if !errors.As(err, &exc) {
+		t.Fatalf("❌ 期望 *ManagedException, 实际 %v", err)
+	}
+	if !strings.HasSuffix(exc.Type, "IndexOutOfRangeException") {
+		t.Errorf("❌ 异常类型错误: %s", exc.Type)
+	}
+	if exc.Export != "CsharpPanic" {
+		t.Errorf("❌ 导出函数错误: %s", exc.Export)
+	}
+	if exc.StackTrace == "" {
+		t.Errorf("❌ 缺少调用栈")
+	}
+	if ErrorCode(err) != proto_pb.BattleErrorCode_INTERNAL_ERROR {
+		t.Errorf("❌ 托管异常应映射为 INTERNAL_ERROR, 实际 %s", ErrorCode(err))
+	}
+	t.Logf("✓ 捕获到 C# 异常: %v", err)
+}
 
-	// CANT CATCH HHHHHA
-	defer func() {
-		if r := recover(); r != nil {
-			t.Logf("✓ 捕获到 Panic: %v", r)
-		} else {
-			t.Errorf("❌ 未捕获到 Panic")
-		}
-	}()
+// TestExceptionHook 测试全局异常回调将报告交给钩子
+func TestExceptionHook(t *testing.T) {
+	var got []*ManagedException
+	SetExceptionHook(func(exc *ManagedException) {
+		got = append(got, exc)
+	})
+	defer SetExceptionHook(nil)
 
-	exCtx := CSharpExceptionContext{
-		CallbackPtr:           ExecptHandler,
-		NotifyExceptionBuffer: globalBuff,
+	data, err := proto.Marshal(&proto_pb.ManagedException{
+		Type:       "System.NullReferenceException",
+		Message:    "Object reference not set to an instance of an object.",
+		StackTrace: "   at BattleInstance.ExecuteRound(Int32 minDamage, Int32 maxDamage)",
+		BattleId:   7,
+		Export:     "OnTick",
+		Timestamp:  1700000000000,
+	})
+	if err != nil {
+		t.Fatalf("❌ 序列化失败: %v", err)
 	}
+	onManagedException(unsafe.Pointer(&data[0]), int32(len(data)))
+	onManagedException(nil, 0)
 
-	InjectCSharpExceptionCallback(exCtx)
-
-	CallCSharpPainc()
+	if len(got) != 1 {
+		t.Fatalf("❌ 期望 1 个异常报告, 实际 %d", len(got))
+	}
+	if got[0].BattleID != 7 || got[0].Export != "OnTick" || got[0].Timestamp.UnixMilli() != 1700000000000 {
+		t.Errorf("❌ 异常报告字段错误: %+v", got[0])
+	}
+	if !strings.Contains(got[0].Error(), "战斗=7") {
+		t.Errorf("❌ 错误信息缺少战斗 ID: %v", got[0])
+	}
 }
@@ -97,6 +97,7 @@ const (
 	BattleErrorCode_INTERNAL_ERROR       BattleErrorCode = 6 // 内部错误
 	BattleErrorCode_TIMEOUT              BattleErrorCode = 7 // 超时
 	BattleErrorCode_INVALID_PROTO_FORMAT BattleErrorCode = 8 // Protobuf 格式错误
+	BattleErrorCode_INVALID_BATTLE_STATE BattleErrorCode = 9 // 当前战斗状态不允许该操作 (例如恢复未暂停的战斗)
 )
 
 // Enum value maps for BattleErrorCode.
@@ -111,6 +112,7 @@ var (
 		6: "INTERNAL_ERROR",
 		7: "TIMEOUT",
 		8: "INVALID_PROTO_FORMAT",
+		9: "INVALID_BATTLE_STATE",
 	}
 	BattleErrorCode_value = map[string]int32{
 		"SUCCESS":              0,
@@ -122,6 +124,7 @@ var (
 		"INTERNAL_ERROR":       6,
 		"TIMEOUT":              7,
 		"INVALID_PROTO_FORMAT": 8,
+		"INVALID_BATTLE_STATE": 9,
 	}
 )
 
@@ -208,7 +211,7 @@ func (NotificationType) EnumDescriptor() ([]byte, []int) {
 // 队伍信息
 type Team struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
-	Lineup        []uint32               `protobuf:"varint,1,rep,packed,name=lineup,proto3" json:"lineup,omitempty"`             // 阵容ID列表
+	Lineup        []uint32               `protobuf:"varint,1,rep,packed,name=lineup,proto3" json:"lineup,omitempty"`             // 阵容ID列表 (单位ID，同一场战斗内不能重复；为空时整个队伍作为一个单位，单位ID为队伍ID)
 	TeamId        uint32                 `protobuf:"varint,2,opt,name=team_id,json=teamId,proto3" json:"team_id,omitempty"`      // 队伍ID
 	TeamName      string                 `protobuf:"bytes,3,opt,name=team_name,json=teamName,proto3" json:"team_name,omitempty"` // 队伍名称
 	unknownFields protoimpl.UnknownFields
@@ -266,6 +269,91 @@ func (x *Team) GetTeamName() string {
 	return ""
 }
 
+// 战斗单位状态，初始属性来自 unit_config.json (默认值和按单位ID配置的属性)
+type BattleUnit struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	UnitId        uint32                 `protobuf:"varint,1,opt,name=unit_id,json=unitId,proto3" json:"unit_id,omitempty"`          // 单位ID (Team.lineup 中的ID)
+	TeamId        uint32                 `protobuf:"varint,2,opt,name=team_id,json=teamId,proto3" json:"team_id,omitempty"`          // 所属队伍ID
+	MaxHealth     int32                  `protobuf:"varint,3,opt,name=max_health,json=maxHealth,proto3" json:"max_health,omitempty"` // 最大生命 (属性 3001)
+	Health        int32                  `protobuf:"varint,4,opt,name=health,proto3" json:"health,omitempty"`                        // 当前生命 (属性 4001)，<= 0 表示已被击败
+	Attack        int32                  `protobuf:"varint,5,opt,name=attack,proto3" json:"attack,omitempty"`                        // 攻击力 (属性 3002)
+	Defense       int32                  `protobuf:"varint,6,opt,name=defense,proto3" json:"defense,omitempty"`                      // 防御力
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *BattleUnit) Reset() {
+	*x = BattleUnit{}
+	mi := &file_battle_proto_msgTypes[1]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *BattleUnit) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*BattleUnit) ProtoMessage() {}
+
+func (x *BattleUnit) ProtoReflect() protoreflect.Message {
+	mi := &file_battle_proto_msgTypes[1]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use BattleUnit.ProtoReflect.Descriptor instead.
+func (*BattleUnit) Descriptor() ([]byte, []int) {
+	return file_battle_proto_rawDescGZIP(), []int{1}
+}
+
+func (x *BattleUnit) GetUnitId() uint32 {
+	if x != nil {
+		return x.UnitId
+	}
+	return 0
+}
+
+func (x *BattleUnit) GetTeamId() uint32 {
+	if x != nil {
+		return x.TeamId
+	}
+	return 0
+}
+
+func (x *BattleUnit) GetMaxHealth() int32 {
+	if x != nil {
+		return x.MaxHealth
+	}
+	return 0
+}
+
+func (x *BattleUnit) GetHealth() int32 {
+	if x != nil {
+		return x.Health
+	}
+	return 0
+}
+
+func (x *BattleUnit) GetAttack() int32 {
+	if x != nil {
+		return x.Attack
+	}
+	return 0
+}
+
+func (x *BattleUnit) GetDefense() int32 {
+	if x != nil {
+		return x.Defense
+	}
+	return 0
+}
+
 // 战斗环境
 type BattleEnv struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
@@ -274,13 +362,14 @@ type BattleEnv struct {
 	BattleId      uint32                 `protobuf:"varint,3,opt,name=battle_id,json=battleId,proto3" json:"battle_id,omitempty"`                // 战斗ID
 	Timestamp     int64                  `protobuf:"varint,4,opt,name=timestamp,proto3" json:"timestamp,omitempty"`                              // 时间戳
 	ConfigVersion uint32                 `protobuf:"varint,5,opt,name=config_version,json=configVersion,proto3" json:"config_version,omitempty"` // 配置版本
+	Seed          uint64                 `protobuf:"varint,6,opt,name=seed,proto3" json:"seed,omitempty"`                                        // 随机种子，相同种子和输入得到相同结果 (为 0 时由 Go 侧分配)
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
 func (x *BattleEnv) Reset() {
 	*x = BattleEnv{}
-	mi := &file_battle_proto_msgTypes[1]
+	mi := &file_battle_proto_msgTypes[2]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -292,7 +381,7 @@ func (x *BattleEnv) String() string {
 func (*BattleEnv) ProtoMessage() {}
 
 func (x *BattleEnv) ProtoReflect() protoreflect.Message {
-	mi := &file_battle_proto_msgTypes[1]
+	mi := &file_battle_proto_msgTypes[2]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -305,7 +394,7 @@ func (x *BattleEnv) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use BattleEnv.ProtoReflect.Descriptor instead.
 func (*BattleEnv) Descriptor() ([]byte, []int) {
-	return file_battle_proto_rawDescGZIP(), []int{1}
+	return file_battle_proto_rawDescGZIP(), []int{2}
 }
 
 func (x *BattleEnv) GetAtk() *Team {
@@ -343,6 +432,13 @@ func (x *BattleEnv) GetConfigVersion() uint32 {
 	return 0
 }
 
+func (x *BattleEnv) GetSeed() uint64 {
+	if x != nil {
+		return x.Seed
+	}
+	return 0
+}
+
 // 开始战斗请求
 type StartBattle struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
@@ -350,13 +446,14 @@ type StartBattle struct {
 	Def           *Team                  `protobuf:"bytes,2,opt,name=def,proto3" json:"def,omitempty"`                            // 防守方队伍
 	BattleId      uint32                 `protobuf:"varint,3,opt,name=battle_id,json=battleId,proto3" json:"battle_id,omitempty"` // 战斗ID
 	Timestamp     int64                  `protobuf:"varint,4,opt,name=timestamp,proto3" json:"timestamp,omitempty"`               // 时间戳
+	Seed          uint64                 `protobuf:"varint,5,opt,name=seed,proto3" json:"seed,omitempty"`                         // 随机种子
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
 func (x *StartBattle) Reset() {
 	*x = StartBattle{}
-	mi := &file_battle_proto_msgTypes[2]
+	mi := &file_battle_proto_msgTypes[3]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -368,7 +465,7 @@ func (x *StartBattle) String() string {
 func (*StartBattle) ProtoMessage() {}
 
 func (x *StartBattle) ProtoReflect() protoreflect.Message {
-	mi := &file_battle_proto_msgTypes[2]
+	mi := &file_battle_proto_msgTypes[3]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -381,7 +478,7 @@ func (x *StartBattle) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use StartBattle.ProtoReflect.Descriptor instead.
 func (*StartBattle) Descriptor() ([]byte, []int) {
-	return file_battle_proto_rawDescGZIP(), []int{2}
+	return file_battle_proto_rawDescGZIP(), []int{3}
 }
 
 func (x *StartBattle) GetAtk() *Team {
@@ -412,6 +509,13 @@ func (x *StartBattle) GetTimestamp() int64 {
 	return 0
 }
 
+func (x *StartBattle) GetSeed() uint64 {
+	if x != nil {
+		return x.Seed
+	}
+	return 0
+}
+
 // 战斗输入 (通用请求格式)
 type BattleInput struct {
 	state protoimpl.MessageState `protogen:"open.v1"`
@@ -421,6 +525,8 @@ type BattleInput struct {
 	//	*BattleInput_Resume
 	//	*BattleInput_Pause
 	//	*BattleInput_UserOp
+	//	*BattleInput_End
+	//	*BattleInput_Destroy
 	Input         isBattleInput_Input `protobuf_oneof:"input"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
@@ -428,7 +534,7 @@ type BattleInput struct {
 
 func (x *BattleInput) Reset() {
 	*x = BattleInput{}
-	mi := &file_battle_proto_msgTypes[3]
+	mi := &file_battle_proto_msgTypes[4]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -440,7 +546,7 @@ func (x *BattleInput) String() string {
 func (*BattleInput) ProtoMessage() {}
 
 func (x *BattleInput) ProtoReflect() protoreflect.Message {
-	mi := &file_battle_proto_msgTypes[3]
+	mi := &file_battle_proto_msgTypes[4]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -453,7 +559,7 @@ func (x *BattleInput) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use BattleInput.ProtoReflect.Descriptor instead.
 func (*BattleInput) Descriptor() ([]byte, []int) {
-	return file_battle_proto_rawDescGZIP(), []int{3}
+	return file_battle_proto_rawDescGZIP(), []int{4}
 }
 
 func (x *BattleInput) GetInput() isBattleInput_Input {
@@ -499,6 +605,24 @@ func (x *BattleInput) GetUserOp() *BattleUserOp {
 	return nil
 }
 
+func (x *BattleInput) GetEnd() *BattleEnd {
+	if x != nil {
+		if x, ok := x.Input.(*BattleInput_End); ok {
+			return x.End
+		}
+	}
+	return nil
+}
+
+func (x *BattleInput) GetDestroy() *BattleDestroy {
+	if x != nil {
+		if x, ok := x.Input.(*BattleInput_Destroy); ok {
+			return x.Destroy
+		}
+	}
+	return nil
+}
+
 type isBattleInput_Input interface {
 	isBattleInput_Input()
 }
@@ -519,6 +643,14 @@ type BattleInput_UserOp struct {
 	UserOp *BattleUserOp `protobuf:"bytes,4,opt,name=user_op,json=userOp,proto3,oneof"`
 }
 
+type BattleInput_End struct {
+	End *BattleEnd `protobuf:"bytes,5,opt,name=end,proto3,oneof"`
+}
+
+type BattleInput_Destroy struct {
+	Destroy *BattleDestroy `protobuf:"bytes,6,opt,name=destroy,proto3,oneof"`
+}
+
 func (*BattleInput_Use) isBattleInput_Input() {}
 
 func (*BattleInput_Resume) isBattleInput_Input() {}
@@ -527,9 +659,14 @@ func (*BattleInput_Pause) isBattleInput_Input() {}
 
 func (*BattleInput_UserOp) isBattleInput_Input() {}
 
+func (*BattleInput_End) isBattleInput_Input() {}
+
+func (*BattleInput_Destroy) isBattleInput_Input() {}
+
+// 用户操作，效果由 battle_item.json 的 operations 定义
 type BattleUserOp struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
-	CharId        int32                  `protobuf:"varint,1,opt,name=char_id,json=charId,proto3" json:"char_id,omitempty"` // 角色ID
+	CharId        int32                  `protobuf:"varint,1,opt,name=char_id,json=charId,proto3" json:"char_id,omitempty"` // 角色ID (执行操作的单位ID)
 	Operation     string                 `protobuf:"bytes,2,opt,name=operation,proto3" json:"operation,omitempty"`          // 操作类型 (attack/defend/use_item
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
@@ -537,7 +674,7 @@ type BattleUserOp struct {
 
 func (x *BattleUserOp) Reset() {
 	*x = BattleUserOp{}
-	mi := &file_battle_proto_msgTypes[4]
+	mi := &file_battle_proto_msgTypes[5]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -549,7 +686,7 @@ func (x *BattleUserOp) String() string {
 func (*BattleUserOp) ProtoMessage() {}
 
 func (x *BattleUserOp) ProtoReflect() protoreflect.Message {
-	mi := &file_battle_proto_msgTypes[4]
+	mi := &file_battle_proto_msgTypes[5]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -562,7 +699,7 @@ func (x *BattleUserOp) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use BattleUserOp.ProtoReflect.Descriptor instead.
 func (*BattleUserOp) Descriptor() ([]byte, []int) {
-	return file_battle_proto_rawDescGZIP(), []int{4}
+	return file_battle_proto_rawDescGZIP(), []int{5}
 }
 
 func (x *BattleUserOp) GetCharId() int32 {
@@ -579,19 +716,19 @@ func (x *BattleUserOp) GetOperation() string {
 	return ""
 }
 
-// 使用道具请求
+// 使用道具请求，效果由 battle_item.json 的 items 定义
 type BattleUseItem struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
 	ItemIds       []uint32               `protobuf:"varint,1,rep,packed,name=item_ids,json=itemIds,proto3" json:"item_ids,omitempty"` // 道具ID列表
-	UserId        uint32                 `protobuf:"varint,2,opt,name=user_id,json=userId,proto3" json:"user_id,omitempty"`           // 使用者ID
-	Quantity      int32                  `protobuf:"varint,3,opt,name=quantity,proto3" json:"quantity,omitempty"`                     // 数量
+	UserId        uint32                 `protobuf:"varint,2,opt,name=user_id,json=userId,proto3" json:"user_id,omitempty"`           // 使用者ID (使用道具的单位ID)
+	Quantity      int32                  `protobuf:"varint,3,opt,name=quantity,proto3" json:"quantity,omitempty"`                     // 数量 (每种道具 1 ~ maxQuantity)
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
 func (x *BattleUseItem) Reset() {
 	*x = BattleUseItem{}
-	mi := &file_battle_proto_msgTypes[5]
+	mi := &file_battle_proto_msgTypes[6]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -603,7 +740,7 @@ func (x *BattleUseItem) String() string {
 func (*BattleUseItem) ProtoMessage() {}
 
 func (x *BattleUseItem) ProtoReflect() protoreflect.Message {
-	mi := &file_battle_proto_msgTypes[5]
+	mi := &file_battle_proto_msgTypes[6]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -616,7 +753,7 @@ func (x *BattleUseItem) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use BattleUseItem.ProtoReflect.Descriptor instead.
 func (*BattleUseItem) Descriptor() ([]byte, []int) {
-	return file_battle_proto_rawDescGZIP(), []int{5}
+	return file_battle_proto_rawDescGZIP(), []int{6}
 }
 
 func (x *BattleUseItem) GetItemIds() []uint32 {
@@ -648,7 +785,7 @@ type BattleResume struct {
 
 func (x *BattleResume) Reset() {
 	*x = BattleResume{}
-	mi := &file_battle_proto_msgTypes[6]
+	mi := &file_battle_proto_msgTypes[7]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -660,7 +797,7 @@ func (x *BattleResume) String() string {
 func (*BattleResume) ProtoMessage() {}
 
 func (x *BattleResume) ProtoReflect() protoreflect.Message {
-	mi := &file_battle_proto_msgTypes[6]
+	mi := &file_battle_proto_msgTypes[7]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -673,7 +810,7 @@ func (x *BattleResume) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use BattleResume.ProtoReflect.Descriptor instead.
 func (*BattleResume) Descriptor() ([]byte, []int) {
-	return file_battle_proto_rawDescGZIP(), []int{6}
+	return file_battle_proto_rawDescGZIP(), []int{7}
 }
 
 type BattlePause struct {
@@ -684,7 +821,7 @@ type BattlePause struct {
 
 func (x *BattlePause) Reset() {
 	*x = BattlePause{}
-	mi := &file_battle_proto_msgTypes[7]
+	mi := &file_battle_proto_msgTypes[8]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -696,7 +833,7 @@ func (x *BattlePause) String() string {
 func (*BattlePause) ProtoMessage() {}
 
 func (x *BattlePause) ProtoReflect() protoreflect.Message {
-	mi := &file_battle_proto_msgTypes[7]
+	mi := &file_battle_proto_msgTypes[8]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -709,7 +846,97 @@ func (x *BattlePause) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use BattlePause.ProtoReflect.Descriptor instead.
 func (*BattlePause) Descriptor() ([]byte, []int) {
-	return file_battle_proto_rawDescGZIP(), []int{7}
+	return file_battle_proto_rawDescGZIP(), []int{8}
+}
+
+// 强制结束战斗，下一个逻辑帧输出结果和回放
+type BattleEnd struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Winner        uint32                 `protobuf:"varint,1,opt,name=winner,proto3" json:"winner,omitempty"`   // 指定胜方队伍ID，0 表示按剩余血量判定 (相同时防守方获胜)
+	Timeout       bool                   `protobuf:"varint,2,opt,name=timeout,proto3" json:"timeout,omitempty"` // 超过最长战斗时长被强制结束，结果的 timeout 为 true
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *BattleEnd) Reset() {
+	*x = BattleEnd{}
+	mi := &file_battle_proto_msgTypes[9]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *BattleEnd) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*BattleEnd) ProtoMessage() {}
+
+func (x *BattleEnd) ProtoReflect() protoreflect.Message {
+	mi := &file_battle_proto_msgTypes[9]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use BattleEnd.ProtoReflect.Descriptor instead.
+func (*BattleEnd) Descriptor() ([]byte, []int) {
+	return file_battle_proto_rawDescGZIP(), []int{9}
+}
+
+func (x *BattleEnd) GetWinner() uint32 {
+	if x != nil {
+		return x.Winner
+	}
+	return 0
+}
+
+func (x *BattleEnd) GetTimeout() bool {
+	if x != nil {
+		return x.Timeout
+	}
+	return false
+}
+
+// 销毁战斗，之后不再执行回合也不接受输入
+type BattleDestroy struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *BattleDestroy) Reset() {
+	*x = BattleDestroy{}
+	mi := &file_battle_proto_msgTypes[10]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *BattleDestroy) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*BattleDestroy) ProtoMessage() {}
+
+func (x *BattleDestroy) ProtoReflect() protoreflect.Message {
+	mi := &file_battle_proto_msgTypes[10]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use BattleDestroy.ProtoReflect.Descriptor instead.
+func (*BattleDestroy) Descriptor() ([]byte, []int) {
+	return file_battle_proto_rawDescGZIP(), []int{10}
 }
 
 type BattleOutput struct {
@@ -725,7 +952,7 @@ type BattleOutput struct {
 
 func (x *BattleOutput) Reset() {
 	*x = BattleOutput{}
-	mi := &file_battle_proto_msgTypes[8]
+	mi := &file_battle_proto_msgTypes[11]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -737,7 +964,7 @@ func (x *BattleOutput) String() string {
 func (*BattleOutput) ProtoMessage() {}
 
 func (x *BattleOutput) ProtoReflect() protoreflect.Message {
-	mi := &file_battle_proto_msgTypes[8]
+	mi := &file_battle_proto_msgTypes[11]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -750,7 +977,7 @@ func (x *BattleOutput) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use BattleOutput.ProtoReflect.Descriptor instead.
 func (*BattleOutput) Descriptor() ([]byte, []int) {
-	return file_battle_proto_rawDescGZIP(), []int{8}
+	return file_battle_proto_rawDescGZIP(), []int{11}
 }
 
 func (x *BattleOutput) GetOutput() isBattleOutput_Output {
@@ -795,22 +1022,24 @@ func (*BattleOutput_Result) isBattleOutput_Output() {}
 func (*BattleOutput_Replay) isBattleOutput_Output() {}
 
 // 战斗结果
+// tick 驱动的战斗 (CreateBattle + OnTick) 以逻辑帧计时: duration 为经历的逻辑帧数
 type BattleResult struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
 	Winner        uint32                 `protobuf:"varint,1,opt,name=winner,proto3" json:"winner,omitempty"`                              // 胜方队伍ID
 	Loser         uint32                 `protobuf:"varint,2,opt,name=loser,proto3" json:"loser,omitempty"`                                // 败方队伍ID
 	AtkDamage     int32                  `protobuf:"varint,3,opt,name=atk_damage,json=atkDamage,proto3" json:"atk_damage,omitempty"`       // 攻击方伤害
 	DefDamage     int32                  `protobuf:"varint,4,opt,name=def_damage,json=defDamage,proto3" json:"def_damage,omitempty"`       // 防守方伤害
-	Kills         []uint32               `protobuf:"varint,5,rep,packed,name=kills,proto3" json:"kills,omitempty"`                         // 击杀列表
+	Kills         []uint32               `protobuf:"varint,5,rep,packed,name=kills,proto3" json:"kills,omitempty"`                         // 被击败的单位ID (按被击败的顺序)
 	Duration      int64                  `protobuf:"varint,6,opt,name=duration,proto3" json:"duration,omitempty"`                          // 战斗持续时间(毫秒)
 	BattleScore   int32                  `protobuf:"varint,7,opt,name=battle_score,json=battleScore,proto3" json:"battle_score,omitempty"` // 战斗积分
+	Timeout       bool                   `protobuf:"varint,8,opt,name=timeout,proto3" json:"timeout,omitempty"`                            // 超过最长战斗时长被强制结束 (TIMEOUT)
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
 func (x *BattleResult) Reset() {
 	*x = BattleResult{}
-	mi := &file_battle_proto_msgTypes[9]
+	mi := &file_battle_proto_msgTypes[12]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -822,7 +1051,7 @@ func (x *BattleResult) String() string {
 func (*BattleResult) ProtoMessage() {}
 
 func (x *BattleResult) ProtoReflect() protoreflect.Message {
-	mi := &file_battle_proto_msgTypes[9]
+	mi := &file_battle_proto_msgTypes[12]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -835,7 +1064,7 @@ func (x *BattleResult) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use BattleResult.ProtoReflect.Descriptor instead.
 func (*BattleResult) Descriptor() ([]byte, []int) {
-	return file_battle_proto_rawDescGZIP(), []int{9}
+	return file_battle_proto_rawDescGZIP(), []int{12}
 }
 
 func (x *BattleResult) GetWinner() uint32 {
@@ -887,22 +1116,38 @@ func (x *BattleResult) GetBattleScore() int32 {
 	return 0
 }
 
+func (x *BattleResult) GetTimeout() bool {
+	if x != nil {
+		return x.Timeout
+	}
+	return false
+}
+
 // 战斗状态
 type BattleStatus struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
-	BattleId      uint32                 `protobuf:"varint,1,opt,name=battle_id,json=battleId,proto3" json:"battle_id,omitempty"`    // 战斗ID
-	Round         int32                  `protobuf:"varint,2,opt,name=round,proto3" json:"round,omitempty"`                          // 当前回合
-	AtkHealth     int32                  `protobuf:"varint,3,opt,name=atk_health,json=atkHealth,proto3" json:"atk_health,omitempty"` // 攻击方生命值
-	DefHealth     int32                  `protobuf:"varint,4,opt,name=def_health,json=defHealth,proto3" json:"def_health,omitempty"` // 防守方生命值
-	State         string                 `protobuf:"bytes,5,opt,name=state,proto3" json:"state,omitempty"`                           // 战斗状态 (running/paused/finished)
-	Timestamp     int64                  `protobuf:"varint,6,opt,name=timestamp,proto3" json:"timestamp,omitempty"`                  // 时间戳
+	BattleId      uint32                 `protobuf:"varint,1,opt,name=battle_id,json=battleId,proto3" json:"battle_id,omitempty"`                // 战斗ID
+	Round         int32                  `protobuf:"varint,2,opt,name=round,proto3" json:"round,omitempty"`                                      // 当前回合
+	AtkHealth     int32                  `protobuf:"varint,3,opt,name=atk_health,json=atkHealth,proto3" json:"atk_health,omitempty"`             // 攻击方生命值 (存活单位之和)
+	DefHealth     int32                  `protobuf:"varint,4,opt,name=def_health,json=defHealth,proto3" json:"def_health,omitempty"`             // 防守方生命值
+	State         string                 `protobuf:"bytes,5,opt,name=state,proto3" json:"state,omitempty"`                                       // 战斗状态 (running/paused/finished)
+	Timestamp     int64                  `protobuf:"varint,6,opt,name=timestamp,proto3" json:"timestamp,omitempty"`                              // 时间戳
+	AtkTeamId     uint32                 `protobuf:"varint,7,opt,name=atk_team_id,json=atkTeamId,proto3" json:"atk_team_id,omitempty"`           // 攻击方队伍ID
+	DefTeamId     uint32                 `protobuf:"varint,8,opt,name=def_team_id,json=defTeamId,proto3" json:"def_team_id,omitempty"`           // 防守方队伍ID
+	Winner        uint32                 `protobuf:"varint,9,opt,name=winner,proto3" json:"winner,omitempty"`                                    // 获胜方队伍ID (finished 时有效)
+	AtkMaxHealth  int32                  `protobuf:"varint,10,opt,name=atk_max_health,json=atkMaxHealth,proto3" json:"atk_max_health,omitempty"` // 攻击方最大生命 (各单位之和，属性 3001)
+	DefMaxHealth  int32                  `protobuf:"varint,11,opt,name=def_max_health,json=defMaxHealth,proto3" json:"def_max_health,omitempty"` // 防守方最大生命
+	AtkAttack     int32                  `protobuf:"varint,12,opt,name=atk_attack,json=atkAttack,proto3" json:"atk_attack,omitempty"`            // 攻击方攻击力 (各单位之和，属性 3002)
+	DefAttack     int32                  `protobuf:"varint,13,opt,name=def_attack,json=defAttack,proto3" json:"def_attack,omitempty"`            // 防守方攻击力
+	Units         []*BattleUnit          `protobuf:"bytes,14,rep,name=units,proto3" json:"units,omitempty"`                                      // 所有单位，攻击方在前，按阵容顺序
+	ShardId       uint32                 `protobuf:"varint,15,opt,name=shard_id,json=shardId,proto3" json:"shard_id,omitempty"`                  // 战斗所在的分片 (CreateShardBattle 指定，CreateBattle 为 0)
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
 func (x *BattleStatus) Reset() {
 	*x = BattleStatus{}
-	mi := &file_battle_proto_msgTypes[10]
+	mi := &file_battle_proto_msgTypes[13]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -914,7 +1159,7 @@ func (x *BattleStatus) String() string {
 func (*BattleStatus) ProtoMessage() {}
 
 func (x *BattleStatus) ProtoReflect() protoreflect.Message {
-	mi := &file_battle_proto_msgTypes[10]
+	mi := &file_battle_proto_msgTypes[13]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -927,7 +1172,7 @@ func (x *BattleStatus) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use BattleStatus.ProtoReflect.Descriptor instead.
 func (*BattleStatus) Descriptor() ([]byte, []int) {
-	return file_battle_proto_rawDescGZIP(), []int{10}
+	return file_battle_proto_rawDescGZIP(), []int{13}
 }
 
 func (x *BattleStatus) GetBattleId() uint32 {
@@ -972,45 +1217,154 @@ func (x *BattleStatus) GetTimestamp() int64 {
 	return 0
 }
 
-// 通用响应消息
-type BattleResponse struct {
-	state         protoimpl.MessageState `protogen:"open.v1"`
-	Code          int32                  `protobuf:"varint,1,opt,name=code,proto3" json:"code,omitempty"`           // 错误码 (0=成功)
-	Message       string                 `protobuf:"bytes,2,opt,name=message,proto3" json:"message,omitempty"`      // 消息
-	Result        []byte                 `protobuf:"bytes,3,opt,name=result,proto3" json:"result,omitempty"`        // 结果数据(序列化的具体消息)
-	Timestamp     int64                  `protobuf:"varint,4,opt,name=timestamp,proto3" json:"timestamp,omitempty"` // 时间戳
-	unknownFields protoimpl.UnknownFields
-	sizeCache     protoimpl.SizeCache
+func (x *BattleStatus) GetAtkTeamId() uint32 {
+	if x != nil {
+		return x.AtkTeamId
+	}
+	return 0
 }
 
-func (x *BattleResponse) Reset() {
-	*x = BattleResponse{}
-	mi := &file_battle_proto_msgTypes[11]
-	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
-	ms.StoreMessageInfo(mi)
+func (x *BattleStatus) GetDefTeamId() uint32 {
+	if x != nil {
+		return x.DefTeamId
+	}
+	return 0
 }
 
-func (x *BattleResponse) String() string {
-	return protoimpl.X.MessageStringOf(x)
+func (x *BattleStatus) GetWinner() uint32 {
+	if x != nil {
+		return x.Winner
+	}
+	return 0
 }
 
-func (*BattleResponse) ProtoMessage() {}
-
-func (x *BattleResponse) ProtoReflect() protoreflect.Message {
-	mi := &file_battle_proto_msgTypes[11]
+func (x *BattleStatus) GetAtkMaxHealth() int32 {
 	if x != nil {
-		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
-		if ms.LoadMessageInfo() == nil {
-			ms.StoreMessageInfo(mi)
-		}
-		return ms
+		return x.AtkMaxHealth
 	}
-	return mi.MessageOf(x)
+	return 0
 }
 
-// Deprecated: Use BattleResponse.ProtoReflect.Descriptor instead.
+func (x *BattleStatus) GetDefMaxHealth() int32 {
+	if x != nil {
+		return x.DefMaxHealth
+	}
+	return 0
+}
+
+func (x *BattleStatus) GetAtkAttack() int32 {
+	if x != nil {
+		return x.AtkAttack
+	}
+	return 0
+}
+
+func (x *BattleStatus) GetDefAttack() int32 {
+	if x != nil {
+		return x.DefAttack
+	}
+	return 0
+}
+
+func (x *BattleStatus) GetUnits() []*BattleUnit {
+	if x != nil {
+		return x.Units
+	}
+	return nil
+}
+
+func (x *BattleStatus) GetShardId() uint32 {
+	if x != nil {
+		return x.ShardId
+	}
+	return 0
+}
+
+// 战斗状态列表 (ListBattleStatus 导出函数的响应)
+type BattleStatusList struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Battles       []*BattleStatus        `protobuf:"bytes,1,rep,name=battles,proto3" json:"battles,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *BattleStatusList) Reset() {
+	*x = BattleStatusList{}
+	mi := &file_battle_proto_msgTypes[14]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *BattleStatusList) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*BattleStatusList) ProtoMessage() {}
+
+func (x *BattleStatusList) ProtoReflect() protoreflect.Message {
+	mi := &file_battle_proto_msgTypes[14]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use BattleStatusList.ProtoReflect.Descriptor instead.
+func (*BattleStatusList) Descriptor() ([]byte, []int) {
+	return file_battle_proto_rawDescGZIP(), []int{14}
+}
+
+func (x *BattleStatusList) GetBattles() []*BattleStatus {
+	if x != nil {
+		return x.Battles
+	}
+	return nil
+}
+
+// 通用响应消息
+type BattleResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Code          int32                  `protobuf:"varint,1,opt,name=code,proto3" json:"code,omitempty"`           // 错误码 (0=成功)
+	Message       string                 `protobuf:"bytes,2,opt,name=message,proto3" json:"message,omitempty"`      // 消息
+	Result        []byte                 `protobuf:"bytes,3,opt,name=result,proto3" json:"result,omitempty"`        // 结果数据(序列化的具体消息)
+	Timestamp     int64                  `protobuf:"varint,4,opt,name=timestamp,proto3" json:"timestamp,omitempty"` // 时间戳
+	Exception     *ManagedException      `protobuf:"bytes,5,opt,name=exception,proto3" json:"exception,omitempty"`  // 处理请求时抛出的托管异常 (没有时为空)
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *BattleResponse) Reset() {
+	*x = BattleResponse{}
+	mi := &file_battle_proto_msgTypes[15]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *BattleResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*BattleResponse) ProtoMessage() {}
+
+func (x *BattleResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_battle_proto_msgTypes[15]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use BattleResponse.ProtoReflect.Descriptor instead.
 func (*BattleResponse) Descriptor() ([]byte, []int) {
-	return file_battle_proto_rawDescGZIP(), []int{11}
+	return file_battle_proto_rawDescGZIP(), []int{15}
 }
 
 func (x *BattleResponse) GetCode() int32 {
@@ -1041,6 +1395,13 @@ func (x *BattleResponse) GetTimestamp() int64 {
 	return 0
 }
 
+func (x *BattleResponse) GetException() *ManagedException {
+	if x != nil {
+		return x.Exception
+	}
+	return nil
+}
+
 // 批量战斗请求
 type BatchBattleRequest struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
@@ -1053,7 +1414,7 @@ type BatchBattleRequest struct {
 
 func (x *BatchBattleRequest) Reset() {
 	*x = BatchBattleRequest{}
-	mi := &file_battle_proto_msgTypes[12]
+	mi := &file_battle_proto_msgTypes[16]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -1065,7 +1426,7 @@ func (x *BatchBattleRequest) String() string {
 func (*BatchBattleRequest) ProtoMessage() {}
 
 func (x *BatchBattleRequest) ProtoReflect() protoreflect.Message {
-	mi := &file_battle_proto_msgTypes[12]
+	mi := &file_battle_proto_msgTypes[16]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -1078,7 +1439,7 @@ func (x *BatchBattleRequest) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use BatchBattleRequest.ProtoReflect.Descriptor instead.
 func (*BatchBattleRequest) Descriptor() ([]byte, []int) {
-	return file_battle_proto_rawDescGZIP(), []int{12}
+	return file_battle_proto_rawDescGZIP(), []int{16}
 }
 
 func (x *BatchBattleRequest) GetBattles() []*StartBattle {
@@ -1116,7 +1477,7 @@ type BatchBattleResponse struct {
 
 func (x *BatchBattleResponse) Reset() {
 	*x = BatchBattleResponse{}
-	mi := &file_battle_proto_msgTypes[13]
+	mi := &file_battle_proto_msgTypes[17]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -1128,7 +1489,7 @@ func (x *BatchBattleResponse) String() string {
 func (*BatchBattleResponse) ProtoMessage() {}
 
 func (x *BatchBattleResponse) ProtoReflect() protoreflect.Message {
-	mi := &file_battle_proto_msgTypes[13]
+	mi := &file_battle_proto_msgTypes[17]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -1141,7 +1502,7 @@ func (x *BatchBattleResponse) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use BatchBattleResponse.ProtoReflect.Descriptor instead.
 func (*BatchBattleResponse) Descriptor() ([]byte, []int) {
-	return file_battle_proto_rawDescGZIP(), []int{13}
+	return file_battle_proto_rawDescGZIP(), []int{17}
 }
 
 func (x *BatchBattleResponse) GetResults() []*BattleResult {
@@ -1180,6 +1541,10 @@ func (x *BatchBattleResponse) GetTotalDuration() int64 {
 }
 
 // 战斗事件 (用于回放)
+// tick 驱动的战斗中 timestamp 为事件发生的逻辑帧，extra["round"] 为回合数
+// "attack" 的 performer_id/target_id 为单位ID，击败目标时 extra["killed"] = "1"；"end" 的为胜方/败方队伍ID
+// 道具 ("item") 和用户操作 ("skill") 每修改一个属性记录一个事件: performer_id/target_id 为使用者单位，
+// value 为属性变化量，extra 中有 attribute_id 以及 item_id + quantity 或 operation
 type BattleEvent struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
 	Timestamp     int64                  `protobuf:"varint,1,opt,name=timestamp,proto3" json:"timestamp,omitempty"`                                                                  // 事件时间戳 (毫秒)
@@ -1194,7 +1559,7 @@ type BattleEvent struct {
 
 func (x *BattleEvent) Reset() {
 	*x = BattleEvent{}
-	mi := &file_battle_proto_msgTypes[14]
+	mi := &file_battle_proto_msgTypes[18]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -1206,7 +1571,7 @@ func (x *BattleEvent) String() string {
 func (*BattleEvent) ProtoMessage() {}
 
 func (x *BattleEvent) ProtoReflect() protoreflect.Message {
-	mi := &file_battle_proto_msgTypes[14]
+	mi := &file_battle_proto_msgTypes[18]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -1219,7 +1584,7 @@ func (x *BattleEvent) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use BattleEvent.ProtoReflect.Descriptor instead.
 func (*BattleEvent) Descriptor() ([]byte, []int) {
-	return file_battle_proto_rawDescGZIP(), []int{14}
+	return file_battle_proto_rawDescGZIP(), []int{18}
 }
 
 func (x *BattleEvent) GetTimestamp() int64 {
@@ -1265,6 +1630,7 @@ func (x *BattleEvent) GetExtra() map[string]string {
 }
 
 // 战斗回放
+// tick 驱动的战斗中 start_time / end_time 为第一回合和最后一回合的逻辑帧
 type BattleReplay struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
 	BattleId      uint32                 `protobuf:"varint,1,opt,name=battle_id,json=battleId,proto3" json:"battle_id,omitempty"`    // 战斗ID
@@ -1281,7 +1647,7 @@ type BattleReplay struct {
 
 func (x *BattleReplay) Reset() {
 	*x = BattleReplay{}
-	mi := &file_battle_proto_msgTypes[15]
+	mi := &file_battle_proto_msgTypes[19]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -1293,7 +1659,7 @@ func (x *BattleReplay) String() string {
 func (*BattleReplay) ProtoMessage() {}
 
 func (x *BattleReplay) ProtoReflect() protoreflect.Message {
-	mi := &file_battle_proto_msgTypes[15]
+	mi := &file_battle_proto_msgTypes[19]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -1306,7 +1672,7 @@ func (x *BattleReplay) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use BattleReplay.ProtoReflect.Descriptor instead.
 func (*BattleReplay) Descriptor() ([]byte, []int) {
-	return file_battle_proto_rawDescGZIP(), []int{15}
+	return file_battle_proto_rawDescGZIP(), []int{19}
 }
 
 func (x *BattleReplay) GetBattleId() uint32 {
@@ -1379,7 +1745,7 @@ type ProgressReport struct {
 
 func (x *ProgressReport) Reset() {
 	*x = ProgressReport{}
-	mi := &file_battle_proto_msgTypes[16]
+	mi := &file_battle_proto_msgTypes[20]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -1391,7 +1757,7 @@ func (x *ProgressReport) String() string {
 func (*ProgressReport) ProtoMessage() {}
 
 func (x *ProgressReport) ProtoReflect() protoreflect.Message {
-	mi := &file_battle_proto_msgTypes[16]
+	mi := &file_battle_proto_msgTypes[20]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -1404,7 +1770,7 @@ func (x *ProgressReport) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use ProgressReport.ProtoReflect.Descriptor instead.
 func (*ProgressReport) Descriptor() ([]byte, []int) {
-	return file_battle_proto_rawDescGZIP(), []int{16}
+	return file_battle_proto_rawDescGZIP(), []int{20}
 }
 
 func (x *ProgressReport) GetBattleId() uint32 {
@@ -1456,7 +1822,7 @@ type BattleNotification struct {
 
 func (x *BattleNotification) Reset() {
 	*x = BattleNotification{}
-	mi := &file_battle_proto_msgTypes[17]
+	mi := &file_battle_proto_msgTypes[21]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -1468,7 +1834,7 @@ func (x *BattleNotification) String() string {
 func (*BattleNotification) ProtoMessage() {}
 
 func (x *BattleNotification) ProtoReflect() protoreflect.Message {
-	mi := &file_battle_proto_msgTypes[17]
+	mi := &file_battle_proto_msgTypes[21]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -1481,7 +1847,7 @@ func (x *BattleNotification) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use BattleNotification.ProtoReflect.Descriptor instead.
 func (*BattleNotification) Descriptor() ([]byte, []int) {
-	return file_battle_proto_rawDescGZIP(), []int{17}
+	return file_battle_proto_rawDescGZIP(), []int{21}
 }
 
 func (x *BattleNotification) GetTimestamp() int64 {
@@ -1534,7 +1900,7 @@ type BattleContext struct {
 
 func (x *BattleContext) Reset() {
 	*x = BattleContext{}
-	mi := &file_battle_proto_msgTypes[18]
+	mi := &file_battle_proto_msgTypes[22]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -1546,7 +1912,7 @@ func (x *BattleContext) String() string {
 func (*BattleContext) ProtoMessage() {}
 
 func (x *BattleContext) ProtoReflect() protoreflect.Message {
-	mi := &file_battle_proto_msgTypes[18]
+	mi := &file_battle_proto_msgTypes[22]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -1559,7 +1925,7 @@ func (x *BattleContext) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use BattleContext.ProtoReflect.Descriptor instead.
 func (*BattleContext) Descriptor() ([]byte, []int) {
-	return file_battle_proto_rawDescGZIP(), []int{18}
+	return file_battle_proto_rawDescGZIP(), []int{22}
 }
 
 func (x *BattleContext) GetBattleId() uint32 {
@@ -1617,139 +1983,803 @@ func (*BattleContext_BattleInput) isBattleContext_Option() {}
 
 func (*BattleContext_BattleOutput) isBattleContext_Option() {}
 
-var File_battle_proto protoreflect.FileDescriptor
+// 单场战斗快照 (对应 C# BattleInstance)
+type BattleSnapshot struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	BattleId      uint32                 `protobuf:"varint,1,opt,name=battle_id,json=battleId,proto3" json:"battle_id,omitempty"`                // 战斗ID
+	AtkTeamId     uint32                 `protobuf:"varint,2,opt,name=atk_team_id,json=atkTeamId,proto3" json:"atk_team_id,omitempty"`           // 攻击方队伍ID
+	DefTeamId     uint32                 `protobuf:"varint,3,opt,name=def_team_id,json=defTeamId,proto3" json:"def_team_id,omitempty"`           // 防守方队伍ID
+	AtkHealth     int32                  `protobuf:"varint,4,opt,name=atk_health,json=atkHealth,proto3" json:"atk_health,omitempty"`             // 攻击方剩余血量
+	DefHealth     int32                  `protobuf:"varint,5,opt,name=def_health,json=defHealth,proto3" json:"def_health,omitempty"`             // 防守方剩余血量
+	CurrentRound  int32                  `protobuf:"varint,6,opt,name=current_round,json=currentRound,proto3" json:"current_round,omitempty"`    // 当前回合
+	IsFinished    bool                   `protobuf:"varint,7,opt,name=is_finished,json=isFinished,proto3" json:"is_finished,omitempty"`          // 是否已结束
+	Winner        uint32                 `protobuf:"varint,8,opt,name=winner,proto3" json:"winner,omitempty"`                                    // 获胜方队伍ID (未结束时为 0)
+	Seed          uint64                 `protobuf:"varint,9,opt,name=seed,proto3" json:"seed,omitempty"`                                        // 创建战斗时的随机种子
+	RngState      uint64                 `protobuf:"varint,10,opt,name=rng_state,json=rngState,proto3" json:"rng_state,omitempty"`               // 随机数生成器当前状态
+	StartFrame    uint64                 `protobuf:"varint,11,opt,name=start_frame,json=startFrame,proto3" json:"start_frame,omitempty"`         // 第一回合的逻辑帧 (未开始时为 0)
+	Events        []*BattleEvent         `protobuf:"bytes,12,rep,name=events,proto3" json:"events,omitempty"`                                    // 已记录的回放事件
+	State         string                 `protobuf:"bytes,13,opt,name=state,proto3" json:"state,omitempty"`                                      // 战斗状态 (running/paused/finished/destroyed，为空时由 is_finished 推断)
+	EndRequested  bool                   `protobuf:"varint,14,opt,name=end_requested,json=endRequested,proto3" json:"end_requested,omitempty"`   // 已强制结束，下一个逻辑帧输出结果
+	AtkMaxHealth  int32                  `protobuf:"varint,15,opt,name=atk_max_health,json=atkMaxHealth,proto3" json:"atk_max_health,omitempty"` // 攻击方最大生命 (为 0 时取默认血量)
+	DefMaxHealth  int32                  `protobuf:"varint,16,opt,name=def_max_health,json=defMaxHealth,proto3" json:"def_max_health,omitempty"` // 防守方最大生命
+	AtkAttack     int32                  `protobuf:"varint,17,opt,name=atk_attack,json=atkAttack,proto3" json:"atk_attack,omitempty"`            // 攻击方攻击力
+	DefAttack     int32                  `protobuf:"varint,18,opt,name=def_attack,json=defAttack,proto3" json:"def_attack,omitempty"`            // 防守方攻击力
+	Units         []*BattleUnit          `protobuf:"bytes,19,rep,name=units,proto3" json:"units,omitempty"`                                      // 所有单位 (为空时由 4/5/15~18 恢复为每队一个单位)
+	AtkTeam       *Team                  `protobuf:"bytes,20,opt,name=atk_team,json=atkTeam,proto3" json:"atk_team,omitempty"`                   // 攻击方队伍 (阵容和名称，用于回放)
+	DefTeam       *Team                  `protobuf:"bytes,21,opt,name=def_team,json=defTeam,proto3" json:"def_team,omitempty"`                   // 防守方队伍
+	ShardId       uint32                 `protobuf:"varint,22,opt,name=shard_id,json=shardId,proto3" json:"shard_id,omitempty"`                  // 战斗所在的分片
+	Timeout       bool                   `protobuf:"varint,23,opt,name=timeout,proto3" json:"timeout,omitempty"`                                 // 超时强制结束 (BattleEnd.timeout)
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
 
-const file_battle_proto_rawDesc = "" +
-	"\n" +
-	"\fbattle.proto\x12\x06battle\"T\n" +
-	"\x04Team\x12\x16\n" +
-	"\x06lineup\x18\x01 \x03(\rR\x06lineup\x12\x17\n" +
-	"\ateam_id\x18\x02 \x01(\rR\x06teamId\x12\x1b\n" +
-	"\tteam_name\x18\x03 \x01(\tR\bteamName\"\xad\x01\n" +
-	"\tBattleEnv\x12\x1e\n" +
-	"\x03atk\x18\x01 \x01(\v2\f.battle.TeamR\x03atk\x12\x1e\n" +
-	"\x03def\x18\x02 \x01(\v2\f.battle.TeamR\x03def\x12\x1b\n" +
-	"\tbattle_id\x18\x03 \x01(\rR\bbattleId\x12\x1c\n" +
-	"\ttimestamp\x18\x04 \x01(\x03R\ttimestamp\x12%\n" +
-	"\x0econfig_version\x18\x05 \x01(\rR\rconfigVersion\"\x88\x01\n" +
-	"\vStartBattle\x12\x1e\n" +
-	"\x03atk\x18\x01 \x01(\v2\f.battle.TeamR\x03atk\x12\x1e\n" +
-	"\x03def\x18\x02 \x01(\v2\f.battle.TeamR\x03def\x12\x1b\n" +
-	"\tbattle_id\x18\x03 \x01(\rR\bbattleId\x12\x1c\n" +
-	"\ttimestamp\x18\x04 \x01(\x03R\ttimestamp\"\xcf\x01\n" +
-	"\vBattleInput\x12)\n" +
-	"\x03use\x18\x01 \x01(\v2\x15.battle.BattleUseItemH\x00R\x03use\x12.\n" +
-	"\x06resume\x18\x02 \x01(\v2\x14.battle.BattleResumeH\x00R\x06resume\x12+\n" +
-	"\x05pause\x18\x03 \x01(\v2\x13.battle.BattlePauseH\x00R\x05pause\x12/\n" +
-	"\auser_op\x18\x04 \x01(\v2\x14.battle.BattleUserOpH\x00R\x06userOpB\a\n" +
-	"\x05input\"E\n" +
-	"\fBattleUserOp\x12\x17\n" +
-	"\achar_id\x18\x01 \x01(\x05R\x06charId\x12\x1c\n" +
-	"\toperation\x18\x02 \x01(\tR\toperation\"_\n" +
-	"\rBattleUseItem\x12\x19\n" +
-	"\bitem_ids\x18\x01 \x03(\rR\aitemIds\x12\x17\n" +
-	"\auser_id\x18\x02 \x01(\rR\x06userId\x12\x1a\n" +
-	"\bquantity\x18\x03 \x01(\x05R\bquantity\"\x0e\n" +
-	"\fBattleResume\"\r\n" +
-	"\vBattlePause\"x\n" +
-	"\fBattleOutput\x12.\n" +
-	"\x06result\x18\x01 \x01(\v2\x14.battle.BattleResultH\x00R\x06result\x12.\n" +
-	"\x06replay\x18\x02 \x01(\v2\x14.battle.BattleReplayH\x00R\x06replayB\b\n" +
-	"\x06output\"\xcf\x01\n" +
-	"\fBattleResult\x12\x16\n" +
-	"\x06winner\x18\x01 \x01(\rR\x06winner\x12\x14\n" +
-	"\x05loser\x18\x02 \x01(\rR\x05loser\x12\x1d\n" +
-	"\n" +
-	"atk_damage\x18\x03 \x01(\x05R\tatkDamage\x12\x1d\n" +
-	"\n" +
-	"def_damage\x18\x04 \x01(\x05R\tdefDamage\x12\x14\n" +
-	"\x05kills\x18\x05 \x03(\rR\x05kills\x12\x1a\n" +
-	"\bduration\x18\x06 \x01(\x03R\bduration\x12!\n" +
-	"\fbattle_score\x18\a \x01(\x05R\vbattleScore\"\xb3\x01\n" +
-	"\fBattleStatus\x12\x1b\n" +
-	"\tbattle_id\x18\x01 \x01(\rR\bbattleId\x12\x14\n" +
-	"\x05round\x18\x02 \x01(\x05R\x05round\x12\x1d\n" +
-	"\n" +
-	"atk_health\x18\x03 \x01(\x05R\tatkHealth\x12\x1d\n" +
-	"\n" +
-	"def_health\x18\x04 \x01(\x05R\tdefHealth\x12\x14\n" +
-	"\x05state\x18\x05 \x01(\tR\x05state\x12\x1c\n" +
-	"\ttimestamp\x18\x06 \x01(\x03R\ttimestamp\"t\n" +
-	"\x0eBattleResponse\x12\x12\n" +
-	"\x04code\x18\x01 \x01(\x05R\x04code\x12\x18\n" +
-	"\amessage\x18\x02 \x01(\tR\amessage\x12\x16\n" +
-	"\x06result\x18\x03 \x01(\fR\x06result\x12\x1c\n" +
-	"\ttimestamp\x18\x04 \x01(\x03R\ttimestamp\"z\n" +
-	"\x12BatchBattleRequest\x12-\n" +
-	"\abattles\x18\x01 \x03(\v2\x13.battle.StartBattleR\abattles\x12\x19\n" +
-	"\bbatch_id\x18\x02 \x01(\tR\abatchId\x12\x1a\n" +
-	"\bparallel\x18\x03 \x01(\x05R\bparallel\"\xd1\x01\n" +
-	"\x13BatchBattleResponse\x12.\n" +
-	"\aresults\x18\x01 \x03(\v2\x14.battle.BattleResultR\aresults\x12\x19\n" +
-	"\bbatch_id\x18\x02 \x01(\tR\abatchId\x12#\n" +
-	"\rsuccess_count\x18\x03 \x01(\x05R\fsuccessCount\x12#\n" +
-	"\rfailure_count\x18\x04 \x01(\x05R\ffailureCount\x12%\n" +
-	"\x0etotal_duration\x18\x05 \x01(\x03R\rtotalDuration\"\x90\x02\n" +
-	"\vBattleEvent\x12\x1c\n" +
-	"\ttimestamp\x18\x01 \x01(\x03R\ttimestamp\x12\x1d\n" +
-	"\n" +
-	"event_type\x18\x02 \x01(\tR\teventType\x12!\n" +
-	"\fperformer_id\x18\x03 \x01(\rR\vperformerId\x12\x1b\n" +
-	"\ttarget_id\x18\x04 \x01(\rR\btargetId\x12\x14\n" +
-	"\x05value\x18\x05 \x01(\x05R\x05value\x124\n" +
-	"\x05extra\x18\x06 \x03(\v2\x1e.battle.BattleEvent.ExtraEntryR\x05extra\x1a8\n" +
-	"\n" +
-	"ExtraEntry\x12\x10\n" +
-	"\x03key\x18\x01 \x01(\tR\x03key\x12\x14\n" +
-	"\x05value\x18\x02 \x01(\tR\x05value:\x028\x01\"\xac\x02\n" +
-	"\fBattleReplay\x12\x1b\n" +
-	"\tbattle_id\x18\x01 \x01(\rR\bbattleId\x12\x1d\n" +
-	"\n" +
-	"start_time\x18\x02 \x01(\x03R\tstartTime\x12\x19\n" +
-	"\bend_time\x18\x03 \x01(\x03R\aendTime\x12'\n" +
-	"\batk_team\x18\x04 \x01(\v2\f.battle.TeamR\aatkTeam\x12'\n" +
-	"\bdef_team\x18\x05 \x01(\v2\f.battle.TeamR\adefTeam\x12+\n" +
-	"\x06events\x18\x06 \x03(\v2\x13.battle.BattleEventR\x06events\x12,\n" +
-	"\x06result\x18\a \x01(\v2\x14.battle.BattleResultR\x06result\x12\x18\n" +
-	"\aversion\x18\b \x01(\tR\aversion\"\xc9\x01\n" +
-	"\x0eProgressReport\x12\x1b\n" +
-	"\tbattle_id\x18\x01 \x01(\rR\bbattleId\x12)\n" +
-	"\x10progress_percent\x18\x02 \x01(\x05R\x0fprogressPercent\x12#\n" +
-	"\rcurrent_round\x18\x03 \x01(\x05R\fcurrentRound\x12,\n" +
-	"\x06status\x18\x04 \x01(\v2\x14.battle.BattleStatusR\x06status\x12\x1c\n" +
-	"\ttimestamp\x18\x05 \x01(\x03R\ttimestamp\"\xd5\x01\n" +
-	"\x12BattleNotification\x12\x1c\n" +
-	"\ttimestamp\x18\x01 \x01(\x03R\ttimestamp\x12E\n" +
-	"\x11notification_type\x18\x02 \x01(\x0e2\x18.battle.NotificationTypeR\x10notificationType\x12\x1b\n" +
-	"\tbattle_id\x18\x03 \x01(\rR\bbattleId\x12\x18\n" +
-	"\apayload\x18\x04 \x01(\fR\apayload\x12#\n" +
-	"\rerror_message\x18\x05 \x01(\tR\ferrorMessage\"\xc1\x01\n" +
-	"\rBattleContext\x12\x1b\n" +
-	"\tbattle_id\x18\x01 \x01(\rR\bbattleId\x12\x12\n" +
-	"\x04tick\x18\x02 \x01(\x04R\x04tick\x128\n" +
-	"\fbattle_input\x18\x03 \x01(\v2\x13.battle.BattleInputH\x00R\vbattleInput\x12;\n" +
-	"\rbattle_output\x18\x04 \x01(\v2\x14.battle.BattleOutputH\x00R\fbattleOutputB\b\n" +
-	"\x06option*|\n" +
-	"\x14BattleInputOperation\x12\t\n" +
-	"\x05Start\x10\x00\x12\r\n" +
-	"\tTickEvent\x10\x01\x12\v\n" +
-	"\aUseItem\x10\x02\x12\a\n" +
-	"\x03End\x10\x03\x12\t\n" +
-	"\x05Pause\x10\x04\x12\n" +
-	"\n" +
-	"\x06Resume\x10\x05\x12\x10\n" +
-	"\fStatusUpdate\x10\x06\x12\v\n" +
-	"\aDestroy\x10\a*\xc5\x01\n" +
-	"\x0fBattleErrorCode\x12\v\n" +
-	"\aSUCCESS\x10\x00\x12\x13\n" +
-	"\x0fINVALID_REQUEST\x10\x01\x12\x12\n" +
-	"\x0eTEAM_NOT_FOUND\x10\x02\x12\x15\n" +
-	"\x11INVALID_TEAM_SIZE\x10\x03\x12\x14\n" +
-	"\x10BATTLE_NOT_FOUND\x10\x04\x12\x14\n" +
-	"\x10DUPLICATE_BATTLE\x10\x05\x12\x12\n" +
-	"\x0eINTERNAL_ERROR\x10\x06\x12\v\n" +
+func (x *BattleSnapshot) Reset() {
+	*x = BattleSnapshot{}
+	mi := &file_battle_proto_msgTypes[23]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *BattleSnapshot) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*BattleSnapshot) ProtoMessage() {}
+
+func (x *BattleSnapshot) ProtoReflect() protoreflect.Message {
+	mi := &file_battle_proto_msgTypes[23]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use BattleSnapshot.ProtoReflect.Descriptor instead.
+func (*BattleSnapshot) Descriptor() ([]byte, []int) {
+	return file_battle_proto_rawDescGZIP(), []int{23}
+}
+
+func (x *BattleSnapshot) GetBattleId() uint32 {
+	if x != nil {
+		return x.BattleId
+	}
+	return 0
+}
+
+func (x *BattleSnapshot) GetAtkTeamId() uint32 {
+	if x != nil {
+		return x.AtkTeamId
+	}
+	return 0
+}
+
+func (x *BattleSnapshot) GetDefTeamId() uint32 {
+	if x != nil {
+		return x.DefTeamId
+	}
+	return 0
+}
+
+func (x *BattleSnapshot) GetAtkHealth() int32 {
+	if x != nil {
+		return x.AtkHealth
+	}
+	return 0
+}
+
+func (x *BattleSnapshot) GetDefHealth() int32 {
+	if x != nil {
+		return x.DefHealth
+	}
+	return 0
+}
+
+func (x *BattleSnapshot) GetCurrentRound() int32 {
+	if x != nil {
+		return x.CurrentRound
+	}
+	return 0
+}
+
+func (x *BattleSnapshot) GetIsFinished() bool {
+	if x != nil {
+		return x.IsFinished
+	}
+	return false
+}
+
+func (x *BattleSnapshot) GetWinner() uint32 {
+	if x != nil {
+		return x.Winner
+	}
+	return 0
+}
+
+func (x *BattleSnapshot) GetSeed() uint64 {
+	if x != nil {
+		return x.Seed
+	}
+	return 0
+}
+
+func (x *BattleSnapshot) GetRngState() uint64 {
+	if x != nil {
+		return x.RngState
+	}
+	return 0
+}
+
+func (x *BattleSnapshot) GetStartFrame() uint64 {
+	if x != nil {
+		return x.StartFrame
+	}
+	return 0
+}
+
+func (x *BattleSnapshot) GetEvents() []*BattleEvent {
+	if x != nil {
+		return x.Events
+	}
+	return nil
+}
+
+func (x *BattleSnapshot) GetState() string {
+	if x != nil {
+		return x.State
+	}
+	return ""
+}
+
+func (x *BattleSnapshot) GetEndRequested() bool {
+	if x != nil {
+		return x.EndRequested
+	}
+	return false
+}
+
+func (x *BattleSnapshot) GetAtkMaxHealth() int32 {
+	if x != nil {
+		return x.AtkMaxHealth
+	}
+	return 0
+}
+
+func (x *BattleSnapshot) GetDefMaxHealth() int32 {
+	if x != nil {
+		return x.DefMaxHealth
+	}
+	return 0
+}
+
+func (x *BattleSnapshot) GetAtkAttack() int32 {
+	if x != nil {
+		return x.AtkAttack
+	}
+	return 0
+}
+
+func (x *BattleSnapshot) GetDefAttack() int32 {
+	if x != nil {
+		return x.DefAttack
+	}
+	return 0
+}
+
+func (x *BattleSnapshot) GetUnits() []*BattleUnit {
+	if x != nil {
+		return x.Units
+	}
+	return nil
+}
+
+func (x *BattleSnapshot) GetAtkTeam() *Team {
+	if x != nil {
+		return x.AtkTeam
+	}
+	return nil
+}
+
+func (x *BattleSnapshot) GetDefTeam() *Team {
+	if x != nil {
+		return x.DefTeam
+	}
+	return nil
+}
+
+func (x *BattleSnapshot) GetShardId() uint32 {
+	if x != nil {
+		return x.ShardId
+	}
+	return 0
+}
+
+func (x *BattleSnapshot) GetTimeout() bool {
+	if x != nil {
+		return x.Timeout
+	}
+	return false
+}
+
+// 战斗状态快照 (ExportBattleState / ImportBattleState)
+type BattleStateSnapshot struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Timestamp     int64                  `protobuf:"varint,1,opt,name=timestamp,proto3" json:"timestamp,omitempty"` // 快照时间戳 (毫秒)
+	Battles       []*BattleSnapshot      `protobuf:"bytes,2,rep,name=battles,proto3" json:"battles,omitempty"`      // 所有存活的战斗
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *BattleStateSnapshot) Reset() {
+	*x = BattleStateSnapshot{}
+	mi := &file_battle_proto_msgTypes[24]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *BattleStateSnapshot) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*BattleStateSnapshot) ProtoMessage() {}
+
+func (x *BattleStateSnapshot) ProtoReflect() protoreflect.Message {
+	mi := &file_battle_proto_msgTypes[24]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use BattleStateSnapshot.ProtoReflect.Descriptor instead.
+func (*BattleStateSnapshot) Descriptor() ([]byte, []int) {
+	return file_battle_proto_rawDescGZIP(), []int{24}
+}
+
+func (x *BattleStateSnapshot) GetTimestamp() int64 {
+	if x != nil {
+		return x.Timestamp
+	}
+	return 0
+}
+
+func (x *BattleStateSnapshot) GetBattles() []*BattleSnapshot {
+	if x != nil {
+		return x.Battles
+	}
+	return nil
+}
+
+// C# 库信息，Go 在加载库时校验
+type LibraryInfo struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Version       string                 `protobuf:"bytes,1,opt,name=version,proto3" json:"version,omitempty"`                          // 库版本 (与 GetLibVersion 一致)
+	AbiVersion    uint32                 `protobuf:"varint,2,opt,name=abi_version,json=abiVersion,proto3" json:"abi_version,omitempty"` // 导出函数 ABI 版本，导出函数签名变化时递增
+	SchemaHash    string                 `protobuf:"bytes,3,opt,name=schema_hash,json=schemaHash,proto3" json:"schema_hash,omitempty"`  // battle.proto 描述符的 SHA-256 (十六进制)
+	Features      []string               `protobuf:"bytes,4,rep,name=features,proto3" json:"features,omitempty"`                        // 支持的可选特性 (例如 "hot_reload")
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *LibraryInfo) Reset() {
+	*x = LibraryInfo{}
+	mi := &file_battle_proto_msgTypes[25]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *LibraryInfo) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*LibraryInfo) ProtoMessage() {}
+
+func (x *LibraryInfo) ProtoReflect() protoreflect.Message {
+	mi := &file_battle_proto_msgTypes[25]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use LibraryInfo.ProtoReflect.Descriptor instead.
+func (*LibraryInfo) Descriptor() ([]byte, []int) {
+	return file_battle_proto_rawDescGZIP(), []int{25}
+}
+
+func (x *LibraryInfo) GetVersion() string {
+	if x != nil {
+		return x.Version
+	}
+	return ""
+}
+
+func (x *LibraryInfo) GetAbiVersion() uint32 {
+	if x != nil {
+		return x.AbiVersion
+	}
+	return 0
+}
+
+func (x *LibraryInfo) GetSchemaHash() string {
+	if x != nil {
+		return x.SchemaHash
+	}
+	return ""
+}
+
+func (x *LibraryInfo) GetFeatures() []string {
+	if x != nil {
+		return x.Features
+	}
+	return nil
+}
+
+// C# 托管异常报告
+type ManagedException struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Type          string                 `protobuf:"bytes,1,opt,name=type,proto3" json:"type,omitempty"`                               // 异常类型全名 (例如 System.IndexOutOfRangeException)
+	Message       string                 `protobuf:"bytes,2,opt,name=message,proto3" json:"message,omitempty"`                         // 异常消息
+	StackTrace    string                 `protobuf:"bytes,3,opt,name=stack_trace,json=stackTrace,proto3" json:"stack_trace,omitempty"` // 托管调用栈
+	BattleId      uint32                 `protobuf:"varint,4,opt,name=battle_id,json=battleId,proto3" json:"battle_id,omitempty"`      // 异常所属的战斗ID (与战斗无关时为 0)
+	Export        string                 `protobuf:"bytes,5,opt,name=export,proto3" json:"export,omitempty"`                           // 抛出异常时正在执行的导出函数
+	Inner         string                 `protobuf:"bytes,6,opt,name=inner,proto3" json:"inner,omitempty"`                             // 内部异常 (InnerException.ToString()，没有时为空)
+	Timestamp     int64                  `protobuf:"varint,7,opt,name=timestamp,proto3" json:"timestamp,omitempty"`                    // 时间戳 (毫秒)
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ManagedException) Reset() {
+	*x = ManagedException{}
+	mi := &file_battle_proto_msgTypes[26]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ManagedException) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ManagedException) ProtoMessage() {}
+
+func (x *ManagedException) ProtoReflect() protoreflect.Message {
+	mi := &file_battle_proto_msgTypes[26]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ManagedException.ProtoReflect.Descriptor instead.
+func (*ManagedException) Descriptor() ([]byte, []int) {
+	return file_battle_proto_rawDescGZIP(), []int{26}
+}
+
+func (x *ManagedException) GetType() string {
+	if x != nil {
+		return x.Type
+	}
+	return ""
+}
+
+func (x *ManagedException) GetMessage() string {
+	if x != nil {
+		return x.Message
+	}
+	return ""
+}
+
+func (x *ManagedException) GetStackTrace() string {
+	if x != nil {
+		return x.StackTrace
+	}
+	return ""
+}
+
+func (x *ManagedException) GetBattleId() uint32 {
+	if x != nil {
+		return x.BattleId
+	}
+	return 0
+}
+
+func (x *ManagedException) GetExport() string {
+	if x != nil {
+		return x.Export
+	}
+	return ""
+}
+
+func (x *ManagedException) GetInner() string {
+	if x != nil {
+		return x.Inner
+	}
+	return ""
+}
+
+func (x *ManagedException) GetTimestamp() int64 {
+	if x != nil {
+		return x.Timestamp
+	}
+	return 0
+}
+
+// 一帧内收集的战斗输入，一次 FFI 调用提交
+// 每条输入的结果以 BattleErrorCode 写入 Go 提供的 int32 数组，顺序与 contexts 一致
+type BattleContextBatch struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Contexts      []*BattleContext       `protobuf:"bytes,1,rep,name=contexts,proto3" json:"contexts,omitempty"` // 只接受 BattleInput 类型的 BattleContext
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *BattleContextBatch) Reset() {
+	*x = BattleContextBatch{}
+	mi := &file_battle_proto_msgTypes[27]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *BattleContextBatch) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*BattleContextBatch) ProtoMessage() {}
+
+func (x *BattleContextBatch) ProtoReflect() protoreflect.Message {
+	mi := &file_battle_proto_msgTypes[27]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use BattleContextBatch.ProtoReflect.Descriptor instead.
+func (*BattleContextBatch) Descriptor() ([]byte, []int) {
+	return file_battle_proto_rawDescGZIP(), []int{27}
+}
+
+func (x *BattleContextBatch) GetContexts() []*BattleContext {
+	if x != nil {
+		return x.Contexts
+	}
+	return nil
+}
+
+// 战斗日志条目
+type JournalEntry struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	Frame uint64                 `protobuf:"varint,1,opt,name=frame,proto3" json:"frame,omitempty"` // 条目所属的逻辑帧
+	// Types that are valid to be assigned to Entry:
+	//
+	//	*JournalEntry_Env
+	//	*JournalEntry_Context
+	Entry         isJournalEntry_Entry `protobuf_oneof:"entry"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *JournalEntry) Reset() {
+	*x = JournalEntry{}
+	mi := &file_battle_proto_msgTypes[28]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *JournalEntry) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*JournalEntry) ProtoMessage() {}
+
+func (x *JournalEntry) ProtoReflect() protoreflect.Message {
+	mi := &file_battle_proto_msgTypes[28]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use JournalEntry.ProtoReflect.Descriptor instead.
+func (*JournalEntry) Descriptor() ([]byte, []int) {
+	return file_battle_proto_rawDescGZIP(), []int{28}
+}
+
+func (x *JournalEntry) GetFrame() uint64 {
+	if x != nil {
+		return x.Frame
+	}
+	return 0
+}
+
+func (x *JournalEntry) GetEntry() isJournalEntry_Entry {
+	if x != nil {
+		return x.Entry
+	}
+	return nil
+}
+
+func (x *JournalEntry) GetEnv() *BattleEnv {
+	if x != nil {
+		if x, ok := x.Entry.(*JournalEntry_Env); ok {
+			return x.Env
+		}
+	}
+	return nil
+}
+
+func (x *JournalEntry) GetContext() *BattleContext {
+	if x != nil {
+		if x, ok := x.Entry.(*JournalEntry_Context); ok {
+			return x.Context
+		}
+	}
+	return nil
+}
+
+type isJournalEntry_Entry interface {
+	isJournalEntry_Entry()
+}
+
+type JournalEntry_Env struct {
+	Env *BattleEnv `protobuf:"bytes,2,opt,name=env,proto3,oneof"` // 创建战斗，frame 为战斗执行第一回合的帧
+}
+
+type JournalEntry_Context struct {
+	Context *BattleContext `protobuf:"bytes,3,opt,name=context,proto3,oneof"` // 跨越 Go/C# 边界的输入 (Tick 为应用它的帧) 或输出
+}
+
+func (*JournalEntry_Env) isJournalEntry_Entry() {}
+
+func (*JournalEntry_Context) isJournalEntry_Entry() {}
+
+var File_battle_proto protoreflect.FileDescriptor
+
+const file_battle_proto_rawDesc = "" +
+	"\n" +
+	"\fbattle.proto\x12\x06battle\"T\n" +
+	"\x04Team\x12\x16\n" +
+	"\x06lineup\x18\x01 \x03(\rR\x06lineup\x12\x17\n" +
+	"\ateam_id\x18\x02 \x01(\rR\x06teamId\x12\x1b\n" +
+	"\tteam_name\x18\x03 \x01(\tR\bteamName\"\xa7\x01\n" +
+	"\n" +
+	"BattleUnit\x12\x17\n" +
+	"\aunit_id\x18\x01 \x01(\rR\x06unitId\x12\x17\n" +
+	"\ateam_id\x18\x02 \x01(\rR\x06teamId\x12\x1d\n" +
+	"\n" +
+	"max_health\x18\x03 \x01(\x05R\tmaxHealth\x12\x16\n" +
+	"\x06health\x18\x04 \x01(\x05R\x06health\x12\x16\n" +
+	"\x06attack\x18\x05 \x01(\x05R\x06attack\x12\x18\n" +
+	"\adefense\x18\x06 \x01(\x05R\adefense\"\xc1\x01\n" +
+	"\tBattleEnv\x12\x1e\n" +
+	"\x03atk\x18\x01 \x01(\v2\f.battle.TeamR\x03atk\x12\x1e\n" +
+	"\x03def\x18\x02 \x01(\v2\f.battle.TeamR\x03def\x12\x1b\n" +
+	"\tbattle_id\x18\x03 \x01(\rR\bbattleId\x12\x1c\n" +
+	"\ttimestamp\x18\x04 \x01(\x03R\ttimestamp\x12%\n" +
+	"\x0econfig_version\x18\x05 \x01(\rR\rconfigVersion\x12\x12\n" +
+	"\x04seed\x18\x06 \x01(\x04R\x04seed\"\x9c\x01\n" +
+	"\vStartBattle\x12\x1e\n" +
+	"\x03atk\x18\x01 \x01(\v2\f.battle.TeamR\x03atk\x12\x1e\n" +
+	"\x03def\x18\x02 \x01(\v2\f.battle.TeamR\x03def\x12\x1b\n" +
+	"\tbattle_id\x18\x03 \x01(\rR\bbattleId\x12\x1c\n" +
+	"\ttimestamp\x18\x04 \x01(\x03R\ttimestamp\x12\x12\n" +
+	"\x04seed\x18\x05 \x01(\x04R\x04seed\"\xa9\x02\n" +
+	"\vBattleInput\x12)\n" +
+	"\x03use\x18\x01 \x01(\v2\x15.battle.BattleUseItemH\x00R\x03use\x12.\n" +
+	"\x06resume\x18\x02 \x01(\v2\x14.battle.BattleResumeH\x00R\x06resume\x12+\n" +
+	"\x05pause\x18\x03 \x01(\v2\x13.battle.BattlePauseH\x00R\x05pause\x12/\n" +
+	"\auser_op\x18\x04 \x01(\v2\x14.battle.BattleUserOpH\x00R\x06userOp\x12%\n" +
+	"\x03end\x18\x05 \x01(\v2\x11.battle.BattleEndH\x00R\x03end\x121\n" +
+	"\adestroy\x18\x06 \x01(\v2\x15.battle.BattleDestroyH\x00R\adestroyB\a\n" +
+	"\x05input\"E\n" +
+	"\fBattleUserOp\x12\x17\n" +
+	"\achar_id\x18\x01 \x01(\x05R\x06charId\x12\x1c\n" +
+	"\toperation\x18\x02 \x01(\tR\toperation\"_\n" +
+	"\rBattleUseItem\x12\x19\n" +
+	"\bitem_ids\x18\x01 \x03(\rR\aitemIds\x12\x17\n" +
+	"\auser_id\x18\x02 \x01(\rR\x06userId\x12\x1a\n" +
+	"\bquantity\x18\x03 \x01(\x05R\bquantity\"\x0e\n" +
+	"\fBattleResume\"\r\n" +
+	"\vBattlePause\"=\n" +
+	"\tBattleEnd\x12\x16\n" +
+	"\x06winner\x18\x01 \x01(\rR\x06winner\x12\x18\n" +
+	"\atimeout\x18\x02 \x01(\bR\atimeout\"\x0f\n" +
+	"\rBattleDestroy\"x\n" +
+	"\fBattleOutput\x12.\n" +
+	"\x06result\x18\x01 \x01(\v2\x14.battle.BattleResultH\x00R\x06result\x12.\n" +
+	"\x06replay\x18\x02 \x01(\v2\x14.battle.BattleReplayH\x00R\x06replayB\b\n" +
+	"\x06output\"\xe9\x01\n" +
+	"\fBattleResult\x12\x16\n" +
+	"\x06winner\x18\x01 \x01(\rR\x06winner\x12\x14\n" +
+	"\x05loser\x18\x02 \x01(\rR\x05loser\x12\x1d\n" +
+	"\n" +
+	"atk_damage\x18\x03 \x01(\x05R\tatkDamage\x12\x1d\n" +
+	"\n" +
+	"def_damage\x18\x04 \x01(\x05R\tdefDamage\x12\x14\n" +
+	"\x05kills\x18\x05 \x03(\rR\x05kills\x12\x1a\n" +
+	"\bduration\x18\x06 \x01(\x03R\bduration\x12!\n" +
+	"\fbattle_score\x18\a \x01(\x05R\vbattleScore\x12\x18\n" +
+	"\atimeout\x18\b \x01(\bR\atimeout\"\xda\x03\n" +
+	"\fBattleStatus\x12\x1b\n" +
+	"\tbattle_id\x18\x01 \x01(\rR\bbattleId\x12\x14\n" +
+	"\x05round\x18\x02 \x01(\x05R\x05round\x12\x1d\n" +
+	"\n" +
+	"atk_health\x18\x03 \x01(\x05R\tatkHealth\x12\x1d\n" +
+	"\n" +
+	"def_health\x18\x04 \x01(\x05R\tdefHealth\x12\x14\n" +
+	"\x05state\x18\x05 \x01(\tR\x05state\x12\x1c\n" +
+	"\ttimestamp\x18\x06 \x01(\x03R\ttimestamp\x12\x1e\n" +
+	"\vatk_team_id\x18\a \x01(\rR\tatkTeamId\x12\x1e\n" +
+	"\vdef_team_id\x18\b \x01(\rR\tdefTeamId\x12\x16\n" +
+	"\x06winner\x18\t \x01(\rR\x06winner\x12$\n" +
+	"\x0eatk_max_health\x18\n" +
+	" \x01(\x05R\fatkMaxHealth\x12$\n" +
+	"\x0edef_max_health\x18\v \x01(\x05R\fdefMaxHealth\x12\x1d\n" +
+	"\n" +
+	"atk_attack\x18\f \x01(\x05R\tatkAttack\x12\x1d\n" +
+	"\n" +
+	"def_attack\x18\r \x01(\x05R\tdefAttack\x12(\n" +
+	"\x05units\x18\x0e \x03(\v2\x12.battle.BattleUnitR\x05units\x12\x19\n" +
+	"\bshard_id\x18\x0f \x01(\rR\ashardId\"B\n" +
+	"\x10BattleStatusList\x12.\n" +
+	"\abattles\x18\x01 \x03(\v2\x14.battle.BattleStatusR\abattles\"\xac\x01\n" +
+	"\x0eBattleResponse\x12\x12\n" +
+	"\x04code\x18\x01 \x01(\x05R\x04code\x12\x18\n" +
+	"\amessage\x18\x02 \x01(\tR\amessage\x12\x16\n" +
+	"\x06result\x18\x03 \x01(\fR\x06result\x12\x1c\n" +
+	"\ttimestamp\x18\x04 \x01(\x03R\ttimestamp\x126\n" +
+	"\texception\x18\x05 \x01(\v2\x18.battle.ManagedExceptionR\texception\"z\n" +
+	"\x12BatchBattleRequest\x12-\n" +
+	"\abattles\x18\x01 \x03(\v2\x13.battle.StartBattleR\abattles\x12\x19\n" +
+	"\bbatch_id\x18\x02 \x01(\tR\abatchId\x12\x1a\n" +
+	"\bparallel\x18\x03 \x01(\x05R\bparallel\"\xd1\x01\n" +
+	"\x13BatchBattleResponse\x12.\n" +
+	"\aresults\x18\x01 \x03(\v2\x14.battle.BattleResultR\aresults\x12\x19\n" +
+	"\bbatch_id\x18\x02 \x01(\tR\abatchId\x12#\n" +
+	"\rsuccess_count\x18\x03 \x01(\x05R\fsuccessCount\x12#\n" +
+	"\rfailure_count\x18\x04 \x01(\x05R\ffailureCount\x12%\n" +
+	"\x0etotal_duration\x18\x05 \x01(\x03R\rtotalDuration\"\x90\x02\n" +
+	"\vBattleEvent\x12\x1c\n" +
+	"\ttimestamp\x18\x01 \x01(\x03R\ttimestamp\x12\x1d\n" +
+	"\n" +
+	"event_type\x18\x02 \x01(\tR\teventType\x12!\n" +
+	"\fperformer_id\x18\x03 \x01(\rR\vperformerId\x12\x1b\n" +
+	"\ttarget_id\x18\x04 \x01(\rR\btargetId\x12\x14\n" +
+	"\x05value\x18\x05 \x01(\x05R\x05value\x124\n" +
+	"\x05extra\x18\x06 \x03(\v2\x1e.battle.BattleEvent.ExtraEntryR\x05extra\x1a8\n" +
+	"\n" +
+	"ExtraEntry\x12\x10\n" +
+	"\x03key\x18\x01 \x01(\tR\x03key\x12\x14\n" +
+	"\x05value\x18\x02 \x01(\tR\x05value:\x028\x01\"\xac\x02\n" +
+	"\fBattleReplay\x12\x1b\n" +
+	"\tbattle_id\x18\x01 \x01(\rR\bbattleId\x12\x1d\n" +
+	"\n" +
+	"start_time\x18\x02 \x01(\x03R\tstartTime\x12\x19\n" +
+	"\bend_time\x18\x03 \x01(\x03R\aendTime\x12'\n" +
+	"\batk_team\x18\x04 \x01(\v2\f.battle.TeamR\aatkTeam\x12'\n" +
+	"\bdef_team\x18\x05 \x01(\v2\f.battle.TeamR\adefTeam\x12+\n" +
+	"\x06events\x18\x06 \x03(\v2\x13.battle.BattleEventR\x06events\x12,\n" +
+	"\x06result\x18\a \x01(\v2\x14.battle.BattleResultR\x06result\x12\x18\n" +
+	"\aversion\x18\b \x01(\tR\aversion\"\xc9\x01\n" +
+	"\x0eProgressReport\x12\x1b\n" +
+	"\tbattle_id\x18\x01 \x01(\rR\bbattleId\x12)\n" +
+	"\x10progress_percent\x18\x02 \x01(\x05R\x0fprogressPercent\x12#\n" +
+	"\rcurrent_round\x18\x03 \x01(\x05R\fcurrentRound\x12,\n" +
+	"\x06status\x18\x04 \x01(\v2\x14.battle.BattleStatusR\x06status\x12\x1c\n" +
+	"\ttimestamp\x18\x05 \x01(\x03R\ttimestamp\"\xd5\x01\n" +
+	"\x12BattleNotification\x12\x1c\n" +
+	"\ttimestamp\x18\x01 \x01(\x03R\ttimestamp\x12E\n" +
+	"\x11notification_type\x18\x02 \x01(\x0e2\x18.battle.NotificationTypeR\x10notificationType\x12\x1b\n" +
+	"\tbattle_id\x18\x03 \x01(\rR\bbattleId\x12\x18\n" +
+	"\apayload\x18\x04 \x01(\fR\apayload\x12#\n" +
+	"\rerror_message\x18\x05 \x01(\tR\ferrorMessage\"\xc1\x01\n" +
+	"\rBattleContext\x12\x1b\n" +
+	"\tbattle_id\x18\x01 \x01(\rR\bbattleId\x12\x12\n" +
+	"\x04tick\x18\x02 \x01(\x04R\x04tick\x128\n" +
+	"\fbattle_input\x18\x03 \x01(\v2\x13.battle.BattleInputH\x00R\vbattleInput\x12;\n" +
+	"\rbattle_output\x18\x04 \x01(\v2\x14.battle.BattleOutputH\x00R\fbattleOutputB\b\n" +
+	"\x06option\"\xfe\x05\n" +
+	"\x0eBattleSnapshot\x12\x1b\n" +
+	"\tbattle_id\x18\x01 \x01(\rR\bbattleId\x12\x1e\n" +
+	"\vatk_team_id\x18\x02 \x01(\rR\tatkTeamId\x12\x1e\n" +
+	"\vdef_team_id\x18\x03 \x01(\rR\tdefTeamId\x12\x1d\n" +
+	"\n" +
+	"atk_health\x18\x04 \x01(\x05R\tatkHealth\x12\x1d\n" +
+	"\n" +
+	"def_health\x18\x05 \x01(\x05R\tdefHealth\x12#\n" +
+	"\rcurrent_round\x18\x06 \x01(\x05R\fcurrentRound\x12\x1f\n" +
+	"\vis_finished\x18\a \x01(\bR\n" +
+	"isFinished\x12\x16\n" +
+	"\x06winner\x18\b \x01(\rR\x06winner\x12\x12\n" +
+	"\x04seed\x18\t \x01(\x04R\x04seed\x12\x1b\n" +
+	"\trng_state\x18\n" +
+	" \x01(\x04R\brngState\x12\x1f\n" +
+	"\vstart_frame\x18\v \x01(\x04R\n" +
+	"startFrame\x12+\n" +
+	"\x06events\x18\f \x03(\v2\x13.battle.BattleEventR\x06events\x12\x14\n" +
+	"\x05state\x18\r \x01(\tR\x05state\x12#\n" +
+	"\rend_requested\x18\x0e \x01(\bR\fendRequested\x12$\n" +
+	"\x0eatk_max_health\x18\x0f \x01(\x05R\fatkMaxHealth\x12$\n" +
+	"\x0edef_max_health\x18\x10 \x01(\x05R\fdefMaxHealth\x12\x1d\n" +
+	"\n" +
+	"atk_attack\x18\x11 \x01(\x05R\tatkAttack\x12\x1d\n" +
+	"\n" +
+	"def_attack\x18\x12 \x01(\x05R\tdefAttack\x12(\n" +
+	"\x05units\x18\x13 \x03(\v2\x12.battle.BattleUnitR\x05units\x12'\n" +
+	"\batk_team\x18\x14 \x01(\v2\f.battle.TeamR\aatkTeam\x12'\n" +
+	"\bdef_team\x18\x15 \x01(\v2\f.battle.TeamR\adefTeam\x12\x19\n" +
+	"\bshard_id\x18\x16 \x01(\rR\ashardId\x12\x18\n" +
+	"\atimeout\x18\x17 \x01(\bR\atimeout\"e\n" +
+	"\x13BattleStateSnapshot\x12\x1c\n" +
+	"\ttimestamp\x18\x01 \x01(\x03R\ttimestamp\x120\n" +
+	"\abattles\x18\x02 \x03(\v2\x16.battle.BattleSnapshotR\abattles\"\x85\x01\n" +
+	"\vLibraryInfo\x12\x18\n" +
+	"\aversion\x18\x01 \x01(\tR\aversion\x12\x1f\n" +
+	"\vabi_version\x18\x02 \x01(\rR\n" +
+	"abiVersion\x12\x1f\n" +
+	"\vschema_hash\x18\x03 \x01(\tR\n" +
+	"schemaHash\x12\x1a\n" +
+	"\bfeatures\x18\x04 \x03(\tR\bfeatures\"\xca\x01\n" +
+	"\x10ManagedException\x12\x12\n" +
+	"\x04type\x18\x01 \x01(\tR\x04type\x12\x18\n" +
+	"\amessage\x18\x02 \x01(\tR\amessage\x12\x1f\n" +
+	"\vstack_trace\x18\x03 \x01(\tR\n" +
+	"stackTrace\x12\x1b\n" +
+	"\tbattle_id\x18\x04 \x01(\rR\bbattleId\x12\x16\n" +
+	"\x06export\x18\x05 \x01(\tR\x06export\x12\x14\n" +
+	"\x05inner\x18\x06 \x01(\tR\x05inner\x12\x1c\n" +
+	"\ttimestamp\x18\a \x01(\x03R\ttimestamp\"G\n" +
+	"\x12BattleContextBatch\x121\n" +
+	"\bcontexts\x18\x01 \x03(\v2\x15.battle.BattleContextR\bcontexts\"\x87\x01\n" +
+	"\fJournalEntry\x12\x14\n" +
+	"\x05frame\x18\x01 \x01(\x04R\x05frame\x12%\n" +
+	"\x03env\x18\x02 \x01(\v2\x11.battle.BattleEnvH\x00R\x03env\x121\n" +
+	"\acontext\x18\x03 \x01(\v2\x15.battle.BattleContextH\x00R\acontextB\a\n" +
+	"\x05entry*|\n" +
+	"\x14BattleInputOperation\x12\t\n" +
+	"\x05Start\x10\x00\x12\r\n" +
+	"\tTickEvent\x10\x01\x12\v\n" +
+	"\aUseItem\x10\x02\x12\a\n" +
+	"\x03End\x10\x03\x12\t\n" +
+	"\x05Pause\x10\x04\x12\n" +
+	"\n" +
+	"\x06Resume\x10\x05\x12\x10\n" +
+	"\fStatusUpdate\x10\x06\x12\v\n" +
+	"\aDestroy\x10\a*\xdf\x01\n" +
+	"\x0fBattleErrorCode\x12\v\n" +
+	"\aSUCCESS\x10\x00\x12\x13\n" +
+	"\x0fINVALID_REQUEST\x10\x01\x12\x12\n" +
+	"\x0eTEAM_NOT_FOUND\x10\x02\x12\x15\n" +
+	"\x11INVALID_TEAM_SIZE\x10\x03\x12\x14\n" +
+	"\x10BATTLE_NOT_FOUND\x10\x04\x12\x14\n" +
+	"\x10DUPLICATE_BATTLE\x10\x05\x12\x12\n" +
+	"\x0eINTERNAL_ERROR\x10\x06\x12\v\n" +
 	"\aTIMEOUT\x10\a\x12\x18\n" +
-	"\x14INVALID_PROTO_FORMAT\x10\b*c\n" +
+	"\x14INVALID_PROTO_FORMAT\x10\b\x12\x18\n" +
+	"\x14INVALID_BATTLE_STATE\x10\t*c\n" +
 	"\x10NotificationType\x12\x11\n" +
 	"\rSTATUS_UPDATE\x10\x00\x12\x12\n" +
 	"\x0eEVENT_OCCURRED\x10\x01\x12\x14\n" +
@@ -1769,59 +2799,82 @@ func file_battle_proto_rawDescGZIP() []byte {
 }
 
 var file_battle_proto_enumTypes = make([]protoimpl.EnumInfo, 3)
-var file_battle_proto_msgTypes = make([]protoimpl.MessageInfo, 20)
+var file_battle_proto_msgTypes = make([]protoimpl.MessageInfo, 30)
 var file_battle_proto_goTypes = []any{
 	(BattleInputOperation)(0),   // 0: battle.BattleInputOperation
 	(BattleErrorCode)(0),        // 1: battle.BattleErrorCode
 	(NotificationType)(0),       // 2: battle.NotificationType
 	(*Team)(nil),                // 3: battle.Team
-	(*BattleEnv)(nil),           // 4: battle.BattleEnv
-	(*StartBattle)(nil),         // 5: battle.StartBattle
-	(*BattleInput)(nil),         // 6: battle.BattleInput
-	(*BattleUserOp)(nil),        // 7: battle.BattleUserOp
-	(*BattleUseItem)(nil),       // 8: battle.BattleUseItem
-	(*BattleResume)(nil),        // 9: battle.BattleResume
-	(*BattlePause)(nil),         // 10: battle.BattlePause
-	(*BattleOutput)(nil),        // 11: battle.BattleOutput
-	(*BattleResult)(nil),        // 12: battle.BattleResult
-	(*BattleStatus)(nil),        // 13: battle.BattleStatus
-	(*BattleResponse)(nil),      // 14: battle.BattleResponse
-	(*BatchBattleRequest)(nil),  // 15: battle.BatchBattleRequest
-	(*BatchBattleResponse)(nil), // 16: battle.BatchBattleResponse
-	(*BattleEvent)(nil),         // 17: battle.BattleEvent
-	(*BattleReplay)(nil),        // 18: battle.BattleReplay
-	(*ProgressReport)(nil),      // 19: battle.ProgressReport
-	(*BattleNotification)(nil),  // 20: battle.BattleNotification
-	(*BattleContext)(nil),       // 21: battle.BattleContext
-	nil,                         // 22: battle.BattleEvent.ExtraEntry
+	(*BattleUnit)(nil),          // 4: battle.BattleUnit
+	(*BattleEnv)(nil),           // 5: battle.BattleEnv
+	(*StartBattle)(nil),         // 6: battle.StartBattle
+	(*BattleInput)(nil),         // 7: battle.BattleInput
+	(*BattleUserOp)(nil),        // 8: battle.BattleUserOp
+	(*BattleUseItem)(nil),       // 9: battle.BattleUseItem
+	(*BattleResume)(nil),        // 10: battle.BattleResume
+	(*BattlePause)(nil),         // 11: battle.BattlePause
+	(*BattleEnd)(nil),           // 12: battle.BattleEnd
+	(*BattleDestroy)(nil),       // 13: battle.BattleDestroy
+	(*BattleOutput)(nil),        // 14: battle.BattleOutput
+	(*BattleResult)(nil),        // 15: battle.BattleResult
+	(*BattleStatus)(nil),        // 16: battle.BattleStatus
+	(*BattleStatusList)(nil),    // 17: battle.BattleStatusList
+	(*BattleResponse)(nil),      // 18: battle.BattleResponse
+	(*BatchBattleRequest)(nil),  // 19: battle.BatchBattleRequest
+	(*BatchBattleResponse)(nil), // 20: battle.BatchBattleResponse
+	(*BattleEvent)(nil),         // 21: battle.BattleEvent
+	(*BattleReplay)(nil),        // 22: battle.BattleReplay
+	(*ProgressReport)(nil),      // 23: battle.ProgressReport
+	(*BattleNotification)(nil),  // 24: battle.BattleNotification
+	(*BattleContext)(nil),       // 25: battle.BattleContext
+	(*BattleSnapshot)(nil),      // 26: battle.BattleSnapshot
+	(*BattleStateSnapshot)(nil), // 27: battle.BattleStateSnapshot
+	(*LibraryInfo)(nil),         // 28: battle.LibraryInfo
+	(*ManagedException)(nil),    // 29: battle.ManagedException
+	(*BattleContextBatch)(nil),  // 30: battle.BattleContextBatch
+	(*JournalEntry)(nil),        // 31: battle.JournalEntry
+	nil,                         // 32: battle.BattleEvent.ExtraEntry
 }
 var file_battle_proto_depIdxs = []int32{
 	3,  // 0: battle.BattleEnv.atk:type_name -> battle.Team
 	3,  // 1: battle.BattleEnv.def:type_name -> battle.Team
 	3,  // 2: battle.StartBattle.atk:type_name -> battle.Team
 	3,  // 3: battle.StartBattle.def:type_name -> battle.Team
-	8,  // 4: battle.BattleInput.use:type_name -> battle.BattleUseItem
-	9,  // 5: battle.BattleInput.resume:type_name -> battle.BattleResume
-	10, // 6: battle.BattleInput.pause:type_name -> battle.BattlePause
-	7,  // 7: battle.BattleInput.user_op:type_name -> battle.BattleUserOp
-	12, // 8: battle.BattleOutput.result:type_name -> battle.BattleResult
-	18, // 9: battle.BattleOutput.replay:type_name -> battle.BattleReplay
-	5,  // 10: battle.BatchBattleRequest.battles:type_name -> battle.StartBattle
-	12, // 11: battle.BatchBattleResponse.results:type_name -> battle.BattleResult
-	22, // 12: battle.BattleEvent.extra:type_name -> battle.BattleEvent.ExtraEntry
-	3,  // 13: battle.BattleReplay.atk_team:type_name -> battle.Team
-	3,  // 14: battle.BattleReplay.def_team:type_name -> battle.Team
-	17, // 15: battle.BattleReplay.events:type_name -> battle.BattleEvent
-	12, // 16: battle.BattleReplay.result:type_name -> battle.BattleResult
-	13, // 17: battle.ProgressReport.status:type_name -> battle.BattleStatus
-	2,  // 18: battle.BattleNotification.notification_type:type_name -> battle.NotificationType
-	6,  // 19: battle.BattleContext.battle_input:type_name -> battle.BattleInput
-	11, // 20: battle.BattleContext.battle_output:type_name -> battle.BattleOutput
-	21, // [21:21] is the sub-list for method output_type
-	21, // [21:21] is the sub-list for method input_type
-	21, // [21:21] is the sub-list for extension type_name
-	21, // [21:21] is the sub-list for extension extendee
-	0,  // [0:21] is the sub-list for field type_name
+	9,  // 4: battle.BattleInput.use:type_name -> battle.BattleUseItem
+	10, // 5: battle.BattleInput.resume:type_name -> battle.BattleResume
+	11, // 6: battle.BattleInput.pause:type_name -> battle.BattlePause
+	8,  // 7: battle.BattleInput.user_op:type_name -> battle.BattleUserOp
+	12, // 8: battle.BattleInput.end:type_name -> battle.BattleEnd
+	13, // 9: battle.BattleInput.destroy:type_name -> battle.BattleDestroy
+	15, // 10: battle.BattleOutput.result:type_name -> battle.BattleResult
+	22, // 11: battle.BattleOutput.replay:type_name -> battle.BattleReplay
+	4,  // 12: battle.BattleStatus.units:type_name -> battle.BattleUnit
+	16, // 13: battle.BattleStatusList.battles:type_name -> battle.BattleStatus
+	29, // 14: battle.BattleResponse.exception:type_name -> battle.ManagedException
+	6,  // 15: battle.BatchBattleRequest.battles:type_name -> battle.StartBattle
+	15, // 16: battle.BatchBattleResponse.results:type_name -> battle.BattleResult
+	32, // 17: battle.BattleEvent.extra:type_name -> battle.BattleEvent.ExtraEntry
+	3,  // 18: battle.BattleReplay.atk_team:type_name -> battle.Team
+	3,  // 19: battle.BattleReplay.def_team:type_name -> battle.Team
+	21, // 20: battle.BattleReplay.events:type_name -> battle.BattleEvent
+	15, // 21: battle.BattleReplay.result:type_name -> battle.BattleResult
+	16, // 22: battle.ProgressReport.status:type_name -> battle.BattleStatus
+	2,  // 23: battle.BattleNotification.notification_type:type_name -> battle.NotificationType
+	7,  // 24: battle.BattleContext.battle_input:type_name -> battle.BattleInput
+	14, // 25: battle.BattleContext.battle_output:type_name -> battle.BattleOutput
+	21, // 26: battle.BattleSnapshot.events:type_name -> battle.BattleEvent
+	4,  // 27: battle.BattleSnapshot.units:type_name -> battle.BattleUnit
+	3,  // 28: battle.BattleSnapshot.atk_team:type_name -> battle.Team
+	3,  // 29: battle.BattleSnapshot.def_team:type_name -> battle.Team
+	26, // 30: battle.BattleStateSnapshot.battles:type_name -> battle.BattleSnapshot
+	25, // 31: battle.BattleContextBatch.contexts:type_name -> battle.BattleContext
+	5,  // 32: battle.JournalEntry.env:type_name -> battle.BattleEnv
+	25, // 33: battle.JournalEntry.context:type_name -> battle.BattleContext
+	34, // [34:34] is the sub-list for method output_type
+	34, // [34:34] is the sub-list for method input_type
+	34, // [34:34] is the sub-list for extension type_name
+	34, // [34:34] is the sub-list for extension extendee
+	0,  // [0:34] is the sub-list for field type_name
 }
 
 func init() { file_battle_proto_init() }
@@ -1829,27 +2882,33 @@ func file_battle_proto_init() {
 	if File_battle_proto != nil {
 		return
 	}
-	file_battle_proto_msgTypes[3].OneofWrappers = []any{
+	file_battle_proto_msgTypes[4].OneofWrappers = []any{
 		(*BattleInput_Use)(nil),
 		(*BattleInput_Resume)(nil),
 		(*BattleInput_Pause)(nil),
 		(*BattleInput_UserOp)(nil),
+		(*BattleInput_End)(nil),
+		(*BattleInput_Destroy)(nil),
 	}
-	file_battle_proto_msgTypes[8].OneofWrappers = []any{
+	file_battle_proto_msgTypes[11].OneofWrappers = []any{
 		(*BattleOutput_Result)(nil),
 		(*BattleOutput_Replay)(nil),
 	}
-	file_battle_proto_msgTypes[18].OneofWrappers = []any{
+	file_battle_proto_msgTypes[22].OneofWrappers = []any{
 		(*BattleContext_BattleInput)(nil),
 		(*BattleContext_BattleOutput)(nil),
 	}
+	file_battle_proto_msgTypes[28].OneofWrappers = []any{
+		(*JournalEntry_Env)(nil),
+		(*JournalEntry_Context)(nil),
+	}
 	type x struct{}
 	out := protoimpl.TypeBuilder{
 		File: protoimpl.DescBuilder{
 			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
 			RawDescriptor: unsafe.Slice(unsafe.StringData(file_battle_proto_rawDesc), len(file_battle_proto_rawDesc)),
 			NumEnums:      3,
-			NumMessages:   20,
+			NumMessages:   30,
 			NumExtensions: 0,
 			NumServices:   0,
 		},
@@ -35,6 +35,17 @@ func (p *ProtoBufferPool) Get() []byte {
 	return buf[:0] // 重置为空，但保留容量
 }
 
+// GetWithCap 从池中获取容量至少为 n 的 buffer
+// 池中 buffer 容量不足时直接分配新的，旧 buffer 放回池中
+func (p *ProtoBufferPool) GetWithCap(n int) []byte {
+	buf := p.Get()
+	if cap(buf) >= n {
+		return buf
+	}
+	p.Put(buf)
+	return make([]byte, 0, n)
+}
+
 // Put 将 buffer 放回池中
 func (p *ProtoBufferPool) Put(buf []byte) {
 	if cap(buf) >= p.size {
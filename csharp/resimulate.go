@@ -0,0 +1,143 @@
+package csharp
+
+import (
+	"cmp"
+	"errors"
+	"fmt"
+	"slices"
+	"unsafe"
+
+	proto_pb "goPureWithCsharp/csharp/proto"
+
+	"google.golang.org/protobuf/proto"
+)
+
+// ============================================================================
+// 复算 - 由战斗环境和输入日志重新执行战斗，校验结果
+// ============================================================================
+//
+// 战斗内的随机数只由 BattleEnv.Seed 决定，输入的 Tick 为应用该输入的 OnTick 帧，
+// 因此相同的 BattleRecord 总是得到相同的结果:
+//
+//	rec := &csharp.BattleRecord{Env: env, StartFrame: start, Inputs: inputs}
+//	if err := csharp.VerifyBattleResult(csharp.NewSimEngine(), rec, claimed); errors.Is(err, csharp.ErrResultMismatch) {
+//	    // 客户端上报的结果与服务器复算不一致
+//	}
+//
+// SimEngine 与 C# 使用相同的随机数算法，可以复算 C# 库中进行的战斗。
+
+// ErrResultMismatch 复算结果与声称的结果不一致
+var ErrResultMismatch = errors.New("战斗结果不一致")
+
+// maxResimulateFrames 复算最多执行的逻辑帧数，超过仍未结束视为失败
+const maxResimulateFrames = 10000
+
+// BattleRecord 复算一场战斗所需的全部数据
+type BattleRecord struct {
+	Env        *proto_pb.BattleEnv       // 创建战斗的环境，Seed 决定随机数
+	StartFrame uint64                    // 战斗执行第一回合的逻辑帧
+	Inputs     []*proto_pb.BattleContext // 输入日志，Tick 为应用该输入的 OnTick 帧
+}
+
+// Resimulate 在 e 上按 rec 重新执行战斗，返回战斗结果
+// e 必须是专用引擎: 复算期间会替换结果回调并推进 e 中的所有战斗，且不能绑定环形缓冲区；
+// 道具和用户操作的效果由配置决定，e 需要已加载与线上相同的 BattleConfigs
+func Resimulate(e Engine, rec *BattleRecord) (*proto_pb.BattleResult, error) {
+	var result *proto_pb.BattleResult
+	err := ReplayBattle(e, rec, func(frame uint64, outputs []*proto_pb.BattleContext) bool {
+		for _, ctx := range outputs {
+			if r := ctx.GetBattleOutput().GetResult(); r != nil {
+				result = r
+			}
+		}
+		return result == nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// ReplayBattle 在 e 上按 rec 重新执行战斗，每帧 OnTick 之后以该战斗在这一帧的输出调用 onFrame
+// onFrame 返回 false 时停止，之后的输入不再应用；执行 maxResimulateFrames 帧仍未停止时返回错误
+// 对 e 的要求与 Resimulate 相同
+func ReplayBattle(e Engine, rec *BattleRecord, onFrame func(frame uint64, outputs []*proto_pb.BattleContext) bool) error {
+	env := rec.Env
+	if env == nil || env.GetAtk() == nil || env.GetDef() == nil {
+		return fmt.Errorf("复算失败: 战斗环境或队伍为空")
+	}
+	battleID := env.GetBattleId()
+
+	inputs := slices.Clone(rec.Inputs)
+	slices.SortStableFunc(inputs, func(a, b *proto_pb.BattleContext) int {
+		return cmp.Compare(a.GetTick(), b.GetTick())
+	})
+	if len(inputs) > 0 && inputs[0].GetTick() < rec.StartFrame {
+		return fmt.Errorf("复算失败: 输入 Tick %d 早于战斗开始帧 %d", inputs[0].GetTick(), rec.StartFrame)
+	}
+
+	var outputs []*proto_pb.BattleContext
+	err := e.RegisterBattleEndNotify(func(dataPtr unsafe.Pointer, dataLen int32) int {
+		ctx := &proto_pb.BattleContext{}
+		if dataPtr == nil || dataLen <= 0 || proto.Unmarshal(unsafe.Slice((*byte)(dataPtr), dataLen), ctx) != nil {
+			return -1
+		}
+		if ctx.GetBattleId() == battleID {
+			outputs = append(outputs, ctx)
+		}
+		return 0
+	})
+	if err != nil {
+		return fmt.Errorf("复算失败: %w", err)
+	}
+	defer e.UnregisterBattleEndNotify()
+
+	if err := e.CreateBattle(env); err != nil {
+		return fmt.Errorf("复算失败: %w", err)
+	}
+	defer e.DestroyBattle(uint64(battleID))
+
+	for frame := rec.StartFrame; frame < rec.StartFrame+maxResimulateFrames; frame++ {
+		n := 0
+		for n < len(inputs) && inputs[n].GetTick() == frame {
+			n++
+		}
+		if n > 0 {
+			if err := resimulateInputs(e, frame, inputs[:n]); err != nil {
+				return err
+			}
+			inputs = inputs[n:]
+		}
+
+		if _, err := e.OnTick(frame); err != nil {
+			return fmt.Errorf("复算失败: 第 %d 帧: %w", frame, err)
+		}
+		produced := outputs
+		outputs = nil
+		if !onFrame(frame, produced) {
+			return nil
+		}
+	}
+	return fmt.Errorf("复算失败: 执行 %d 帧后战斗 %d 仍未结束", maxResimulateFrames, battleID)
+}
+
+// resimulateInputs 提交同一帧的输入
+// 单条输入被拒绝不算失败: 线上同样拒绝了它，复算时得到相同的结果
+func resimulateInputs(e Engine, frame uint64, inputs []*proto_pb.BattleContext) error {
+	if _, err := e.ProcessBattleContextBatch(inputs); err != nil {
+		return fmt.Errorf("复算失败: 第 %d 帧输入: %w", frame, err)
+	}
+	return nil
+}
+
+// VerifyBattleResult 复算 rec 并与 claimed 比较，不一致时返回包装 ErrResultMismatch 的错误
+func VerifyBattleResult(e Engine, rec *BattleRecord, claimed *proto_pb.BattleResult) error {
+	got, err := Resimulate(e, rec)
+	if err != nil {
+		return err
+	}
+	if !proto.Equal(got, claimed) {
+		return fmt.Errorf("%w: 战斗 %d 声称 {%v}, 复算 {%v}", ErrResultMismatch, rec.Env.GetBattleId(), claimed, got)
+	}
+	return nil
+}
@@ -0,0 +1,97 @@
+package csharp
+
+import (
+	"errors"
+	"testing"
+	"unsafe"
+
+	proto_pb "goPureWithCsharp/csharp/proto"
+
+	"google.golang.org/protobuf/proto"
+)
+
+// TestBattleRandSequence 测试 SplitMix64 参考序列，C# BattleRandom 必须产生相同的值
+func TestBattleRandSequence(t *testing.T) {
+	r := newBattleRand(0)
+	want := []uint64{0xE220A8397B1DCDAF, 0x6E789E6AA1B965F4, 0x06C45D188009454F}
+	for i, w := range want {
+		if got := r.next(); got != w {
+			t.Fatalf("❌ 第 %d 个随机数: 期望 0x%X, 实际 0x%X", i, w, got)
+		}
+	}
+}
+
+// TestResimulate 测试相同种子和输入日志复算得到相同结果，篡改的结果被识别
+func TestResimulate(t *testing.T) {
+	env := &proto_pb.BattleEnv{
+		BattleId: 7,
+		Atk:      &proto_pb.Team{TeamId: 100},
+		Def:      &proto_pb.Team{TeamId: 101},
+		Seed:     42,
+	}
+	pause := &proto_pb.BattleContext{
+		BattleId: 7,
+		Tick:     12,
+		Option: &proto_pb.BattleContext_BattleInput{
+			BattleInput: &proto_pb.BattleInput{Input: &proto_pb.BattleInput_Pause{Pause: &proto_pb.BattlePause{}}},
+		},
+	}
+	resume := &proto_pb.BattleContext{
+		BattleId: 7,
+		Tick:     15,
+		Option: &proto_pb.BattleContext_BattleInput{
+			BattleInput: &proto_pb.BattleInput{Input: &proto_pb.BattleInput_Resume{Resume: &proto_pb.BattleResume{}}},
+		},
+	}
+
+	// 线上战斗: 另一场战斗同时进行，不影响战斗 7 的随机数
+	live := NewSimEngine()
+	live.DisableBattleLogging()
+	defer live.Close()
+
+	var claimed *proto_pb.BattleResult
+	live.RegisterBattleEndNotify(func(dataPtr unsafe.Pointer, dataLen int32) int {
+		ctx := &proto_pb.BattleContext{}
+		if err := proto.Unmarshal(unsafe.Slice((*byte)(dataPtr), dataLen), ctx); err == nil && ctx.GetBattleId() == 7 {
+			if r := ctx.GetBattleOutput().GetResult(); r != nil {
+				claimed = r
+			}
+		}
+		return 0
+	})
+	live.CreateBattle(teamEnv(8, 200, 201, 0))
+	if err := live.CreateBattle(env); err != nil {
+		t.Fatalf("❌ 创建战斗失败: %v", err)
+	}
+	for frame := uint64(10); claimed == nil && frame < 40; frame++ {
+		for _, in := range []*proto_pb.BattleContext{pause, resume} {
+			if frame == in.GetTick() {
+				live.ProcessBattleContextBatch([]*proto_pb.BattleContext{in})
+			}
+		}
+		live.OnTick(frame)
+	}
+	if claimed == nil {
+		t.Fatalf("❌ 战斗未结束")
+	}
+
+	replay := NewSimEngine()
+	replay.DisableBattleLogging()
+	defer replay.Close()
+
+	rec := &BattleRecord{Env: env, StartFrame: 10, Inputs: []*proto_pb.BattleContext{resume, pause}}
+	if err := VerifyBattleResult(replay, rec, claimed); err != nil {
+		t.Fatalf("❌ 复算结果应一致: %v", err)
+	}
+
+	forged := proto.Clone(claimed).(*proto_pb.BattleResult)
+	forged.Winner = 999
+	if err := VerifyBattleResult(replay, rec, forged); !errors.Is(err, ErrResultMismatch) {
+		t.Errorf("❌ 篡改的结果应返回 ErrResultMismatch, 实际 %v", err)
+	}
+
+	early := &BattleRecord{Env: env, StartFrame: 13, Inputs: rec.Inputs}
+	if _, err := Resimulate(replay, early); err == nil {
+		t.Errorf("❌ 早于开始帧的输入应被拒绝")
+	}
+}
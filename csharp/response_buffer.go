@@ -0,0 +1,113 @@
+package csharp
+
+import (
+	"errors"
+	"fmt"
+	"runtime"
+
+	"github.com/ebitengine/purego"
+)
+
+// ============================================================================
+// 响应缓冲区大小协商
+// ============================================================================
+//
+// 协议 (ProcessProtoMessage / ProcessBatchProtoMessage):
+//  1. Go 将缓冲区容量写入 *response_len 后调用
+//  2. C# 响应长度 <= 容量时写入数据，*response_len 为实际长度
+//  3. C# 响应长度 >  容量时不写数据，*response_len 为所需长度，
+//     并按调用线程暂存该响应，Go 在同一线程以相同请求重试时直接返回暂存结果
+//  4. Go 从 ProtoBufferPool 取足够大的缓冲区重试；放弃重试时调用 DiscardPendingResponse 丢弃暂存的响应
+
+const (
+	singleResponseBufferSize = 10240    // ProcessProtoMessage 初始缓冲区
+	batchResponseBufferSize  = 102400   // ProcessBatchProtoMessage 初始缓冲区
+	maxResponseSize          = 16 << 20 // 单次响应上限 16MB
+	maxResponseRetries       = 3        // 缓冲区扩容重试次数
+)
+
+var (
+	// ErrResponseTruncated 多次扩容后缓冲区仍不足以容纳响应
+	ErrResponseTruncated = errors.New("响应缓冲区不足")
+	// ErrResponseTooLarge 响应超过大小上限
+	ErrResponseTooLarge = errors.New("响应超过大小上限")
+	// ErrInvalidResponseLen C# 返回了非法的响应长度
+	ErrInvalidResponseLen = errors.New("响应长度非法")
+)
+
+// ResponseSizeError 响应大小协商失败的详细信息
+// 通过 errors.Is 可判断具体原因 (ErrResponseTruncated / ErrResponseTooLarge / ErrInvalidResponseLen)
+type ResponseSizeError struct {
+	Export   string // 导出函数名
+	Required int32  // C# 报告的响应长度
+	Capacity int32  // 最后一次提供的缓冲区容量
+	reason   error
+}
+
+func (e *ResponseSizeError) Error() string {
+	return fmt.Sprintf("%s: %v (需要 %d 字节, 缓冲区 %d 字节)", e.Export, e.reason, e.Required, e.Capacity)
+}
+
+func (e *ResponseSizeError) Unwrap() error {
+	return e.reason
+}
+
+// responseCall 以给定缓冲区调用一次导出函数
+// buf 的长度即容量，返回 C# 写回的 response_len
+type responseCall func(buf []byte) int32
+
+// negotiateResponse 按协议调用导出函数，必要时从 ProtoBufferPool 取更大的缓冲区重试
+// 返回的数据是独立副本；不超过初始大小的缓冲区归还到池中，扩容的大缓冲区交给 GC
+func negotiateResponse(export string, initialSize int, call responseCall) ([]byte, error) {
+	pool := GetGlobalProtoBufferPool()
+	release := func(buf []byte) {
+		if cap(buf) <= initialSize {
+			pool.Put(buf)
+		}
+	}
+	size := initialSize
+	var required int32
+
+	for attempt := 0; attempt <= maxResponseRetries; attempt++ {
+		buf := pool.GetWithCap(size)[:size]
+		respLen := call(buf)
+		required = respLen
+
+		switch {
+		case respLen < 0:
+			release(buf)
+			return nil, &ResponseSizeError{Export: export, Required: respLen, Capacity: int32(size), reason: ErrInvalidResponseLen}
+		case int(respLen) <= size:
+			data := append([]byte(nil), buf[:respLen]...)
+			release(buf)
+			return data, nil
+		case int(respLen) > maxResponseSize:
+			release(buf)
+			return nil, &ResponseSizeError{Export: export, Required: respLen, Capacity: int32(size), reason: ErrResponseTooLarge}
+		}
+
+		release(buf)
+		goLog(LogLevelDebug, "[Go] %s 响应 %d 字节超过缓冲区 %d 字节，扩容重试\n", export, respLen, size)
+		if attempt < maxResponseRetries {
+			size = int(respLen)
+		}
+	}
+
+	return nil, &ResponseSizeError{Export: export, Required: required, Capacity: int32(size), reason: ErrResponseTruncated}
+}
+
+// negotiatePendingResponse 对会暂存超大响应的导出函数 (ProcessProtoMessage / ProcessBatchProtoMessage) 协商响应
+// C# 按调用线程暂存响应，协商期间锁定 OS 线程使重试落在同一线程；放弃重试时通知 C# 丢弃暂存的响应
+func (l *Library) negotiatePendingResponse(export string, initialSize int, call responseCall) ([]byte, error) {
+	runtime.LockOSThread()
+	defer runtime.UnlockOSThread()
+
+	data, err := negotiateResponse(export, initialSize, call)
+	if errors.Is(err, ErrResponseTooLarge) || errors.Is(err, ErrResponseTruncated) {
+		// 旧版本库没有该导出，暂存的响应留到本线程的下一次调用时丢弃
+		if fnPtr, dlErr := l.getCachedFunction("DiscardPendingResponse"); dlErr == nil {
+			purego.SyscallN(fnPtr)
+		}
+	}
+	return data, err
+}
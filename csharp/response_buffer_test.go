@@ -0,0 +1,59 @@
+package csharp
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+)
+
+// TestNegotiateResponseGrow 测试响应超过缓冲区时按回报长度扩容重试
+func TestNegotiateResponseGrow(t *testing.T) {
+	payload := bytes.Repeat([]byte{0xAB}, singleResponseBufferSize*3)
+
+	calls := 0
+	data, err := negotiateResponse("ProcessProtoMessage", singleResponseBufferSize, func(buf []byte) int32 {
+		calls++
+		if len(buf) < len(payload) {
+			return int32(len(payload))
+		}
+		return int32(copy(buf, payload))
+	})
+	if err != nil {
+		t.Fatalf("❌ 协商失败: %v", err)
+	}
+	if calls != 2 {
+		t.Errorf("❌ 期望调用 2 次, 实际 %d", calls)
+	}
+	if !bytes.Equal(data, payload) {
+		t.Errorf("❌ 响应数据不一致, 长度 %d", len(data))
+	}
+}
+
+// TestNegotiateResponseErrors 测试非法、超限和持续不足的响应返回类型化错误
+func TestNegotiateResponseErrors(t *testing.T) {
+	cases := []struct {
+		name string
+		call responseCall
+		want error
+	}{
+		{"非法长度", func(buf []byte) int32 { return -1 }, ErrInvalidResponseLen},
+		{"超过上限", func(buf []byte) int32 { return maxResponseSize + 1 }, ErrResponseTooLarge},
+		{"持续不足", func(buf []byte) int32 { return int32(len(buf)) + 1 }, ErrResponseTruncated},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			data, err := negotiateResponse("ProcessBatchProtoMessage", batchResponseBufferSize, c.call)
+			if data != nil {
+				t.Errorf("❌ 出错时不应返回数据")
+			}
+			if !errors.Is(err, c.want) {
+				t.Fatalf("❌ 期望 %v, 实际 %v", c.want, err)
+			}
+			var sizeErr *ResponseSizeError
+			if !errors.As(err, &sizeErr) || sizeErr.Export != "ProcessBatchProtoMessage" {
+				t.Errorf("❌ 期望 *ResponseSizeError, 实际 %#v", err)
+			}
+		})
+	}
+}
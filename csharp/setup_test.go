@@ -32,7 +32,7 @@ func setupConfigLoaderTest(t *testing.T) func() {
 	// 执行所有环境步骤
 	for _, step := range steps {
 		if err := step.fn(t); err != nil {
-			t.Fail()
+			t.Fatalf("%s失败: %v", step.name, err)
 		}
 	}
 
@@ -45,8 +45,14 @@ func setupConfigLoaderTest(t *testing.T) func() {
 
 // ensureLibraryLoaded 环境步骤：确保 C# SO 库已加载
 func ensureLibraryLoaded(t *testing.T) error {
-	// 检查库是否已初始化（libHandle > 0 表示已加载）
-	// 由于 libHandle 是包私有的，我们通过尝试调用一个函数来判断
+	// BATTLE_ENGINE=sim 时直接使用纯 Go 模拟引擎，无需 .NET SDK
+	if os.Getenv("BATTLE_ENGINE") == "sim" {
+		UseSimEngine()
+		return nil
+	}
+
+	// 检查库是否已初始化（存在当前引擎表示已加载）
+	// 我们通过尝试调用一个函数来判断
 	// 如果返回"未初始化"错误，说明库未加载；否则库已加载
 
 	// 简单的方式：检查库文件是否存在，如果存在就初始化
@@ -69,7 +75,7 @@ func ensureLibraryLoaded(t *testing.T) error {
 		projectRoot = filepath.Dir(projectRoot)
 	}
 
-	return fmt.Errorf("找不到 C# 库文件 TestExport_Release.so")
+	return fmt.Errorf("找不到 C# 库文件 TestExport_Release.so (设置 BATTLE_ENGINE=sim 使用模拟引擎)")
 }
 
 // clearTestConfigCache 环境步骤：清空测试配置缓存
@@ -0,0 +1,290 @@
+package csharp
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"math/bits"
+	"sync/atomic"
+	"syscall"
+	"unsafe"
+
+	proto_pb "goPureWithCsharp/csharp/proto"
+
+	"github.com/ebitengine/purego"
+	"google.golang.org/protobuf/proto"
+)
+
+// ============================================================================
+// SharedRing - Go 与 C# 之间的共享内存单生产者单消费者环形缓冲区
+// ============================================================================
+//
+// 内存由 Go 通过匿名 mmap 分配，不在 Go 堆上，C# 持有指针期间不会被 GC 移动或回收。
+// 布局 (与 C# SharedRing.cs 一致):
+//
+//	[0, 4)     magic     'RING'
+//	[4, 8)     version   1
+//	[8, 16)    capacity  数据区字节数 (2 的幂)
+//	[64, 72)   head      消费者已读取的位置 (只由消费者写)
+//	[128, 136) tail      生产者已提交的位置 (只由生产者写)
+//	[192, ...) 数据区
+//
+// head / tail 单调递增，取模 capacity 得到数据区偏移，各自独占一个缓存行。
+// 帧格式: uint32 小端长度 + protobuf 数据，整帧按 8 字节对齐；
+// 数据区尾部放不下整帧时写入长度为 ringWrapMarker 的跳转标记，帧从数据区开头写入。
+//
+// 单生产者单消费者: 同一个环的写入方和读取方各自只能有一个 goroutine / 线程。
+
+const (
+	ringMagic      = 0x474E4952 // "RING" 小端
+	ringVersion    = 1
+	ringHeaderSize = 192
+	ringHeadOffset = 64
+	ringTailOffset = 128
+	ringFrameAlign = 8
+	ringFrameHead  = 4          // 帧长度前缀
+	ringWrapMarker = 0xFFFFFFFF // 跳转到数据区开头
+
+	minRingCapacity     = 4 << 10
+	DefaultRingCapacity = 64 << 10 // 默认数据区大小
+)
+
+// ErrRingFull 环形缓冲区剩余空间不足以写入一帧
+var ErrRingFull = errors.New("环形缓冲区已满")
+
+// SharedRing 共享内存环形缓冲区
+type SharedRing struct {
+	mem  []byte
+	data []byte
+	head *atomic.Uint64
+	tail *atomic.Uint64
+	mask uint64
+}
+
+// NewSharedRing 分配数据区至少为 capacity 字节的环形缓冲区，容量向上取整到 2 的幂
+func NewSharedRing(capacity int) (*SharedRing, error) {
+	if capacity < minRingCapacity {
+		capacity = minRingCapacity
+	}
+	capacity = 1 << bits.Len(uint(capacity-1))
+
+	mem, err := syscall.Mmap(-1, 0, ringHeaderSize+capacity, syscall.PROT_READ|syscall.PROT_WRITE, syscall.MAP_ANON|syscall.MAP_SHARED)
+	if err != nil {
+		return nil, fmt.Errorf("分配共享内存失败: %w", err)
+	}
+
+	binary.LittleEndian.PutUint32(mem[0:], ringMagic)
+	binary.LittleEndian.PutUint32(mem[4:], ringVersion)
+	binary.LittleEndian.PutUint64(mem[8:], uint64(capacity))
+
+	return &SharedRing{
+		mem:  mem,
+		data: mem[ringHeaderSize:],
+		head: (*atomic.Uint64)(unsafe.Pointer(&mem[ringHeadOffset])),
+		tail: (*atomic.Uint64)(unsafe.Pointer(&mem[ringTailOffset])),
+		mask: uint64(capacity - 1),
+	}, nil
+}
+
+// Pointer 共享内存起始地址，传给 C#
+func (r *SharedRing) Pointer() uintptr {
+	return uintptr(unsafe.Pointer(&r.mem[0]))
+}
+
+// Size 共享内存总字节数 (头部 + 数据区)
+func (r *SharedRing) Size() int {
+	return len(r.mem)
+}
+
+// Capacity 数据区字节数
+func (r *SharedRing) Capacity() int {
+	return len(r.data)
+}
+
+// Len 已写入但尚未读取的字节数 (包含帧头和对齐)
+func (r *SharedRing) Len() int {
+	return int(r.tail.Load() - r.head.Load())
+}
+
+// Write 写入一帧
+func (r *SharedRing) Write(frame []byte) error {
+	buf, publish, err := r.reserve(len(frame))
+	if err != nil {
+		return err
+	}
+	copy(buf, frame)
+	publish()
+	return nil
+}
+
+// WriteMessage 将 msg 直接序列化到环形缓冲区中，不经过中间缓冲
+func (r *SharedRing) WriteMessage(msg proto.Message) error {
+	opts := proto.MarshalOptions{}
+	buf, publish, err := r.reserve(opts.Size(msg))
+	if err != nil {
+		return err
+	}
+	if _, err := opts.MarshalAppend(buf[:0], msg); err != nil {
+		return fmt.Errorf("序列化帧失败: %w", err)
+	}
+	publish()
+	return nil
+}
+
+// reserve 预留 n 字节的帧，返回帧数据区和提交函数
+// 提交前消费者看不到这一帧；不提交则预留作废
+func (r *SharedRing) reserve(n int) ([]byte, func(), error) {
+	capacity := uint64(len(r.data))
+	need := alignFrame(n)
+	if need > capacity/2 {
+		return nil, nil, fmt.Errorf("帧长度 %d 超过环形缓冲区容量的一半 (%d)", n, capacity/2)
+	}
+
+	tail := r.tail.Load()
+	head := r.head.Load()
+	pos := tail & r.mask
+
+	// 尾部放不下整帧，跳到数据区开头
+	skip := uint64(0)
+	if contig := capacity - pos; need > contig {
+		skip = contig
+	}
+	if tail+skip+need-head > capacity {
+		return nil, nil, fmt.Errorf("%w: 需要 %d 字节, 剩余 %d 字节", ErrRingFull, need, capacity-(tail-head))
+	}
+
+	if skip > 0 {
+		binary.LittleEndian.PutUint32(r.data[pos:], ringWrapMarker)
+		pos = 0
+	}
+	binary.LittleEndian.PutUint32(r.data[pos:], uint32(n))
+	frame := r.data[pos+ringFrameHead : pos+ringFrameHead+uint64(n)]
+
+	return frame, func() { r.tail.Store(tail + skip + need) }, nil
+}
+
+// Drain 依次读取所有已提交的帧，返回读取的帧数
+// frame 直接引用共享内存，只在 fn 执行期间有效；fn 返回后该帧空间交还给生产者
+func (r *SharedRing) Drain(fn func(frame []byte)) int {
+	capacity := uint64(len(r.data))
+	head := r.head.Load()
+	tail := r.tail.Load()
+
+	count := 0
+	for head != tail {
+		pos := head & r.mask
+		n := binary.LittleEndian.Uint32(r.data[pos:])
+		if n == ringWrapMarker {
+			head += capacity - pos
+			r.head.Store(head)
+			continue
+		}
+
+		fn(r.data[pos+ringFrameHead : pos+ringFrameHead+uint64(n)])
+		head += alignFrame(int(n))
+		r.head.Store(head)
+		count++
+	}
+	return count
+}
+
+// Close 释放共享内存
+// 调用前必须确保 C# 已解除绑定 (AttachRingTransport(nil))，否则 C# 会访问已释放的内存
+func (r *SharedRing) Close() error {
+	if r.mem == nil {
+		return nil
+	}
+	err := syscall.Munmap(r.mem)
+	r.mem, r.data = nil, nil
+	return err
+}
+
+// alignFrame 帧头加数据按 ringFrameAlign 对齐后的长度
+func alignFrame(n int) uint64 {
+	return (uint64(n) + ringFrameHead + ringFrameAlign - 1) &^ (ringFrameAlign - 1)
+}
+
+// ============================================================================
+// RingTransport - 输入/输出环形缓冲区
+// ============================================================================
+//
+// 绑定后输入和输出不再逐条跨越 FFI:
+//   - Go 将 BattleContext 输入写入 Input，下一次 OnTick 开始时 C# 一次性读取并处理
+//   - C# 在 OnTick 中把战斗输出写入 Output (写满时退回结果回调)，Go 在 OnTick 返回后读取
+//
+// 热重载时新库自动绑定同一组环形缓冲区，未处理的输入不会丢失。
+
+// RingTransport 一组输入/输出环形缓冲区
+type RingTransport struct {
+	Input  *SharedRing // Go 写入, C# OnTick 读取
+	Output *SharedRing // C# OnTick 写入, Go 读取
+}
+
+// NewRingTransport 分配输入/输出环形缓冲区
+func NewRingTransport(inputCapacity, outputCapacity int) (*RingTransport, error) {
+	input, err := NewSharedRing(inputCapacity)
+	if err != nil {
+		return nil, err
+	}
+	output, err := NewSharedRing(outputCapacity)
+	if err != nil {
+		_ = input.Close()
+		return nil, err
+	}
+	return &RingTransport{Input: input, Output: output}, nil
+}
+
+// Close 释放两个环形缓冲区，调用前需先从引擎解除绑定
+func (t *RingTransport) Close() error {
+	return errors.Join(t.Input.Close(), t.Output.Close())
+}
+
+// AttachRingTransport 将环形缓冲区绑定到当前引擎，t 为 nil 时解除绑定
+func AttachRingTransport(t *RingTransport) error {
+	e, err := currentEngine()
+	if err != nil {
+		return err
+	}
+	return e.AttachRingTransport(t)
+}
+
+// AttachRingTransport 将环形缓冲区绑定到 C#，t 为 nil 时解除绑定
+// 需要库支持 FeatureRingTransport
+func (l *Library) AttachRingTransport(t *RingTransport) error {
+	if !l.HasFeature(FeatureRingTransport) {
+		return fmt.Errorf("%w: AttachRingTransport (库不支持 %s)", ErrExportNotFound, FeatureRingTransport)
+	}
+
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+
+	if l.handle == 0 {
+		return ErrNotInitialized
+	}
+	if err := l.attachRingTransport(t); err != nil {
+		return err
+	}
+
+	l.callbackMu.Lock()
+	l.transport = t
+	l.callbackMu.Unlock()
+	return nil
+}
+
+// attachRingTransport 调用 C# AttachRingTransport，调用方需持有 l.mu 读锁
+func (l *Library) attachRingTransport(t *RingTransport) error {
+	fnPtr, err := l.getCachedFunction("AttachRingTransport")
+	if err != nil {
+		return err
+	}
+
+	var args [4]uintptr
+	if t != nil {
+		args = [4]uintptr{t.Input.Pointer(), uintptr(t.Input.Size()), t.Output.Pointer(), uintptr(t.Output.Size())}
+	}
+	result, _, _ := purego.SyscallN(fnPtr, args[:]...)
+	if int32(result) != 0 {
+		return exportError("AttachRingTransport", int32(result), proto_pb.BattleErrorCode_INVALID_REQUEST)
+	}
+	return nil
+}
@@ -0,0 +1,127 @@
+package csharp
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+	"unsafe"
+
+	proto_pb "goPureWithCsharp/csharp/proto"
+
+	"google.golang.org/protobuf/proto"
+)
+
+// TestSharedRingWrap 测试帧在数据区尾部跳转、写满后读取释放空间
+func TestSharedRingWrap(t *testing.T) {
+	ring, err := NewSharedRing(0)
+	if err != nil {
+		t.Fatalf("❌ 分配环形缓冲区失败: %v", err)
+	}
+	defer ring.Close()
+
+	if ring.Capacity() != minRingCapacity || ring.Size() != ringHeaderSize+minRingCapacity {
+		t.Fatalf("❌ 容量错误: %d / %d", ring.Capacity(), ring.Size())
+	}
+
+	// 1000 字节的帧占 1008 字节，4 帧之后尾部只剩 64 字节，第 5 帧需要跳转
+	frame := bytes.Repeat([]byte{0xAB}, 1000)
+	for round := 0; round < 10; round++ {
+		written := 0
+		for {
+			frame[0] = byte(written)
+			err := ring.Write(frame)
+			if errors.Is(err, ErrRingFull) {
+				break
+			}
+			if err != nil {
+				t.Fatalf("❌ 写入失败: %v", err)
+			}
+			written++
+		}
+		if written == 0 {
+			t.Fatalf("❌ 第 %d 轮没有写入任何帧", round)
+		}
+
+		read := 0
+		ring.Drain(func(got []byte) {
+			if len(got) != len(frame) || got[0] != byte(read) {
+				t.Fatalf("❌ 第 %d 轮第 %d 帧内容错误", round, read)
+			}
+			read++
+		})
+		if read != written || ring.Len() != 0 {
+			t.Fatalf("❌ 第 %d 轮写入 %d 帧, 读取 %d 帧, 剩余 %d 字节", round, written, read, ring.Len())
+		}
+	}
+
+	if err := ring.Write(make([]byte, minRingCapacity)); err == nil || errors.Is(err, ErrRingFull) {
+		t.Errorf("❌ 超大帧应被拒绝, 实际 %v", err)
+	}
+}
+
+// TestSimEngineRingTransport 测试输入在 OnTick 中从环形缓冲区读取，输出写入环形缓冲区而非回调
+func TestSimEngineRingTransport(t *testing.T) {
+	engine := NewSimEngine()
+	engine.DisableBattleLogging()
+	defer engine.Close()
+
+	transport, err := NewRingTransport(0, 0)
+	if err != nil {
+		t.Fatalf("❌ 分配环形缓冲区失败: %v", err)
+	}
+	defer transport.Close()
+
+	if err := engine.AttachRingTransport(transport); err != nil {
+		t.Fatalf("❌ 绑定环形缓冲区失败: %v", err)
+	}
+	callbacks := 0
+	engine.RegisterBattleEndNotify(func(unsafe.Pointer, int32) int {
+		callbacks++
+		return 0
+	})
+
+	if err := engine.CreateBattle(teamEnv(1, 100, 101, 0)); err != nil {
+		t.Fatalf("❌ 创建战斗失败: %v", err)
+	}
+	// 暂停后恢复，战斗在同一帧内继续执行
+	inputs := []*proto_pb.BattleInput{
+		{Input: &proto_pb.BattleInput_Pause{Pause: &proto_pb.BattlePause{}}},
+		{Input: &proto_pb.BattleInput_Resume{Resume: &proto_pb.BattleResume{}}},
+		{Input: &proto_pb.BattleInput_UserOp{UserOp: &proto_pb.BattleUserOp{Operation: "attack"}}},
+	}
+	for i, in := range inputs {
+		err := transport.Input.WriteMessage(&proto_pb.BattleContext{
+			BattleId: 1,
+			Tick:     uint64(i),
+			Option:   &proto_pb.BattleContext_BattleInput{BattleInput: in},
+		})
+		if err != nil {
+			t.Fatalf("❌ 写入输入失败: %v", err)
+		}
+	}
+
+	var outputs []*proto_pb.BattleContext
+	for i := 0; i < 20 && len(outputs) == 0; i++ {
+		if _, err := engine.OnTick(uint64(i)); err != nil {
+			t.Fatalf("❌ OnTick 失败: %v", err)
+		}
+		if transport.Input.Len() != 0 {
+			t.Fatalf("❌ OnTick 后输入应全部处理, 剩余 %d 字节", transport.Input.Len())
+		}
+		transport.Output.Drain(func(frame []byte) {
+			ctx := &proto_pb.BattleContext{}
+			if err := proto.Unmarshal(frame, ctx); err != nil {
+				t.Fatalf("❌ 反序列化输出失败: %v", err)
+			}
+			outputs = append(outputs, ctx)
+		})
+	}
+
+	if len(outputs) != 2 || outputs[0].GetBattleId() != 1 || outputs[0].GetBattleOutput().GetResult() == nil ||
+		outputs[1].GetBattleOutput().GetReplay() == nil {
+		t.Fatalf("❌ 期望从环形缓冲区读到战斗 1 的结果和回放, 实际 %v", outputs)
+	}
+	if callbacks != 0 {
+		t.Errorf("❌ 绑定环形缓冲区后不应走结果回调, 实际 %d 次", callbacks)
+	}
+}
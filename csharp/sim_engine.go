@@ -0,0 +1,1233 @@
+package csharp
+
+import (
+	"cmp"
+	"errors"
+	"fmt"
+	"slices"
+	"strconv"
+	"sync"
+	"time"
+	"unsafe"
+
+	proto_pb "goPureWithCsharp/csharp/proto"
+
+	"github.com/ebitengine/purego"
+	"google.golang.org/protobuf/proto"
+)
+
+// ============================================================================
+// SimEngine - 纯 Go 模拟战斗引擎
+// ============================================================================
+
+// 与 C# BattleManager / BattleInstance 保持一致的战斗参数
+const (
+	simInitialHealth = 300 // BattleInstance.DefaultHealth，阵容为空的队伍作为一个单位时的血量
+	simMinDamage     = 20  // BattleManager.OnTick -> ExecuteRound(20, 50)
+	simMaxDamage     = 50
+
+	simOutputBufferSize = 20480 // BattleManager._outputBuffer
+	simConfigBufferSize = 20480 // BattleManager.LoadConfig 输出缓冲
+
+	simSystemError = -3 // NativeAOTExceptionInjector.NativeErrorCode.SystemError
+)
+
+// simBattle 对应 C# BattleInstance
+type simBattle struct {
+	battleID      uint32
+	shard         uint32 // 所在分片，对应 C# BattleShard.Id
+	atkTeamID     uint32
+	defTeamID     uint32
+	atkTeam       *proto_pb.Team // 创建战斗时的队伍 (回放)
+	defTeam       *proto_pb.Team
+	units         []*proto_pb.BattleUnit // 攻击方在前，按阵容顺序
+	currentRound  int32
+	state         string // BattleState*，对应 C# BattleInstance.State
+	finished      bool   // 已分出胜负，对应 C# BattleInstance.IsFinished
+	winner        uint32
+	endRequested  bool // 已强制结束，下一个逻辑帧记录结束事件
+	timeout       bool // 超时强制结束，对应 C# BattleInstance.TimedOut
+	resultPending bool // 本帧结束，OnTick 输出结果后清除
+	seed          uint64
+	random        *battleRand
+	startFrame    uint64
+	endFrame      uint64
+	events        []*proto_pb.BattleEvent
+}
+
+// simReplayVersion 对应 C# BattleInstance.ReplayVersion
+const simReplayVersion = "1.0"
+
+// SimEngine 纯 Go 实现的 Engine
+// 复刻 C# 侧 BattleManager、BattleInstance.ExecuteRound 与 SimpleBattleEngine 的语义，
+// 使 Proxy、BattleManager 以及 cmd/test 在没有 .NET SDK 的机器上也能运行
+// 战斗分片只记录在战斗上 (OnTickShard 只执行该分片的战斗)，所有分片共用一把锁
+type SimEngine struct {
+	mu       sync.Mutex
+	outputMu sync.Mutex // 串行化战斗输出，多个分片并行 OnTickShard 时保护环形缓冲区和 outputBuffer
+
+	battles map[uint32]*simBattle
+	order   []uint32 // 按创建顺序遍历，模拟 Dictionary 的枚举顺序
+
+	logLevel int
+
+	configLoader    RegisterConfigLoaderFunc
+	configName      string
+	configData      []byte
+	effects         *EffectTable // 对应 C# BattleManager._effects
+	units           *UnitTable   // 对应 C# BattleManager._units
+	battleEndNotify RegisterNotifyCb
+	notifyCallback  unsafe.Pointer
+	transport       *RingTransport
+
+	outputBuffer []byte
+
+	health
+}
+
+var _ Engine = (*SimEngine)(nil)
+
+// NewSimEngine 创建模拟引擎
+func NewSimEngine() *SimEngine {
+	return &SimEngine{
+		battles:      make(map[uint32]*simBattle),
+		effects:      NewEffectTable(),
+		units:        NewUnitTable(),
+		logLevel:     LogLevelDebug,
+		outputBuffer: make([]byte, 0, simOutputBufferSize),
+	}
+}
+
+// UseSimEngine 创建模拟引擎并设为当前激活的引擎
+func UseSimEngine() *SimEngine {
+	e := NewSimEngine()
+	UseEngine(e)
+	return e
+}
+
+// simLog 对应 C# BattleLogger
+func (s *SimEngine) simLog(level int, format string, args ...interface{}) {
+	if level < s.logLevel {
+		return
+	}
+	tags := []string{"DEBUG", "INFO", "WARN", "ERROR"}
+	if level >= 0 && level < len(tags) {
+		fmt.Printf("Sim[%s] %s\n", tags[level], fmt.Sprintf(format, args...))
+	}
+}
+
+// ============================================================================
+// 低级 API
+// ============================================================================
+
+// ProcessProtoMessage 对应 C# ExportedFunctions.ProcessProtoMessage
+func (s *SimEngine) ProcessProtoMessage(requestData []byte) ([]byte, error) {
+	resp := &proto_pb.BattleResponse{Timestamp: time.Now().UnixMilli()}
+
+	battleReq := &proto_pb.StartBattle{}
+	if err := proto.Unmarshal(requestData, battleReq); err != nil {
+		resp.Code = int32(proto_pb.BattleErrorCode_INVALID_PROTO_FORMAT)
+		resp.Message = fmt.Sprintf("Protobuf 格式错误: %v", err)
+		return proto.Marshal(resp)
+	}
+
+	s.mu.Lock()
+	result, replay, err := s.executeBattle(battleReq)
+	s.mu.Unlock()
+	if err != nil {
+		resp.Code = int32(proto_pb.BattleErrorCode_INTERNAL_ERROR)
+		resp.Message = fmt.Sprintf("内部错误: %v", err)
+		return proto.Marshal(resp)
+	}
+	s.notifyBattleCompleted(replay)
+
+	resultData, err := proto.Marshal(result)
+	if err != nil {
+		return nil, fmt.Errorf("战斗结果序列化失败: %w", err)
+	}
+	resp.Code = int32(proto_pb.BattleErrorCode_SUCCESS)
+	resp.Message = "战斗执行成功"
+	resp.Result = resultData
+	return proto.Marshal(resp)
+}
+
+// ProcessBatchProtoMessage 对应 C# ExportedFunctions.ProcessBatchProtoMessage
+func (s *SimEngine) ProcessBatchProtoMessage(requestData []byte) ([]byte, error) {
+	resp := &proto_pb.BattleResponse{Timestamp: time.Now().UnixMilli()}
+
+	batchReq := &proto_pb.BatchBattleRequest{}
+	if err := proto.Unmarshal(requestData, batchReq); err != nil {
+		resp.Code = int32(proto_pb.BattleErrorCode_INVALID_PROTO_FORMAT)
+		resp.Message = fmt.Sprintf("Protobuf 格式错误: %v", err)
+		return proto.Marshal(resp)
+	}
+
+	batchResp := &proto_pb.BatchBattleResponse{BatchId: batchReq.GetBatchId()}
+	totalStart := time.Now()
+	for _, battleReq := range batchReq.GetBattles() {
+		s.mu.Lock()
+		result, replay, err := s.executeBattle(battleReq)
+		s.mu.Unlock()
+		if err != nil {
+			s.simLog(LogLevelError, "战斗执行失败: %v", err)
+			batchResp.FailureCount++
+			continue
+		}
+		s.notifyBattleCompleted(replay)
+		batchResp.Results = append(batchResp.Results, result)
+		batchResp.SuccessCount++
+	}
+	batchResp.TotalDuration = time.Since(totalStart).Milliseconds()
+
+	resultData, err := proto.Marshal(batchResp)
+	if err != nil {
+		return nil, fmt.Errorf("批量战斗结果序列化失败: %w", err)
+	}
+	resp.Code = int32(proto_pb.BattleErrorCode_SUCCESS)
+	resp.Message = "批量战斗执行成功"
+	resp.Result = resultData
+	return proto.Marshal(resp)
+}
+
+// executeBattle 对应 C# SimpleBattleEngine.ExecuteBattle，调用方需持有 s.mu
+func (s *SimEngine) executeBattle(req *proto_pb.StartBattle) (*proto_pb.BattleResult, *proto_pb.BattleReplay, error) {
+	if req.GetAtk() == nil || req.GetDef() == nil {
+		return nil, nil, fmt.Errorf("攻击方或防守方队伍为空")
+	}
+	atkID, defID := req.GetAtk().GetTeamId(), req.GetDef().GetTeamId()
+
+	startTime := time.Now().UnixMilli()
+	events := []*proto_pb.BattleEvent{}
+	random := newBattleRand(req.GetSeed())
+
+	// Demo: 模拟 3 回合战斗
+	atkHealth, defHealth := int32(simInitialHealth), int32(simInitialHealth)
+	for round := 1; round <= 3; round++ {
+		atkDamage := random.between(20, 50)
+		defHealth -= atkDamage
+		events = append(events, &proto_pb.BattleEvent{
+			Timestamp:   time.Now().UnixMilli(),
+			EventType:   "attack",
+			PerformerId: atkID,
+			TargetId:    defID,
+			Value:       atkDamage,
+		})
+
+		defDamage := random.between(15, 40)
+		atkHealth -= defDamage
+		events = append(events, &proto_pb.BattleEvent{
+			Timestamp:   time.Now().UnixMilli(),
+			EventType:   "attack",
+			PerformerId: defID,
+			TargetId:    atkID,
+			Value:       defDamage,
+		})
+	}
+
+	endTime := time.Now().UnixMilli()
+	winner, loser := defID, atkID
+	if atkHealth > defHealth {
+		winner, loser = atkID, defID
+	}
+	events = append(events, &proto_pb.BattleEvent{
+		Timestamp:   endTime,
+		EventType:   "end",
+		PerformerId: winner,
+		TargetId:    loser,
+		Value:       1,
+	})
+
+	result := &proto_pb.BattleResult{
+		Winner:      winner,
+		Loser:       loser,
+		AtkDamage:   simInitialHealth - atkHealth,
+		DefDamage:   simInitialHealth - defHealth,
+		Duration:    endTime - startTime,
+		BattleScore: (simInitialHealth - defHealth) * 10,
+	}
+	replay := &proto_pb.BattleReplay{
+		BattleId:  req.GetBattleId(),
+		StartTime: startTime,
+		EndTime:   endTime,
+		AtkTeam:   req.GetAtk(),
+		DefTeam:   req.GetDef(),
+		Events:    events,
+		Result:    result,
+		Version:   "1.0",
+	}
+	return result, replay, nil
+}
+
+// notifyBattleCompleted 对应 C# SimpleBattleEngine.NotifyBattleCompleted
+func (s *SimEngine) notifyBattleCompleted(replay *proto_pb.BattleReplay) {
+	payload, err := proto.Marshal(replay)
+	if err != nil {
+		return
+	}
+	s.notifyBattle(&proto_pb.BattleNotification{
+		Timestamp:        time.Now().UnixMilli(),
+		NotificationType: proto_pb.NotificationType_BATTLE_COMPLETED,
+		BattleId:         replay.GetBattleId(),
+		Payload:          payload,
+	})
+}
+
+// notifyBattle 对应 C# BattleCallbackManager.NotifyBattle
+// 通过 RegisterCallback 注册的函数指针回调 Go
+func (s *SimEngine) notifyBattle(notification *proto_pb.BattleNotification) {
+	s.mu.Lock()
+	callbackPtr := s.notifyCallback
+	s.mu.Unlock()
+
+	if callbackPtr == nil {
+		return
+	}
+
+	data, err := proto.Marshal(notification)
+	if err != nil || len(data) == 0 {
+		return
+	}
+	purego.SyscallN(
+		uintptr(callbackPtr),
+		uintptr(unsafe.Pointer(&data[0])),
+		uintptr(len(data)),
+	)
+}
+
+// RegisterCallback 对应 C# BattleCallbackManager.RegisterCallback
+func (s *SimEngine) RegisterCallback(callbackPtr unsafe.Pointer) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.notifyCallback = callbackPtr
+	return nil
+}
+
+// TestNotifyCallback 对应 C# ExportedFunctions.TestNotifyCallback
+func (s *SimEngine) TestNotifyCallback(notificationType int32, battleID int64, timestamp int64) (int32, error) {
+	s.notifyBattle(&proto_pb.BattleNotification{
+		Timestamp:        timestamp,
+		NotificationType: proto_pb.NotificationType(notificationType),
+		BattleId:         uint32(battleID),
+	})
+	return 0, nil
+}
+
+// ============================================================================
+// 异常处理
+// ============================================================================
+
+// CallPanic 模拟 CsharpPanic 中的托管异常路径 (数组越界)
+// WrapExportFunction 捕获异常后返回 SystemError，Go 取回异常报告作为这次调用的错误
+func (s *SimEngine) CallPanic() error {
+	return withManagedException(
+		exportError("CsharpPanic", simSystemError, proto_pb.BattleErrorCode_INTERNAL_ERROR),
+		&ManagedException{
+			Type:       "System.IndexOutOfRangeException",
+			Message:    "Index was outside the bounds of the array.",
+			StackTrace: "   at BattleDemo.CsharpPanic()",
+			Export:     "CsharpPanic",
+			Timestamp:  time.Now(),
+		},
+	)
+}
+
+// ============================================================================
+// 回调注册
+// ============================================================================
+
+// RegisterConfigLoader 对应 C# BattleManager.RegisterConfigLoader
+func (s *SimEngine) RegisterConfigLoader(fn RegisterConfigLoaderFunc) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.configLoader = fn
+	s.simLog(LogLevelInfo, "配置加载器已注册")
+	return nil
+}
+
+// callConfigLoader 调用 Go 侧配置加载器
+// 输出槽预先填入一块缓冲区及其容量，兼容 "写入指针" 与 "写入缓冲区" 两种加载器实现
+func (s *SimEngine) callConfigLoader(configName string) ([]byte, error) {
+	s.mu.Lock()
+	loader := s.configLoader
+	s.mu.Unlock()
+
+	if loader == nil {
+		return nil, fmt.Errorf("配置加载器未注册")
+	}
+
+	nameBytes := []byte(configName)
+	if len(nameBytes) == 0 {
+		return nil, fmt.Errorf("配置文件名不能为空")
+	}
+
+	buffer := make([]byte, simConfigBufferSize)
+	outDataPtr := unsafe.Pointer(&buffer[0])
+	outDataLen := int32(len(buffer))
+
+	result := loader(
+		unsafe.Pointer(&nameBytes[0]),
+		int32(len(nameBytes)),
+		unsafe.Pointer(&outDataPtr),
+		unsafe.Pointer(&outDataLen),
+	)
+	if result != 0 {
+		return nil, fmt.Errorf("配置加载器返回错误: %d", result)
+	}
+
+	if outDataPtr == nil || outDataLen <= 0 {
+		return nil, nil
+	}
+	data := make([]byte, outDataLen)
+	copy(data, unsafe.Slice((*byte)(outDataPtr), outDataLen))
+	return data, nil
+}
+
+// LoadConfig 对应 C# BattleManager.LoadConfig
+func (s *SimEngine) LoadConfig(configName string) error {
+	data, err := s.callConfigLoader(configName)
+	if err != nil {
+		s.simLog(LogLevelError, "加载配置失败: %s", configName)
+		return exportError("LoadConfig", -1, proto_pb.BattleErrorCode_INVALID_REQUEST)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if err := errors.Join(s.effects.Load(configName, data), s.units.Load(configName, data)); err != nil {
+		s.simLog(LogLevelError, "解析配置失败: %v", err)
+		return exportError("LoadConfig", -1, proto_pb.BattleErrorCode_INVALID_REQUEST)
+	}
+	s.configName = configName
+	s.configData = data
+
+	s.simLog(LogLevelInfo, "配置已加载: %s (%d 字节)", configName, len(data))
+	return nil
+}
+
+// GetConfigLoaderData 对应 C# ExportedFunctions.GetConfigLoaderDataCSharp
+func (s *SimEngine) GetConfigLoaderData(configName string) ([]byte, error) {
+	data, err := s.callConfigLoader(configName)
+	if err != nil {
+		return nil, exportError("GetConfigLoaderDataCSharp", -1, proto_pb.BattleErrorCode_INVALID_REQUEST)
+	}
+	return data, nil
+}
+
+// RegisterBattleEndNotify 对应 C# BattleManager.RegisterResultCallback
+func (s *SimEngine) RegisterBattleEndNotify(fn RegisterNotifyCb) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.battleEndNotify = fn
+	s.simLog(LogLevelInfo, "战斗结果回调已注册")
+	return nil
+}
+
+// RegisterBattleResultCallback 与 RegisterBattleEndNotify 共用 C# 同一个结果回调
+func (s *SimEngine) RegisterBattleResultCallback(fn BattleResultCallbackFunc) error {
+	return s.RegisterBattleEndNotify(battleResultAdapter(fn))
+}
+
+// UnregisterConfigLoader 对应 C# RegisterConfigLoader(null)
+func (s *SimEngine) UnregisterConfigLoader() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.configLoader = nil
+	s.simLog(LogLevelInfo, "配置加载器已注销")
+	return nil
+}
+
+// UnregisterBattleEndNotify 对应 C# RegisterResultCallback(null)
+func (s *SimEngine) UnregisterBattleEndNotify() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.battleEndNotify = nil
+	s.simLog(LogLevelInfo, "战斗结果回调已注销")
+	return nil
+}
+
+// ============================================================================
+// 战斗管理
+// ============================================================================
+
+// CreateBattle 对应 C# BattleManager.CreateBattlee，在分片 0 中创建战斗
+// 按 env 的双方阵容创建单位，env.Seed 决定战斗内的随机数
+func (s *SimEngine) CreateBattle(env *proto_pb.BattleEnv) error {
+	return s.createBattle("CreateBattle", 0, env)
+}
+
+// CreateShardBattle 对应 C# BattleManager.CreateBattlee(shardId, env)
+// 战斗 ID 在所有分片中唯一
+func (s *SimEngine) CreateShardBattle(shardID uint32, env *proto_pb.BattleEnv) error {
+	return s.createBattle("CreateShardBattle", shardID, env)
+}
+
+func (s *SimEngine) createBattle(op string, shardID uint32, env *proto_pb.BattleEnv) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	battleID := env.GetBattleId()
+	if _, exists := s.battles[battleID]; exists {
+		s.simLog(LogLevelError, "战斗 ID=%d 已存在", battleID)
+		return exportError(op, -1, proto_pb.BattleErrorCode_DUPLICATE_BATTLE)
+	}
+	atk, def := env.GetAtk(), env.GetDef()
+	if atk == nil || def == nil {
+		s.simLog(LogLevelError, "战斗 ID=%d 缺少攻击方或防守方队伍", battleID)
+		return exportError(op, int32(proto_pb.BattleErrorCode_TEAM_NOT_FOUND), proto_pb.BattleErrorCode_TEAM_NOT_FOUND)
+	}
+	if atk.GetTeamId() == def.GetTeamId() {
+		s.simLog(LogLevelError, "战斗 ID=%d 双方队伍 ID 相同: %d", battleID, atk.GetTeamId())
+		return exportError(op, int32(proto_pb.BattleErrorCode_INVALID_REQUEST), proto_pb.BattleErrorCode_INVALID_REQUEST)
+	}
+	units, code := s.units.newUnits(atk, def)
+	if code != proto_pb.BattleErrorCode_SUCCESS {
+		s.simLog(LogLevelError, "战斗 ID=%d 阵容无效: %s", battleID, code)
+		return exportError(op, int32(code), code)
+	}
+
+	s.battles[battleID] = &simBattle{
+		battleID:  battleID,
+		shard:     shardID,
+		atkTeamID: atk.GetTeamId(),
+		defTeamID: def.GetTeamId(),
+		atkTeam:   proto.Clone(atk).(*proto_pb.Team),
+		defTeam:   proto.Clone(def).(*proto_pb.Team),
+		units:     units,
+		state:     BattleStateRunning,
+		seed:      env.GetSeed(),
+		random:    newBattleRand(env.GetSeed()),
+	}
+	s.order = append(s.order, battleID)
+
+	s.simLog(LogLevelInfo, "战斗已创建: ID=%d, 分片=%d, ATK=%d, DEF=%d, 单位=%d, Seed=%d",
+		battleID, shardID, atk.GetTeamId(), def.GetTeamId(), len(units), env.GetSeed())
+	return nil
+}
+
+// DestroyBattle 对应 C# BattleManager.DestroyBattle
+func (s *SimEngine) DestroyBattle(battleId uint64) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	id := uint32(battleId)
+	if _, exists := s.battles[id]; !exists {
+		s.simLog(LogLevelError, "战斗 ID=%d 不存在", id)
+		return exportError("DestroyBattle", -1, proto_pb.BattleErrorCode_BATTLE_NOT_FOUND)
+	}
+
+	delete(s.battles, id)
+	for i, bid := range s.order {
+		if bid == id {
+			s.order = append(s.order[:i], s.order[i+1:]...)
+			break
+		}
+	}
+	s.simLog(LogLevelInfo, "战斗已销毁: ID=%d", id)
+	return nil
+}
+
+// executeRound 对应 C# BattleInstance.ExecuteRound，调用方需持有 s.mu
+// 攻击方存活单位按阵容顺序各攻击一次，然后是防守方；一方单位全部被击败时战斗结束
+// 攻击和结束都以 frame 为时间戳记录为回放事件
+func (s *SimEngine) executeRound(b *simBattle, minDamage, maxDamage int32, frame uint64) {
+	if b.state != BattleStateRunning {
+		return
+	}
+
+	b.currentRound++
+	if b.currentRound == 1 {
+		b.startFrame = frame
+	}
+
+	for _, side := range [2][2]uint32{{b.atkTeamID, b.defTeamID}, {b.defTeamID, b.atkTeamID}} {
+		teamID, enemyID := side[0], side[1]
+		for _, unit := range b.units {
+			if unit.GetTeamId() != teamID || unit.GetHealth() <= 0 {
+				continue
+			}
+			target := b.pickTarget(teamID)
+			damage := unitDamage(b.random.between(minDamage, maxDamage+1), unit, target)
+			target.Health -= damage
+			evt := b.addEvent(frame, "attack", unit.GetUnitId(), target.GetUnitId(), damage)
+			s.simLog(LogLevelDebug, "[Battle %d] Round %d: 单位 %d 攻击 %d, 伤害=%d, 剩余血量=%d",
+				b.battleID, b.currentRound, unit.GetUnitId(), target.GetUnitId(), damage, target.GetHealth())
+			if target.GetHealth() > 0 {
+				continue
+			}
+
+			evt.Extra["killed"] = "1"
+			if b.pickTarget(teamID) == nil {
+				b.finish(frame, teamID, enemyID)
+				s.simLog(LogLevelInfo, "[Battle %d] 队伍 %d 全部被击败, 队伍 %d 获胜!", b.battleID, enemyID, teamID)
+				return
+			}
+		}
+	}
+}
+
+func (b *simBattle) finish(frame uint64, winner, loser uint32) {
+	b.state = BattleStateFinished
+	b.finished = true
+	b.winner = winner
+	b.endFrame = frame
+	b.resultPending = true
+	b.addEvent(frame, "end", winner, loser, 1)
+}
+
+// completeEnd 对应 C# BattleInstance.CompleteEnd
+func (s *SimEngine) completeEnd(b *simBattle, frame uint64) {
+	if !b.endRequested {
+		return
+	}
+
+	b.endRequested = false
+	if b.currentRound == 0 {
+		b.startFrame = frame
+	}
+	loser := b.atkTeamID
+	if b.winner == b.atkTeamID {
+		loser = b.defTeamID
+	}
+	b.endFrame = frame
+	b.resultPending = true
+	b.addEvent(frame, "end", b.winner, loser, 0)
+	s.simLog(LogLevelInfo, "[Battle %d] 强制结束, 胜方=%d, 超时=%v", b.battleID, b.winner, b.timeout)
+}
+
+// takeResult 对应 C# BattleInstance.TakeResult
+func (b *simBattle) takeResult() bool {
+	if !b.resultPending {
+		return false
+	}
+	b.resultPending = false
+	return true
+}
+
+// processInput 对应 C# BattleInstance.ProcessInput，返回 BattleErrorCode
+// 道具和用户操作按 effects 修改属性，属性变化以 ctx.Tick 为时间戳记录为回放事件
+func (b *simBattle) processInput(ctx *proto_pb.BattleContext, effects *EffectTable) proto_pb.BattleErrorCode {
+	input := ctx.GetBattleInput()
+	switch in := input.GetInput().(type) {
+	case *proto_pb.BattleInput_Pause:
+		if b.state != BattleStateRunning {
+			return proto_pb.BattleErrorCode_INVALID_BATTLE_STATE
+		}
+		b.state = BattleStatePaused
+	case *proto_pb.BattleInput_Resume:
+		if b.state != BattleStatePaused {
+			return proto_pb.BattleErrorCode_INVALID_BATTLE_STATE
+		}
+		b.state = BattleStateRunning
+	case *proto_pb.BattleInput_End:
+		return b.requestEnd(in.End.GetWinner(), in.End.GetTimeout())
+	case *proto_pb.BattleInput_Destroy:
+		if b.state == BattleStateDestroyed {
+			return proto_pb.BattleErrorCode_INVALID_BATTLE_STATE
+		}
+		// 强制结束后尚未输出的结果不再输出
+		b.state = BattleStateDestroyed
+		b.endRequested = false
+	case *proto_pb.BattleInput_Use, *proto_pb.BattleInput_UserOp:
+		if b.state != BattleStateRunning {
+			return proto_pb.BattleErrorCode_INVALID_BATTLE_STATE
+		}
+		resolved, code := effects.resolve(input)
+		if code != proto_pb.BattleErrorCode_SUCCESS {
+			return code
+		}
+		return b.applyEffects(ctx.GetTick(), resolved)
+	default:
+		return proto_pb.BattleErrorCode_INVALID_REQUEST
+	}
+	return proto_pb.BattleErrorCode_SUCCESS
+}
+
+// applyEffects 对应 C# BattleInstance.ApplyEffects
+// 使用者不在战斗中或已被击败时不修改任何属性；生命不会因效果降到 0 以下，单位只会在攻击中被击败
+func (b *simBattle) applyEffects(frame uint64, effects []inputEffect) proto_pb.BattleErrorCode {
+	for _, effect := range effects {
+		unit := b.findUnit(effect.user)
+		if unit == nil {
+			return proto_pb.BattleErrorCode_TEAM_NOT_FOUND
+		}
+		if unit.GetHealth() <= 0 {
+			return proto_pb.BattleErrorCode_INVALID_REQUEST
+		}
+	}
+
+	for _, effect := range effects {
+		unit := b.findUnit(effect.user)
+		switch effect.attributeID {
+		case AttributeMaxHealth:
+			unit.MaxHealth = max(unit.MaxHealth+effect.value, 1)
+			if effect.value > 0 {
+				unit.Health += effect.value
+			}
+		case AttributeAttack:
+			unit.Attack = max(unit.Attack+effect.value, 0)
+		case AttributeHealth:
+			unit.Health += effect.value
+		}
+		unit.Health = min(max(unit.Health, 1), unit.MaxHealth)
+
+		evt := b.addEvent(frame, effect.eventType, effect.user, effect.user, effect.value)
+		evt.Extra["attribute_id"] = strconv.FormatUint(uint64(effect.attributeID), 10)
+		for k, v := range effect.extra {
+			evt.Extra[k] = v
+		}
+	}
+	return proto_pb.BattleErrorCode_SUCCESS
+}
+
+// requestEnd 对应 C# BattleInstance.RequestEnd
+// winner 为 0 时按双方单位的剩余血量之和判定，相同时防守方获胜；timeout 记入结果
+func (b *simBattle) requestEnd(winner uint32, timeout bool) proto_pb.BattleErrorCode {
+	if b.state != BattleStateRunning && b.state != BattleStatePaused {
+		return proto_pb.BattleErrorCode_INVALID_BATTLE_STATE
+	}
+	if winner == 0 {
+		atkHealth, _, _ := b.teamStats(b.atkTeamID)
+		defHealth, _, _ := b.teamStats(b.defTeamID)
+		winner = b.defTeamID
+		if atkHealth > defHealth {
+			winner = b.atkTeamID
+		}
+	} else if winner != b.atkTeamID && winner != b.defTeamID {
+		return proto_pb.BattleErrorCode_INVALID_REQUEST
+	}
+
+	b.state = BattleStateFinished
+	b.finished = true
+	b.winner = winner
+	b.endRequested = true
+	b.timeout = timeout
+	return proto_pb.BattleErrorCode_SUCCESS
+}
+
+func (b *simBattle) addEvent(frame uint64, eventType string, performerID, targetID uint32, value int32) *proto_pb.BattleEvent {
+	evt := &proto_pb.BattleEvent{
+		Timestamp:   int64(frame),
+		EventType:   eventType,
+		PerformerId: performerID,
+		TargetId:    targetID,
+		Value:       value,
+		Extra:       map[string]string{"round": strconv.Itoa(int(b.currentRound))},
+	}
+	b.events = append(b.events, evt)
+	return evt
+}
+
+// buildResult 对应 C# BattleInstance.BuildResult
+// 伤害为该方单位受到的伤害总和，击杀列表为被击败的单位，时长为经历的逻辑帧数
+func (b *simBattle) buildResult() *proto_pb.BattleResult {
+	loser := b.atkTeamID
+	if b.winner == b.atkTeamID {
+		loser = b.defTeamID
+	}
+
+	var atkDamage, defDamage int32
+	kills := []uint32{}
+	for _, evt := range b.events {
+		if evt.GetEventType() != "attack" {
+			continue
+		}
+		if target := b.findUnit(evt.GetTargetId()); target != nil && target.GetTeamId() == b.atkTeamID {
+			atkDamage += evt.GetValue()
+		} else {
+			defDamage += evt.GetValue()
+		}
+		if evt.GetExtra()["killed"] != "" {
+			kills = append(kills, evt.GetTargetId())
+		}
+	}
+
+	return &proto_pb.BattleResult{
+		Winner:      b.winner,
+		Loser:       loser,
+		AtkDamage:   atkDamage,
+		DefDamage:   defDamage,
+		Kills:       kills,
+		Duration:    int64(b.endFrame - b.startFrame + 1),
+		BattleScore: defDamage * 10,
+		Timeout:     b.timeout,
+	}
+}
+
+// buildReplay 对应 C# BattleInstance.BuildReplay
+func (b *simBattle) buildReplay(result *proto_pb.BattleResult) *proto_pb.BattleReplay {
+	return &proto_pb.BattleReplay{
+		BattleId:  b.battleID,
+		StartTime: int64(b.startFrame),
+		EndTime:   int64(b.endFrame),
+		AtkTeam:   proto.Clone(b.atkTeam).(*proto_pb.Team),
+		DefTeam:   proto.Clone(b.defTeam).(*proto_pb.Team),
+		Events:    slices.Clone(b.events),
+		Result:    result,
+		Version:   simReplayVersion,
+	}
+}
+
+// OnTick 对应 C# BattleManager.OnTick
+// 每个未结束的战斗执行一回合，结束的战斗依次输出结果和回放，BattleContext.Tick 为 frame
+// 回调在释放锁之后调用，允许回调内再次调用引擎
+func (s *SimEngine) OnTick(frame uint64) (int32, error) {
+	return s.tick(frame, nil)
+}
+
+// OnTickShard 对应 C# BattleManager.OnTickShard，只执行 shardID 分片中的战斗，不处理环形缓冲区中的输入
+func (s *SimEngine) OnTickShard(shardID uint32, frame uint64) (int32, error) {
+	return s.tick(frame, &shardID)
+}
+
+// tick 执行 shardID 分片中的战斗，shardID 为空时先处理环形缓冲区中的输入，再按分片顺序执行所有战斗
+func (s *SimEngine) tick(frame uint64, shardID *uint32) (int32, error) {
+	s.mu.Lock()
+	transport := s.transport
+	if transport != nil && shardID == nil {
+		// 与 C# 一致: 先处理环形缓冲区中累积的输入，再执行回合；输入的 Tick 记为应用它的帧
+		transport.Input.Drain(func(data []byte) {
+			ctx := &proto_pb.BattleContext{}
+			if err := proto.Unmarshal(data, ctx); err != nil {
+				s.simLog(LogLevelError, "环形缓冲区输入反序列化失败: %v", err)
+				return
+			}
+			ctx.Tick = frame
+			if err := s.processContextInput(ctx); err != nil {
+				s.simLog(LogLevelError, "环形缓冲区输入处理失败: %v", err)
+			}
+		})
+	}
+
+	// 与 C# 一致: 分片按 ID 顺序执行，分片内按创建顺序
+	order := slices.SortedStableFunc(slices.Values(s.order), func(a, b uint32) int {
+		return cmp.Compare(s.battles[a].shard, s.battles[b].shard)
+	})
+
+	battleCount := int32(0)
+	finished := 0
+	outputs := []*proto_pb.BattleContext{}
+	for _, battleID := range order {
+		b := s.battles[battleID]
+		if shardID != nil && b.shard != *shardID {
+			continue
+		}
+
+		// 暂停、结束和销毁的战斗不执行回合
+		if b.state == BattleStateRunning {
+			s.executeRound(b, simMinDamage, simMaxDamage, frame)
+			battleCount++
+		}
+
+		// 强制结束的战斗在本帧记录结束事件
+		s.completeEnd(b, frame)
+
+		if b.takeResult() {
+			// 先输出结果，再输出包含全部回合事件的回放
+			result := b.buildResult()
+			outputs = append(outputs,
+				simOutput(battleID, frame, &proto_pb.BattleOutput{
+					Output: &proto_pb.BattleOutput_Result{Result: result},
+				}),
+				simOutput(battleID, frame, &proto_pb.BattleOutput{
+					Output: &proto_pb.BattleOutput_Replay{Replay: b.buildReplay(result)},
+				}))
+			finished++
+		}
+	}
+	notify := s.battleEndNotify
+	s.mu.Unlock()
+
+	s.outputMu.Lock()
+	defer s.outputMu.Unlock()
+	for _, ctx := range outputs {
+		// 优先写入输出环形缓冲区，写满或未绑定时退回结果回调
+		if transport != nil && transport.Output.WriteMessage(ctx) == nil {
+			continue
+		}
+		if notify != nil {
+			s.emitOutput(notify, ctx)
+		}
+	}
+
+	if battleCount > 0 {
+		s.simLog(LogLevelDebug, "Tick %d: 处理 %d 场战斗, 完成 %d 场", frame, battleCount, finished)
+	}
+	return battleCount, nil
+}
+
+// simOutput 构造一条战斗输出，Tick 为产生该输出的逻辑帧
+func simOutput(battleID uint32, frame uint64, output *proto_pb.BattleOutput) *proto_pb.BattleContext {
+	return &proto_pb.BattleContext{
+		BattleId: battleID,
+		Tick:     frame,
+		Option:   &proto_pb.BattleContext_BattleOutput{BattleOutput: output},
+	}
+}
+
+// emitOutput 序列化 BattleContext 到复用缓冲区并回调 Go
+func (s *SimEngine) emitOutput(notify RegisterNotifyCb, ctx *proto_pb.BattleContext) {
+	data, err := proto.MarshalOptions{}.MarshalAppend(s.outputBuffer[:0], ctx)
+	if err != nil {
+		s.simLog(LogLevelError, "战斗结果序列化失败: ID=%d, %v", ctx.GetBattleId(), err)
+		return
+	}
+	s.outputBuffer = data[:0]
+	if len(data) == 0 {
+		notify(nil, 0)
+		return
+	}
+	notify(unsafe.Pointer(&data[0]), int32(len(data)))
+}
+
+// GetBattleCount 对应 C# BattleManager.GetBattleCount
+func (s *SimEngine) GetBattleCount() (int32, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return int32(len(s.battles)), nil
+}
+
+// GetBattleStatus 对应 C# BattleManager.ListStatus(battleId)
+func (s *SimEngine) GetBattleStatus(battleID uint32) (*proto_pb.BattleStatus, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	b, exists := s.battles[battleID]
+	if !exists {
+		return nil, exportError("GetBattleStatus", -1, proto_pb.BattleErrorCode_BATTLE_NOT_FOUND)
+	}
+	return b.status(time.Now().UnixMilli()), nil
+}
+
+// ListBattles 对应 C# BattleManager.ListStatus(0)，按创建顺序返回
+func (s *SimEngine) ListBattles() ([]*proto_pb.BattleStatus, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := time.Now().UnixMilli()
+	list := make([]*proto_pb.BattleStatus, 0, len(s.order))
+	for _, battleID := range s.order {
+		list = append(list, s.battles[battleID].status(now))
+	}
+	return list, nil
+}
+
+// status 对应 C# BattleInstance.ToStatus
+func (b *simBattle) status(timestamp int64) *proto_pb.BattleStatus {
+	atkHealth, atkMaxHealth, atkAttack := b.teamStats(b.atkTeamID)
+	defHealth, defMaxHealth, defAttack := b.teamStats(b.defTeamID)
+	return &proto_pb.BattleStatus{
+		BattleId:     b.battleID,
+		ShardId:      b.shard,
+		Round:        b.currentRound,
+		AtkHealth:    atkHealth,
+		DefHealth:    defHealth,
+		State:        b.state,
+		Timestamp:    timestamp,
+		AtkTeamId:    b.atkTeamID,
+		DefTeamId:    b.defTeamID,
+		Winner:       b.winner,
+		AtkMaxHealth: atkMaxHealth,
+		DefMaxHealth: defMaxHealth,
+		AtkAttack:    atkAttack,
+		DefAttack:    defAttack,
+		Units:        cloneUnits(b.units),
+	}
+}
+
+// ProcessBattleInput 对应 C# BattleInputHandler.ProcessBattleInput
+func (s *SimEngine) ProcessBattleInput(battleId uint32, teamId uint32, actionType byte, actionValue int32) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	b, exists := s.battles[battleId]
+	if !exists {
+		return exportError("ProcessBattleInput", -1, battleInputErrorCode(-1)) // 战斗不存在
+	}
+	if b.state != BattleStateRunning {
+		return exportError("ProcessBattleInput", -2, battleInputErrorCode(-2)) // 战斗已结束 (或暂停、已销毁)
+	}
+	if actionType > 2 {
+		return exportError("ProcessBattleInput", -3, battleInputErrorCode(-3)) // 无效操作类型
+	}
+	return nil
+}
+
+// ProcessBattleContextInput 对应 C# ExportedFunctions.ProcessBattleContextInput
+func (s *SimEngine) ProcessBattleContextInput(input []byte) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	ctx := &proto_pb.BattleContext{}
+	if err := proto.Unmarshal(input, ctx); err != nil {
+		return exportError("ProcessBattleContextInput", -1, proto_pb.BattleErrorCode_INVALID_REQUEST)
+	}
+	return s.processContextInput(ctx)
+}
+
+// processContextInput 处理一条 BattleContext 输入，调用方需持有 s.mu
+func (s *SimEngine) processContextInput(ctx *proto_pb.BattleContext) error {
+	if ctx.GetBattleInput() == nil {
+		return exportError("ProcessBattleContextInput", -1, proto_pb.BattleErrorCode_INVALID_REQUEST)
+	}
+	if code := s.applyInput(ctx); code != proto_pb.BattleErrorCode_SUCCESS {
+		return exportError("ProcessBattleContextInput", int32(code), code)
+	}
+	return nil
+}
+
+// applyInput 对应 C# BattleManager.ProcessBattleContextInput，调用方需持有 s.mu
+func (s *SimEngine) applyInput(ctx *proto_pb.BattleContext) proto_pb.BattleErrorCode {
+	b, exists := s.battles[ctx.GetBattleId()]
+	if !exists {
+		return proto_pb.BattleErrorCode_BATTLE_NOT_FOUND
+	}
+	s.simLog(LogLevelInfo, "[Battle %d] 处理 BattleContext 输入, 状态=%s, Tick=%d", b.battleID, b.state, ctx.GetTick())
+	return b.processInput(ctx, s.effects)
+}
+
+// ProcessBattleContextBatch 对应 C# BattleManager.ProcessBattleContextBatch
+func (s *SimEngine) ProcessBattleContextBatch(contexts []*proto_pb.BattleContext) ([]proto_pb.BattleErrorCode, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	codes := make([]proto_pb.BattleErrorCode, len(contexts))
+	for i, ctx := range contexts {
+		if ctx.GetBattleInput() == nil {
+			codes[i] = proto_pb.BattleErrorCode_INVALID_REQUEST
+			continue
+		}
+		codes[i] = s.applyInput(ctx)
+	}
+	return codes, nil
+}
+
+// AttachRingTransport 对应 C# ExportedFunctions.AttachRingTransport
+func (s *SimEngine) AttachRingTransport(t *RingTransport) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.transport = t
+	return nil
+}
+
+// ============================================================================
+// 状态迁移
+// ============================================================================
+
+// ExportBattleState 对应 C# BattleManager.ExportState
+func (s *SimEngine) ExportBattleState() (*proto_pb.BattleStateSnapshot, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	snapshot := &proto_pb.BattleStateSnapshot{Timestamp: time.Now().UnixMilli()}
+	for _, battleID := range s.order {
+		b := s.battles[battleID]
+		atkHealth, atkMaxHealth, atkAttack := b.teamStats(b.atkTeamID)
+		defHealth, defMaxHealth, defAttack := b.teamStats(b.defTeamID)
+		snapshot.Battles = append(snapshot.Battles, &proto_pb.BattleSnapshot{
+			BattleId:     b.battleID,
+			ShardId:      b.shard,
+			AtkTeamId:    b.atkTeamID,
+			DefTeamId:    b.defTeamID,
+			AtkHealth:    atkHealth,
+			DefHealth:    defHealth,
+			CurrentRound: b.currentRound,
+			IsFinished:   b.finished,
+			State:        b.state,
+			EndRequested: b.endRequested,
+			AtkMaxHealth: atkMaxHealth,
+			DefMaxHealth: defMaxHealth,
+			AtkAttack:    atkAttack,
+			DefAttack:    defAttack,
+			Units:        cloneUnits(b.units),
+			AtkTeam:      proto.Clone(b.atkTeam).(*proto_pb.Team),
+			DefTeam:      proto.Clone(b.defTeam).(*proto_pb.Team),
+			Winner:       b.winner,
+			Seed:         b.seed,
+			RngState:     b.random.state,
+			StartFrame:   b.startFrame,
+			Events:       slices.Clone(b.events),
+			Timeout:      b.timeout,
+		})
+	}
+
+	s.simLog(LogLevelInfo, "战斗状态已导出: %d 场", len(snapshot.Battles))
+	return snapshot, nil
+}
+
+// snapshotState 对应 C# BattleInstance.FromSnapshot 的状态恢复，旧版本快照没有 state
+func snapshotState(b *proto_pb.BattleSnapshot) string {
+	switch b.GetState() {
+	case BattleStateRunning, BattleStatePaused, BattleStateFinished, BattleStateDestroyed:
+		return b.GetState()
+	}
+	if b.GetIsFinished() {
+		return BattleStateFinished
+	}
+	return BattleStateRunning
+}
+
+// snapshotTeam 旧版本快照没有队伍信息，只恢复队伍 ID
+func snapshotTeam(team *proto_pb.Team, teamID uint32) *proto_pb.Team {
+	if team == nil {
+		return &proto_pb.Team{TeamId: teamID}
+	}
+	return proto.Clone(team).(*proto_pb.Team)
+}
+
+// snapshotUnits 对应 C# BattleInstance.FromSnapshot 的单位恢复
+// 旧版本快照没有单位，每队恢复为一个单位
+func snapshotUnits(b *proto_pb.BattleSnapshot) []*proto_pb.BattleUnit {
+	if len(b.GetUnits()) > 0 {
+		return cloneUnits(b.GetUnits())
+	}
+	return []*proto_pb.BattleUnit{
+		teamUnit(b.GetAtkTeamId(), b.GetAtkHealth(), cmp.Or(b.GetAtkMaxHealth(), simInitialHealth), b.GetAtkAttack()),
+		teamUnit(b.GetDefTeamId(), b.GetDefHealth(), cmp.Or(b.GetDefMaxHealth(), simInitialHealth), b.GetDefAttack()),
+	}
+}
+
+// ImportBattleState 对应 C# BattleManager.ImportState
+// 任一战斗 ID 已存在时整个快照不导入
+func (s *SimEngine) ImportBattleState(snapshot *proto_pb.BattleStateSnapshot) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, b := range snapshot.GetBattles() {
+		if _, exists := s.battles[b.GetBattleId()]; exists {
+			s.simLog(LogLevelError, "导入战斗状态失败: 战斗 ID=%d 已存在", b.GetBattleId())
+			return exportError("ImportBattleState", -1, importStateErrorCode(-1))
+		}
+	}
+
+	for _, b := range snapshot.GetBattles() {
+		sb := &simBattle{
+			battleID:     b.GetBattleId(),
+			shard:        b.GetShardId(),
+			atkTeamID:    b.GetAtkTeamId(),
+			defTeamID:    b.GetDefTeamId(),
+			atkTeam:      snapshotTeam(b.GetAtkTeam(), b.GetAtkTeamId()),
+			defTeam:      snapshotTeam(b.GetDefTeam(), b.GetDefTeamId()),
+			units:        snapshotUnits(b),
+			currentRound: b.GetCurrentRound(),
+			state:        snapshotState(b),
+			finished:     b.GetIsFinished(),
+			endRequested: b.GetEndRequested(),
+			timeout:      b.GetTimeout(),
+			winner:       b.GetWinner(),
+			seed:         b.GetSeed(),
+			random:       &battleRand{state: b.GetRngState()},
+			startFrame:   b.GetStartFrame(),
+			events:       slices.Clone(b.GetEvents()),
+		}
+		if sb.finished && !sb.endRequested && len(sb.events) > 0 {
+			sb.endFrame = uint64(sb.events[len(sb.events)-1].GetTimestamp())
+		}
+		s.battles[b.GetBattleId()] = sb
+		s.order = append(s.order, b.GetBattleId())
+	}
+
+	s.simLog(LogLevelInfo, "战斗状态已导入: %d 场", len(snapshot.GetBattles()))
+	return nil
+}
+
+// ============================================================================
+// 日志控制
+// ============================================================================
+
+// SetBattleLogLevel 对应 C# ExportedFunctions.SetBattleLogLevel
+func (s *SimEngine) SetBattleLogLevel(level int) error {
+	if level < LogLevelDebug || level > LogLevelNone {
+		return exportError("SetBattleLogLevel", -1, proto_pb.BattleErrorCode_INVALID_REQUEST)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.logLevel = level
+	return nil
+}
+
+// GetBattleLogLevel 对应 C# ExportedFunctions.GetBattleLogLevel
+func (s *SimEngine) GetBattleLogLevel() (int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.logLevel, nil
+}
+
+// EnableBattleLogging 对应 C# BattleLogger.EnableAll
+func (s *SimEngine) EnableBattleLogging() error {
+	return s.SetBattleLogLevel(LogLevelDebug)
+}
+
+// DisableBattleLogging 对应 C# BattleLogger.DisableAll
+func (s *SimEngine) DisableBattleLogging() error {
+	return s.SetBattleLogLevel(LogLevelNone)
+}
+
+// ============================================================================
+// 全局函数
+// ============================================================================
+
+// CallGlobalFunction 对应 C# CallGoGlobalHandleBattleNotification / CallGoCalculateSum
+func (s *SimEngine) CallGlobalFunction(functionName string, battleID uint32, notificationType int, timestamp int64) (int32, error) {
+	switch functionName {
+	case "CallGoGlobalHandleBattleNotification":
+		s.notifyBattle(&proto_pb.BattleNotification{
+			BattleId:         battleID,
+			NotificationType: proto_pb.NotificationType(notificationType),
+			Timestamp:        timestamp,
+		})
+		return 0, nil
+
+	case "CallGoCalculateSum":
+		return int32(battleID) + int32(notificationType), nil
+
+	default:
+		return -1, fmt.Errorf("未知的 C# 全局函数: %s", functionName)
+	}
+}
+
+// CallSimpleGlobalFunction 对应 C# CallGoSimpleGlobalFunction
+func (s *SimEngine) CallSimpleGlobalFunction(battleID uint32, action string) (int32, error) {
+	s.simLog(LogLevelInfo, "CallGoSimpleGlobalFunction 被调用: BattleID=%d, Action=%s", battleID, action)
+	return 0, nil
+}
+
+// ============================================================================
+// 能力查询
+// ============================================================================
+
+// HasExport 模拟引擎实现了 Go 侧使用的全部导出函数
+func (s *SimEngine) HasExport(name string) bool {
+	return slices.Contains(requiredFuncs, name) || slices.Contains(optionalExports, name)
+}
+
+// HasFeature 模拟引擎支持全部可选特性
+func (s *SimEngine) HasFeature(feature string) bool {
+	switch feature {
+	case FeatureResponseNegotiation, FeatureHotReload, FeatureRingTransport, FeatureContextBatch, FeatureBattleStatus, FeatureShards:
+		return true
+	}
+	return false
+}
+
+// Close 清空所有战斗和回调
+func (s *SimEngine) Close() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.battles = make(map[uint32]*simBattle)
+	s.order = nil
+	s.configLoader = nil
+	s.battleEndNotify = nil
+	s.notifyCallback = nil
+	s.transport = nil
+	return nil
+}
@@ -0,0 +1,291 @@
+package csharp
+
+import (
+	"errors"
+	"slices"
+	"testing"
+	"unsafe"
+
+	proto_pb "goPureWithCsharp/csharp/proto"
+
+	"google.golang.org/protobuf/proto"
+)
+
+// teamEnv 阵容为空的战斗环境，双方各作为一个单位 (单位 ID 为队伍 ID)
+func teamEnv(battleID, atkTeamID, defTeamID uint32, seed uint64) *proto_pb.BattleEnv {
+	return &proto_pb.BattleEnv{
+		BattleId: battleID,
+		Atk:      &proto_pb.Team{TeamId: atkTeamID},
+		Def:      &proto_pb.Team{TeamId: defTeamID},
+		Seed:     seed,
+	}
+}
+
+// TestSimEngineTickUntilFinished 测试模拟引擎按 Tick 推进战斗并回调输出结果
+func TestSimEngineTickUntilFinished(t *testing.T) {
+	engine := NewSimEngine()
+	engine.DisableBattleLogging()
+	defer engine.Close()
+
+	outputs := []*proto_pb.BattleContext{}
+	err := engine.RegisterBattleEndNotify(func(dataPtr unsafe.Pointer, dataLen int32) int {
+		ctx := &proto_pb.BattleContext{}
+		if err := proto.Unmarshal(unsafe.Slice((*byte)(dataPtr), dataLen), ctx); err != nil {
+			t.Errorf("❌ 反序列化输出失败: %v", err)
+			return -1
+		}
+		outputs = append(outputs, ctx)
+		return 0
+	})
+	if err != nil {
+		t.Fatalf("❌ 注册回调失败: %v", err)
+	}
+
+	if err := engine.CreateBattle(teamEnv(1, 100, 101, 0)); err != nil {
+		t.Fatalf("❌ 创建战斗失败: %v", err)
+	}
+	if err := engine.CreateBattle(teamEnv(1, 100, 101, 0)); err == nil {
+		t.Errorf("❌ 重复创建战斗应该失败")
+	}
+
+	// 每回合最少 20 点伤害，300 血量最多 15 回合结束
+	frame := uint64(100)
+	for ; frame < 120 && len(outputs) == 0; frame++ {
+		if _, err := engine.OnTick(frame); err != nil {
+			t.Fatalf("❌ OnTick 失败: %v", err)
+		}
+	}
+
+	// 结束时依次输出结果和回放
+	if len(outputs) != 2 {
+		t.Fatalf("❌ 期望 2 个战斗输出, 实际 %d", len(outputs))
+	}
+	for _, out := range outputs {
+		if tick := out.GetTick(); tick != frame-1 {
+			t.Errorf("❌ 输出应标记为产生它的逻辑帧 %d, 实际 %d", frame-1, tick)
+		}
+	}
+	result := outputs[0].GetBattleOutput().GetResult()
+	winner := result.GetWinner()
+	if winner != 100 && winner != 101 {
+		t.Errorf("❌ 胜方异常: %d", winner)
+	}
+	if result.GetLoser()+winner != 201 || !slices.Equal(result.GetKills(), []uint32{result.GetLoser()}) {
+		t.Errorf("❌ 败方或击杀异常: %v", result)
+	}
+	if result.GetDuration() != int64(frame-100) || result.GetBattleScore() != result.GetDefDamage()*10 {
+		t.Errorf("❌ 时长应为 %d 帧, 分数应为 DEF 受到伤害的 10 倍: %v", frame-100, result)
+	}
+
+	replay := outputs[1].GetBattleOutput().GetReplay()
+	if replay == nil {
+		t.Fatalf("❌ 第二个输出应为战斗回放: %v", outputs[1])
+	}
+	if !proto.Equal(replay.GetResult(), result) || replay.GetStartTime() != 100 || replay.GetEndTime() != int64(frame-1) {
+		t.Errorf("❌ 回放结果或时间范围异常: %v", replay)
+	}
+	events := replay.GetEvents()
+	if len(events) < 2 || events[len(events)-1].GetEventType() != "end" || events[len(events)-1].GetPerformerId() != winner {
+		t.Fatalf("❌ 回放应以胜方的结束事件收尾: %v", events)
+	}
+	var atkDamage, defDamage int32
+	for _, evt := range events[:len(events)-1] {
+		if evt.GetEventType() != "attack" || evt.GetExtra()["round"] == "" {
+			t.Errorf("❌ 回合事件异常: %v", evt)
+		}
+		if evt.GetTargetId() == 100 {
+			atkDamage += evt.GetValue()
+		} else {
+			defDamage += evt.GetValue()
+		}
+	}
+	if atkDamage != result.GetAtkDamage() || defDamage != result.GetDefDamage() {
+		t.Errorf("❌ 事件伤害合计 %d/%d 与结果 %d/%d 不一致", atkDamage, defDamage, result.GetAtkDamage(), result.GetDefDamage())
+	}
+
+	processed, _ := engine.OnTick(frame)
+	if processed != 0 {
+		t.Errorf("❌ 已结束的战斗不应再处理, 实际处理 %d 场", processed)
+	}
+
+	if err := engine.DestroyBattle(1); err != nil {
+		t.Errorf("❌ 销毁战斗失败: %v", err)
+	}
+	if count, _ := engine.GetBattleCount(); count != 0 {
+		t.Errorf("❌ 期望战斗数量 0, 实际 %d", count)
+	}
+}
+
+// TestSimEngineExecBattle 测试通过包级 API 在模拟引擎上执行单场战斗
+func TestSimEngineExecBattle(t *testing.T) {
+	prev := UseEngine(NewSimEngine())
+	defer UseEngine(prev)
+
+	result, err := ExecBattle(&proto_pb.StartBattle{
+		Atk:      &proto_pb.Team{TeamId: 1001},
+		Def:      &proto_pb.Team{TeamId: 1002},
+		BattleId: 50001,
+	})
+	if err != nil {
+		t.Fatalf("❌ 战斗执行失败: %v", err)
+	}
+	if result.GetWinner() == 0 || result.GetWinner() == result.GetLoser() {
+		t.Errorf("❌ 战斗结果异常: %+v", result)
+	}
+
+	if _, err := ExecBattle(&proto_pb.StartBattle{BattleId: 50002}); err == nil {
+		t.Errorf("❌ 缺少队伍时应返回错误")
+	}
+}
+
+// TestSimEngineStateTransfer 测试战斗状态导出后在另一个引擎中恢复
+func TestSimEngineStateTransfer(t *testing.T) {
+	src := NewSimEngine()
+	src.DisableBattleLogging()
+	defer src.Close()
+
+	for id := uint32(1); id <= 3; id++ {
+		if err := src.CreateBattle(teamEnv(id, 100+id, 200+id, uint64(id))); err != nil {
+			t.Fatalf("❌ 创建战斗失败: %v", err)
+		}
+	}
+	src.OnTick(1)
+	src.OnTick(2)
+
+	snapshot, err := src.ExportBattleState()
+	if err != nil {
+		t.Fatalf("❌ 导出战斗状态失败: %v", err)
+	}
+	if len(snapshot.GetBattles()) != 3 {
+		t.Fatalf("❌ 期望导出 3 场战斗, 实际 %d", len(snapshot.GetBattles()))
+	}
+
+	dst := NewSimEngine()
+	dst.DisableBattleLogging()
+	defer dst.Close()
+
+	if err := dst.ImportBattleState(snapshot); err != nil {
+		t.Fatalf("❌ 导入战斗状态失败: %v", err)
+	}
+	restored, _ := dst.ExportBattleState()
+	for i, b := range restored.GetBattles() {
+		want := snapshot.GetBattles()[i]
+		if !proto.Equal(b, want) {
+			t.Errorf("❌ 战斗 %d 状态不一致: %v != %v", want.GetBattleId(), b, want)
+		}
+	}
+
+	// 恢复的战斗可以继续推进，随机数状态随快照迁移，与原引擎的后续回合一致
+	if processed, _ := dst.OnTick(3); processed != 3 {
+		t.Errorf("❌ 期望处理 3 场战斗, 实际 %d", processed)
+	}
+	src.OnTick(3)
+	srcNext, _ := src.ExportBattleState()
+	dstNext, _ := dst.ExportBattleState()
+	for i, b := range dstNext.GetBattles() {
+		if want := srcNext.GetBattles()[i]; !proto.Equal(b, want) {
+			t.Errorf("❌ 战斗 %d 迁移后回合不一致: %v != %v", want.GetBattleId(), b, want)
+		}
+	}
+
+	// ID 冲突时整个快照不导入
+	if err := dst.ImportBattleState(snapshot); err == nil {
+		t.Errorf("❌ 重复导入应该失败")
+	}
+	if count, _ := dst.GetBattleCount(); count != 3 {
+		t.Errorf("❌ 期望战斗数量 3, 实际 %d", count)
+	}
+}
+
+// TestSimEngineBattleStatus 测试查询单场和所有战斗的回合、血量和状态
+func TestSimEngineBattleStatus(t *testing.T) {
+	engine := NewSimEngine()
+	engine.DisableBattleLogging()
+	defer engine.Close()
+
+	if _, err := engine.GetBattleStatus(1); !errors.Is(err, ErrBattleNotFound) {
+		t.Errorf("❌ 不存在的战斗应返回 ErrBattleNotFound, 实际 %v", err)
+	}
+
+	engine.CreateBattle(teamEnv(1, 100, 101, 0))
+	engine.CreateBattle(teamEnv(2, 200, 201, 0))
+	engine.OnTick(0)
+
+	status, err := engine.GetBattleStatus(1)
+	if err != nil {
+		t.Fatalf("❌ 查询战斗状态失败: %v", err)
+	}
+	if status.GetRound() != 1 || status.GetState() != BattleStateRunning || status.GetAtkTeamId() != 100 ||
+		status.GetDefTeamId() != 101 || status.GetDefHealth() >= 300 || status.GetTimestamp() == 0 {
+		t.Errorf("❌ 第一回合后状态异常: %v", status)
+	}
+
+	for frame := uint64(1); frame < 20; frame++ {
+		engine.OnTick(frame)
+	}
+	list, err := engine.ListBattles()
+	if err != nil || len(list) != 2 {
+		t.Fatalf("❌ 期望 2 场战斗, 实际 %v (%v)", list, err)
+	}
+	for i, s := range list {
+		if s.GetBattleId() != uint32(i+1) || s.GetState() != BattleStateFinished || s.GetWinner() == 0 {
+			t.Errorf("❌ 战斗结束后状态异常: %v", s)
+		}
+	}
+}
+
+// TestSimEngineLifecycleSnapshot 测试暂停和已强制结束的状态随快照迁移
+func TestSimEngineLifecycleSnapshot(t *testing.T) {
+	src := NewSimEngine()
+	src.DisableBattleLogging()
+	defer src.Close()
+
+	src.CreateBattle(teamEnv(1, 100, 101, 1))
+	src.CreateBattle(teamEnv(2, 200, 201, 2))
+	src.OnTick(1)
+
+	input := func(id uint32, in *proto_pb.BattleInput) *proto_pb.BattleContext {
+		return &proto_pb.BattleContext{BattleId: id, Option: &proto_pb.BattleContext_BattleInput{BattleInput: in}}
+	}
+	codes, err := src.ProcessBattleContextBatch([]*proto_pb.BattleContext{
+		input(1, &proto_pb.BattleInput{Input: &proto_pb.BattleInput_Pause{Pause: &proto_pb.BattlePause{}}}),
+		input(2, &proto_pb.BattleInput{Input: &proto_pb.BattleInput_End{End: &proto_pb.BattleEnd{Winner: 200}}}),
+		input(2, &proto_pb.BattleInput{Input: &proto_pb.BattleInput_Resume{Resume: &proto_pb.BattleResume{}}}),
+	})
+	if err != nil {
+		t.Fatalf("❌ 批量输入失败: %v", err)
+	}
+	if !slices.Equal(codes, []proto_pb.BattleErrorCode{proto_pb.BattleErrorCode_SUCCESS, proto_pb.BattleErrorCode_SUCCESS, proto_pb.BattleErrorCode_INVALID_BATTLE_STATE}) {
+		t.Fatalf("❌ 输入结果码异常: %v", codes)
+	}
+
+	snapshot, _ := src.ExportBattleState()
+	dst := NewSimEngine()
+	dst.DisableBattleLogging()
+	defer dst.Close()
+	if err := dst.ImportBattleState(snapshot); err != nil {
+		t.Fatalf("❌ 导入战斗状态失败: %v", err)
+	}
+
+	outputs := []*proto_pb.BattleOutput{}
+	dst.RegisterBattleEndNotify(func(dataPtr unsafe.Pointer, dataLen int32) int {
+		ctx := &proto_pb.BattleContext{}
+		proto.Unmarshal(unsafe.Slice((*byte)(dataPtr), dataLen), ctx)
+		outputs = append(outputs, ctx.GetBattleOutput())
+		return 0
+	})
+	// 暂停的战斗不推进回合，已强制结束的战斗在迁移后的第一帧输出结果
+	if processed, _ := dst.OnTick(2); processed != 0 {
+		t.Errorf("❌ 期望不执行回合, 实际 %d", processed)
+	}
+	if status, _ := dst.GetBattleStatus(1); status.GetState() != BattleStatePaused || status.GetRound() != 1 {
+		t.Errorf("❌ 暂停状态未迁移: %v", status)
+	}
+	if len(outputs) != 2 || outputs[0].GetResult().GetWinner() != 200 {
+		t.Fatalf("❌ 强制结束的战斗应输出结果和回放, 实际 %v", outputs)
+	}
+	events := outputs[1].GetReplay().GetEvents()
+	if last := events[len(events)-1]; last.GetEventType() != "end" || last.GetValue() != 0 || last.GetTimestamp() != 2 {
+		t.Errorf("❌ 强制结束事件异常: %v", last)
+	}
+}
@@ -0,0 +1,243 @@
+package csharp
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync/atomic"
+	"time"
+
+	proto_pb "goPureWithCsharp/csharp/proto"
+)
+
+// ============================================================================
+// 受监督调用 - 带 context 截止时间的原生调用
+// ============================================================================
+//
+// 原生调用无法被中断。受监督调用在独立的 goroutine 中执行原生调用并等待 ctx:
+//   - 调用在截止时间前返回: 原样返回结果
+//   - 截止时间已过: 返回 TIMEOUT 的 *BattleError，并将引擎标记为不健康
+//   - ctx 被取消: 返回 ctx.Err()，调用在后台继续执行，不影响引擎健康状态
+//
+// 引擎不健康后，受监督调用和 *Library 的所有导出函数调用都立即返回 ErrUnhealthy，
+// 直到热重载替换为新库。卡住的调用仍持有库的读锁，不健康的库不会被卸载。
+//
+//	result, err := csharp.ExecBattleContext(ctx, req)
+//	processed, err := csharp.Supervise(engine).OnTick(ctx, frame)
+
+// health 引擎健康状态，嵌入 *Library 与 *SimEngine
+type health struct {
+	failure atomic.Pointer[error] // 第一次超时的错误，nil 表示健康
+}
+
+// Health 引擎健康时返回 nil，否则返回包装了 ErrUnhealthy 和超时原因的错误
+func (h *health) Health() error {
+	if cause := h.failure.Load(); cause != nil {
+		return fmt.Errorf("%w: %w", ErrUnhealthy, *cause)
+	}
+	return nil
+}
+
+// markUnhealthy 标记引擎不健康，只记录第一次的原因
+func (h *health) markUnhealthy(cause error) {
+	h.failure.CompareAndSwap(nil, &cause)
+}
+
+// Supervised 以 context 约束指定引擎上的原生调用
+type Supervised struct {
+	engine Engine
+}
+
+// Supervise 返回 e 的受监督调用入口
+func Supervise(e Engine) Supervised {
+	return Supervised{engine: e}
+}
+
+// supervisedResult 后台调用的结果
+type supervisedResult[T any] struct {
+	value T
+	err   error
+}
+
+// supervise 在后台 goroutine 中执行 call，并在 ctx 结束时放弃等待
+func supervise[T any](ctx context.Context, e Engine, op string, call func() (T, error)) (T, error) {
+	var zero T
+	if e == nil {
+		return zero, ErrNotInitialized
+	}
+	if err := e.Health(); err != nil {
+		return zero, err
+	}
+	if err := ctx.Err(); err != nil {
+		return zero, err
+	}
+
+	start := time.Now()
+	done := make(chan supervisedResult[T], 1)
+	go func() {
+		value, err := call()
+		done <- supervisedResult[T]{value: value, err: err}
+	}()
+
+	select {
+	case r := <-done:
+		return r.value, r.err
+	case <-ctx.Done():
+	}
+
+	if !errors.Is(ctx.Err(), context.DeadlineExceeded) {
+		return zero, ctx.Err()
+	}
+
+	timeoutErr := &BattleError{
+		Op:      op,
+		Code:    proto_pb.BattleErrorCode_TIMEOUT,
+		Message: fmt.Sprintf("超过截止时间，已等待 %v", time.Since(start).Round(time.Millisecond)),
+	}
+	if h, ok := e.(interface{ markUnhealthy(error) }); ok {
+		h.markUnhealthy(timeoutErr)
+	}
+	fmt.Printf("[Go] %s 超时，引擎已标记为不健康，需要热重载\n", op)
+
+	go func() {
+		<-done
+		fmt.Printf("[Go] 超时的 %s 调用在 %v 后返回，引擎保持不健康\n", op, time.Since(start).Round(time.Millisecond))
+	}()
+	return zero, timeoutErr
+}
+
+// superviseErr 用于只返回 error 的调用
+func superviseErr(ctx context.Context, e Engine, op string, call func() error) error {
+	_, err := supervise(ctx, e, op, func() (struct{}, error) {
+		return struct{}{}, call()
+	})
+	return err
+}
+
+// ============================================================================
+// 受监督的战斗 API
+// ============================================================================
+
+// ExecBattle 执行单场战斗
+func (s Supervised) ExecBattle(ctx context.Context, battleReq *proto_pb.StartBattle) (*proto_pb.BattleResult, error) {
+	return supervise(ctx, s.engine, "ExecBattle", func() (*proto_pb.BattleResult, error) {
+		return execBattle(s.engine, battleReq)
+	})
+}
+
+// ExecBatchBattle 执行批量战斗
+func (s Supervised) ExecBatchBattle(ctx context.Context, batchReq *proto_pb.BatchBattleRequest) (*proto_pb.BatchBattleResponse, error) {
+	return supervise(ctx, s.engine, "ExecBatchBattle", func() (*proto_pb.BatchBattleResponse, error) {
+		return execBatchBattle(s.engine, batchReq)
+	})
+}
+
+// CreateBattle 创建战斗
+func (s Supervised) CreateBattle(ctx context.Context, env *proto_pb.BattleEnv) error {
+	return superviseErr(ctx, s.engine, "CreateBattle", func() error {
+		return s.engine.CreateBattle(env)
+	})
+}
+
+// CreateShardBattle 在 shardID 分片中创建战斗
+func (s Supervised) CreateShardBattle(ctx context.Context, shardID uint32, env *proto_pb.BattleEnv) error {
+	return superviseErr(ctx, s.engine, "CreateShardBattle", func() error {
+		return s.engine.CreateShardBattle(shardID, env)
+	})
+}
+
+// DestroyBattle 销毁战斗
+func (s Supervised) DestroyBattle(ctx context.Context, battleId uint64) error {
+	return superviseErr(ctx, s.engine, "DestroyBattle", func() error {
+		return s.engine.DestroyBattle(battleId)
+	})
+}
+
+// OnTick 推动战斗进行逻辑帧 frame，返回处理的战斗数量
+func (s Supervised) OnTick(ctx context.Context, frame uint64) (int32, error) {
+	return supervise(ctx, s.engine, "OnTick", func() (int32, error) {
+		return s.engine.OnTick(frame)
+	})
+}
+
+// OnTickShard 推动 shardID 分片中的战斗进行逻辑帧 frame，返回处理的战斗数量
+func (s Supervised) OnTickShard(ctx context.Context, shardID uint32, frame uint64) (int32, error) {
+	return supervise(ctx, s.engine, "OnTickShard", func() (int32, error) {
+		return s.engine.OnTickShard(shardID, frame)
+	})
+}
+
+// ProcessBattleInput 处理战斗输入
+func (s Supervised) ProcessBattleInput(ctx context.Context, battleId uint32, teamId uint32, actionType byte, actionValue int32) error {
+	return superviseErr(ctx, s.engine, "ProcessBattleInput", func() error {
+		return s.engine.ProcessBattleInput(battleId, teamId, actionType, actionValue)
+	})
+}
+
+// ProcessBattleContextInput 处理序列化后的 BattleContext 输入
+func (s Supervised) ProcessBattleContextInput(ctx context.Context, input []byte) error {
+	return superviseErr(ctx, s.engine, "ProcessBattleContextInput", func() error {
+		return s.engine.ProcessBattleContextInput(input)
+	})
+}
+
+// ProcessBattleContextBatch 批量处理 BattleContext 输入，返回每条输入的结果码
+func (s Supervised) ProcessBattleContextBatch(ctx context.Context, contexts []*proto_pb.BattleContext) ([]proto_pb.BattleErrorCode, error) {
+	return supervise(ctx, s.engine, "ProcessBattleContextBatch", func() ([]proto_pb.BattleErrorCode, error) {
+		return s.engine.ProcessBattleContextBatch(contexts)
+	})
+}
+
+// ============================================================================
+// 当前引擎上的受监督调用
+// ============================================================================
+
+// ExecBattleContext 在当前引擎上执行单场战斗，受 ctx 截止时间约束
+func ExecBattleContext(ctx context.Context, battleReq *proto_pb.StartBattle) (*proto_pb.BattleResult, error) {
+	return Supervise(CurrentEngine()).ExecBattle(ctx, battleReq)
+}
+
+// ExecBatchBattleContext 在当前引擎上执行批量战斗，受 ctx 截止时间约束
+func ExecBatchBattleContext(ctx context.Context, batchReq *proto_pb.BatchBattleRequest) (*proto_pb.BatchBattleResponse, error) {
+	return Supervise(CurrentEngine()).ExecBatchBattle(ctx, batchReq)
+}
+
+// CreateBattleContext 在当前引擎上创建战斗，受 ctx 截止时间约束
+func CreateBattleContext(ctx context.Context, env *proto_pb.BattleEnv) error {
+	return Supervise(CurrentEngine()).CreateBattle(ctx, env)
+}
+
+// CreateShardBattleContext 在当前引擎的 shardID 分片中创建战斗，受 ctx 截止时间约束
+func CreateShardBattleContext(ctx context.Context, shardID uint32, env *proto_pb.BattleEnv) error {
+	return Supervise(CurrentEngine()).CreateShardBattle(ctx, shardID, env)
+}
+
+// DestroyBattleContext 在当前引擎上销毁战斗，受 ctx 截止时间约束
+func DestroyBattleContext(ctx context.Context, battleId uint64) error {
+	return Supervise(CurrentEngine()).DestroyBattle(ctx, battleId)
+}
+
+// OnTickContext 在当前引擎上推动逻辑帧 frame，受 ctx 截止时间约束
+func OnTickContext(ctx context.Context, frame uint64) (int32, error) {
+	return Supervise(CurrentEngine()).OnTick(ctx, frame)
+}
+
+// OnTickShardContext 在当前引擎上推动 shardID 分片的逻辑帧 frame，受 ctx 截止时间约束
+func OnTickShardContext(ctx context.Context, shardID uint32, frame uint64) (int32, error) {
+	return Supervise(CurrentEngine()).OnTickShard(ctx, shardID, frame)
+}
+
+// ProcessBattleInputContext 在当前引擎上处理战斗输入，受 ctx 截止时间约束
+func ProcessBattleInputContext(ctx context.Context, battleId uint32, teamId uint32, actionType byte, actionValue int32) error {
+	return Supervise(CurrentEngine()).ProcessBattleInput(ctx, battleId, teamId, actionType, actionValue)
+}
+
+// ProcessBattleContextInputContext 在当前引擎上处理序列化后的 BattleContext 输入，受 ctx 截止时间约束
+func ProcessBattleContextInputContext(ctx context.Context, input []byte) error {
+	return Supervise(CurrentEngine()).ProcessBattleContextInput(ctx, input)
+}
+
+// ProcessBattleContextBatchContext 在当前引擎上批量处理 BattleContext 输入，受 ctx 截止时间约束
+func ProcessBattleContextBatchContext(ctx context.Context, contexts []*proto_pb.BattleContext) ([]proto_pb.BattleErrorCode, error) {
+	return Supervise(CurrentEngine()).ProcessBattleContextBatch(ctx, contexts)
+}
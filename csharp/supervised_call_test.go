@@ -0,0 +1,71 @@
+package csharp
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+	"unsafe"
+)
+
+// TestSupervisedCallTimeout 测试调用超时返回 TIMEOUT，引擎标记为不健康后快速失败
+func TestSupervisedCallTimeout(t *testing.T) {
+	engine := NewSimEngine()
+	engine.DisableBattleLogging()
+	defer engine.Close()
+
+	// 结果回调阻塞，模拟卡在 OnTick 中的 C# 战斗
+	release := make(chan struct{})
+	defer close(release)
+	engine.RegisterBattleEndNotify(func(dataPtr unsafe.Pointer, dataLen int32) int {
+		<-release
+		return 0
+	})
+
+	supervised := Supervise(engine)
+	if err := supervised.CreateBattle(context.Background(), teamEnv(1, 100, 101, 0)); err != nil {
+		t.Fatalf("❌ 创建战斗失败: %v", err)
+	}
+
+	var err error
+	for i := 0; i < 20 && err == nil; i++ {
+		ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+		_, err = supervised.OnTick(ctx, uint64(i))
+		cancel()
+	}
+	if !errors.Is(err, ErrTimeout) || !IsRetryable(err) {
+		t.Fatalf("❌ 期望可重试的 ErrTimeout, 实际 %v", err)
+	}
+	if !errors.Is(engine.Health(), ErrUnhealthy) {
+		t.Fatalf("❌ 超时后引擎应不健康, 实际 %v", engine.Health())
+	}
+
+	start := time.Now()
+	err = supervised.CreateBattle(context.Background(), teamEnv(2, 100, 101, 0))
+	if !errors.Is(err, ErrUnhealthy) || !errors.Is(err, ErrTimeout) {
+		t.Errorf("❌ 不健康的引擎期望 ErrUnhealthy, 实际 %v", err)
+	}
+	if elapsed := time.Since(start); elapsed > 10*time.Millisecond {
+		t.Errorf("❌ 不健康的引擎应快速失败, 耗时 %v", elapsed)
+	}
+}
+
+// TestSupervisedCallCanceled 测试调用方取消不影响引擎健康状态
+func TestSupervisedCallCanceled(t *testing.T) {
+	engine := NewSimEngine()
+	engine.DisableBattleLogging()
+	defer engine.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, err := Supervise(engine).OnTick(ctx, 0); !errors.Is(err, context.Canceled) {
+		t.Errorf("❌ 期望 context.Canceled, 实际 %v", err)
+	}
+	if err := engine.Health(); err != nil {
+		t.Errorf("❌ 取消不应标记引擎不健康: %v", err)
+	}
+
+	if _, err := Supervise(nil).OnTick(context.Background(), 0); !errors.Is(err, ErrNotInitialized) {
+		t.Errorf("❌ 空引擎期望 ErrNotInitialized, 实际 %v", err)
+	}
+}